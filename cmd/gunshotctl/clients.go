@@ -0,0 +1,314 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/Imm0bilize/gunshot-api-service/pkg/client"
+	"github.com/pkg/errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const _listPage = 500
+
+var _clientHeader = []string{"ID", "FULL NAME", "LOCATION", "LATITUDE", "LONGITUDE", "VERSION", "HEALTH"}
+
+func clientRow(sensor client.Sensor) []string {
+	return []string{
+		sensor.ID.Hex(),
+		sensor.FullName,
+		sensor.LocationName,
+		formatFloat(sensor.Latitude),
+		formatFloat(sensor.Longitude),
+		strconv.FormatInt(sensor.Version, 10),
+		string(sensor.Health.Status),
+	}
+}
+
+func registerClient(e *env, args []string) int {
+	flags := e.flags("clients register", "-name <full name> -location <name> -lat <latitude> -lon <longitude>")
+
+	var (
+		fullName = flags.String("name", "", "the full name of the client")
+		location = flags.String("location", "", "the name of the location")
+		lat      = flags.Float64("lat", 0, "the latitude")
+		lon      = flags.Float64("lon", 0, "the longitude")
+		notify   = flags.String("notify", "", "comma separated notification methods")
+	)
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *fullName == "" || *location == "" || !isSet(flags, "lat") || !isSet(flags, "lon") {
+		return e.usageError(flags, "-name, -location, -lat and -lon are required")
+	}
+
+	c, err := e.client()
+	if err != nil {
+		return e.fail(err)
+	}
+
+	id, err := c.RegisterSensor(e.ctx, client.SensorInfo{
+		LocationName:        *location,
+		FullName:            *fullName,
+		Latitude:            *lat,
+		Longitude:           *lon,
+		NotificationMethods: splitList(*notify),
+	})
+	if err != nil {
+		return e.fail(err)
+	}
+
+	return e.render(map[string]string{"clientID": id}, []string{"ID"}, [][]string{{id}})
+}
+
+// listClients lists all clients page by page
+func listClients(e *env, args []string) int {
+	flags := e.flags("clients list", "[-page <size>]")
+	page := flags.Int64("page", _listPage, "how many clients are requested at once")
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *page <= 0 {
+		return e.usageError(flags, "-page must be positive")
+	}
+
+	c, err := e.client()
+	if err != nil {
+		return e.fail(err)
+	}
+
+	var (
+		sensors = make([]client.Sensor, 0)
+		after   string
+	)
+
+	for {
+		var batch []client.Sensor
+		if batch, after, err = c.ListSensors(e.ctx, after, *page); err != nil {
+			return e.fail(err)
+		}
+
+		sensors = append(sensors, batch...)
+		if after == "" {
+			break
+		}
+	}
+
+	rows := make([][]string, 0, len(sensors))
+	for _, sensor := range sensors {
+		rows = append(rows, clientRow(sensor))
+	}
+
+	return e.render(sensors, _clientHeader, rows)
+}
+
+func getClient(e *env, args []string) int {
+	flags := e.flags("clients get", "<client id>")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 {
+		return e.usageError(flags, "the client id is required")
+	}
+
+	c, err := e.client()
+	if err != nil {
+		return e.fail(err)
+	}
+
+	sensor, err := c.GetSensor(e.ctx, flags.Arg(0))
+	if err != nil {
+		return e.fail(err)
+	}
+
+	return e.render(sensor, _clientHeader, [][]string{clientRow(sensor)})
+}
+
+// updateClient patches fields set by flags, the write is conditional on the version the client is read at
+func updateClient(e *env, args []string) int {
+	flags := e.flags(
+		"clients update", "[-name <full name>] [-location <name>] [-lat <latitude>] [-lon <longitude>] <client id>",
+	)
+
+	var (
+		fullName = flags.String("name", "", "the full name of the client")
+		location = flags.String("location", "", "the name of the location")
+		lat      = flags.Float64("lat", 0, "the latitude")
+		lon      = flags.Float64("lon", 0, "the longitude")
+	)
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 {
+		return e.usageError(flags, "the client id is required")
+	}
+
+	var (
+		patch   client.SensorPatch
+		changed bool
+	)
+
+	flags.Visit(func(f *flag.Flag) {
+		changed = true
+
+		switch f.Name {
+		case "name":
+			patch.FullName = fullName
+		case "location":
+			patch.LocationName = location
+		case "lat":
+			patch.Latitude = lat
+		case "lon":
+			patch.Longitude = lon
+		}
+	})
+
+	if !changed {
+		return e.usageError(flags, "nothing to update")
+	}
+
+	c, err := e.client()
+	if err != nil {
+		return e.fail(err)
+	}
+
+	sensor, err := c.GetSensor(e.ctx, flags.Arg(0))
+	if err != nil {
+		return e.fail(err)
+	}
+
+	sensor, err = c.PatchSensor(e.ctx, flags.Arg(0), sensor.Version, patch)
+	if err != nil {
+		return e.fail(err)
+	}
+
+	return e.render(sensor, _clientHeader, [][]string{clientRow(sensor)})
+}
+
+func deleteClient(e *env, args []string) int {
+	flags := e.flags("clients delete", "<client id>")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 {
+		return e.usageError(flags, "the client id is required")
+	}
+
+	c, err := e.client()
+	if err != nil {
+		return e.fail(err)
+	}
+
+	sensor, err := c.GetSensor(e.ctx, flags.Arg(0))
+	if err != nil {
+		return e.fail(err)
+	}
+
+	if err := c.DeleteSensor(e.ctx, flags.Arg(0), sensor.Version); err != nil {
+		return e.fail(err)
+	}
+
+	fmt.Fprintln(e.stderr, "deleted", flags.Arg(0))
+
+	return exitOK
+}
+
+// importClients imports the CSV or GeoJSON file, '-' reads CSV of stdin. Nothing is created if any row is
+// invalid, errors of rows are listed then
+func importClients(e *env, args []string) int {
+	flags := e.flags("clients import", "[-dry-run] <file.csv|file.geojson|->")
+	dryRun := flags.Bool("dry-run", false, "only validate the file")
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 {
+		return e.usageError(flags, "the file is required")
+	}
+
+	var (
+		path        = flags.Arg(0)
+		file        = e.stdin
+		contentType = "text/csv"
+	)
+
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return e.fail(err)
+		}
+		defer f.Close()
+
+		file = f
+
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".geojson", ".json":
+			contentType = "application/geo+json"
+		case ".csv":
+		default:
+			return e.usageError(flags, "unknown format of %s: expected .csv or .geojson", path)
+		}
+	}
+
+	c, err := e.client()
+	if err != nil {
+		return e.fail(err)
+	}
+
+	result, err := c.ImportSensors(e.ctx, file, contentType, *dryRun)
+
+	var problem *client.Error
+	if errors.As(err, &problem) && problem.ImportErrors() != nil {
+		rows := make([][]string, 0)
+		for _, row := range problem.ImportErrors() {
+			rows = append(rows, []string{strconv.Itoa(row.Row), row.Error})
+		}
+
+		e.render(problem.ImportErrors(), []string{"ROW", "ERROR"}, rows)
+
+		return e.fail(err)
+	}
+	if err != nil {
+		return e.fail(err)
+	}
+
+	rows := make([][]string, 0, len(result.Created))
+	for _, created := range result.Created {
+		rows = append(rows, []string{strconv.Itoa(created.Row), created.ClientID})
+	}
+
+	if result.DryRun {
+		fmt.Fprintf(e.stderr, "%d rows are valid, nothing is created\n", result.Rows)
+	}
+
+	return e.render(result, []string{"ROW", "CLIENT ID"}, rows)
+}
+
+func isSet(flags *flag.FlagSet, name string) bool {
+	var set bool
+	flags.Visit(func(f *flag.Flag) {
+		set = set || f.Name == name
+	})
+
+	return set
+}
+
+func splitList(s string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
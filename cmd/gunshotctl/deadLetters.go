@@ -0,0 +1,58 @@
+package main
+
+import (
+	"github.com/Imm0bilize/gunshot-api-service/pkg/client"
+	"strconv"
+)
+
+// replayDeadLetters produces dead letters of detections to the detections topic again through the admin API,
+// the API key must be AUTH_ADMIN_TOKEN of the service. Letters are selected by their offsets in the
+// dead-letter topic, -source-offset keeps the ones whose x-offset header is listed
+func replayDeadLetters(e *env, args []string) int {
+	flags := e.flags(
+		"dead-letters replay", "[-partition <n>] -from <offset> [-to <offset>] [-source-offset <offset,...>]",
+	)
+
+	var (
+		partition     = flags.Int("partition", 0, "the partition of the dead-letter topic")
+		from          = flags.Int64("from", 0, "the offset of the first letter in the dead-letter topic")
+		to            = flags.Int64("to", 0, "the offset of the last letter, the newest one if not set")
+		sourceOffsets = flags.String("source-offset", "", "comma separated offsets in the detections topic")
+	)
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 0 {
+		return e.usageError(flags, "unexpected arguments")
+	}
+	if !isSet(flags, "from") {
+		return e.usageError(flags, "-from is required")
+	}
+
+	replay := client.DeadLetterReplay{Partition: int32(*partition), From: *from}
+	if isSet(flags, "to") {
+		replay.To = to
+	}
+	for _, offset := range splitList(*sourceOffsets) {
+		parsed, err := strconv.ParseInt(offset, 10, 64)
+		if err != nil {
+			return e.usageError(flags, "-source-offset '%s' is not an offset", offset)
+		}
+		replay.SourceOffsets = append(replay.SourceOffsets, parsed)
+	}
+
+	c, err := e.client()
+	if err != nil {
+		return e.fail(err)
+	}
+
+	result, err := c.ReplayDeadLetters(e.ctx, replay)
+	if err != nil {
+		return e.fail(err)
+	}
+
+	return e.render(result, []string{"REPLAYED", "SKIPPED", "NEXT"}, [][]string{{
+		strconv.Itoa(result.Replayed), strconv.Itoa(result.Skipped), strconv.FormatInt(result.Next, 10),
+	}})
+}
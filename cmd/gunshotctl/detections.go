@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Imm0bilize/gunshot-api-service/pkg/client"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+const _tailPage = 500
+
+// _tailLag is how far back each poll looks again: IDs given by replicas of the service in the same second
+// and detections written slowly are not stored in the order of their IDs
+const _tailLag = 10 * time.Second
+
+// tailDetections polls detections and prints new ones until it's interrupted. Detections are listed in the
+// order the service stores them, from the last printed one, so detections with old timestamps, e.g. of the
+// device which was offline, are printed when they arrive
+func tailDetections(e *env, args []string) int {
+	flags := e.flags("detections tail", "[-client <id>] [-zone <id>] [-since 10m] [-interval 5s]")
+
+	var (
+		clientID = flags.String("client", "", "only detections of the client")
+		zoneID   = flags.String("zone", "", "only detections of the zone")
+		since    = flags.Duration("since", 10*time.Minute, "print detections stored within this period first")
+		interval = flags.Duration("interval", 5*time.Second, "the period of polls")
+	)
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *interval <= 0 {
+		return e.usageError(flags, "-interval must be positive")
+	}
+
+	c, err := e.client()
+	if err != nil {
+		return e.fail(err)
+	}
+
+	var (
+		// IDs keep seconds of the time they are given
+		from    = time.Now().Add(-*since).Truncate(time.Second)
+		newest  time.Time
+		seen    = make(map[primitive.ObjectID]struct{})
+		encoder = json.NewEncoder(e.stdout)
+		ticker  = time.NewTicker(*interval)
+	)
+	defer ticker.Stop()
+
+	if e.profile.Output == outputTable {
+		fmt.Fprintf(
+			e.stdout, "%-20s  %-24s  %-12s  %-10s  %s\n", "TIMESTAMP", "CLIENT", "LABEL", "CONFIDENCE", "INCIDENT",
+		)
+	}
+
+	for {
+		after := primitive.NewObjectIDFromTimestamp(from)
+
+		for {
+			detections, err := c.ListDetections(e.ctx, client.DetectionFilter{
+				ClientID: *clientID,
+				ZoneID:   *zoneID,
+				After:    after.Hex(),
+				Limit:    _tailPage,
+			})
+			if err != nil {
+				if e.ctx.Err() != nil {
+					return exitOK
+				}
+
+				return e.fail(err)
+			}
+
+			for _, detection := range detections {
+				after = detection.ID
+
+				if _, ok := seen[detection.ID]; ok {
+					continue
+				}
+
+				seen[detection.ID] = struct{}{}
+				if stored := detection.ID.Timestamp(); stored.After(newest) {
+					newest = stored
+				}
+
+				if e.profile.Output == outputJSON {
+					if err := encoder.Encode(detection); err != nil {
+						return e.fail(err)
+					}
+
+					continue
+				}
+
+				incident := ""
+				if !detection.IncidentID.IsZero() {
+					incident = detection.IncidentID.Hex()
+				}
+
+				fmt.Fprintf(
+					e.stdout, "%-20s  %-24s  %-12s  %-10.2f  %s\n",
+					detection.Timestamp.UTC().Format(time.RFC3339),
+					detection.ClientID.Hex(),
+					detection.Label,
+					detection.Confidence,
+					incident,
+				)
+			}
+
+			if len(detections) < _tailPage {
+				break
+			}
+		}
+
+		// the next poll starts before the newest printed detection, the ones already printed are skipped, and
+		// those stored before its start are never listed again
+		if next := newest.Add(-_tailLag); next.After(from) {
+			from = next
+		}
+		for id := range seen {
+			if id.Timestamp().Before(from) {
+				delete(seen, id)
+			}
+		}
+
+		select {
+		case <-e.ctx.Done():
+			return exitOK
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/Imm0bilize/gunshot-api-service/pkg/client"
+	"github.com/pkg/errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// config is the file of profiles, it keeps API keys, so it's readable by the owner only
+type config struct {
+	Current  string             `json:"current"`
+	Profiles map[string]profile `json:"profiles"`
+}
+
+type profile struct {
+	URL    string `json:"url"`
+	APIKey string `json:"apiKey,omitempty"`
+	Output string `json:"output,omitempty"`
+}
+
+func defaultConfigPath() string {
+	if path := os.Getenv("GUNSHOTCTL_CONFIG"); path != "" {
+		return path
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "gunshotctl.json"
+	}
+
+	return filepath.Join(dir, "gunshotctl", "config.json")
+}
+
+func loadConfig(path string) (config, error) {
+	cfg := config{Profiles: make(map[string]profile)}
+
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, errors.Wrap(err, "can't read the config")
+	}
+
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return cfg, errors.Wrapf(err, "the config %s is malformed", path)
+	}
+
+	if cfg.Profiles == nil {
+		cfg.Profiles = make(map[string]profile)
+	}
+
+	return cfg, nil
+}
+
+func saveConfig(path string, cfg config) error {
+	raw, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return errors.Wrap(err, "can't save the config")
+	}
+
+	return errors.Wrap(os.WriteFile(path, append(raw, '\n'), 0o600), "can't save the config")
+}
+
+type envParams struct {
+	configPath  string
+	profileName string
+	output      string
+	baseURL     string
+	apiKey      string
+	stdin       io.Reader
+	stdout      io.Writer
+	stderr      io.Writer
+}
+
+// env is what commands run with: the profile resolved from the config, the environment and flags
+type env struct {
+	ctx        context.Context
+	configPath string
+	config     config
+	profile    profile
+	stdin      io.Reader
+	stdout     io.Writer
+	stderr     io.Writer
+}
+
+func newEnv(ctx context.Context, params envParams) (*env, error) {
+	cfg, err := loadConfig(params.configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	name := params.profileName
+	if name == "" {
+		name = cfg.Current
+	}
+
+	p, ok := cfg.Profiles[name]
+	if params.profileName != "" && !ok {
+		return nil, fmt.Errorf("unknown profile '%s'", params.profileName)
+	}
+
+	for _, override := range []struct {
+		value  string
+		target *string
+	}{
+		{os.Getenv("GUNSHOT_URL"), &p.URL},
+		{os.Getenv("GUNSHOT_API_KEY"), &p.APIKey},
+		{params.baseURL, &p.URL},
+		{params.apiKey, &p.APIKey},
+		{params.output, &p.Output},
+	} {
+		if override.value != "" {
+			*override.target = override.value
+		}
+	}
+
+	switch p.Output {
+	case "":
+		p.Output = outputTable
+	case outputTable, outputJSON:
+	default:
+		return nil, fmt.Errorf("unknown output '%s': expected table or json", p.Output)
+	}
+
+	return &env{
+		ctx:        ctx,
+		configPath: params.configPath,
+		config:     cfg,
+		profile:    p,
+		stdin:      params.stdin,
+		stdout:     params.stdout,
+		stderr:     params.stderr,
+	}, nil
+}
+
+// client returns the SDK client of the profile
+func (e *env) client() (*client.Client, error) {
+	if e.profile.URL == "" {
+		return nil, errors.New("the address of the service is not set: use a profile, -url or GUNSHOT_URL")
+	}
+
+	return client.New(client.Config{BaseURL: e.profile.URL, APIKey: e.profile.APIKey})
+}
+
+// flags returns the flag set of the subcommand
+func (e *env) flags(name, usage string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(e.stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(e.stderr, "usage: gunshotctl "+name+" "+usage)
+		flags.PrintDefaults()
+	}
+
+	return flags
+}
+
+// render writes the value as indented JSON or as the table of rows
+func (e *env) render(v interface{}, header []string, rows [][]string) int {
+	if e.profile.Output == outputJSON {
+		encoder := json.NewEncoder(e.stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(v); err != nil {
+			return e.fail(err)
+		}
+
+		return exitOK
+	}
+
+	w := tabwriter.NewWriter(e.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+
+	if err := w.Flush(); err != nil {
+		return e.fail(err)
+	}
+
+	return exitOK
+}
+
+// fail reports the error, problems of the service are shown with their request id and violations
+func (e *env) fail(err error) int {
+	fmt.Fprintln(e.stderr, "error:", err)
+
+	var problem *client.Error
+	if errors.As(err, &problem) {
+		for _, violation := range problem.Violations() {
+			fmt.Fprintf(e.stderr, "  %s %s\n", violation.Location, violation.Error)
+		}
+
+		fmt.Fprintln(e.stderr, "request id:", problem.RequestID)
+	}
+
+	return exitFailure
+}
+
+// usageError reports misuse of the subcommand
+func (e *env) usageError(flags *flag.FlagSet, format string, args ...interface{}) int {
+	fmt.Fprintf(e.stderr, format+"\n", args...)
+	flags.Usage()
+
+	return exitUsage
+}
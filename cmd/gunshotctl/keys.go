@@ -0,0 +1,154 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"github.com/Imm0bilize/gunshot-api-service/pkg/client"
+	"os"
+	"time"
+)
+
+var _keyHeader = []string{"ID", "CREATED", "EXPIRES", "PUBLIC KEY"}
+
+func keyRow(key client.DeviceKey) []string {
+	expires := ""
+	if !key.ExpiresAt.IsZero() {
+		expires = key.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+
+	return []string{
+		key.ID, key.CreatedAt.UTC().Format(time.RFC3339), expires, base64.StdEncoding.EncodeToString(key.PublicKey),
+	}
+}
+
+func listKeys(e *env, args []string) int {
+	flags := e.flags("keys list", "<client id>")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 {
+		return e.usageError(flags, "the client id is required")
+	}
+
+	c, err := e.client()
+	if err != nil {
+		return e.fail(err)
+	}
+
+	keys, err := c.ListDeviceKeys(e.ctx, flags.Arg(0))
+	if err != nil {
+		return e.fail(err)
+	}
+
+	rows := make([][]string, 0, len(keys))
+	for _, key := range keys {
+		rows = append(rows, keyRow(key))
+	}
+
+	return e.render(keys, _keyHeader, rows)
+}
+
+// rotateKey registers the new key of the device, previous keys keep working for the overlap. Without
+// -public-key the key pair is generated and the base64 seed of the private key is written to the file
+// for the device
+func rotateKey(e *env, args []string) int {
+	flags := e.flags(
+		"keys rotate", "[-public-key <base64> | -private-key-out <file>] [-overlap <duration>] <client id>",
+	)
+
+	var (
+		publicKey  = flags.String("public-key", "", "base64 of the Ed25519 public key of the device")
+		privateOut = flags.String("private-key-out", "", "the file for the generated private key")
+		overlap    = flags.Duration("overlap", 0, "how long previous keys keep working, the service default if 0")
+	)
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 {
+		return e.usageError(flags, "the client id is required")
+	}
+	if (*publicKey == "") == (*privateOut == "") {
+		return e.usageError(flags, "either -public-key or -private-key-out is required")
+	}
+
+	var (
+		key []byte
+		err error
+	)
+
+	if *publicKey != "" {
+		if key, err = base64.StdEncoding.DecodeString(*publicKey); err != nil {
+			return e.usageError(flags, "-public-key is not base64")
+		}
+	} else {
+		if key, err = generateKey(*privateOut); err != nil {
+			return e.fail(err)
+		}
+
+		fmt.Fprintln(e.stderr, "the private key is written to", *privateOut)
+	}
+
+	c, err := e.client()
+	if err != nil {
+		return e.fail(err)
+	}
+
+	var keyOverlap *time.Duration
+	if isSet(flags, "overlap") {
+		keyOverlap = overlap
+	}
+
+	registered, err := c.RegisterDeviceKey(e.ctx, flags.Arg(0), key, keyOverlap)
+	if err != nil {
+		return e.fail(err)
+	}
+
+	return e.render(registered, _keyHeader, [][]string{keyRow(registered)})
+}
+
+// generateKey writes the seed of the new private key to the file which must not exist and returns the
+// public key
+func generateKey(path string) ([]byte, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := fmt.Fprintln(f, base64.StdEncoding.EncodeToString(private.Seed())); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	return public, f.Close()
+}
+
+func revokeKey(e *env, args []string) int {
+	flags := e.flags("keys revoke", "<client id> <key id>")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 2 {
+		return e.usageError(flags, "the client id and the key id are required")
+	}
+
+	c, err := e.client()
+	if err != nil {
+		return e.fail(err)
+	}
+
+	if err := c.RevokeDeviceKey(e.ctx, flags.Arg(0), flags.Arg(1)); err != nil {
+		return e.fail(err)
+	}
+
+	fmt.Fprintln(e.stderr, "revoked", flags.Arg(1))
+
+	return exitOK
+}
@@ -0,0 +1,153 @@
+// Command gunshotctl operates the fleet through the API of the service:
+//
+//	gunshotctl [-profile <name>] [-o table|json] <command> <subcommand> [flags] [args]
+//
+// Commands are clients, detections, dead-letters, keys, chain, evidence and profiles, 'gunshotctl help' lists
+// them. The address and the API key come from the profile of the config file, GUNSHOT_URL and GUNSHOT_API_KEY
+// override it. The exit code is 0 on success, 1 when the call fails or the verification doesn't pass and 2
+// on misuse
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+)
+
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+// command runs the subcommand with its arguments
+type command func(e *env, args []string) int
+
+var _commands = map[string]map[string]command{
+	"clients": {
+		"register": registerClient,
+		"list":     listClients,
+		"get":      getClient,
+		"update":   updateClient,
+		"delete":   deleteClient,
+		"import":   importClients,
+	},
+	"detections": {
+		"tail": tailDetections,
+	},
+	"dead-letters": {
+		"replay": replayDeadLetters,
+	},
+	"keys": {
+		"list":   listKeys,
+		"rotate": rotateKey,
+		"revoke": revokeKey,
+	},
+	"chain": {
+		"verify": verifyChain,
+	},
+	"evidence": {
+		"export": exportEvidence,
+		"verify": verifyEvidence,
+	},
+	"profiles": {
+		"list": listProfiles,
+		"set":  setProfile,
+		"use":  useProfile,
+	},
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	stop()
+
+	os.Exit(code)
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("gunshotctl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	var (
+		configPath  = flags.String("config", defaultConfigPath(), "the config file with profiles")
+		profileName = flags.String("profile", "", "the profile of the config, the current one by default")
+		output      = flags.String("o", "", "the output: table or json")
+		baseURL     = flags.String("url", "", "the address of the service, overrides the profile")
+		apiKey      = flags.String("api-key", "", "the API key of the organization, overrides the profile")
+	)
+
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: gunshotctl [flags] <command> <subcommand> [flags] [args]")
+		flags.PrintDefaults()
+		usage(stderr)
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if flags.NArg() == 0 || flags.Arg(0) == "help" {
+		flags.Usage()
+		return exitUsage
+	}
+
+	subcommands, ok := _commands[flags.Arg(0)]
+	if !ok || flags.NArg() < 2 {
+		fmt.Fprintf(stderr, "unknown command '%s'\n", flags.Arg(0))
+		usage(stderr)
+		return exitUsage
+	}
+
+	cmd, ok := subcommands[flags.Arg(1)]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command '%s %s'\n", flags.Arg(0), flags.Arg(1))
+		usage(stderr)
+		return exitUsage
+	}
+
+	e, err := newEnv(ctx, envParams{
+		configPath:  *configPath,
+		profileName: *profileName,
+		output:      *output,
+		baseURL:     *baseURL,
+		apiKey:      *apiKey,
+		stdin:       stdin,
+		stdout:      stdout,
+		stderr:      stderr,
+	})
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	return cmd(e, flags.Args()[2:])
+}
+
+func usage(w io.Writer) {
+	names := make([]string, 0, len(_commands))
+	for name := range _commands {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "commands:")
+	for _, name := range names {
+		subcommands := make([]string, 0, len(_commands[name]))
+		for subcommand := range _commands[name] {
+			subcommands = append(subcommands, subcommand)
+		}
+
+		sort.Strings(subcommands)
+
+		for _, subcommand := range subcommands {
+			fmt.Fprintf(w, "  %s %s\n", name, subcommand)
+		}
+	}
+}
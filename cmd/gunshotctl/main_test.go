@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/testserver"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const _apiKey = testserver.APIKey
+
+type fixture struct {
+	server *testserver.Server
+	config string
+}
+
+func newFixture(t *testing.T) *fixture {
+	return &fixture{server: testserver.New(t), config: filepath.Join(t.TempDir(), "config.json")}
+}
+
+// run runs gunshotctl with the config of the fixture and returns the exit code and outputs
+func (f *fixture) run(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+
+	code := run(
+		context.Background(),
+		append([]string{"-config", f.config}, args...),
+		strings.NewReader(stdin),
+		&stdout,
+		&stderr,
+	)
+
+	return code, stdout.String(), stderr.String()
+}
+
+func TestProfiles(t *testing.T) {
+	f := newFixture(t)
+
+	code, _, stderr := f.run("", "clients", "list")
+	require.Equal(t, exitFailure, code)
+	require.Contains(t, stderr, "the address of the service is not set")
+
+	code, _, _ = f.run(_apiKey+"\n", "profiles", "set", "-url", f.server.URL, "-api-key", "-", "test")
+	require.Equal(t, exitOK, code)
+
+	code, _, _ = f.run("", "profiles", "set", "-url", "http://localhost:1", "-output", "json", "other")
+	require.Equal(t, exitOK, code)
+
+	info, err := os.Stat(f.config)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	code, stdout, _ := f.run("", "-o", "json", "profiles", "list")
+	require.Equal(t, exitOK, code)
+	require.NotContains(t, stdout, _apiKey)
+
+	var profiles []profileInfo
+	require.NoError(t, json.Unmarshal([]byte(stdout), &profiles))
+	require.Equal(t, []profileInfo{
+		{Name: "other", URL: "http://localhost:1", Output: "json"},
+		{Name: "test", URL: f.server.URL, APIKey: true, Current: true},
+	}, profiles)
+
+	// the first profile is the current one
+	code, _, _ = f.run("", "clients", "list")
+	require.Equal(t, exitOK, code)
+
+	code, _, _ = f.run("", "profiles", "use", "other")
+	require.Equal(t, exitOK, code)
+
+	code, _, stderr = f.run("", "-profile", "missing", "clients", "list")
+	require.Equal(t, exitUsage, code)
+	require.Contains(t, stderr, "unknown profile 'missing'")
+}
+
+func TestClients(t *testing.T) {
+	f := newFixture(t)
+	api := []string{"-url", f.server.URL, "-api-key", _apiKey}
+
+	code, stdout, stderr := f.run(
+		"", append(api, "-o", "json", "clients", "register", "-name", "Sensor 1", "-location", "Main st.",
+			"-lat", "55.7", "-lon", "37.6")...,
+	)
+	require.Equal(t, exitOK, code, stderr)
+
+	var registered map[string]string
+	require.NoError(t, json.Unmarshal([]byte(stdout), &registered))
+	id := registered["clientID"]
+
+	code, _, stderr = f.run("", append(api, "clients", "update", "-name", "Sensor 2", id)...)
+	require.Equal(t, exitOK, code, stderr)
+
+	code, stdout, _ = f.run("", append(api, "clients", "get", id)...)
+	require.Equal(t, exitOK, code)
+	require.Contains(t, stdout, "Sensor 2")
+	require.Contains(t, stdout, "VERSION")
+
+	code, stdout, _ = f.run("", append(api, "clients", "list")...)
+	require.Equal(t, exitOK, code)
+	require.Contains(t, stdout, id)
+
+	code, _, stderr = f.run("", append(api, "clients", "register", "-name", "Sensor 3", "-location", "Side st.",
+		"-lat", "55.8", "-lon", "37.7")...)
+	require.Equal(t, exitOK, code, stderr)
+
+	// pages are requested until the last one
+	code, stdout, _ = f.run("", append(api, "-o", "json", "clients", "list", "-page", "1")...)
+	require.Equal(t, exitOK, code)
+
+	var listed []map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &listed))
+	require.Len(t, listed, 2)
+	require.Equal(t, id, listed[0]["ID"])
+
+	code, _, _ = f.run("", append(api, "clients", "delete", id)...)
+	require.Equal(t, exitOK, code)
+
+	code, _, stderr = f.run("", append(api, "clients", "get", id)...)
+	require.Equal(t, exitFailure, code)
+	require.Contains(t, stderr, "not-found: can't get client: the client is not found")
+	require.Contains(t, stderr, "request id:")
+
+	code, _, _ = f.run("", append(api, "clients", "register", "-name", "Sensor 1")...)
+	require.Equal(t, exitUsage, code)
+}
+
+func TestImportClients(t *testing.T) {
+	f := newFixture(t)
+	api := []string{"-url", f.server.URL, "-api-key", _apiKey}
+
+	csv := "fullName,locationName,latitude,longitude\nSensor 1,Main st.,55.7,37.6\n"
+
+	code, stdout, stderr := f.run(csv, append(api, "clients", "import", "-dry-run", "-")...)
+	require.Equal(t, exitOK, code, stderr)
+	require.Contains(t, stderr, "1 rows are valid")
+	require.Contains(t, stdout, "CLIENT ID")
+
+	code, stdout, stderr = f.run(csv+",Side st.,55.8,37.7\n", append(api, "clients", "import", "-")...)
+	require.Equal(t, exitFailure, code)
+	require.Contains(t, stdout, "fullName is required")
+	require.Contains(t, stderr, "1 of 2 rows are invalid")
+}
+
+func TestUsage(t *testing.T) {
+	f := newFixture(t)
+
+	for _, args := range [][]string{{}, {"help"}, {"clients"}, {"clients", "rename"}, {"-o", "yaml", "clients", "list"}} {
+		code, _, _ := f.run("", args...)
+		require.Equal(t, exitUsage, code, args)
+	}
+}
+
+func TestReplayDeadLetters(t *testing.T) {
+	f := newFixture(t)
+	admin := []string{"-url", f.server.URL, "-api-key", testserver.AdminToken}
+
+	code, _, stderr := f.run("", "-url", f.server.URL, "-api-key", _apiKey, "dead-letters", "replay", "-from", "3")
+	require.Equal(t, exitFailure, code)
+	require.Contains(t, stderr, "invalid admin token")
+
+	code, stdout, stderr := f.run("", append(admin, "dead-letters", "replay", "-from", "3", "-to", "5",
+		"-source-offset", "10,12")...)
+	require.Equal(t, exitOK, code, stderr)
+	require.Contains(t, stdout, "REPLAYED")
+	require.Contains(t, stdout, "6")
+
+	to := int64(5)
+	require.Equal(t, []entities.DeadLetterReplay{
+		{From: 3, To: &to, SourceOffsets: []int64{10, 12}},
+	}, f.server.DeadLetters.Replays())
+
+	code, _, _ = f.run("", append(admin, "dead-letters", "replay", "-to", "5")...)
+	require.Equal(t, exitUsage, code)
+
+	code, _, _ = f.run("", append(admin, "dead-letters", "replay", "-from", "3", "-source-offset", "x")...)
+	require.Equal(t, exitUsage, code)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type profileInfo struct {
+	Name    string `json:"name"`
+	URL     string `json:"url"`
+	Output  string `json:"output,omitempty"`
+	APIKey  bool   `json:"apiKey"`
+	Current bool   `json:"current"`
+}
+
+// listProfiles lists profiles of the config, API keys are never shown
+func listProfiles(e *env, args []string) int {
+	flags := e.flags("profiles list", "")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	names := make([]string, 0, len(e.config.Profiles))
+	for name := range e.config.Profiles {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var (
+		profiles = make([]profileInfo, 0, len(names))
+		rows     = make([][]string, 0, len(names))
+	)
+
+	for _, name := range names {
+		p := e.config.Profiles[name]
+		info := profileInfo{
+			Name: name, URL: p.URL, Output: p.Output, APIKey: p.APIKey != "", Current: name == e.config.Current,
+		}
+		profiles = append(profiles, info)
+
+		current := ""
+		if info.Current {
+			current = "*"
+		}
+
+		rows = append(rows, []string{current, name, p.URL, p.Output, fmt.Sprint(info.APIKey)})
+	}
+
+	return e.render(profiles, []string{"CURRENT", "NAME", "URL", "OUTPUT", "API KEY"}, rows)
+}
+
+// setProfile creates or changes the profile, the first profile becomes the current one. '-api-key -' reads
+// the key from stdin, so it's not left in the shell history
+func setProfile(e *env, args []string) int {
+	flags := e.flags("profiles set", "[-url <address>] [-api-key <key>|-] [-output table|json] <name>")
+
+	var (
+		url    = flags.String("url", "", "the address of the service")
+		apiKey = flags.String("api-key", "", "the API key of the organization, '-' reads it from stdin")
+		output = flags.String("output", "", "the default output: table or json")
+	)
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 {
+		return e.usageError(flags, "the name of the profile is required")
+	}
+	if *output != "" && *output != outputTable && *output != outputJSON {
+		return e.usageError(flags, "unknown output '%s': expected table or json", *output)
+	}
+
+	if *apiKey == "-" {
+		line, err := bufio.NewReader(e.stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return e.fail(fmt.Errorf("can't read the API key: %w", err))
+		}
+
+		*apiKey = strings.TrimSpace(line)
+	}
+
+	name := flags.Arg(0)
+
+	p := e.config.Profiles[name]
+	for _, field := range []struct {
+		value  string
+		target *string
+	}{{*url, &p.URL}, {*apiKey, &p.APIKey}, {*output, &p.Output}} {
+		if field.value != "" {
+			*field.target = field.value
+		}
+	}
+
+	e.config.Profiles[name] = p
+	if e.config.Current == "" {
+		e.config.Current = name
+	}
+
+	if err := saveConfig(e.configPath, e.config); err != nil {
+		return e.fail(err)
+	}
+
+	return exitOK
+}
+
+func useProfile(e *env, args []string) int {
+	flags := e.flags("profiles use", "<name>")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 {
+		return e.usageError(flags, "the name of the profile is required")
+	}
+
+	if _, ok := e.config.Profiles[flags.Arg(0)]; !ok {
+		return e.fail(fmt.Errorf("unknown profile '%s'", flags.Arg(0)))
+	}
+
+	e.config.Current = flags.Arg(0)
+	if err := saveConfig(e.configPath, e.config); err != nil {
+		return e.fail(err)
+	}
+
+	return exitOK
+}
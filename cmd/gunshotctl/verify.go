@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"github.com/Imm0bilize/gunshot-api-service/pkg/evidence"
+	"os"
+	"strconv"
+	"time"
+)
+
+// verifyChain checks the hash chain of uploads of the client by the service, the exit code is 1 when the
+// chain is broken
+func verifyChain(e *env, args []string) int {
+	flags := e.flags("chain verify", "[-from <RFC3339>] [-to <RFC3339>] <client id>")
+
+	var (
+		fromFlag = flags.String("from", "", "the beginning of the range, RFC3339")
+		toFlag   = flags.String("to", "", "the end of the range, RFC3339")
+	)
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 {
+		return e.usageError(flags, "the client id is required")
+	}
+
+	var from, to time.Time
+	for _, bound := range []struct {
+		value  string
+		target *time.Time
+	}{{*fromFlag, &from}, {*toFlag, &to}} {
+		if bound.value == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, bound.value)
+		if err != nil {
+			return e.usageError(flags, "invalid time %s: expected RFC3339", bound.value)
+		}
+
+		*bound.target = t
+	}
+
+	c, err := e.client()
+	if err != nil {
+		return e.fail(err)
+	}
+
+	verification, err := c.VerifyAudioChain(e.ctx, flags.Arg(0), from, to)
+	if err != nil {
+		return e.fail(err)
+	}
+
+	rows := make([][]string, 0, len(verification.Breaks))
+	for _, b := range verification.Breaks {
+		rows = append(rows, []string{strconv.FormatInt(b.Sequence, 10), string(b.Reason), b.Expected, b.Actual})
+	}
+
+	if e.profile.Output == outputTable {
+		fmt.Fprintf(
+			e.stdout, "records: %d, sequences %d..%d, valid: %t\n",
+			verification.Records, verification.FirstSequence, verification.LastSequence, verification.Valid,
+		)
+	}
+
+	if code := e.render(verification, []string{"SEQUENCE", "REASON", "EXPECTED", "ACTUAL"}, rows); code != exitOK {
+		return code
+	}
+
+	if !verification.Valid {
+		return exitFailure
+	}
+
+	return exitOK
+}
+
+func exportEvidence(e *env, args []string) int {
+	flags := e.flags("evidence export", "-out <bundle.zip> <incident id>")
+	out := flags.String("out", "", "the file of the bundle")
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 || *out == "" {
+		return e.usageError(flags, "the incident id and -out are required")
+	}
+
+	c, err := e.client()
+	if err != nil {
+		return e.fail(err)
+	}
+
+	f, err := os.OpenFile(*out, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return e.fail(err)
+	}
+
+	if err := c.ExportEvidence(e.ctx, flags.Arg(0), f); err != nil {
+		_ = f.Close()
+		_ = os.Remove(*out)
+		return e.fail(err)
+	}
+
+	if err := f.Close(); err != nil {
+		return e.fail(err)
+	}
+
+	fmt.Fprintln(e.stderr, "the bundle is written to", *out)
+
+	return exitOK
+}
+
+// verifyEvidence checks the evidence bundle offline, the exit code is 1 for the invalid bundle and for the
+// bundle checked without -key, since anyone can sign a forged bundle with their own key
+func verifyEvidence(e *env, args []string) int {
+	flags := e.flags("evidence verify", "[-key <base64 public key>] <bundle.zip>")
+	key := flags.String("key", "", "base64 encoded public key the bundle must be signed with")
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 {
+		return e.usageError(flags, "the bundle is required")
+	}
+
+	f, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return e.fail(err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return e.fail(err)
+	}
+
+	report, err := evidence.Verify(f, info.Size(), *key)
+	if err != nil {
+		return e.fail(err)
+	}
+
+	rows := [][]string{{
+		strconv.FormatBool(report.Valid), strconv.FormatBool(report.Trusted), strconv.Itoa(report.Files),
+		strconv.Itoa(report.Clips), report.PublicKey,
+	}}
+
+	header := []string{"VALID", "TRUSTED", "FILES", "CLIPS", "PUBLIC KEY"}
+	if code := e.render(report, header, rows); code != exitOK {
+		return code
+	}
+
+	if !report.Valid {
+		if e.profile.Output == outputTable {
+			for _, problem := range report.Problems {
+				fmt.Fprintln(e.stderr, problem)
+			}
+		}
+
+		return exitFailure
+	}
+
+	if !report.Trusted {
+		fmt.Fprintln(e.stderr, "the bundle is intact, but it's not trusted: pass -key with the key of the service")
+		return exitFailure
+	}
+
+	return exitOK
+}
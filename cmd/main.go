@@ -4,9 +4,14 @@ import (
 	"github.com/Imm0bilize/gunshot-api-service/internal/app"
 	"github.com/Imm0bilize/gunshot-api-service/internal/config"
 	"log"
+	"os"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "verify-evidence" {
+		os.Exit(verifyEvidence(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	cfg, err := config.New(".env.public", ".env.private")
 	if err != nil {
 		log.Fatal(err)
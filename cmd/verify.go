@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/Imm0bilize/gunshot-api-service/pkg/evidence"
+	"io"
+	"os"
+)
+
+// verifyEvidence checks the evidence bundle offline:
+//
+//	verify-evidence [-key <base64 public key>] bundle.zip
+//
+// The exit code is 0 for the valid bundle signed with the key, 1 for the invalid one, 2 when it can't be read
+// and 3 for the intact bundle checked without -key, since anyone can sign a forged bundle with their own key
+func verifyEvidence(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("verify-evidence", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	key := flags.String("key", "", "base64 encoded public key the bundle must be signed with")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintln(stderr, "usage: verify-evidence [-key <public key>] <bundle.zip>")
+		return 2
+	}
+
+	f, err := os.Open(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	report, err := evidence.Verify(f, info.Size(), *key)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	if !report.Valid {
+		return 1
+	}
+
+	if !report.Trusted {
+		fmt.Fprintln(stderr, "the bundle is intact, but it's not trusted: pass -key with the public key of the service")
+		return 3
+	}
+
+	return 0
+}
@@ -5,8 +5,12 @@ import (
 	"fmt"
 	"github.com/Imm0bilize/gunshot-api-service/internal/config"
 	"github.com/Imm0bilize/gunshot-api-service/internal/controller/http"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
 	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/msbroker"
 	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository"
+	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/webhooks"
+	"github.com/Imm0bilize/gunshot-api-service/internal/kms"
+	"github.com/Imm0bilize/gunshot-api-service/internal/signing"
 	"github.com/Imm0bilize/gunshot-api-service/internal/uCase"
 	"github.com/Shopify/sarama"
 	"github.com/pkg/errors"
@@ -50,7 +54,7 @@ func createTraceProvider(cfg config.OTELConfig) func(context.Context) error {
 		),
 	)
 	if err != nil {
-		log.Printf("Could not set resources: ", err)
+		log.Printf("Could not set resources: %v", err)
 	}
 
 	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}))
@@ -80,6 +84,31 @@ func createKafkaProducer(cfg config.KafkaConfig) (sarama.SyncProducer, error) {
 	return producer, nil
 }
 
+func createKafkaConsumer(cfg config.KafkaConfig) (sarama.Consumer, error) {
+	kfkCfg := sarama.NewConfig()
+	kfkCfg.Version = sarama.V3_3_0_0
+
+	consumer, err := sarama.NewConsumer(strings.Split(cfg.Peers, ","), kfkCfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "error during create consumer")
+	}
+
+	return consumer, nil
+}
+
+func createKafkaConsumerGroup(cfg config.KafkaConfig) (sarama.ConsumerGroup, error) {
+	kfkCfg := sarama.NewConfig()
+	kfkCfg.Version = sarama.V3_3_0_0
+	kfkCfg.Consumer.Offsets.Initial = sarama.OffsetOldest
+
+	group, err := sarama.NewConsumerGroup(strings.Split(cfg.Peers, ","), cfg.Group, kfkCfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "error during create consumer group")
+	}
+
+	return group, nil
+}
+
 func createDB(cfg config.DBConfig) (*mongo.Database, func(context.Context) error, error) {
 	clientOptions := options.Client()
 	clientOptions.Monitor = otelmongo.NewMonitor()
@@ -118,21 +147,104 @@ func Run(cfg *config.Config) {
 
 	// DB
 	db, dbShutdown, err := createDB(cfg.DB)
+	if err != nil {
+		logger.Fatal("error when connecting to the database", zap.Error(err))
+	}
 	logger.Debug("successfully connected to the database")
 
+	if err := repository.EnsureIndexes(ctx, db); err != nil {
+		logger.Fatal("error when creating indexes", zap.Error(err))
+	}
+
+	// Broker, dead letters of the consumer are sent by the same producer
 	producer, err := createKafkaProducer(cfg.Kafka)
 	if err != nil {
+		logger.Fatal("error when creating producer", zap.Error(err))
+	}
+	broker := msbroker.NewKafkaProducer(
+		logger,
+		producer,
+		msbroker.Topics{
+			Audio:       cfg.Kafka.Topic,
+			Incident:    cfg.Kafka.IncidentTopic,
+			Alert:       cfg.Kafka.AlertTopic,
+			Maintenance: cfg.Kafka.MaintenanceTopic,
+		},
+	)
 
+	var signer *signing.Signer
+	if cfg.Audit.SigningKey != "" {
+		if signer, err = signing.NewSigner(cfg.Audit.SigningKey); err != nil {
+			logger.Fatal("error when creating signer", zap.Error(err))
+		}
+	}
+
+	// the audio is encrypted by the store, so every path to it decrypts transparently
+	repo := repository.NewRepo(db)
+	var encryptedBlobs uCase.RewrapRepo
+	if !cfg.Encryption.Disabled {
+		keys, created, err := kms.LoadOrCreateKeyfile(cfg.Encryption.Keyfile)
+		if err != nil {
+			logger.Fatal("error when loading keyfile", zap.Error(err))
+		}
+		if created {
+			logger.Warn(
+				"the keyfile is created, back it up: the audio can't be decrypted without it",
+				zap.String("keyfile", cfg.Encryption.Keyfile),
+			)
+		}
+
+		blobs := repository.NewEncryptedBlobRepo(repo.Blob, keys)
+		repo.Blob, encryptedBlobs = blobs, blobs
+	} else {
+		logger.Warn("the audio is stored unencrypted since ENCRYPTION_DISABLED is set")
+	}
+
+	// dead letters are replayed by admins into the detections topic
+	var deadLetters *msbroker.DeadLetters
+	var deadLetterQueue uCase.DeadLetterQueue
+	if cfg.Kafka.DeadLetterTopic != "" {
+		consumer, err := createKafkaConsumer(cfg.Kafka)
+		if err != nil {
+			logger.Fatal("error when creating consumer of dead letters", zap.Error(err))
+		}
+
+		deadLetters = msbroker.NewDeadLetters(
+			logger, consumer, producer, cfg.Kafka.DetectionTopic, cfg.Kafka.DeadLetterTopic,
+		)
+		deadLetterQueue = deadLetters
 	}
-	// Broker
-	broker := msbroker.NewKafkaProducer(logger, producer, cfg.Kafka.Topic)
 
 	// domain service
 	params := uCase.Params{
-		Logger:      logger,
-		Repo:        repository.NewRepo(db),
-		AudioSender: broker,
-		AudioLength: 1000,
+		Logger:         logger,
+		Repo:           repo,
+		AudioSender:    broker,
+		Publisher:      broker,
+		AudioLength:    1000,
+		IncidentRadius: cfg.Incident.Radius,
+		IncidentWindow: cfg.Incident.Window,
+		DegradedAfter:  cfg.Health.DegradedAfter,
+		OfflineAfter:   cfg.Health.OfflineAfter,
+		CommandTTL:     cfg.Command.TTL,
+		CommandAck:     cfg.Command.AckTimeout,
+		ClockMaxSkew:   cfg.Clock.MaxSkew,
+		ClockMaxDrift:  cfg.Clock.MaxDrift,
+		AuditSigner:    signer,
+
+		SignatureRequired: cfg.Device.SignatureRequired,
+		KeyOverlap:        cfg.Device.KeyOverlap,
+
+		Retention: entities.Retention{
+			AudioDays:     cfg.Retention.AudioDays,
+			DetectionDays: cfg.Retention.DetectionDays,
+			HeartbeatDays: cfg.Retention.HeartbeatDays,
+		},
+		SweepInterval:  cfg.Retention.SweepInterval,
+		DeleteGrace:    cfg.Client.DeleteGrace,
+		EncryptedBlobs: encryptedBlobs,
+		WebhookSender:  webhooks.NewHTTPSender(cfg.Webhook.Timeout),
+		DeadLetters:    deadLetterQueue,
 	}
 
 	useCase, err := uCase.NewUseCase(params)
@@ -141,8 +253,28 @@ func Run(cfg *config.Config) {
 		logger.Fatal("error when creating business logic of the service", zap.Error(err))
 	}
 
+	// Detections
+	consumerGroup, err := createKafkaConsumerGroup(cfg.Kafka)
+	if err != nil {
+		logger.Fatal("error when creating consumer group", zap.Error(err))
+	}
+	consumer := msbroker.NewKafkaConsumer(
+		logger, consumerGroup, producer, cfg.Kafka.DetectionTopic, cfg.Kafka.DeadLetterTopic, useCase.Detection,
+	)
+
+	consumerCtx, stopConsumer := context.WithCancel(ctx)
+	go consumer.Run(consumerCtx)
+
+	// Fleet health, command expiration, retention of the audio, rewrap of its data keys and purge of deleted clients
+	monitorCtx, stopMonitor := context.WithCancel(ctx)
+	go useCase.Fleet.Monitor(monitorCtx, cfg.Health.CheckInterval)
+	go useCase.Command.Monitor(monitorCtx, cfg.Command.ExpireInterval)
+	go useCase.Retention.Monitor(monitorCtx, cfg.Retention.SweepInterval)
+	go useCase.KeyRotation.Monitor(monitorCtx, cfg.Encryption.RewrapInterval)
+	go useCase.Client.Monitor(monitorCtx, cfg.Client.PurgeInterval)
+
 	//http server
-	httpServer := http.NewHTTPServer(logger, useCase)
+	httpServer := http.NewHTTPServer(logger, useCase, cfg.Auth.AdminToken)
 
 	go func() {
 		if err := httpServer.Run(fmt.Sprintf(":%s", cfg.HTTP.Port)); err != nil {
@@ -151,13 +283,25 @@ func Run(cfg *config.Config) {
 	}()
 
 	// Shutdown
-	shutdown := make(chan os.Signal)
+	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
 	<-shutdown
 
 	ctx, shutdownFunc := context.WithTimeout(context.Background(), time.Second*10)
 	defer shutdownFunc()
 
+	stopMonitor()
+	stopConsumer()
+	if err = consumer.Shutdown(); err != nil {
+		logger.Error("error when shutting down consumer", zap.Error(err))
+	}
+
+	if deadLetters != nil {
+		if err = deadLetters.Shutdown(); err != nil {
+			logger.Error("error when shutting down consumer of dead letters", zap.Error(err))
+		}
+	}
+
 	if err = dbShutdown(ctx); err != nil {
 		logger.Error("error when closing database connection", zap.Error(err))
 	}
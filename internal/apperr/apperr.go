@@ -0,0 +1,77 @@
+// Package apperr is the taxonomy of domain errors. Sentinel errors of use cases and repositories carry
+// the kind, the transport maps kinds to its statuses
+package apperr
+
+import "errors"
+
+type Kind string
+
+const (
+	// Internal is the kind of errors without one, their messages are not shown to callers
+	Internal        Kind = "internal"
+	NotFound        Kind = "not-found"
+	InvalidArgument Kind = "invalid-argument"
+	Conflict        Kind = "conflict"
+	// Unavailable errors may pass on retry
+	Unavailable Kind = "unavailable"
+	// FailedPrecondition is the stale version of the conditional request
+	FailedPrecondition   Kind = "failed-precondition"
+	PreconditionRequired Kind = "precondition-required"
+	Unauthenticated      Kind = "unauthenticated"
+	PermissionDenied     Kind = "permission-denied"
+	ResourceExhausted    Kind = "resource-exhausted"
+	UnsupportedMedia     Kind = "unsupported-media"
+	// Unimplemented is the feature disabled by the configuration
+	Unimplemented Kind = "unimplemented"
+)
+
+// Error is the error of the kind, Details are shown to callers along with the message
+type Error struct {
+	Kind    Kind
+	Msg     string
+	Details interface{}
+	err     error
+}
+
+func (e *Error) Error() string {
+	return e.Msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// New returns the sentinel error of the kind
+func New(kind Kind, msg string) error {
+	return &Error{Kind: kind, Msg: msg}
+}
+
+// Wrap gives the kind to the error keeping its message, the outer kind wins
+func Wrap(kind Kind, err error) error {
+	return &Error{Kind: kind, Msg: err.Error(), err: err}
+}
+
+// WithDetails returns the error of the kind with details, e.g. errors of rows
+func WithDetails(kind Kind, msg string, details interface{}) error {
+	return &Error{Kind: kind, Msg: msg, Details: details}
+}
+
+// KindOf returns the kind of the outermost typed error of the chain
+func KindOf(err error) Kind {
+	var typed *Error
+	if errors.As(err, &typed) {
+		return typed.Kind
+	}
+
+	return Internal
+}
+
+// DetailsOf returns details of the outermost typed error of the chain
+func DetailsOf(err error) interface{} {
+	var typed *Error
+	if errors.As(err, &typed) {
+		return typed.Details
+	}
+
+	return nil
+}
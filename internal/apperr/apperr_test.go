@@ -0,0 +1,46 @@
+package apperr_test
+
+import (
+	"fmt"
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestKindOf(t *testing.T) {
+	var (
+		notFound = apperr.New(apperr.NotFound, "the client is not found")
+		quota    = apperr.New(apperr.PermissionDenied, "the quota is exceeded")
+	)
+
+	testTable := []struct {
+		name    string
+		err     error
+		expKind apperr.Kind
+	}{
+		{name: "sentinel", err: notFound, expKind: apperr.NotFound},
+		{name: "wrapped by pkg/errors", err: errors.Wrap(notFound, "can't get the client"), expKind: apperr.NotFound},
+		{name: "wrapped by fmt", err: fmt.Errorf("%w: 10 sensors", quota), expKind: apperr.PermissionDenied},
+		{
+			name:    "outer kind wins",
+			err:     apperr.Wrap(apperr.ResourceExhausted, fmt.Errorf("%w: 10 uploads", quota)),
+			expKind: apperr.ResourceExhausted,
+		},
+		{name: "untyped", err: errors.New("connection refused"), expKind: apperr.Internal},
+	}
+
+	for _, tCase := range testTable {
+		t.Run(tCase.name, func(t *testing.T) {
+			require.Equal(t, tCase.expKind, apperr.KindOf(tCase.err))
+		})
+	}
+}
+
+func TestWrapKeepsIdentity(t *testing.T) {
+	quota := apperr.New(apperr.PermissionDenied, "the quota is exceeded")
+	err := apperr.Wrap(apperr.ResourceExhausted, fmt.Errorf("%w: 10 uploads", quota))
+
+	require.ErrorIs(t, err, quota)
+	require.Equal(t, "the quota is exceeded: 10 uploads", err.Error())
+}
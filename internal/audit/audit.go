@@ -0,0 +1,93 @@
+// Package audit carries the audit entry of the request being processed.
+//
+// The HTTP layer starts the entry for every mutating call and records the outcome, use cases complete it
+// with the target and the change of the entity when they know them.
+package audit
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"time"
+)
+
+type key struct{}
+
+// WithEntry attaches the entry of the request to the context
+func WithEntry(ctx context.Context, entry *entities.AuditEntry) context.Context {
+	return context.WithValue(ctx, key{}, entry)
+}
+
+// Entry returns the entry of the request, false means the request is not audited
+func Entry(ctx context.Context) (*entities.AuditEntry, bool) {
+	entry, ok := ctx.Value(key{}).(*entities.AuditEntry)
+	return entry, ok
+}
+
+// Active reports whether the request is audited, so the use case has to collect the change
+func Active(ctx context.Context) bool {
+	_, ok := Entry(ctx)
+	return ok
+}
+
+// SetTargetID sets the id of the target, e.g. when it's created by the request
+func SetTargetID(ctx context.Context, id string) {
+	if entry, ok := Entry(ctx); ok {
+		entry.Target.ID = id
+	}
+}
+
+// Change records the difference between states of the target
+func Change(ctx context.Context, before, after interface{}) error {
+	entry, ok := Entry(ctx)
+	if !ok {
+		return nil
+	}
+
+	changes, err := entities.Diff(before, after)
+	if err != nil {
+		return err
+	}
+
+	entry.Changes = changes
+
+	return nil
+}
+
+// Append adds the difference between states of one of targets of the request, e.g. of a bulk write, the fields
+// are prefixed by the id of the target
+func Append(ctx context.Context, id string, before, after interface{}) error {
+	entry, ok := Entry(ctx)
+	if !ok {
+		return nil
+	}
+
+	changes, err := entities.Diff(before, after)
+	if err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		change.Field = id + "." + change.Field
+		entry.Changes = append(entry.Changes, change)
+	}
+
+	return nil
+}
+
+// Detach keeps values of the context (tenant, audit entry, span) but not its cancellation, so the entry
+// is saved even if the caller has gone
+func Detach(ctx context.Context) context.Context {
+	return detached{parent: ctx}
+}
+
+type detached struct {
+	parent context.Context
+}
+
+func (detached) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+func (detached) Done() <-chan struct{} { return nil }
+
+func (detached) Err() error { return nil }
+
+func (d detached) Value(key interface{}) interface{} { return d.parent.Value(key) }
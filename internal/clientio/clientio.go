@@ -0,0 +1,70 @@
+// Package clientio reads and writes clients in files of GIS tools: CSV, GeoJSON and KML
+package clientio
+
+import (
+	"fmt"
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"io"
+)
+
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatGeoJSON Format = "geojson"
+	FormatKML     Format = "kml"
+)
+
+var (
+	ErrUnknownFormat = apperr.New(apperr.InvalidArgument, "unknown format")
+	ErrMalformed     = apperr.New(apperr.InvalidArgument, "the file is malformed")
+)
+
+// Row is the client read from the record of the file, Number counts records from 1 without the CSV header.
+// Err tells why the record can't be read
+type Row struct {
+	Number int
+	Client entities.Client
+	Err    error
+}
+
+// ContentType returns the media type of the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv"
+	case FormatGeoJSON:
+		return "application/geo+json"
+	case FormatKML:
+		return "application/vnd.google-earth.kml+xml"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// Read returns rows of the file, only CSV and GeoJSON may be imported
+func Read(format Format, r io.Reader) ([]Row, error) {
+	switch format {
+	case FormatCSV:
+		return ReadCSV(r)
+	case FormatGeoJSON:
+		return ReadGeoJSON(r)
+	default:
+		return nil, fmt.Errorf("%w: '%s' can't be imported", ErrUnknownFormat, format)
+	}
+}
+
+// Write writes clients in the format
+func Write(format Format, w io.Writer, clients []entities.Client) error {
+	switch format {
+	case FormatCSV:
+		return WriteCSV(w, clients)
+	case FormatGeoJSON:
+		return WriteGeoJSON(w, clients)
+	case FormatKML:
+		return WriteKML(w, clients)
+	default:
+		return fmt.Errorf("%w: '%s'", ErrUnknownFormat, format)
+	}
+}
@@ -0,0 +1,105 @@
+package clientio_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"github.com/Imm0bilize/gunshot-api-service/internal/clientio"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"strings"
+	"testing"
+)
+
+var testClients = []entities.Client{
+	{ID: primitive.NewObjectID(), FullName: "North", LocationName: "station, 1", Latitude: 55.75, Longitude: 37.61},
+	{ID: primitive.NewObjectID(), FullName: "Null island", LocationName: "sea", Latitude: 0, Longitude: 0},
+}
+
+func TestReadCSV(t *testing.T) {
+	input := "Latitude,longitude,fullName,locationName,notes\n" +
+		"55.75,37.61,North,station,x\n" +
+		"0,0,Null island,sea\n" +
+		"north,37.61,Broken,station\n" +
+		",37.61,Missing,station\n"
+
+	rows, err := clientio.ReadCSV(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+
+	require.NoError(t, rows[0].Err)
+	require.Equal(t, 1, rows[0].Number)
+	require.Equal(t, entities.Client{FullName: "North", LocationName: "station", Latitude: 55.75, Longitude: 37.61}, rows[0].Client)
+	require.NoError(t, rows[1].Err)
+	require.Zero(t, rows[1].Client.Latitude)
+	require.EqualError(t, rows[2].Err, "'latitude' must be a number")
+	require.Equal(t, 3, rows[2].Number)
+	require.EqualError(t, rows[3].Err, "'latitude' is required")
+
+	_, err = clientio.ReadCSV(strings.NewReader("fullName,latitude\nx,1\n"))
+	require.ErrorIs(t, err, clientio.ErrMalformed)
+}
+
+func TestReadGeoJSON(t *testing.T) {
+	input := `{"type": "FeatureCollection", "features": [
+		{"type": "Feature", "geometry": {"type": "Point", "coordinates": [37.61, 55.75]},
+		 "properties": {"fullName": "North", "locationName": "station"}},
+		{"type": "Feature", "geometry": {"type": "LineString", "coordinates": [[0, 0], [1, 1]]}, "properties": {}},
+		{"type": "Feature", "geometry": null, "properties": {}}
+	]}`
+
+	rows, err := clientio.ReadGeoJSON(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+
+	require.NoError(t, rows[0].Err)
+	require.Equal(t, entities.Client{FullName: "North", LocationName: "station", Latitude: 55.75, Longitude: 37.61}, rows[0].Client)
+	require.Error(t, rows[1].Err)
+	require.Equal(t, 2, rows[1].Number)
+	require.Error(t, rows[2].Err)
+
+	_, err = clientio.ReadGeoJSON(strings.NewReader(`{"type": "Feature"}`))
+	require.ErrorIs(t, err, clientio.ErrMalformed)
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, format := range []clientio.Format{clientio.FormatCSV, clientio.FormatGeoJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, clientio.Write(format, &buf, testClients))
+
+			rows, err := clientio.Read(format, &buf)
+			require.NoError(t, err)
+			require.Len(t, rows, len(testClients))
+
+			for i, row := range rows {
+				require.NoError(t, row.Err)
+				require.Equal(t, testClients[i].FullName, row.Client.FullName)
+				require.Equal(t, testClients[i].LocationName, row.Client.LocationName)
+				require.Equal(t, testClients[i].Latitude, row.Client.Latitude)
+				require.Equal(t, testClients[i].Longitude, row.Client.Longitude)
+			}
+		})
+	}
+}
+
+func TestWriteKML(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, clientio.WriteKML(&buf, testClients))
+
+	var document struct {
+		Placemarks []struct {
+			ID          string `xml:"id,attr"`
+			Name        string `xml:"name"`
+			Coordinates string `xml:"Point>coordinates"`
+		} `xml:"Document>Placemark"`
+	}
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &document))
+	require.Len(t, document.Placemarks, 2)
+	require.Equal(t, testClients[0].ID.Hex(), document.Placemarks[0].ID)
+	require.Equal(t, "North", document.Placemarks[0].Name)
+	require.Equal(t, "37.61,55.75", document.Placemarks[0].Coordinates)
+
+	_, err := clientio.Read(clientio.FormatKML, &buf)
+	require.ErrorIs(t, err, clientio.ErrUnknownFormat)
+}
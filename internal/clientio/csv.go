@@ -0,0 +1,124 @@
+package clientio
+
+import (
+	"encoding/csv"
+	"fmt"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// columns of the CSV file, other columns are ignored on import, so exported files may be imported back
+const (
+	ColumnID           = "id"
+	ColumnFullName     = "fullName"
+	ColumnLocationName = "locationName"
+	ColumnLatitude     = "latitude"
+	ColumnLongitude    = "longitude"
+)
+
+var _csvRequired = []string{ColumnFullName, ColumnLocationName, ColumnLatitude, ColumnLongitude}
+
+// ReadCSV reads clients of the CSV file with the header, names of columns are case-insensitive
+func ReadCSV(r io.Reader) ([]Row, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: can't read the header: %s", ErrMalformed, err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range _csvRequired {
+		if _, ok := columns[strings.ToLower(name)]; !ok {
+			return nil, fmt.Errorf("%w: column '%s' is required", ErrMalformed, name)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		i := columns[strings.ToLower(name)]
+		if i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	rows := make([]Row, 0)
+	for number := 1; ; number++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// quoting errors leave the reader at the next line
+			if _, ok := err.(*csv.ParseError); ok {
+				rows = append(rows, Row{Number: number, Err: err})
+				continue
+			}
+			return nil, fmt.Errorf("%w: %s", ErrMalformed, err)
+		}
+
+		row := Row{
+			Number: number,
+			Client: entities.Client{
+				FullName:     field(record, ColumnFullName),
+				LocationName: field(record, ColumnLocationName),
+			},
+		}
+
+		if row.Client.Latitude, err = parseCoordinate(field(record, ColumnLatitude), ColumnLatitude); err != nil {
+			row.Err = err
+		} else if row.Client.Longitude, err = parseCoordinate(field(record, ColumnLongitude), ColumnLongitude); err != nil {
+			row.Err = err
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func parseCoordinate(value, name string) (float64, error) {
+	if value == "" {
+		return 0, fmt.Errorf("'%s' is required", name)
+	}
+
+	coordinate, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("'%s' must be a number", name)
+	}
+
+	return coordinate, nil
+}
+
+// WriteCSV writes clients with the header
+func WriteCSV(w io.Writer, clients []entities.Client) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(
+		[]string{ColumnID, ColumnFullName, ColumnLocationName, ColumnLatitude, ColumnLongitude},
+	); err != nil {
+		return err
+	}
+
+	for _, client := range clients {
+		if err := writer.Write([]string{
+			client.ID.Hex(),
+			client.FullName,
+			client.LocationName,
+			strconv.FormatFloat(client.Latitude, 'f', -1, 64),
+			strconv.FormatFloat(client.Longitude, 'f', -1, 64),
+		}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
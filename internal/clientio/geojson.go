@@ -0,0 +1,92 @@
+package clientio
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"io"
+)
+
+// feature is the GeoJSON feature of the client, positions are [longitude, latitude] (RFC 7946)
+type feature struct {
+	Type       string            `json:"type"`
+	ID         string            `json:"id,omitempty"`
+	Geometry   *point            `json:"geometry"`
+	Properties featureProperties `json:"properties"`
+}
+
+type point struct {
+	Type        string    `json:"type"`
+	Coordinates []float64 `json:"coordinates"`
+}
+
+type featureProperties struct {
+	FullName     string `json:"fullName"`
+	LocationName string `json:"locationName"`
+}
+
+type featureCollection struct {
+	Type     string            `json:"type"`
+	Features []json.RawMessage `json:"features"`
+}
+
+// ReadGeoJSON reads clients of the FeatureCollection with Point geometries
+func ReadGeoJSON(r io.Reader) ([]Row, error) {
+	var collection featureCollection
+	if err := json.NewDecoder(r).Decode(&collection); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrMalformed, err)
+	}
+	if collection.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("%w: type must be 'FeatureCollection', got '%s'", ErrMalformed, collection.Type)
+	}
+
+	rows := make([]Row, 0, len(collection.Features))
+	for i, raw := range collection.Features {
+		row := Row{Number: i + 1}
+
+		var f feature
+		if err := json.Unmarshal(raw, &f); err != nil {
+			row.Err = fmt.Errorf("invalid feature: %s", err)
+			rows = append(rows, row)
+			continue
+		}
+
+		row.Client = entities.Client{FullName: f.Properties.FullName, LocationName: f.Properties.LocationName}
+
+		switch {
+		case f.Type != "Feature":
+			row.Err = fmt.Errorf("type must be 'Feature', got '%s'", f.Type)
+		case f.Geometry == nil || f.Geometry.Type != "Point":
+			row.Err = fmt.Errorf("geometry must be 'Point'")
+		case len(f.Geometry.Coordinates) < 2:
+			row.Err = fmt.Errorf("position must be [longitude, latitude]")
+		default:
+			row.Client.Longitude, row.Client.Latitude = f.Geometry.Coordinates[0], f.Geometry.Coordinates[1]
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// WriteGeoJSON writes clients as the FeatureCollection
+func WriteGeoJSON(w io.Writer, clients []entities.Client) error {
+	features := make([]feature, 0, len(clients))
+	for _, client := range clients {
+		features = append(features, feature{
+			Type: "Feature",
+			ID:   client.ID.Hex(),
+			Geometry: &point{
+				Type:        "Point",
+				Coordinates: []float64{client.Longitude, client.Latitude},
+			},
+			Properties: featureProperties{FullName: client.FullName, LocationName: client.LocationName},
+		})
+	}
+
+	return json.NewEncoder(w).Encode(struct {
+		Type     string    `json:"type"`
+		Features []feature `json:"features"`
+	}{Type: "FeatureCollection", Features: features})
+}
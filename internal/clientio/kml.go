@@ -0,0 +1,57 @@
+package clientio
+
+import (
+	"encoding/xml"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"io"
+	"strconv"
+)
+
+type kml struct {
+	XMLName  xml.Name    `xml:"http://www.opengis.net/kml/2.2 kml"`
+	Document kmlDocument `xml:"Document"`
+}
+
+type kmlDocument struct {
+	Name       string         `xml:"name"`
+	Placemarks []kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlPlacemark struct {
+	ID          string   `xml:"id,attr"`
+	Name        string   `xml:"name"`
+	Description string   `xml:"description"`
+	Point       kmlPoint `xml:"Point"`
+}
+
+type kmlPoint struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+// WriteKML writes clients as placemarks, the name is the full name and the description is the location name
+func WriteKML(w io.Writer, clients []entities.Client) error {
+	document := kml{Document: kmlDocument{Name: "clients", Placemarks: make([]kmlPlacemark, 0, len(clients))}}
+	for _, client := range clients {
+		document.Document.Placemarks = append(document.Document.Placemarks, kmlPlacemark{
+			ID:          client.ID.Hex(),
+			Name:        client.FullName,
+			Description: client.LocationName,
+			Point: kmlPoint{
+				Coordinates: strconv.FormatFloat(client.Longitude, 'f', -1, 64) + "," +
+					strconv.FormatFloat(client.Latitude, 'f', -1, 64),
+			},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return err
+	}
+
+	return encoder.Flush()
+}
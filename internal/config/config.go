@@ -4,6 +4,7 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
 	"github.com/pkg/errors"
+	"time"
 )
 
 type DBConfig struct {
@@ -28,16 +29,95 @@ type OTELConfig struct {
 }
 
 type KafkaConfig struct {
-	Peers string `env:"KAFKA_PEERS"`
-	Topic string `env:"KAFKA_TOPIC"`
+	Peers            string `env:"KAFKA_PEERS"`
+	Topic            string `env:"KAFKA_TOPIC"`
+	DetectionTopic   string `env:"KAFKA_DETECTION_TOPIC" split_words:"true"`
+	IncidentTopic    string `env:"KAFKA_INCIDENT_TOPIC" split_words:"true"`
+	AlertTopic       string `env:"KAFKA_ALERT_TOPIC" split_words:"true"`
+	MaintenanceTopic string `env:"KAFKA_MAINTENANCE_TOPIC" split_words:"true"`
+	DeadLetterTopic  string `env:"KAFKA_DEAD_LETTER_TOPIC" split_words:"true"`
+	Group            string `env:"KAFKA_GROUP"`
+}
+
+type IncidentConfig struct {
+	Radius float64       `env:"INCIDENT_RADIUS" default:"500"`
+	Window time.Duration `env:"INCIDENT_WINDOW" default:"1m"`
+}
+
+type HealthConfig struct {
+	DegradedAfter time.Duration `env:"HEALTH_DEGRADED_AFTER" split_words:"true" default:"2m"`
+	OfflineAfter  time.Duration `env:"HEALTH_OFFLINE_AFTER" split_words:"true" default:"10m"`
+	CheckInterval time.Duration `env:"HEALTH_CHECK_INTERVAL" split_words:"true" default:"1m"`
+}
+
+type CommandConfig struct {
+	TTL            time.Duration `env:"COMMAND_TTL" default:"10m"`
+	ExpireInterval time.Duration `env:"COMMAND_EXPIRE_INTERVAL" split_words:"true" default:"30s"`
+	AckTimeout     time.Duration `env:"COMMAND_ACK_TIMEOUT" split_words:"true" default:"1m"`
+}
+
+// ClientConfig is the grace period of deleted clients, they may be restored until the purge
+type ClientConfig struct {
+	DeleteGrace   time.Duration `env:"CLIENT_DELETE_GRACE" split_words:"true" default:"720h"`
+	PurgeInterval time.Duration `env:"CLIENT_PURGE_INTERVAL" split_words:"true" default:"1h"`
+}
+
+type ClockConfig struct {
+	MaxSkew  time.Duration `env:"CLOCK_MAX_SKEW" split_words:"true" default:"2s"`
+	MaxDrift float64       `env:"CLOCK_MAX_DRIFT" split_words:"true" default:"100"`
+}
+
+type AuthConfig struct {
+	AdminToken string `env:"AUTH_ADMIN_TOKEN" split_words:"true"`
+}
+
+type AuditConfig struct {
+	SigningKey string `env:"AUDIT_SIGNING_KEY" split_words:"true"`
+}
+
+type DeviceConfig struct {
+	KeyOverlap        time.Duration `env:"DEVICE_KEY_OVERLAP" split_words:"true" default:"24h"`
+	SignatureRequired bool          `env:"DEVICE_SIGNATURE_REQUIRED" split_words:"true" default:"false"`
+}
+
+// RetentionConfig is the retention (days) of organizations which have not set their own, zero keeps forever
+type RetentionConfig struct {
+	AudioDays     int           `env:"RETENTION_AUDIO_DAYS" split_words:"true" default:"30"`
+	DetectionDays int           `env:"RETENTION_DETECTION_DAYS" split_words:"true" default:"365"`
+	HeartbeatDays int           `env:"RETENTION_HEARTBEAT_DAYS" split_words:"true" default:"7"`
+	SweepInterval time.Duration `env:"RETENTION_SWEEP_INTERVAL" split_words:"true" default:"1h"`
+}
+
+// EncryptionConfig sets up the envelope encryption of the stored audio with the local keyfile KMS. The missing
+// keyfile is created, the audio is stored unencrypted only when the encryption is disabled explicitly
+type EncryptionConfig struct {
+	Disabled       bool          `env:"ENCRYPTION_DISABLED" split_words:"true" default:"false"`
+	Keyfile        string        `env:"ENCRYPTION_KEYFILE" split_words:"true" default:"keyfile.json"`
+	RewrapInterval time.Duration `env:"ENCRYPTION_REWRAP_INTERVAL" split_words:"true" default:"24h"`
+}
+
+// WebhookConfig is the timeout of one delivery to the webhook of the organization
+type WebhookConfig struct {
+	Timeout time.Duration `env:"WEBHOOK_TIMEOUT" split_words:"true" default:"10s"`
 }
 
 type Config struct {
-	HTTP  HTTPConfig
-	GRPC  GRPCConfig
-	DB    DBConfig
-	OTEL  OTELConfig
-	Kafka KafkaConfig
+	HTTP       HTTPConfig
+	GRPC       GRPCConfig
+	DB         DBConfig
+	OTEL       OTELConfig
+	Kafka      KafkaConfig
+	Client     ClientConfig
+	Incident   IncidentConfig
+	Health     HealthConfig
+	Command    CommandConfig
+	Clock      ClockConfig
+	Auth       AuthConfig
+	Audit      AuditConfig
+	Device     DeviceConfig
+	Retention  RetentionConfig
+	Encryption EncryptionConfig
+	Webhook    WebhookConfig
 }
 
 func New(envFiles ...string) (*Config, error) {
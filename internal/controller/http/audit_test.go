@@ -0,0 +1,107 @@
+package http
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/uCase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type acceptingOrganizations struct {
+	uCase.OrganizationUseCase
+}
+
+func (acceptingOrganizations) Authenticate(context.Context, string) (entities.Organization, error) {
+	return entities.Organization{ID: primitive.NewObjectID()}, nil
+}
+
+type recordingAudit struct {
+	uCase.AuditUseCase
+
+	recordErr error
+	recorded  []entities.AuditOutcome
+	completed []entities.AuditOutcome
+}
+
+func (r *recordingAudit) Record(_ context.Context, _ uuid.UUID, entry *entities.AuditEntry) error {
+	r.recorded = append(r.recorded, entry.Outcome)
+	return r.recordErr
+}
+
+func (r *recordingAudit) Complete(_ context.Context, _ uuid.UUID, entry *entities.AuditEntry) error {
+	r.completed = append(r.completed, entry.Outcome)
+	return nil
+}
+
+type countingClients struct {
+	uCase.ClientUseCase
+
+	created int
+}
+
+func (c *countingClients) Create(context.Context, uuid.UUID, *entities.Client) (string, error) {
+	c.created++
+	return primitive.NewObjectID().Hex(), nil
+}
+
+// TestAuditBeforeMutation checks that the call isn't made if its pending audit entry can't be saved
+func TestAuditBeforeMutation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testTable := []struct {
+		name         string
+		recordErr    error
+		expStatus    int
+		expCreated   int
+		expCompleted []entities.AuditOutcome
+	}{
+		{
+			name:         "entry is completed after the call",
+			expStatus:    http.StatusCreated,
+			expCreated:   1,
+			expCompleted: []entities.AuditOutcome{entities.AuditSucceeded},
+		},
+		{
+			name:      "call fails without the entry",
+			recordErr: errors.New("the database is down"),
+			expStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tCase := range testTable {
+		t.Run(tCase.name, func(t *testing.T) {
+			var (
+				audit   = &recordingAudit{recordErr: tCase.recordErr}
+				clients = &countingClients{}
+			)
+
+			router := NewHTTPServer(zap.NewNop(), &uCase.UseCase{
+				Organization: acceptingOrganizations{}, Audit: audit, Client: clients,
+			}, "")
+
+			body := `{"locationName": "Main st.", "fullName": "Sensor 1", "latitude": 55.7, "longitude": 37.6,` +
+				` "notificationMethods": ["sms"]}`
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/client", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-REQUEST-ID", uuid.NewString())
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tCase.expStatus, w.Code, w.Body.String())
+			require.Equal(t, tCase.expCreated, clients.created)
+			require.Equal(t, []entities.AuditOutcome{entities.AuditPending}, audit.recorded)
+			require.Equal(t, tCase.expCompleted, audit.completed)
+		})
+	}
+}
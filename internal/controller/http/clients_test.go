@@ -0,0 +1,109 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Imm0bilize/gunshot-api-service/internal/controller/http/dto"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/uCase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type deletingClients struct {
+	uCase.ClientUseCase
+
+	deleted []int64
+}
+
+func (d *deletingClients) Get(context.Context, uuid.UUID, string) (entities.Client, error) {
+	return entities.Client{ID: primitive.NewObjectID(), Version: 3}, nil
+}
+
+func (d *deletingClients) Delete(_ context.Context, _ uuid.UUID, _ string, version int64) error {
+	d.deleted = append(d.deleted, version)
+	return nil
+}
+
+// TestIfMatch checks that writes to clients take the strong ETag of the version or '*'
+func TestIfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testTable := []struct {
+		name       string
+		ifMatch    string
+		expStatus  int
+		expDeleted []int64
+		expDetail  string
+	}{
+		{
+			name:      "without the header",
+			expStatus: http.StatusPreconditionRequired,
+			expDetail: "the If-Match header is required",
+		},
+		{
+			name:       "the ETag of the version",
+			ifMatch:    `"3-0a1b2c3d4e5f6071"`,
+			expStatus:  http.StatusNoContent,
+			expDeleted: []int64{3},
+		},
+		{
+			name:       "any version",
+			ifMatch:    "*",
+			expStatus:  http.StatusNoContent,
+			expDeleted: []int64{entities.AnyVersion},
+		},
+		{
+			name:      "the weak ETag",
+			ifMatch:   `W/"3"`,
+			expStatus: http.StatusPreconditionFailed,
+			expDetail: "the If-Match header has the weak ETag, If-Match takes strong ones only",
+		},
+		{
+			name:      "not an ETag",
+			ifMatch:   "3",
+			expStatus: http.StatusPreconditionFailed,
+			expDetail: "the If-Match header is not an ETag of the client",
+		},
+		{
+			name:      "the negative version",
+			ifMatch:   `"-1"`,
+			expStatus: http.StatusPreconditionFailed,
+			expDetail: "the If-Match header is not an ETag of the client",
+		},
+	}
+
+	for _, tCase := range testTable {
+		t.Run(tCase.name, func(t *testing.T) {
+			clients := &deletingClients{}
+
+			router := NewHTTPServer(zap.NewNop(), &uCase.UseCase{
+				Organization: acceptingOrganizations{}, Audit: &recordingAudit{}, Client: clients,
+			}, "")
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/v1/client/"+primitive.NewObjectID().Hex(), nil)
+			req.Header.Set("X-REQUEST-ID", uuid.NewString())
+			if tCase.ifMatch != "" {
+				req.Header.Set("If-Match", tCase.ifMatch)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tCase.expStatus, w.Code, w.Body.String())
+			require.Equal(t, tCase.expDeleted, clients.deleted)
+
+			if tCase.expDetail != "" {
+				var problem dto.Problem
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+				require.Equal(t, tCase.expDetail, problem.Detail)
+			}
+		})
+	}
+}
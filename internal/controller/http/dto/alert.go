@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"time"
+)
+
+type AlertRuleInfo struct {
+	Name       string `json:"name" binding:"required"`
+	ClientID   string `json:"clientID"`
+	ZoneID     string `json:"zoneID"`
+	Expression string `json:"expression" binding:"required"`
+	Window     int    `json:"window" binding:"min=0"`
+	Timezone   string `json:"timezone"`
+	Enabled    bool   `json:"enabled"`
+}
+
+type DryRunRequest struct {
+	Rule AlertRuleInfo `json:"rule" binding:"required"`
+	Days int           `json:"days" binding:"required,min=1,max=90"`
+}
+
+type DryRunResponse struct {
+	Evaluated int              `json:"evaluated"`
+	Matched   int              `json:"matched"`
+	Alerts    []entities.Alert `json:"alerts"`
+}
+
+type AlertRulesResponse struct {
+	Rules []entities.AlertRule `json:"rules"`
+}
+
+type AlertsQuery struct {
+	RuleID   string    `form:"ruleID"`
+	ClientID string    `form:"clientID"`
+	ZoneID   string    `form:"zoneID"`
+	From     time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To       time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit    int64     `form:"limit,default=50" binding:"min=1,max=500"`
+	Offset   int64     `form:"offset" binding:"min=0"`
+}
+
+type AlertsResponse struct {
+	Alerts []entities.Alert `json:"alerts"`
+}
@@ -0,0 +1,8 @@
+package dto
+
+import "time"
+
+type ChainVerifyQuery struct {
+	From time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To   time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
@@ -0,0 +1,35 @@
+package dto
+
+import (
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"time"
+)
+
+type AuditQuery struct {
+	Actor      string    `form:"actor"`
+	TargetType string    `form:"targetType"`
+	TargetID   string    `form:"targetID"`
+	RequestID  string    `form:"requestID"`
+	From       time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit      int64     `form:"limit,default=50" binding:"min=1,max=500"`
+	Offset     int64     `form:"offset" binding:"min=0"`
+	Format     string    `form:"format,default=json" binding:"oneof=json jsonl"`
+}
+
+func (q AuditQuery) ToEntity() entities.AuditFilter {
+	return entities.AuditFilter{
+		Actor:      q.Actor,
+		TargetType: q.TargetType,
+		TargetID:   q.TargetID,
+		RequestID:  q.RequestID,
+		From:       q.From,
+		To:         q.To,
+		Limit:      q.Limit,
+		Offset:     q.Offset,
+	}
+}
+
+type AuditResponse struct {
+	Entries []entities.AuditEntry `json:"entries"`
+}
@@ -1,15 +1,72 @@
 package dto
 
-import "time"
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"time"
+)
 
+// ClientInfo keeps coordinates by pointers, so the zero latitude or longitude passes 'required'
 type ClientInfo struct {
 	LocationName        string   `json:"locationName" binding:"required"`
 	FullName            string   `json:"fullName" binding:"required"`
-	Latitude            float64  `json:"latitude" binding:"required"`
-	Longitude           float64  `json:"longitude" binding:"required"`
+	Latitude            *float64 `json:"latitude" binding:"required,min=-90,max=90"`
+	Longitude           *float64 `json:"longitude" binding:"required,min=-180,max=180"`
 	NotificationMethods []string `json:"notificationMethods" binding:"required"`
 }
 
+// ClientPatch is the JSON merge patch (RFC 7396) of the client: absent fields are kept, no field of the client
+// can be removed, so null is refused
+type ClientPatch map[string]json.RawMessage
+
+func (p ClientPatch) ToEntity() (entities.ClientPatch, error) {
+	var patch entities.ClientPatch
+
+	for name, raw := range p {
+		null := bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+
+		switch name {
+		case "locationName", "fullName":
+			var value string
+			if !null {
+				if err := json.Unmarshal(raw, &value); err != nil {
+					return entities.ClientPatch{}, fmt.Errorf("'%s' must be a string", name)
+				}
+			}
+			if value == "" {
+				return entities.ClientPatch{}, fmt.Errorf("'%s' can't be removed", name)
+			}
+
+			if name == "locationName" {
+				patch.LocationName = &value
+			} else {
+				patch.FullName = &value
+			}
+		case "latitude", "longitude":
+			if null {
+				return entities.ClientPatch{}, fmt.Errorf("'%s' can't be removed", name)
+			}
+
+			var value float64
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return entities.ClientPatch{}, fmt.Errorf("'%s' must be a number", name)
+			}
+
+			if name == "latitude" {
+				patch.Latitude = &value
+			} else {
+				patch.Longitude = &value
+			}
+		default:
+			return entities.ClientPatch{}, fmt.Errorf("'%s' is unknown or can't be patched", name)
+		}
+	}
+
+	return patch, nil
+}
+
 type UploadAudioRequest struct {
 	Timestamp time.Time `url:"ts" binding:"required"`
 	ID        string    `url:"id" binding:"required"`
@@ -19,6 +76,26 @@ type RegisterResponse struct {
 	ClientID string `json:"clientID"`
 }
 
-type ErrorResponse struct {
-	Msg string `json:"error"`
+type CreatedResponse struct {
+	ID string `json:"ID"`
+}
+
+type ClientImportQuery struct {
+	DryRun bool `form:"dryRun"`
+}
+
+type ClientExportQuery struct {
+	Format string `form:"format" binding:"omitempty,oneof=csv geojson kml"`
+}
+
+// ClientsQuery pages clients in the order of their IDs, after is the next of the previous page
+type ClientsQuery struct {
+	After string `form:"after"`
+	Limit int64  `form:"limit,default=50" binding:"min=1,max=500"`
+}
+
+// ClientsResponse is the page of clients, next is set when there may be more of them
+type ClientsResponse struct {
+	Clients []entities.Client `json:"clients"`
+	Next    string            `json:"next,omitempty"`
 }
@@ -0,0 +1,17 @@
+package dto
+
+import (
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"time"
+)
+
+type ClockSyncRequest struct {
+	ClientSend time.Time `json:"clientSend" binding:"required"`
+	// Previous is the last exchange completed by the client with its receive time
+	Previous *entities.ClockSample `json:"previous"`
+}
+
+type ClockSyncResponse struct {
+	entities.ClockSample
+	Clock entities.ClockState `json:"clock"`
+}
@@ -0,0 +1,28 @@
+package dto
+
+import (
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+)
+
+type CommandRequest struct {
+	Type entities.CommandType `json:"type" binding:"required,oneof=reboot recalibrate upload_buffer"`
+	Args map[string]string    `json:"args"`
+	// TTL overrides the default time (seconds) the command waits for the client
+	TTL int `json:"ttl" binding:"min=0"`
+}
+
+type CommandsQuery struct {
+	Status entities.CommandStatus `form:"status"`
+	Limit  int64                  `form:"limit,default=50" binding:"min=1,max=500"`
+	Offset int64                  `form:"offset" binding:"min=0"`
+}
+
+type CommandsResponse struct {
+	Commands []entities.Command `json:"commands"`
+}
+
+type CommandResultRequest struct {
+	Success bool   `json:"success"`
+	Result  string `json:"result"`
+	Error   string `json:"error"`
+}
@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"time"
+)
+
+type SensorConfig struct {
+	Gain           *float64 `json:"gain"`
+	SampleRate     *int     `json:"sampleRate" binding:"omitempty,min=8000,max=192000"`
+	ChunkLength    *int     `json:"chunkLength" binding:"omitempty,min=1"`
+	UploadInterval *int     `json:"uploadInterval" binding:"omitempty,min=1"`
+}
+
+func (s SensorConfig) ToEntity() entities.SensorConfig {
+	return entities.SensorConfig{
+		Gain:           s.Gain,
+		SampleRate:     s.SampleRate,
+		ChunkLength:    s.ChunkLength,
+		UploadInterval: s.UploadInterval,
+	}
+}
+
+// PollQuery enables long polling: the request is held up to Wait until there is something new
+type PollQuery struct {
+	Wait time.Duration `form:"wait"`
+}
+
+type ConfigResponse struct {
+	Version        int64                 `json:"version"`
+	Config         entities.SensorConfig `json:"config"`
+	AppliedVersion int64                 `json:"appliedVersion"`
+	Drift          bool                  `json:"drift"`
+}
+
+type AppliedConfigRequest struct {
+	Version int64 `json:"version" binding:"min=0"`
+}
@@ -0,0 +1,10 @@
+package dto
+
+// DeadLetterReplayRequest selects dead letters of the partition by their offsets, to is inclusive and the newest
+// letter when it's absent; sourceOffsets keep the letters whose x-offset header is one of them
+type DeadLetterReplayRequest struct {
+	Partition     int32   `json:"partition" binding:"min=0"`
+	From          int64   `json:"from" binding:"min=0"`
+	To            *int64  `json:"to" binding:"omitempty,min=0"`
+	SourceOffsets []int64 `json:"sourceOffsets"`
+}
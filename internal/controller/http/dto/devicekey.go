@@ -0,0 +1,14 @@
+package dto
+
+import "github.com/Imm0bilize/gunshot-api-service/internal/entities"
+
+type DeviceKeyRequest struct {
+	// PublicKey is base64 of the raw 32 bytes Ed25519 key
+	PublicKey []byte `json:"publicKey" binding:"required"`
+	// Overlap is how long previous keys keep working (seconds), the server default is used when omitted
+	Overlap *int `json:"overlap" binding:"omitempty,min=0"`
+}
+
+type DeviceKeysResponse struct {
+	Keys []entities.DeviceKey `json:"keys"`
+}
@@ -0,0 +1,25 @@
+package dto
+
+import (
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"time"
+)
+
+type HeartbeatRequest struct {
+	Battery         float64 `json:"battery" binding:"min=0,max=100"`
+	SignalStrength  float64 `json:"signalStrength"`
+	FirmwareVersion string  `json:"firmwareVersion" binding:"required"`
+	DiskUsage       float64 `json:"diskUsage" binding:"min=0,max=100"`
+	Temperature     float64 `json:"temperature"`
+}
+
+type HeartbeatsQuery struct {
+	From   time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To     time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit  int64     `form:"limit,default=100" binding:"min=1,max=1000"`
+	Offset int64     `form:"offset" binding:"min=0"`
+}
+
+type HeartbeatsResponse struct {
+	Heartbeats []entities.Heartbeat `json:"heartbeats"`
+}
@@ -0,0 +1,30 @@
+package dto
+
+import (
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"time"
+)
+
+type IncidentsQuery struct {
+	ZoneID string    `form:"zoneID"`
+	From   time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To     time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit  int64     `form:"limit,default=50" binding:"min=1,max=500"`
+	Offset int64     `form:"offset" binding:"min=0"`
+}
+
+type IncidentsResponse struct {
+	Incidents []entities.Incident `json:"incidents"`
+}
+
+type TransitionRequest struct {
+	Status string `json:"status" binding:"required,oneof=acknowledged dispatched resolved false_positive"`
+	// DisplayName is shown with the transition, the actor is the authenticated caller
+	DisplayName string `json:"displayName"`
+	Notes       string `json:"notes"`
+}
+
+type LegalHoldRequest struct {
+	Reason string `json:"reason" binding:"required"`
+	Actor  string `json:"actor" binding:"required"`
+}
@@ -0,0 +1,58 @@
+package dto
+
+import (
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+)
+
+type QuotaInfo struct {
+	MaxSensors       int `json:"maxSensors" binding:"min=0"`
+	UploadsPerMinute int `json:"uploadsPerMinute" binding:"min=0"`
+}
+
+// RetentionInfo is kept in days, zero falls back to the default of the service
+type RetentionInfo struct {
+	AudioDays     int `json:"audioDays" binding:"min=0"`
+	DetectionDays int `json:"detectionDays" binding:"min=0"`
+	HeartbeatDays int `json:"heartbeatDays" binding:"min=0"`
+}
+
+// PrivacyInfo selects the filter of the uploaded audio, the empty mode keeps the audio as it is
+type PrivacyInfo struct {
+	Mode string `json:"mode" binding:"omitempty,oneof=speech-band impulses-only"`
+}
+
+type OrganizationInfo struct {
+	Name      string        `json:"name" binding:"required"`
+	Quota     QuotaInfo     `json:"quota"`
+	Retention RetentionInfo `json:"retention"`
+	Privacy   PrivacyInfo   `json:"privacy"`
+}
+
+func (o OrganizationInfo) ToEntity() entities.Organization {
+	return entities.Organization{
+		Name: o.Name,
+		Quota: entities.Quota{
+			MaxSensors:       o.Quota.MaxSensors,
+			UploadsPerMinute: o.Quota.UploadsPerMinute,
+		},
+		Retention: entities.Retention{
+			AudioDays:     o.Retention.AudioDays,
+			DetectionDays: o.Retention.DetectionDays,
+			HeartbeatDays: o.Retention.HeartbeatDays,
+		},
+		Privacy: entities.PrivacyFilter{Mode: entities.PrivacyMode(o.Privacy.Mode)},
+	}
+}
+
+type OrganizationCreatedResponse struct {
+	ID     string `json:"ID"`
+	APIKey string `json:"apiKey"`
+}
+
+type APIKeyResponse struct {
+	APIKey string `json:"apiKey"`
+}
+
+type OrganizationsResponse struct {
+	Organizations []entities.Organization `json:"organizations"`
+}
@@ -0,0 +1,13 @@
+package dto
+
+// Problem is the error response of RFC 7807 (application/problem+json)
+type Problem struct {
+	Type      string      `json:"type"`
+	Title     string      `json:"title"`
+	Status    int         `json:"status"`
+	Detail    string      `json:"detail,omitempty"`
+	Instance  string      `json:"instance,omitempty"`
+	Code      string      `json:"code"`
+	RequestID string      `json:"requestID,omitempty"`
+	Errors    interface{} `json:"errors,omitempty"`
+}
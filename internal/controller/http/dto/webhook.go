@@ -0,0 +1,18 @@
+package dto
+
+import "github.com/Imm0bilize/gunshot-api-service/internal/entities"
+
+type WebhookInfo struct {
+	URL    string                  `json:"url" binding:"required"`
+	Events []entities.WebhookEvent `json:"events" binding:"required,min=1"`
+}
+
+// WebhookCreatedResponse carries the secret deliveries are signed with, it's never returned again
+type WebhookCreatedResponse struct {
+	ID     string `json:"ID"`
+	Secret string `json:"secret"`
+}
+
+type WebhooksResponse struct {
+	Webhooks []entities.Webhook `json:"webhooks"`
+}
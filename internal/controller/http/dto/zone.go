@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/geo"
+	"time"
+)
+
+type ZoneInfo struct {
+	Name     string            `json:"name" binding:"required"`
+	Owner    string            `json:"owner"`
+	Metadata map[string]string `json:"metadata"`
+	Area     geo.Polygon       `json:"area" binding:"required"`
+}
+
+type ZonesResponse struct {
+	Zones []entities.Zone `json:"zones"`
+}
+
+type DetectionsQuery struct {
+	ClientID string    `form:"clientID"`
+	ZoneID   string    `form:"zoneID"`
+	From     time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To       time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	After    string    `form:"after"`
+	Limit    int64     `form:"limit,default=50" binding:"min=1,max=500"`
+	Offset   int64     `form:"offset" binding:"min=0"`
+}
+
+type DetectionsResponse struct {
+	Detections []entities.Detection `json:"detections"`
+}
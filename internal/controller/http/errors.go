@@ -0,0 +1,44 @@
+package http
+
+import (
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	v1 "github.com/Imm0bilize/gunshot-api-service/internal/controller/http/v1"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+// RenderErrors writes the last error of handlers as the problem (RFC 7807), errors after the response has
+// been written can only be logged
+func RenderErrors(logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		last := c.Errors.Last()
+		if last == nil {
+			return
+		}
+
+		problem := v1.NewProblem(c, last.Err)
+		if problem.Status >= http.StatusInternalServerError {
+			logger.Error(
+				"error during request",
+				zap.String("path", c.Request.URL.Path),
+				zap.String("reqID", problem.RequestID),
+				zap.Error(last.Err),
+			)
+		}
+
+		if c.Writer.Written() {
+			return
+		}
+
+		c.Header("Content-Type", v1.ProblemContentType)
+		c.JSON(problem.Status, problem)
+	}
+}
+
+// NoRoute answers unknown routes with the problem too
+func NoRoute(c *gin.Context) {
+	_ = c.Error(apperr.New(apperr.NotFound, "the route not found"))
+}
@@ -0,0 +1,137 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/Imm0bilize/gunshot-api-service/internal/controller/http/dto"
+	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRenderErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	requestID := uuid.New()
+
+	testTable := []struct {
+		name       string
+		err        error
+		expStatus  int
+		expCode    apperr.Kind
+		expDetail  string
+		expDetails bool
+	}{
+		{
+			name:      "not found",
+			err:       errors.Wrap(repository.ErrClientNotFound, "can't get client"),
+			expStatus: http.StatusNotFound,
+			expCode:   apperr.NotFound,
+			expDetail: "can't get client: the client is not found",
+		},
+		{
+			name:      "invalid argument",
+			err:       apperr.New(apperr.InvalidArgument, "invalid zoneID"),
+			expStatus: http.StatusBadRequest,
+			expCode:   apperr.InvalidArgument,
+			expDetail: "invalid zoneID",
+		},
+		{
+			name:      "stale version",
+			err:       repository.ErrClientVersionMismatch,
+			expStatus: http.StatusPreconditionFailed,
+			expCode:   apperr.FailedPrecondition,
+			expDetail: repository.ErrClientVersionMismatch.Error(),
+		},
+		{
+			name:       "details",
+			err:        apperr.WithDetails(apperr.InvalidArgument, "1 of 2 rows are invalid", []string{"row 2"}),
+			expStatus:  http.StatusBadRequest,
+			expCode:    apperr.InvalidArgument,
+			expDetail:  "1 of 2 rows are invalid",
+			expDetails: true,
+		},
+		{
+			name:      "internal message is hidden",
+			err:       errors.Wrap(errors.New("E11000 duplicate key"), "can't create client"),
+			expStatus: http.StatusInternalServerError,
+			expCode:   apperr.Internal,
+		},
+		{
+			name:      "database is unavailable",
+			err:       errors.Wrap(mongo.ErrClientDisconnected, "can't get client"),
+			expStatus: http.StatusServiceUnavailable,
+			expCode:   apperr.Unavailable,
+		},
+	}
+
+	for _, tCase := range testTable {
+		t.Run(tCase.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(RenderErrors(zap.NewNop()))
+			router.GET("/api/v1/client/:id", InjectRequestIDIntoCtx, func(c *gin.Context) {
+				_ = c.Error(tCase.err)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/client/1", nil)
+			req.Header.Set(_requestIDHeader, requestID.String())
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tCase.expStatus, w.Code)
+			require.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+
+			var problem dto.Problem
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			require.Equal(t, tCase.expStatus, problem.Status)
+			require.Equal(t, string(tCase.expCode), problem.Code)
+			require.Equal(t, tCase.expDetail, problem.Detail)
+			require.Equal(t, http.StatusText(tCase.expStatus), problem.Title)
+			require.Equal(t, "/api/v1/client/1", problem.Instance)
+			require.Equal(t, requestID.String(), problem.RequestID)
+			require.Equal(t, tCase.expDetails, problem.Errors != nil)
+		})
+	}
+}
+
+func TestRenderErrorsWithoutRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(RenderErrors(zap.NewNop()))
+	router.NoRoute(NoRoute)
+	router.GET("/api/v1/client/:id", InjectRequestIDIntoCtx, func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	testTable := []struct {
+		name      string
+		path      string
+		expStatus int
+	}{
+		{name: "bad request id", path: "/api/v1/client/1", expStatus: http.StatusBadRequest},
+		{name: "unknown route", path: "/api/v1/unknown", expStatus: http.StatusNotFound},
+	}
+
+	for _, tCase := range testTable {
+		t.Run(tCase.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tCase.path, nil)
+			req.Header.Set(_requestIDHeader, "not-a-uuid")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tCase.expStatus, w.Code)
+			require.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+
+			var problem dto.Problem
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			require.Equal(t, tCase.expStatus, problem.Status)
+			require.Empty(t, problem.RequestID)
+		})
+	}
+}
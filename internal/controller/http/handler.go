@@ -1,6 +1,7 @@
 package http
 
 import (
+	"github.com/Imm0bilize/gunshot-api-service/internal/controller/http/openapi"
 	v1 "github.com/Imm0bilize/gunshot-api-service/internal/controller/http/v1"
 	"github.com/Imm0bilize/gunshot-api-service/internal/uCase"
 	"github.com/gin-gonic/gin"
@@ -10,7 +11,7 @@ import (
 	"net/http/pprof"
 )
 
-func NewHTTPServer(logger *zap.Logger, domain *uCase.UseCase) *gin.Engine {
+func NewHTTPServer(logger *zap.Logger, domain *uCase.UseCase, adminToken string) *gin.Engine {
 	router := gin.New()
 
 	router.Use(gin.Logger())
@@ -18,12 +19,20 @@ func NewHTTPServer(logger *zap.Logger, domain *uCase.UseCase) *gin.Engine {
 	// trace
 	router.Use(otelgin.Middleware("gunshot-api-service"))
 
+	// errors of handlers as application/problem+json
+	router.Use(RenderErrors(logger))
+	router.NoRoute(NoRoute)
+
+	spec := openapi.New()
+	router.GET("/api/openapi.json", spec.Serve)
+	router.GET("/api/docs", openapi.Docs)
+
 	// Debug handlers
 	router.GET("/ping", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"message": "pong"}) })
 	initPprof(router.Group("/debug"))
 
 	// API
-	initAPI(router, logger, domain)
+	initAPI(router, logger, domain, adminToken, spec)
 
 	return router
 }
@@ -46,11 +55,18 @@ func initPprof(router *gin.RouterGroup) {
 	}
 }
 
-func initAPI(router *gin.Engine, logger *zap.Logger, domain *uCase.UseCase) {
+// initAPI registers routes of the API, requests are checked against the OpenAPI document once they are
+// authenticated and before they reach handlers
+func initAPI(
+	router *gin.Engine, logger *zap.Logger, domain *uCase.UseCase, adminToken string, spec *openapi.Document,
+) {
 	handlerV1 := v1.NewHandler(logger, domain)
 
 	api := router.Group("/api")
 	{
-		handlerV1.InitAPI(api, InjectRequestIDIntoCtx, InjectClientIDIntoCtx)
+		handlerV1.InitAPI(
+			api, InjectRequestIDIntoCtx, InjectClientIDIntoCtx, Authenticate(domain), RequireAdmin(adminToken),
+			spec.Validator(), Deduplicate(domain),
+		)
 	}
 }
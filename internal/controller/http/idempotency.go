@@ -0,0 +1,147 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/Imm0bilize/gunshot-api-service/internal/audit"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/uCase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"io"
+	"net/http"
+)
+
+const (
+	_idempotencyKeyHeader      = "Idempotency-Key"
+	_idempotentReplayedHeader  = "Idempotent-Replayed"
+	_maxIdempotencyKeyLength   = 255
+	_maxIdempotentResponseSize = 1 << 20
+	// _maxIdempotentBodySize is the largest body the API takes, the one of the import
+	_maxIdempotentBodySize = 16 << 20
+)
+
+// _replayedHeaders are headers of the response sent again with its body
+var _replayedHeaders = []string{"Content-Type", "ETag"}
+
+// Deduplicate serves retries of POSTs and PATCHes with the 'Idempotency-Key' header by the response of the
+// first attempt, the key is unique per organization. The retry must have the method, path and body of the
+// first attempt, the key sent with another request gets 409. Failed requests and responses over 1 MiB free
+// the key, so their retries are served again
+func Deduplicate(domain *uCase.UseCase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(_idempotencyKeyHeader)
+		if key == "" || (c.Request.Method != http.MethodPost && c.Request.Method != http.MethodPatch) {
+			c.Next()
+			return
+		}
+
+		if len(key) > _maxIdempotencyKeyLength {
+			_ = c.Error(apperr.New(apperr.InvalidArgument, "invalid header 'Idempotency-Key': longer than 255 bytes"))
+			c.Abort()
+			return
+		}
+
+		// the body is hashed before it's served, so it's read and given back to the handler
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, _maxIdempotentBodySize))
+		if err != nil {
+			_ = c.Error(apperr.Wrap(
+				apperr.InvalidArgument,
+				fmt.Errorf("can't read the body of at most %d bytes: %w", _maxIdempotentBodySize, err),
+			))
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		bodyHash := sha256.Sum256(body)
+
+		requestID := c.MustGet("requestID").(uuid.UUID)
+		request := &entities.IdempotentRequest{
+			Key:      key,
+			Method:   c.Request.Method,
+			Path:     c.Request.URL.RequestURI(),
+			BodyHash: hex.EncodeToString(bodyHash[:]),
+		}
+
+		completed, started, err := domain.Idempotency.Begin(c.Request.Context(), requestID, request)
+		if err != nil {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+
+		if !started {
+			for name, value := range completed.Header {
+				c.Header(name, value)
+			}
+			c.Header(_idempotentReplayedHeader, "true")
+			c.Status(completed.Status)
+			_, _ = c.Writer.Write(completed.Body)
+			c.Abort()
+			return
+		}
+
+		// the key is saved or freed even if the caller has gone, errors are logged by the use case
+		ctx := audit.Detach(c.Request.Context())
+
+		holdCtx, stopHolding := context.WithCancel(ctx)
+		held := *request
+		go domain.Idempotency.Hold(holdCtx, requestID, &held)
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+		stopHolding()
+		if len(c.Errors) > 0 || recorder.Status() >= http.StatusBadRequest || recorder.overflow {
+			_ = domain.Idempotency.Release(ctx, requestID, request)
+			return
+		}
+
+		request.Status = recorder.Status()
+		request.Header = make(map[string]string)
+		for _, name := range _replayedHeaders {
+			if value := recorder.Header().Get(name); value != "" {
+				request.Header[name] = value
+			}
+		}
+		request.Body = recorder.body.Bytes()
+
+		_ = domain.Idempotency.Complete(ctx, requestID, request)
+	}
+}
+
+// responseRecorder keeps the body written to the response unless it's over the limit
+type responseRecorder struct {
+	gin.ResponseWriter
+
+	body     bytes.Buffer
+	overflow bool
+}
+
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	r.keep(p)
+	return r.ResponseWriter.Write(p)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.keep([]byte(s))
+	return r.ResponseWriter.WriteString(s)
+}
+
+func (r *responseRecorder) keep(p []byte) {
+	if r.overflow {
+		return
+	}
+
+	if r.body.Len()+len(p) > _maxIdempotentResponseSize {
+		r.overflow = true
+		r.body = bytes.Buffer{}
+		return
+	}
+
+	r.body.Write(p)
+}
@@ -0,0 +1,99 @@
+package http
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/uCase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// memoryIdempotency keeps idempotent requests of one tenant in memory
+type memoryIdempotency struct {
+	mu       sync.Mutex
+	requests map[string]entities.IdempotentRequest
+}
+
+func (m *memoryIdempotency) Begin(
+	_ context.Context, request *entities.IdempotentRequest,
+) (entities.IdempotentRequest, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if existing, ok := m.requests[request.Key]; ok {
+		return existing, false, nil
+	}
+	m.requests[request.Key] = *request
+
+	return *request, true, nil
+}
+
+func (m *memoryIdempotency) Complete(_ context.Context, request *entities.IdempotentRequest) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[request.Key] = *request
+	return nil
+}
+
+func (m *memoryIdempotency) Extend(context.Context, *entities.IdempotentRequest) error {
+	return nil
+}
+
+func (m *memoryIdempotency) Release(_ context.Context, request *entities.IdempotentRequest) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.requests, request.Key)
+	return nil
+}
+
+// TestIdempotencyKeyWithAnotherBody checks that the retry is served by the response of the first attempt
+// and the key sent with another body is refused
+func TestIdempotencyKeyWithAnotherBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	clients := &countingClients{}
+	router := NewHTTPServer(zap.NewNop(), &uCase.UseCase{
+		Organization: acceptingOrganizations{},
+		Audit:        &recordingAudit{},
+		Client:       clients,
+		Idempotency: uCase.NewIdempotencyUCase(
+			zap.NewNop(), &memoryIdempotency{requests: make(map[string]entities.IdempotentRequest)},
+		),
+	}, "")
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/client", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-REQUEST-ID", uuid.NewString())
+		req.Header.Set("Idempotency-Key", "key")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	const body = `{"locationName": "Main st.", "fullName": "Sensor 1", "latitude": 55.7, "longitude": 37.6,` +
+		` "notificationMethods": ["sms"]}`
+
+	first := post(body)
+	require.Equal(t, http.StatusCreated, first.Code, first.Body.String())
+
+	retry := post(body)
+	require.Equal(t, http.StatusCreated, retry.Code, retry.Body.String())
+	require.Equal(t, "true", retry.Header().Get("Idempotent-Replayed"))
+	require.Equal(t, first.Body.String(), retry.Body.String())
+
+	another := post(strings.Replace(body, "Sensor 1", "Sensor 2", 1))
+	require.Equal(t, http.StatusConflict, another.Code, another.Body.String())
+
+	require.Equal(t, 1, clients.created)
+}
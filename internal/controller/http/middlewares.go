@@ -1,18 +1,30 @@
 package http
 
 import (
+	"crypto/subtle"
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/Imm0bilize/gunshot-api-service/internal/tenant"
+	"github.com/Imm0bilize/gunshot-api-service/internal/uCase"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"net/http"
+	"strings"
 )
 
-const _requestIDHeader = "X-REQUEST-ID"
+const (
+	_requestIDHeader     = "X-REQUEST-ID"
+	_authorizationHeader = "Authorization"
+)
+
+func bearerToken(c *gin.Context) string {
+	return strings.TrimPrefix(c.GetHeader(_authorizationHeader), "Bearer ")
+}
 
 func InjectRequestIDIntoCtx(c *gin.Context) {
 	var requestID uuid.UUID
 
 	if err := requestID.Scan(c.GetHeader(_requestIDHeader)); err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid or empty header 'X-REQUEST-ID'"})
+		_ = c.Error(apperr.New(apperr.InvalidArgument, "invalid or empty header 'X-REQUEST-ID'"))
+		c.Abort()
 		return
 	}
 
@@ -24,10 +36,47 @@ func InjectClientIDIntoCtx(c *gin.Context) {
 	var clientID string
 
 	if clientID = c.Param("id"); clientID == "" {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid clientID: clientID must not be empty"})
+		_ = c.Error(apperr.New(apperr.InvalidArgument, "invalid clientID: clientID must not be empty"))
+		c.Abort()
 		return
 	}
 
 	c.Set("clientID", clientID)
 	c.Next()
 }
+
+// Authenticate scopes the request to the organization which owns the API key from the 'Authorization' header
+func Authenticate(domain *uCase.UseCase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		organization, err := domain.Organization.Authenticate(c.Request.Context(), bearerToken(c))
+		if err != nil {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+
+		c.Request = c.Request.WithContext(tenant.WithOrganization(c.Request.Context(), organization))
+		c.Next()
+	}
+}
+
+// RequireAdmin lets through requests with the admin token, the empty token disables the admin API
+func RequireAdmin(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			_ = c.Error(apperr.New(apperr.PermissionDenied, "the admin API is disabled"))
+			c.Abort()
+			return
+		}
+
+		if subtle.ConstantTimeCompare([]byte(bearerToken(c)), []byte(token)) != 1 {
+			_ = c.Error(apperr.New(apperr.Unauthenticated, "invalid admin token"))
+			c.Abort()
+			return
+		}
+
+		// organizations are managed across tenants
+		c.Request = c.Request.WithContext(tenant.System(c.Request.Context()))
+		c.Next()
+	}
+}
@@ -0,0 +1,180 @@
+// Package openapi describes the API as the OpenAPI 3 document, serves it with the docs UI and validates
+// requests against it. The document is built from the table of operations and the Go types of requests
+// and responses, so binding rules of DTOs become constraints of schemas
+package openapi
+
+import (
+	"sort"
+	"strings"
+)
+
+const (
+	_version = "3.0.3"
+
+	_requestIDParameter      = "#/components/parameters/RequestID"
+	_idempotencyKeyParameter = "#/components/parameters/IdempotencyKey"
+	_problemResponse         = "#/components/responses/Problem"
+)
+
+type Document struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       Info                            `json:"info"`
+	Paths      map[string]map[string]Operation `json:"paths"`
+	Components Components                      `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary"`
+	Tags        []string              `json:"tags"`
+	Security    []map[string][]string `json:"security"`
+	Parameters  []*Parameter          `json:"parameters"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+}
+
+// Parameter is the parameter or the reference to the one of components
+type Parameter struct {
+	Ref         string  `json:"$ref,omitempty"`
+	Name        string  `json:"name,omitempty"`
+	In          string  `json:"in,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Response is the response or the reference to the one of components
+type Response struct {
+	Ref         string                `json:"$ref,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Headers     map[string]*Header    `json:"headers,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	Parameters      map[string]*Parameter      `json:"parameters"`
+	Responses       map[string]*Response       `json:"responses"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	Scheme      string `json:"scheme"`
+	Description string `json:"description,omitempty"`
+}
+
+// New builds the document of the API
+func New() *Document {
+	g := newGenerator()
+
+	document := &Document{
+		OpenAPI: _version,
+		Info: Info{
+			Title:   "gunshot-api-service",
+			Version: "v1",
+			Description: "Every request carries the 'X-REQUEST-ID' header (UUID) which identifies it in logs, " +
+				"traces, the audit log and problem details of errors.",
+		},
+		Paths: make(map[string]map[string]Operation),
+		Components: Components{
+			Parameters: map[string]*Parameter{
+				"RequestID": {
+					Name:        "X-REQUEST-ID",
+					In:          "header",
+					Description: "UUID of the request, returned in problem details and recorded in the audit log",
+					Required:    true,
+					Schema:      &Schema{Type: "string", Format: "uuid"},
+				},
+				"IdempotencyKey": {
+					Name: "Idempotency-Key",
+					In:   "header",
+					Description: "unique key of the request (up to 255 bytes), its retries within 24 hours get the " +
+						"response of the first attempt with 'Idempotent-Replayed: true' unless it has failed. The key " +
+						"sent with another method, path or body gets 409",
+					Schema: &Schema{Type: "string"},
+				},
+			},
+			Responses: map[string]*Response{
+				"Problem": {
+					Description: "Problem details (RFC 7807)",
+					Content:     map[string]*MediaType{"application/problem+json": {Schema: g.schema(_problemType)}},
+				},
+			},
+			SecuritySchemes: map[string]*SecurityScheme{
+				"apiKey": {Type: "http", Scheme: "bearer", Description: "API key of the organization"},
+				"admin":  {Type: "http", Scheme: "bearer", Description: "Admin token (AUTH_ADMIN_TOKEN)"},
+			},
+		},
+	}
+
+	for _, op := range operations() {
+		path := templatePath(op.path)
+		if document.Paths[path] == nil {
+			document.Paths[path] = make(map[string]Operation)
+		}
+
+		document.Paths[path][strings.ToLower(op.method)] = op.build(g)
+	}
+
+	document.Components.Schemas = g.components
+
+	return document
+}
+
+// Routes lists operations as "METHOD /gin/path", the form of routes of gin
+func (d *Document) Routes() []string {
+	routes := make([]string, 0)
+	for path, item := range d.Paths {
+		for method := range item {
+			routes = append(routes, strings.ToUpper(method)+" "+ginPath(path))
+		}
+	}
+
+	sort.Strings(routes)
+
+	return routes
+}
+
+// templatePath turns gin parameters (:id) into OpenAPI ones ({id})
+func templatePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+func ginPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			segments[i] = ":" + segment[1:len(segment)-1]
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,59 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"github.com/Imm0bilize/gunshot-api-service/internal/controller/http/openapi"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestDocumentReferences(t *testing.T) {
+	raw, err := json.Marshal(openapi.New())
+	require.NoError(t, err)
+
+	var document map[string]interface{}
+	require.NoError(t, json.Unmarshal(raw, &document))
+
+	var (
+		refs = make([]string, 0)
+		ids  = make(map[string]bool)
+		walk func(v interface{})
+	)
+
+	walk = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for key, value := range v {
+				if ref, ok := value.(string); ok && key == "$ref" {
+					refs = append(refs, ref)
+				}
+
+				if id, ok := value.(string); ok && key == "operationId" {
+					require.False(t, ids[id], "operationId %s is duplicated", id)
+					ids[id] = true
+				}
+
+				walk(value)
+			}
+		case []interface{}:
+			for _, value := range v {
+				walk(value)
+			}
+		}
+	}
+
+	walk(document)
+	require.NotEmpty(t, refs)
+
+	for _, ref := range refs {
+		var target interface{} = document
+		for _, segment := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			object, ok := target.(map[string]interface{})
+			require.True(t, ok, ref)
+
+			target, ok = object[segment]
+			require.True(t, ok, "%s is not defined", ref)
+		}
+	}
+}
@@ -0,0 +1,565 @@
+package openapi
+
+import (
+	"github.com/Imm0bilize/gunshot-api-service/internal/controller/http/dto"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/uCase"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	_jsonType        = "application/json"
+	_mergePatchType  = "application/merge-patch+json"
+	_csvType         = "text/csv"
+	_geoJSONType     = "application/geo+json"
+	_kmlType         = "application/vnd.google-earth.kml+xml"
+	_zipType         = "application/zip"
+	_eventStreamType = "text/event-stream"
+	_ndjsonType      = "application/x-ndjson"
+	_anyType         = "*/*"
+)
+
+var _problemType = reflect.TypeOf(dto.Problem{})
+
+type auth int
+
+const (
+	// _tenant routes take the API key of the organization
+	_tenant auth = iota
+	_admin
+)
+
+// operation is the route of the API with Go types of its query, body and responses
+type operation struct {
+	method  string
+	path    string
+	id      string
+	summary string
+	tag     string
+	auth    auth
+	headers []*Parameter
+	query   interface{}
+	// body maps media types to Go values or *Schema of the required body
+	body      map[string]interface{}
+	responses []response
+}
+
+type response struct {
+	status      int
+	description string
+	// content maps media types to Go values or *Schema, nil for responses without the body
+	content map[string]interface{}
+	headers []string
+}
+
+func jsonBody(v interface{}) map[string]interface{} {
+	return map[string]interface{}{_jsonType: v}
+}
+
+func ok(v interface{}) response {
+	return response{status: http.StatusOK, description: "OK", content: jsonBody(v)}
+}
+
+func created(v interface{}) response {
+	return response{status: http.StatusCreated, description: "Created", content: jsonBody(v)}
+}
+
+func withETag(r response) response {
+	r.headers = []string{"ETag"}
+	return r
+}
+
+func download(mediaType string) response {
+	return response{status: http.StatusOK, description: "OK", content: map[string]interface{}{mediaType: binary()}}
+}
+
+var (
+	okEmpty   = response{status: http.StatusOK, description: "OK"}
+	noContent = response{status: http.StatusNoContent, description: "No Content"}
+	notChange = response{status: http.StatusNotModified, description: "the ETag in If-None-Match is current"}
+)
+
+func binary() *Schema {
+	return &Schema{Type: "string", Format: "binary"}
+}
+
+// pathTypes are schemas of path parameters which are not strings
+var pathTypes = map[string]*Schema{
+	"ts": {Type: "integer", Format: "int64", Description: "unix time in milliseconds"},
+}
+
+var _responseHeaders = map[string]*Header{
+	"ETag": {Description: "version of the resource", Schema: &Schema{Type: "string"}},
+}
+
+var (
+	_ifMatch = &Parameter{
+		Name:        "If-Match",
+		In:          "header",
+		Description: "ETag of the client or '*', 428 without it, 412 when the client has been changed since",
+		Schema:      &Schema{Type: "string"},
+	}
+	_ifNoneMatch = &Parameter{
+		Name:        "If-None-Match",
+		In:          "header",
+		Description: "ETag the caller has, 304 when it is current",
+		Schema:      &Schema{Type: "string"},
+	}
+	_signature = &Parameter{
+		Name:        "X-Signature",
+		In:          "header",
+		Description: `base64 Ed25519 signature of "<clientID>\n<ts>\n<X-Sequence>\n<hex SHA-256 of the audio>\n"`,
+		Schema:      &Schema{Type: "string", Format: "byte"},
+	}
+	_sequence = &Parameter{
+		Name:        "X-Sequence",
+		In:          "header",
+		Description: "sequence number of the signed upload, required with X-Signature",
+		Schema:      &Schema{Type: "integer", Format: "int64"},
+	}
+)
+
+// _clientPatch is written by hand, dto.ClientPatch is the raw map of the merge patch
+var _clientPatch = &Schema{
+	Type: "object",
+	Properties: map[string]*Schema{
+		"locationName": {Type: "string", MinLength: length(1)},
+		"fullName":     {Type: "string", MinLength: length(1)},
+		"latitude":     {Type: "number", Format: "double", Minimum: float(-90), Maximum: float(90)},
+		"longitude":    {Type: "number", Format: "double", Minimum: float(-180), Maximum: float(180)},
+	},
+	AdditionalProperties: false,
+}
+
+func float(v float64) *float64 {
+	return &v
+}
+
+func length(v int) *int {
+	return &v
+}
+
+func (op operation) build(g *generator) Operation {
+	built := Operation{
+		OperationID: op.id,
+		Summary:     op.summary,
+		Tags:        []string{op.tag},
+		Security:    []map[string][]string{{"apiKey": {}}},
+		Parameters:  []*Parameter{{Ref: _requestIDParameter}},
+		Responses:   map[string]*Response{"default": {Ref: _problemResponse}},
+	}
+
+	if op.auth == _admin {
+		built.Security = []map[string][]string{{"admin": {}}}
+	} else if op.method == http.MethodPost || op.method == http.MethodPatch {
+		built.Parameters = append(built.Parameters, &Parameter{Ref: _idempotencyKeyParameter})
+	}
+
+	for _, segment := range strings.Split(op.path, "/") {
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+
+		schema, ok := pathTypes[segment[1:]]
+		if !ok {
+			schema = &Schema{Type: "string"}
+		}
+
+		built.Parameters = append(
+			built.Parameters, &Parameter{Name: segment[1:], In: "path", Required: true, Schema: schema},
+		)
+	}
+
+	built.Parameters = append(built.Parameters, op.headers...)
+	built.Parameters = append(built.Parameters, g.parameters(op.query)...)
+
+	if op.body != nil {
+		built.RequestBody = &RequestBody{Required: true, Content: g.content(op.body)}
+	}
+
+	for _, r := range op.responses {
+		described := &Response{Description: r.description}
+		if r.content != nil {
+			described.Content = g.content(r.content)
+		}
+
+		for _, name := range r.headers {
+			if described.Headers == nil {
+				described.Headers = make(map[string]*Header)
+			}
+
+			described.Headers[name] = _responseHeaders[name]
+		}
+
+		built.Responses[strconv.Itoa(r.status)] = described
+	}
+
+	return built
+}
+
+func (g *generator) content(media map[string]interface{}) map[string]*MediaType {
+	content := make(map[string]*MediaType, len(media))
+	for mediaType, v := range media {
+		schema, ok := v.(*Schema)
+		if !ok {
+			schema = g.schema(reflect.TypeOf(v))
+		}
+
+		content[mediaType] = &MediaType{Schema: schema}
+	}
+
+	return content
+}
+
+// operations is every route of Handler.InitAPI, the test of the router fails when they drift apart
+func operations() []operation {
+	const v1 = "/api/v1"
+
+	return []operation{
+		// clients
+		{
+			method: http.MethodPost, path: v1 + "/client", id: "registerClient", tag: "clients",
+			summary: "Register the client (sensor)", body: jsonBody(dto.ClientInfo{}),
+			responses: []response{created(dto.RegisterResponse{})},
+		},
+		{
+			method: http.MethodGet, path: v1 + "/client/:id", id: "getClient", tag: "clients",
+			summary: "Get the client, the ETag carries its version", headers: []*Parameter{_ifNoneMatch},
+			responses: []response{withETag(ok(entities.Client{})), notChange},
+		},
+		{
+			method: http.MethodPut, path: v1 + "/client/:id", id: "updateClient", tag: "clients",
+			summary: "Replace the client", headers: []*Parameter{_ifMatch}, body: jsonBody(dto.ClientInfo{}),
+			responses: []response{withETag(ok(entities.Client{}))},
+		},
+		{
+			method: http.MethodPatch, path: v1 + "/client/:id", id: "patchClient", tag: "clients",
+			summary: "Apply the JSON merge patch (RFC 7396) to the client", headers: []*Parameter{_ifMatch},
+			body:      map[string]interface{}{_mergePatchType: _clientPatch},
+			responses: []response{withETag(ok(entities.Client{}))},
+		},
+		{
+			method: http.MethodDelete, path: v1 + "/client/:id", id: "deleteClient", tag: "clients",
+			summary: "Delete the client, it may be restored within the grace period", headers: []*Parameter{_ifMatch},
+			responses: []response{noContent},
+		},
+		{
+			method: http.MethodPost, path: v1 + "/client/:id/restore", id: "restoreClient", tag: "clients",
+			summary: "Restore the deleted client", responses: []response{withETag(ok(entities.Client{}))},
+		},
+		{
+			method: http.MethodGet, path: v1 + "/client/:id/history", id: "clientHistory", tag: "clients",
+			summary:   "List versions of the client from the oldest one",
+			responses: []response{ok([]entities.ClientVersion{})},
+		},
+		{
+			method: http.MethodGet, path: v1 + "/clients", id: "listClients", tag: "clients",
+			summary: "List clients by pages in the order of their IDs", query: dto.ClientsQuery{},
+			responses: []response{ok(dto.ClientsResponse{})},
+		},
+		{
+			method: http.MethodPost, path: v1 + "/clients/import", id: "importClients", tag: "clients",
+			summary: "Import clients from CSV or GeoJSON, nothing is created if any row is invalid",
+			query:   dto.ClientImportQuery{},
+			body: map[string]interface{}{
+				_csvType:     &Schema{Type: "string", Description: "fullName, locationName, latitude, longitude columns"},
+				_geoJSONType: &Schema{Type: "object", Description: "FeatureCollection of points"},
+			},
+			responses: []response{
+				{status: http.StatusOK, description: "the dry run", content: jsonBody(entities.ClientImport{})},
+				created(entities.ClientImport{}),
+			},
+		},
+		{
+			method: http.MethodGet, path: v1 + "/clients/export", id: "exportClients", tag: "clients",
+			summary: "Export clients for GIS tools", query: dto.ClientExportQuery{},
+			responses: []response{{
+				status: http.StatusOK, description: "OK",
+				content: map[string]interface{}{_csvType: binary(), _geoJSONType: binary(), _kmlType: binary()},
+			}},
+		},
+
+		// data subject requests
+		{
+			method: http.MethodGet, path: v1 + "/client/:id/export", id: "exportClientData", tag: "subjects",
+			summary: "Export everything kept about the client", responses: []response{download(_zipType)},
+		},
+		{
+			method: http.MethodPost, path: v1 + "/client/:id/erase", id: "eraseClientData", tag: "subjects",
+			summary: "Erase the client and its data", responses: []response{ok(entities.Tombstone{})},
+		},
+
+		// audio
+		{
+			method: http.MethodPost, path: v1 + "/client/:id/:ts/upload", id: "uploadAudio", tag: "audio",
+			summary: "Upload the audio recorded at ts", headers: []*Parameter{_signature, _sequence},
+			body:      map[string]interface{}{_anyType: binary()},
+			responses: []response{{status: http.StatusAccepted, description: "Accepted"}},
+		},
+		{
+			method: http.MethodGet, path: v1 + "/client/:id/audio/verify", id: "verifyAudioChain", tag: "audio",
+			summary: "Verify the hash chain of uploads", query: dto.ChainVerifyQuery{},
+			responses: []response{ok(entities.ChainVerification{})},
+		},
+
+		// device keys
+		{
+			method: http.MethodPost, path: v1 + "/client/:id/keys", id: "registerDeviceKey", tag: "device keys",
+			summary: "Register the Ed25519 key of the device", body: jsonBody(dto.DeviceKeyRequest{}),
+			responses: []response{created(entities.DeviceKey{})},
+		},
+		{
+			method: http.MethodGet, path: v1 + "/client/:id/keys", id: "listDeviceKeys", tag: "device keys",
+			summary: "List keys of the device", responses: []response{ok(dto.DeviceKeysResponse{})},
+		},
+		{
+			method: http.MethodDelete, path: v1 + "/client/:id/keys/:keyID", id: "revokeDeviceKey", tag: "device keys",
+			summary: "Revoke the key of the device", responses: []response{noContent},
+		},
+
+		// clock
+		{
+			method: http.MethodPost, path: v1 + "/client/:id/clock/sync", id: "syncClock", tag: "clock",
+			summary: "Exchange timestamps with the device", body: jsonBody(dto.ClockSyncRequest{}),
+			responses: []response{ok(dto.ClockSyncResponse{})},
+		},
+
+		// fleet
+		{
+			method: http.MethodPost, path: v1 + "/client/:id/heartbeat", id: "sendHeartbeat", tag: "fleet",
+			summary: "Report the state of the device", body: jsonBody(dto.HeartbeatRequest{}),
+			responses: []response{noContent},
+		},
+		{
+			method: http.MethodGet, path: v1 + "/client/:id/heartbeats", id: "listHeartbeats", tag: "fleet",
+			summary: "List heartbeats of the device", query: dto.HeartbeatsQuery{},
+			responses: []response{ok(dto.HeartbeatsResponse{})},
+		},
+		{
+			method: http.MethodGet, path: v1 + "/fleet/health", id: "fleetHealth", tag: "fleet",
+			summary: "Health of devices of the organization", responses: []response{ok(entities.FleetHealth{})},
+		},
+
+		// config
+		{
+			method: http.MethodGet, path: v1 + "/client/:id/config", id: "getClientConfig", tag: "config",
+			summary: "Get the effective config, long polls with If-None-Match and wait",
+			headers: []*Parameter{_ifNoneMatch}, query: dto.PollQuery{},
+			responses: []response{withETag(ok(dto.ConfigResponse{})), notChange},
+		},
+		{
+			method: http.MethodPut, path: v1 + "/client/:id/config", id: "setClientConfig", tag: "config",
+			summary: "Set the config of the client", body: jsonBody(dto.SensorConfig{}),
+			responses: []response{ok(entities.ConfigDocument{})},
+		},
+		{
+			method: http.MethodPost, path: v1 + "/client/:id/config/applied", id: "reportAppliedConfig", tag: "config",
+			summary: "Report the version of the config the device has applied",
+			body:    jsonBody(dto.AppliedConfigRequest{}), responses: []response{noContent},
+		},
+		{
+			method: http.MethodGet, path: v1 + "/zones/:id/config", id: "getZoneConfig", tag: "config",
+			summary: "Get the config of the zone", responses: []response{ok(entities.ConfigDocument{})},
+		},
+		{
+			method: http.MethodPut, path: v1 + "/zones/:id/config", id: "setZoneConfig", tag: "config",
+			summary: "Set the config of the zone", body: jsonBody(dto.SensorConfig{}),
+			responses: []response{ok(entities.ConfigDocument{})},
+		},
+
+		// commands
+		{
+			method: http.MethodPost, path: v1 + "/client/:id/commands", id: "enqueueCommand", tag: "commands",
+			summary: "Enqueue the command for the device", body: jsonBody(dto.CommandRequest{}),
+			responses: []response{created(dto.CreatedResponse{})},
+		},
+		{
+			method: http.MethodGet, path: v1 + "/client/:id/commands", id: "listCommands", tag: "commands",
+			summary: "List commands of the device", query: dto.CommandsQuery{},
+			responses: []response{ok(dto.CommandsResponse{})},
+		},
+		{
+			method: http.MethodGet, path: v1 + "/client/:id/commands/next", id: "pollCommands", tag: "commands",
+			summary: "Long poll pending commands", query: dto.PollQuery{},
+			responses: []response{ok(dto.CommandsResponse{})},
+		},
+		{
+			method: http.MethodGet, path: v1 + "/client/:id/commands/stream", id: "streamCommands", tag: "commands",
+			summary: "Stream commands as server-sent events",
+			responses: []response{{
+				status: http.StatusOK, description: "'command' events with commands, 'error' events with problems",
+				content: map[string]interface{}{_eventStreamType: &Schema{Type: "string"}},
+			}},
+		},
+		{
+			method: http.MethodGet, path: v1 + "/client/:id/commands/:commandID", id: "getCommand", tag: "commands",
+			summary: "Get the command", responses: []response{ok(entities.Command{})},
+		},
+		{
+			method: http.MethodPost, path: v1 + "/client/:id/commands/:commandID/ack", id: "acknowledgeCommand",
+			tag: "commands", summary: "Acknowledge the command", responses: []response{ok(entities.Command{})},
+		},
+		{
+			method: http.MethodPost, path: v1 + "/client/:id/commands/:commandID/result", id: "completeCommand",
+			tag: "commands", summary: "Report the result of the command",
+			body: jsonBody(dto.CommandResultRequest{}), responses: []response{ok(entities.Command{})},
+		},
+
+		// incidents
+		{
+			method: http.MethodGet, path: v1 + "/incidents", id: "listIncidents", tag: "incidents",
+			summary: "List incidents", query: dto.IncidentsQuery{}, responses: []response{ok(dto.IncidentsResponse{})},
+		},
+		{
+			method: http.MethodGet, path: v1 + "/incidents/:id", id: "getIncident", tag: "incidents",
+			summary: "Get the incident", responses: []response{ok(entities.Incident{})},
+		},
+		{
+			method: http.MethodGet, path: v1 + "/incidents/:id/evidence", id: "exportEvidence", tag: "incidents",
+			summary: "Export the signed evidence bundle", responses: []response{download(_zipType)},
+		},
+		{
+			method: http.MethodPost, path: v1 + "/incidents/:id/transitions", id: "transitionIncident", tag: "incidents",
+			summary: "Change the status of the incident", body: jsonBody(dto.TransitionRequest{}),
+			responses: []response{ok(entities.Incident{})},
+		},
+		{
+			method: http.MethodPost, path: v1 + "/incidents/:id/legal-hold", id: "holdIncident", tag: "retention",
+			summary: "Put the incident under the legal hold", body: jsonBody(dto.LegalHoldRequest{}),
+			responses: []response{ok(entities.Incident{})},
+		},
+		{
+			method: http.MethodDelete, path: v1 + "/incidents/:id/legal-hold", id: "releaseIncident", tag: "retention",
+			summary: "Release the legal hold", responses: []response{ok(entities.Incident{})},
+		},
+		{
+			method: http.MethodGet, path: v1 + "/retention/report", id: "retentionReport", tag: "retention",
+			summary: "What the next sweep removes", responses: []response{ok(entities.RetentionReport{})},
+		},
+
+		// detections and alerts
+		{
+			method: http.MethodGet, path: v1 + "/detections", id: "listDetections", tag: "detections",
+			summary: "List detections", query: dto.DetectionsQuery{}, responses: []response{ok(dto.DetectionsResponse{})},
+		},
+		{
+			method: http.MethodPost, path: v1 + "/rules", id: "createAlertRule", tag: "alerts",
+			summary: "Create the alert rule", body: jsonBody(dto.AlertRuleInfo{}),
+			responses: []response{created(dto.CreatedResponse{})},
+		},
+		{
+			method: http.MethodGet, path: v1 + "/rules", id: "listAlertRules", tag: "alerts",
+			summary: "List alert rules", responses: []response{ok(dto.AlertRulesResponse{})},
+		},
+		{
+			method: http.MethodPost, path: v1 + "/rules/dry-run", id: "dryRunAlertRule", tag: "alerts",
+			summary: "Evaluate the rule over past detections", body: jsonBody(dto.DryRunRequest{}),
+			responses: []response{ok(dto.DryRunResponse{})},
+		},
+		{
+			method: http.MethodGet, path: v1 + "/rules/:id", id: "getAlertRule", tag: "alerts",
+			summary: "Get the alert rule", responses: []response{ok(entities.AlertRule{})},
+		},
+		{
+			method: http.MethodPut, path: v1 + "/rules/:id", id: "updateAlertRule", tag: "alerts",
+			summary: "Replace the alert rule", body: jsonBody(dto.AlertRuleInfo{}), responses: []response{okEmpty},
+		},
+		{
+			method: http.MethodDelete, path: v1 + "/rules/:id", id: "deleteAlertRule", tag: "alerts",
+			summary: "Delete the alert rule", responses: []response{okEmpty},
+		},
+		{
+			method: http.MethodGet, path: v1 + "/alerts", id: "listAlerts", tag: "alerts",
+			summary: "List alerts", query: dto.AlertsQuery{}, responses: []response{ok(dto.AlertsResponse{})},
+		},
+
+		// zones
+		{
+			method: http.MethodPost, path: v1 + "/zones", id: "createZone", tag: "zones",
+			summary: "Create the zone", body: jsonBody(dto.ZoneInfo{}), responses: []response{created(dto.CreatedResponse{})},
+		},
+		{
+			method: http.MethodGet, path: v1 + "/zones", id: "listZones", tag: "zones",
+			summary: "List zones", responses: []response{ok(dto.ZonesResponse{})},
+		},
+		{
+			method: http.MethodGet, path: v1 + "/zones/:id", id: "getZone", tag: "zones",
+			summary: "Get the zone", responses: []response{ok(entities.Zone{})},
+		},
+		{
+			method: http.MethodPut, path: v1 + "/zones/:id", id: "updateZone", tag: "zones",
+			summary: "Replace the zone", body: jsonBody(dto.ZoneInfo{}), responses: []response{okEmpty},
+		},
+		{
+			method: http.MethodDelete, path: v1 + "/zones/:id", id: "deleteZone", tag: "zones",
+			summary: "Delete the zone", responses: []response{okEmpty},
+		},
+
+		// webhooks
+		{
+			method: http.MethodPost, path: v1 + "/webhooks", id: "createWebhook", tag: "webhooks",
+			summary: "Subscribe the URL to events, the returned secret signs deliveries",
+			body:    jsonBody(dto.WebhookInfo{}), responses: []response{created(dto.WebhookCreatedResponse{})},
+		},
+		{
+			method: http.MethodGet, path: v1 + "/webhooks", id: "listWebhooks", tag: "webhooks",
+			summary: "List webhooks", responses: []response{ok(dto.WebhooksResponse{})},
+		},
+		{
+			method: http.MethodGet, path: v1 + "/webhooks/:id", id: "getWebhook", tag: "webhooks",
+			summary: "Get the webhook", responses: []response{ok(entities.Webhook{})},
+		},
+		{
+			method: http.MethodDelete, path: v1 + "/webhooks/:id", id: "deleteWebhook", tag: "webhooks",
+			summary: "Delete the webhook", responses: []response{okEmpty},
+		},
+
+		// admin
+		{
+			method: http.MethodPost, path: v1 + "/organizations", id: "createOrganization", tag: "organizations",
+			auth: _admin, summary: "Create the organization with its API key", body: jsonBody(dto.OrganizationInfo{}),
+			responses: []response{created(dto.OrganizationCreatedResponse{})},
+		},
+		{
+			method: http.MethodGet, path: v1 + "/organizations", id: "listOrganizations", tag: "organizations",
+			auth: _admin, summary: "List organizations", responses: []response{ok(dto.OrganizationsResponse{})},
+		},
+		{
+			method: http.MethodGet, path: v1 + "/organizations/:id", id: "getOrganization", tag: "organizations",
+			auth: _admin, summary: "Get the organization", responses: []response{ok(entities.Organization{})},
+		},
+		{
+			method: http.MethodPut, path: v1 + "/organizations/:id", id: "updateOrganization", tag: "organizations",
+			auth: _admin, summary: "Replace the organization", body: jsonBody(dto.OrganizationInfo{}),
+			responses: []response{okEmpty},
+		},
+		{
+			method: http.MethodPost, path: v1 + "/organizations/:id/key", id: "rotateOrganizationKey",
+			tag: "organizations", auth: _admin, summary: "Issue the new API key, the old one stops working",
+			responses: []response{ok(dto.APIKeyResponse{})},
+		},
+		{
+			method: http.MethodPost, path: v1 + "/encryption/rewrap", id: "rewrapDataKeys", tag: "encryption",
+			auth: _admin, summary: "Rewrap data keys of the audio with the current key",
+			responses: []response{ok(uCase.RewrapResult{})},
+		},
+		{
+			method: http.MethodPost, path: v1 + "/dead-letters/replay", id: "replayDeadLetters", tag: "dead-letters",
+			auth: _admin, summary: "Produce dead letters of detections to the detections topic again",
+			body: jsonBody(dto.DeadLetterReplayRequest{}), responses: []response{ok(entities.DeadLetterReplayResult{})},
+		},
+
+		// audit
+		{
+			method: http.MethodGet, path: v1 + "/audit", id: "listAudit", tag: "audit",
+			summary: "List audit entries, format=jsonl exports them signed", query: dto.AuditQuery{},
+			responses: []response{{
+				status: http.StatusOK, description: "OK",
+				content: map[string]interface{}{_jsonType: dto.AuditResponse{}, _ndjsonType: binary()},
+			}},
+		},
+	}
+}
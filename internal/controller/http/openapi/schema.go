@@ -0,0 +1,254 @@
+package openapi
+
+import (
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema is the subset of the OpenAPI 3.0 schema object the service uses. AdditionalProperties is false or
+// the *Schema of values
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+}
+
+const _refPrefix = "#/components/schemas/"
+
+var (
+	_timeType     = reflect.TypeOf(time.Time{})
+	_durationType = reflect.TypeOf(time.Duration(0))
+	_objectIDType = reflect.TypeOf(primitive.ObjectID{})
+	_uuidType     = reflect.TypeOf(uuid.UUID{})
+	_bytesType    = reflect.TypeOf([]byte(nil))
+)
+
+// generator derives schemas of Go types from their json and binding tags, structs become components
+type generator struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newGenerator() *generator {
+	return &generator{components: make(map[string]*Schema), names: make(map[reflect.Type]string)}
+}
+
+func (g *generator) schema(t reflect.Type) *Schema {
+	switch t {
+	case _timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case _durationType:
+		return &Schema{Type: "integer", Format: "int64", Description: "nanoseconds"}
+	case _objectIDType:
+		return &Schema{Type: "string", Format: "objectid"}
+	case _uuidType:
+		return &Schema{Type: "string", Format: "uuid"}
+	case _bytesType:
+		return &Schema{Type: "string", Format: "byte"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := g.schema(t.Elem())
+		if schema.Ref != "" {
+			// siblings of $ref are ignored
+			return &Schema{AllOf: []*Schema{schema}, Nullable: true}
+		}
+
+		schema.Nullable = true
+		return schema
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		return g.component(t)
+	default:
+		// interface{} takes any value
+		return &Schema{}
+	}
+}
+
+// component registers the struct once and refers to it, types of different packages with the same name
+// are told apart by the package
+func (g *generator) component(t reflect.Type) *Schema {
+	if name, ok := g.names[t]; ok {
+		return &Schema{Ref: _refPrefix + name}
+	}
+
+	name := t.Name()
+	if _, taken := g.components[name]; taken || name == "" {
+		pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+
+	g.names[t] = name
+	g.components[name] = &Schema{} // reserved for recursive types
+	g.components[name] = g.object(t)
+
+	return &Schema{Ref: _refPrefix + name}
+}
+
+func (g *generator) object(t reflect.Type) *Schema {
+	object := &Schema{Type: "object", Properties: make(map[string]*Schema), AdditionalProperties: false}
+	g.fields(t, object)
+
+	return object
+}
+
+// fields adds exported fields of the struct to the object, fields of embedded structs are promoted
+func (g *generator) fields(t reflect.Type, object *Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			g.fields(field.Type, object)
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		schema := g.schema(field.Type)
+		if constrain(schema, field.Tag.Get("binding")) {
+			// pointers of required fields tell the zero value from the absent one, null is not allowed
+			if len(schema.AllOf) > 0 {
+				schema = schema.AllOf[0]
+			}
+
+			schema.Nullable = false
+			object.Required = append(object.Required, name)
+		}
+
+		object.Properties[name] = schema
+	}
+}
+
+// constrain applies rules of the binding tag to the schema and tells if the field is required
+func constrain(schema *Schema, binding string) bool {
+	var required bool
+
+	target := schema
+	if len(schema.AllOf) > 0 {
+		target = &Schema{} // constraints of components are their own
+	}
+
+	for _, rule := range strings.Split(binding, ",") {
+		key, value, _ := strings.Cut(rule, "=")
+		switch key {
+		case "required":
+			required = true
+		case "oneof":
+			target.Enum = strings.Fields(value)
+		case "min", "max":
+			limit, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+
+			switch {
+			case target.Type == "string" && key == "min":
+				length := int(limit)
+				target.MinLength = &length
+			case target.Type == "array" && key == "min":
+				length := int(limit)
+				target.MinItems = &length
+			case key == "min":
+				target.Minimum = &limit
+			default:
+				target.Maximum = &limit
+			}
+		}
+	}
+
+	return required
+}
+
+// parameters describes fields of the query struct by their form tags
+func (g *generator) parameters(query interface{}) []*Parameter {
+	if query == nil {
+		return nil
+	}
+
+	var (
+		t          = reflect.TypeOf(query)
+		parameters = make([]*Parameter, 0, t.NumField())
+	)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag := strings.Split(field.Tag.Get("form"), ",")
+		if tag[0] == "" || tag[0] == "-" {
+			continue
+		}
+
+		schema := g.schema(field.Type)
+		if field.Type == _durationType {
+			schema = &Schema{Type: "string", Format: "duration", Description: "Go duration, e.g. 30s"}
+		}
+
+		for _, option := range tag[1:] {
+			if strings.HasPrefix(option, "default=") {
+				schema.Default = defaultValue(schema, strings.TrimPrefix(option, "default="))
+			}
+		}
+
+		required := constrain(schema, field.Tag.Get("binding"))
+		parameters = append(parameters, &Parameter{Name: tag[0], In: "query", Required: required, Schema: schema})
+	}
+
+	return parameters
+}
+
+func defaultValue(schema *Schema, value string) interface{} {
+	switch schema.Type {
+	case "integer":
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,20 @@
+package openapi
+
+import (
+	_ "embed"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+//go:embed docs.html
+var _docs []byte
+
+// Serve writes the document
+func (d *Document) Serve(c *gin.Context) {
+	c.IndentedJSON(http.StatusOK, d)
+}
+
+// Docs serves the page which renders the document from openapi.json next to it
+func Docs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", _docs)
+}
@@ -0,0 +1,415 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Violation is the part of the request which doesn't match the document, e.g. "query.limit"
+type Violation struct {
+	Location string `json:"location"`
+	Error    string `json:"error"`
+}
+
+// _maxValidatedBody limits JSON bodies which are read to be checked, files are streamed to handlers instead
+const _maxValidatedBody = 1 << 20
+
+// _validatedTypes are media types of bodies checked against schemas, other bodies are left to handlers
+var _validatedTypes = map[string]bool{_jsonType: true, _mergePatchType: true}
+
+// Validator checks requests against the document in the strict mode: unknown query parameters and fields
+// of JSON bodies are rejected as well as bodies of routes which take none. Routes out of the document,
+// e.g. the docs themselves, pass as they are. It reads bodies, so it goes after the authentication
+func (d *Document) Validator() gin.HandlerFunc {
+	routes := make(map[string]Operation)
+	for path, item := range d.Paths {
+		for method, op := range item {
+			routes[strings.ToUpper(method)+" "+ginPath(path)] = op
+		}
+	}
+
+	return func(c *gin.Context) {
+		op, ok := routes[c.Request.Method+" "+c.FullPath()]
+		if !ok {
+			c.Next()
+			return
+		}
+
+		if err := d.validate(c, op); err != nil {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+type validation struct {
+	document   *Document
+	violations []Violation
+}
+
+func (v *validation) fail(location, format string, args ...interface{}) {
+	v.violations = append(v.violations, Violation{Location: location, Error: fmt.Sprintf(format, args...)})
+}
+
+func (d *Document) validate(c *gin.Context, op Operation) error {
+	var (
+		v        = &validation{document: d}
+		query    = c.Request.URL.Query()
+		declared = make(map[string]bool)
+	)
+
+	for _, parameter := range op.Parameters {
+		parameter = d.parameter(parameter)
+		location := parameter.In + "." + parameter.Name
+
+		var value string
+		switch parameter.In {
+		case "header":
+			value = c.GetHeader(parameter.Name)
+		case "path":
+			value = c.Param(parameter.Name)
+		case "query":
+			declared[parameter.Name] = true
+			value = query.Get(parameter.Name)
+		}
+
+		if value == "" {
+			if parameter.Required {
+				v.fail(location, "is required")
+			}
+
+			continue
+		}
+
+		v.scalar(location, parameter.Schema, value)
+	}
+
+	unknown := make([]string, 0)
+	for name := range query {
+		if !declared[name] {
+			unknown = append(unknown, name)
+		}
+	}
+
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		v.fail("query."+name, "is unknown")
+	}
+
+	if err := v.body(c, op.RequestBody); err != nil {
+		return err
+	}
+
+	if len(v.violations) > 0 {
+		return apperr.WithDetails(
+			apperr.InvalidArgument,
+			fmt.Sprintf("the request doesn't match the API: %s %s", v.violations[0].Location, v.violations[0].Error),
+			v.violations,
+		)
+	}
+
+	return nil
+}
+
+// body checks the media type of the body and JSON bodies against their schemas, the body is put back for
+// the handler
+func (v *validation) body(c *gin.Context, body *RequestBody) error {
+	if body == nil {
+		if c.Request.ContentLength > 0 {
+			v.fail("body", "the route takes no body")
+		}
+
+		return nil
+	}
+
+	mediaType := c.ContentType()
+
+	media, ok := matchMedia(body.Content, mediaType)
+	if !ok {
+		return apperr.New(
+			apperr.UnsupportedMedia, "the body must be one of "+strings.Join(mediaTypes(body.Content), ", "),
+		)
+	}
+
+	if !_validatedTypes[mediaType] {
+		return nil
+	}
+
+	raw, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, _maxValidatedBody))
+	if err != nil {
+		return apperr.Wrap(
+			apperr.InvalidArgument, fmt.Errorf("can't read the body of at most %d bytes: %w", _maxValidatedBody, err),
+		)
+	}
+
+	c.Request.Body = io.NopCloser(bytes.NewReader(raw))
+
+	if len(bytes.TrimSpace(raw)) == 0 {
+		if body.Required {
+			v.fail("body", "is required")
+		}
+
+		return nil
+	}
+
+	var value interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		v.fail("body", "is not a JSON document")
+		return nil
+	}
+
+	v.value("body", media.Schema, value)
+
+	return nil
+}
+
+func matchMedia(content map[string]*MediaType, mediaType string) (*MediaType, bool) {
+	if media, ok := content[mediaType]; ok {
+		return media, true
+	}
+
+	if mediaType != "" {
+		if media, ok := content[mediaType[:strings.Index(mediaType+"/", "/")]+"/*"]; ok {
+			return media, true
+		}
+	}
+
+	media, ok := content[_anyType]
+
+	return media, ok
+}
+
+func mediaTypes(content map[string]*MediaType) []string {
+	types := make([]string, 0, len(content))
+	for mediaType := range content {
+		types = append(types, mediaType)
+	}
+
+	sort.Strings(types)
+
+	return types
+}
+
+// scalar checks the value of the header, the path or the query parameter
+func (v *validation) scalar(location string, schema *Schema, raw string) {
+	schema = v.document.schema(schema)
+
+	switch schema.Type {
+	case "integer":
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			v.fail(location, "must be an integer")
+			return
+		}
+
+		v.bounds(location, schema, float64(n))
+	case "number":
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			v.fail(location, "must be a number")
+			return
+		}
+
+		v.bounds(location, schema, n)
+	case "boolean":
+		if _, err := strconv.ParseBool(raw); err != nil {
+			v.fail(location, "must be a boolean")
+		}
+	case "string":
+		v.string(location, schema, raw)
+	}
+}
+
+// value checks the decoded JSON value
+func (v *validation) value(location string, schema *Schema, value interface{}) {
+	schema = v.document.schema(schema)
+
+	if value == nil {
+		if !schema.Nullable && schema.Type != "" {
+			v.fail(location, "must not be null")
+		}
+
+		return
+	}
+
+	for _, part := range schema.AllOf {
+		v.value(location, part, value)
+	}
+
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			v.fail(location, "must be an object")
+			return
+		}
+
+		v.object(location, schema, object)
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			v.fail(location, "must be an array")
+			return
+		}
+
+		if schema.MinItems != nil && len(items) < *schema.MinItems {
+			v.fail(location, "must have at least %d items", *schema.MinItems)
+		}
+
+		for i, item := range items {
+			v.value(fmt.Sprintf("%s[%d]", location, i), schema.Items, item)
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			v.fail(location, "must be a string")
+			return
+		}
+
+		v.string(location, schema, s)
+	case "integer", "number":
+		number, ok := value.(json.Number)
+		if !ok {
+			v.fail(location, "must be a %s", schema.Type)
+			return
+		}
+
+		if schema.Type == "integer" {
+			if _, err := number.Int64(); err != nil {
+				v.fail(location, "must be an integer")
+				return
+			}
+		}
+
+		n, err := number.Float64()
+		if err != nil {
+			v.fail(location, "must be a number")
+			return
+		}
+
+		v.bounds(location, schema, n)
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			v.fail(location, "must be a boolean")
+		}
+	}
+}
+
+func (v *validation) object(location string, schema *Schema, object map[string]interface{}) {
+	for _, name := range schema.Required {
+		if _, ok := object[name]; !ok {
+			v.fail(location+"."+name, "is required")
+		}
+	}
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		if property, ok := schema.Properties[name]; ok {
+			v.value(location+"."+name, property, object[name])
+			continue
+		}
+
+		switch additional := schema.AdditionalProperties.(type) {
+		case bool:
+			if !additional {
+				v.fail(location+"."+name, "is unknown")
+			}
+		case *Schema:
+			v.value(location+"."+name, additional, object[name])
+		}
+	}
+}
+
+func (v *validation) string(location string, schema *Schema, s string) {
+	if len(schema.Enum) > 0 && !contains(schema.Enum, s) {
+		v.fail(location, "must be one of %s", strings.Join(schema.Enum, ", "))
+	}
+
+	if schema.MinLength != nil && len(s) < *schema.MinLength {
+		v.fail(location, "must be at least %d characters long", *schema.MinLength)
+	}
+
+	var err error
+
+	switch schema.Format {
+	case "date-time":
+		_, err = time.Parse(time.RFC3339, s)
+	case "uuid":
+		_, err = uuid.Parse(s)
+	case "byte":
+		_, err = base64.StdEncoding.DecodeString(s)
+	case "duration":
+		_, err = time.ParseDuration(s)
+	case "objectid":
+		_, err = primitive.ObjectIDFromHex(s)
+	}
+
+	if err != nil {
+		v.fail(location, "must be %s", schema.Format)
+	}
+}
+
+func (v *validation) bounds(location string, schema *Schema, n float64) {
+	if schema.Minimum != nil && n < *schema.Minimum {
+		v.fail(location, "must be at least %v", *schema.Minimum)
+	}
+
+	if schema.Maximum != nil && n > *schema.Maximum {
+		v.fail(location, "must be at most %v", *schema.Maximum)
+	}
+}
+
+// schema resolves the reference to the component
+func (d *Document) schema(schema *Schema) *Schema {
+	if schema == nil {
+		return &Schema{}
+	}
+
+	if schema.Ref != "" {
+		return d.schema(d.Components.Schemas[strings.TrimPrefix(schema.Ref, _refPrefix)])
+	}
+
+	return schema
+}
+
+func (d *Document) parameter(parameter *Parameter) *Parameter {
+	if parameter.Ref != "" {
+		return d.Components.Parameters[parameter.Ref[strings.LastIndex(parameter.Ref, "/")+1:]]
+	}
+
+	return parameter
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
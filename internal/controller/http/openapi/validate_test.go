@@ -0,0 +1,188 @@
+package openapi_test
+
+import (
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/Imm0bilize/gunshot-api-service/internal/controller/http/openapi"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestValidator(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var (
+		requestID = uuid.NewString()
+		client    = `{"locationName": "Main st.", "fullName": "Sensor 1", "latitude": 0, "longitude": 37.6,` +
+			` "notificationMethods": ["sms"]}`
+	)
+
+	testTable := []struct {
+		name          string
+		method        string
+		target        string
+		contentType   string
+		body          string
+		noRequestID   bool
+		expKind       apperr.Kind
+		expViolations []openapi.Violation
+	}{
+		{
+			name: "valid body", method: http.MethodPost, target: "/api/v1/client",
+			contentType: "application/json", body: client,
+		},
+		{
+			name: "valid query", method: http.MethodGet, target: "/api/v1/incidents?limit=10&from=2023-01-02T15:04:05Z",
+		},
+		{
+			name: "valid merge patch", method: http.MethodPatch, target: "/api/v1/client/1",
+			contentType: "application/merge-patch+json", body: `{"fullName": "Sensor 2", "latitude": 10}`,
+		},
+		{
+			name: "removed name in merge patch", method: http.MethodPatch, target: "/api/v1/client/1",
+			contentType: "application/merge-patch+json", body: `{"fullName": null}`,
+			expKind: apperr.InvalidArgument,
+		},
+		{
+			name: "any media of the upload", method: http.MethodPost, target: "/api/v1/client/1/1672671845000/upload",
+			contentType: "audio/wav", body: "RIFF",
+		},
+		{
+			name: "too large body", method: http.MethodPost, target: "/api/v1/client",
+			contentType: "application/json", body: `{"fullName": "` + strings.Repeat("a", 1<<20) + `"}`,
+			expKind: apperr.InvalidArgument,
+		},
+		{
+			name: "route out of the document", method: http.MethodGet, target: "/ping?verbose=1", noRequestID: true,
+		},
+		{
+			name: "missing request id", method: http.MethodGet, target: "/api/v1/incidents", noRequestID: true,
+			expKind:       apperr.InvalidArgument,
+			expViolations: []openapi.Violation{{Location: "header.X-REQUEST-ID", Error: "is required"}},
+		},
+		{
+			name: "unknown and invalid query parameters", method: http.MethodGet,
+			target:  "/api/v1/incidents?limit=1000&from=yesterday&sort=asc",
+			expKind: apperr.InvalidArgument,
+			expViolations: []openapi.Violation{
+				{Location: "query.from", Error: "must be date-time"},
+				{Location: "query.limit", Error: "must be at most 500"},
+				{Location: "query.sort", Error: "is unknown"},
+			},
+		},
+		{
+			name: "invalid path parameter", method: http.MethodPost, target: "/api/v1/client/1/yesterday/upload",
+			expKind:       apperr.InvalidArgument,
+			expViolations: []openapi.Violation{{Location: "path.ts", Error: "must be an integer"}},
+		},
+		{
+			name: "unknown field and wrong types", method: http.MethodPost, target: "/api/v1/client",
+			contentType: "application/json",
+			body:        `{"locationName": 1, "fullName": "Sensor 1", "latitude": 91, "longitude": null, "zone": "A"}`,
+			expKind:     apperr.InvalidArgument,
+			expViolations: []openapi.Violation{
+				{Location: "body.notificationMethods", Error: "is required"},
+				{Location: "body.latitude", Error: "must be at most 90"},
+				{Location: "body.locationName", Error: "must be a string"},
+				{Location: "body.longitude", Error: "must not be null"},
+				{Location: "body.zone", Error: "is unknown"},
+			},
+		},
+		{
+			name: "nested schemas", method: http.MethodPost, target: "/api/v1/rules/dry-run",
+			contentType: "application/json",
+			body:        `{"rule": {"name": "night", "expression": "count > 1", "window": -1}, "days": 1.5}`,
+			expKind:     apperr.InvalidArgument,
+			expViolations: []openapi.Violation{
+				{Location: "body.days", Error: "must be an integer"},
+				{Location: "body.rule.window", Error: "must be at least 0"},
+			},
+		},
+		{
+			name: "missing body", method: http.MethodPost, target: "/api/v1/client", contentType: "application/json",
+			expKind:       apperr.InvalidArgument,
+			expViolations: []openapi.Violation{{Location: "body", Error: "is required"}},
+		},
+		{
+			name: "body of the route without one", method: http.MethodPost, target: "/api/v1/client/1/restore",
+			contentType: "application/json", body: "{}",
+			expKind:       apperr.InvalidArgument,
+			expViolations: []openapi.Violation{{Location: "body", Error: "the route takes no body"}},
+		},
+		{
+			name: "wrong media type", method: http.MethodPatch, target: "/api/v1/client/1",
+			contentType: "application/json", body: `{"fullName": "Sensor 1"}`,
+			expKind: apperr.UnsupportedMedia,
+		},
+	}
+
+	for _, tCase := range testTable {
+		t.Run(tCase.name, func(t *testing.T) {
+			var (
+				handled bool
+				body    string
+				last    error
+			)
+
+			handler := func(c *gin.Context) {
+				handled = true
+
+				raw, err := io.ReadAll(c.Request.Body)
+				require.NoError(t, err)
+
+				body = string(raw)
+			}
+
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Next()
+				if err := c.Errors.Last(); err != nil {
+					last = err.Err
+				}
+			})
+			router.Use(openapi.New().Validator())
+
+			for _, path := range []string{
+				"/api/v1/client",
+				"/api/v1/client/:id",
+				"/api/v1/client/:id/:ts/upload",
+				"/api/v1/client/:id/restore",
+				"/api/v1/incidents",
+				"/api/v1/rules/dry-run",
+				"/ping",
+			} {
+				router.Handle(tCase.method, path, handler)
+			}
+
+			req := httptest.NewRequest(tCase.method, tCase.target, strings.NewReader(tCase.body))
+			if tCase.contentType != "" {
+				req.Header.Set("Content-Type", tCase.contentType)
+			}
+
+			if !tCase.noRequestID {
+				req.Header.Set("X-REQUEST-ID", requestID)
+			}
+
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			if tCase.expKind == "" {
+				require.NoError(t, last)
+				require.True(t, handled)
+				require.Equal(t, tCase.body, body)
+				return
+			}
+
+			require.Error(t, last)
+			require.False(t, handled)
+			require.Equal(t, tCase.expKind, apperr.KindOf(last))
+			if tCase.expViolations != nil {
+				require.Equal(t, tCase.expViolations, apperr.DetailsOf(last))
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package http
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/controller/http/openapi"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/uCase"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// TestOpenAPIMatchesRoutes fails when routes are added to Handler.InitAPI without the document or the other
+// way round
+func TestOpenAPIMatchesRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := NewHTTPServer(zap.NewNop(), &uCase.UseCase{}, "")
+
+	routes := make([]string, 0)
+	for _, route := range router.Routes() {
+		if strings.HasPrefix(route.Path, "/api/v1/") {
+			routes = append(routes, route.Method+" "+route.Path)
+		}
+	}
+
+	sort.Strings(routes)
+
+	require.Equal(t, routes, openapi.New().Routes())
+}
+
+func TestOpenAPIServed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := NewHTTPServer(zap.NewNop(), &uCase.UseCase{}, "")
+
+	for path, contentType := range map[string]string{
+		"/api/openapi.json": "application/json; charset=utf-8",
+		"/api/docs":         "text/html; charset=utf-8",
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+		require.Equal(t, http.StatusOK, w.Code, path)
+		require.Equal(t, contentType, w.Header().Get("Content-Type"), path)
+	}
+}
+
+type rejectingOrganizations struct {
+	uCase.OrganizationUseCase
+}
+
+func (rejectingOrganizations) Authenticate(context.Context, string) (entities.Organization, error) {
+	return entities.Organization{}, uCase.ErrUnauthenticated
+}
+
+// TestValidationAfterAuthentication checks that anonymous requests are rejected before their bodies are read
+func TestValidationAfterAuthentication(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := NewHTTPServer(zap.NewNop(), &uCase.UseCase{Organization: rejectingOrganizations{}}, "")
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/client", strings.NewReader(`{"fullName": 1}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-REQUEST-ID", "6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+}
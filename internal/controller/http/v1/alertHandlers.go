@@ -0,0 +1,209 @@
+package v1
+
+import (
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/Imm0bilize/gunshot-api-service/internal/controller/http/dto"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"net/http"
+)
+
+// optionalObjectID converts the hex id, the empty string is the zero id
+func optionalObjectID(id string) (primitive.ObjectID, error) {
+	if id == "" {
+		return primitive.NilObjectID, nil
+	}
+
+	return primitive.ObjectIDFromHex(id)
+}
+
+func toAlertRule(req dto.AlertRuleInfo) (entities.AlertRule, error) {
+	clientID, err := optionalObjectID(req.ClientID)
+	if err != nil {
+		return entities.AlertRule{}, errors.Wrap(err, "invalid clientID")
+	}
+
+	zoneID, err := optionalObjectID(req.ZoneID)
+	if err != nil {
+		return entities.AlertRule{}, errors.Wrap(err, "invalid zoneID")
+	}
+
+	return entities.AlertRule{
+		Name:       req.Name,
+		ClientID:   clientID,
+		ZoneID:     zoneID,
+		Expression: req.Expression,
+		Window:     req.Window,
+		Timezone:   req.Timezone,
+		Enabled:    req.Enabled,
+	}, nil
+}
+
+func (h *Handler) CreateAlertRule(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		req       dto.AlertRuleInfo
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
+
+	rule, err := toAlertRule(req)
+	if err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
+
+	id, err := h.domain.AlertRule.Create(c.Request.Context(), requestID, &rule)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.CreatedResponse{ID: id})
+}
+
+func (h *Handler) ListAlertRules(c *gin.Context) {
+	requestID := c.MustGet("requestID").(uuid.UUID)
+
+	alertRules, err := h.domain.AlertRule.List(c.Request.Context(), requestID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.AlertRulesResponse{Rules: alertRules})
+}
+
+func (h *Handler) GetAlertRule(c *gin.Context) {
+	requestID := c.MustGet("requestID").(uuid.UUID)
+
+	rule, err := h.domain.AlertRule.Get(c.Request.Context(), requestID, c.Param("id"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+func (h *Handler) UpdateAlertRule(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		req       dto.AlertRuleInfo
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
+
+	rule, err := toAlertRule(req)
+	if err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
+
+	if err := h.domain.AlertRule.Update(c.Request.Context(), requestID, c.Param("id"), &rule); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (h *Handler) DeleteAlertRule(c *gin.Context) {
+	requestID := c.MustGet("requestID").(uuid.UUID)
+
+	if err := h.domain.AlertRule.Delete(c.Request.Context(), requestID, c.Param("id")); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (h *Handler) DryRunAlertRule(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		req       dto.DryRunRequest
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
+
+	rule, err := toAlertRule(req.Rule)
+	if err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
+
+	result, err := h.domain.AlertRule.DryRun(c.Request.Context(), requestID, rule, req.Days)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DryRunResponse{
+		Evaluated: result.Evaluated,
+		Matched:   len(result.Alerts),
+		Alerts:    result.Alerts,
+	})
+}
+
+func (h *Handler) ListAlerts(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		query     dto.AlertsQuery
+	)
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
+
+	ruleID, err := optionalObjectID(query.RuleID)
+	if err != nil {
+		_ = c.Error(apperr.New(apperr.InvalidArgument, "invalid ruleID"))
+		return
+	}
+
+	clientID, err := optionalObjectID(query.ClientID)
+	if err != nil {
+		_ = c.Error(apperr.New(apperr.InvalidArgument, "invalid clientID"))
+		return
+	}
+
+	zoneID, err := optionalObjectID(query.ZoneID)
+	if err != nil {
+		_ = c.Error(apperr.New(apperr.InvalidArgument, "invalid zoneID"))
+		return
+	}
+
+	alerts, err := h.domain.Alert.List(
+		c.Request.Context(),
+		requestID,
+		entities.AlertFilter{
+			RuleID:   ruleID,
+			ClientID: clientID,
+			ZoneID:   zoneID,
+			From:     query.From,
+			To:       query.To,
+			Limit:    query.Limit,
+			Offset:   query.Offset,
+		},
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.AlertsResponse{Alerts: alerts})
+}
@@ -20,28 +20,179 @@ func NewHandler(logger *zap.Logger, domain *uCase.UseCase) *Handler {
 	}
 }
 
+// InitAPI registers routes of the API, validate checks requests of the authenticated caller against the
+// OpenAPI document, deduplicate serves retries of the caller's POSTs and PATCHes with idempotency keys
 func (h *Handler) InitAPI(router *gin.RouterGroup,
-	injectRequestID, injectClientID func(c *gin.Context),
+	injectRequestID, injectClientID, authenticate, requireAdmin, validate, deduplicate func(c *gin.Context),
 ) {
 
 	v1 := router.Group("v1")
 	{
 		client := v1.Group("client")
 		{
-			client.Use(injectRequestID)
+			client.Use(injectRequestID, authenticate, validate, deduplicate)
 
-			client.POST("", h.RegisterNewClient)
+			client.POST("", h.audit("client"), h.RegisterNewClient)
 
 			clientID := client.Group(":id")
 			{
-				clientID.Use(injectClientID)
+				clientID.Use(injectClientID, h.requireClient)
 
 				clientID.GET("", h.GetClient)
-				clientID.PUT("", h.UpdateClient)
-				clientID.DELETE("", h.DeleteClient)
+				clientID.PUT("", h.audit("client"), h.UpdateClient)
+				clientID.PATCH("", h.audit("client"), h.PatchClient)
+				clientID.DELETE("", h.audit("client"), h.DeleteClient)
 
 				clientID.POST(":ts/upload", h.UploadAudio)
+				clientID.GET("audio/verify", h.VerifyAudioChain)
+
+				clientID.POST("keys", h.audit("device_key"), h.RegisterDeviceKey)
+				clientID.GET("keys", h.ListDeviceKeys)
+				clientID.DELETE("keys/:keyID", h.audit("device_key"), h.RevokeDeviceKey)
+				clientID.POST("clock/sync", h.SyncClock)
+
+				clientID.POST("heartbeat", h.SendHeartbeat)
+				clientID.GET("heartbeats", h.ListHeartbeats)
+
+				clientID.GET("config", h.GetClientConfig)
+				clientID.PUT("config", h.audit("client_config"), h.SetClientConfig)
+				clientID.POST("config/applied", h.ReportAppliedConfig)
+
+				clientID.POST("commands", h.audit("command"), h.EnqueueCommand)
+				clientID.GET("commands", h.ListCommands)
+				clientID.GET("commands/next", h.PollCommands)
+				clientID.GET("commands/stream", h.StreamCommands)
+				clientID.GET("commands/:commandID", h.GetCommand)
+				clientID.POST("commands/:commandID/ack", h.AcknowledgeCommand)
+				clientID.POST("commands/:commandID/result", h.CompleteCommand)
+			}
+
+			// deleted clients are reachable until they are purged
+			deleted := client.Group(":id")
+			{
+				deleted.Use(injectClientID)
+
+				deleted.POST("restore", h.audit("client"), h.RestoreClient)
+				deleted.GET("history", h.ClientHistory)
+				deleted.GET("export", h.audit("client"), h.ExportClientData)
+				deleted.POST("erase", h.audit("client"), h.EraseClientData)
 			}
 		}
+
+		clients := v1.Group("clients")
+		{
+			clients.Use(injectRequestID, authenticate, validate, deduplicate)
+
+			clients.GET("", h.ListClients)
+			clients.POST("import", h.audit("client"), h.ImportClients)
+			clients.GET("export", h.ExportClients)
+		}
+
+		incidents := v1.Group("incidents")
+		{
+			incidents.Use(injectRequestID, authenticate, validate, deduplicate)
+
+			incidents.GET("", h.ListIncidents)
+			incidents.GET(":id", h.GetIncident)
+			incidents.GET(":id/evidence", h.ExportEvidence)
+			incidents.POST(":id/transitions", h.audit("incident"), h.TransitionIncident)
+			incidents.POST(":id/legal-hold", h.audit("incident"), h.HoldIncident)
+			incidents.DELETE(":id/legal-hold", h.audit("incident"), h.ReleaseIncident)
+		}
+
+		retention := v1.Group("retention")
+		{
+			retention.Use(injectRequestID, authenticate, validate, deduplicate)
+
+			retention.GET("report", h.RetentionReport)
+		}
+
+		alertRules := v1.Group("rules")
+		{
+			alertRules.Use(injectRequestID, authenticate, validate, deduplicate)
+
+			alertRules.POST("", h.audit("alert_rule"), h.CreateAlertRule)
+			alertRules.GET("", h.ListAlertRules)
+			alertRules.POST("dry-run", h.DryRunAlertRule)
+			alertRules.GET(":id", h.GetAlertRule)
+			alertRules.PUT(":id", h.audit("alert_rule"), h.UpdateAlertRule)
+			alertRules.DELETE(":id", h.audit("alert_rule"), h.DeleteAlertRule)
+		}
+
+		alerts := v1.Group("alerts")
+		{
+			alerts.Use(injectRequestID, authenticate, validate, deduplicate)
+
+			alerts.GET("", h.ListAlerts)
+		}
+
+		detections := v1.Group("detections")
+		{
+			detections.Use(injectRequestID, authenticate, validate, deduplicate)
+
+			detections.GET("", h.ListDetections)
+		}
+
+		zones := v1.Group("zones")
+		{
+			zones.Use(injectRequestID, authenticate, validate, deduplicate)
+
+			zones.POST("", h.audit("zone"), h.CreateZone)
+			zones.GET("", h.ListZones)
+			zones.GET(":id", h.GetZone)
+			zones.PUT(":id", h.audit("zone"), h.UpdateZone)
+			zones.DELETE(":id", h.audit("zone"), h.DeleteZone)
+			zones.GET(":id/config", h.GetZoneConfig)
+			zones.PUT(":id/config", h.audit("zone_config"), h.SetZoneConfig)
+		}
+
+		webhooks := v1.Group("webhooks")
+		{
+			webhooks.Use(injectRequestID, authenticate, validate, deduplicate)
+
+			webhooks.POST("", h.audit("webhook"), h.CreateWebhook)
+			webhooks.GET("", h.ListWebhooks)
+			webhooks.GET(":id", h.GetWebhook)
+			webhooks.DELETE(":id", h.audit("webhook"), h.DeleteWebhook)
+		}
+
+		organizations := v1.Group("organizations")
+		{
+			organizations.Use(injectRequestID, requireAdmin, validate)
+
+			organizations.POST("", h.audit("organization"), h.CreateOrganization)
+			organizations.GET("", h.ListOrganizations)
+			organizations.GET(":id", h.GetOrganization)
+			organizations.PUT(":id", h.audit("organization"), h.UpdateOrganization)
+			organizations.POST(":id/key", h.audit("organization_key"), h.RotateOrganizationKey)
+		}
+
+		encryption := v1.Group("encryption")
+		{
+			encryption.Use(injectRequestID, requireAdmin, validate)
+
+			encryption.POST("rewrap", h.audit("encryption_key"), h.RewrapDataKeys)
+		}
+
+		deadLetters := v1.Group("dead-letters")
+		{
+			deadLetters.Use(injectRequestID, requireAdmin, validate)
+
+			deadLetters.POST("replay", h.audit("dead_letter"), h.ReplayDeadLetters)
+		}
+
+		auditLog := v1.Group("audit")
+		{
+			auditLog.Use(injectRequestID, authenticate, validate, deduplicate)
+
+			auditLog.GET("", h.ListAudit)
+		}
+
+		fleet := v1.Group("fleet")
+		{
+			fleet.Use(injectRequestID, authenticate, validate, deduplicate)
+
+			fleet.GET("health", h.FleetHealth)
+		}
 	}
 }
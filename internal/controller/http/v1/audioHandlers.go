@@ -0,0 +1,104 @@
+package v1
+
+import (
+	"encoding/base64"
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/Imm0bilize/gunshot-api-service/internal/controller/http/dto"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	_signatureHeader = "X-Signature"
+	_sequenceHeader  = "X-Sequence"
+)
+
+func (h *Handler) UploadAudio(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		clientID  = c.MustGet("clientID").(string)
+	)
+
+	ts, err := strconv.ParseInt(c.Param("ts"), 10, 64)
+	if err != nil {
+		_ = c.Error(apperr.New(apperr.InvalidArgument, "invalid timestamp: expected unix time in milliseconds"))
+		return
+	}
+
+	payload, err := c.GetRawData()
+	if err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
+
+	signature, err := uploadSignature(c)
+	if err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
+
+	err = h.domain.Audio.Upload(
+		c.Request.Context(),
+		requestID,
+		clientID,
+		entities.Message{
+			Payload:     payload,
+			Timestamp:   time.UnixMilli(ts).UTC(),
+			MessageType: c.ContentType(),
+			Signature:   signature,
+		},
+	)
+
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// uploadSignature reads the device signature from headers, unsigned uploads have no 'X-Signature'
+func uploadSignature(c *gin.Context) (entities.UploadSignature, error) {
+	raw := c.GetHeader(_signatureHeader)
+	if raw == "" {
+		return entities.UploadSignature{}, nil
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(raw)
+	if err != nil {
+		return entities.UploadSignature{}, errors.New("invalid signature: expected base64")
+	}
+
+	sequence, err := strconv.ParseInt(c.GetHeader(_sequenceHeader), 10, 64)
+	if err != nil || sequence <= 0 {
+		return entities.UploadSignature{}, errors.New("invalid sequence: expected a positive integer")
+	}
+
+	return entities.UploadSignature{Sequence: sequence, Signature: signature}, nil
+}
+
+func (h *Handler) VerifyAudioChain(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		clientID  = c.MustGet("clientID").(string)
+		query     dto.ChainVerifyQuery
+	)
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
+
+	verification, err := h.domain.Audio.Verify(c.Request.Context(), requestID, clientID, query.From, query.To)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, verification)
+}
@@ -0,0 +1,126 @@
+package v1
+
+import (
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/Imm0bilize/gunshot-api-service/internal/audit"
+	"github.com/Imm0bilize/gunshot-api-service/internal/controller/http/dto"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/tenant"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+const _adminActor = "admin"
+
+// audit records the call of the route in the audit log. The entry is saved as pending before the call, so
+// nothing is changed without a trace, and is completed with the outcome after it. The target id is the ':id'
+// parameter unless the use case sets it, e.g. for created entities
+func (h *Handler) audit(targetType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var (
+			requestID = c.MustGet("requestID").(uuid.UUID)
+			ctx       = c.Request.Context()
+		)
+
+		tenantID, err := tenant.ID(ctx)
+		if err != nil {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+
+		entry := &entities.AuditEntry{
+			TenantID:   tenantID,
+			Actor:      actor(c),
+			RemoteAddr: c.ClientIP(),
+			RequestID:  requestID.String(),
+			Method:     c.Request.Method,
+			Route:      c.FullPath(),
+			Target:     entities.AuditTarget{Type: targetType, ID: c.Param("id")},
+			Changes:    make([]entities.AuditChange, 0),
+			Outcome:    entities.AuditPending,
+			Timestamp:  time.Now().UTC(),
+		}
+
+		if err := h.domain.Audit.Record(ctx, requestID, entry); err != nil {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+
+		c.Request = c.Request.WithContext(audit.WithEntry(ctx, entry))
+		c.Next()
+
+		entry.Status = c.Writer.Status()
+		if last := c.Errors.Last(); last != nil && !c.Writer.Written() {
+			// the problem is written by the error middleware after the entry is recorded
+			entry.Status = NewProblem(c, last.Err).Status
+		}
+		entry.Outcome = entities.AuditSucceeded
+		if entry.Status >= http.StatusBadRequest {
+			entry.Outcome = entities.AuditFailed
+		}
+
+		// the entry is completed even if the caller has gone
+		if err := h.domain.Audit.Complete(audit.Detach(c.Request.Context()), requestID, entry); err != nil {
+			h.logger.Error("audit entry is left pending", zap.String("reqID", requestID.String()), zap.Error(err))
+		}
+	}
+}
+
+func actor(c *gin.Context) string {
+	organization, ok := tenant.Organization(c.Request.Context())
+	if !ok {
+		return _adminActor
+	}
+
+	return "organization:" + organization.ID.Hex()
+}
+
+func (h *Handler) ListAudit(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		query     dto.AuditQuery
+	)
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
+
+	if query.Format == "jsonl" {
+		h.exportAudit(c, requestID, query.ToEntity())
+		return
+	}
+
+	entries, err := h.domain.Audit.List(c.Request.Context(), requestID, query.ToEntity())
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.AuditResponse{Entries: entries})
+}
+
+// exportAudit streams the signed JSONL, errors after the first line can only be logged
+func (h *Handler) exportAudit(c *gin.Context, requestID uuid.UUID, filter entities.AuditFilter) {
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", `attachment; filename="audit.jsonl"`)
+
+	err := h.domain.Audit.Export(c.Request.Context(), requestID, filter, c.Writer)
+	if err == nil {
+		return
+	}
+
+	if c.Writer.Written() {
+		h.logger.Error("error during export audit", zap.String("reqID", requestID.String()), zap.Error(err))
+		return
+	}
+
+	c.Header("Content-Type", "")
+	c.Header("Content-Disposition", "")
+	_ = c.Error(err)
+}
@@ -1,16 +1,18 @@
 package v1
 
 import (
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
 	"github.com/Imm0bilize/gunshot-api-service/internal/controller/http/dto"
 	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
-	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"github.com/pkg/errors"
 	"go.uber.org/zap"
 	"net/http"
+	"strings"
 )
 
+const _mergePatchType = "application/merge-patch+json"
+
 func (h *Handler) RegisterNewClient(c *gin.Context) {
 	var (
 		req       dto.ClientInfo
@@ -18,7 +20,7 @@ func (h *Handler) RegisterNewClient(c *gin.Context) {
 	)
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Msg: err.Error()})
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
 		return
 	}
 
@@ -33,13 +35,13 @@ func (h *Handler) RegisterNewClient(c *gin.Context) {
 		&entities.Client{
 			FullName:     req.FullName,
 			LocationName: req.LocationName,
-			Latitude:     req.Latitude,
-			Longitude:    req.Longitude,
+			Latitude:     *req.Latitude,
+			Longitude:    *req.Longitude,
 		},
 	)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Msg: err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -54,31 +56,75 @@ func (h *Handler) UpdateClient(c *gin.Context) {
 		req       dto.ClientInfo
 	)
 
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusUnprocessableEntity, dto.ErrorResponse{Msg: err.Error()})
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
 		return
 	}
 
-	err := h.domain.Client.Update(
+	client, err := h.domain.Client.Update(
 		c.Request.Context(),
 		requestID,
 		clientID,
+		version,
 		&entities.Client{
 			LocationName: req.LocationName,
 			FullName:     req.FullName,
-			Latitude:     req.Latitude,
-			Longitude:    req.Longitude,
+			Latitude:     *req.Latitude,
+			Longitude:    *req.Longitude,
 		},
 	)
 
 	if err != nil {
-		if errors.Is(err, repository.ErrClientNotFound) {
-			c.JSON(http.StatusNotFound, dto.ErrorResponse{Msg: err.Error()})
-		}
+		_ = c.Error(err)
+		return
+	}
+
+	c.Header("ETag", client.ETag())
+	c.JSON(http.StatusOK, client)
+}
+
+// PatchClient applies the JSON merge patch to the client
+func (h *Handler) PatchClient(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		clientID  = c.MustGet("clientID").(string)
+		req       dto.ClientPatch
+	)
+
+	if c.ContentType() != _mergePatchType {
+		_ = c.Error(apperr.New(apperr.UnsupportedMedia, "the patch must be "+_mergePatchType))
+		return
+	}
+
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil || req == nil {
+		_ = c.Error(apperr.New(apperr.InvalidArgument, "the patch must be a JSON object"))
+		return
+	}
+
+	patch, err := req.ToEntity()
+	if err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
 
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Msg: err.Error()})
+	client, err := h.domain.Client.Patch(c.Request.Context(), requestID, clientID, version, patch)
+	if err != nil {
+		_ = c.Error(err)
+		return
 	}
 
+	c.Header("ETag", client.ETag())
+	c.JSON(http.StatusOK, client)
 }
 
 func (h *Handler) GetClient(c *gin.Context) {
@@ -89,33 +135,159 @@ func (h *Handler) GetClient(c *gin.Context) {
 
 	client, err := h.domain.Client.Get(c.Request.Context(), requestID, clientID)
 	if err != nil {
-		if errors.Is(err, repository.ErrClientNotFound) {
-			c.JSON(http.StatusNotFound, dto.ErrorResponse{Msg: err.Error()})
-			return
-		}
+		_ = c.Error(err)
+		return
+	}
+
+	etag := client.ETag()
+	c.Header("ETag", etag)
 
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Msg: err.Error()})
+	if noneMatch(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
 		return
 	}
 
 	c.JSON(http.StatusOK, client)
 }
 
+// ListClients returns the page of clients of the organization, deleted clients are not listed
+func (h *Handler) ListClients(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		query     dto.ClientsQuery
+	)
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
+
+	after, err := optionalObjectID(query.After)
+	if err != nil {
+		_ = c.Error(apperr.New(apperr.InvalidArgument, "invalid after"))
+		return
+	}
+
+	clients, err := h.domain.Client.List(
+		c.Request.Context(), requestID, entities.ClientFilter{After: after, Limit: query.Limit},
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	resp := dto.ClientsResponse{Clients: clients}
+	if int64(len(clients)) == query.Limit {
+		resp.Next = clients[len(clients)-1].ID.Hex()
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
 func (h *Handler) DeleteClient(c *gin.Context) {
 	var (
 		clientID  = c.MustGet("clientID").(string)
 		requestID = c.MustGet("requestID").(uuid.UUID)
 	)
 
-	if err := h.domain.Client.Delete(c.Request.Context(), requestID, clientID); err != nil {
-		if errors.Is(err, repository.ErrClientNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "the client not found"})
-			return
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	if err := h.domain.Client.Delete(c.Request.Context(), requestID, clientID, version); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) RestoreClient(c *gin.Context) {
+	var (
+		clientID  = c.MustGet("clientID").(string)
+		requestID = c.MustGet("requestID").(uuid.UUID)
+	)
+
+	client, err := h.domain.Client.Restore(c.Request.Context(), requestID, clientID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Header("ETag", client.ETag())
+	c.JSON(http.StatusOK, client)
+}
+
+func (h *Handler) ClientHistory(c *gin.Context) {
+	var (
+		clientID  = c.MustGet("clientID").(string)
+		requestID = c.MustGet("requestID").(uuid.UUID)
+	)
+
+	versions, err := h.domain.Client.History(c.Request.Context(), requestID, clientID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, versions)
+}
+
+// ifMatch returns the version of the client the request is conditional on, writes to clients must carry
+// the ETag of the version they change or '*' which matches any version (RFC 9110). If-Match compares tags
+// strongly, so weak tags never match
+func ifMatch(c *gin.Context) (int64, bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		_ = c.Error(apperr.New(apperr.PreconditionRequired, "the If-Match header is required"))
+		return 0, false
+	}
+
+	if header == "*" {
+		return entities.AnyVersion, true
+	}
+
+	if strings.HasPrefix(header, "W/") {
+		_ = c.Error(apperr.New(
+			apperr.FailedPrecondition, "the If-Match header has the weak ETag, If-Match takes strong ones only",
+		))
+		return 0, false
+	}
+
+	version, err := entities.ParseClientETag(header)
+	if err != nil || version < 0 {
+		_ = c.Error(apperr.New(apperr.FailedPrecondition, "the If-Match header is not an ETag of the client"))
+		return 0, false
+	}
+
+	return version, true
+}
+
+// noneMatch tells if the If-None-Match header lists the entity tag, the comparison is weak
+func noneMatch(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
 		}
+	}
+
+	return false
+}
+
+// requireClient stops requests to clients of other organizations
+func (h *Handler) requireClient(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		clientID  = c.MustGet("clientID").(string)
+	)
 
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if _, err := h.domain.Client.Get(c.Request.Context(), requestID, clientID); err != nil {
+		_ = c.Error(err)
+		c.Abort()
 		return
 	}
 
-	c.String(http.StatusOK, "ok")
+	c.Next()
 }
@@ -0,0 +1,101 @@
+package v1
+
+import (
+	"fmt"
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/Imm0bilize/gunshot-api-service/internal/clientio"
+	"github.com/Imm0bilize/gunshot-api-service/internal/controller/http/dto"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+// _maxImportSize limits the file of the import
+const _maxImportSize = 16 << 20
+
+// importFormats are formats of the import by the content type
+var importFormats = map[string]clientio.Format{
+	"text/csv":             clientio.FormatCSV,
+	"application/geo+json": clientio.FormatGeoJSON,
+}
+
+// ImportClients creates clients of the CSV file or the GeoJSON FeatureCollection, nothing is created if any
+// row is invalid
+func (h *Handler) ImportClients(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		query     dto.ClientImportQuery
+	)
+
+	format, ok := importFormats[c.ContentType()]
+	if !ok {
+		_ = c.Error(apperr.New(apperr.UnsupportedMedia, "the import must be text/csv or application/geo+json"))
+		return
+	}
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, _maxImportSize)
+
+	result, err := h.domain.Client.Import(c.Request.Context(), requestID, format, body, query.DryRun)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	switch {
+	case len(result.Errors) > 0:
+		_ = c.Error(apperr.WithDetails(
+			apperr.InvalidArgument, fmt.Sprintf("%d of %d rows are invalid", len(result.Errors), result.Rows), result.Errors,
+		))
+	case result.DryRun:
+		c.JSON(http.StatusOK, result)
+	default:
+		h.logger.Info(
+			"clients are imported",
+			zap.String("request_id", requestID.String()),
+			zap.Int("count", len(result.Created)),
+		)
+		c.JSON(http.StatusCreated, result)
+	}
+}
+
+// ExportClients streams clients of the organization as CSV, GeoJSON or KML, errors after the first byte can
+// only be logged
+func (h *Handler) ExportClients(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		query     dto.ClientExportQuery
+	)
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
+
+	format := clientio.Format(query.Format)
+	if format == "" {
+		format = clientio.FormatCSV
+	}
+
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", `attachment; filename="clients.`+string(format)+`"`)
+
+	err := h.domain.Client.Export(c.Request.Context(), requestID, format, c.Writer)
+	if err == nil {
+		return
+	}
+
+	if c.Writer.Written() {
+		h.logger.Error("error during export clients", zap.String("reqID", requestID.String()), zap.Error(err))
+		return
+	}
+
+	c.Header("Content-Type", "")
+	c.Header("Content-Disposition", "")
+	_ = c.Error(err)
+}
@@ -0,0 +1,36 @@
+package v1
+
+import (
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/Imm0bilize/gunshot-api-service/internal/controller/http/dto"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"time"
+)
+
+// SyncClock is the NTP-style exchange: the client completes the answer with its receive time
+// and passes it as 'previous' with the next exchange
+func (h *Handler) SyncClock(c *gin.Context) {
+	var (
+		serverReceive = time.Now().UTC()
+		requestID     = c.MustGet("requestID").(uuid.UUID)
+		clientID      = c.MustGet("clientID").(string)
+		req           dto.ClockSyncRequest
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
+
+	answer, clock, err := h.domain.Clock.Sync(
+		c.Request.Context(), requestID, clientID, req.ClientSend, serverReceive, req.Previous,
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ClockSyncResponse{ClockSample: answer, Clock: clock})
+}
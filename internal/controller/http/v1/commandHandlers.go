@@ -0,0 +1,191 @@
+package v1
+
+import (
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/Imm0bilize/gunshot-api-service/internal/controller/http/dto"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"io"
+	"net/http"
+	"time"
+)
+
+// _streamKeepAlive is how often the idle command stream sends a ping, so proxies don't close it
+const _streamKeepAlive = 15 * time.Second
+
+func (h *Handler) EnqueueCommand(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		clientID  = c.MustGet("clientID").(string)
+		req       dto.CommandRequest
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
+
+	command := entities.Command{Type: req.Type, Args: req.Args}
+	if req.TTL > 0 {
+		command.ExpiresAt = time.Now().UTC().Add(time.Duration(req.TTL) * time.Second)
+	}
+
+	id, err := h.domain.Command.Enqueue(c.Request.Context(), requestID, clientID, &command)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.CreatedResponse{ID: id})
+}
+
+func (h *Handler) ListCommands(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		query     dto.CommandsQuery
+	)
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
+
+	clientID, err := primitive.ObjectIDFromHex(c.MustGet("clientID").(string))
+	if err != nil {
+		_ = c.Error(apperr.New(apperr.InvalidArgument, "invalid client id"))
+		return
+	}
+
+	commands, err := h.domain.Command.List(
+		c.Request.Context(),
+		requestID,
+		entities.CommandFilter{
+			ClientID: clientID,
+			Status:   query.Status,
+			Limit:    query.Limit,
+			Offset:   query.Offset,
+		},
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.CommandsResponse{Commands: commands})
+}
+
+func (h *Handler) GetCommand(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		clientID  = c.MustGet("clientID").(string)
+	)
+
+	command, err := h.domain.Command.Get(c.Request.Context(), requestID, clientID, c.Param("commandID"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, command)
+}
+
+// PollCommands delivers pending commands to the device, the request is held up to 'wait' if there are none
+func (h *Handler) PollCommands(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		clientID  = c.MustGet("clientID").(string)
+		query     dto.PollQuery
+	)
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
+
+	if query.Wait > _maxWait {
+		query.Wait = _maxWait
+	}
+
+	commands, err := h.domain.Command.Next(c.Request.Context(), requestID, clientID, query.Wait)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.CommandsResponse{Commands: commands})
+}
+
+// StreamCommands delivers commands to the device as server-sent events until it disconnects
+func (h *Handler) StreamCommands(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		clientID  = c.MustGet("clientID").(string)
+		ctx       = c.Request.Context()
+	)
+
+	c.Stream(func(io.Writer) bool {
+		commands, err := h.domain.Command.Next(ctx, requestID, clientID, _streamKeepAlive)
+		if err != nil {
+			c.SSEvent("error", NewProblem(c, err))
+			return false
+		}
+
+		for _, command := range commands {
+			c.SSEvent("command", command)
+		}
+
+		if len(commands) == 0 {
+			c.SSEvent("ping", time.Now().UTC())
+		}
+
+		return ctx.Err() == nil
+	})
+}
+
+func (h *Handler) AcknowledgeCommand(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		clientID  = c.MustGet("clientID").(string)
+	)
+
+	command, err := h.domain.Command.Acknowledge(c.Request.Context(), requestID, clientID, c.Param("commandID"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, command)
+}
+
+func (h *Handler) CompleteCommand(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		clientID  = c.MustGet("clientID").(string)
+		req       dto.CommandResultRequest
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
+
+	command, err := h.domain.Command.Complete(
+		c.Request.Context(),
+		requestID,
+		clientID,
+		c.Param("commandID"),
+		entities.CommandResult{
+			Success: req.Success,
+			Result:  req.Result,
+			Error:   req.Error,
+		},
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, command)
+}
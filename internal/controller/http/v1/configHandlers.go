@@ -0,0 +1,132 @@
+package v1
+
+import (
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/Imm0bilize/gunshot-api-service/internal/controller/http/dto"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"time"
+)
+
+// _maxWait limits how long the long polling request is held
+const _maxWait = time.Minute
+
+func (h *Handler) GetClientConfig(c *gin.Context) {
+	var (
+		requestID   = c.MustGet("requestID").(uuid.UUID)
+		clientID    = c.MustGet("clientID").(string)
+		ifNoneMatch = c.GetHeader("If-None-Match")
+		query       dto.PollQuery
+		effective   entities.EffectiveConfig
+		err         error
+	)
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
+
+	if query.Wait > _maxWait {
+		query.Wait = _maxWait
+	}
+
+	if ifNoneMatch != "" && query.Wait > 0 {
+		effective, err = h.domain.Config.Wait(c.Request.Context(), requestID, clientID, ifNoneMatch, query.Wait)
+	} else {
+		effective, err = h.domain.Config.Effective(c.Request.Context(), requestID, clientID)
+	}
+
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Header("ETag", effective.ETag)
+
+	if ifNoneMatch == effective.ETag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ConfigResponse{
+		Version:        effective.Version,
+		Config:         effective.Config,
+		AppliedVersion: effective.AppliedVersion,
+		Drift:          effective.Drift(),
+	})
+}
+
+func (h *Handler) SetClientConfig(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		clientID  = c.MustGet("clientID").(string)
+		req       dto.SensorConfig
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
+
+	document, err := h.domain.Config.SetClientConfig(c.Request.Context(), requestID, clientID, req.ToEntity())
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, document)
+}
+
+func (h *Handler) ReportAppliedConfig(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		clientID  = c.MustGet("clientID").(string)
+		req       dto.AppliedConfigRequest
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
+
+	if err := h.domain.Config.Applied(c.Request.Context(), requestID, clientID, req.Version); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) GetZoneConfig(c *gin.Context) {
+	requestID := c.MustGet("requestID").(uuid.UUID)
+
+	document, err := h.domain.Config.GetZoneConfig(c.Request.Context(), requestID, c.Param("id"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, document)
+}
+
+func (h *Handler) SetZoneConfig(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		req       dto.SensorConfig
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
+
+	document, err := h.domain.Config.SetZoneConfig(c.Request.Context(), requestID, c.Param("id"), req.ToEntity())
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, document)
+}
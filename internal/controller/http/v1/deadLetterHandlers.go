@@ -0,0 +1,36 @@
+package v1
+
+import (
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/Imm0bilize/gunshot-api-service/internal/controller/http/dto"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+// ReplayDeadLetters produces dead letters of detections to the detections topic again
+func (h *Handler) ReplayDeadLetters(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		req       dto.DeadLetterReplayRequest
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
+
+	result, err := h.domain.DeadLetter.Replay(c.Request.Context(), requestID, entities.DeadLetterReplay{
+		Partition:     req.Partition,
+		From:          req.From,
+		To:            req.To,
+		SourceOffsets: req.SourceOffsets,
+	})
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,60 @@
+package v1
+
+import (
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/Imm0bilize/gunshot-api-service/internal/controller/http/dto"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+func (h *Handler) ListDetections(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		query     dto.DetectionsQuery
+	)
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
+
+	clientID, err := optionalObjectID(query.ClientID)
+	if err != nil {
+		_ = c.Error(apperr.New(apperr.InvalidArgument, "invalid clientID"))
+		return
+	}
+
+	zoneID, err := optionalObjectID(query.ZoneID)
+	if err != nil {
+		_ = c.Error(apperr.New(apperr.InvalidArgument, "invalid zoneID"))
+		return
+	}
+
+	after, err := optionalObjectID(query.After)
+	if err != nil {
+		_ = c.Error(apperr.New(apperr.InvalidArgument, "invalid after"))
+		return
+	}
+
+	detections, err := h.domain.Detection.List(
+		c.Request.Context(),
+		requestID,
+		entities.DetectionFilter{
+			ClientID: clientID,
+			ZoneID:   zoneID,
+			From:     query.From,
+			To:       query.To,
+			After:    after,
+			Limit:    query.Limit,
+			Offset:   query.Offset,
+		},
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DetectionsResponse{Detections: detections})
+}
@@ -0,0 +1,66 @@
+package v1
+
+import (
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/Imm0bilize/gunshot-api-service/internal/controller/http/dto"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"time"
+)
+
+func (h *Handler) RegisterDeviceKey(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		clientID  = c.MustGet("clientID").(string)
+		req       dto.DeviceKeyRequest
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
+
+	var overlap *time.Duration
+	if req.Overlap != nil {
+		d := time.Duration(*req.Overlap) * time.Second
+		overlap = &d
+	}
+
+	key, err := h.domain.DeviceKey.Register(c.Request.Context(), requestID, clientID, req.PublicKey, overlap)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, key)
+}
+
+func (h *Handler) ListDeviceKeys(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		clientID  = c.MustGet("clientID").(string)
+	)
+
+	keys, err := h.domain.DeviceKey.List(c.Request.Context(), requestID, clientID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DeviceKeysResponse{Keys: keys})
+}
+
+func (h *Handler) RevokeDeviceKey(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		clientID  = c.MustGet("clientID").(string)
+	)
+
+	if err := h.domain.DeviceKey.Revoke(c.Request.Context(), requestID, clientID, c.Param("keyID")); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,20 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+// RewrapDataKeys runs the key rotation now instead of waiting for the next scheduled run
+func (h *Handler) RewrapDataKeys(c *gin.Context) {
+	requestID := c.MustGet("requestID").(uuid.UUID)
+
+	result, err := h.domain.KeyRotation.Rewrap(c.Request.Context(), requestID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,39 @@
+package v1
+
+import (
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// ExportEvidence streams the signed ZIP bundle of the incident, errors after the first byte can only be logged
+func (h *Handler) ExportEvidence(c *gin.Context) {
+	var (
+		requestID  = c.MustGet("requestID").(uuid.UUID)
+		incidentID = c.Param("id")
+	)
+
+	if _, err := primitive.ObjectIDFromHex(incidentID); err != nil {
+		_ = c.Error(apperr.New(apperr.InvalidArgument, "invalid incident id"))
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="incident-`+incidentID+`-evidence.zip"`)
+
+	err := h.domain.Evidence.Export(c.Request.Context(), requestID, incidentID, c.Writer)
+	if err == nil {
+		return
+	}
+
+	if c.Writer.Written() {
+		h.logger.Error("error during export evidence", zap.String("reqID", requestID.String()), zap.Error(err))
+		return
+	}
+
+	c.Header("Content-Type", "")
+	c.Header("Content-Disposition", "")
+	_ = c.Error(err)
+}
@@ -0,0 +1,91 @@
+package v1
+
+import (
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/Imm0bilize/gunshot-api-service/internal/controller/http/dto"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"net/http"
+)
+
+func (h *Handler) SendHeartbeat(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		clientID  = c.MustGet("clientID").(string)
+		req       dto.HeartbeatRequest
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
+
+	err := h.domain.Fleet.Heartbeat(
+		c.Request.Context(),
+		requestID,
+		clientID,
+		&entities.Heartbeat{
+			Battery:         req.Battery,
+			SignalStrength:  req.SignalStrength,
+			FirmwareVersion: req.FirmwareVersion,
+			DiskUsage:       req.DiskUsage,
+			Temperature:     req.Temperature,
+		},
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) ListHeartbeats(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		query     dto.HeartbeatsQuery
+	)
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
+
+	clientID, err := primitive.ObjectIDFromHex(c.MustGet("clientID").(string))
+	if err != nil {
+		_ = c.Error(apperr.New(apperr.InvalidArgument, "invalid client id"))
+		return
+	}
+
+	heartbeats, err := h.domain.Fleet.Heartbeats(
+		c.Request.Context(),
+		requestID,
+		entities.HeartbeatFilter{
+			ClientID: clientID,
+			From:     query.From,
+			To:       query.To,
+			Limit:    query.Limit,
+			Offset:   query.Offset,
+		},
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.HeartbeatsResponse{Heartbeats: heartbeats})
+}
+
+func (h *Handler) FleetHealth(c *gin.Context) {
+	requestID := c.MustGet("requestID").(uuid.UUID)
+
+	health, err := h.domain.Fleet.Health(c.Request.Context(), requestID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, health)
+}
@@ -0,0 +1,88 @@
+package v1
+
+import (
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/Imm0bilize/gunshot-api-service/internal/controller/http/dto"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+func (h *Handler) ListIncidents(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		query     dto.IncidentsQuery
+	)
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
+
+	zoneID, err := optionalObjectID(query.ZoneID)
+	if err != nil {
+		_ = c.Error(apperr.New(apperr.InvalidArgument, "invalid zoneID"))
+		return
+	}
+
+	incidents, err := h.domain.Incident.List(
+		c.Request.Context(),
+		requestID,
+		entities.IncidentFilter{
+			ZoneID: zoneID,
+			From:   query.From,
+			To:     query.To,
+			Limit:  query.Limit,
+			Offset: query.Offset,
+		},
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.IncidentsResponse{Incidents: incidents})
+}
+
+func (h *Handler) GetIncident(c *gin.Context) {
+	requestID := c.MustGet("requestID").(uuid.UUID)
+
+	incident, err := h.domain.Incident.Get(c.Request.Context(), requestID, c.Param("id"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, incident)
+}
+
+func (h *Handler) TransitionIncident(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		req       dto.TransitionRequest
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
+
+	incident, err := h.domain.Incident.Transition(
+		c.Request.Context(),
+		requestID,
+		c.Param("id"),
+		entities.IncidentTransition{
+			To:          entities.IncidentStatus(req.Status),
+			Actor:       actor(c),
+			DisplayName: req.DisplayName,
+			Notes:       req.Notes,
+		},
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, incident)
+}
@@ -0,0 +1,88 @@
+package v1
+
+import (
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/Imm0bilize/gunshot-api-service/internal/controller/http/dto"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+func (h *Handler) CreateOrganization(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		req       dto.OrganizationInfo
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
+
+	organization := req.ToEntity()
+
+	id, key, err := h.domain.Organization.Create(c.Request.Context(), requestID, &organization)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.OrganizationCreatedResponse{ID: id, APIKey: key})
+}
+
+func (h *Handler) ListOrganizations(c *gin.Context) {
+	requestID := c.MustGet("requestID").(uuid.UUID)
+
+	organizations, err := h.domain.Organization.List(c.Request.Context(), requestID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.OrganizationsResponse{Organizations: organizations})
+}
+
+func (h *Handler) GetOrganization(c *gin.Context) {
+	requestID := c.MustGet("requestID").(uuid.UUID)
+
+	organization, err := h.domain.Organization.Get(c.Request.Context(), requestID, c.Param("id"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, organization)
+}
+
+func (h *Handler) UpdateOrganization(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		req       dto.OrganizationInfo
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
+
+	organization := req.ToEntity()
+
+	if err := h.domain.Organization.Update(c.Request.Context(), requestID, c.Param("id"), &organization); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (h *Handler) RotateOrganizationKey(c *gin.Context) {
+	requestID := c.MustGet("requestID").(uuid.UUID)
+
+	key, err := h.domain.Organization.RotateKey(c.Request.Context(), requestID, c.Param("id"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.APIKeyResponse{APIKey: key})
+}
@@ -0,0 +1,60 @@
+package v1
+
+import (
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/Imm0bilize/gunshot-api-service/internal/controller/http/dto"
+	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+const ProblemContentType = "application/problem+json"
+
+var _statuses = map[apperr.Kind]int{
+	apperr.NotFound:             http.StatusNotFound,
+	apperr.InvalidArgument:      http.StatusBadRequest,
+	apperr.Conflict:             http.StatusConflict,
+	apperr.Unavailable:          http.StatusServiceUnavailable,
+	apperr.FailedPrecondition:   http.StatusPreconditionFailed,
+	apperr.PreconditionRequired: http.StatusPreconditionRequired,
+	apperr.Unauthenticated:      http.StatusUnauthorized,
+	apperr.PermissionDenied:     http.StatusForbidden,
+	apperr.ResourceExhausted:    http.StatusTooManyRequests,
+	apperr.UnsupportedMedia:     http.StatusUnsupportedMediaType,
+	apperr.Unimplemented:        http.StatusNotImplemented,
+}
+
+// NewProblem describes the error to the caller, messages of internal errors and failures of the database
+// are not shown
+func NewProblem(c *gin.Context, err error) dto.Problem {
+	kind := apperr.KindOf(err)
+	if kind == apperr.Internal && repository.IsUnavailable(err) {
+		kind = apperr.Unavailable
+	}
+
+	status, ok := _statuses[kind]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+
+	problem := dto.Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   err.Error(),
+		Instance: c.Request.URL.Path,
+		Code:     string(kind),
+		Errors:   apperr.DetailsOf(err),
+	}
+
+	if kind == apperr.Internal || kind == apperr.Unavailable {
+		problem.Detail = ""
+	}
+
+	if requestID, ok := c.Get("requestID"); ok {
+		problem.RequestID = requestID.(uuid.UUID).String()
+	}
+
+	return problem
+}
@@ -0,0 +1,57 @@
+package v1
+
+import (
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/Imm0bilize/gunshot-api-service/internal/controller/http/dto"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+func (h *Handler) HoldIncident(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		req       dto.LegalHoldRequest
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
+
+	incident, err := h.domain.Retention.Hold(
+		c.Request.Context(), requestID, c.Param("id"), entities.LegalHold{Reason: req.Reason, Actor: req.Actor},
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, incident)
+}
+
+func (h *Handler) ReleaseIncident(c *gin.Context) {
+	requestID := c.MustGet("requestID").(uuid.UUID)
+
+	incident, err := h.domain.Retention.Release(c.Request.Context(), requestID, c.Param("id"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, incident)
+}
+
+// RetentionReport is the dry run of the retention: what the next sweep removes
+func (h *Handler) RetentionReport(c *gin.Context) {
+	requestID := c.MustGet("requestID").(uuid.UUID)
+
+	report, err := h.domain.Retention.Report(c.Request.Context(), requestID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
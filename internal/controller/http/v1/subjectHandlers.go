@@ -0,0 +1,51 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+// ExportClientData streams the ZIP archive of everything kept about the client, errors after the first
+// byte can only be logged
+func (h *Handler) ExportClientData(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		clientID  = c.MustGet("clientID").(string)
+	)
+
+	// the id has been checked by requireClient
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="client-`+clientID+`-export.zip"`)
+
+	err := h.domain.Subject.Export(c.Request.Context(), requestID, clientID, c.Writer)
+	if err == nil {
+		return
+	}
+
+	if c.Writer.Written() {
+		h.logger.Error("error during export client data", zap.String("reqID", requestID.String()), zap.Error(err))
+		return
+	}
+
+	c.Header("Content-Type", "")
+	c.Header("Content-Disposition", "")
+	_ = c.Error(err)
+}
+
+// EraseClientData removes the client with its data and returns the tombstone with the report
+func (h *Handler) EraseClientData(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		clientID  = c.MustGet("clientID").(string)
+	)
+
+	tombstone, err := h.domain.Subject.Erase(c.Request.Context(), requestID, clientID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tombstone)
+}
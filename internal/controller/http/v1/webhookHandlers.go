@@ -0,0 +1,66 @@
+package v1
+
+import (
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/Imm0bilize/gunshot-api-service/internal/controller/http/dto"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+func (h *Handler) CreateWebhook(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		req       dto.WebhookInfo
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
+
+	webhook := entities.Webhook{URL: req.URL, Events: req.Events}
+	id, err := h.domain.Webhook.Create(c.Request.Context(), requestID, &webhook)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.WebhookCreatedResponse{ID: id, Secret: webhook.Secret})
+}
+
+func (h *Handler) ListWebhooks(c *gin.Context) {
+	requestID := c.MustGet("requestID").(uuid.UUID)
+
+	webhooks, err := h.domain.Webhook.List(c.Request.Context(), requestID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.WebhooksResponse{Webhooks: webhooks})
+}
+
+func (h *Handler) GetWebhook(c *gin.Context) {
+	requestID := c.MustGet("requestID").(uuid.UUID)
+
+	webhook, err := h.domain.Webhook.Get(c.Request.Context(), requestID, c.Param("id"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+func (h *Handler) DeleteWebhook(c *gin.Context) {
+	requestID := c.MustGet("requestID").(uuid.UUID)
+
+	if err := h.domain.Webhook.Delete(c.Request.Context(), requestID, c.Param("id")); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
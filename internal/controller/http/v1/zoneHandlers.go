@@ -0,0 +1,104 @@
+package v1
+
+import (
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/Imm0bilize/gunshot-api-service/internal/controller/http/dto"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+func (h *Handler) CreateZone(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		req       dto.ZoneInfo
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
+
+	id, err := h.domain.Zone.Create(
+		c.Request.Context(),
+		requestID,
+		&entities.Zone{
+			Name:     req.Name,
+			Owner:    req.Owner,
+			Metadata: req.Metadata,
+			Area:     req.Area,
+		},
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.CreatedResponse{ID: id})
+}
+
+func (h *Handler) ListZones(c *gin.Context) {
+	requestID := c.MustGet("requestID").(uuid.UUID)
+
+	zones, err := h.domain.Zone.List(c.Request.Context(), requestID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ZonesResponse{Zones: zones})
+}
+
+func (h *Handler) GetZone(c *gin.Context) {
+	requestID := c.MustGet("requestID").(uuid.UUID)
+
+	zone, err := h.domain.Zone.Get(c.Request.Context(), requestID, c.Param("id"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, zone)
+}
+
+func (h *Handler) UpdateZone(c *gin.Context) {
+	var (
+		requestID = c.MustGet("requestID").(uuid.UUID)
+		req       dto.ZoneInfo
+	)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(apperr.Wrap(apperr.InvalidArgument, err))
+		return
+	}
+
+	err := h.domain.Zone.Update(
+		c.Request.Context(),
+		requestID,
+		c.Param("id"),
+		&entities.Zone{
+			Name:     req.Name,
+			Owner:    req.Owner,
+			Metadata: req.Metadata,
+			Area:     req.Area,
+		},
+	)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (h *Handler) DeleteZone(c *gin.Context) {
+	requestID := c.MustGet("requestID").(uuid.UUID)
+
+	if err := h.domain.Zone.Delete(c.Request.Context(), requestID, c.Param("id")); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
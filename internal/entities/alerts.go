@@ -0,0 +1,43 @@
+package entities
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// AlertRule is evaluated for every detection of the client or of clients in the zone,
+// rules without client and zone apply to all clients
+type AlertRule struct {
+	ID         primitive.ObjectID `json:"ID" bson:"_id"`
+	TenantID   primitive.ObjectID `json:"tenantID" bson:"tenantID"`
+	Name       string             `json:"name" bson:"name"`
+	ClientID   primitive.ObjectID `json:"clientID,omitempty" bson:"clientID,omitempty"`
+	ZoneID     primitive.ObjectID `json:"zoneID,omitempty" bson:"zoneID,omitempty"`
+	Expression string             `json:"expression" bson:"expression"`
+	// Window is the period (seconds) in which detections of the client are counted for the `count` variable
+	Window   int    `json:"window" bson:"window"`
+	Timezone string `json:"timezone" bson:"timezone"`
+	Enabled  bool   `json:"enabled" bson:"enabled"`
+}
+
+type Alert struct {
+	ID          primitive.ObjectID   `json:"ID" bson:"_id"`
+	TenantID    primitive.ObjectID   `json:"tenantID" bson:"tenantID"`
+	RuleID      primitive.ObjectID   `json:"ruleID" bson:"ruleID"`
+	RuleName    string               `json:"ruleName" bson:"ruleName"`
+	DetectionID primitive.ObjectID   `json:"detectionID" bson:"detectionID"`
+	ClientID    primitive.ObjectID   `json:"clientID" bson:"clientID"`
+	IncidentID  primitive.ObjectID   `json:"incidentID,omitempty" bson:"incidentID,omitempty"`
+	ZoneIDs     []primitive.ObjectID `json:"zoneIDs" bson:"zoneIDs"`
+	CreatedAt   time.Time            `json:"createdAt" bson:"createdAt"`
+}
+
+type AlertFilter struct {
+	RuleID   primitive.ObjectID
+	ClientID primitive.ObjectID
+	ZoneID   primitive.ObjectID
+	From     time.Time
+	To       time.Time
+	Limit    int64
+	Offset   int64
+}
@@ -0,0 +1,114 @@
+package entities
+
+import (
+	"encoding/json"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"reflect"
+	"sort"
+	"time"
+)
+
+type AuditOutcome string
+
+const (
+	// AuditPending is the outcome of the call being processed, the entry stays pending if the service has
+	// stopped before the call has finished
+	AuditPending   AuditOutcome = "pending"
+	AuditSucceeded AuditOutcome = "succeeded"
+	AuditFailed    AuditOutcome = "failed"
+)
+
+// AuditTarget is the entity the request has changed
+type AuditTarget struct {
+	Type string `json:"type" bson:"type"`
+	ID   string `json:"ID" bson:"id"`
+}
+
+// AuditChange is the change of one field of the target, the nil before means the field has been created
+// and the nil after means it has been removed
+type AuditChange struct {
+	Field  string      `json:"field" bson:"field"`
+	Before interface{} `json:"before,omitempty" bson:"before,omitempty"`
+	After  interface{} `json:"after,omitempty" bson:"after,omitempty"`
+}
+
+// AuditEntry is the record of one mutating API call
+type AuditEntry struct {
+	ID         primitive.ObjectID `json:"ID" bson:"_id"`
+	TenantID   primitive.ObjectID `json:"tenantID" bson:"tenantID"`
+	Actor      string             `json:"actor" bson:"actor"`
+	RemoteAddr string             `json:"remoteAddr" bson:"remoteAddr"`
+	RequestID  string             `json:"requestID" bson:"requestID"`
+	Method     string             `json:"method" bson:"method"`
+	Route      string             `json:"route" bson:"route"`
+	Target     AuditTarget        `json:"target" bson:"target"`
+	Changes    []AuditChange      `json:"changes" bson:"changes"`
+	Outcome    AuditOutcome       `json:"outcome" bson:"outcome"`
+	Status     int                `json:"status" bson:"status"`
+	Timestamp  time.Time          `json:"timestamp" bson:"timestamp"`
+}
+
+type AuditFilter struct {
+	Actor      string
+	TargetType string
+	TargetID   string
+	RequestID  string
+	From       time.Time
+	To         time.Time
+	Limit      int64
+	Offset     int64
+}
+
+// Diff compares JSON representations of two states of the entity field by field, nil stands for
+// the missing entity. Fields hidden from JSON are not recorded
+func Diff(before, after interface{}) ([]AuditChange, error) {
+	beforeFields, err := jsonFields(before)
+	if err != nil {
+		return nil, err
+	}
+
+	afterFields, err := jsonFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(beforeFields)+len(afterFields))
+	for name := range beforeFields {
+		names = append(names, name)
+	}
+	for name := range afterFields {
+		if _, ok := beforeFields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	changes := make([]AuditChange, 0)
+	for _, name := range names {
+		if reflect.DeepEqual(beforeFields[name], afterFields[name]) {
+			continue
+		}
+
+		changes = append(changes, AuditChange{Field: name, Before: beforeFields[name], After: afterFields[name]})
+	}
+
+	return changes, nil
+}
+
+func jsonFields(v interface{}) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	if v == nil || reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil() {
+		return fields, nil
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,41 @@
+package entities
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// Blob is a stored object, e.g. the uploaded audio
+type Blob struct {
+	Key         string             `json:"key" bson:"_id"`
+	TenantID    primitive.ObjectID `json:"tenantID" bson:"tenantID"`
+	ContentType string             `json:"contentType" bson:"contentType"`
+	Data        []byte             `json:"-" bson:"data"`
+	Size        int64              `json:"size" bson:"size"`
+	CreatedAt   time.Time          `json:"createdAt" bson:"createdAt"`
+	// ClientID and Timestamp (corrected) tell which incidents the audio may belong to
+	ClientID  primitive.ObjectID `json:"clientID" bson:"clientID"`
+	Timestamp time.Time          `json:"timestamp" bson:"timestamp"`
+	// ExpiresAt is checked by the sweeper, nil keeps the object forever
+	ExpiresAt *time.Time `json:"expiresAt,omitempty" bson:"expiresAt,omitempty"`
+	// Encryption is set when Data is the ciphertext, objects stored before encryption was enabled have none
+	Encryption *BlobEncryption `json:"encryption,omitempty" bson:"encryption,omitempty"`
+	// Filter is the privacy filter applied before the object was stored
+	Filter *AppliedFilter `json:"filter,omitempty" bson:"filter,omitempty"`
+}
+
+const BlobAlgorithm = "AES-256-GCM"
+
+// BlobEncryption is the envelope of the object: the data is encrypted by its own data key, which is
+// wrapped by the key encryption key with KeyID. Rotation re-wraps the data key only
+type BlobEncryption struct {
+	Algorithm  string `json:"algorithm" bson:"algorithm"`
+	KeyID      string `json:"keyID" bson:"keyID"`
+	WrappedKey []byte `json:"-" bson:"wrappedKey"`
+	Nonce      []byte `json:"-" bson:"nonce"`
+}
+
+// AudioBlobKey addresses the audio by its hash, so an upload retried after a failure doesn't duplicate it
+func AudioBlobKey(clientID primitive.ObjectID, payloadHash string) string {
+	return "audio/" + clientID.Hex() + "/" + payloadHash
+}
@@ -0,0 +1,144 @@
+package entities
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"strconv"
+	"time"
+)
+
+// GenesisHash is the previous hash of the first record of every client
+const GenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// ChainHead is the last record of the hash chain of the client
+type ChainHead struct {
+	Sequence int64  `json:"sequence" bson:"sequence"`
+	Hash     string `json:"hash" bson:"hash"`
+	// DeviceSequence is the last sequence of a signed upload, it moves together with the head
+	DeviceSequence int64 `json:"deviceSequence" bson:"deviceSequence"`
+}
+
+// PrevHash returns the hash the next record has to commit to
+func (h ChainHead) PrevHash() string {
+	if h.Sequence == 0 {
+		return GenesisHash
+	}
+
+	return h.Hash
+}
+
+// ChainLink is the part of the record which is passed with the audio to downstream consumers
+type ChainLink struct {
+	Sequence    int64  `json:"sequence" bson:"sequence"`
+	PayloadHash string `json:"payloadHash" bson:"payloadHash"`
+	PrevHash    string `json:"prevHash" bson:"prevHash"`
+	Hash        string `json:"hash" bson:"hash"`
+}
+
+// ChainRecord commits to the uploaded payload and to the previous record of the client
+type ChainRecord struct {
+	ID        primitive.ObjectID `json:"ID" bson:"_id"`
+	TenantID  primitive.ObjectID `json:"tenantID" bson:"tenantID"`
+	ClientID  primitive.ObjectID `json:"clientID" bson:"clientID"`
+	Timestamp time.Time          `json:"timestamp" bson:"timestamp"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+	ChainLink `bson:",inline"`
+	// Verification is the result of the check of the device signature, it's not covered by the hash
+	Verification SignatureVerification `json:"verification" bson:"verification"`
+	// RawTimestamp, ContentType and Filter describe the stored audio, they are not covered by the hash
+	RawTimestamp time.Time      `json:"rawTimestamp" bson:"rawTimestamp"`
+	ContentType  string         `json:"contentType" bson:"contentType"`
+	Filter       *AppliedFilter `json:"filter,omitempty" bson:"filter,omitempty"`
+	// DeviceSequence is the device sequence of the head after the record, it's not covered by the hash
+	DeviceSequence int64 `json:"deviceSequence" bson:"deviceSequence"`
+}
+
+// HashPayload returns the hex encoded SHA-256 of the audio
+func HashPayload(payload []byte) string {
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// ComputeHash returns SHA-256 over the client, the sequence, the timestamp, the payload hash and the
+// previous hash, separated by '\n'. Consumers of the broker message recompute it the same way
+func (r ChainRecord) ComputeHash() string {
+	h := sha256.New()
+	for _, part := range []string{
+		r.ClientID.Hex(),
+		strconv.FormatInt(r.Sequence, 10),
+		r.Timestamp.UTC().Format(time.RFC3339Nano),
+		r.PayloadHash,
+		r.PrevHash,
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{'\n'})
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type ChainBreakReason string
+
+const (
+	// ChainRecordAltered means the stored hash doesn't match the content of the record
+	ChainRecordAltered ChainBreakReason = "record_altered"
+	// ChainLinkBroken means the record doesn't commit to the hash of its predecessor
+	ChainLinkBroken ChainBreakReason = "link_broken"
+	// ChainRecordMissing means there is a gap in sequences
+	ChainRecordMissing ChainBreakReason = "record_missing"
+	// ChainHeadMismatch means the last record is not the head of the client, the tail has been removed
+	ChainHeadMismatch ChainBreakReason = "head_mismatch"
+)
+
+type ChainBreak struct {
+	Sequence int64            `json:"sequence"`
+	Reason   ChainBreakReason `json:"reason"`
+	Expected string           `json:"expected,omitempty"`
+	Actual   string           `json:"actual,omitempty"`
+}
+
+type ChainVerification struct {
+	ClientID      string       `json:"clientID"`
+	From          time.Time    `json:"from"`
+	To            time.Time    `json:"to"`
+	Records       int          `json:"records"`
+	FirstSequence int64        `json:"firstSequence"`
+	LastSequence  int64        `json:"lastSequence"`
+	Valid         bool         `json:"valid"`
+	Breaks        []ChainBreak `json:"breaks"`
+}
+
+// VerifyChain checks records ordered by sequence. The predecessor is the record before the first one,
+// nil when the first record is the start of the chain or unknown
+func VerifyChain(predecessor *ChainRecord, records []ChainRecord) []ChainBreak {
+	breaks := make([]ChainBreak, 0)
+
+	prev := predecessor
+	for i := range records {
+		record := records[i]
+
+		if actual := record.ComputeHash(); actual != record.Hash {
+			breaks = append(breaks, ChainBreak{
+				Sequence: record.Sequence, Reason: ChainRecordAltered, Expected: record.Hash, Actual: actual,
+			})
+		}
+
+		switch {
+		case prev == nil && record.Sequence == 1 && record.PrevHash != GenesisHash:
+			breaks = append(breaks, ChainBreak{
+				Sequence: record.Sequence, Reason: ChainLinkBroken, Expected: GenesisHash, Actual: record.PrevHash,
+			})
+		case prev != nil && record.Sequence != prev.Sequence+1:
+			breaks = append(breaks, ChainBreak{Sequence: prev.Sequence + 1, Reason: ChainRecordMissing})
+		case prev != nil && record.PrevHash != prev.Hash:
+			breaks = append(breaks, ChainBreak{
+				Sequence: record.Sequence, Reason: ChainLinkBroken, Expected: prev.Hash, Actual: record.PrevHash,
+			})
+		}
+
+		prev = &records[i]
+	}
+
+	return breaks
+}
@@ -0,0 +1,20 @@
+package entities
+
+// ClientImport is the outcome of the import, rows are numbered from 1 without the CSV header.
+// Nothing is created if any row has errors
+type ClientImport struct {
+	DryRun  bool             `json:"dryRun"`
+	Rows    int              `json:"rows"`
+	Created []ImportedClient `json:"created"`
+	Errors  []ImportError    `json:"errors"`
+}
+
+type ImportedClient struct {
+	Row      int    `json:"row"`
+	ClientID string `json:"clientID"`
+}
+
+type ImportError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
@@ -0,0 +1,27 @@
+package entities
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type ClientOperation string
+
+const (
+	ClientCreated  ClientOperation = "create"
+	ClientUpdated  ClientOperation = "update"
+	ClientDeleted  ClientOperation = "delete"
+	ClientRestored ClientOperation = "restore"
+)
+
+// ClientVersion is the state of the client after the operation with the difference from the previous one
+type ClientVersion struct {
+	ID        primitive.ObjectID `json:"ID" bson:"_id"`
+	TenantID  primitive.ObjectID `json:"tenantID" bson:"tenantID"`
+	ClientID  primitive.ObjectID `json:"clientID" bson:"clientID"`
+	Operation ClientOperation    `json:"operation" bson:"operation"`
+	Client    Client             `json:"client" bson:"client"`
+	Changes   []AuditChange      `json:"changes" bson:"changes"`
+	RequestID string             `json:"requestID" bson:"requestID"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+}
@@ -1,13 +1,131 @@
 package entities
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/Imm0bilize/gunshot-api-service/internal/geo"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Client struct {
-	ID           primitive.ObjectID `json:"ID" bson:"_id"`
-	LocationName string             `json:"locationName" bson:"locationName"`
-	FullName     string             `json:"fullName" bson:"fullName"`
-	Latitude     float64            `json:"latitude" bson:"latitude"`
-	Longitude    float64            `json:"longitude" bson:"longitude"`
+	ID           primitive.ObjectID   `json:"ID" bson:"_id"`
+	TenantID     primitive.ObjectID   `json:"tenantID" bson:"tenantID"`
+	LocationName string               `json:"locationName" bson:"locationName"`
+	FullName     string               `json:"fullName" bson:"fullName"`
+	Latitude     float64              `json:"latitude" bson:"latitude"`
+	Longitude    float64              `json:"longitude" bson:"longitude"`
+	ZoneIDs      []primitive.ObjectID `json:"zoneIDs" bson:"zoneIDs"`
+	// Version grows with every change of the client by operators, it's the ETag of the client
+	Version int64        `json:"version" bson:"version"`
+	Health  ClientHealth `json:"health" bson:"health"`
+	// AppliedConfig is the version of the config reported by the client
+	AppliedConfig int64      `json:"appliedConfigVersion" bson:"appliedConfigVersion"`
+	Clock         ClockState `json:"clock" bson:"clock"`
+	// Chain is the head of the hash chain over uploaded audio
+	Chain ChainHead `json:"chain" bson:"chain"`
+	// Keys verify signatures of uploads, several keys are active during rotation
+	Keys []DeviceKey `json:"keys" bson:"keys"`
+	// DeletedAt marks the client deleted, it's purged after the grace period unless restored
+	DeletedAt *time.Time `json:"deletedAt,omitempty" bson:"deletedAt,omitempty"`
+}
+
+// ActiveKeys returns keys which may sign uploads at the moment
+func (c Client) ActiveKeys(now time.Time) []DeviceKey {
+	keys := make([]DeviceKey, 0, len(c.Keys))
+	for _, key := range c.Keys {
+		if key.ActiveAt(now) {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+// ClientPatch changes fields of the client which are set. ZoneIDs are set along with the location
+type ClientPatch struct {
+	LocationName *string
+	FullName     *string
+	Latitude     *float64
+	Longitude    *float64
+	ZoneIDs      []primitive.ObjectID
+}
+
+// Empty tells if the patch changes nothing
+func (p ClientPatch) Empty() bool {
+	return p.LocationName == nil && p.FullName == nil && !p.Moves() && p.ZoneIDs == nil
+}
+
+// Moves tells if the patch changes the location of the client
+func (p ClientPatch) Moves() bool {
+	return p.Latitude != nil || p.Longitude != nil
+}
+
+// Apply returns the client with the patch applied
+func (p ClientPatch) Apply(client Client) Client {
+	if p.LocationName != nil {
+		client.LocationName = *p.LocationName
+	}
+	if p.FullName != nil {
+		client.FullName = *p.FullName
+	}
+	if p.Latitude != nil {
+		client.Latitude = *p.Latitude
+	}
+	if p.Longitude != nil {
+		client.Longitude = *p.Longitude
+	}
+	if p.ZoneIDs != nil {
+		client.ZoneIDs = p.ZoneIDs
+	}
+
+	return client
+}
+
+// ETag is the entity tag of the representation of the client. Health, clock and chain state change without
+// the version, so the tag carries the digest of the client along with the version writes are conditional on
+func (c Client) ETag() string {
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return ClientETag(c.Version)
+	}
+
+	digest := sha256.Sum256(raw)
+
+	return strconv.Quote(strconv.FormatInt(c.Version, 10) + "-" + hex.EncodeToString(digest[:8]))
+}
+
+// AnyVersion makes the conditional write match the client of any version, it's the version of 'If-Match: *'
+const AnyVersion int64 = -1
+
+// ClientETag is the entity tag of the version of the client, it's enough for conditional writes
+func ClientETag(version int64) string {
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// ParseClientETag returns the version of the entity tag, the digest of the representation is ignored
+func ParseClientETag(etag string) (int64, error) {
+	raw, err := strconv.Unquote(strings.TrimSpace(etag))
+	if err != nil {
+		return 0, err
+	}
+
+	if i := strings.IndexByte(raw, '-'); i >= 0 {
+		raw = raw[:i]
+	}
+
+	return strconv.ParseInt(raw, 10, 64)
+}
+
+func (c Client) Location() geo.Point {
+	return geo.Point{Latitude: c.Latitude, Longitude: c.Longitude}
+}
+
+// ClientFilter pages clients in the order of their IDs: the page starts after the client with the ID After
+type ClientFilter struct {
+	After primitive.ObjectID
+	Limit int64
 }
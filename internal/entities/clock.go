@@ -0,0 +1,49 @@
+package entities
+
+import (
+	"time"
+)
+
+// ClockSample is one NTP-style exchange: the client sends a request at ClientSend, the server receives it
+// at ServerReceive and answers at ServerSend, the client receives the answer at ClientReceive.
+// Client times are read from the client clock, server times from the server clock
+type ClockSample struct {
+	ClientSend    time.Time `json:"clientSend"`
+	ServerReceive time.Time `json:"serverReceive"`
+	ServerSend    time.Time `json:"serverSend"`
+	ClientReceive time.Time `json:"clientReceive"`
+}
+
+// Offset is how much the server clock is ahead of the client clock
+func (s ClockSample) Offset() time.Duration {
+	return (s.ServerReceive.Sub(s.ClientSend) + s.ServerSend.Sub(s.ClientReceive)) / 2
+}
+
+// Delay is the round-trip time of the network
+func (s ClockSample) Delay() time.Duration {
+	return s.ClientReceive.Sub(s.ClientSend) - s.ServerSend.Sub(s.ServerReceive)
+}
+
+// ClockState is the running estimate of the client clock error
+type ClockState struct {
+	Offset    time.Duration `json:"offset" bson:"offset"`       // at UpdatedAt, server minus client
+	Drift     float64       `json:"drift" bson:"drift"`         // ppm, how fast the offset grows
+	Delay     time.Duration `json:"delay" bson:"delay"`         // of the last exchange
+	Samples   int           `json:"samples" bson:"samples"`     // number of exchanges in the estimate
+	UpdatedAt time.Time     `json:"updatedAt" bson:"updatedAt"` // server time of the last exchange
+	Skewed    bool          `json:"skewed" bson:"skewed"`
+}
+
+// OffsetAt extrapolates the offset to the passed server time
+func (c ClockState) OffsetAt(t time.Time) time.Duration {
+	if c.Samples == 0 {
+		return 0
+	}
+
+	return c.Offset + time.Duration(c.Drift*float64(t.Sub(c.UpdatedAt))/1e6)
+}
+
+// Correct converts time of the client clock into the server time
+func (c ClockState) Correct(t time.Time) time.Time {
+	return t.Add(c.OffsetAt(t))
+}
@@ -0,0 +1,55 @@
+package entities
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type CommandType string
+
+const (
+	CommandReboot       CommandType = "reboot"
+	CommandRecalibrate  CommandType = "recalibrate"
+	CommandUploadBuffer CommandType = "upload_buffer"
+)
+
+type CommandStatus string
+
+const (
+	CommandPending      CommandStatus = "pending"
+	CommandDelivered    CommandStatus = "delivered"
+	CommandAcknowledged CommandStatus = "acknowledged"
+	CommandSucceeded    CommandStatus = "succeeded"
+	CommandFailed       CommandStatus = "failed"
+	CommandExpired      CommandStatus = "expired"
+)
+
+// Command is an instruction for the sensor, e.g. upload_buffer with args {"around": "<RFC 3339>", "seconds": "30"}
+type Command struct {
+	ID          primitive.ObjectID `json:"ID" bson:"_id"`
+	ClientID    primitive.ObjectID `json:"clientID" bson:"clientID"`
+	Type        CommandType        `json:"type" bson:"type"`
+	Args        map[string]string  `json:"args,omitempty" bson:"args,omitempty"`
+	Status      CommandStatus      `json:"status" bson:"status"`
+	CreatedAt   time.Time          `json:"createdAt" bson:"createdAt"`
+	ExpiresAt   time.Time          `json:"expiresAt" bson:"expiresAt"`
+	DeliveredAt time.Time          `json:"deliveredAt,omitempty" bson:"deliveredAt,omitempty"`
+	AckedAt     time.Time          `json:"ackedAt,omitempty" bson:"ackedAt,omitempty"`
+	CompletedAt time.Time          `json:"completedAt,omitempty" bson:"completedAt,omitempty"`
+	Result      string             `json:"result,omitempty" bson:"result,omitempty"`
+	Error       string             `json:"error,omitempty" bson:"error,omitempty"`
+}
+
+// CommandResult is the outcome of the command reported by the sensor
+type CommandResult struct {
+	Success bool
+	Result  string
+	Error   string
+}
+
+type CommandFilter struct {
+	ClientID primitive.ObjectID
+	Status   CommandStatus
+	Limit    int64
+	Offset   int64
+}
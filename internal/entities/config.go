@@ -0,0 +1,64 @@
+package entities
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type ConfigScope string
+
+const (
+	ConfigScopeClient ConfigScope = "client"
+	ConfigScopeZone   ConfigScope = "zone"
+)
+
+// SensorConfig holds settings of the recording pipeline, nil fields are not set on the level
+// and inherited from the level below
+type SensorConfig struct {
+	Gain           *float64 `json:"gain,omitempty" bson:"gain,omitempty"`                     // dB
+	SampleRate     *int     `json:"sampleRate,omitempty" bson:"sampleRate,omitempty"`         // Hz
+	ChunkLength    *int     `json:"chunkLength,omitempty" bson:"chunkLength,omitempty"`       // seconds
+	UploadInterval *int     `json:"uploadInterval,omitempty" bson:"uploadInterval,omitempty"` // seconds
+}
+
+// Merge returns the config with fields set in the override replacing its own
+func (c SensorConfig) Merge(override SensorConfig) SensorConfig {
+	if override.Gain != nil {
+		c.Gain = override.Gain
+	}
+	if override.SampleRate != nil {
+		c.SampleRate = override.SampleRate
+	}
+	if override.ChunkLength != nil {
+		c.ChunkLength = override.ChunkLength
+	}
+	if override.UploadInterval != nil {
+		c.UploadInterval = override.UploadInterval
+	}
+
+	return c
+}
+
+// ConfigDocument is the desired config of a client or a zone, the version is increased on every change
+type ConfigDocument struct {
+	ID        primitive.ObjectID `json:"ID" bson:"_id"`
+	Scope     ConfigScope        `json:"scope" bson:"scope"`
+	OwnerID   primitive.ObjectID `json:"ownerID" bson:"ownerID"`
+	Version   int64              `json:"version" bson:"version"`
+	Config    SensorConfig       `json:"config" bson:"config"`
+	UpdatedAt time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+// EffectiveConfig is the config the client must apply: configs of its zones with the client override on top
+type EffectiveConfig struct {
+	ClientID       primitive.ObjectID `json:"clientID"`
+	Version        int64              `json:"version"`
+	ETag           string             `json:"-"`
+	Config         SensorConfig       `json:"config"`
+	AppliedVersion int64              `json:"appliedVersion"`
+}
+
+// Drift reports whether the client runs another version of the config than desired
+func (e EffectiveConfig) Drift() bool {
+	return e.AppliedVersion != e.Version
+}
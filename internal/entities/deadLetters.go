@@ -0,0 +1,20 @@
+package entities
+
+// DeadLetterReplay selects letters of the partition of the dead-letter topic by their offsets there, To is
+// inclusive and the nil one reaches the newest letter. Non-empty SourceOffsets keep the letters whose x-offset
+// header (the offset in the topic they have failed in) is one of them
+type DeadLetterReplay struct {
+	Partition     int32   `json:"partition"`
+	From          int64   `json:"from"`
+	To            *int64  `json:"to,omitempty"`
+	SourceOffsets []int64 `json:"sourceOffsets,omitempty"`
+}
+
+// DeadLetterReplayResult counts the letters read: Replayed are produced to the topic they have come from again,
+// Skipped don't match SourceOffsets or don't come from the detections topic. Next is the offset after the last
+// letter read, the replay of the rest starts from it
+type DeadLetterReplayResult struct {
+	Replayed int   `json:"replayed"`
+	Skipped  int   `json:"skipped"`
+	Next     int64 `json:"next"`
+}
@@ -0,0 +1,79 @@
+package entities
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"strconv"
+	"time"
+)
+
+var ErrInvalidPublicKey = errors.New("the public key must be 32 bytes of an Ed25519 key")
+
+// DeviceKey is the Ed25519 key the client signs uploads with. A rotated key keeps working until ExpiresAt,
+// the zero ExpiresAt means the key doesn't expire
+type DeviceKey struct {
+	ID        string    `json:"ID" bson:"id"`
+	PublicKey []byte    `json:"publicKey" bson:"publicKey"`
+	CreatedAt time.Time `json:"createdAt" bson:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt,omitempty" bson:"expiresAt,omitempty"`
+}
+
+// NewDeviceKey validates the key, its id is the beginning of SHA-256 of the key
+func NewDeviceKey(publicKey []byte, now time.Time) (DeviceKey, error) {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return DeviceKey{}, ErrInvalidPublicKey
+	}
+
+	sum := sha256.Sum256(publicKey)
+
+	return DeviceKey{ID: hex.EncodeToString(sum[:8]), PublicKey: publicKey, CreatedAt: now}, nil
+}
+
+func (k DeviceKey) ActiveAt(t time.Time) bool {
+	return k.ExpiresAt.IsZero() || t.Before(k.ExpiresAt)
+}
+
+// Verify checks the signature of the message with the key
+func (k DeviceKey) Verify(message, signature []byte) bool {
+	return len(k.PublicKey) == ed25519.PublicKeySize && ed25519.Verify(k.PublicKey, message, signature)
+}
+
+// UploadSignature is sent by the client with the audio. Sequence is the counter of uploads of the device,
+// it must grow, so a captured upload can't be replayed
+type UploadSignature struct {
+	Sequence  int64  `json:"sequence"`
+	Signature []byte `json:"signature,omitempty"`
+}
+
+type SignatureStatus string
+
+const (
+	SignatureVerified SignatureStatus = "verified"
+	SignatureAbsent   SignatureStatus = "unsigned"
+)
+
+// SignatureVerification is the result of the check of the upload signature
+type SignatureVerification struct {
+	Status SignatureStatus `json:"status" bson:"status"`
+	KeyID  string          `json:"keyID,omitempty" bson:"keyID,omitempty"`
+}
+
+// UploadSigningPayload is the message the client signs: the client id, the timestamp of the upload in unix
+// milliseconds as sent by the client, the sequence and the hex SHA-256 of the audio, each followed by '\n'
+func UploadSigningPayload(clientID primitive.ObjectID, timestamp time.Time, sequence int64, payloadHash string) []byte {
+	var message []byte
+	for _, part := range []string{
+		clientID.Hex(),
+		strconv.FormatInt(timestamp.UnixMilli(), 10),
+		strconv.FormatInt(sequence, 10),
+		payloadHash,
+	} {
+		message = append(message, part...)
+		message = append(message, '\n')
+	}
+
+	return message
+}
@@ -0,0 +1,64 @@
+package entities
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type HealthStatus string
+
+const (
+	HealthOnline   HealthStatus = "online"
+	HealthDegraded HealthStatus = "degraded"
+	HealthOffline  HealthStatus = "offline"
+)
+
+// Heartbeat is the periodic status report of the sensor
+type Heartbeat struct {
+	ID              primitive.ObjectID `json:"ID" bson:"_id"`
+	TenantID        primitive.ObjectID `json:"tenantID" bson:"tenantID"`
+	ClientID        primitive.ObjectID `json:"clientID" bson:"clientID"`
+	Battery         float64            `json:"battery" bson:"battery"`               // percent
+	SignalStrength  float64            `json:"signalStrength" bson:"signalStrength"` // dBm
+	FirmwareVersion string             `json:"firmwareVersion" bson:"firmwareVersion"`
+	DiskUsage       float64            `json:"diskUsage" bson:"diskUsage"`     // percent
+	Temperature     float64            `json:"temperature" bson:"temperature"` // celsius
+	ReceivedAt      time.Time          `json:"receivedAt" bson:"receivedAt"`
+	ExpiresAt       *time.Time         `json:"expiresAt,omitempty" bson:"expiresAt,omitempty"`
+}
+
+// ClientHealth is the latest known state of the sensor
+type ClientHealth struct {
+	Status    HealthStatus `json:"status" bson:"status"`
+	LastSeen  time.Time    `json:"lastSeen" bson:"lastSeen"`
+	Heartbeat Heartbeat    `json:"heartbeat" bson:"heartbeat"`
+}
+
+type HeartbeatFilter struct {
+	ClientID primitive.ObjectID
+	From     time.Time
+	To       time.Time
+	Limit    int64
+	Offset   int64
+}
+
+type FleetHealth struct {
+	Online   int            `json:"online"`
+	Degraded int            `json:"degraded"`
+	Offline  int            `json:"offline"`
+	Clients  []ClientStatus `json:"clients"`
+}
+
+type ClientStatus struct {
+	ClientID primitive.ObjectID `json:"clientID"`
+	Health   ClientHealth       `json:"health"`
+}
+
+// MaintenanceEvent is raised when the sensor needs the attention of the maintenance team
+type MaintenanceEvent struct {
+	ClientID  primitive.ObjectID `json:"clientID"`
+	From      HealthStatus       `json:"from"`
+	To        HealthStatus       `json:"to"`
+	LastSeen  time.Time          `json:"lastSeen"`
+	Timestamp time.Time          `json:"timestamp"`
+}
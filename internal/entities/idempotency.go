@@ -0,0 +1,33 @@
+package entities
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// IdempotentRequest is the POST or PATCH of the tenant sent with the 'Idempotency-Key' header. It's pending
+// while the first attempt is served, then the response is kept until ExpiresAt and is returned to retries of
+// the request instead of serving them again
+type IdempotentRequest struct {
+	ID       primitive.ObjectID `bson:"_id"`
+	TenantID primitive.ObjectID `bson:"tenantID"`
+	Key      string             `bson:"key"`
+	Method   string             `bson:"method"`
+	Path     string             `bson:"path"`
+	// BodyHash is the hex SHA-256 of the request body
+	BodyHash string `bson:"bodyHash"`
+	// Status is 0 while the request is pending
+	Status    int               `bson:"status"`
+	Header    map[string]string `bson:"header,omitempty"`
+	Body      []byte            `bson:"body,omitempty"`
+	ExpiresAt time.Time         `bson:"expiresAt"`
+}
+
+func (r IdempotentRequest) Pending() bool {
+	return r.Status == 0
+}
+
+// Matches tells if the request is the retry of r rather than another request which has taken its key
+func (r IdempotentRequest) Matches(request IdempotentRequest) bool {
+	return r.Method == request.Method && r.Path == request.Path && r.BodyHash == request.BodyHash
+}
@@ -0,0 +1,87 @@
+package entities
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+const LabelGunshot = "gunshot"
+
+type IncidentStatus string
+
+const (
+	IncidentNew           IncidentStatus = "new"
+	IncidentAcknowledged  IncidentStatus = "acknowledged"
+	IncidentDispatched    IncidentStatus = "dispatched"
+	IncidentResolved      IncidentStatus = "resolved"
+	IncidentFalsePositive IncidentStatus = "false_positive"
+)
+
+// ClosedIncidentStatuses are statuses of incidents which don't accept new detections and transitions
+var ClosedIncidentStatuses = []IncidentStatus{IncidentResolved, IncidentFalsePositive}
+
+type Detection struct {
+	ID            primitive.ObjectID   `json:"ID" bson:"_id"`
+	TenantID      primitive.ObjectID   `json:"tenantID" bson:"tenantID"`
+	RequestID     string               `json:"requestID" bson:"requestID"`
+	ClientID      primitive.ObjectID   `json:"clientID" bson:"clientID"`
+	IncidentID    primitive.ObjectID   `json:"incidentID,omitempty" bson:"incidentID,omitempty"`
+	Label         string               `json:"label" bson:"label"`
+	Confidence    float64              `json:"confidence" bson:"confidence"`
+	ModelVersion  string               `json:"modelVersion" bson:"modelVersion"`
+	Timestamp     time.Time            `json:"timestamp" bson:"timestamp"`
+	FalsePositive bool                 `json:"falsePositive" bson:"falsePositive"`
+	ZoneIDs       []primitive.ObjectID `json:"zoneIDs" bson:"zoneIDs"`
+	// ExpiresAt is watched by the TTL index, detections of held incidents have none
+	ExpiresAt *time.Time `json:"expiresAt,omitempty" bson:"expiresAt,omitempty"`
+}
+
+type DetectionFilter struct {
+	ClientID   primitive.ObjectID
+	ZoneID     primitive.ObjectID
+	IncidentID primitive.ObjectID
+	From       time.Time
+	To         time.Time
+	// After lists detections stored after the one with the ID in the order they are stored instead of
+	// the order of their timestamps
+	After  primitive.ObjectID
+	Limit  int64
+	Offset int64
+}
+
+type Incident struct {
+	ID          primitive.ObjectID   `json:"ID" bson:"_id"`
+	TenantID    primitive.ObjectID   `json:"tenantID" bson:"tenantID"`
+	Latitude    float64              `json:"latitude" bson:"latitude"`
+	Longitude   float64              `json:"longitude" bson:"longitude"`
+	ShotCount   int                  `json:"shotCount" bson:"shotCount"`
+	FirstSeen   time.Time            `json:"firstSeen" bson:"firstSeen"`
+	LastSeen    time.Time            `json:"lastSeen" bson:"lastSeen"`
+	Clients     []primitive.ObjectID `json:"clients" bson:"clients"`
+	Status      IncidentStatus       `json:"status" bson:"status"`
+	Transitions []IncidentTransition `json:"transitions" bson:"transitions"`
+	ZoneIDs     []primitive.ObjectID `json:"zoneIDs" bson:"zoneIDs"`
+	LegalHold   *LegalHold           `json:"legalHold,omitempty" bson:"legalHold,omitempty"`
+}
+
+type IncidentTransition struct {
+	From IncidentStatus `json:"from" bson:"from"`
+	To   IncidentStatus `json:"to" bson:"to"`
+	// Actor is the authenticated caller, organization:<id> or admin as in the audit log
+	Actor string `json:"actor" bson:"actor"`
+	// DisplayName is the name the caller has given, it's not verified
+	DisplayName string    `json:"displayName,omitempty" bson:"displayName,omitempty"`
+	Notes       string    `json:"notes" bson:"notes"`
+	Timestamp   time.Time `json:"timestamp" bson:"timestamp"`
+}
+
+type IncidentFilter struct {
+	ZoneID   primitive.ObjectID
+	ClientID primitive.ObjectID
+	// Held selects incidents under the legal hold only
+	Held   bool
+	From   time.Time
+	To     time.Time
+	Limit  int64
+	Offset int64
+}
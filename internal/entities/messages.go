@@ -6,8 +6,17 @@ import (
 )
 
 type Message struct {
-	Payload     []byte             `json:"payload"`
-	Timestamp   time.Time          `json:"timestamp"`
-	MessageType string             `json:"messageType"`
-	ID          primitive.ObjectID `json:"ID"`
+	Payload []byte `json:"payload"`
+	// Timestamp is corrected by the clock estimate of the client, RawTimestamp is the one the client sent
+	Timestamp    time.Time          `json:"timestamp"`
+	RawTimestamp time.Time          `json:"rawTimestamp"`
+	MessageType  string             `json:"messageType"`
+	ID           primitive.ObjectID `json:"ID"`
+	// Chain links the payload to the previous upload of the client
+	Chain ChainLink `json:"chain"`
+	// Signature is made by the device, Verification is the result of its check by the service
+	Signature    UploadSignature       `json:"signature"`
+	Verification SignatureVerification `json:"verification"`
+	// Filter is set when the privacy filter of the organization has changed the payload
+	Filter *AppliedFilter `json:"filter,omitempty"`
 }
@@ -0,0 +1,26 @@
+package entities
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// Organization is the tenant (e.g. a municipality) which owns sensors and their data
+type Organization struct {
+	ID         primitive.ObjectID `json:"ID" bson:"_id"`
+	Name       string             `json:"name" bson:"name"`
+	APIKeyHash string             `json:"-" bson:"apiKeyHash"`
+	Quota      Quota              `json:"quota" bson:"quota"`
+	Retention  Retention          `json:"retention" bson:"retention"`
+	Privacy    PrivacyFilter      `json:"privacy" bson:"privacy"`
+	CreatedAt  time.Time          `json:"createdAt" bson:"createdAt"`
+	// Sensors counts live clients of the organization against the quota, it's missing in organizations
+	// created before the counter and set up from their clients on the first use
+	Sensors int64 `json:"-" bson:"sensors"`
+}
+
+// Quota limits the usage of the organization, zero values mean no limit
+type Quota struct {
+	MaxSensors       int `json:"maxSensors" bson:"maxSensors"`
+	UploadsPerMinute int `json:"uploadsPerMinute" bson:"uploadsPerMinute"`
+}
@@ -0,0 +1,38 @@
+package entities
+
+type PrivacyMode string
+
+const (
+	// PrivacySpeechBand attenuates the speech band outside impulse windows
+	PrivacySpeechBand PrivacyMode = "speech-band"
+	// PrivacyImpulsesOnly keeps impulse windows only, the rest of the audio is zeroed
+	PrivacyImpulsesOnly PrivacyMode = "impulses-only"
+)
+
+// PrivacyFilter is applied to the audio of the organization before it's archived and forwarded,
+// the empty mode keeps the audio as it is
+type PrivacyFilter struct {
+	Mode PrivacyMode `json:"mode,omitempty" bson:"mode,omitempty"`
+}
+
+func (f PrivacyFilter) Enabled() bool {
+	return f.Mode != ""
+}
+
+// ImpulseWindow is the part of the audio around a detected impulse, offsets from the start in milliseconds
+type ImpulseWindow struct {
+	StartMs int64 `json:"startMs" bson:"startMs"`
+	EndMs   int64 `json:"endMs" bson:"endMs"`
+}
+
+// AppliedFilter tags the audio changed by the privacy filter
+type AppliedFilter struct {
+	Mode PrivacyMode `json:"mode" bson:"mode"`
+	// LowHz and HighHz bound the attenuated band of PrivacySpeechBand
+	LowHz  int `json:"lowHz,omitempty" bson:"lowHz,omitempty"`
+	HighHz int `json:"highHz,omitempty" bson:"highHz,omitempty"`
+	// Windows are kept untouched
+	Windows []ImpulseWindow `json:"windows" bson:"windows"`
+	// SourceHash is SHA-256 of the audio as the device sent it, the device signature covers this one
+	SourceHash string `json:"sourceHash" bson:"sourceHash"`
+}
@@ -0,0 +1,90 @@
+package entities
+
+import (
+	"time"
+)
+
+// RetentionClass is the kind of data kept for its own period
+type RetentionClass string
+
+const (
+	RetentionAudio      RetentionClass = "audio"
+	RetentionDetections RetentionClass = "detections"
+	RetentionHeartbeats RetentionClass = "heartbeats"
+)
+
+// Retention is the period the data of every class is kept for. The zero period of the organization
+// falls back to the default of the service, the zero default keeps the data forever
+type Retention struct {
+	AudioDays     int `json:"audioDays" bson:"audioDays"`
+	DetectionDays int `json:"detectionDays" bson:"detectionDays"`
+	HeartbeatDays int `json:"heartbeatDays" bson:"heartbeatDays"`
+}
+
+// Or fills periods which are not set from defaults
+func (r Retention) Or(defaults Retention) Retention {
+	if r.AudioDays <= 0 {
+		r.AudioDays = defaults.AudioDays
+	}
+	if r.DetectionDays <= 0 {
+		r.DetectionDays = defaults.DetectionDays
+	}
+	if r.HeartbeatDays <= 0 {
+		r.HeartbeatDays = defaults.HeartbeatDays
+	}
+
+	return r
+}
+
+// Period returns how long the data of the class is kept, zero means forever
+func (r Retention) Period(class RetentionClass) time.Duration {
+	var days int
+	switch class {
+	case RetentionAudio:
+		days = r.AudioDays
+	case RetentionDetections:
+		days = r.DetectionDays
+	case RetentionHeartbeats:
+		days = r.HeartbeatDays
+	}
+
+	if days <= 0 {
+		return 0
+	}
+
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// ExpiresAt returns when the data of the class created at the moment is removed, nil means never
+func (r Retention) ExpiresAt(class RetentionClass, createdAt time.Time) *time.Time {
+	period := r.Period(class)
+	if period == 0 {
+		return nil
+	}
+
+	expiresAt := createdAt.Add(period).UTC()
+	return &expiresAt
+}
+
+// LegalHold exempts audio and detections of the incident from the retention
+type LegalHold struct {
+	Reason string    `json:"reason" bson:"reason"`
+	Actor  string    `json:"actor" bson:"actor"`
+	Since  time.Time `json:"since" bson:"since"`
+}
+
+// RetentionClassReport is what the sweep removes of the class, Held is exempted by legal holds
+type RetentionClassReport struct {
+	Class RetentionClass `json:"class"`
+	Count int64          `json:"count"`
+	Bytes int64          `json:"bytes,omitempty"`
+	Held  int64          `json:"held,omitempty"`
+}
+
+// RetentionReport is the dry run of the sweep: the data expiring before Until
+type RetentionReport struct {
+	GeneratedAt time.Time              `json:"generatedAt"`
+	Until       time.Time              `json:"until"`
+	Retention   Retention              `json:"retention"`
+	Classes     []RetentionClassReport `json:"classes"`
+}
@@ -0,0 +1,40 @@
+package entities
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// ErasedValue replaces personal data which can't be deleted, e.g. values in the audit log
+const ErasedValue = "[erased]"
+
+// Tombstone marks the erased client, late messages of the broker about it are dropped
+type Tombstone struct {
+	ClientID  primitive.ObjectID `json:"clientID" bson:"_id"`
+	TenantID  primitive.ObjectID `json:"tenantID" bson:"tenantID"`
+	RequestID string             `json:"requestID" bson:"requestID"`
+	ErasedAt  time.Time          `json:"erasedAt" bson:"erasedAt"`
+	Report    ErasureReport      `json:"report" bson:"report"`
+}
+
+// ErasureReport counts documents of every collection the erasure has touched
+type ErasureReport struct {
+	Deleted       map[string]int64 `json:"deleted" bson:"deleted"`
+	Pseudonymised map[string]int64 `json:"pseudonymised" bson:"pseudonymised"`
+	// Held are kept for incidents under the legal hold, they refer to the client by its ID only
+	Held map[string]int64 `json:"held" bson:"held"`
+}
+
+func NewErasureReport() ErasureReport {
+	return ErasureReport{
+		Deleted:       make(map[string]int64),
+		Pseudonymised: make(map[string]int64),
+		Held:          make(map[string]int64),
+	}
+}
+
+// ErasureHold is what the erasure has to keep: detections of held incidents and the audio in their windows
+type ErasureHold struct {
+	Incidents []primitive.ObjectID
+	Windows   [][2]time.Time
+}
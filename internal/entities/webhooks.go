@@ -0,0 +1,33 @@
+package entities
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type WebhookEvent string
+
+const (
+	// WebhookIncidentTransition is sent when the incident has moved to another status
+	WebhookIncidentTransition WebhookEvent = "incident.transition"
+	// WebhookAlert is sent when the alert rule has matched the detection
+	WebhookAlert WebhookEvent = "alert"
+)
+
+var WebhookEvents = []WebhookEvent{WebhookIncidentTransition, WebhookAlert}
+
+// Webhook is the URL of the organization events are POSTed to, deliveries are signed by the secret
+type Webhook struct {
+	ID        primitive.ObjectID `json:"ID" bson:"_id"`
+	TenantID  primitive.ObjectID `json:"tenantID" bson:"tenantID"`
+	URL       string             `json:"url" bson:"url"`
+	Events    []WebhookEvent     `json:"events" bson:"events"`
+	Secret    string             `json:"-" bson:"secret"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+// WebhookPayloadIncident is the data of the incident.transition event
+type WebhookPayloadIncident struct {
+	Incident   Incident           `json:"incident"`
+	Transition IncidentTransition `json:"transition"`
+}
@@ -0,0 +1,28 @@
+package entities
+
+import (
+	"github.com/Imm0bilize/gunshot-api-service/internal/geo"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Zone is a named area (district, campus, station) which clients and incidents are assigned to
+type Zone struct {
+	ID       primitive.ObjectID `json:"ID" bson:"_id"`
+	TenantID primitive.ObjectID `json:"tenantID" bson:"tenantID"`
+	Name     string             `json:"name" bson:"name"`
+	Owner    string             `json:"owner" bson:"owner"`
+	Metadata map[string]string  `json:"metadata" bson:"metadata"`
+	Area     geo.Polygon        `json:"area" bson:"area"`
+}
+
+// ZonesOf returns ids of zones that contain the point
+func ZonesOf(zones []Zone, point geo.Point) []primitive.ObjectID {
+	ids := make([]primitive.ObjectID, 0)
+	for _, zone := range zones {
+		if zone.Area.Contains(point) {
+			ids = append(ids, zone.ID)
+		}
+	}
+
+	return ids
+}
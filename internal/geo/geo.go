@@ -0,0 +1,25 @@
+package geo
+
+import "math"
+
+const _earthRadius = 6371000.0 // meters
+
+type Point struct {
+	Latitude  float64 `json:"latitude" bson:"latitude"`
+	Longitude float64 `json:"longitude" bson:"longitude"`
+}
+
+// Distance returns the great-circle distance between two points in meters
+func Distance(a, b Point) float64 {
+	lat1, lat2 := toRadians(a.Latitude), toRadians(b.Latitude)
+	dLat := lat2 - lat1
+	dLon := toRadians(b.Longitude - a.Longitude)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * _earthRadius * math.Asin(math.Sqrt(h))
+}
+
+func toRadians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
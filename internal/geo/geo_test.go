@@ -0,0 +1,120 @@
+package geo_test
+
+import (
+	"github.com/Imm0bilize/gunshot-api-service/internal/geo"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	testTable := []struct {
+		name  string
+		a, b  geo.Point
+		exp   float64
+		delta float64
+	}{
+		{
+			name:  "same point",
+			a:     geo.Point{Latitude: 55.7558, Longitude: 37.6173},
+			b:     geo.Point{Latitude: 55.7558, Longitude: 37.6173},
+			exp:   0,
+			delta: 0.001,
+		},
+		{
+			name:  "moscow - saint petersburg",
+			a:     geo.Point{Latitude: 55.7558, Longitude: 37.6173},
+			b:     geo.Point{Latitude: 59.9343, Longitude: 30.3351},
+			exp:   634000,
+			delta: 2000,
+		},
+		{
+			name:  "one degree of latitude",
+			a:     geo.Point{Latitude: 0, Longitude: 0},
+			b:     geo.Point{Latitude: 1, Longitude: 0},
+			exp:   111195,
+			delta: 10,
+		},
+	}
+
+	for _, tCase := range testTable {
+		t.Run(tCase.name, func(t *testing.T) {
+			require.InDelta(t, tCase.exp, geo.Distance(tCase.a, tCase.b), tCase.delta)
+		})
+	}
+}
+
+func TestPolygonContains(t *testing.T) {
+	square := geo.Polygon{
+		Type: "Polygon",
+		Coordinates: [][][]float64{
+			{{37.0, 55.0}, {38.0, 55.0}, {38.0, 56.0}, {37.0, 56.0}, {37.0, 55.0}},
+			{{37.4, 55.4}, {37.6, 55.4}, {37.6, 55.6}, {37.4, 55.6}, {37.4, 55.4}},
+		},
+	}
+
+	testTable := []struct {
+		name  string
+		point geo.Point
+		exp   bool
+	}{
+		{
+			name:  "inside",
+			point: geo.Point{Latitude: 55.2, Longitude: 37.2},
+			exp:   true,
+		},
+		{
+			name:  "outside",
+			point: geo.Point{Latitude: 59.9, Longitude: 30.3},
+			exp:   false,
+		},
+		{
+			name:  "inside the hole",
+			point: geo.Point{Latitude: 55.5, Longitude: 37.5},
+			exp:   false,
+		},
+	}
+
+	require.NoError(t, square.Validate())
+
+	for _, tCase := range testTable {
+		t.Run(tCase.name, func(t *testing.T) {
+			require.Equal(t, tCase.exp, square.Contains(tCase.point))
+		})
+	}
+}
+
+func TestPolygonValidate(t *testing.T) {
+	testTable := []struct {
+		name    string
+		polygon geo.Polygon
+	}{
+		{
+			name:    "wrong type",
+			polygon: geo.Polygon{Type: "Point"},
+		},
+		{
+			name:    "no rings",
+			polygon: geo.Polygon{Type: "Polygon"},
+		},
+		{
+			name: "not closed ring",
+			polygon: geo.Polygon{
+				Type:        "Polygon",
+				Coordinates: [][][]float64{{{37, 55}, {38, 55}, {38, 56}, {37, 56}}},
+			},
+		},
+		{
+			name: "latitude out of range",
+			polygon: geo.Polygon{
+				Type:        "Polygon",
+				Coordinates: [][][]float64{{{37, 95}, {38, 55}, {38, 56}, {37, 95}}},
+			},
+		},
+	}
+
+	for _, tCase := range testTable {
+		t.Run(tCase.name, func(t *testing.T) {
+			require.ErrorIs(t, tCase.polygon.Validate(), geo.ErrInvalidPolygon)
+		})
+	}
+}
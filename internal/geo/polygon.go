@@ -0,0 +1,81 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrInvalidPolygon = errors.New("invalid polygon")
+)
+
+// Polygon is a GeoJSON polygon: the first ring is the exterior, the rest are holes.
+// Positions are [longitude, latitude] as required by RFC 7946
+type Polygon struct {
+	Type        string        `json:"type" bson:"type"`
+	Coordinates [][][]float64 `json:"coordinates" bson:"coordinates"`
+}
+
+func (p Polygon) Validate() error {
+	if p.Type != "Polygon" {
+		return fmt.Errorf("%w: type must be 'Polygon', got '%s'", ErrInvalidPolygon, p.Type)
+	}
+
+	if len(p.Coordinates) == 0 {
+		return fmt.Errorf("%w: exterior ring is required", ErrInvalidPolygon)
+	}
+
+	for i, ring := range p.Coordinates {
+		if len(ring) < 4 {
+			return fmt.Errorf("%w: ring %d must have at least 4 positions", ErrInvalidPolygon, i)
+		}
+
+		for _, position := range ring {
+			if len(position) < 2 {
+				return fmt.Errorf("%w: position must be [longitude, latitude]", ErrInvalidPolygon)
+			}
+			if position[0] < -180 || position[0] > 180 || position[1] < -90 || position[1] > 90 {
+				return fmt.Errorf("%w: position %v is out of range", ErrInvalidPolygon, position)
+			}
+		}
+
+		first, last := ring[0], ring[len(ring)-1]
+		if first[0] != last[0] || first[1] != last[1] {
+			return fmt.Errorf("%w: ring %d is not closed", ErrInvalidPolygon, i)
+		}
+	}
+
+	return nil
+}
+
+// Contains reports whether the point is inside the exterior ring and outside of the holes
+func (p Polygon) Contains(point Point) bool {
+	if len(p.Coordinates) == 0 || !ringContains(p.Coordinates[0], point) {
+		return false
+	}
+
+	for _, hole := range p.Coordinates[1:] {
+		if ringContains(hole, point) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ringContains is the ray casting test, the ring is small enough to treat it as planar
+func ringContains(ring [][]float64, point Point) bool {
+	inside := false
+
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		xi, yi := ring[i][0], ring[i][1]
+		xj, yj := ring[j][0], ring[j][1]
+
+		if (yi > point.Latitude) != (yj > point.Latitude) &&
+			point.Longitude < (xj-xi)*(point.Latitude-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
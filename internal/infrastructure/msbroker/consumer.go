@@ -0,0 +1,171 @@
+package msbroker
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/pkg/api/brokerschemas"
+	"github.com/Shopify/sarama"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/Shopify/sarama/otelsarama"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"strconv"
+)
+
+type DetectionProcessor interface {
+	Process(ctx context.Context, reqID uuid.UUID, detection *entities.Detection) error
+}
+
+type KafkaConsumer struct {
+	topic           string
+	deadLetterTopic string
+	tracer          trace.Tracer
+	group           sarama.ConsumerGroup
+	producer        sarama.SyncProducer
+	processor       DetectionProcessor
+	logger          *zap.Logger
+}
+
+// NewKafkaConsumer creates the consumer of detections, messages which can't be processed are sent to the
+// dead-letter topic by the producer
+func NewKafkaConsumer(
+	logger *zap.Logger,
+	group sarama.ConsumerGroup,
+	producer sarama.SyncProducer,
+	topic string,
+	deadLetterTopic string,
+	processor DetectionProcessor,
+) *KafkaConsumer {
+	return &KafkaConsumer{
+		topic:           topic,
+		deadLetterTopic: deadLetterTopic,
+		tracer:          otel.Tracer("msbroker"),
+		group:           group,
+		producer:        producer,
+		processor:       processor,
+		logger:          logger,
+	}
+}
+
+// Run consumes the detections topic until the context is canceled
+func (k *KafkaConsumer) Run(ctx context.Context) {
+	for {
+		if err := k.group.Consume(ctx, []string{k.topic}, k); err != nil {
+			if errors.Is(err, sarama.ErrClosedConsumerGroup) {
+				return
+			}
+
+			k.logger.Error("error during consume messages", zap.Error(err))
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+func (k *KafkaConsumer) Setup(sarama.ConsumerGroupSession) error { return nil }
+
+func (k *KafkaConsumer) Cleanup(sarama.ConsumerGroupSession) error { return nil }
+
+// ConsumeClaim marks the message once it's processed or sent to the dead-letter topic. If the dead letter
+// can't be sent, the claim stops without marking the message, so it's consumed again after the rebalance
+func (k *KafkaConsumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for msg := range claim.Messages() {
+		ctx := otel.GetTextMapPropagator().Extract(session.Context(), otelsarama.NewConsumerMessageCarrier(msg))
+
+		if err := k.handle(ctx, msg); err != nil {
+			k.logger.Error(
+				"error during handle message",
+				zap.Int32("partition", msg.Partition),
+				zap.Int64("offset", msg.Offset),
+				zap.Error(err),
+			)
+
+			if err := k.deadLetter(msg, err); err != nil {
+				k.logger.Error(
+					"error during send message to the dead-letter topic",
+					zap.Int32("partition", msg.Partition),
+					zap.Int64("offset", msg.Offset),
+					zap.Error(err),
+				)
+
+				return err
+			}
+		}
+
+		session.MarkMessage(msg, "")
+	}
+
+	return nil
+}
+
+// deadLetter sends the message as it is to the dead-letter topic, headers tell where it comes from and why
+// it has failed
+func (k *KafkaConsumer) deadLetter(msg *sarama.ConsumerMessage, reason error) error {
+	headers := make([]sarama.RecordHeader, 0, len(msg.Headers)+4)
+	for _, header := range msg.Headers {
+		if header != nil {
+			headers = append(headers, *header)
+		}
+	}
+
+	for _, header := range [][2]string{
+		{"x-error", reason.Error()},
+		{"x-topic", msg.Topic},
+		{"x-partition", strconv.FormatInt(int64(msg.Partition), 10)},
+		{"x-offset", strconv.FormatInt(msg.Offset, 10)},
+	} {
+		headers = append(headers, sarama.RecordHeader{Key: []byte(header[0]), Value: []byte(header[1])})
+	}
+
+	_, _, err := k.producer.SendMessage(&sarama.ProducerMessage{
+		Topic:   k.deadLetterTopic,
+		Key:     sarama.ByteEncoder(msg.Key),
+		Value:   sarama.ByteEncoder(msg.Value),
+		Headers: headers,
+	})
+	if err != nil {
+		return errors.Wrap(err, "can't send message into the dead-letter topic")
+	}
+
+	return nil
+}
+
+func (k *KafkaConsumer) handle(ctx context.Context, msg *sarama.ConsumerMessage) error {
+	ctx, span := k.tracer.Start(ctx, "msbroker.Consume")
+	defer span.End()
+
+	var detectionMsg brokerschemas.DetectionMessage
+	if err := json.Unmarshal(msg.Value, &detectionMsg); err != nil {
+		return errors.Wrap(err, "can't unmarshal msg")
+	}
+
+	clientID, err := primitive.ObjectIDFromHex(detectionMsg.ClientID)
+	if err != nil {
+		return errors.Wrap(err, "invalid client id")
+	}
+
+	detection := entities.Detection{
+		ClientID:     clientID,
+		Label:        detectionMsg.Label,
+		Confidence:   detectionMsg.Confidence,
+		ModelVersion: detectionMsg.ModelVersion,
+		Timestamp:    detectionMsg.Timestamp,
+	}
+
+	if err := k.processor.Process(ctx, detectionMsg.RequestID, &detection); err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "can't process detection")
+	}
+
+	return nil
+}
+
+func (k *KafkaConsumer) Shutdown() error {
+	return k.group.Close()
+}
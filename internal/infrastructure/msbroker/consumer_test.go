@@ -0,0 +1,137 @@
+package msbroker_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/msbroker"
+	"github.com/Imm0bilize/gunshot-api-service/pkg/api/brokerschemas"
+	"github.com/Shopify/sarama"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"testing"
+)
+
+type fakeProcessor struct {
+	err error
+}
+
+func (f fakeProcessor) Process(context.Context, uuid.UUID, *entities.Detection) error {
+	return f.err
+}
+
+type fakeProducer struct {
+	sarama.SyncProducer
+
+	err  error
+	sent []*sarama.ProducerMessage
+}
+
+func (f *fakeProducer) SendMessage(msg *sarama.ProducerMessage) (int32, int64, error) {
+	if f.err != nil {
+		return 0, 0, f.err
+	}
+
+	f.sent = append(f.sent, msg)
+
+	return 0, int64(len(f.sent)), nil
+}
+
+type fakeSession struct {
+	sarama.ConsumerGroupSession
+
+	marked []int64
+}
+
+func (f *fakeSession) Context() context.Context {
+	return context.Background()
+}
+
+func (f *fakeSession) MarkMessage(msg *sarama.ConsumerMessage, _ string) {
+	f.marked = append(f.marked, msg.Offset)
+}
+
+type fakeClaim struct {
+	sarama.ConsumerGroupClaim
+
+	messages chan *sarama.ConsumerMessage
+}
+
+func (f fakeClaim) Messages() <-chan *sarama.ConsumerMessage {
+	return f.messages
+}
+
+func TestConsumeClaim(t *testing.T) {
+	value, err := json.Marshal(brokerschemas.DetectionMessage{
+		RequestID: uuid.New(), ClientID: primitive.NewObjectID().Hex(), Label: entities.LabelGunshot,
+	})
+	require.NoError(t, err)
+
+	testTable := []struct {
+		name           string
+		processErr     error
+		sendErr        error
+		expErr         bool
+		expMarked      []int64
+		expDeadLetters int
+	}{
+		{
+			name:      "processed message is marked",
+			expMarked: []int64{7},
+		},
+		{
+			name:           "failed message is marked after the dead letter",
+			processErr:     errors.New("the database is down"),
+			expMarked:      []int64{7},
+			expDeadLetters: 1,
+		},
+		{
+			name:       "failed message is not marked without the dead letter",
+			processErr: errors.New("the database is down"),
+			sendErr:    errors.New("the broker is down"),
+			expErr:     true,
+		},
+	}
+
+	for _, tCase := range testTable {
+		t.Run(tCase.name, func(t *testing.T) {
+			var (
+				producer = &fakeProducer{err: tCase.sendErr}
+				session  = &fakeSession{}
+				claim    = fakeClaim{messages: make(chan *sarama.ConsumerMessage, 1)}
+			)
+
+			claim.messages <- &sarama.ConsumerMessage{Topic: "detections", Partition: 2, Offset: 7, Value: value}
+			close(claim.messages)
+
+			consumer := msbroker.NewKafkaConsumer(
+				zap.NewNop(), nil, producer, "detections", "detections-dlq", fakeProcessor{err: tCase.processErr},
+			)
+
+			err := consumer.ConsumeClaim(session, claim)
+			if tCase.expErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, tCase.expMarked, session.marked)
+			require.Len(t, producer.sent, tCase.expDeadLetters)
+
+			if tCase.expDeadLetters > 0 {
+				deadLetter := producer.sent[0]
+				require.Equal(t, "detections-dlq", deadLetter.Topic)
+				require.Equal(t, sarama.ByteEncoder(value), deadLetter.Value)
+				require.Contains(t, deadLetter.Headers, sarama.RecordHeader{
+					Key: []byte("x-error"), Value: []byte("can't process detection: the database is down"),
+				})
+				require.Contains(t, deadLetter.Headers, sarama.RecordHeader{
+					Key: []byte("x-offset"), Value: []byte("7"),
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package msbroker
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Shopify/sarama"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+)
+
+// _replayIdle is how long the replay waits for the next letter before it returns what it has read
+const _replayIdle = 10 * time.Second
+
+// _deadLetterHeaders are set by the consumer when the message is sent to the dead-letter topic
+var _deadLetterHeaders = map[string]bool{"x-error": true, "x-topic": true, "x-partition": true, "x-offset": true}
+
+var ErrDeadLetterOffset = apperr.New(apperr.InvalidArgument, "the offset is out of the dead-letter topic")
+
+// DeadLetters replays letters of the dead-letter topic into the detections topic
+type DeadLetters struct {
+	topic           string
+	deadLetterTopic string
+	tracer          trace.Tracer
+	consumer        sarama.Consumer
+	producer        sarama.SyncProducer
+	logger          *zap.Logger
+}
+
+func NewDeadLetters(
+	logger *zap.Logger, consumer sarama.Consumer, producer sarama.SyncProducer, topic, deadLetterTopic string,
+) *DeadLetters {
+	return &DeadLetters{
+		topic:           topic,
+		deadLetterTopic: deadLetterTopic,
+		tracer:          otel.Tracer("msbroker"),
+		consumer:        consumer,
+		producer:        producer,
+		logger:          logger,
+	}
+}
+
+// Replay produces the selected letters to the topic they have failed in without the headers of the dead letter,
+// x-replayed-from tells the offset of the letter. Letters which fail again come back to the dead-letter topic
+func (d *DeadLetters) Replay(
+	ctx context.Context, replay entities.DeadLetterReplay,
+) (entities.DeadLetterReplayResult, error) {
+	ctx, span := d.tracer.Start(ctx, "msbroker.Replay")
+	defer span.End()
+
+	result := entities.DeadLetterReplayResult{Next: replay.From}
+
+	partition, err := d.consumer.ConsumePartition(d.deadLetterTopic, replay.Partition, replay.From)
+	if err != nil {
+		if errors.Is(err, sarama.ErrOffsetOutOfRange) {
+			return result, ErrDeadLetterOffset
+		}
+
+		return result, errors.Wrap(err, "can't consume the dead-letter topic")
+	}
+	defer partition.AsyncClose()
+
+	// the high water mark is the offset of the next letter, nothing after it is waited for
+	last := partition.HighWaterMarkOffset() - 1
+	if replay.To != nil && *replay.To < last {
+		last = *replay.To
+	}
+
+	sourceOffsets := make(map[string]bool, len(replay.SourceOffsets))
+	for _, offset := range replay.SourceOffsets {
+		sourceOffsets[strconv.FormatInt(offset, 10)] = true
+	}
+
+	idle := time.NewTimer(_replayIdle)
+	defer idle.Stop()
+
+	for result.Next <= last {
+		var msg *sarama.ConsumerMessage
+		select {
+		case msg = <-partition.Messages():
+		case <-idle.C:
+			return result, nil
+		case <-ctx.Done():
+			return result, ctx.Err()
+		}
+
+		if msg.Offset > last {
+			break
+		}
+		result.Next = msg.Offset + 1
+
+		if !idle.Stop() {
+			<-idle.C
+		}
+		idle.Reset(_replayIdle)
+
+		if header(msg, "x-topic") != d.topic ||
+			(len(sourceOffsets) > 0 && !sourceOffsets[header(msg, "x-offset")]) {
+			result.Skipped++
+			continue
+		}
+
+		if err := d.send(msg); err != nil {
+			return result, err
+		}
+		result.Replayed++
+	}
+
+	return result, nil
+}
+
+func (d *DeadLetters) send(letter *sarama.ConsumerMessage) error {
+	headers := make([]sarama.RecordHeader, 0, len(letter.Headers)+1)
+	for _, h := range letter.Headers {
+		if h != nil && !_deadLetterHeaders[string(h.Key)] {
+			headers = append(headers, *h)
+		}
+	}
+	headers = append(headers, sarama.RecordHeader{
+		Key: []byte("x-replayed-from"), Value: []byte(strconv.FormatInt(letter.Offset, 10)),
+	})
+
+	_, _, err := d.producer.SendMessage(&sarama.ProducerMessage{
+		Topic:   d.topic,
+		Key:     sarama.ByteEncoder(letter.Key),
+		Value:   sarama.ByteEncoder(letter.Value),
+		Headers: headers,
+	})
+	if err != nil {
+		return errors.Wrap(err, "can't replay the dead letter")
+	}
+
+	d.logger.Info(
+		"dead letter is replayed",
+		zap.Int32("partition", letter.Partition),
+		zap.Int64("offset", letter.Offset),
+		zap.String("sourceOffset", header(letter, "x-offset")),
+	)
+
+	return nil
+}
+
+func header(msg *sarama.ConsumerMessage, key string) string {
+	for _, h := range msg.Headers {
+		if h != nil && string(h.Key) == key {
+			return string(h.Value)
+		}
+	}
+
+	return ""
+}
+
+func (d *DeadLetters) Shutdown() error {
+	return d.consumer.Close()
+}
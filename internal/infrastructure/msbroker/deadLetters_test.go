@@ -0,0 +1,132 @@
+package msbroker_test
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/msbroker"
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"strconv"
+	"testing"
+)
+
+// fakeTopic serves letters of one partition starting from the requested offset
+type fakeTopic struct {
+	sarama.Consumer
+
+	letters []*sarama.ConsumerMessage
+}
+
+func (f *fakeTopic) ConsumePartition(_ string, _ int32, offset int64) (sarama.PartitionConsumer, error) {
+	if offset > int64(len(f.letters)) {
+		return nil, sarama.ErrOffsetOutOfRange
+	}
+
+	messages := make(chan *sarama.ConsumerMessage, len(f.letters))
+	for _, letter := range f.letters[offset:] {
+		messages <- letter
+	}
+
+	return fakePartition{messages: messages, highWaterMark: int64(len(f.letters))}, nil
+}
+
+type fakePartition struct {
+	sarama.PartitionConsumer
+
+	messages      chan *sarama.ConsumerMessage
+	highWaterMark int64
+}
+
+func (f fakePartition) Messages() <-chan *sarama.ConsumerMessage { return f.messages }
+
+func (f fakePartition) HighWaterMarkOffset() int64 { return f.highWaterMark }
+
+func (f fakePartition) AsyncClose() {}
+
+// letter is the dead letter of the message with the offset in the detections topic
+func letter(offset int64, sourceTopic string, sourceOffset int64) *sarama.ConsumerMessage {
+	return &sarama.ConsumerMessage{
+		Topic:  "detections-dlq",
+		Offset: offset,
+		Value:  []byte(strconv.FormatInt(sourceOffset, 10)),
+		Headers: []*sarama.RecordHeader{
+			{Key: []byte("traceparent"), Value: []byte("trace")},
+			{Key: []byte("x-error"), Value: []byte("the database is down")},
+			{Key: []byte("x-topic"), Value: []byte(sourceTopic)},
+			{Key: []byte("x-offset"), Value: []byte(strconv.FormatInt(sourceOffset, 10))},
+		},
+	}
+}
+
+func TestReplay(t *testing.T) {
+	topic := &fakeTopic{letters: []*sarama.ConsumerMessage{
+		letter(0, "detections", 10),
+		letter(1, "other", 11),
+		letter(2, "detections", 12),
+		letter(3, "detections", 13),
+	}}
+
+	to := int64(2)
+	testTable := []struct {
+		name        string
+		replay      entities.DeadLetterReplay
+		expResult   entities.DeadLetterReplayResult
+		expReplayed []string
+		expErr      error
+	}{
+		{
+			name:        "up to the newest letter",
+			replay:      entities.DeadLetterReplay{From: 0},
+			expResult:   entities.DeadLetterReplayResult{Replayed: 3, Skipped: 1, Next: 4},
+			expReplayed: []string{"10", "12", "13"},
+		},
+		{
+			name:        "offset range",
+			replay:      entities.DeadLetterReplay{From: 1, To: &to},
+			expResult:   entities.DeadLetterReplayResult{Replayed: 1, Skipped: 1, Next: 3},
+			expReplayed: []string{"12"},
+		},
+		{
+			name:        "source offsets",
+			replay:      entities.DeadLetterReplay{From: 0, SourceOffsets: []int64{13}},
+			expResult:   entities.DeadLetterReplayResult{Replayed: 1, Skipped: 3, Next: 4},
+			expReplayed: []string{"13"},
+		},
+		{
+			name:      "nothing to replay",
+			replay:    entities.DeadLetterReplay{From: 4},
+			expResult: entities.DeadLetterReplayResult{Next: 4},
+		},
+		{
+			name:      "offset out of the topic",
+			replay:    entities.DeadLetterReplay{From: 5},
+			expResult: entities.DeadLetterReplayResult{Next: 5},
+			expErr:    msbroker.ErrDeadLetterOffset,
+		},
+	}
+
+	for _, tCase := range testTable {
+		t.Run(tCase.name, func(t *testing.T) {
+			producer := &fakeProducer{}
+			deadLetters := msbroker.NewDeadLetters(zap.NewNop(), topic, producer, "detections", "detections-dlq")
+
+			result, err := deadLetters.Replay(context.Background(), tCase.replay)
+			require.ErrorIs(t, err, tCase.expErr)
+			require.Equal(t, tCase.expResult, result)
+
+			replayed := make([]string, 0)
+			for _, msg := range producer.sent {
+				require.Equal(t, "detections", msg.Topic)
+				// headers of the dead letter are dropped, so the failed replay gets its own
+				require.NotContains(t, msg.Headers, sarama.RecordHeader{
+					Key: []byte("x-error"), Value: []byte("the database is down"),
+				})
+				require.Contains(t, msg.Headers, sarama.RecordHeader{Key: []byte("traceparent"), Value: []byte("trace")})
+
+				replayed = append(replayed, string(msg.Value.(sarama.ByteEncoder)))
+			}
+			require.ElementsMatch(t, tCase.expReplayed, replayed)
+		})
+	}
+}
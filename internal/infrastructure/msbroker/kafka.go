@@ -15,21 +15,28 @@ import (
 	"time"
 )
 
+type Topics struct {
+	Audio       string
+	Incident    string
+	Alert       string
+	Maintenance string
+}
+
 type KafkaProducer struct {
-	topic    string
+	topics   Topics
 	tracer   trace.Tracer
 	producer sarama.SyncProducer
 	logger   *zap.Logger
 }
 
-func NewKafkaProducer(logger *zap.Logger, producer sarama.SyncProducer, topic string) *KafkaProducer {
+func NewKafkaProducer(logger *zap.Logger, producer sarama.SyncProducer, topics Topics) *KafkaProducer {
 	tracer := otel.Tracer("msbroker")
 
 	return &KafkaProducer{
 		tracer:   tracer,
 		producer: producer,
 		logger:   logger,
-		topic:    topic,
+		topics:   topics,
 	}
 }
 
@@ -42,13 +49,63 @@ func (k *KafkaProducer) Send(ctx context.Context, reqID uuid.UUID, message entit
 		Payload:   message,
 	}
 
-	msgBytes, err := json.Marshal(&msg)
+	return k.send(ctx, k.topics.Audio, reqID, &msg)
+}
+
+// SendIncidentTransition notifies downstream systems about the changed status of the incident
+func (k *KafkaProducer) SendIncidentTransition(
+	ctx context.Context, reqID uuid.UUID, incident entities.Incident, transition entities.IncidentTransition,
+) error {
+	ctx, span := k.tracer.Start(ctx, "msbroker.SendIncidentTransition")
+	defer span.End()
+
+	msg := brokerschemas.IncidentTransitionMessage{
+		RequestID:  reqID,
+		IncidentID: incident.ID.Hex(),
+		Transition: transition,
+		ShotCount:  incident.ShotCount,
+		Clients:    incident.Clients,
+	}
+
+	return k.send(ctx, k.topics.Incident, reqID, &msg)
+}
+
+// SendAlert passes the raised alert to the notification services
+func (k *KafkaProducer) SendAlert(ctx context.Context, reqID uuid.UUID, alert entities.Alert) error {
+	ctx, span := k.tracer.Start(ctx, "msbroker.SendAlert")
+	defer span.End()
+
+	msg := brokerschemas.AlertMessage{
+		RequestID: reqID,
+		Alert:     alert,
+	}
+
+	return k.send(ctx, k.topics.Alert, reqID, &msg)
+}
+
+// SendMaintenanceEvent notifies the maintenance team about the broken sensor
+func (k *KafkaProducer) SendMaintenanceEvent(
+	ctx context.Context, reqID uuid.UUID, event entities.MaintenanceEvent,
+) error {
+	ctx, span := k.tracer.Start(ctx, "msbroker.SendMaintenanceEvent")
+	defer span.End()
+
+	msg := brokerschemas.MaintenanceMessage{
+		RequestID: reqID,
+		Event:     event,
+	}
+
+	return k.send(ctx, k.topics.Maintenance, reqID, &msg)
+}
+
+func (k *KafkaProducer) send(ctx context.Context, topic string, reqID uuid.UUID, msg interface{}) error {
+	msgBytes, err := json.Marshal(msg)
 	if err != nil {
 		return errors.Wrap(err, "can't marshal msg")
 	}
 
 	producerMsg := &sarama.ProducerMessage{
-		Topic:     k.topic,
+		Topic:     topic,
 		Key:       sarama.StringEncoder(reqID.String()),
 		Value:     sarama.ByteEncoder(msgBytes),
 		Timestamp: time.Now(),
@@ -64,6 +121,7 @@ func (k *KafkaProducer) Send(ctx context.Context, reqID uuid.UUID, message entit
 	k.logger.Info(
 		"message successfully send to broker",
 		zap.String("requestID", reqID.String()),
+		zap.String("topic", topic),
 		zap.Int32("partition", partition),
 		zap.Int64("offset", offset),
 	)
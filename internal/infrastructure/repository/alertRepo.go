@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/tenant"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type AlertRepo struct {
+	collection *mongo.Collection
+	tracer     trace.Tracer
+}
+
+func (a AlertRepo) Create(ctx context.Context, alert *entities.Alert) (string, error) {
+	ctx, span := a.tracer.Start(ctx, "AlertRepo.Create")
+	defer span.End()
+
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	alert.ID = primitive.NewObjectID()
+	alert.TenantID = tenantID
+
+	if _, err := a.collection.InsertOne(ctx, alert); err != nil {
+		span.RecordError(err)
+		return "", errors.Wrap(err, "error during create alert")
+	}
+
+	return alert.ID.Hex(), nil
+}
+
+func (a AlertRepo) List(ctx context.Context, filter entities.AlertFilter) ([]entities.Alert, error) {
+	ctx, span := a.tracer.Start(ctx, "AlertRepo.List")
+	defer span.End()
+
+	query, err := scoped(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	if !filter.RuleID.IsZero() {
+		query["ruleID"] = filter.RuleID
+	}
+	if !filter.ClientID.IsZero() {
+		query["clientID"] = filter.ClientID
+	}
+	if !filter.ZoneID.IsZero() {
+		query["zoneIDs"] = filter.ZoneID
+	}
+	addTimeRange(query, "createdAt", filter.From, filter.To)
+
+	opts := options.Find().
+		SetSort(bson.M{"createdAt": -1}).
+		SetSkip(filter.Offset).
+		SetLimit(filter.Limit)
+
+	cursor, err := a.collection.Find(ctx, query, opts)
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during list alerts")
+	}
+
+	alerts := make([]entities.Alert, 0)
+	if err := cursor.All(ctx, &alerts); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during decode alerts")
+	}
+
+	return alerts, nil
+}
+
+func NewAlertRepo(database *mongo.Database) *AlertRepo {
+	return &AlertRepo{
+		collection: database.Collection(_alertsCollection),
+		tracer:     otel.Tracer("AlertRepo"),
+	}
+}
@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/tenant"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type AlertRuleRepo struct {
+	collection *mongo.Collection
+	tracer     trace.Tracer
+}
+
+func (a AlertRuleRepo) Create(ctx context.Context, rule *entities.AlertRule) (string, error) {
+	ctx, span := a.tracer.Start(ctx, "AlertRuleRepo.Create")
+	defer span.End()
+
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	rule.ID = primitive.NewObjectID()
+	rule.TenantID = tenantID
+
+	if _, err := a.collection.InsertOne(ctx, rule); err != nil {
+		span.RecordError(err)
+		return "", errors.Wrap(err, "error during create alert rule")
+	}
+
+	return rule.ID.Hex(), nil
+}
+
+func (a AlertRuleRepo) Get(ctx context.Context, id string) (entities.AlertRule, error) {
+	ctx, span := a.tracer.Start(ctx, "AlertRuleRepo.Get")
+	defer span.End()
+
+	castedID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return entities.AlertRule{}, invalidID(err, "alert rule")
+	}
+
+	filter, err := scoped(ctx, bson.M{"_id": castedID})
+	if err != nil {
+		return entities.AlertRule{}, err
+	}
+
+	var rule entities.AlertRule
+	if err := a.collection.FindOne(ctx, filter).Decode(&rule); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return entities.AlertRule{}, ErrAlertRuleNotFound
+		}
+
+		span.RecordError(err)
+		return entities.AlertRule{}, errors.Wrap(err, "error during get alert rule from db")
+	}
+
+	return rule, nil
+}
+
+// Update replaces fields of the rule, the rule is returned as it was before and after the update. Both are
+// taken by one write, so concurrent writes can't get between them
+func (a AlertRuleRepo) Update(
+	ctx context.Context, id string, rule *entities.AlertRule,
+) (entities.AlertRule, entities.AlertRule, error) {
+	ctx, span := a.tracer.Start(ctx, "AlertRuleRepo.Update")
+	defer span.End()
+
+	castedID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return entities.AlertRule{}, entities.AlertRule{}, invalidID(err, "alert rule")
+	}
+
+	filter, err := scoped(ctx, bson.M{"_id": castedID})
+	if err != nil {
+		return entities.AlertRule{}, entities.AlertRule{}, err
+	}
+
+	set := bson.M{
+		"name":       rule.Name,
+		"expression": rule.Expression,
+		"window":     rule.Window,
+		"timezone":   rule.Timezone,
+		"enabled":    rule.Enabled,
+	}
+	update := bson.M{"$set": set}
+
+	// the rule without client and zone applies to every client
+	unset := bson.M{}
+	if rule.ClientID.IsZero() {
+		unset["clientID"] = ""
+	} else {
+		set["clientID"] = rule.ClientID
+	}
+	if rule.ZoneID.IsZero() {
+		unset["zoneID"] = ""
+	} else {
+		set["zoneID"] = rule.ZoneID
+	}
+	if len(unset) != 0 {
+		update["$unset"] = unset
+	}
+
+	var before entities.AlertRule
+	if err := a.collection.FindOneAndUpdate(ctx, filter, update).Decode(&before); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return entities.AlertRule{}, entities.AlertRule{}, ErrAlertRuleNotFound
+		}
+
+		span.RecordError(err)
+		return entities.AlertRule{}, entities.AlertRule{}, errors.Wrap(err, "error during update alert rule")
+	}
+
+	after := before
+	after.Name, after.Expression, after.Window = rule.Name, rule.Expression, rule.Window
+	after.Timezone, after.Enabled = rule.Timezone, rule.Enabled
+	after.ClientID, after.ZoneID = rule.ClientID, rule.ZoneID
+
+	return before, after, nil
+}
+
+// Delete removes the rule and returns it as it was removed
+func (a AlertRuleRepo) Delete(ctx context.Context, id string) (entities.AlertRule, error) {
+	ctx, span := a.tracer.Start(ctx, "AlertRuleRepo.Delete")
+	defer span.End()
+
+	castedID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return entities.AlertRule{}, invalidID(err, "alert rule")
+	}
+
+	filter, err := scoped(ctx, bson.M{"_id": castedID})
+	if err != nil {
+		return entities.AlertRule{}, err
+	}
+
+	var deleted entities.AlertRule
+	if err := a.collection.FindOneAndDelete(ctx, filter).Decode(&deleted); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return entities.AlertRule{}, ErrAlertRuleNotFound
+		}
+
+		span.RecordError(err)
+		return entities.AlertRule{}, errors.Wrap(err, "error during delete alert rule")
+	}
+
+	return deleted, nil
+}
+
+func (a AlertRuleRepo) List(ctx context.Context) ([]entities.AlertRule, error) {
+	ctx, span := a.tracer.Start(ctx, "AlertRuleRepo.List")
+	defer span.End()
+
+	return a.find(ctx, bson.M{})
+}
+
+// FindForClient returns enabled rules attached to the client, to one of the zones
+// and rules attached to neither client nor zone
+func (a AlertRuleRepo) FindForClient(
+	ctx context.Context, clientID primitive.ObjectID, zoneIDs []primitive.ObjectID,
+) ([]entities.AlertRule, error) {
+	ctx, span := a.tracer.Start(ctx, "AlertRuleRepo.FindForClient")
+	defer span.End()
+
+	if zoneIDs == nil {
+		zoneIDs = []primitive.ObjectID{}
+	}
+
+	filter := bson.M{
+		"enabled": true,
+		"$or": bson.A{
+			bson.M{"clientID": clientID},
+			bson.M{"zoneID": bson.M{"$in": zoneIDs}},
+			bson.M{"clientID": bson.M{"$exists": false}, "zoneID": bson.M{"$exists": false}},
+		},
+	}
+
+	return a.find(ctx, filter)
+}
+
+func (a AlertRuleRepo) find(ctx context.Context, filter bson.M) ([]entities.AlertRule, error) {
+	filter, err := scoped(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := a.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "error during find alert rules")
+	}
+
+	alertRules := make([]entities.AlertRule, 0)
+	if err := cursor.All(ctx, &alertRules); err != nil {
+		return nil, errors.Wrap(err, "error during decode alert rules")
+	}
+
+	return alertRules, nil
+}
+
+func NewAlertRuleRepo(database *mongo.Database) *AlertRuleRepo {
+	return &AlertRuleRepo{
+		collection: database.Collection(_alertRulesCollection),
+		tracer:     otel.Tracer("AlertRuleRepo"),
+	}
+}
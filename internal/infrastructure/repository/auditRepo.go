@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// AuditRepo is append-only: entries are never deleted by the service, the pending entry is completed once
+type AuditRepo struct {
+	collection *mongo.Collection
+	tracer     trace.Tracer
+}
+
+// Create appends the entry, the tenant is taken from the entry since admin calls are recorded too
+func (a AuditRepo) Create(ctx context.Context, entry *entities.AuditEntry) (string, error) {
+	ctx, span := a.tracer.Start(ctx, "AuditRepo.Create")
+	defer span.End()
+
+	entry.ID = primitive.NewObjectID()
+
+	if _, err := a.collection.InsertOne(ctx, entry); err != nil {
+		span.RecordError(err)
+		return "", errors.Wrap(err, "error during create audit entry")
+	}
+
+	return entry.ID.Hex(), nil
+}
+
+// Complete saves the outcome of the pending entry, the target and the changes found during the call
+func (a AuditRepo) Complete(ctx context.Context, entry *entities.AuditEntry) error {
+	ctx, span := a.tracer.Start(ctx, "AuditRepo.Complete")
+	defer span.End()
+
+	update := bson.M{"$set": bson.M{
+		"target":  entry.Target,
+		"changes": entry.Changes,
+		"outcome": entry.Outcome,
+		"status":  entry.Status,
+	}}
+
+	res, err := a.collection.UpdateOne(ctx, bson.M{"_id": entry.ID, "outcome": entities.AuditPending}, update)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "error during complete audit entry")
+	}
+
+	if res.MatchedCount == 0 {
+		return ErrAuditEntryCompleted
+	}
+
+	return nil
+}
+
+func (a AuditRepo) List(ctx context.Context, filter entities.AuditFilter) ([]entities.AuditEntry, error) {
+	ctx, span := a.tracer.Start(ctx, "AuditRepo.List")
+	defer span.End()
+
+	query, err := scoped(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.Actor != "" {
+		query["actor"] = filter.Actor
+	}
+	if filter.TargetType != "" {
+		query["target.type"] = filter.TargetType
+	}
+	if filter.TargetID != "" {
+		query["target.id"] = filter.TargetID
+	}
+	if filter.RequestID != "" {
+		query["requestID"] = filter.RequestID
+	}
+	addTimeRange(query, "timestamp", filter.From, filter.To)
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "timestamp", Value: 1}, {Key: "_id", Value: 1}}).
+		SetSkip(filter.Offset).
+		SetLimit(filter.Limit)
+
+	cursor, err := a.collection.Find(ctx, query, opts)
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during list audit entries")
+	}
+
+	entries := make([]entities.AuditEntry, 0)
+	if err := cursor.All(ctx, &entries); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during decode audit entries")
+	}
+
+	return entries, nil
+}
+
+func NewAuditRepo(database *mongo.Database) *AuditRepo {
+	return &AuditRepo{
+		collection: database.Collection(_auditCollection),
+		tracer:     otel.Tracer("AuditRepo"),
+	}
+}
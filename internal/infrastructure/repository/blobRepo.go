@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/tenant"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+// BlobRepo keeps objects in a collection, one document per object. Audio chunks are far below
+// the document size limit
+type BlobRepo struct {
+	collection *mongo.Collection
+	tracer     trace.Tracer
+}
+
+// Put saves the object unless one with the key already exists: keys of the audio are made of its hash, so
+// the stored object is the same one and is kept as it was first written, with its own creation time and
+// encryption. The retention of the existing object is only extended
+func (b BlobRepo) Put(ctx context.Context, blob *entities.Blob) error {
+	ctx, span := b.tracer.Start(ctx, "BlobRepo.Put")
+	defer span.End()
+
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return err
+	}
+	blob.TenantID = tenantID
+
+	filter, err := scoped(ctx, bson.M{"_id": blob.Key})
+	if err != nil {
+		return err
+	}
+
+	raw, err := bson.Marshal(blob)
+	if err != nil {
+		return errors.Wrap(err, "error during marshal blob")
+	}
+
+	var fields bson.M
+	if err := bson.Unmarshal(raw, &fields); err != nil {
+		return errors.Wrap(err, "error during unmarshal blob")
+	}
+	delete(fields, "_id")
+
+	res, err := b.collection.UpdateOne(ctx, filter, bson.M{"$setOnInsert": fields}, options.Update().SetUpsert(true))
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "error during put blob")
+	}
+
+	if res.UpsertedCount == 1 {
+		return nil
+	}
+
+	// the object kept forever has no expiresAt and isn't matched
+	extend := bson.M{"$unset": bson.M{"expiresAt": ""}}
+	if blob.ExpiresAt != nil {
+		filter["expiresAt"] = bson.M{"$lt": *blob.ExpiresAt}
+		extend = bson.M{"$set": bson.M{"expiresAt": *blob.ExpiresAt}}
+	}
+
+	if _, err := b.collection.UpdateOne(ctx, filter, extend); err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "error during extend blob")
+	}
+
+	return nil
+}
+
+func (b BlobRepo) Get(ctx context.Context, key string) (entities.Blob, error) {
+	ctx, span := b.tracer.Start(ctx, "BlobRepo.Get")
+	defer span.End()
+
+	filter, err := scoped(ctx, bson.M{"_id": key})
+	if err != nil {
+		return entities.Blob{}, err
+	}
+
+	var blob entities.Blob
+	if err := b.collection.FindOne(ctx, filter).Decode(&blob); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return entities.Blob{}, ErrBlobNotFound
+		}
+
+		span.RecordError(err)
+		return entities.Blob{}, errors.Wrap(err, "error during get blob")
+	}
+
+	return blob, nil
+}
+
+func (b BlobRepo) Delete(ctx context.Context, key string) error {
+	ctx, span := b.tracer.Start(ctx, "BlobRepo.Delete")
+	defer span.End()
+
+	filter, err := scoped(ctx, bson.M{"_id": key})
+	if err != nil {
+		return err
+	}
+
+	res, err := b.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "error during delete blob")
+	}
+
+	if res.DeletedCount == 0 {
+		return ErrBlobNotFound
+	}
+
+	return nil
+}
+
+// Expired returns objects which expire before the moment ordered by the key, without the data.
+// The key of the last object of the previous page is passed to get the next one
+func (b BlobRepo) Expired(ctx context.Context, before time.Time, after string, limit int64) ([]entities.Blob, error) {
+	ctx, span := b.tracer.Start(ctx, "BlobRepo.Expired")
+	defer span.End()
+
+	filter, err := scoped(ctx, bson.M{"expiresAt": bson.M{"$lte": before}})
+	if err != nil {
+		return nil, err
+	}
+	if after != "" {
+		filter["_id"] = bson.M{"$gt": after}
+	}
+
+	opts := options.Find().
+		SetSort(bson.M{"_id": 1}).
+		SetLimit(limit).
+		SetProjection(bson.M{"data": 0})
+
+	cursor, err := b.collection.Find(ctx, filter, opts)
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during list expired blobs")
+	}
+
+	blobs := make([]entities.Blob, 0)
+	if err := cursor.All(ctx, &blobs); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during decode blobs")
+	}
+
+	return blobs, nil
+}
+
+// Stale returns encrypted objects with the data key wrapped by another key than the passed one, without
+// the data. The key of the last object of the previous page is passed to get the next one
+func (b BlobRepo) Stale(ctx context.Context, keyID string, after string, limit int64) ([]entities.Blob, error) {
+	ctx, span := b.tracer.Start(ctx, "BlobRepo.Stale")
+	defer span.End()
+
+	filter, err := scoped(ctx, bson.M{"encryption.keyID": bson.M{"$exists": true, "$ne": keyID}})
+	if err != nil {
+		return nil, err
+	}
+	if after != "" {
+		filter["_id"] = bson.M{"$gt": after}
+	}
+
+	opts := options.Find().
+		SetSort(bson.M{"_id": 1}).
+		SetLimit(limit).
+		SetProjection(bson.M{"data": 0})
+
+	cursor, err := b.collection.Find(ctx, filter, opts)
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during list stale blobs")
+	}
+
+	blobs := make([]entities.Blob, 0)
+	if err := cursor.All(ctx, &blobs); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during decode blobs")
+	}
+
+	return blobs, nil
+}
+
+// SetEncryption replaces the envelope of the object if nobody has replaced it since it was read
+func (b BlobRepo) SetEncryption(ctx context.Context, key string, from, to entities.BlobEncryption) error {
+	ctx, span := b.tracer.Start(ctx, "BlobRepo.SetEncryption")
+	defer span.End()
+
+	filter, err := scoped(ctx, bson.M{"_id": key, "encryption.wrappedKey": from.WrappedKey})
+	if err != nil {
+		return err
+	}
+
+	res, err := b.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"encryption": to}})
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "error during set encryption of blob")
+	}
+
+	if res.MatchedCount == 0 {
+		return ErrBlobNotFound
+	}
+
+	return nil
+}
+
+func NewBlobRepo(database *mongo.Database) *BlobRepo {
+	return &BlobRepo{
+		collection: database.Collection(_blobsCollection),
+		tracer:     otel.Tracer("BlobRepo"),
+	}
+}
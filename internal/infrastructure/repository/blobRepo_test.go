@@ -0,0 +1,65 @@
+//go:build integration
+// +build integration
+
+package repository_test
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository"
+	"github.com/stretchr/testify/suite"
+	"github.com/testcontainers/testcontainers-go"
+	"go.mongodb.org/mongo-driver/mongo"
+	"testing"
+	"time"
+)
+
+type BlobRepoSuite struct {
+	suite.Suite
+	repo      *repository.BlobRepo
+	dbClient  *mongo.Client
+	container testcontainers.Container
+}
+
+func TestBlobRepoSuite(t *testing.T) {
+	suite.Run(t, new(BlobRepoSuite))
+}
+
+func (s *BlobRepoSuite) SetupSuite() {
+	s.dbClient, s.container = startMongo(&s.Suite)
+	s.repo = repository.NewBlobRepo(s.dbClient.Database(_dbName))
+}
+
+func (s *BlobRepoSuite) TearDownSuite() {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	s.Require().NoError(s.dbClient.Disconnect(ctx))
+	s.Require().NoError(s.container.Terminate(ctx))
+}
+
+// TestPutKeepsStored checks that the object put again keeps its fields and only gets the longer retention
+func (s *BlobRepoSuite) TestPutKeepsStored() {
+	var (
+		first    = time.Now().UTC().Truncate(time.Millisecond)
+		expires  = first.Add(time.Hour)
+		extended = first.Add(2 * time.Hour)
+		original = entities.Blob{Key: "audio/kept", Data: []byte("first"), CreatedAt: first, ExpiresAt: &expires}
+	)
+	s.Require().NoError(s.repo.Put(tenantCtx, &original))
+
+	for _, expiresAt := range []time.Time{extended, expires} {
+		expiresAt := expiresAt
+		again := entities.Blob{
+			Key: original.Key, Data: []byte("second"), CreatedAt: first.Add(time.Minute), ExpiresAt: &expiresAt,
+		}
+		s.Require().NoError(s.repo.Put(tenantCtx, &again))
+	}
+
+	got, err := s.repo.Get(tenantCtx, original.Key)
+	s.Require().NoError(err)
+	s.Equal([]byte("first"), got.Data)
+	s.Equal(first, got.CreatedAt.UTC())
+	s.Require().NotNil(got.ExpiresAt)
+	s.Equal(extended, got.ExpiresAt.UTC(), "the retention must not be shortened")
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/tenant"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+// ChainRepo keeps hash chain records of uploaded audio, records are never updated
+type ChainRepo struct {
+	collection *mongo.Collection
+	tracer     trace.Tracer
+}
+
+// Create saves the record, the unique index on the sequence of the client fails it with ErrChainSequenceTaken
+// if another record has taken the sequence
+func (c ChainRepo) Create(ctx context.Context, record *entities.ChainRecord) (string, error) {
+	ctx, span := c.tracer.Start(ctx, "ChainRepo.Create")
+	defer span.End()
+
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	record.ID = primitive.NewObjectID()
+	record.TenantID = tenantID
+
+	if _, err := c.collection.InsertOne(ctx, record); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return "", ErrChainSequenceTaken
+		}
+
+		span.RecordError(err)
+		return "", errors.Wrap(err, "error during create chain record")
+	}
+
+	return record.ID.Hex(), nil
+}
+
+// FindUpload returns the latest record of the upload of the client with the timestamp and the payload
+func (c ChainRepo) FindUpload(
+	ctx context.Context, clientID primitive.ObjectID, rawTimestamp time.Time, payloadHash string,
+) (entities.ChainRecord, error) {
+	ctx, span := c.tracer.Start(ctx, "ChainRepo.FindUpload")
+	defer span.End()
+
+	query, err := scoped(ctx, bson.M{
+		"clientID":     clientID,
+		"payloadHash":  payloadHash,
+		"rawTimestamp": rawTimestamp,
+	})
+	if err != nil {
+		return entities.ChainRecord{}, err
+	}
+
+	var record entities.ChainRecord
+
+	opts := options.FindOne().SetSort(bson.M{"sequence": -1})
+	if err := c.collection.FindOne(ctx, query, opts).Decode(&record); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return entities.ChainRecord{}, ErrChainRecordNotFound
+		}
+
+		span.RecordError(err)
+		return entities.ChainRecord{}, errors.Wrap(err, "error during find chain record")
+	}
+
+	return record, nil
+}
+
+// Bounds returns the first and the last sequence of records of the client created in the time range,
+// zeros mean there are no such records
+func (c ChainRepo) Bounds(
+	ctx context.Context, clientID primitive.ObjectID, from, to time.Time,
+) (int64, int64, error) {
+	ctx, span := c.tracer.Start(ctx, "ChainRepo.Bounds")
+	defer span.End()
+
+	query, err := scoped(ctx, bson.M{"clientID": clientID})
+	if err != nil {
+		return 0, 0, err
+	}
+	addTimeRange(query, "createdAt", from, to)
+
+	var bounds [2]int64
+	for i, order := range []int{1, -1} {
+		var record entities.ChainRecord
+
+		opts := options.FindOne().SetSort(bson.M{"sequence": order}).SetProjection(bson.M{"sequence": 1})
+		if err := c.collection.FindOne(ctx, query, opts).Decode(&record); err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return 0, 0, nil
+			}
+
+			span.RecordError(err)
+			return 0, 0, errors.Wrap(err, "error during get chain bounds")
+		}
+
+		bounds[i] = record.Sequence
+	}
+
+	return bounds[0], bounds[1], nil
+}
+
+// Range returns records of the client with sequences in [from, to] ordered by sequence
+func (c ChainRepo) Range(
+	ctx context.Context, clientID primitive.ObjectID, from, to int64,
+) ([]entities.ChainRecord, error) {
+	ctx, span := c.tracer.Start(ctx, "ChainRepo.Range")
+	defer span.End()
+
+	query, err := scoped(ctx, bson.M{
+		"clientID": clientID,
+		"sequence": bson.M{"$gte": from, "$lte": to},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := c.collection.Find(ctx, query, options.Find().SetSort(bson.M{"sequence": 1}))
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during list chain records")
+	}
+
+	records := make([]entities.ChainRecord, 0)
+	if err := cursor.All(ctx, &records); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during decode chain records")
+	}
+
+	return records, nil
+}
+
+// Between returns records of the client with the (corrected) timestamp in the range ordered by sequence
+func (c ChainRepo) Between(
+	ctx context.Context, clientID primitive.ObjectID, from, to time.Time,
+) ([]entities.ChainRecord, error) {
+	ctx, span := c.tracer.Start(ctx, "ChainRepo.Between")
+	defer span.End()
+
+	query, err := scoped(ctx, bson.M{"clientID": clientID})
+	if err != nil {
+		return nil, err
+	}
+	addTimeRange(query, "timestamp", from, to)
+
+	cursor, err := c.collection.Find(ctx, query, options.Find().SetSort(bson.M{"sequence": 1}))
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during list chain records")
+	}
+
+	records := make([]entities.ChainRecord, 0)
+	if err := cursor.All(ctx, &records); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during decode chain records")
+	}
+
+	return records, nil
+}
+
+func NewChainRepo(database *mongo.Database) *ChainRepo {
+	return &ChainRepo{
+		collection: database.Collection(_chainCollection),
+		tracer:     otel.Tracer("ChainRepo"),
+	}
+}
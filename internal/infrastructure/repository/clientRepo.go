@@ -3,12 +3,15 @@ package repository
 import (
 	"context"
 	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/tenant"
 	"github.com/pkg/errors"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
+	"time"
 )
 
 type ClientRepo struct {
@@ -21,9 +24,16 @@ func (c ClientRepo) Create(ctx context.Context, client *entities.Client) (string
 	ctx, span := c.tracer.Start(ctx, "ClientRepo.Create")
 	defer span.End()
 
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return "", err
+	}
+
 	client.ID = primitive.NewObjectID()
+	client.TenantID = tenantID
+	client.Version = 1
 
-	_, err := c.collection.InsertOne(ctx, client)
+	_, err = c.collection.InsertOne(ctx, client)
 	if err != nil {
 		return "", errors.Wrap(err, "error during create client")
 	}
@@ -31,17 +41,75 @@ func (c ClientRepo) Create(ctx context.Context, client *entities.Client) (string
 	return client.ID.Hex(), nil
 }
 
+// CreateMany saves clients with one ordered bulk write and returns their IDs in the same order. The write
+// stops at the first failed client, the IDs of clients created before it are returned with the error
+func (c ClientRepo) CreateMany(ctx context.Context, clients []*entities.Client) ([]string, error) {
+	ctx, span := c.tracer.Start(ctx, "ClientRepo.CreateMany")
+	defer span.End()
+
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(clients))
+	models := make([]mongo.WriteModel, 0, len(clients))
+	for _, client := range clients {
+		client.ID = primitive.NewObjectID()
+		client.TenantID = tenantID
+		client.Version = 1
+
+		ids = append(ids, client.ID.Hex())
+		models = append(models, mongo.NewInsertOneModel().SetDocument(client))
+	}
+
+	if len(models) == 0 {
+		return ids, nil
+	}
+
+	if _, err := c.collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(true)); err != nil {
+		span.RecordError(err)
+		return ids[:insertedBefore(err, len(ids))], errors.Wrap(err, "error during create clients")
+	}
+
+	return ids, nil
+}
+
+// insertedBefore returns the number of documents the failed ordered bulk write of the size has inserted,
+// it's 0 when the error doesn't tell it
+func insertedBefore(err error, size int) int {
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) {
+		return 0
+	}
+
+	// the write concern error alone means all documents are written but not confirmed
+	if len(bulkErr.WriteErrors) == 0 {
+		return size
+	}
+
+	inserted := size
+	for _, writeErr := range bulkErr.WriteErrors {
+		if writeErr.Index < inserted {
+			inserted = writeErr.Index
+		}
+	}
+
+	return inserted
+}
+
 func (c ClientRepo) Get(ctx context.Context, id string) (entities.Client, error) {
 	ctx, span := c.tracer.Start(ctx, "ClientRepo.Get")
 	defer span.End()
 
 	castedID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return entities.Client{}, errors.Wrap(err, "invalid client id")
+		return entities.Client{}, invalidID(err, "client")
 	}
 
-	filter := bson.M{
-		"_id": castedID,
+	filter, err := scoped(ctx, live(bson.M{"_id": castedID}))
+	if err != nil {
+		return entities.Client{}, err
 	}
 
 	var client entities.Client
@@ -57,17 +125,17 @@ func (c ClientRepo) Get(ctx context.Context, id string) (entities.Client, error)
 	return client, nil
 }
 
-func (c ClientRepo) Update(ctx context.Context, id string, client *entities.Client) error {
+// Update changes the client if it still has the version, the client is returned as it was before and after
+// the update
+func (c ClientRepo) Update(
+	ctx context.Context, id string, version int64, client *entities.Client,
+) (entities.Client, entities.Client, error) {
 	ctx, span := c.tracer.Start(ctx, "ClientRepo.Update")
 	defer span.End()
 
 	castedID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return errors.Wrap(err, "invalid client id")
-	}
-
-	filter := bson.M{
-		"_id": castedID,
+		return entities.Client{}, entities.Client{}, invalidID(err, "client")
 	}
 
 	update := bson.M{
@@ -76,51 +144,557 @@ func (c ClientRepo) Update(ctx context.Context, id string, client *entities.Clie
 			"fullName":     client.FullName,
 			"latitude":     client.Latitude,
 			"longitude":    client.Longitude,
+			"zoneIDs":      client.ZoneIDs,
 		},
+		"$inc": bson.M{"version": 1},
+	}
+
+	before, err := c.change(ctx, castedID, version, update)
+	if err != nil {
+		span.RecordError(err)
+		return entities.Client{}, entities.Client{}, err
+	}
+
+	after := before
+	after.LocationName, after.FullName = client.LocationName, client.FullName
+	after.Latitude, after.Longitude = client.Latitude, client.Longitude
+	after.ZoneIDs = client.ZoneIDs
+	after.Version++
+
+	return before, after, nil
+}
+
+func (c ClientRepo) List(ctx context.Context) ([]entities.Client, error) {
+	ctx, span := c.tracer.Start(ctx, "ClientRepo.List")
+	defer span.End()
+
+	filter, err := scoped(ctx, live(bson.M{}))
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := c.collection.Find(ctx, filter)
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during list clients")
+	}
+
+	clients := make([]entities.Client, 0)
+	if err := cursor.All(ctx, &clients); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during decode clients")
+	}
+
+	return clients, nil
+}
+
+// Page returns live clients of the tenant in the order of their IDs
+func (c ClientRepo) Page(ctx context.Context, filter entities.ClientFilter) ([]entities.Client, error) {
+	ctx, span := c.tracer.Start(ctx, "ClientRepo.Page")
+	defer span.End()
+
+	query, err := scoped(ctx, live(bson.M{}))
+	if err != nil {
+		return nil, err
+	}
+	if !filter.After.IsZero() {
+		query["_id"] = bson.M{"$gt": filter.After}
+	}
+
+	cursor, err := c.collection.Find(ctx, query, options.Find().SetSort(bson.M{"_id": 1}).SetLimit(filter.Limit))
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during page clients")
+	}
+
+	clients := make([]entities.Client, 0)
+	if err := cursor.All(ctx, &clients); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during decode clients")
+	}
+
+	return clients, nil
+}
+
+// Count returns the number of clients of the tenant
+func (c ClientRepo) Count(ctx context.Context) (int64, error) {
+	ctx, span := c.tracer.Start(ctx, "ClientRepo.Count")
+	defer span.End()
+
+	filter, err := scoped(ctx, live(bson.M{}))
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := c.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		span.RecordError(err)
+		return 0, errors.Wrap(err, "error during count clients")
+	}
+
+	return count, nil
+}
+
+// SetZones replaces zones the client is assigned to
+func (c ClientRepo) SetZones(ctx context.Context, id primitive.ObjectID, zoneIDs []primitive.ObjectID) error {
+	ctx, span := c.tracer.Start(ctx, "ClientRepo.SetZones")
+	defer span.End()
+
+	if _, err := c.setFields(ctx, id, bson.M{"zoneIDs": zoneIDs}); err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "error during set zones of client")
+	}
+
+	return nil
+}
+
+// SetHealth saves the latest known state of the client
+func (c ClientRepo) SetHealth(ctx context.Context, id primitive.ObjectID, health entities.ClientHealth) error {
+	ctx, span := c.tracer.Start(ctx, "ClientRepo.SetHealth")
+	defer span.End()
+
+	res, err := c.setFields(ctx, id, bson.M{"health": health})
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "error during set health of client")
+	}
+
+	if res.MatchedCount == 0 {
+		return ErrClientNotFound
+	}
+
+	return nil
+}
+
+// SetHealthStatus changes the status of the client if it hasn't been seen since the time the status was
+// computed for, it reports whether the status is changed
+func (c ClientRepo) SetHealthStatus(
+	ctx context.Context, id primitive.ObjectID, lastSeen time.Time, status entities.HealthStatus,
+) (bool, error) {
+	ctx, span := c.tracer.Start(ctx, "ClientRepo.SetHealthStatus")
+	defer span.End()
+
+	// clients created before the health was introduced have none
+	expected := bson.M{"_id": id, "health.lastSeen": lastSeen}
+	if lastSeen.IsZero() {
+		expected["health.lastSeen"] = bson.M{"$in": bson.A{lastSeen, nil}}
+	}
+
+	filter, err := scoped(ctx, live(expected))
+	if err != nil {
+		return false, err
+	}
+
+	res, err := c.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"health.status": status}})
+	if err != nil {
+		span.RecordError(err)
+		return false, errors.Wrap(err, "error during set health status of client")
+	}
+
+	return res.MatchedCount > 0, nil
+}
+
+// SetAppliedConfig saves the version of the config the client reported as applied
+func (c ClientRepo) SetAppliedConfig(ctx context.Context, id primitive.ObjectID, version int64) error {
+	ctx, span := c.tracer.Start(ctx, "ClientRepo.SetAppliedConfig")
+	defer span.End()
+
+	res, err := c.setFields(ctx, id, bson.M{"appliedConfigVersion": version})
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "error during set applied config of client")
+	}
+
+	if res.MatchedCount == 0 {
+		return ErrClientNotFound
+	}
+
+	return nil
+}
+
+// SetClock saves the clock estimate of the client
+func (c ClientRepo) SetClock(ctx context.Context, id primitive.ObjectID, clock entities.ClockState) error {
+	ctx, span := c.tracer.Start(ctx, "ClientRepo.SetClock")
+	defer span.End()
+
+	res, err := c.setFields(ctx, id, bson.M{"clock": clock})
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "error during set clock of client")
+	}
+
+	if res.MatchedCount == 0 {
+		return ErrClientNotFound
+	}
+
+	return nil
+}
+
+// AddKey appends the key to keys of the client unless it's there already. Keys which would be active after
+// expireBy expire at it. It's one update, so concurrent rotations and revocations are not lost
+func (c ClientRepo) AddKey(
+	ctx context.Context, id primitive.ObjectID, key entities.DeviceKey, expireBy time.Time,
+) error {
+	ctx, span := c.tracer.Start(ctx, "ClientRepo.AddKey")
+	defer span.End()
+
+	filter, err := scoped(ctx, live(bson.M{"_id": id, "keys.id": bson.M{"$ne": key.ID}}))
+	if err != nil {
+		return err
+	}
+
+	outlives := bson.M{"$or": bson.A{
+		bson.M{"$in": bson.A{bson.M{"$type": "$$key.expiresAt"}, bson.A{"missing", "null"}}},
+		bson.M{"$gt": bson.A{"$$key.expiresAt", expireBy}},
+	}}
+	expired := bson.M{"$mergeObjects": bson.A{"$$key", bson.M{"expiresAt": expireBy}}}
+
+	update := bson.A{bson.M{"$set": bson.M{"keys": bson.M{"$concatArrays": bson.A{
+		bson.M{"$map": bson.M{
+			"input": bson.M{"$ifNull": bson.A{"$keys", bson.A{}}},
+			"as":    "key",
+			"in":    bson.M{"$cond": bson.A{outlives, expired, "$$key"}},
+		}},
+		bson.A{bson.M{"$literal": key}},
+	}}}}}
+
+	res, err := c.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "error during add key of client")
+	}
+
+	if res.MatchedCount == 0 {
+		return c.notMatched(ctx, id, ErrDeviceKeyExists)
+	}
+
+	return nil
+}
+
+// RevokeKey expires the key of the client at the time unless it has expired before, the key is returned as it
+// was before the revocation
+func (c ClientRepo) RevokeKey(
+	ctx context.Context, id primitive.ObjectID, keyID string, at time.Time,
+) (entities.DeviceKey, error) {
+	ctx, span := c.tracer.Start(ctx, "ClientRepo.RevokeKey")
+	defer span.End()
+
+	filter, err := scoped(ctx, live(bson.M{"_id": id, "keys.id": keyID}))
+	if err != nil {
+		return entities.DeviceKey{}, err
 	}
 
-	res := c.collection.FindOneAndUpdate(ctx, filter, update)
+	opts := options.FindOneAndUpdate().
+		SetProjection(bson.M{"keys": 1}).
+		SetArrayFilters(options.ArrayFilters{Filters: bson.A{bson.M{
+			"key.id": keyID,
+			"$or":    bson.A{bson.M{"key.expiresAt": nil}, bson.M{"key.expiresAt": bson.M{"$gt": at}}},
+		}}})
+
+	var before struct {
+		Keys []entities.DeviceKey `bson:"keys"`
+	}
 
-	if res.Err() != nil {
-		if errors.Is(res.Err(), mongo.ErrNoDocuments) {
-			return ErrClientNotFound
+	res := c.collection.FindOneAndUpdate(ctx, filter, bson.M{"$set": bson.M{"keys.$[key].expiresAt": at}}, opts)
+	if err := res.Decode(&before); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return entities.DeviceKey{}, c.notMatched(ctx, id, ErrDeviceKeyNotFound)
 		}
 
 		span.RecordError(err)
-		return errors.Wrap(err, "error during update information")
+		return entities.DeviceKey{}, errors.Wrap(err, "error during revoke key of client")
+	}
+
+	for _, key := range before.Keys {
+		if key.ID == keyID {
+			return key, nil
+		}
+	}
+
+	return entities.DeviceKey{}, ErrDeviceKeyNotFound
+}
+
+// AdvanceChain moves the head of the hash chain if nobody has moved it since it was read
+func (c ClientRepo) AdvanceChain(ctx context.Context, id primitive.ObjectID, from, to entities.ChainHead) error {
+	ctx, span := c.tracer.Start(ctx, "ClientRepo.AdvanceChain")
+	defer span.End()
+
+	// clients created before the chain was introduced have no head
+	expected := bson.M{"chain.sequence": from.Sequence}
+	if from.Sequence == 0 {
+		expected = bson.M{"chain.sequence": bson.M{"$in": bson.A{0, nil}}}
+	}
+	expected["_id"] = id
+
+	filter, err := scoped(ctx, live(expected))
+	if err != nil {
+		return err
+	}
+
+	res, err := c.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"chain": to}})
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "error during advance chain of client")
+	}
+
+	if res.MatchedCount == 0 {
+		return ErrChainHeadMoved
 	}
 
 	return nil
 }
 
-// Delete remove the user from database
-func (c ClientRepo) Delete(ctx context.Context, id string) error {
+// Patch changes only the fields of the patch if the client still has the version, the client is returned as
+// it was before and after the patch
+func (c ClientRepo) Patch(
+	ctx context.Context, id string, version int64, patch entities.ClientPatch,
+) (entities.Client, entities.Client, error) {
+	ctx, span := c.tracer.Start(ctx, "ClientRepo.Patch")
+	defer span.End()
+
+	castedID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return entities.Client{}, entities.Client{}, invalidID(err, "client")
+	}
+
+	set := bson.M{}
+	if patch.LocationName != nil {
+		set["locationName"] = *patch.LocationName
+	}
+	if patch.FullName != nil {
+		set["fullName"] = *patch.FullName
+	}
+	if patch.Latitude != nil {
+		set["latitude"] = *patch.Latitude
+	}
+	if patch.Longitude != nil {
+		set["longitude"] = *patch.Longitude
+	}
+	if patch.ZoneIDs != nil {
+		set["zoneIDs"] = patch.ZoneIDs
+	}
+
+	update := bson.M{"$inc": bson.M{"version": 1}}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+
+	before, err := c.change(ctx, castedID, version, update)
+	if err != nil {
+		span.RecordError(err)
+		return entities.Client{}, entities.Client{}, err
+	}
+
+	after := patch.Apply(before)
+	after.Version++
+
+	return before, after, nil
+}
+
+// Delete marks the client deleted if it still has the version, it can be restored until it's purged. The
+// client is returned as it was before and after the deletion
+func (c ClientRepo) Delete(ctx context.Context, id string, version int64) (entities.Client, entities.Client, error) {
 	ctx, span := c.tracer.Start(ctx, "ClientRepo.Delete")
 	defer span.End()
 
 	castedID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return errors.Wrap(err, "invalid client id")
+		return entities.Client{}, entities.Client{}, invalidID(err, "client")
+	}
+
+	// the database keeps milliseconds
+	deletedAt := time.Now().UTC().Truncate(time.Millisecond)
+
+	before, err := c.change(ctx, castedID, version, bson.M{
+		"$set": bson.M{"deletedAt": deletedAt},
+		"$inc": bson.M{"version": 1},
+	})
+	if err != nil {
+		span.RecordError(err)
+		return entities.Client{}, entities.Client{}, err
+	}
+
+	after := before
+	after.DeletedAt = &deletedAt
+	after.Version++
+
+	return before, after, nil
+}
+
+// change applies the update to the live client of the version and returns the client as it was before the
+// update, both are taken by one write, so concurrent writes of other fields can't get between them
+func (c ClientRepo) change(
+	ctx context.Context, id primitive.ObjectID, version int64, update bson.M,
+) (entities.Client, error) {
+	filter, err := scoped(ctx, live(versioned(bson.M{"_id": id}, version)))
+	if err != nil {
+		return entities.Client{}, err
+	}
+
+	var before entities.Client
+	err = c.collection.FindOneAndUpdate(
+		ctx, filter, update, options.FindOneAndUpdate().SetReturnDocument(options.Before),
+	).Decode(&before)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return entities.Client{}, c.mismatch(ctx, id)
+		}
+
+		return entities.Client{}, errors.Wrap(err, "error during change client")
+	}
+
+	return before, nil
+}
+
+// mismatch tells why the conditional write has not matched the client
+func (c ClientRepo) mismatch(ctx context.Context, id primitive.ObjectID) error {
+	return c.notMatched(ctx, id, ErrClientVersionMismatch)
+}
+
+// notMatched returns ErrClientNotFound if there is no such client and the reason otherwise
+func (c ClientRepo) notMatched(ctx context.Context, id primitive.ObjectID, reason error) error {
+	filter, err := scoped(ctx, live(bson.M{"_id": id}))
+	if err != nil {
+		return err
+	}
+
+	count, err := c.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return errors.Wrap(err, "error during count clients")
 	}
 
-	filter := bson.M{
-		"_id": castedID,
+	if count == 0 {
+		return ErrClientNotFound
 	}
 
-	res := c.collection.FindOneAndDelete(ctx, filter)
+	return reason
+}
+
+// GetWithDeleted returns the client even if it's deleted but not purged yet
+func (c ClientRepo) GetWithDeleted(ctx context.Context, id string) (entities.Client, error) {
+	ctx, span := c.tracer.Start(ctx, "ClientRepo.GetWithDeleted")
+	defer span.End()
+
+	castedID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return entities.Client{}, invalidID(err, "client")
+	}
 
-	if res.Err() != nil {
+	filter, err := scoped(ctx, bson.M{"_id": castedID})
+	if err != nil {
+		return entities.Client{}, err
+	}
+
+	var client entities.Client
+	if err := c.collection.FindOne(ctx, filter).Decode(&client); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return ErrClientNotFound
+			return entities.Client{}, ErrClientNotFound
 		}
 
 		span.RecordError(err)
-		return errors.Wrap(err, "error during delete client")
+		return entities.Client{}, errors.Wrap(err, "error during get client from db")
+	}
+
+	return client, nil
+}
+
+// Restore brings the deleted client back
+func (c ClientRepo) Restore(ctx context.Context, id primitive.ObjectID) error {
+	ctx, span := c.tracer.Start(ctx, "ClientRepo.Restore")
+	defer span.End()
+
+	filter, err := scoped(ctx, bson.M{"_id": id, "deletedAt": bson.M{"$exists": true}})
+	if err != nil {
+		return err
+	}
+
+	res, err := c.collection.UpdateOne(ctx, filter, bson.M{
+		"$unset": bson.M{"deletedAt": ""},
+		"$inc":   bson.M{"version": 1},
+	})
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "error during restore client")
+	}
+
+	if res.MatchedCount == 0 {
+		return ErrClientNotDeleted
 	}
 
 	return nil
 }
 
+// Purge removes clients deleted before the time for good and returns their IDs
+func (c ClientRepo) Purge(ctx context.Context, before time.Time) ([]primitive.ObjectID, error) {
+	ctx, span := c.tracer.Start(ctx, "ClientRepo.Purge")
+	defer span.End()
+
+	filter, err := scoped(ctx, bson.M{"deletedAt": bson.M{"$lt": before}})
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := c.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during find deleted clients")
+	}
+
+	var purged []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &purged); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during decode deleted clients")
+	}
+
+	// the client may have been restored since it was found
+	ids := make([]primitive.ObjectID, 0, len(purged))
+	for _, client := range purged {
+		filter["_id"] = client.ID
+
+		res, err := c.collection.DeleteOne(ctx, filter)
+		if err != nil {
+			span.RecordError(err)
+			return nil, errors.Wrap(err, "error during purge client")
+		}
+		if res.DeletedCount > 0 {
+			ids = append(ids, client.ID)
+		}
+	}
+
+	return ids, nil
+}
+
+// versioned matches the client of the version, clients created before versions have none
+func versioned(filter bson.M, version int64) bson.M {
+	switch version {
+	case entities.AnyVersion:
+	case 0:
+		filter["version"] = bson.M{"$in": bson.A{0, nil}}
+	default:
+		filter["version"] = version
+	}
+
+	return filter
+}
+
+// live excludes deleted clients
+func live(filter bson.M) bson.M {
+	filter["deletedAt"] = bson.M{"$exists": false}
+	return filter
+}
+
+// setFields updates fields of the client of the tenant
+func (c ClientRepo) setFields(ctx context.Context, id primitive.ObjectID, fields bson.M) (*mongo.UpdateResult, error) {
+	filter, err := scoped(ctx, live(bson.M{"_id": id}))
+	if err != nil {
+		return nil, err
+	}
+
+	return c.collection.UpdateOne(ctx, filter, bson.M{"$set": fields})
+}
+
 func NewClientRepo(database *mongo.Database) *ClientRepo {
 	tracer := otel.Tracer("ClientRepo")
 
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
 	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository"
+	"github.com/Imm0bilize/gunshot-api-service/internal/tenant"
 	"github.com/docker/go-connections/nat"
 	"github.com/stretchr/testify/suite"
 	"github.com/testcontainers/testcontainers-go"
@@ -34,23 +35,24 @@ type ClientRepoSuite struct {
 }
 
 var tempoClient = &entities.Client{
-	ID:                  primitive.ObjectID{},
-	LocationName:        "test",
-	FullName:            "test test",
-	Latitude:            52.124,
-	Longitude:           12.235,
-	NotificationMethods: nil,
+	ID:           primitive.ObjectID{},
+	LocationName: "test",
+	FullName:     "test test",
+	Latitude:     52.124,
+	Longitude:    12.235,
 }
 
+var tenantCtx = tenant.WithID(context.Background(), primitive.NewObjectID())
+
 func TestClientRepoSuite(t *testing.T) {
 	suite.Run(t, new(ClientRepoSuite))
 }
 
-func (c *ClientRepoSuite) SetupSuite() {
+func startMongo(s *suite.Suite) (*mongo.Client, testcontainers.Container) {
 	ctx := context.Background()
 
 	port, err := nat.NewPort("", "27017")
-	c.Require().NoError(err)
+	s.Require().NoError(err)
 
 	req := testcontainers.ContainerRequest{
 		Image:        _mongoImageName,
@@ -65,19 +67,22 @@ func (c *ClientRepoSuite) SetupSuite() {
 			Started:          true,
 		},
 	)
-	c.Require().NoError(err)
+	s.Require().NoError(err)
 
-	c.container = mongoC
-	endpoint, err := c.container.Endpoint(ctx, "")
+	endpoint, err := mongoC.Endpoint(ctx, "")
 	if err != nil {
-		c.T().Fatal(err)
+		s.T().Fatal(err)
 	}
 
 	mongoClient, err := mongo.Connect(ctx, options.Client().ApplyURI(fmt.Sprintf("mongodb://%s", endpoint)))
-	c.Require().NoError(err)
+	s.Require().NoError(err)
+
+	return mongoClient, mongoC
+}
 
-	c.dbClient = mongoClient
-	c.repo = repository.NewClientRepo(mongoClient.Database(_dbName))
+func (c *ClientRepoSuite) SetupSuite() {
+	c.dbClient, c.container = startMongo(&c.Suite)
+	c.repo = repository.NewClientRepo(c.dbClient.Database(_dbName))
 }
 
 func (c *ClientRepoSuite) TearDownSuite() {
@@ -101,7 +106,7 @@ func (c *ClientRepoSuite) TestCreate() {
 
 	for _, testCase := range testTable {
 		c.Run(testCase.name, func() {
-			id, err := c.repo.Create(context.Background(), testCase.client)
+			id, err := c.repo.Create(tenantCtx, testCase.client)
 			c.Require().NoError(err)
 
 			objectID, err := primitive.ObjectIDFromHex(id)
@@ -144,7 +149,7 @@ func (c *ClientRepoSuite) TestGet() {
 			name:   "existing id",
 			expErr: nil,
 			createClientFn: func() (string, error) {
-				return c.repo.Create(context.Background(), tempoClient)
+				return c.repo.Create(tenantCtx, tempoClient)
 			},
 		},
 	}
@@ -156,12 +161,146 @@ func (c *ClientRepoSuite) TestGet() {
 				c.Require().NoError(err)
 				testCase.id = id
 			}
-			_, err := c.repo.Get(context.Background(), testCase.id)
+			_, err := c.repo.Get(tenantCtx, testCase.id)
 			c.ErrorIs(err, testCase.expErr)
 		})
 	}
 }
 
+func (c *ClientRepoSuite) TestSetHealthStatus() {
+	id, err := c.repo.Create(tenantCtx, &entities.Client{FullName: "test", LocationName: "test"})
+	c.Require().NoError(err)
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	c.Require().NoError(err)
+
+	// the client has never been seen
+	changed, err := c.repo.SetHealthStatus(tenantCtx, objectID, time.Time{}, entities.HealthOffline)
+	c.Require().NoError(err)
+	c.True(changed)
+
+	seen := time.Now().UTC().Truncate(time.Millisecond)
+	c.Require().NoError(c.repo.SetHealth(
+		tenantCtx, objectID, entities.ClientHealth{Status: entities.HealthOnline, LastSeen: seen},
+	))
+
+	// the status computed before the heartbeat must not overwrite it
+	changed, err = c.repo.SetHealthStatus(tenantCtx, objectID, seen.Add(-time.Hour), entities.HealthOffline)
+	c.Require().NoError(err)
+	c.False(changed)
+
+	changed, err = c.repo.SetHealthStatus(tenantCtx, objectID, seen, entities.HealthDegraded)
+	c.Require().NoError(err)
+	c.True(changed)
+
+	client, err := c.repo.Get(tenantCtx, id)
+	c.Require().NoError(err)
+	c.Equal(entities.HealthDegraded, client.Health.Status)
+	c.True(seen.Equal(client.Health.LastSeen))
+}
+
+func (c *ClientRepoSuite) TestKeys() {
+	id, err := c.repo.Create(tenantCtx, &entities.Client{FullName: "test", LocationName: "test"})
+	c.Require().NoError(err)
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	c.Require().NoError(err)
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	first := entities.DeviceKey{ID: "first", PublicKey: []byte("first"), CreatedAt: now}
+	second := entities.DeviceKey{ID: "second", PublicKey: []byte("second"), CreatedAt: now}
+
+	c.Require().NoError(c.repo.AddKey(tenantCtx, objectID, first, now.Add(time.Hour)))
+	c.Require().NoError(c.repo.AddKey(tenantCtx, objectID, second, now.Add(time.Hour)))
+	c.ErrorIs(c.repo.AddKey(tenantCtx, objectID, second, now), repository.ErrDeviceKeyExists)
+
+	client, err := c.repo.Get(tenantCtx, id)
+	c.Require().NoError(err)
+	c.Require().Len(client.Keys, 2)
+	c.True(now.Add(time.Hour).Equal(client.Keys[0].ExpiresAt), "the previous key must expire after the overlap")
+	c.True(client.Keys[1].ExpiresAt.IsZero())
+
+	before, err := c.repo.RevokeKey(tenantCtx, objectID, "second", now)
+	c.Require().NoError(err)
+	c.True(before.ExpiresAt.IsZero())
+
+	_, err = c.repo.RevokeKey(tenantCtx, objectID, "missing", now)
+	c.ErrorIs(err, repository.ErrDeviceKeyNotFound)
+
+	client, err = c.repo.Get(tenantCtx, id)
+	c.Require().NoError(err)
+	c.True(now.Equal(client.Keys[1].ExpiresAt))
+	c.True(now.Add(time.Hour).Equal(client.Keys[0].ExpiresAt), "other keys must be kept")
+}
+
+// TestChange checks that the client before and after the write is the one stored, along with fields which
+// change without the version
+func (c *ClientRepoSuite) TestChange() {
+	id, err := c.repo.Create(tenantCtx, &entities.Client{FullName: "old", LocationName: "test"})
+	c.Require().NoError(err)
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	c.Require().NoError(err)
+
+	health := entities.ClientHealth{Status: entities.HealthOnline, LastSeen: time.Now().UTC()}
+	c.Require().NoError(c.repo.SetHealth(tenantCtx, objectID, health))
+
+	name := "new"
+	before, after, err := c.repo.Patch(tenantCtx, id, 1, entities.ClientPatch{FullName: &name})
+	c.Require().NoError(err)
+	c.Equal("old", before.FullName)
+	c.Equal(entities.HealthOnline, before.Health.Status)
+
+	stored, err := c.repo.Get(tenantCtx, id)
+	c.Require().NoError(err)
+	c.Equal(stored, after)
+
+	_, _, err = c.repo.Update(tenantCtx, id, 1, &entities.Client{FullName: "stale"})
+	c.ErrorIs(err, repository.ErrClientVersionMismatch)
+
+	// If-Match: * matches the client of any version
+	_, stored, err = c.repo.Update(tenantCtx, id, entities.AnyVersion, &entities.Client{FullName: "any"})
+	c.Require().NoError(err)
+	c.EqualValues(3, stored.Version)
+
+	before, after, err = c.repo.Delete(tenantCtx, id, 3)
+	c.Require().NoError(err)
+	c.Equal(stored, before)
+
+	stored, err = c.repo.GetWithDeleted(tenantCtx, id)
+	c.Require().NoError(err)
+	c.Equal(stored, after)
+}
+
+// TestCreateManyPartial checks that IDs of clients written before the failed one are returned
+func (c *ClientRepoSuite) TestCreateManyPartial() {
+	collection := c.dbClient.Database(_dbName).Collection(_collectionName)
+
+	index, err := collection.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "fullName", Value: 1}},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"locationName": "bulk"}),
+	})
+	c.Require().NoError(err)
+	defer func() {
+		_, err := collection.Indexes().DropOne(context.Background(), index)
+		c.NoError(err)
+	}()
+
+	ids, err := c.repo.CreateMany(tenantCtx, []*entities.Client{
+		{FullName: "first", LocationName: "bulk"},
+		{FullName: "second", LocationName: "bulk"},
+		{FullName: "first", LocationName: "bulk"},
+		{FullName: "third", LocationName: "bulk"},
+	})
+	c.Require().Error(err)
+	c.Require().Len(ids, 2)
+
+	for _, id := range ids {
+		_, err := c.repo.Get(tenantCtx, id)
+		c.NoError(err)
+	}
+}
+
 //func (c *ClientRepoSuite) TestUpdate() {
 //	testTable := []struct {
 //		name string
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/tenant"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ClientVersionRepo keeps the history of client documents
+type ClientVersionRepo struct {
+	collection *mongo.Collection
+	tracer     trace.Tracer
+}
+
+func (c ClientVersionRepo) Create(ctx context.Context, version *entities.ClientVersion) (string, error) {
+	ctx, span := c.tracer.Start(ctx, "ClientVersionRepo.Create")
+	defer span.End()
+
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	version.ID = primitive.NewObjectID()
+	version.TenantID = tenantID
+
+	if _, err := c.collection.InsertOne(ctx, version); err != nil {
+		span.RecordError(err)
+		return "", errors.Wrap(err, "error during create client version")
+	}
+
+	return version.ID.Hex(), nil
+}
+
+// List returns versions of the client from the oldest one
+func (c ClientVersionRepo) List(ctx context.Context, clientID primitive.ObjectID) ([]entities.ClientVersion, error) {
+	ctx, span := c.tracer.Start(ctx, "ClientVersionRepo.List")
+	defer span.End()
+
+	filter, err := scoped(ctx, bson.M{"clientID": clientID})
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := c.collection.Find(ctx, filter, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during list client versions")
+	}
+
+	versions := make([]entities.ClientVersion, 0)
+	if err := cursor.All(ctx, &versions); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during decode client versions")
+	}
+
+	return versions, nil
+}
+
+// DeleteForClients removes the history of purged clients
+func (c ClientVersionRepo) DeleteForClients(ctx context.Context, clientIDs []primitive.ObjectID) (int64, error) {
+	ctx, span := c.tracer.Start(ctx, "ClientVersionRepo.DeleteForClients")
+	defer span.End()
+
+	filter, err := scoped(ctx, bson.M{"clientID": bson.M{"$in": clientIDs}})
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := c.collection.DeleteMany(ctx, filter)
+	if err != nil {
+		span.RecordError(err)
+		return 0, errors.Wrap(err, "error during delete client versions")
+	}
+
+	return res.DeletedCount, nil
+}
+
+func NewClientVersionRepo(database *mongo.Database) *ClientVersionRepo {
+	return &ClientVersionRepo{
+		collection: database.Collection(_clientVersionsCollection),
+		tracer:     otel.Tracer("ClientVersionRepo"),
+	}
+}
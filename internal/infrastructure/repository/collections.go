@@ -1,5 +1,21 @@
 package repository
 
 const (
-	_clientsCollection = "Clients"
+	_clientsCollection        = "Clients"
+	_incidentsCollection      = "Incidents"
+	_detectionsCollection     = "Detections"
+	_alertRulesCollection     = "AlertRules"
+	_alertsCollection         = "Alerts"
+	_zonesCollection          = "Zones"
+	_heartbeatsCollection     = "Heartbeats"
+	_configsCollection        = "Configs"
+	_commandsCollection       = "Commands"
+	_organizationsCollection  = "Organizations"
+	_auditCollection          = "Audit"
+	_chainCollection          = "AudioChain"
+	_blobsCollection          = "AudioBlobs"
+	_tombstonesCollection     = "Tombstones"
+	_clientVersionsCollection = "ClientVersions"
+	_idempotencyCollection    = "IdempotentRequests"
+	_webhooksCollection       = "Webhooks"
 )
@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+type CommandRepo struct {
+	collection *mongo.Collection
+	tracer     trace.Tracer
+}
+
+func (c CommandRepo) Create(ctx context.Context, command *entities.Command) (string, error) {
+	ctx, span := c.tracer.Start(ctx, "CommandRepo.Create")
+	defer span.End()
+
+	command.ID = primitive.NewObjectID()
+
+	if _, err := c.collection.InsertOne(ctx, command); err != nil {
+		span.RecordError(err)
+		return "", errors.Wrap(err, "error during create command")
+	}
+
+	return command.ID.Hex(), nil
+}
+
+func (c CommandRepo) Get(ctx context.Context, clientID, id primitive.ObjectID) (entities.Command, error) {
+	ctx, span := c.tracer.Start(ctx, "CommandRepo.Get")
+	defer span.End()
+
+	var command entities.Command
+	if err := c.collection.FindOne(ctx, bson.M{"_id": id, "clientID": clientID}).Decode(&command); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return entities.Command{}, ErrCommandNotFound
+		}
+
+		span.RecordError(err)
+		return entities.Command{}, errors.Wrap(err, "error during get command from db")
+	}
+
+	return command, nil
+}
+
+func (c CommandRepo) List(ctx context.Context, filter entities.CommandFilter) ([]entities.Command, error) {
+	ctx, span := c.tracer.Start(ctx, "CommandRepo.List")
+	defer span.End()
+
+	query := bson.M{"clientID": filter.ClientID}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+
+	opts := options.Find().
+		SetSort(bson.M{"createdAt": -1}).
+		SetSkip(filter.Offset).
+		SetLimit(filter.Limit)
+
+	cursor, err := c.collection.Find(ctx, query, opts)
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during list commands")
+	}
+
+	commands := make([]entities.Command, 0)
+	if err := cursor.All(ctx, &commands); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during decode commands")
+	}
+
+	return commands, nil
+}
+
+// Claim marks pending not expired commands of the client as delivered and returns them in order of creation,
+// commands which aren't acknowledged in time are returned to the queue by Redeliver
+func (c CommandRepo) Claim(ctx context.Context, clientID primitive.ObjectID, now time.Time) ([]entities.Command, error) {
+	ctx, span := c.tracer.Start(ctx, "CommandRepo.Claim")
+	defer span.End()
+
+	filter := bson.M{
+		"clientID":  clientID,
+		"status":    entities.CommandPending,
+		"expiresAt": bson.M{"$gt": now},
+	}
+
+	cursor, err := c.collection.Find(ctx, filter, options.Find().SetSort(bson.M{"createdAt": 1}))
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during find pending commands")
+	}
+
+	pending := make([]entities.Command, 0)
+	if err := cursor.All(ctx, &pending); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during decode commands")
+	}
+
+	// every command is claimed separately, so a concurrent request of the same client doesn't get it twice
+	claimed := make([]entities.Command, 0, len(pending))
+	for _, command := range pending {
+		update := bson.M{
+			"$set": bson.M{"status": entities.CommandDelivered, "deliveredAt": now},
+		}
+
+		res, err := c.collection.UpdateOne(ctx, bson.M{"_id": command.ID, "status": entities.CommandPending}, update)
+		if err != nil {
+			span.RecordError(err)
+			return nil, errors.Wrap(err, "error during claim command")
+		}
+
+		if res.ModifiedCount == 0 {
+			continue
+		}
+
+		command.Status, command.DeliveredAt = entities.CommandDelivered, now
+		claimed = append(claimed, command)
+	}
+
+	return claimed, nil
+}
+
+// Acknowledge marks the delivered command as accepted by the client
+func (c CommandRepo) Acknowledge(
+	ctx context.Context, clientID, id primitive.ObjectID, at time.Time,
+) (entities.Command, error) {
+	ctx, span := c.tracer.Start(ctx, "CommandRepo.Acknowledge")
+	defer span.End()
+
+	return c.transition(
+		ctx,
+		clientID,
+		id,
+		[]entities.CommandStatus{entities.CommandPending, entities.CommandDelivered},
+		bson.M{"status": entities.CommandAcknowledged, "ackedAt": at},
+	)
+}
+
+// Complete saves the result of the command, the command may be completed without the acknowledgement
+func (c CommandRepo) Complete(
+	ctx context.Context, clientID, id primitive.ObjectID, result entities.CommandResult, at time.Time,
+) (entities.Command, error) {
+	ctx, span := c.tracer.Start(ctx, "CommandRepo.Complete")
+	defer span.End()
+
+	status := entities.CommandSucceeded
+	if !result.Success {
+		status = entities.CommandFailed
+	}
+
+	return c.transition(
+		ctx,
+		clientID,
+		id,
+		[]entities.CommandStatus{entities.CommandPending, entities.CommandDelivered, entities.CommandAcknowledged},
+		bson.M{"status": status, "completedAt": at, "result": result.Result, "error": result.Error},
+	)
+}
+
+func (c CommandRepo) transition(
+	ctx context.Context, clientID, id primitive.ObjectID, from []entities.CommandStatus, set bson.M,
+) (entities.Command, error) {
+	filter := bson.M{
+		"_id":      id,
+		"clientID": clientID,
+		"status":   bson.M{"$in": from},
+	}
+
+	var command entities.Command
+	err := c.collection.FindOneAndUpdate(
+		ctx, filter, bson.M{"$set": set}, options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&command)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return entities.Command{}, ErrCommandStatusChanged
+		}
+
+		return entities.Command{}, errors.Wrap(err, "error during change command status")
+	}
+
+	return command, nil
+}
+
+// Redeliver returns commands delivered before the time and not acknowledged since to the queue, so the client
+// which has lost the response gets them again
+func (c CommandRepo) Redeliver(ctx context.Context, deliveredBefore, now time.Time) (int64, error) {
+	ctx, span := c.tracer.Start(ctx, "CommandRepo.Redeliver")
+	defer span.End()
+
+	filter := bson.M{
+		"status":      entities.CommandDelivered,
+		"deliveredAt": bson.M{"$lte": deliveredBefore},
+		"expiresAt":   bson.M{"$gt": now},
+	}
+
+	update := bson.M{
+		"$set":   bson.M{"status": entities.CommandPending},
+		"$unset": bson.M{"deliveredAt": ""},
+	}
+
+	res, err := c.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		span.RecordError(err)
+		return 0, errors.Wrap(err, "error during redeliver commands")
+	}
+
+	return res.ModifiedCount, nil
+}
+
+// Expire marks commands which were not completed before their deadline as expired
+func (c CommandRepo) Expire(ctx context.Context, now time.Time) (int64, error) {
+	ctx, span := c.tracer.Start(ctx, "CommandRepo.Expire")
+	defer span.End()
+
+	filter := bson.M{
+		"status": bson.M{
+			"$in": []entities.CommandStatus{
+				entities.CommandPending, entities.CommandDelivered, entities.CommandAcknowledged,
+			},
+		},
+		"expiresAt": bson.M{"$lte": now},
+	}
+
+	res, err := c.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"status": entities.CommandExpired}})
+	if err != nil {
+		span.RecordError(err)
+		return 0, errors.Wrap(err, "error during expire commands")
+	}
+
+	return res.ModifiedCount, nil
+}
+
+func NewCommandRepo(database *mongo.Database) *CommandRepo {
+	return &CommandRepo{
+		collection: database.Collection(_commandsCollection),
+		tracer:     otel.Tracer("CommandRepo"),
+	}
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+type ConfigRepo struct {
+	collection *mongo.Collection
+	tracer     trace.Tracer
+}
+
+// Save replaces the config of the owner and increases its version
+func (c ConfigRepo) Save(
+	ctx context.Context, scope entities.ConfigScope, ownerID primitive.ObjectID, config entities.SensorConfig,
+) (entities.ConfigDocument, error) {
+	ctx, span := c.tracer.Start(ctx, "ConfigRepo.Save")
+	defer span.End()
+
+	filter := bson.M{
+		"scope":   scope,
+		"ownerID": ownerID,
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"config":    config,
+			"updatedAt": time.Now().UTC(),
+		},
+		"$inc":         bson.M{"version": 1},
+		"$setOnInsert": bson.M{"_id": primitive.NewObjectID()},
+	}
+
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.After)
+
+	var document entities.ConfigDocument
+	if err := c.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&document); err != nil {
+		span.RecordError(err)
+		return entities.ConfigDocument{}, errors.Wrap(err, "error during save config")
+	}
+
+	return document, nil
+}
+
+func (c ConfigRepo) Get(
+	ctx context.Context, scope entities.ConfigScope, ownerID primitive.ObjectID,
+) (entities.ConfigDocument, error) {
+	ctx, span := c.tracer.Start(ctx, "ConfigRepo.Get")
+	defer span.End()
+
+	filter := bson.M{
+		"scope":   scope,
+		"ownerID": ownerID,
+	}
+
+	var document entities.ConfigDocument
+	if err := c.collection.FindOne(ctx, filter).Decode(&document); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return entities.ConfigDocument{}, ErrConfigNotFound
+		}
+
+		span.RecordError(err)
+		return entities.ConfigDocument{}, errors.Wrap(err, "error during get config from db")
+	}
+
+	return document, nil
+}
+
+// FindForClient returns the config of the client and configs of its zones
+func (c ConfigRepo) FindForClient(
+	ctx context.Context, clientID primitive.ObjectID, zoneIDs []primitive.ObjectID,
+) ([]entities.ConfigDocument, error) {
+	ctx, span := c.tracer.Start(ctx, "ConfigRepo.FindForClient")
+	defer span.End()
+
+	if zoneIDs == nil {
+		zoneIDs = []primitive.ObjectID{}
+	}
+
+	filter := bson.M{
+		"$or": bson.A{
+			bson.M{"scope": entities.ConfigScopeClient, "ownerID": clientID},
+			bson.M{"scope": entities.ConfigScopeZone, "ownerID": bson.M{"$in": zoneIDs}},
+		},
+	}
+
+	cursor, err := c.collection.Find(ctx, filter)
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during find configs of client")
+	}
+
+	documents := make([]entities.ConfigDocument, 0)
+	if err := cursor.All(ctx, &documents); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during decode configs")
+	}
+
+	return documents, nil
+}
+
+func NewConfigRepo(database *mongo.Database) *ConfigRepo {
+	return &ConfigRepo{
+		collection: database.Collection(_configsCollection),
+		tracer:     otel.Tracer("ConfigRepo"),
+	}
+}
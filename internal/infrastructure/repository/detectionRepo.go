@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/tenant"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+type DetectionRepo struct {
+	collection *mongo.Collection
+	tracer     trace.Tracer
+}
+
+func (d DetectionRepo) Create(ctx context.Context, detection *entities.Detection) (string, error) {
+	ctx, span := d.tracer.Start(ctx, "DetectionRepo.Create")
+	defer span.End()
+
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	detection.ID = primitive.NewObjectID()
+	detection.TenantID = tenantID
+
+	if _, err := d.collection.InsertOne(ctx, detection); err != nil {
+		span.RecordError(err)
+		return "", errors.Wrap(err, "error during create detection")
+	}
+
+	return detection.ID.Hex(), nil
+}
+
+func (d DetectionRepo) List(ctx context.Context, filter entities.DetectionFilter) ([]entities.Detection, error) {
+	ctx, span := d.tracer.Start(ctx, "DetectionRepo.List")
+	defer span.End()
+
+	query, err := scoped(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	if !filter.ClientID.IsZero() {
+		query["clientID"] = filter.ClientID
+	}
+	if !filter.ZoneID.IsZero() {
+		query["zoneIDs"] = filter.ZoneID
+	}
+	if !filter.IncidentID.IsZero() {
+		query["incidentID"] = filter.IncidentID
+	}
+	addTimeRange(query, "timestamp", filter.From, filter.To)
+
+	// IDs are given on insert, so they follow the order detections are stored in
+	sort := bson.M{"timestamp": 1}
+	if !filter.After.IsZero() {
+		query["_id"] = bson.M{"$gt": filter.After}
+		sort = bson.M{"_id": 1}
+	}
+
+	opts := options.Find().
+		SetSort(sort).
+		SetSkip(filter.Offset).
+		SetLimit(filter.Limit)
+
+	cursor, err := d.collection.Find(ctx, query, opts)
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during list detections")
+	}
+
+	detections := make([]entities.Detection, 0)
+	if err := cursor.All(ctx, &detections); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during decode detections")
+	}
+
+	return detections, nil
+}
+
+// Count returns the number of detections of the client in the time range
+func (d DetectionRepo) Count(ctx context.Context, clientID primitive.ObjectID, from, to time.Time) (int64, error) {
+	ctx, span := d.tracer.Start(ctx, "DetectionRepo.Count")
+	defer span.End()
+
+	query, err := scoped(ctx, bson.M{"clientID": clientID})
+	if err != nil {
+		return 0, err
+	}
+	addTimeRange(query, "timestamp", from, to)
+
+	count, err := d.collection.CountDocuments(ctx, query)
+	if err != nil {
+		span.RecordError(err)
+		return 0, errors.Wrap(err, "error during count detections")
+	}
+
+	return count, nil
+}
+
+// MarkFalsePositive flags all detections of the incident as false positive
+func (d DetectionRepo) MarkFalsePositive(ctx context.Context, incidentID string) error {
+	ctx, span := d.tracer.Start(ctx, "DetectionRepo.MarkFalsePositive")
+	defer span.End()
+
+	castedID, err := primitive.ObjectIDFromHex(incidentID)
+	if err != nil {
+		return invalidID(err, "incident")
+	}
+
+	filter, err := scoped(ctx, bson.M{"incidentID": castedID})
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{
+		"$set": bson.M{"falsePositive": true},
+	}
+
+	if _, err := d.collection.UpdateMany(ctx, filter, update); err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "error during mark detections")
+	}
+
+	return nil
+}
+
+// Hold removes the expiration of detections of the incident, so the TTL index keeps them
+func (d DetectionRepo) Hold(ctx context.Context, incidentID primitive.ObjectID) error {
+	ctx, span := d.tracer.Start(ctx, "DetectionRepo.Hold")
+	defer span.End()
+
+	filter, err := scoped(ctx, bson.M{"incidentID": incidentID})
+	if err != nil {
+		return err
+	}
+
+	if _, err := d.collection.UpdateMany(ctx, filter, bson.M{"$unset": bson.M{"expiresAt": ""}}); err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "error during hold detections")
+	}
+
+	return nil
+}
+
+// Release sets the expiration of detections of the incident to their timestamp plus the period,
+// the zero period keeps them forever
+func (d DetectionRepo) Release(ctx context.Context, incidentID primitive.ObjectID, period time.Duration) error {
+	ctx, span := d.tracer.Start(ctx, "DetectionRepo.Release")
+	defer span.End()
+
+	if period == 0 {
+		return nil
+	}
+
+	filter, err := scoped(ctx, bson.M{"incidentID": incidentID})
+	if err != nil {
+		return err
+	}
+
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{"expiresAt": bson.M{"$add": bson.A{"$timestamp", period.Milliseconds()}}}}},
+	}
+
+	if _, err := d.collection.UpdateMany(ctx, filter, update); err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "error during release detections")
+	}
+
+	return nil
+}
+
+// CountExpiring returns the number of detections the TTL index removes before the moment
+func (d DetectionRepo) CountExpiring(ctx context.Context, before time.Time) (int64, error) {
+	ctx, span := d.tracer.Start(ctx, "DetectionRepo.CountExpiring")
+	defer span.End()
+
+	filter, err := scoped(ctx, bson.M{"expiresAt": bson.M{"$lte": before}})
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := d.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		span.RecordError(err)
+		return 0, errors.Wrap(err, "error during count expiring detections")
+	}
+
+	return count, nil
+}
+
+func NewDetectionRepo(database *mongo.Database) *DetectionRepo {
+	return &DetectionRepo{
+		collection: database.Collection(_detectionsCollection),
+		tracer:     otel.Tracer("DetectionRepo"),
+	}
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/kms"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const _dataKeySize = 32
+
+var (
+	ErrBlobDecryption = errors.New("the blob can't be decrypted")
+)
+
+// EncryptedBlobRepo encrypts objects of the underlying store with the envelope encryption: every object
+// gets its own AES-256-GCM data key wrapped by the KMS. The key of the object is authenticated, so the
+// ciphertext can't be moved to another object. Objects stored in plain are returned as is
+type EncryptedBlobRepo struct {
+	BlobRepository
+	kms    kms.KMS
+	tracer trace.Tracer
+}
+
+func (e EncryptedBlobRepo) Put(ctx context.Context, blob *entities.Blob) error {
+	ctx, span := e.tracer.Start(ctx, "EncryptedBlobRepo.Put")
+	defer span.End()
+
+	dataKey := make([]byte, _dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return errors.Wrap(err, "error during generate data key")
+	}
+
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return errors.Wrap(err, "error during generate nonce")
+	}
+
+	wrapped, err := e.kms.Wrap(ctx, dataKey)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "error during wrap data key")
+	}
+
+	// the caller keeps its plain data
+	encrypted := *blob
+	encrypted.Data = aead.Seal(nil, nonce, blob.Data, []byte(blob.Key))
+	encrypted.Encryption = &entities.BlobEncryption{
+		Algorithm:  entities.BlobAlgorithm,
+		KeyID:      wrapped.KeyID,
+		WrappedKey: wrapped.Ciphertext,
+		Nonce:      nonce,
+	}
+
+	if err := e.BlobRepository.Put(ctx, &encrypted); err != nil {
+		return err
+	}
+	blob.TenantID = encrypted.TenantID
+
+	return nil
+}
+
+func (e EncryptedBlobRepo) Get(ctx context.Context, key string) (entities.Blob, error) {
+	ctx, span := e.tracer.Start(ctx, "EncryptedBlobRepo.Get")
+	defer span.End()
+
+	blob, err := e.BlobRepository.Get(ctx, key)
+	if err != nil || blob.Encryption == nil {
+		return blob, err
+	}
+
+	dataKey, err := e.kms.Unwrap(ctx, wrappedKey(*blob.Encryption))
+	if err != nil {
+		span.RecordError(err)
+		return entities.Blob{}, errors.Wrapf(ErrBlobDecryption, "%s: %s", key, err)
+	}
+
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return entities.Blob{}, err
+	}
+
+	data, err := aead.Open(nil, blob.Encryption.Nonce, blob.Data, []byte(blob.Key))
+	if err != nil {
+		span.RecordError(err)
+		return entities.Blob{}, errors.Wrapf(ErrBlobDecryption, "%s: %s", key, err)
+	}
+
+	blob.Data = data
+	blob.Encryption = nil
+
+	return blob, nil
+}
+
+// CurrentKeyID is the key encryption key new objects are wrapped with
+func (e EncryptedBlobRepo) CurrentKeyID() string {
+	return e.kms.CurrentKeyID()
+}
+
+// Rewrap wraps the data key of the object with the current key encryption key, the data is not touched
+func (e EncryptedBlobRepo) Rewrap(ctx context.Context, blob entities.Blob) error {
+	ctx, span := e.tracer.Start(ctx, "EncryptedBlobRepo.Rewrap")
+	defer span.End()
+
+	if blob.Encryption == nil {
+		return nil
+	}
+
+	dataKey, err := e.kms.Unwrap(ctx, wrappedKey(*blob.Encryption))
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrapf(ErrBlobDecryption, "%s: %s", blob.Key, err)
+	}
+
+	wrapped, err := e.kms.Wrap(ctx, dataKey)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "error during wrap data key")
+	}
+
+	to := *blob.Encryption
+	to.KeyID = wrapped.KeyID
+	to.WrappedKey = wrapped.Ciphertext
+
+	return e.BlobRepository.SetEncryption(ctx, blob.Key, *blob.Encryption, to)
+}
+
+func wrappedKey(encryption entities.BlobEncryption) kms.WrappedKey {
+	return kms.WrappedKey{KeyID: encryption.KeyID, Ciphertext: encryption.WrappedKey}
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "error during create cipher")
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "error during create cipher")
+	}
+
+	return aead, nil
+}
+
+func NewEncryptedBlobRepo(blobs BlobRepository, keys kms.KMS) *EncryptedBlobRepo {
+	return &EncryptedBlobRepo{
+		BlobRepository: blobs,
+		kms:            keys,
+		tracer:         otel.Tracer("EncryptedBlobRepo"),
+	}
+}
@@ -0,0 +1,96 @@
+package repository_test
+
+import (
+	"bytes"
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository"
+	mock_repository "github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository/mocks"
+	"github.com/Imm0bilize/gunshot-api-service/internal/kms"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestEncryptedBlobRepo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		ctx    = context.Background()
+		audio  = []byte("speech and a gunshot")
+		stored = make(map[string]entities.Blob)
+		old    = bytes.Repeat([]byte{1}, 32)
+	)
+
+	inner := mock_repository.NewMockBlobRepository(ctrl)
+	inner.EXPECT().Put(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, blob *entities.Blob) error {
+			stored[blob.Key] = *blob
+			return nil
+		},
+	).AnyTimes()
+	inner.EXPECT().Get(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, key string) (entities.Blob, error) {
+			blob, ok := stored[key]
+			if !ok {
+				return entities.Blob{}, repository.ErrBlobNotFound
+			}
+			return blob, nil
+		},
+	).AnyTimes()
+	inner.EXPECT().SetEncryption(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, key string, _, to entities.BlobEncryption) error {
+			blob := stored[key]
+			blob.Encryption = &to
+			stored[key] = blob
+			return nil
+		},
+	).AnyTimes()
+
+	keys, err := kms.NewKeyfile("old", map[string][]byte{"old": old})
+	require.NoError(t, err)
+
+	blobs := repository.NewEncryptedBlobRepo(inner, keys)
+
+	blob := entities.Blob{Key: "audio/a", Data: audio}
+	require.NoError(t, blobs.Put(ctx, &blob))
+	require.Equal(t, audio, blob.Data, "the caller keeps the plain data")
+
+	require.NotContains(t, string(stored["audio/a"].Data), "gunshot")
+	require.Equal(t, "old", stored["audio/a"].Encryption.KeyID)
+
+	got, err := blobs.Get(ctx, "audio/a")
+	require.NoError(t, err)
+	require.Equal(t, audio, got.Data)
+
+	// the ciphertext is bound to its key
+	moved := stored["audio/a"]
+	moved.Key = "audio/b"
+	stored["audio/b"] = moved
+	_, err = blobs.Get(ctx, "audio/b")
+	require.ErrorIs(t, err, repository.ErrBlobDecryption)
+
+	// the rotation rewraps the data key, the audio is not rewritten
+	rotated, err := kms.NewKeyfile("new", map[string][]byte{"old": old, "new": bytes.Repeat([]byte{2}, 32)})
+	require.NoError(t, err)
+
+	blobs = repository.NewEncryptedBlobRepo(inner, rotated)
+	ciphertext := stored["audio/a"].Data
+	require.NoError(t, blobs.Rewrap(ctx, stored["audio/a"]))
+	require.Equal(t, "new", stored["audio/a"].Encryption.KeyID)
+	require.Equal(t, ciphertext, stored["audio/a"].Data)
+
+	withoutOld, err := kms.NewKeyfile("new", map[string][]byte{"new": bytes.Repeat([]byte{2}, 32)})
+	require.NoError(t, err)
+
+	got, err = repository.NewEncryptedBlobRepo(inner, withoutOld).Get(ctx, "audio/a")
+	require.NoError(t, err)
+	require.Equal(t, audio, got.Data)
+
+	// objects stored before the encryption are returned as is
+	stored["audio/plain"] = entities.Blob{Key: "audio/plain", Data: audio}
+	got, err = blobs.Get(ctx, "audio/plain")
+	require.NoError(t, err)
+	require.Equal(t, audio, got.Data)
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/tenant"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErasureRepo removes the data of the client from every collection which refers to it and keeps
+// tombstones of erased clients
+type ErasureRepo struct {
+	database   *mongo.Database
+	tombstones *mongo.Collection
+	tracer     trace.Tracer
+}
+
+// Tombstone saves the tombstone, the repeated erasure of the client replaces it
+func (e ErasureRepo) Tombstone(ctx context.Context, tombstone *entities.Tombstone) error {
+	ctx, span := e.tracer.Start(ctx, "ErasureRepo.Tombstone")
+	defer span.End()
+
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return err
+	}
+	tombstone.TenantID = tenantID
+
+	_, err = e.tombstones.ReplaceOne(
+		ctx, bson.M{"_id": tombstone.ClientID}, tombstone, options.Replace().SetUpsert(true),
+	)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "error during save tombstone")
+	}
+
+	return nil
+}
+
+// Erased tells whether the client has been erased, IDs are unique across tenants
+func (e ErasureRepo) Erased(ctx context.Context, clientID primitive.ObjectID) (bool, error) {
+	ctx, span := e.tracer.Start(ctx, "ErasureRepo.Erased")
+	defer span.End()
+
+	count, err := e.tombstones.CountDocuments(ctx, bson.M{"_id": clientID}, options.Count().SetLimit(1))
+	if err != nil {
+		span.RecordError(err)
+		return false, errors.Wrap(err, "error during find tombstone")
+	}
+
+	return count > 0, nil
+}
+
+// Erase deletes documents of the client except the ones of the hold and pseudonymises the audit log.
+// The client document goes last, so the erasure can be repeated after a failure
+func (e ErasureRepo) Erase(
+	ctx context.Context, clientID primitive.ObjectID, hold entities.ErasureHold,
+) (entities.ErasureReport, error) {
+	ctx, span := e.tracer.Start(ctx, "ErasureRepo.Erase")
+	defer span.End()
+
+	report := entities.NewErasureReport()
+
+	// the audio and its chain records are kept in windows of held incidents, detections with the incidents
+	var inWindows, ofIncidents bson.M
+	if len(hold.Windows) > 0 {
+		windows := make(bson.A, 0, len(hold.Windows))
+		for _, window := range hold.Windows {
+			windows = append(windows, bson.M{"timestamp": bson.M{"$gte": window[0], "$lte": window[1]}})
+		}
+		inWindows = bson.M{"$or": windows}
+	}
+	if len(hold.Incidents) > 0 {
+		ofIncidents = bson.M{"incidentID": bson.M{"$in": hold.Incidents}}
+	}
+
+	deletions := []struct {
+		collection string
+		keep       bson.M
+	}{
+		{collection: _blobsCollection, keep: inWindows},
+		{collection: _chainCollection, keep: inWindows},
+		{collection: _detectionsCollection, keep: ofIncidents},
+		{collection: _heartbeatsCollection},
+		{collection: _commandsCollection},
+		{collection: _alertsCollection},
+		{collection: _alertRulesCollection},
+		{collection: _clientVersionsCollection},
+	}
+
+	for _, deletion := range deletions {
+		collection := e.database.Collection(deletion.collection)
+
+		filter, err := scoped(ctx, bson.M{"clientID": clientID})
+		if err != nil {
+			return entities.ErasureReport{}, err
+		}
+
+		if deletion.keep != nil {
+			kept, err := scoped(ctx, bson.M{"clientID": clientID, "$and": bson.A{deletion.keep}})
+			if err != nil {
+				return entities.ErasureReport{}, err
+			}
+
+			if report.Held[deletion.collection], err = collection.CountDocuments(ctx, kept); err != nil {
+				span.RecordError(err)
+				return entities.ErasureReport{}, errors.Wrapf(err, "error during count held %s", deletion.collection)
+			}
+
+			filter["$nor"] = bson.A{deletion.keep}
+		}
+
+		res, err := collection.DeleteMany(ctx, filter)
+		if err != nil {
+			span.RecordError(err)
+			return entities.ErasureReport{}, errors.Wrapf(err, "error during erase %s", deletion.collection)
+		}
+		report.Deleted[deletion.collection] = res.DeletedCount
+	}
+
+	// configs are not scoped, the client ID is unique
+	res, err := e.database.Collection(_configsCollection).DeleteMany(
+		ctx, bson.M{"scope": entities.ConfigScopeClient, "ownerID": clientID},
+	)
+	if err != nil {
+		span.RecordError(err)
+		return entities.ErasureReport{}, errors.Wrap(err, "error during erase configs")
+	}
+	report.Deleted[_configsCollection] = res.DeletedCount
+
+	// the audit log keeps what has been done, values of the client are replaced
+	filter, err := scoped(ctx, bson.M{"target.id": clientID.Hex(), "changes.0": bson.M{"$exists": true}})
+	if err != nil {
+		return entities.ErasureReport{}, err
+	}
+	updated, err := e.database.Collection(_auditCollection).UpdateMany(ctx, filter, bson.M{
+		"$set": bson.M{
+			"changes.$[].before": entities.ErasedValue,
+			"changes.$[].after":  entities.ErasedValue,
+		},
+	})
+	if err != nil {
+		span.RecordError(err)
+		return entities.ErasureReport{}, errors.Wrap(err, "error during pseudonymise audit entries")
+	}
+	report.Pseudonymised[_auditCollection] = updated.ModifiedCount
+
+	// bulk writes, e.g. the import, audit changes of every client with fields prefixed by its ID, only
+	// the changes of the client are replaced
+	prefix := primitive.Regex{Pattern: "^" + clientID.Hex() + `\.`}
+	if filter, err = scoped(ctx, bson.M{"changes.field": prefix}); err != nil {
+		return entities.ErasureReport{}, err
+	}
+	updated, err = e.database.Collection(_auditCollection).UpdateMany(
+		ctx,
+		filter,
+		bson.M{"$set": bson.M{
+			"changes.$[change].before": entities.ErasedValue,
+			"changes.$[change].after":  entities.ErasedValue,
+		}},
+		options.Update().SetArrayFilters(options.ArrayFilters{
+			Filters: bson.A{bson.M{"change.field": prefix}},
+		}),
+	)
+	if err != nil {
+		span.RecordError(err)
+		return entities.ErasureReport{}, errors.Wrap(err, "error during pseudonymise audit entries of bulk writes")
+	}
+	report.Pseudonymised[_auditCollection] += updated.ModifiedCount
+
+	if filter, err = scoped(ctx, bson.M{"_id": clientID}); err != nil {
+		return entities.ErasureReport{}, err
+	}
+	res, err = e.database.Collection(_clientsCollection).DeleteOne(ctx, filter)
+	if err != nil {
+		span.RecordError(err)
+		return entities.ErasureReport{}, errors.Wrap(err, "error during erase the client")
+	}
+	report.Deleted[_clientsCollection] = res.DeletedCount
+
+	return report, nil
+}
+
+func NewErasureRepo(database *mongo.Database) *ErasureRepo {
+	return &ErasureRepo{
+		database:   database,
+		tombstones: database.Collection(_tombstonesCollection),
+		tracer:     otel.Tracer("ErasureRepo"),
+	}
+}
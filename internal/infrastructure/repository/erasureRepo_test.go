@@ -0,0 +1,88 @@
+//go:build integration
+// +build integration
+
+package repository_test
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/audit"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+	"github.com/testcontainers/testcontainers-go"
+	"go.mongodb.org/mongo-driver/mongo"
+	"strings"
+	"testing"
+	"time"
+)
+
+type ErasureRepoSuite struct {
+	suite.Suite
+	repo      *repository.Repo
+	dbClient  *mongo.Client
+	container testcontainers.Container
+}
+
+func TestErasureRepoSuite(t *testing.T) {
+	suite.Run(t, new(ErasureRepoSuite))
+}
+
+func (s *ErasureRepoSuite) SetupSuite() {
+	s.dbClient, s.container = startMongo(&s.Suite)
+	s.repo = repository.NewRepo(s.dbClient.Database(_dbName))
+}
+
+func (s *ErasureRepoSuite) TearDownSuite() {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	s.Require().NoError(s.dbClient.Disconnect(ctx))
+	s.Require().NoError(s.container.Terminate(ctx))
+}
+
+// TestErasureOfImportedClient checks that the erasure replaces values of the client in the audit entry of
+// the import, which has every imported client as its changes, and keeps values of the other clients
+func (s *ErasureRepoSuite) TestErasureOfImportedClient() {
+	entry := &entities.AuditEntry{
+		RequestID: uuid.NewString(), Method: "POST", Route: "/api/v1/clients/import",
+		Target: entities.AuditTarget{Type: "clients"}, Outcome: entities.AuditPending, Timestamp: time.Now().UTC(),
+	}
+	_, err := s.repo.Audit.Create(tenantCtx, entry)
+	s.Require().NoError(err)
+
+	erased := &entities.Client{FullName: "John Doe", LocationName: "home", Latitude: 52.1, Longitude: 12.2}
+	kept := &entities.Client{FullName: "Jane Roe", LocationName: "office", Latitude: 52.3, Longitude: 12.4}
+	_, err = s.repo.Client.CreateMany(tenantCtx, []*entities.Client{erased, kept})
+	s.Require().NoError(err)
+
+	ctx := audit.WithEntry(tenantCtx, entry)
+	for _, client := range []*entities.Client{erased, kept} {
+		s.Require().NoError(audit.Append(ctx, client.ID.Hex(), nil, *client))
+	}
+	entry.Outcome = entities.AuditSucceeded
+	s.Require().NoError(s.repo.Audit.Complete(tenantCtx, entry))
+
+	report, err := s.repo.Erasure.Erase(tenantCtx, erased.ID, entities.ErasureHold{})
+	s.Require().NoError(err)
+	s.Equal(int64(1), report.Pseudonymised["Audit"])
+
+	entries, err := s.repo.Audit.List(tenantCtx, entities.AuditFilter{RequestID: entry.RequestID})
+	s.Require().NoError(err)
+	s.Require().Len(entries, 1)
+
+	var erasedChanges, keptChanges int
+	for _, change := range entries[0].Changes {
+		switch {
+		case strings.HasPrefix(change.Field, erased.ID.Hex()+"."):
+			erasedChanges++
+			s.Equal(entities.ErasedValue, change.Before, change.Field)
+			s.Equal(entities.ErasedValue, change.After, change.Field)
+		case strings.HasPrefix(change.Field, kept.ID.Hex()+"."):
+			keptChanges++
+			s.NotEqual(entities.ErasedValue, change.After, change.Field)
+		}
+	}
+	s.NotZero(erasedChanges)
+	s.NotZero(keptChanges)
+}
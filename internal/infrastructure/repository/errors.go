@@ -1,7 +1,43 @@
 package repository
 
-import "errors"
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/mongo"
+)
 
 var (
-	ErrClientNotFound = errors.New("the client is not found")
+	ErrClientNotFound        = apperr.New(apperr.NotFound, "the client is not found")
+	ErrClientNotDeleted      = apperr.New(apperr.Conflict, "the client is not deleted")
+	ErrClientVersionMismatch = apperr.New(apperr.FailedPrecondition, "the client has been changed")
+	ErrIncidentNotFound      = apperr.New(apperr.NotFound, "the incident is not found")
+	ErrIncidentStatusChanged = apperr.New(apperr.Conflict, "the incident status has been changed by someone else")
+	ErrAlertRuleNotFound     = apperr.New(apperr.NotFound, "the alert rule is not found")
+	ErrZoneNotFound          = apperr.New(apperr.NotFound, "the zone is not found")
+	ErrConfigNotFound        = apperr.New(apperr.NotFound, "the config is not found")
+	ErrCommandNotFound       = apperr.New(apperr.NotFound, "the command is not found")
+	ErrCommandStatusChanged  = apperr.New(apperr.Conflict, "the command is already completed or expired")
+	ErrOrganizationNotFound  = apperr.New(apperr.NotFound, "the organization is not found")
+	ErrChainHeadMoved        = apperr.New(apperr.Conflict, "the hash chain has been extended by someone else")
+	ErrChainSequenceTaken    = apperr.New(apperr.Conflict, "the sequence of the hash chain is already taken")
+	ErrChainRecordNotFound   = apperr.New(apperr.NotFound, "the chain record is not found")
+	ErrBlobNotFound          = apperr.New(apperr.NotFound, "the blob is not found")
+	ErrDeviceKeyExists       = apperr.New(apperr.Conflict, "the device key is already registered")
+	ErrDeviceKeyNotFound     = apperr.New(apperr.NotFound, "the device key is not found")
+	ErrAuditEntryCompleted   = apperr.New(apperr.Conflict, "the audit entry is already completed")
+	ErrWebhookNotFound       = apperr.New(apperr.NotFound, "the webhook is not found")
 )
+
+// invalidID is the error of the malformed ID of the entity
+func invalidID(err error, entity string) error {
+	return apperr.Wrap(apperr.InvalidArgument, errors.Wrap(err, "invalid "+entity+" id"))
+}
+
+// IsUnavailable tells if the error is the failure of the database which may pass on retry
+func IsUnavailable(err error) bool {
+	return mongo.IsNetworkError(err) ||
+		mongo.IsTimeout(err) ||
+		errors.Is(err, mongo.ErrClientDisconnected) ||
+		errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/tenant"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+type HeartbeatRepo struct {
+	collection *mongo.Collection
+	tracer     trace.Tracer
+}
+
+func (h HeartbeatRepo) Create(ctx context.Context, heartbeat *entities.Heartbeat) (string, error) {
+	ctx, span := h.tracer.Start(ctx, "HeartbeatRepo.Create")
+	defer span.End()
+
+	// heartbeats are kept per tenant for the retention, the system context has none
+	if tenantID, err := tenant.ID(ctx); err == nil {
+		heartbeat.TenantID = tenantID
+	}
+	heartbeat.ID = primitive.NewObjectID()
+
+	if _, err := h.collection.InsertOne(ctx, heartbeat); err != nil {
+		span.RecordError(err)
+		return "", errors.Wrap(err, "error during create heartbeat")
+	}
+
+	return heartbeat.ID.Hex(), nil
+}
+
+func (h HeartbeatRepo) List(ctx context.Context, filter entities.HeartbeatFilter) ([]entities.Heartbeat, error) {
+	ctx, span := h.tracer.Start(ctx, "HeartbeatRepo.List")
+	defer span.End()
+
+	query := bson.M{"clientID": filter.ClientID}
+	addTimeRange(query, "receivedAt", filter.From, filter.To)
+
+	opts := options.Find().
+		SetSort(bson.M{"receivedAt": -1}).
+		SetSkip(filter.Offset).
+		SetLimit(filter.Limit)
+
+	cursor, err := h.collection.Find(ctx, query, opts)
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during list heartbeats")
+	}
+
+	heartbeats := make([]entities.Heartbeat, 0)
+	if err := cursor.All(ctx, &heartbeats); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during decode heartbeats")
+	}
+
+	return heartbeats, nil
+}
+
+// CountExpiring returns the number of heartbeats the TTL index removes before the moment
+func (h HeartbeatRepo) CountExpiring(ctx context.Context, before time.Time) (int64, error) {
+	ctx, span := h.tracer.Start(ctx, "HeartbeatRepo.CountExpiring")
+	defer span.End()
+
+	filter, err := scoped(ctx, bson.M{"expiresAt": bson.M{"$lte": before}})
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := h.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		span.RecordError(err)
+		return 0, errors.Wrap(err, "error during count expiring heartbeats")
+	}
+
+	return count, nil
+}
+
+func NewHeartbeatRepo(database *mongo.Database) *HeartbeatRepo {
+	return &HeartbeatRepo{
+		collection: database.Collection(_heartbeatsCollection),
+		tracer:     otel.Tracer("HeartbeatRepo"),
+	}
+}
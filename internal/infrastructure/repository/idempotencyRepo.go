@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/tenant"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+// IdempotencyRepo keeps requests sent with idempotency keys, the key is unique per tenant and expired requests
+// are removed by the TTL index
+type IdempotencyRepo struct {
+	collection *mongo.Collection
+	tracer     trace.Tracer
+}
+
+// Begin saves the pending request unless the tenant has sent the key already, then the request of the key is
+// returned with false. The expired request the TTL index hasn't removed yet doesn't hold the key
+func (i IdempotencyRepo) Begin(
+	ctx context.Context, request *entities.IdempotentRequest,
+) (entities.IdempotentRequest, bool, error) {
+	ctx, span := i.tracer.Start(ctx, "IdempotencyRepo.Begin")
+	defer span.End()
+
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return entities.IdempotentRequest{}, false, err
+	}
+
+	request.ID = primitive.NewObjectID()
+	request.TenantID = tenantID
+
+	key := bson.M{"tenantID": tenantID, "key": request.Key}
+
+	expired := bson.M{"tenantID": tenantID, "key": request.Key, "expiresAt": bson.M{"$lte": time.Now().UTC()}}
+	if _, err := i.collection.DeleteOne(ctx, expired); err != nil {
+		span.RecordError(err)
+		return entities.IdempotentRequest{}, false, errors.Wrap(err, "error during delete expired request")
+	}
+
+	_, err = i.collection.InsertOne(ctx, request)
+	if err == nil {
+		return *request, true, nil
+	}
+
+	if !mongo.IsDuplicateKeyError(err) {
+		span.RecordError(err)
+		return entities.IdempotentRequest{}, false, errors.Wrap(err, "error during create idempotent request")
+	}
+
+	var existing entities.IdempotentRequest
+	if err := i.collection.FindOne(ctx, key).Decode(&existing); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			// the request of the key has been released since the insert, the key is reported as pending
+			return entities.IdempotentRequest{
+				TenantID: tenantID, Key: request.Key, Method: request.Method, Path: request.Path,
+				BodyHash: request.BodyHash,
+			}, false, nil
+		}
+
+		span.RecordError(err)
+		return entities.IdempotentRequest{}, false, errors.Wrap(err, "error during get idempotent request")
+	}
+
+	return existing, false, nil
+}
+
+// Complete saves the response of the pending request
+func (i IdempotencyRepo) Complete(ctx context.Context, request *entities.IdempotentRequest) error {
+	ctx, span := i.tracer.Start(ctx, "IdempotencyRepo.Complete")
+	defer span.End()
+
+	update := bson.M{"$set": bson.M{
+		"status":    request.Status,
+		"header":    request.Header,
+		"body":      request.Body,
+		"expiresAt": request.ExpiresAt,
+	}}
+
+	if _, err := i.collection.UpdateOne(ctx, bson.M{"_id": request.ID, "status": 0}, update); err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "error during complete idempotent request")
+	}
+
+	return nil
+}
+
+// Extend moves the expiration of the pending request, so the key is held while the request is served
+func (i IdempotencyRepo) Extend(ctx context.Context, request *entities.IdempotentRequest) error {
+	ctx, span := i.tracer.Start(ctx, "IdempotencyRepo.Extend")
+	defer span.End()
+
+	update := bson.M{"$set": bson.M{"expiresAt": request.ExpiresAt}}
+	if _, err := i.collection.UpdateOne(ctx, bson.M{"_id": request.ID, "status": 0}, update); err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "error during extend idempotent request")
+	}
+
+	return nil
+}
+
+// Release removes the pending request, so the key may be sent again
+func (i IdempotencyRepo) Release(ctx context.Context, request *entities.IdempotentRequest) error {
+	ctx, span := i.tracer.Start(ctx, "IdempotencyRepo.Release")
+	defer span.End()
+
+	if _, err := i.collection.DeleteOne(ctx, bson.M{"_id": request.ID, "status": 0}); err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "error during release idempotent request")
+	}
+
+	return nil
+}
+
+func NewIdempotencyRepo(database *mongo.Database) *IdempotencyRepo {
+	return &IdempotencyRepo{
+		collection: database.Collection(_idempotencyCollection),
+		tracer:     otel.Tracer("IdempotencyRepo"),
+	}
+}
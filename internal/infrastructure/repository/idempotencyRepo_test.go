@@ -0,0 +1,124 @@
+//go:build integration
+// +build integration
+
+package repository_test
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository"
+	"github.com/Imm0bilize/gunshot-api-service/internal/tenant"
+	"github.com/stretchr/testify/suite"
+	"github.com/testcontainers/testcontainers-go"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type IdempotencyRepoSuite struct {
+	suite.Suite
+	repo      *repository.IdempotencyRepo
+	dbClient  *mongo.Client
+	container testcontainers.Container
+}
+
+func TestIdempotencyRepoSuite(t *testing.T) {
+	suite.Run(t, new(IdempotencyRepoSuite))
+}
+
+func (s *IdempotencyRepoSuite) SetupSuite() {
+	s.dbClient, s.container = startMongo(&s.Suite)
+
+	database := s.dbClient.Database(_dbName)
+	s.Require().NoError(repository.EnsureIndexes(context.Background(), database))
+	s.repo = repository.NewIdempotencyRepo(database)
+}
+
+func (s *IdempotencyRepoSuite) TearDownSuite() {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	s.Require().NoError(s.dbClient.Disconnect(ctx))
+	s.Require().NoError(s.container.Terminate(ctx))
+}
+
+func (s *IdempotencyRepoSuite) request(key string, expiresAt time.Time) *entities.IdempotentRequest {
+	return &entities.IdempotentRequest{Key: key, Method: http.MethodPost, Path: "/api/v1/client", ExpiresAt: expiresAt}
+}
+
+// TestKeyIsTaken checks that the key is held by the first request until it's released, the response saved
+// by Complete is returned to the next ones
+func (s *IdempotencyRepoSuite) TestKeyIsTaken() {
+	expiresAt := time.Now().UTC().Add(time.Hour)
+
+	first := s.request("taken", expiresAt)
+	_, started, err := s.repo.Begin(tenantCtx, first)
+	s.Require().NoError(err)
+	s.Require().True(started)
+
+	pending, started, err := s.repo.Begin(tenantCtx, s.request("taken", expiresAt))
+	s.Require().NoError(err)
+	s.False(started)
+	s.True(pending.Pending())
+
+	// keys of other organizations are their own
+	otherCtx := tenant.WithID(context.Background(), primitive.NewObjectID())
+	_, started, err = s.repo.Begin(otherCtx, s.request("taken", expiresAt))
+	s.Require().NoError(err)
+	s.True(started)
+
+	first.Status, first.Header, first.Body = http.StatusCreated, map[string]string{"ETag": `"1"`}, []byte(`{}`)
+	s.Require().NoError(s.repo.Complete(tenantCtx, first))
+
+	completed, started, err := s.repo.Begin(tenantCtx, s.request("taken", expiresAt))
+	s.Require().NoError(err)
+	s.False(started)
+	s.Equal(http.StatusCreated, completed.Status)
+	s.Equal(first.Header, completed.Header)
+	s.Equal(first.Body, completed.Body)
+
+	// the completed request isn't released
+	s.Require().NoError(s.repo.Release(tenantCtx, first))
+	_, started, err = s.repo.Begin(tenantCtx, s.request("taken", expiresAt))
+	s.Require().NoError(err)
+	s.False(started)
+}
+
+// TestLeaseIsExtended checks that the pending request holds its key past the lease while it's extended
+func (s *IdempotencyRepoSuite) TestLeaseIsExtended() {
+	pending := s.request("extended", time.Now().UTC().Add(time.Second))
+	_, started, err := s.repo.Begin(tenantCtx, pending)
+	s.Require().NoError(err)
+	s.Require().True(started)
+
+	pending.ExpiresAt = time.Now().UTC().Add(time.Hour)
+	s.Require().NoError(s.repo.Extend(tenantCtx, pending))
+	time.Sleep(time.Second)
+
+	_, started, err = s.repo.Begin(tenantCtx, s.request("extended", time.Now().UTC().Add(time.Hour)))
+	s.Require().NoError(err)
+	s.False(started)
+}
+
+// TestKeyIsFreed checks that released and expired requests don't hold their keys
+func (s *IdempotencyRepoSuite) TestKeyIsFreed() {
+	released := s.request("released", time.Now().UTC().Add(time.Hour))
+	_, started, err := s.repo.Begin(tenantCtx, released)
+	s.Require().NoError(err)
+	s.Require().True(started)
+
+	s.Require().NoError(s.repo.Release(tenantCtx, released))
+	_, started, err = s.repo.Begin(tenantCtx, s.request("released", time.Now().UTC().Add(time.Hour)))
+	s.Require().NoError(err)
+	s.True(started)
+
+	_, started, err = s.repo.Begin(tenantCtx, s.request("expired", time.Now().UTC().Add(-time.Second)))
+	s.Require().NoError(err)
+	s.Require().True(started)
+
+	_, started, err = s.repo.Begin(tenantCtx, s.request("expired", time.Now().UTC().Add(time.Hour)))
+	s.Require().NoError(err)
+	s.True(started)
+}
@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/tenant"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+type IncidentRepo struct {
+	collection *mongo.Collection
+	tracer     trace.Tracer
+}
+
+func (i IncidentRepo) Create(ctx context.Context, incident *entities.Incident) (string, error) {
+	ctx, span := i.tracer.Start(ctx, "IncidentRepo.Create")
+	defer span.End()
+
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	incident.ID = primitive.NewObjectID()
+	incident.TenantID = tenantID
+
+	if _, err := i.collection.InsertOne(ctx, incident); err != nil {
+		span.RecordError(err)
+		return "", errors.Wrap(err, "error during create incident")
+	}
+
+	return incident.ID.Hex(), nil
+}
+
+func (i IncidentRepo) Get(ctx context.Context, id string) (entities.Incident, error) {
+	ctx, span := i.tracer.Start(ctx, "IncidentRepo.Get")
+	defer span.End()
+
+	castedID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return entities.Incident{}, invalidID(err, "incident")
+	}
+
+	filter, err := scoped(ctx, bson.M{"_id": castedID})
+	if err != nil {
+		return entities.Incident{}, err
+	}
+
+	var incident entities.Incident
+	if err := i.collection.FindOne(ctx, filter).Decode(&incident); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return entities.Incident{}, ErrIncidentNotFound
+		}
+
+		span.RecordError(err)
+		return entities.Incident{}, errors.Wrap(err, "error during get incident from db")
+	}
+
+	return incident, nil
+}
+
+// Merge adds the detection of the client to the incident unless it has been closed: the location moves to the
+// running centroid of the detections, the seen range widens and the client is added once. It's a single update,
+// so concurrent detections of the incident aren't lost
+func (i IncidentRepo) Merge(
+	ctx context.Context, id primitive.ObjectID, client entities.Client, ts time.Time,
+) (entities.Incident, error) {
+	ctx, span := i.tracer.Start(ctx, "IncidentRepo.Merge")
+	defer span.End()
+
+	filter, err := scoped(ctx, bson.M{
+		"_id":    id,
+		"status": bson.M{"$nin": entities.ClosedIncidentStatuses},
+	})
+	if err != nil {
+		return entities.Incident{}, err
+	}
+
+	// fields of the stage are computed of the document before the update
+	centroid := func(field string, value float64) bson.M {
+		return bson.M{"$divide": bson.A{
+			bson.M{"$add": bson.A{bson.M{"$multiply": bson.A{"$" + field, "$shotCount"}}, value}},
+			bson.M{"$add": bson.A{"$shotCount", 1}},
+		}}
+	}
+	clients := bson.M{"$ifNull": bson.A{"$clients", bson.A{}}}
+
+	update := bson.A{bson.M{"$set": bson.M{
+		"latitude":  centroid("latitude", client.Latitude),
+		"longitude": centroid("longitude", client.Longitude),
+		"shotCount": bson.M{"$add": bson.A{"$shotCount", 1}},
+		"firstSeen": bson.M{"$min": bson.A{"$firstSeen", ts}},
+		"lastSeen":  bson.M{"$max": bson.A{"$lastSeen", ts}},
+		"clients": bson.M{"$cond": bson.A{
+			bson.M{"$in": bson.A{client.ID, clients}},
+			clients,
+			bson.M{"$concatArrays": bson.A{clients, bson.A{client.ID}}},
+		}},
+	}}}
+
+	var incident entities.Incident
+	err = i.collection.FindOneAndUpdate(
+		ctx, filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&incident)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return entities.Incident{}, ErrIncidentNotFound
+		}
+
+		span.RecordError(err)
+		return entities.Incident{}, errors.Wrap(err, "error during merge detection into incident")
+	}
+
+	return incident, nil
+}
+
+// SetZones sets zones of the incident if no detection has been merged since it had the shot count, a later
+// merge sets zones of its own location
+func (i IncidentRepo) SetZones(
+	ctx context.Context, id primitive.ObjectID, shotCount int, zoneIDs []primitive.ObjectID,
+) error {
+	ctx, span := i.tracer.Start(ctx, "IncidentRepo.SetZones")
+	defer span.End()
+
+	filter, err := scoped(ctx, bson.M{"_id": id, "shotCount": shotCount})
+	if err != nil {
+		return err
+	}
+
+	if _, err := i.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"zoneIDs": zoneIDs}}); err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "error during set zones of incident")
+	}
+
+	return nil
+}
+
+// Transition moves the incident to the new status if it is still in the expected one
+func (i IncidentRepo) Transition(ctx context.Context, id string, transition entities.IncidentTransition) error {
+	ctx, span := i.tracer.Start(ctx, "IncidentRepo.Transition")
+	defer span.End()
+
+	castedID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return invalidID(err, "incident")
+	}
+
+	filter, err := scoped(ctx, bson.M{
+		"_id":    castedID,
+		"status": transition.From,
+	})
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{
+		"$set":  bson.M{"status": transition.To},
+		"$push": bson.M{"transitions": transition},
+	}
+
+	res := i.collection.FindOneAndUpdate(ctx, filter, update)
+	if err := res.Err(); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrIncidentStatusChanged
+		}
+
+		span.RecordError(err)
+		return errors.Wrap(err, "error during change incident status")
+	}
+
+	return nil
+}
+
+// FindActive returns not closed incidents that were seen after the passed time
+func (i IncidentRepo) FindActive(ctx context.Context, since time.Time) ([]entities.Incident, error) {
+	ctx, span := i.tracer.Start(ctx, "IncidentRepo.FindActive")
+	defer span.End()
+
+	filter, err := scoped(ctx, bson.M{
+		"lastSeen": bson.M{"$gte": since},
+		"status":   bson.M{"$nin": entities.ClosedIncidentStatuses},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := i.collection.Find(ctx, filter)
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during find active incidents")
+	}
+
+	incidents := make([]entities.Incident, 0)
+	if err := cursor.All(ctx, &incidents); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during decode incidents")
+	}
+
+	return incidents, nil
+}
+
+func (i IncidentRepo) List(ctx context.Context, filter entities.IncidentFilter) ([]entities.Incident, error) {
+	ctx, span := i.tracer.Start(ctx, "IncidentRepo.List")
+	defer span.End()
+
+	query, err := scoped(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	if !filter.ZoneID.IsZero() {
+		query["zoneIDs"] = filter.ZoneID
+	}
+	if !filter.ClientID.IsZero() {
+		query["clients"] = filter.ClientID
+	}
+	if filter.Held {
+		query["legalHold"] = bson.M{"$exists": true}
+	}
+	addTimeRange(query, "lastSeen", filter.From, filter.To)
+
+	opts := options.Find().
+		SetSort(bson.M{"lastSeen": -1}).
+		SetSkip(filter.Offset).
+		SetLimit(filter.Limit)
+
+	cursor, err := i.collection.Find(ctx, query, opts)
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during list incidents")
+	}
+
+	incidents := make([]entities.Incident, 0)
+	if err := cursor.All(ctx, &incidents); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during decode incidents")
+	}
+
+	return incidents, nil
+}
+
+// SetLegalHold places the incident under the legal hold, nil releases it
+func (i IncidentRepo) SetLegalHold(ctx context.Context, id primitive.ObjectID, hold *entities.LegalHold) error {
+	ctx, span := i.tracer.Start(ctx, "IncidentRepo.SetLegalHold")
+	defer span.End()
+
+	filter, err := scoped(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{"$set": bson.M{"legalHold": hold}}
+	if hold == nil {
+		update = bson.M{"$unset": bson.M{"legalHold": ""}}
+	}
+
+	res, err := i.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "error during set legal hold of incident")
+	}
+
+	if res.MatchedCount == 0 {
+		return ErrIncidentNotFound
+	}
+
+	return nil
+}
+
+func NewIncidentRepo(database *mongo.Database) *IncidentRepo {
+	return &IncidentRepo{
+		collection: database.Collection(_incidentsCollection),
+		tracer:     otel.Tracer("IncidentRepo"),
+	}
+}
@@ -0,0 +1,120 @@
+//go:build integration
+// +build integration
+
+package repository_test
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository"
+	"github.com/stretchr/testify/suite"
+	"github.com/testcontainers/testcontainers-go"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"sync"
+	"testing"
+	"time"
+)
+
+type IncidentRepoSuite struct {
+	suite.Suite
+	repo      *repository.IncidentRepo
+	dbClient  *mongo.Client
+	container testcontainers.Container
+}
+
+func TestIncidentRepoSuite(t *testing.T) {
+	suite.Run(t, new(IncidentRepoSuite))
+}
+
+func (s *IncidentRepoSuite) SetupSuite() {
+	s.dbClient, s.container = startMongo(&s.Suite)
+	s.repo = repository.NewIncidentRepo(s.dbClient.Database(_dbName))
+}
+
+func (s *IncidentRepoSuite) TearDownSuite() {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	s.Require().NoError(s.dbClient.Disconnect(ctx))
+	s.Require().NoError(s.container.Terminate(ctx))
+}
+
+func (s *IncidentRepoSuite) create(first entities.Client, ts time.Time) entities.Incident {
+	incident := entities.Incident{
+		Latitude:  first.Latitude,
+		Longitude: first.Longitude,
+		ShotCount: 1,
+		FirstSeen: ts,
+		LastSeen:  ts,
+		Clients:   []primitive.ObjectID{first.ID},
+		Status:    entities.IncidentNew,
+	}
+
+	_, err := s.repo.Create(tenantCtx, &incident)
+	s.Require().NoError(err)
+
+	return incident
+}
+
+func (s *IncidentRepoSuite) TestConcurrentMerge() {
+	var (
+		now      = time.Now().UTC().Truncate(time.Millisecond)
+		first    = entities.Client{ID: primitive.NewObjectID(), Latitude: 10, Longitude: 20}
+		second   = entities.Client{ID: primitive.NewObjectID(), Latitude: 20, Longitude: 40}
+		incident = s.create(first, now)
+		wg       sync.WaitGroup
+	)
+
+	for n := 0; n < 10; n++ {
+		wg.Add(1)
+
+		go func(n int) {
+			defer wg.Done()
+
+			_, err := s.repo.Merge(tenantCtx, incident.ID, second, now.Add(time.Duration(n-5)*time.Second))
+			s.NoError(err)
+		}(n)
+	}
+
+	wg.Wait()
+
+	got, err := s.repo.Get(tenantCtx, incident.ID.Hex())
+	s.Require().NoError(err)
+
+	s.Equal(11, got.ShotCount)
+	s.Equal([]primitive.ObjectID{first.ID, second.ID}, got.Clients)
+	s.InDelta((10+20*10)/11.0, got.Latitude, 1e-9)
+	s.InDelta((20+40*10)/11.0, got.Longitude, 1e-9)
+	s.Equal(now.Add(-5*time.Second), got.FirstSeen.UTC())
+	s.Equal(now.Add(4*time.Second), got.LastSeen.UTC())
+}
+
+func (s *IncidentRepoSuite) TestMergeClosed() {
+	client := entities.Client{ID: primitive.NewObjectID(), Latitude: 10, Longitude: 20}
+	incident := s.create(client, time.Now().UTC())
+
+	s.Require().NoError(s.repo.Transition(tenantCtx, incident.ID.Hex(), entities.IncidentTransition{
+		From: entities.IncidentNew, To: entities.IncidentFalsePositive,
+	}))
+
+	_, err := s.repo.Merge(tenantCtx, incident.ID, client, time.Now().UTC())
+	s.ErrorIs(err, repository.ErrIncidentNotFound)
+}
+
+func (s *IncidentRepoSuite) TestSetZones() {
+	client := entities.Client{ID: primitive.NewObjectID(), Latitude: 10, Longitude: 20}
+	incident := s.create(client, time.Now().UTC())
+
+	merged, err := s.repo.Merge(tenantCtx, incident.ID, client, time.Now().UTC())
+	s.Require().NoError(err)
+
+	// the zones of the location before the merge are stale
+	stale, fresh := []primitive.ObjectID{primitive.NewObjectID()}, []primitive.ObjectID{primitive.NewObjectID()}
+	s.Require().NoError(s.repo.SetZones(tenantCtx, incident.ID, incident.ShotCount, stale))
+	s.Require().NoError(s.repo.SetZones(tenantCtx, incident.ID, merged.ShotCount, fresh))
+
+	got, err := s.repo.Get(tenantCtx, incident.ID.Hex())
+	s.Require().NoError(err)
+	s.Equal(fresh, got.ZoneIDs)
+}
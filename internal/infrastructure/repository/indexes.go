@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EnsureIndexes creates indexes the retention and the hash chain rely on. Detections and heartbeats are
+// removed by TTL indexes on expiresAt (documents without the field are kept); blobs are removed by the
+// sweeper, since the store of the audio is not necessarily the database. The sequence of chain records is
+// unique per client, so concurrent uploads can't fork the chain. Idempotency keys are unique per tenant and
+// expire by the TTL index, so do counters of uploads
+func EnsureIndexes(ctx context.Context, database *mongo.Database) error {
+	ttl := options.Index().SetExpireAfterSeconds(0).SetName("retention_ttl")
+
+	indexes := map[string][]mongo.IndexModel{
+		_detectionsCollection: {{Keys: bson.D{{Key: "expiresAt", Value: 1}}, Options: ttl}},
+		_heartbeatsCollection: {{Keys: bson.D{{Key: "expiresAt", Value: 1}}, Options: ttl}},
+		_blobsCollection: {{
+			Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+			Options: options.Index().SetName("retention_sweep").SetSparse(true),
+		}},
+		_chainCollection: {
+			{
+				Keys:    bson.D{{Key: "clientID", Value: 1}, {Key: "sequence", Value: 1}},
+				Options: options.Index().SetName("chain_sequence").SetUnique(true),
+			},
+			{
+				Keys:    bson.D{{Key: "clientID", Value: 1}, {Key: "payloadHash", Value: 1}},
+				Options: options.Index().SetName("chain_upload"),
+			},
+		},
+		_idempotencyCollection: {
+			{
+				Keys:    bson.D{{Key: "tenantID", Value: 1}, {Key: "key", Value: 1}},
+				Options: options.Index().SetName("idempotency_key").SetUnique(true),
+			},
+			{Keys: bson.D{{Key: "expiresAt", Value: 1}}, Options: ttl},
+		},
+		_uploadCountersCollection: {
+			{
+				Keys:    bson.D{{Key: "tenantID", Value: 1}, {Key: "minute", Value: 1}},
+				Options: options.Index().SetName("upload_counter").SetUnique(true),
+			},
+			{Keys: bson.D{{Key: "expiresAt", Value: 1}}, Options: ttl},
+		},
+	}
+
+	for collection, models := range indexes {
+		if _, err := database.Collection(collection).Indexes().CreateMany(ctx, models); err != nil {
+			return errors.Wrapf(err, "error during create indexes of %s", collection)
+		}
+	}
+
+	return nil
+}
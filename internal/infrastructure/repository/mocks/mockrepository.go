@@ -7,9 +7,11 @@ package mock_repository
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	entities "github.com/Imm0bilize/gunshot-api-service/internal/entities"
 	gomock "github.com/golang/mock/gomock"
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // MockClientRepository is a mock of ClientRepository interface.
@@ -35,6 +37,49 @@ func (m *MockClientRepository) EXPECT() *MockClientRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AddKey mocks base method.
+func (m *MockClientRepository) AddKey(ctx context.Context, id primitive.ObjectID, key entities.DeviceKey, expireBy time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddKey", ctx, id, key, expireBy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddKey indicates an expected call of AddKey.
+func (mr *MockClientRepositoryMockRecorder) AddKey(ctx, id, key, expireBy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddKey", reflect.TypeOf((*MockClientRepository)(nil).AddKey), ctx, id, key, expireBy)
+}
+
+// AdvanceChain mocks base method.
+func (m *MockClientRepository) AdvanceChain(ctx context.Context, id primitive.ObjectID, from, to entities.ChainHead) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdvanceChain", ctx, id, from, to)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AdvanceChain indicates an expected call of AdvanceChain.
+func (mr *MockClientRepositoryMockRecorder) AdvanceChain(ctx, id, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdvanceChain", reflect.TypeOf((*MockClientRepository)(nil).AdvanceChain), ctx, id, from, to)
+}
+
+// Count mocks base method.
+func (m *MockClientRepository) Count(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count.
+func (mr *MockClientRepositoryMockRecorder) Count(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockClientRepository)(nil).Count), ctx)
+}
+
 // Create mocks base method.
 func (m *MockClientRepository) Create(ctx context.Context, client *entities.Client) (string, error) {
 	m.ctrl.T.Helper()
@@ -50,18 +95,35 @@ func (mr *MockClientRepositoryMockRecorder) Create(ctx, client interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockClientRepository)(nil).Create), ctx, client)
 }
 
+// CreateMany mocks base method.
+func (m *MockClientRepository) CreateMany(ctx context.Context, clients []*entities.Client) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMany", ctx, clients)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMany indicates an expected call of CreateMany.
+func (mr *MockClientRepositoryMockRecorder) CreateMany(ctx, clients interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMany", reflect.TypeOf((*MockClientRepository)(nil).CreateMany), ctx, clients)
+}
+
 // Delete mocks base method.
-func (m *MockClientRepository) Delete(ctx context.Context, id string) error {
+func (m *MockClientRepository) Delete(ctx context.Context, id string, version int64) (entities.Client, entities.Client, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", ctx, id)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "Delete", ctx, id, version)
+	ret0, _ := ret[0].(entities.Client)
+	ret1, _ := ret[1].(entities.Client)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockClientRepositoryMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+func (mr *MockClientRepositoryMockRecorder) Delete(ctx, id, version interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockClientRepository)(nil).Delete), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockClientRepository)(nil).Delete), ctx, id, version)
 }
 
 // Get mocks base method.
@@ -79,16 +141,1772 @@ func (mr *MockClientRepositoryMockRecorder) Get(ctx, id interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockClientRepository)(nil).Get), ctx, id)
 }
 
-// Update mocks base method.
-func (m *MockClientRepository) Update(ctx context.Context, id string, client *entities.Client) error {
+// GetWithDeleted mocks base method.
+func (m *MockClientRepository) GetWithDeleted(ctx context.Context, id string) (entities.Client, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWithDeleted", ctx, id)
+	ret0, _ := ret[0].(entities.Client)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWithDeleted indicates an expected call of GetWithDeleted.
+func (mr *MockClientRepositoryMockRecorder) GetWithDeleted(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithDeleted", reflect.TypeOf((*MockClientRepository)(nil).GetWithDeleted), ctx, id)
+}
+
+// List mocks base method.
+func (m *MockClientRepository) List(ctx context.Context) ([]entities.Client, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx)
+	ret0, _ := ret[0].([]entities.Client)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockClientRepositoryMockRecorder) List(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockClientRepository)(nil).List), ctx)
+}
+
+// Page mocks base method.
+func (m *MockClientRepository) Page(ctx context.Context, filter entities.ClientFilter) ([]entities.Client, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Page", ctx, filter)
+	ret0, _ := ret[0].([]entities.Client)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Page indicates an expected call of Page.
+func (mr *MockClientRepositoryMockRecorder) Page(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Page", reflect.TypeOf((*MockClientRepository)(nil).Page), ctx, filter)
+}
+
+// Patch mocks base method.
+func (m *MockClientRepository) Patch(ctx context.Context, id string, version int64, patch entities.ClientPatch) (entities.Client, entities.Client, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Patch", ctx, id, version, patch)
+	ret0, _ := ret[0].(entities.Client)
+	ret1, _ := ret[1].(entities.Client)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Patch indicates an expected call of Patch.
+func (mr *MockClientRepositoryMockRecorder) Patch(ctx, id, version, patch interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Patch", reflect.TypeOf((*MockClientRepository)(nil).Patch), ctx, id, version, patch)
+}
+
+// Purge mocks base method.
+func (m *MockClientRepository) Purge(ctx context.Context, before time.Time) ([]primitive.ObjectID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, before)
+	ret0, _ := ret[0].([]primitive.ObjectID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockClientRepositoryMockRecorder) Purge(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockClientRepository)(nil).Purge), ctx, before)
+}
+
+// Restore mocks base method.
+func (m *MockClientRepository) Restore(ctx context.Context, id primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockClientRepositoryMockRecorder) Restore(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockClientRepository)(nil).Restore), ctx, id)
+}
+
+// RevokeKey mocks base method.
+func (m *MockClientRepository) RevokeKey(ctx context.Context, id primitive.ObjectID, keyID string, at time.Time) (entities.DeviceKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeKey", ctx, id, keyID, at)
+	ret0, _ := ret[0].(entities.DeviceKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeKey indicates an expected call of RevokeKey.
+func (mr *MockClientRepositoryMockRecorder) RevokeKey(ctx, id, keyID, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeKey", reflect.TypeOf((*MockClientRepository)(nil).RevokeKey), ctx, id, keyID, at)
+}
+
+// SetAppliedConfig mocks base method.
+func (m *MockClientRepository) SetAppliedConfig(ctx context.Context, id primitive.ObjectID, version int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAppliedConfig", ctx, id, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetAppliedConfig indicates an expected call of SetAppliedConfig.
+func (mr *MockClientRepositoryMockRecorder) SetAppliedConfig(ctx, id, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAppliedConfig", reflect.TypeOf((*MockClientRepository)(nil).SetAppliedConfig), ctx, id, version)
+}
+
+// SetClock mocks base method.
+func (m *MockClientRepository) SetClock(ctx context.Context, id primitive.ObjectID, clock entities.ClockState) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetClock", ctx, id, clock)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetClock indicates an expected call of SetClock.
+func (mr *MockClientRepositoryMockRecorder) SetClock(ctx, id, clock interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetClock", reflect.TypeOf((*MockClientRepository)(nil).SetClock), ctx, id, clock)
+}
+
+// SetHealth mocks base method.
+func (m *MockClientRepository) SetHealth(ctx context.Context, id primitive.ObjectID, health entities.ClientHealth) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHealth", ctx, id, health)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHealth indicates an expected call of SetHealth.
+func (mr *MockClientRepositoryMockRecorder) SetHealth(ctx, id, health interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHealth", reflect.TypeOf((*MockClientRepository)(nil).SetHealth), ctx, id, health)
+}
+
+// SetHealthStatus mocks base method.
+func (m *MockClientRepository) SetHealthStatus(ctx context.Context, id primitive.ObjectID, lastSeen time.Time, status entities.HealthStatus) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHealthStatus", ctx, id, lastSeen, status)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetHealthStatus indicates an expected call of SetHealthStatus.
+func (mr *MockClientRepositoryMockRecorder) SetHealthStatus(ctx, id, lastSeen, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHealthStatus", reflect.TypeOf((*MockClientRepository)(nil).SetHealthStatus), ctx, id, lastSeen, status)
+}
+
+// SetZones mocks base method.
+func (m *MockClientRepository) SetZones(ctx context.Context, id primitive.ObjectID, zoneIDs []primitive.ObjectID) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", ctx, id, client)
+	ret := m.ctrl.Call(m, "SetZones", ctx, id, zoneIDs)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
+// SetZones indicates an expected call of SetZones.
+func (mr *MockClientRepositoryMockRecorder) SetZones(ctx, id, zoneIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetZones", reflect.TypeOf((*MockClientRepository)(nil).SetZones), ctx, id, zoneIDs)
+}
+
+// Update mocks base method.
+func (m *MockClientRepository) Update(ctx context.Context, id string, version int64, client *entities.Client) (entities.Client, entities.Client, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, id, version, client)
+	ret0, _ := ret[0].(entities.Client)
+	ret1, _ := ret[1].(entities.Client)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
 // Update indicates an expected call of Update.
-func (mr *MockClientRepositoryMockRecorder) Update(ctx, id, client interface{}) *gomock.Call {
+func (mr *MockClientRepositoryMockRecorder) Update(ctx, id, version, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockClientRepository)(nil).Update), ctx, id, version, client)
+}
+
+// MockClientVersionRepository is a mock of ClientVersionRepository interface.
+type MockClientVersionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockClientVersionRepositoryMockRecorder
+}
+
+// MockClientVersionRepositoryMockRecorder is the mock recorder for MockClientVersionRepository.
+type MockClientVersionRepositoryMockRecorder struct {
+	mock *MockClientVersionRepository
+}
+
+// NewMockClientVersionRepository creates a new mock instance.
+func NewMockClientVersionRepository(ctrl *gomock.Controller) *MockClientVersionRepository {
+	mock := &MockClientVersionRepository{ctrl: ctrl}
+	mock.recorder = &MockClientVersionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClientVersionRepository) EXPECT() *MockClientVersionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockClientVersionRepository) Create(ctx context.Context, version *entities.ClientVersion) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, version)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockClientVersionRepositoryMockRecorder) Create(ctx, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockClientVersionRepository)(nil).Create), ctx, version)
+}
+
+// DeleteForClients mocks base method.
+func (m *MockClientVersionRepository) DeleteForClients(ctx context.Context, clientIDs []primitive.ObjectID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteForClients", ctx, clientIDs)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteForClients indicates an expected call of DeleteForClients.
+func (mr *MockClientVersionRepositoryMockRecorder) DeleteForClients(ctx, clientIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteForClients", reflect.TypeOf((*MockClientVersionRepository)(nil).DeleteForClients), ctx, clientIDs)
+}
+
+// List mocks base method.
+func (m *MockClientVersionRepository) List(ctx context.Context, clientID primitive.ObjectID) ([]entities.ClientVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, clientID)
+	ret0, _ := ret[0].([]entities.ClientVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockClientVersionRepositoryMockRecorder) List(ctx, clientID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockClientVersionRepository)(nil).List), ctx, clientID)
+}
+
+// MockIncidentRepository is a mock of IncidentRepository interface.
+type MockIncidentRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIncidentRepositoryMockRecorder
+}
+
+// MockIncidentRepositoryMockRecorder is the mock recorder for MockIncidentRepository.
+type MockIncidentRepositoryMockRecorder struct {
+	mock *MockIncidentRepository
+}
+
+// NewMockIncidentRepository creates a new mock instance.
+func NewMockIncidentRepository(ctrl *gomock.Controller) *MockIncidentRepository {
+	mock := &MockIncidentRepository{ctrl: ctrl}
+	mock.recorder = &MockIncidentRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIncidentRepository) EXPECT() *MockIncidentRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockIncidentRepository) Create(ctx context.Context, incident *entities.Incident) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, incident)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockIncidentRepositoryMockRecorder) Create(ctx, incident interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockIncidentRepository)(nil).Create), ctx, incident)
+}
+
+// FindActive mocks base method.
+func (m *MockIncidentRepository) FindActive(ctx context.Context, since time.Time) ([]entities.Incident, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindActive", ctx, since)
+	ret0, _ := ret[0].([]entities.Incident)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindActive indicates an expected call of FindActive.
+func (mr *MockIncidentRepositoryMockRecorder) FindActive(ctx, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindActive", reflect.TypeOf((*MockIncidentRepository)(nil).FindActive), ctx, since)
+}
+
+// Get mocks base method.
+func (m *MockIncidentRepository) Get(ctx context.Context, id string) (entities.Incident, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(entities.Incident)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockIncidentRepositoryMockRecorder) Get(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockIncidentRepository)(nil).Get), ctx, id)
+}
+
+// List mocks base method.
+func (m *MockIncidentRepository) List(ctx context.Context, filter entities.IncidentFilter) ([]entities.Incident, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, filter)
+	ret0, _ := ret[0].([]entities.Incident)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockIncidentRepositoryMockRecorder) List(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockIncidentRepository)(nil).List), ctx, filter)
+}
+
+// Merge mocks base method.
+func (m *MockIncidentRepository) Merge(ctx context.Context, id primitive.ObjectID, client entities.Client, ts time.Time) (entities.Incident, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Merge", ctx, id, client, ts)
+	ret0, _ := ret[0].(entities.Incident)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Merge indicates an expected call of Merge.
+func (mr *MockIncidentRepositoryMockRecorder) Merge(ctx, id, client, ts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Merge", reflect.TypeOf((*MockIncidentRepository)(nil).Merge), ctx, id, client, ts)
+}
+
+// SetLegalHold mocks base method.
+func (m *MockIncidentRepository) SetLegalHold(ctx context.Context, id primitive.ObjectID, hold *entities.LegalHold) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLegalHold", ctx, id, hold)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetLegalHold indicates an expected call of SetLegalHold.
+func (mr *MockIncidentRepositoryMockRecorder) SetLegalHold(ctx, id, hold interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLegalHold", reflect.TypeOf((*MockIncidentRepository)(nil).SetLegalHold), ctx, id, hold)
+}
+
+// SetZones mocks base method.
+func (m *MockIncidentRepository) SetZones(ctx context.Context, id primitive.ObjectID, shotCount int, zoneIDs []primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetZones", ctx, id, shotCount, zoneIDs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetZones indicates an expected call of SetZones.
+func (mr *MockIncidentRepositoryMockRecorder) SetZones(ctx, id, shotCount, zoneIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetZones", reflect.TypeOf((*MockIncidentRepository)(nil).SetZones), ctx, id, shotCount, zoneIDs)
+}
+
+// Transition mocks base method.
+func (m *MockIncidentRepository) Transition(ctx context.Context, id string, transition entities.IncidentTransition) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transition", ctx, id, transition)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Transition indicates an expected call of Transition.
+func (mr *MockIncidentRepositoryMockRecorder) Transition(ctx, id, transition interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transition", reflect.TypeOf((*MockIncidentRepository)(nil).Transition), ctx, id, transition)
+}
+
+// MockDetectionRepository is a mock of DetectionRepository interface.
+type MockDetectionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockDetectionRepositoryMockRecorder
+}
+
+// MockDetectionRepositoryMockRecorder is the mock recorder for MockDetectionRepository.
+type MockDetectionRepositoryMockRecorder struct {
+	mock *MockDetectionRepository
+}
+
+// NewMockDetectionRepository creates a new mock instance.
+func NewMockDetectionRepository(ctrl *gomock.Controller) *MockDetectionRepository {
+	mock := &MockDetectionRepository{ctrl: ctrl}
+	mock.recorder = &MockDetectionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDetectionRepository) EXPECT() *MockDetectionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Count mocks base method.
+func (m *MockDetectionRepository) Count(ctx context.Context, clientID primitive.ObjectID, from, to time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", ctx, clientID, from, to)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count.
+func (mr *MockDetectionRepositoryMockRecorder) Count(ctx, clientID, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockDetectionRepository)(nil).Count), ctx, clientID, from, to)
+}
+
+// CountExpiring mocks base method.
+func (m *MockDetectionRepository) CountExpiring(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountExpiring", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountExpiring indicates an expected call of CountExpiring.
+func (mr *MockDetectionRepositoryMockRecorder) CountExpiring(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountExpiring", reflect.TypeOf((*MockDetectionRepository)(nil).CountExpiring), ctx, before)
+}
+
+// Create mocks base method.
+func (m *MockDetectionRepository) Create(ctx context.Context, detection *entities.Detection) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, detection)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockDetectionRepositoryMockRecorder) Create(ctx, detection interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockDetectionRepository)(nil).Create), ctx, detection)
+}
+
+// Hold mocks base method.
+func (m *MockDetectionRepository) Hold(ctx context.Context, incidentID primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Hold", ctx, incidentID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Hold indicates an expected call of Hold.
+func (mr *MockDetectionRepositoryMockRecorder) Hold(ctx, incidentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hold", reflect.TypeOf((*MockDetectionRepository)(nil).Hold), ctx, incidentID)
+}
+
+// List mocks base method.
+func (m *MockDetectionRepository) List(ctx context.Context, filter entities.DetectionFilter) ([]entities.Detection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, filter)
+	ret0, _ := ret[0].([]entities.Detection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockDetectionRepositoryMockRecorder) List(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockDetectionRepository)(nil).List), ctx, filter)
+}
+
+// MarkFalsePositive mocks base method.
+func (m *MockDetectionRepository) MarkFalsePositive(ctx context.Context, incidentID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkFalsePositive", ctx, incidentID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkFalsePositive indicates an expected call of MarkFalsePositive.
+func (mr *MockDetectionRepositoryMockRecorder) MarkFalsePositive(ctx, incidentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkFalsePositive", reflect.TypeOf((*MockDetectionRepository)(nil).MarkFalsePositive), ctx, incidentID)
+}
+
+// Release mocks base method.
+func (m *MockDetectionRepository) Release(ctx context.Context, incidentID primitive.ObjectID, period time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Release", ctx, incidentID, period)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockDetectionRepositoryMockRecorder) Release(ctx, incidentID, period interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockDetectionRepository)(nil).Release), ctx, incidentID, period)
+}
+
+// MockAlertRuleRepository is a mock of AlertRuleRepository interface.
+type MockAlertRuleRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAlertRuleRepositoryMockRecorder
+}
+
+// MockAlertRuleRepositoryMockRecorder is the mock recorder for MockAlertRuleRepository.
+type MockAlertRuleRepositoryMockRecorder struct {
+	mock *MockAlertRuleRepository
+}
+
+// NewMockAlertRuleRepository creates a new mock instance.
+func NewMockAlertRuleRepository(ctrl *gomock.Controller) *MockAlertRuleRepository {
+	mock := &MockAlertRuleRepository{ctrl: ctrl}
+	mock.recorder = &MockAlertRuleRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAlertRuleRepository) EXPECT() *MockAlertRuleRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockAlertRuleRepository) Create(ctx context.Context, rule *entities.AlertRule) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, rule)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockAlertRuleRepositoryMockRecorder) Create(ctx, rule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAlertRuleRepository)(nil).Create), ctx, rule)
+}
+
+// Delete mocks base method.
+func (m *MockAlertRuleRepository) Delete(ctx context.Context, id string) (entities.AlertRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(entities.AlertRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockAlertRuleRepositoryMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockAlertRuleRepository)(nil).Delete), ctx, id)
+}
+
+// FindForClient mocks base method.
+func (m *MockAlertRuleRepository) FindForClient(ctx context.Context, clientID primitive.ObjectID, zoneIDs []primitive.ObjectID) ([]entities.AlertRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindForClient", ctx, clientID, zoneIDs)
+	ret0, _ := ret[0].([]entities.AlertRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindForClient indicates an expected call of FindForClient.
+func (mr *MockAlertRuleRepositoryMockRecorder) FindForClient(ctx, clientID, zoneIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindForClient", reflect.TypeOf((*MockAlertRuleRepository)(nil).FindForClient), ctx, clientID, zoneIDs)
+}
+
+// Get mocks base method.
+func (m *MockAlertRuleRepository) Get(ctx context.Context, id string) (entities.AlertRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(entities.AlertRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockAlertRuleRepositoryMockRecorder) Get(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockAlertRuleRepository)(nil).Get), ctx, id)
+}
+
+// List mocks base method.
+func (m *MockAlertRuleRepository) List(ctx context.Context) ([]entities.AlertRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx)
+	ret0, _ := ret[0].([]entities.AlertRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockAlertRuleRepositoryMockRecorder) List(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAlertRuleRepository)(nil).List), ctx)
+}
+
+// Update mocks base method.
+func (m *MockAlertRuleRepository) Update(ctx context.Context, id string, rule *entities.AlertRule) (entities.AlertRule, entities.AlertRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, id, rule)
+	ret0, _ := ret[0].(entities.AlertRule)
+	ret1, _ := ret[1].(entities.AlertRule)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockAlertRuleRepositoryMockRecorder) Update(ctx, id, rule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockAlertRuleRepository)(nil).Update), ctx, id, rule)
+}
+
+// MockAlertRepository is a mock of AlertRepository interface.
+type MockAlertRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAlertRepositoryMockRecorder
+}
+
+// MockAlertRepositoryMockRecorder is the mock recorder for MockAlertRepository.
+type MockAlertRepositoryMockRecorder struct {
+	mock *MockAlertRepository
+}
+
+// NewMockAlertRepository creates a new mock instance.
+func NewMockAlertRepository(ctrl *gomock.Controller) *MockAlertRepository {
+	mock := &MockAlertRepository{ctrl: ctrl}
+	mock.recorder = &MockAlertRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAlertRepository) EXPECT() *MockAlertRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockAlertRepository) Create(ctx context.Context, alert *entities.Alert) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, alert)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockAlertRepositoryMockRecorder) Create(ctx, alert interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAlertRepository)(nil).Create), ctx, alert)
+}
+
+// List mocks base method.
+func (m *MockAlertRepository) List(ctx context.Context, filter entities.AlertFilter) ([]entities.Alert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, filter)
+	ret0, _ := ret[0].([]entities.Alert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockAlertRepositoryMockRecorder) List(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAlertRepository)(nil).List), ctx, filter)
+}
+
+// MockZoneRepository is a mock of ZoneRepository interface.
+type MockZoneRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockZoneRepositoryMockRecorder
+}
+
+// MockZoneRepositoryMockRecorder is the mock recorder for MockZoneRepository.
+type MockZoneRepositoryMockRecorder struct {
+	mock *MockZoneRepository
+}
+
+// NewMockZoneRepository creates a new mock instance.
+func NewMockZoneRepository(ctrl *gomock.Controller) *MockZoneRepository {
+	mock := &MockZoneRepository{ctrl: ctrl}
+	mock.recorder = &MockZoneRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockZoneRepository) EXPECT() *MockZoneRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockZoneRepository) Create(ctx context.Context, zone *entities.Zone) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, zone)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockZoneRepositoryMockRecorder) Create(ctx, zone interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockZoneRepository)(nil).Create), ctx, zone)
+}
+
+// Delete mocks base method.
+func (m *MockZoneRepository) Delete(ctx context.Context, id string) (entities.Zone, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(entities.Zone)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockZoneRepositoryMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockZoneRepository)(nil).Delete), ctx, id)
+}
+
+// Get mocks base method.
+func (m *MockZoneRepository) Get(ctx context.Context, id string) (entities.Zone, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(entities.Zone)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockZoneRepositoryMockRecorder) Get(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockZoneRepository)(nil).Get), ctx, id)
+}
+
+// List mocks base method.
+func (m *MockZoneRepository) List(ctx context.Context) ([]entities.Zone, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx)
+	ret0, _ := ret[0].([]entities.Zone)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockZoneRepositoryMockRecorder) List(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockZoneRepository)(nil).List), ctx)
+}
+
+// Update mocks base method.
+func (m *MockZoneRepository) Update(ctx context.Context, id string, zone *entities.Zone) (entities.Zone, entities.Zone, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, id, zone)
+	ret0, _ := ret[0].(entities.Zone)
+	ret1, _ := ret[1].(entities.Zone)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockZoneRepositoryMockRecorder) Update(ctx, id, zone interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockZoneRepository)(nil).Update), ctx, id, zone)
+}
+
+// MockHeartbeatRepository is a mock of HeartbeatRepository interface.
+type MockHeartbeatRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockHeartbeatRepositoryMockRecorder
+}
+
+// MockHeartbeatRepositoryMockRecorder is the mock recorder for MockHeartbeatRepository.
+type MockHeartbeatRepositoryMockRecorder struct {
+	mock *MockHeartbeatRepository
+}
+
+// NewMockHeartbeatRepository creates a new mock instance.
+func NewMockHeartbeatRepository(ctrl *gomock.Controller) *MockHeartbeatRepository {
+	mock := &MockHeartbeatRepository{ctrl: ctrl}
+	mock.recorder = &MockHeartbeatRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHeartbeatRepository) EXPECT() *MockHeartbeatRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CountExpiring mocks base method.
+func (m *MockHeartbeatRepository) CountExpiring(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountExpiring", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountExpiring indicates an expected call of CountExpiring.
+func (mr *MockHeartbeatRepositoryMockRecorder) CountExpiring(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountExpiring", reflect.TypeOf((*MockHeartbeatRepository)(nil).CountExpiring), ctx, before)
+}
+
+// Create mocks base method.
+func (m *MockHeartbeatRepository) Create(ctx context.Context, heartbeat *entities.Heartbeat) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, heartbeat)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockHeartbeatRepositoryMockRecorder) Create(ctx, heartbeat interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockHeartbeatRepository)(nil).Create), ctx, heartbeat)
+}
+
+// List mocks base method.
+func (m *MockHeartbeatRepository) List(ctx context.Context, filter entities.HeartbeatFilter) ([]entities.Heartbeat, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, filter)
+	ret0, _ := ret[0].([]entities.Heartbeat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockHeartbeatRepositoryMockRecorder) List(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockHeartbeatRepository)(nil).List), ctx, filter)
+}
+
+// MockConfigRepository is a mock of ConfigRepository interface.
+type MockConfigRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockConfigRepositoryMockRecorder
+}
+
+// MockConfigRepositoryMockRecorder is the mock recorder for MockConfigRepository.
+type MockConfigRepositoryMockRecorder struct {
+	mock *MockConfigRepository
+}
+
+// NewMockConfigRepository creates a new mock instance.
+func NewMockConfigRepository(ctrl *gomock.Controller) *MockConfigRepository {
+	mock := &MockConfigRepository{ctrl: ctrl}
+	mock.recorder = &MockConfigRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockConfigRepository) EXPECT() *MockConfigRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindForClient mocks base method.
+func (m *MockConfigRepository) FindForClient(ctx context.Context, clientID primitive.ObjectID, zoneIDs []primitive.ObjectID) ([]entities.ConfigDocument, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindForClient", ctx, clientID, zoneIDs)
+	ret0, _ := ret[0].([]entities.ConfigDocument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindForClient indicates an expected call of FindForClient.
+func (mr *MockConfigRepositoryMockRecorder) FindForClient(ctx, clientID, zoneIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindForClient", reflect.TypeOf((*MockConfigRepository)(nil).FindForClient), ctx, clientID, zoneIDs)
+}
+
+// Get mocks base method.
+func (m *MockConfigRepository) Get(ctx context.Context, scope entities.ConfigScope, ownerID primitive.ObjectID) (entities.ConfigDocument, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, scope, ownerID)
+	ret0, _ := ret[0].(entities.ConfigDocument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockConfigRepositoryMockRecorder) Get(ctx, scope, ownerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockConfigRepository)(nil).Get), ctx, scope, ownerID)
+}
+
+// Save mocks base method.
+func (m *MockConfigRepository) Save(ctx context.Context, scope entities.ConfigScope, ownerID primitive.ObjectID, config entities.SensorConfig) (entities.ConfigDocument, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, scope, ownerID, config)
+	ret0, _ := ret[0].(entities.ConfigDocument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockConfigRepositoryMockRecorder) Save(ctx, scope, ownerID, config interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockConfigRepository)(nil).Save), ctx, scope, ownerID, config)
+}
+
+// MockCommandRepository is a mock of CommandRepository interface.
+type MockCommandRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCommandRepositoryMockRecorder
+}
+
+// MockCommandRepositoryMockRecorder is the mock recorder for MockCommandRepository.
+type MockCommandRepositoryMockRecorder struct {
+	mock *MockCommandRepository
+}
+
+// NewMockCommandRepository creates a new mock instance.
+func NewMockCommandRepository(ctrl *gomock.Controller) *MockCommandRepository {
+	mock := &MockCommandRepository{ctrl: ctrl}
+	mock.recorder = &MockCommandRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCommandRepository) EXPECT() *MockCommandRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Acknowledge mocks base method.
+func (m *MockCommandRepository) Acknowledge(ctx context.Context, clientID, id primitive.ObjectID, at time.Time) (entities.Command, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Acknowledge", ctx, clientID, id, at)
+	ret0, _ := ret[0].(entities.Command)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Acknowledge indicates an expected call of Acknowledge.
+func (mr *MockCommandRepositoryMockRecorder) Acknowledge(ctx, clientID, id, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Acknowledge", reflect.TypeOf((*MockCommandRepository)(nil).Acknowledge), ctx, clientID, id, at)
+}
+
+// Claim mocks base method.
+func (m *MockCommandRepository) Claim(ctx context.Context, clientID primitive.ObjectID, now time.Time) ([]entities.Command, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Claim", ctx, clientID, now)
+	ret0, _ := ret[0].([]entities.Command)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Claim indicates an expected call of Claim.
+func (mr *MockCommandRepositoryMockRecorder) Claim(ctx, clientID, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Claim", reflect.TypeOf((*MockCommandRepository)(nil).Claim), ctx, clientID, now)
+}
+
+// Complete mocks base method.
+func (m *MockCommandRepository) Complete(ctx context.Context, clientID, id primitive.ObjectID, result entities.CommandResult, at time.Time) (entities.Command, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", ctx, clientID, id, result, at)
+	ret0, _ := ret[0].(entities.Command)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Complete indicates an expected call of Complete.
+func (mr *MockCommandRepositoryMockRecorder) Complete(ctx, clientID, id, result, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockCommandRepository)(nil).Complete), ctx, clientID, id, result, at)
+}
+
+// Create mocks base method.
+func (m *MockCommandRepository) Create(ctx context.Context, command *entities.Command) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, command)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockCommandRepositoryMockRecorder) Create(ctx, command interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCommandRepository)(nil).Create), ctx, command)
+}
+
+// Expire mocks base method.
+func (m *MockCommandRepository) Expire(ctx context.Context, now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Expire", ctx, now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Expire indicates an expected call of Expire.
+func (mr *MockCommandRepositoryMockRecorder) Expire(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Expire", reflect.TypeOf((*MockCommandRepository)(nil).Expire), ctx, now)
+}
+
+// Get mocks base method.
+func (m *MockCommandRepository) Get(ctx context.Context, clientID, id primitive.ObjectID) (entities.Command, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, clientID, id)
+	ret0, _ := ret[0].(entities.Command)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockCommandRepositoryMockRecorder) Get(ctx, clientID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockCommandRepository)(nil).Get), ctx, clientID, id)
+}
+
+// List mocks base method.
+func (m *MockCommandRepository) List(ctx context.Context, filter entities.CommandFilter) ([]entities.Command, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, filter)
+	ret0, _ := ret[0].([]entities.Command)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockCommandRepositoryMockRecorder) List(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockCommandRepository)(nil).List), ctx, filter)
+}
+
+// Redeliver mocks base method.
+func (m *MockCommandRepository) Redeliver(ctx context.Context, deliveredBefore, now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Redeliver", ctx, deliveredBefore, now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Redeliver indicates an expected call of Redeliver.
+func (mr *MockCommandRepositoryMockRecorder) Redeliver(ctx, deliveredBefore, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Redeliver", reflect.TypeOf((*MockCommandRepository)(nil).Redeliver), ctx, deliveredBefore, now)
+}
+
+// MockOrganizationRepository is a mock of OrganizationRepository interface.
+type MockOrganizationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrganizationRepositoryMockRecorder
+}
+
+// MockOrganizationRepositoryMockRecorder is the mock recorder for MockOrganizationRepository.
+type MockOrganizationRepositoryMockRecorder struct {
+	mock *MockOrganizationRepository
+}
+
+// NewMockOrganizationRepository creates a new mock instance.
+func NewMockOrganizationRepository(ctrl *gomock.Controller) *MockOrganizationRepository {
+	mock := &MockOrganizationRepository{ctrl: ctrl}
+	mock.recorder = &MockOrganizationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrganizationRepository) EXPECT() *MockOrganizationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockOrganizationRepository) Create(ctx context.Context, organization *entities.Organization) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, organization)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockOrganizationRepositoryMockRecorder) Create(ctx, organization interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockOrganizationRepository)(nil).Create), ctx, organization)
+}
+
+// Get mocks base method.
+func (m *MockOrganizationRepository) Get(ctx context.Context, id string) (entities.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(entities.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockOrganizationRepositoryMockRecorder) Get(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockOrganizationRepository)(nil).Get), ctx, id)
+}
+
+// GetByKeyHash mocks base method.
+func (m *MockOrganizationRepository) GetByKeyHash(ctx context.Context, hash string) (entities.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByKeyHash", ctx, hash)
+	ret0, _ := ret[0].(entities.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByKeyHash indicates an expected call of GetByKeyHash.
+func (mr *MockOrganizationRepositoryMockRecorder) GetByKeyHash(ctx, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByKeyHash", reflect.TypeOf((*MockOrganizationRepository)(nil).GetByKeyHash), ctx, hash)
+}
+
+// InitSensors mocks base method.
+func (m *MockOrganizationRepository) InitSensors(ctx context.Context, id primitive.ObjectID, count int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InitSensors", ctx, id, count)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InitSensors indicates an expected call of InitSensors.
+func (mr *MockOrganizationRepositoryMockRecorder) InitSensors(ctx, id, count interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InitSensors", reflect.TypeOf((*MockOrganizationRepository)(nil).InitSensors), ctx, id, count)
+}
+
+// List mocks base method.
+func (m *MockOrganizationRepository) List(ctx context.Context) ([]entities.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx)
+	ret0, _ := ret[0].([]entities.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockOrganizationRepositoryMockRecorder) List(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockOrganizationRepository)(nil).List), ctx)
+}
+
+// ReleaseSensors mocks base method.
+func (m *MockOrganizationRepository) ReleaseSensors(ctx context.Context, id primitive.ObjectID, sensors int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseSensors", ctx, id, sensors)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseSensors indicates an expected call of ReleaseSensors.
+func (mr *MockOrganizationRepositoryMockRecorder) ReleaseSensors(ctx, id, sensors interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseSensors", reflect.TypeOf((*MockOrganizationRepository)(nil).ReleaseSensors), ctx, id, sensors)
+}
+
+// ReserveSensors mocks base method.
+func (m *MockOrganizationRepository) ReserveSensors(ctx context.Context, id primitive.ObjectID, sensors, limit int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveSensors", ctx, id, sensors, limit)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReserveSensors indicates an expected call of ReserveSensors.
+func (mr *MockOrganizationRepositoryMockRecorder) ReserveSensors(ctx, id, sensors, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveSensors", reflect.TypeOf((*MockOrganizationRepository)(nil).ReserveSensors), ctx, id, sensors, limit)
+}
+
+// SetKeyHash mocks base method.
+func (m *MockOrganizationRepository) SetKeyHash(ctx context.Context, id, hash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetKeyHash", ctx, id, hash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetKeyHash indicates an expected call of SetKeyHash.
+func (mr *MockOrganizationRepositoryMockRecorder) SetKeyHash(ctx, id, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetKeyHash", reflect.TypeOf((*MockOrganizationRepository)(nil).SetKeyHash), ctx, id, hash)
+}
+
+// Update mocks base method.
+func (m *MockOrganizationRepository) Update(ctx context.Context, id string, organization *entities.Organization) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, id, organization)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockOrganizationRepositoryMockRecorder) Update(ctx, id, organization interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockOrganizationRepository)(nil).Update), ctx, id, organization)
+}
+
+// MockAuditRepository is a mock of AuditRepository interface.
+type MockAuditRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditRepositoryMockRecorder
+}
+
+// MockAuditRepositoryMockRecorder is the mock recorder for MockAuditRepository.
+type MockAuditRepositoryMockRecorder struct {
+	mock *MockAuditRepository
+}
+
+// NewMockAuditRepository creates a new mock instance.
+func NewMockAuditRepository(ctrl *gomock.Controller) *MockAuditRepository {
+	mock := &MockAuditRepository{ctrl: ctrl}
+	mock.recorder = &MockAuditRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditRepository) EXPECT() *MockAuditRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Complete mocks base method.
+func (m *MockAuditRepository) Complete(ctx context.Context, entry *entities.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", ctx, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Complete indicates an expected call of Complete.
+func (mr *MockAuditRepositoryMockRecorder) Complete(ctx, entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockAuditRepository)(nil).Complete), ctx, entry)
+}
+
+// Create mocks base method.
+func (m *MockAuditRepository) Create(ctx context.Context, entry *entities.AuditEntry) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, entry)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockAuditRepositoryMockRecorder) Create(ctx, entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAuditRepository)(nil).Create), ctx, entry)
+}
+
+// List mocks base method.
+func (m *MockAuditRepository) List(ctx context.Context, filter entities.AuditFilter) ([]entities.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, filter)
+	ret0, _ := ret[0].([]entities.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockAuditRepositoryMockRecorder) List(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAuditRepository)(nil).List), ctx, filter)
+}
+
+// MockChainRepository is a mock of ChainRepository interface.
+type MockChainRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockChainRepositoryMockRecorder
+}
+
+// MockChainRepositoryMockRecorder is the mock recorder for MockChainRepository.
+type MockChainRepositoryMockRecorder struct {
+	mock *MockChainRepository
+}
+
+// NewMockChainRepository creates a new mock instance.
+func NewMockChainRepository(ctrl *gomock.Controller) *MockChainRepository {
+	mock := &MockChainRepository{ctrl: ctrl}
+	mock.recorder = &MockChainRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockChainRepository) EXPECT() *MockChainRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Between mocks base method.
+func (m *MockChainRepository) Between(ctx context.Context, clientID primitive.ObjectID, from, to time.Time) ([]entities.ChainRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Between", ctx, clientID, from, to)
+	ret0, _ := ret[0].([]entities.ChainRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Between indicates an expected call of Between.
+func (mr *MockChainRepositoryMockRecorder) Between(ctx, clientID, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Between", reflect.TypeOf((*MockChainRepository)(nil).Between), ctx, clientID, from, to)
+}
+
+// Bounds mocks base method.
+func (m *MockChainRepository) Bounds(ctx context.Context, clientID primitive.ObjectID, from, to time.Time) (int64, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Bounds", ctx, clientID, from, to)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Bounds indicates an expected call of Bounds.
+func (mr *MockChainRepositoryMockRecorder) Bounds(ctx, clientID, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Bounds", reflect.TypeOf((*MockChainRepository)(nil).Bounds), ctx, clientID, from, to)
+}
+
+// Create mocks base method.
+func (m *MockChainRepository) Create(ctx context.Context, record *entities.ChainRecord) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, record)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockChainRepositoryMockRecorder) Create(ctx, record interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockChainRepository)(nil).Create), ctx, record)
+}
+
+// FindUpload mocks base method.
+func (m *MockChainRepository) FindUpload(ctx context.Context, clientID primitive.ObjectID, rawTimestamp time.Time, payloadHash string) (entities.ChainRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUpload", ctx, clientID, rawTimestamp, payloadHash)
+	ret0, _ := ret[0].(entities.ChainRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUpload indicates an expected call of FindUpload.
+func (mr *MockChainRepositoryMockRecorder) FindUpload(ctx, clientID, rawTimestamp, payloadHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUpload", reflect.TypeOf((*MockChainRepository)(nil).FindUpload), ctx, clientID, rawTimestamp, payloadHash)
+}
+
+// Range mocks base method.
+func (m *MockChainRepository) Range(ctx context.Context, clientID primitive.ObjectID, from, to int64) ([]entities.ChainRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Range", ctx, clientID, from, to)
+	ret0, _ := ret[0].([]entities.ChainRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Range indicates an expected call of Range.
+func (mr *MockChainRepositoryMockRecorder) Range(ctx, clientID, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Range", reflect.TypeOf((*MockChainRepository)(nil).Range), ctx, clientID, from, to)
+}
+
+// MockBlobRepository is a mock of BlobRepository interface.
+type MockBlobRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockBlobRepositoryMockRecorder
+}
+
+// MockBlobRepositoryMockRecorder is the mock recorder for MockBlobRepository.
+type MockBlobRepositoryMockRecorder struct {
+	mock *MockBlobRepository
+}
+
+// NewMockBlobRepository creates a new mock instance.
+func NewMockBlobRepository(ctrl *gomock.Controller) *MockBlobRepository {
+	mock := &MockBlobRepository{ctrl: ctrl}
+	mock.recorder = &MockBlobRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBlobRepository) EXPECT() *MockBlobRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockBlobRepository) Delete(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockBlobRepositoryMockRecorder) Delete(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockBlobRepository)(nil).Delete), ctx, key)
+}
+
+// Expired mocks base method.
+func (m *MockBlobRepository) Expired(ctx context.Context, before time.Time, after string, limit int64) ([]entities.Blob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Expired", ctx, before, after, limit)
+	ret0, _ := ret[0].([]entities.Blob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Expired indicates an expected call of Expired.
+func (mr *MockBlobRepositoryMockRecorder) Expired(ctx, before, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Expired", reflect.TypeOf((*MockBlobRepository)(nil).Expired), ctx, before, after, limit)
+}
+
+// Get mocks base method.
+func (m *MockBlobRepository) Get(ctx context.Context, key string) (entities.Blob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, key)
+	ret0, _ := ret[0].(entities.Blob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockBlobRepositoryMockRecorder) Get(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockBlobRepository)(nil).Get), ctx, key)
+}
+
+// Put mocks base method.
+func (m *MockBlobRepository) Put(ctx context.Context, blob *entities.Blob) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Put", ctx, blob)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Put indicates an expected call of Put.
+func (mr *MockBlobRepositoryMockRecorder) Put(ctx, blob interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockBlobRepository)(nil).Put), ctx, blob)
+}
+
+// SetEncryption mocks base method.
+func (m *MockBlobRepository) SetEncryption(ctx context.Context, key string, from, to entities.BlobEncryption) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetEncryption", ctx, key, from, to)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetEncryption indicates an expected call of SetEncryption.
+func (mr *MockBlobRepositoryMockRecorder) SetEncryption(ctx, key, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEncryption", reflect.TypeOf((*MockBlobRepository)(nil).SetEncryption), ctx, key, from, to)
+}
+
+// Stale mocks base method.
+func (m *MockBlobRepository) Stale(ctx context.Context, keyID, after string, limit int64) ([]entities.Blob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stale", ctx, keyID, after, limit)
+	ret0, _ := ret[0].([]entities.Blob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stale indicates an expected call of Stale.
+func (mr *MockBlobRepositoryMockRecorder) Stale(ctx, keyID, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stale", reflect.TypeOf((*MockBlobRepository)(nil).Stale), ctx, keyID, after, limit)
+}
+
+// MockErasureRepository is a mock of ErasureRepository interface.
+type MockErasureRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockErasureRepositoryMockRecorder
+}
+
+// MockErasureRepositoryMockRecorder is the mock recorder for MockErasureRepository.
+type MockErasureRepositoryMockRecorder struct {
+	mock *MockErasureRepository
+}
+
+// NewMockErasureRepository creates a new mock instance.
+func NewMockErasureRepository(ctrl *gomock.Controller) *MockErasureRepository {
+	mock := &MockErasureRepository{ctrl: ctrl}
+	mock.recorder = &MockErasureRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockErasureRepository) EXPECT() *MockErasureRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Erase mocks base method.
+func (m *MockErasureRepository) Erase(ctx context.Context, clientID primitive.ObjectID, hold entities.ErasureHold) (entities.ErasureReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Erase", ctx, clientID, hold)
+	ret0, _ := ret[0].(entities.ErasureReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Erase indicates an expected call of Erase.
+func (mr *MockErasureRepositoryMockRecorder) Erase(ctx, clientID, hold interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Erase", reflect.TypeOf((*MockErasureRepository)(nil).Erase), ctx, clientID, hold)
+}
+
+// Erased mocks base method.
+func (m *MockErasureRepository) Erased(ctx context.Context, clientID primitive.ObjectID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Erased", ctx, clientID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Erased indicates an expected call of Erased.
+func (mr *MockErasureRepositoryMockRecorder) Erased(ctx, clientID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Erased", reflect.TypeOf((*MockErasureRepository)(nil).Erased), ctx, clientID)
+}
+
+// Tombstone mocks base method.
+func (m *MockErasureRepository) Tombstone(ctx context.Context, tombstone *entities.Tombstone) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Tombstone", ctx, tombstone)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Tombstone indicates an expected call of Tombstone.
+func (mr *MockErasureRepositoryMockRecorder) Tombstone(ctx, tombstone interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Tombstone", reflect.TypeOf((*MockErasureRepository)(nil).Tombstone), ctx, tombstone)
+}
+
+// MockIdempotencyRepository is a mock of IdempotencyRepository interface.
+type MockIdempotencyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdempotencyRepositoryMockRecorder
+}
+
+// MockIdempotencyRepositoryMockRecorder is the mock recorder for MockIdempotencyRepository.
+type MockIdempotencyRepositoryMockRecorder struct {
+	mock *MockIdempotencyRepository
+}
+
+// NewMockIdempotencyRepository creates a new mock instance.
+func NewMockIdempotencyRepository(ctrl *gomock.Controller) *MockIdempotencyRepository {
+	mock := &MockIdempotencyRepository{ctrl: ctrl}
+	mock.recorder = &MockIdempotencyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdempotencyRepository) EXPECT() *MockIdempotencyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Begin mocks base method.
+func (m *MockIdempotencyRepository) Begin(ctx context.Context, request *entities.IdempotentRequest) (entities.IdempotentRequest, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Begin", ctx, request)
+	ret0, _ := ret[0].(entities.IdempotentRequest)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Begin indicates an expected call of Begin.
+func (mr *MockIdempotencyRepositoryMockRecorder) Begin(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Begin", reflect.TypeOf((*MockIdempotencyRepository)(nil).Begin), ctx, request)
+}
+
+// Complete mocks base method.
+func (m *MockIdempotencyRepository) Complete(ctx context.Context, request *entities.IdempotentRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", ctx, request)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Complete indicates an expected call of Complete.
+func (mr *MockIdempotencyRepositoryMockRecorder) Complete(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockIdempotencyRepository)(nil).Complete), ctx, request)
+}
+
+// Extend mocks base method.
+func (m *MockIdempotencyRepository) Extend(ctx context.Context, request *entities.IdempotentRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Extend", ctx, request)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Extend indicates an expected call of Extend.
+func (mr *MockIdempotencyRepositoryMockRecorder) Extend(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Extend", reflect.TypeOf((*MockIdempotencyRepository)(nil).Extend), ctx, request)
+}
+
+// Release mocks base method.
+func (m *MockIdempotencyRepository) Release(ctx context.Context, request *entities.IdempotentRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Release", ctx, request)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockIdempotencyRepositoryMockRecorder) Release(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockIdempotencyRepository)(nil).Release), ctx, request)
+}
+
+// MockWebhookRepository is a mock of WebhookRepository interface.
+type MockWebhookRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookRepositoryMockRecorder
+}
+
+// MockWebhookRepositoryMockRecorder is the mock recorder for MockWebhookRepository.
+type MockWebhookRepositoryMockRecorder struct {
+	mock *MockWebhookRepository
+}
+
+// NewMockWebhookRepository creates a new mock instance.
+func NewMockWebhookRepository(ctrl *gomock.Controller) *MockWebhookRepository {
+	mock := &MockWebhookRepository{ctrl: ctrl}
+	mock.recorder = &MockWebhookRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookRepository) EXPECT() *MockWebhookRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockWebhookRepository) Create(ctx context.Context, webhook *entities.Webhook) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, webhook)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockWebhookRepositoryMockRecorder) Create(ctx, webhook interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockWebhookRepository)(nil).Create), ctx, webhook)
+}
+
+// Delete mocks base method.
+func (m *MockWebhookRepository) Delete(ctx context.Context, id string) (entities.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(entities.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockWebhookRepositoryMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockWebhookRepository)(nil).Delete), ctx, id)
+}
+
+// Get mocks base method.
+func (m *MockWebhookRepository) Get(ctx context.Context, id string) (entities.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(entities.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockWebhookRepositoryMockRecorder) Get(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockWebhookRepository)(nil).Get), ctx, id)
+}
+
+// List mocks base method.
+func (m *MockWebhookRepository) List(ctx context.Context, event entities.WebhookEvent) ([]entities.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, event)
+	ret0, _ := ret[0].([]entities.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockWebhookRepositoryMockRecorder) List(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockWebhookRepository)(nil).List), ctx, event)
+}
+
+// MockQuotaRepository is a mock of QuotaRepository interface.
+type MockQuotaRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockQuotaRepositoryMockRecorder
+}
+
+// MockQuotaRepositoryMockRecorder is the mock recorder for MockQuotaRepository.
+type MockQuotaRepositoryMockRecorder struct {
+	mock *MockQuotaRepository
+}
+
+// NewMockQuotaRepository creates a new mock instance.
+func NewMockQuotaRepository(ctrl *gomock.Controller) *MockQuotaRepository {
+	mock := &MockQuotaRepository{ctrl: ctrl}
+	mock.recorder = &MockQuotaRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockQuotaRepository) EXPECT() *MockQuotaRepositoryMockRecorder {
+	return m.recorder
+}
+
+// TakeUpload mocks base method.
+func (m *MockQuotaRepository) TakeUpload(ctx context.Context, tenantID primitive.ObjectID, minute time.Time, limit int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakeUpload", ctx, tenantID, minute, limit)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TakeUpload indicates an expected call of TakeUpload.
+func (mr *MockQuotaRepositoryMockRecorder) TakeUpload(ctx, tenantID, minute, limit interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockClientRepository)(nil).Update), ctx, id, client)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeUpload", reflect.TypeOf((*MockQuotaRepository)(nil).TakeUpload), ctx, tenantID, minute, limit)
 }
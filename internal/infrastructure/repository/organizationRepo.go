@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// OrganizationRepo stores tenants themselves, so its queries are not scoped
+type OrganizationRepo struct {
+	collection *mongo.Collection
+	tracer     trace.Tracer
+}
+
+func (o OrganizationRepo) Create(ctx context.Context, organization *entities.Organization) (string, error) {
+	ctx, span := o.tracer.Start(ctx, "OrganizationRepo.Create")
+	defer span.End()
+
+	organization.ID = primitive.NewObjectID()
+
+	if _, err := o.collection.InsertOne(ctx, organization); err != nil {
+		span.RecordError(err)
+		return "", errors.Wrap(err, "error during create organization")
+	}
+
+	return organization.ID.Hex(), nil
+}
+
+func (o OrganizationRepo) Get(ctx context.Context, id string) (entities.Organization, error) {
+	ctx, span := o.tracer.Start(ctx, "OrganizationRepo.Get")
+	defer span.End()
+
+	castedID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return entities.Organization{}, invalidID(err, "organization")
+	}
+
+	return o.findOne(ctx, bson.M{"_id": castedID})
+}
+
+// GetByKeyHash returns the organization which API key has the hash
+func (o OrganizationRepo) GetByKeyHash(ctx context.Context, hash string) (entities.Organization, error) {
+	ctx, span := o.tracer.Start(ctx, "OrganizationRepo.GetByKeyHash")
+	defer span.End()
+
+	return o.findOne(ctx, bson.M{"apiKeyHash": hash})
+}
+
+func (o OrganizationRepo) List(ctx context.Context) ([]entities.Organization, error) {
+	ctx, span := o.tracer.Start(ctx, "OrganizationRepo.List")
+	defer span.End()
+
+	cursor, err := o.collection.Find(ctx, bson.M{})
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during list organizations")
+	}
+
+	organizations := make([]entities.Organization, 0)
+	if err := cursor.All(ctx, &organizations); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during decode organizations")
+	}
+
+	return organizations, nil
+}
+
+func (o OrganizationRepo) Update(ctx context.Context, id string, organization *entities.Organization) error {
+	ctx, span := o.tracer.Start(ctx, "OrganizationRepo.Update")
+	defer span.End()
+
+	castedID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return invalidID(err, "organization")
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"name":      organization.Name,
+			"quota":     organization.Quota,
+			"retention": organization.Retention,
+			"privacy":   organization.Privacy,
+		},
+	}
+
+	return o.updateOne(ctx, castedID, update)
+}
+
+// SetKeyHash replaces the API key of the organization
+func (o OrganizationRepo) SetKeyHash(ctx context.Context, id string, hash string) error {
+	ctx, span := o.tracer.Start(ctx, "OrganizationRepo.SetKeyHash")
+	defer span.End()
+
+	castedID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return invalidID(err, "organization")
+	}
+
+	return o.updateOne(ctx, castedID, bson.M{"$set": bson.M{"apiKeyHash": hash}})
+}
+
+// ReserveSensors takes places of the sensors in the counter of the organization if the limit (0 means no
+// limit) leaves them, it returns false when it doesn't or the counter is not set up yet
+func (o OrganizationRepo) ReserveSensors(ctx context.Context, id primitive.ObjectID, sensors, limit int) (bool, error) {
+	ctx, span := o.tracer.Start(ctx, "OrganizationRepo.ReserveSensors")
+	defer span.End()
+
+	counter := bson.M{"$exists": true}
+	if limit > 0 {
+		counter["$lte"] = limit - sensors
+	}
+
+	res, err := o.collection.UpdateOne(
+		ctx, bson.M{"_id": id, "sensors": counter}, bson.M{"$inc": bson.M{"sensors": sensors}},
+	)
+	if err != nil {
+		span.RecordError(err)
+		return false, errors.Wrap(err, "error during reserve sensors of organization")
+	}
+
+	return res.MatchedCount == 1, nil
+}
+
+// ReleaseSensors gives back places of the sensors deleted or not created
+func (o OrganizationRepo) ReleaseSensors(ctx context.Context, id primitive.ObjectID, sensors int) error {
+	ctx, span := o.tracer.Start(ctx, "OrganizationRepo.ReleaseSensors")
+	defer span.End()
+
+	_, err := o.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "sensors": bson.M{"$gte": sensors}},
+		bson.M{"$inc": bson.M{"sensors": -sensors}},
+	)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "error during release sensors of organization")
+	}
+
+	return nil
+}
+
+// InitSensors sets up the counter of sensors of the organization unless it's already done, it returns
+// whether the counter has been set by the call
+func (o OrganizationRepo) InitSensors(ctx context.Context, id primitive.ObjectID, count int64) (bool, error) {
+	ctx, span := o.tracer.Start(ctx, "OrganizationRepo.InitSensors")
+	defer span.End()
+
+	res, err := o.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "sensors": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"sensors": count}},
+	)
+	if err != nil {
+		span.RecordError(err)
+		return false, errors.Wrap(err, "error during init sensors of organization")
+	}
+
+	return res.ModifiedCount == 1, nil
+}
+
+func (o OrganizationRepo) findOne(ctx context.Context, filter bson.M) (entities.Organization, error) {
+	var organization entities.Organization
+	if err := o.collection.FindOne(ctx, filter).Decode(&organization); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return entities.Organization{}, ErrOrganizationNotFound
+		}
+
+		return entities.Organization{}, errors.Wrap(err, "error during get organization from db")
+	}
+
+	return organization, nil
+}
+
+func (o OrganizationRepo) updateOne(ctx context.Context, id primitive.ObjectID, update bson.M) error {
+	res, err := o.collection.UpdateByID(ctx, id, update)
+	if err != nil {
+		return errors.Wrap(err, "error during update organization")
+	}
+
+	if res.MatchedCount == 0 {
+		return ErrOrganizationNotFound
+	}
+
+	return nil
+}
+
+func NewOrganizationRepo(database *mongo.Database) *OrganizationRepo {
+	return &OrganizationRepo{
+		collection: database.Collection(_organizationsCollection),
+		tracer:     otel.Tracer("OrganizationRepo"),
+	}
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+	"time"
+)
+
+// addTimeRange restricts the field by the passed bounds, zero bounds are ignored
+func addTimeRange(query bson.M, field string, from, to time.Time) {
+	if from.IsZero() && to.IsZero() {
+		return
+	}
+
+	bounds := bson.M{}
+	if !from.IsZero() {
+		bounds["$gte"] = from
+	}
+	if !to.IsZero() {
+		bounds["$lte"] = to
+	}
+
+	query[field] = bounds
+}
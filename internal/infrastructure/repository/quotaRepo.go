@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+const _uploadCountersCollection = "UploadCounters"
+
+// QuotaRepo counts uploads of tenants per minute, so every instance of the service applies the same limit.
+// Counters of past minutes are removed by the TTL index
+type QuotaRepo struct {
+	collection *mongo.Collection
+	tracer     trace.Tracer
+}
+
+// TakeUpload counts the upload in the minute of the tenant, it returns false when the minute already has
+// limit uploads. The counter is taken with one $inc filtered by the limit, so concurrent uploads can't pass
+// it together
+func (q QuotaRepo) TakeUpload(
+	ctx context.Context, tenantID primitive.ObjectID, minute time.Time, limit int,
+) (bool, error) {
+	ctx, span := q.tracer.Start(ctx, "QuotaRepo.TakeUpload")
+	defer span.End()
+
+	minute = minute.UTC().Truncate(time.Minute)
+
+	filter := bson.M{"tenantID": tenantID, "minute": minute, "uploads": bson.M{"$lt": limit}}
+	update := bson.M{
+		"$inc":         bson.M{"uploads": 1},
+		"$setOnInsert": bson.M{"expiresAt": minute.Add(2 * time.Minute)},
+	}
+
+	// the full counter doesn't match the filter, so the upsert runs into the unique index. The first
+	// upload of the minute may run into the counter inserted by a concurrent one, so it's tried again
+	for attempt := 0; attempt < 2; attempt++ {
+		_, err := q.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+		if err == nil {
+			return true, nil
+		}
+
+		if !mongo.IsDuplicateKeyError(err) {
+			span.RecordError(err)
+			return false, errors.Wrap(err, "error during take upload of tenant")
+		}
+	}
+
+	return false, nil
+}
+
+func NewQuotaRepo(database *mongo.Database) *QuotaRepo {
+	return &QuotaRepo{
+		collection: database.Collection(_uploadCountersCollection),
+		tracer:     otel.Tracer("QuotaRepo"),
+	}
+}
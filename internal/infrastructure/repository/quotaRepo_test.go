@@ -0,0 +1,96 @@
+//go:build integration
+// +build integration
+
+package repository_test
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository"
+	"github.com/stretchr/testify/suite"
+	"github.com/testcontainers/testcontainers-go"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type QuotaRepoSuite struct {
+	suite.Suite
+	repo      *repository.QuotaRepo
+	dbClient  *mongo.Client
+	container testcontainers.Container
+}
+
+func TestQuotaRepoSuite(t *testing.T) {
+	suite.Run(t, new(QuotaRepoSuite))
+}
+
+func (s *QuotaRepoSuite) SetupSuite() {
+	s.dbClient, s.container = startMongo(&s.Suite)
+
+	database := s.dbClient.Database(_dbName)
+	s.Require().NoError(repository.EnsureIndexes(context.Background(), database))
+	s.repo = repository.NewQuotaRepo(database)
+}
+
+func (s *QuotaRepoSuite) TearDownSuite() {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	s.Require().NoError(s.dbClient.Disconnect(ctx))
+	s.Require().NoError(s.container.Terminate(ctx))
+}
+
+// TestConcurrentUploads checks that concurrent uploads can't take more than the limit of the minute
+func (s *QuotaRepoSuite) TestConcurrentUploads() {
+	const limit = 5
+
+	var (
+		tenantID = primitive.NewObjectID()
+		minute   = time.Now()
+		taken    int32
+		wg       sync.WaitGroup
+	)
+
+	for i := 0; i < 4*limit; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ok, err := s.repo.TakeUpload(context.Background(), tenantID, minute, limit)
+			s.NoError(err)
+			if ok {
+				atomic.AddInt32(&taken, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	s.Equal(int32(limit), taken)
+}
+
+// TestCounters checks that counters are kept per tenant and per minute
+func (s *QuotaRepoSuite) TestCounters() {
+	var (
+		tenantID = primitive.NewObjectID()
+		minute   = time.Now().Truncate(time.Minute)
+	)
+
+	ok, err := s.repo.TakeUpload(context.Background(), tenantID, minute, 1)
+	s.Require().NoError(err)
+	s.True(ok)
+
+	ok, err = s.repo.TakeUpload(context.Background(), tenantID, minute.Add(30*time.Second), 1)
+	s.Require().NoError(err)
+	s.False(ok)
+
+	ok, err = s.repo.TakeUpload(context.Background(), tenantID, minute.Add(time.Minute), 1)
+	s.Require().NoError(err)
+	s.True(ok)
+
+	ok, err = s.repo.TakeUpload(context.Background(), primitive.NewObjectID(), minute, 1)
+	s.Require().NoError(err)
+	s.True(ok)
+}
@@ -3,26 +3,240 @@ package repository
 import (
 	"context"
 	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"time"
 )
 
 var (
-	_ ClientRepository = ClientRepo{}
+	_ ClientRepository        = ClientRepo{}
+	_ ClientVersionRepository = ClientVersionRepo{}
+	_ IncidentRepository      = IncidentRepo{}
+	_ DetectionRepository     = DetectionRepo{}
+	_ AlertRuleRepository     = AlertRuleRepo{}
+	_ AlertRepository         = AlertRepo{}
+	_ ZoneRepository          = ZoneRepo{}
+	_ HeartbeatRepository     = HeartbeatRepo{}
+	_ ConfigRepository        = ConfigRepo{}
+	_ CommandRepository       = CommandRepo{}
+	_ OrganizationRepository  = OrganizationRepo{}
+	_ AuditRepository         = AuditRepo{}
+	_ ChainRepository         = ChainRepo{}
+	_ BlobRepository          = BlobRepo{}
+	_ BlobRepository          = EncryptedBlobRepo{}
+	_ ErasureRepository       = ErasureRepo{}
 )
 
 type ClientRepository interface {
 	Create(ctx context.Context, client *entities.Client) (string, error)
+	CreateMany(ctx context.Context, clients []*entities.Client) ([]string, error)
 	Get(ctx context.Context, id string) (entities.Client, error)
-	Update(ctx context.Context, id string, client *entities.Client) error
-	Delete(ctx context.Context, id string) error
+	Update(
+		ctx context.Context, id string, version int64, client *entities.Client,
+	) (entities.Client, entities.Client, error)
+	Patch(
+		ctx context.Context, id string, version int64, patch entities.ClientPatch,
+	) (entities.Client, entities.Client, error)
+	Delete(ctx context.Context, id string, version int64) (entities.Client, entities.Client, error)
+	List(ctx context.Context) ([]entities.Client, error)
+	Page(ctx context.Context, filter entities.ClientFilter) ([]entities.Client, error)
+	Count(ctx context.Context) (int64, error)
+	SetZones(ctx context.Context, id primitive.ObjectID, zoneIDs []primitive.ObjectID) error
+	SetHealth(ctx context.Context, id primitive.ObjectID, health entities.ClientHealth) error
+	SetHealthStatus(
+		ctx context.Context, id primitive.ObjectID, lastSeen time.Time, status entities.HealthStatus,
+	) (bool, error)
+	SetAppliedConfig(ctx context.Context, id primitive.ObjectID, version int64) error
+	SetClock(ctx context.Context, id primitive.ObjectID, clock entities.ClockState) error
+	AddKey(ctx context.Context, id primitive.ObjectID, key entities.DeviceKey, expireBy time.Time) error
+	RevokeKey(ctx context.Context, id primitive.ObjectID, keyID string, at time.Time) (entities.DeviceKey, error)
+	AdvanceChain(ctx context.Context, id primitive.ObjectID, from, to entities.ChainHead) error
+	GetWithDeleted(ctx context.Context, id string) (entities.Client, error)
+	Restore(ctx context.Context, id primitive.ObjectID) error
+	Purge(ctx context.Context, before time.Time) ([]primitive.ObjectID, error)
+}
+
+type ClientVersionRepository interface {
+	Create(ctx context.Context, version *entities.ClientVersion) (string, error)
+	List(ctx context.Context, clientID primitive.ObjectID) ([]entities.ClientVersion, error)
+	DeleteForClients(ctx context.Context, clientIDs []primitive.ObjectID) (int64, error)
+}
+
+type IncidentRepository interface {
+	Create(ctx context.Context, incident *entities.Incident) (string, error)
+	Get(ctx context.Context, id string) (entities.Incident, error)
+	Merge(ctx context.Context, id primitive.ObjectID, client entities.Client, ts time.Time) (entities.Incident, error)
+	SetZones(ctx context.Context, id primitive.ObjectID, shotCount int, zoneIDs []primitive.ObjectID) error
+	Transition(ctx context.Context, id string, transition entities.IncidentTransition) error
+	FindActive(ctx context.Context, since time.Time) ([]entities.Incident, error)
+	List(ctx context.Context, filter entities.IncidentFilter) ([]entities.Incident, error)
+	SetLegalHold(ctx context.Context, id primitive.ObjectID, hold *entities.LegalHold) error
+}
+
+type DetectionRepository interface {
+	Create(ctx context.Context, detection *entities.Detection) (string, error)
+	List(ctx context.Context, filter entities.DetectionFilter) ([]entities.Detection, error)
+	Count(ctx context.Context, clientID primitive.ObjectID, from, to time.Time) (int64, error)
+	MarkFalsePositive(ctx context.Context, incidentID string) error
+	Hold(ctx context.Context, incidentID primitive.ObjectID) error
+	Release(ctx context.Context, incidentID primitive.ObjectID, period time.Duration) error
+	CountExpiring(ctx context.Context, before time.Time) (int64, error)
+}
+
+type AlertRuleRepository interface {
+	Create(ctx context.Context, rule *entities.AlertRule) (string, error)
+	Get(ctx context.Context, id string) (entities.AlertRule, error)
+	Update(ctx context.Context, id string, rule *entities.AlertRule) (entities.AlertRule, entities.AlertRule, error)
+	Delete(ctx context.Context, id string) (entities.AlertRule, error)
+	List(ctx context.Context) ([]entities.AlertRule, error)
+	FindForClient(
+		ctx context.Context, clientID primitive.ObjectID, zoneIDs []primitive.ObjectID,
+	) ([]entities.AlertRule, error)
+}
+
+type AlertRepository interface {
+	Create(ctx context.Context, alert *entities.Alert) (string, error)
+	List(ctx context.Context, filter entities.AlertFilter) ([]entities.Alert, error)
+}
+
+type ZoneRepository interface {
+	Create(ctx context.Context, zone *entities.Zone) (string, error)
+	Get(ctx context.Context, id string) (entities.Zone, error)
+	Update(ctx context.Context, id string, zone *entities.Zone) (entities.Zone, entities.Zone, error)
+	Delete(ctx context.Context, id string) (entities.Zone, error)
+	List(ctx context.Context) ([]entities.Zone, error)
+}
+
+type HeartbeatRepository interface {
+	Create(ctx context.Context, heartbeat *entities.Heartbeat) (string, error)
+	List(ctx context.Context, filter entities.HeartbeatFilter) ([]entities.Heartbeat, error)
+	CountExpiring(ctx context.Context, before time.Time) (int64, error)
+}
+
+type ConfigRepository interface {
+	Save(
+		ctx context.Context, scope entities.ConfigScope, ownerID primitive.ObjectID, config entities.SensorConfig,
+	) (entities.ConfigDocument, error)
+	Get(ctx context.Context, scope entities.ConfigScope, ownerID primitive.ObjectID) (entities.ConfigDocument, error)
+	FindForClient(
+		ctx context.Context, clientID primitive.ObjectID, zoneIDs []primitive.ObjectID,
+	) ([]entities.ConfigDocument, error)
+}
+
+type CommandRepository interface {
+	Create(ctx context.Context, command *entities.Command) (string, error)
+	Get(ctx context.Context, clientID, id primitive.ObjectID) (entities.Command, error)
+	List(ctx context.Context, filter entities.CommandFilter) ([]entities.Command, error)
+	Claim(ctx context.Context, clientID primitive.ObjectID, now time.Time) ([]entities.Command, error)
+	Acknowledge(ctx context.Context, clientID, id primitive.ObjectID, at time.Time) (entities.Command, error)
+	Complete(
+		ctx context.Context, clientID, id primitive.ObjectID, result entities.CommandResult, at time.Time,
+	) (entities.Command, error)
+	Redeliver(ctx context.Context, deliveredBefore, now time.Time) (int64, error)
+	Expire(ctx context.Context, now time.Time) (int64, error)
+}
+
+type OrganizationRepository interface {
+	Create(ctx context.Context, organization *entities.Organization) (string, error)
+	Get(ctx context.Context, id string) (entities.Organization, error)
+	GetByKeyHash(ctx context.Context, hash string) (entities.Organization, error)
+	List(ctx context.Context) ([]entities.Organization, error)
+	Update(ctx context.Context, id string, organization *entities.Organization) error
+	SetKeyHash(ctx context.Context, id string, hash string) error
+	ReserveSensors(ctx context.Context, id primitive.ObjectID, sensors, limit int) (bool, error)
+	ReleaseSensors(ctx context.Context, id primitive.ObjectID, sensors int) error
+	InitSensors(ctx context.Context, id primitive.ObjectID, count int64) (bool, error)
+}
+
+type AuditRepository interface {
+	Create(ctx context.Context, entry *entities.AuditEntry) (string, error)
+	Complete(ctx context.Context, entry *entities.AuditEntry) error
+	List(ctx context.Context, filter entities.AuditFilter) ([]entities.AuditEntry, error)
+}
+
+type ChainRepository interface {
+	Create(ctx context.Context, record *entities.ChainRecord) (string, error)
+	FindUpload(
+		ctx context.Context, clientID primitive.ObjectID, rawTimestamp time.Time, payloadHash string,
+	) (entities.ChainRecord, error)
+	Bounds(ctx context.Context, clientID primitive.ObjectID, from, to time.Time) (int64, int64, error)
+	Range(ctx context.Context, clientID primitive.ObjectID, from, to int64) ([]entities.ChainRecord, error)
+	Between(ctx context.Context, clientID primitive.ObjectID, from, to time.Time) ([]entities.ChainRecord, error)
+}
+
+type BlobRepository interface {
+	Put(ctx context.Context, blob *entities.Blob) error
+	Get(ctx context.Context, key string) (entities.Blob, error)
+	Delete(ctx context.Context, key string) error
+	Expired(ctx context.Context, before time.Time, after string, limit int64) ([]entities.Blob, error)
+	Stale(ctx context.Context, keyID string, after string, limit int64) ([]entities.Blob, error)
+	SetEncryption(ctx context.Context, key string, from, to entities.BlobEncryption) error
+}
+
+type ErasureRepository interface {
+	Tombstone(ctx context.Context, tombstone *entities.Tombstone) error
+	Erased(ctx context.Context, clientID primitive.ObjectID) (bool, error)
+	Erase(ctx context.Context, clientID primitive.ObjectID, hold entities.ErasureHold) (entities.ErasureReport, error)
+}
+
+type IdempotencyRepository interface {
+	Begin(ctx context.Context, request *entities.IdempotentRequest) (entities.IdempotentRequest, bool, error)
+	Complete(ctx context.Context, request *entities.IdempotentRequest) error
+	Extend(ctx context.Context, request *entities.IdempotentRequest) error
+	Release(ctx context.Context, request *entities.IdempotentRequest) error
+}
+
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook *entities.Webhook) (string, error)
+	Get(ctx context.Context, id string) (entities.Webhook, error)
+	List(ctx context.Context, event entities.WebhookEvent) ([]entities.Webhook, error)
+	Delete(ctx context.Context, id string) (entities.Webhook, error)
+}
+
+type QuotaRepository interface {
+	TakeUpload(ctx context.Context, tenantID primitive.ObjectID, minute time.Time, limit int) (bool, error)
 }
 
 type Repo struct {
-	Client ClientRepository
+	Client        ClientRepository
+	ClientVersion ClientVersionRepository
+	Incident      IncidentRepository
+	Detection     DetectionRepository
+	AlertRule     AlertRuleRepository
+	Alert         AlertRepository
+	Zone          ZoneRepository
+	Heartbeat     HeartbeatRepository
+	Config        ConfigRepository
+	Command       CommandRepository
+	Organization  OrganizationRepository
+	Audit         AuditRepository
+	Chain         ChainRepository
+	Blob          BlobRepository
+	Erasure       ErasureRepository
+	Idempotency   IdempotencyRepository
+	Quota         QuotaRepository
+	Webhook       WebhookRepository
 }
 
 func NewRepo(database *mongo.Database) *Repo {
 	return &Repo{
-		Client: NewClientRepo(database),
+		Client:        NewClientRepo(database),
+		ClientVersion: NewClientVersionRepo(database),
+		Incident:      NewIncidentRepo(database),
+		Detection:     NewDetectionRepo(database),
+		AlertRule:     NewAlertRuleRepo(database),
+		Alert:         NewAlertRepo(database),
+		Zone:          NewZoneRepo(database),
+		Heartbeat:     NewHeartbeatRepo(database),
+		Config:        NewConfigRepo(database),
+		Command:       NewCommandRepo(database),
+		Organization:  NewOrganizationRepo(database),
+		Audit:         NewAuditRepo(database),
+		Chain:         NewChainRepo(database),
+		Blob:          NewBlobRepo(database),
+		Erasure:       NewErasureRepo(database),
+		Idempotency:   NewIdempotencyRepo(database),
+		Quota:         NewQuotaRepo(database),
+		Webhook:       NewWebhookRepo(database),
 	}
 }
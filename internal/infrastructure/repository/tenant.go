@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/tenant"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// scoped restricts the query to documents of the tenant of the context, the system context is not restricted
+func scoped(ctx context.Context, query bson.M) (bson.M, error) {
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !tenantID.IsZero() {
+		query["tenantID"] = tenantID
+	}
+
+	return query, nil
+}
@@ -0,0 +1,344 @@
+//go:build integration
+// +build integration
+
+package repository_test
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository"
+	"github.com/Imm0bilize/gunshot-api-service/internal/tenant"
+	"github.com/stretchr/testify/suite"
+	"github.com/testcontainers/testcontainers-go"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TenantSuite makes sure an organization can't reach documents of another one
+type TenantSuite struct {
+	suite.Suite
+	repo      *repository.Repo
+	dbClient  *mongo.Client
+	container testcontainers.Container
+
+	owner    context.Context
+	stranger context.Context
+}
+
+func TestTenantSuite(t *testing.T) {
+	suite.Run(t, new(TenantSuite))
+}
+
+func (s *TenantSuite) SetupSuite() {
+	s.dbClient, s.container = startMongo(&s.Suite)
+	s.repo = repository.NewRepo(s.dbClient.Database(_dbName))
+
+	s.owner = tenant.WithID(context.Background(), primitive.NewObjectID())
+	s.stranger = tenant.WithID(context.Background(), primitive.NewObjectID())
+}
+
+func (s *TenantSuite) TearDownSuite() {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	s.Require().NoError(s.dbClient.Disconnect(ctx))
+	s.Require().NoError(s.container.Terminate(ctx))
+}
+
+func (s *TenantSuite) createClient() entities.Client {
+	client := entities.Client{FullName: "owner", LocationName: "test", Latitude: 52.124, Longitude: 12.235}
+
+	id, err := s.repo.Client.Create(s.owner, &client)
+	s.Require().NoError(err)
+
+	got, err := s.repo.Client.Get(s.owner, id)
+	s.Require().NoError(err)
+
+	return got
+}
+
+func (s *TenantSuite) TestClientIsolation() {
+	client := s.createClient()
+
+	_, err := s.repo.Client.Get(s.stranger, client.ID.Hex())
+	s.ErrorIs(err, repository.ErrClientNotFound)
+
+	stranger := &entities.Client{FullName: "stranger"}
+	_, _, err = s.repo.Client.Update(s.stranger, client.ID.Hex(), client.Version, stranger)
+	s.ErrorIs(err, repository.ErrClientNotFound)
+
+	err = s.repo.Client.SetHealth(s.stranger, client.ID, entities.ClientHealth{Status: entities.HealthOffline})
+	s.ErrorIs(err, repository.ErrClientNotFound)
+
+	_, _, err = s.repo.Client.Delete(s.stranger, client.ID.Hex(), client.Version)
+	s.ErrorIs(err, repository.ErrClientNotFound)
+
+	clients, err := s.repo.Client.List(s.stranger)
+	s.Require().NoError(err)
+	s.Empty(clients)
+
+	clients, err = s.repo.Client.Page(s.stranger, entities.ClientFilter{Limit: 10})
+	s.Require().NoError(err)
+	s.Empty(clients)
+
+	count, err := s.repo.Client.Count(s.stranger)
+	s.Require().NoError(err)
+	s.Zero(count)
+
+	got, err := s.repo.Client.Get(s.owner, client.ID.Hex())
+	s.Require().NoError(err)
+	s.Equal(client, got, "the stranger must not change the client")
+}
+
+func (s *TenantSuite) TestSystemContext() {
+	client := s.createClient()
+
+	got, err := s.repo.Client.Get(tenant.System(context.Background()), client.ID.Hex())
+	s.Require().NoError(err)
+	s.Equal(client.TenantID, got.TenantID)
+}
+
+func (s *TenantSuite) TestContextWithoutTenant() {
+	client := s.createClient()
+
+	_, err := s.repo.Client.Get(context.Background(), client.ID.Hex())
+	s.ErrorIs(err, tenant.ErrNoTenant)
+
+	_, err = s.repo.Client.List(context.Background())
+	s.ErrorIs(err, tenant.ErrNoTenant)
+
+	_, err = s.repo.Client.Create(context.Background(), &entities.Client{})
+	s.ErrorIs(err, tenant.ErrNoTenant)
+
+	_, err = s.repo.Incident.List(context.Background(), entities.IncidentFilter{})
+	s.ErrorIs(err, tenant.ErrNoTenant)
+}
+
+func (s *TenantSuite) TestZoneIsolation() {
+	id, err := s.repo.Zone.Create(s.owner, &entities.Zone{Name: "zone"})
+	s.Require().NoError(err)
+
+	_, err = s.repo.Zone.Get(s.stranger, id)
+	s.ErrorIs(err, repository.ErrZoneNotFound)
+
+	zones, err := s.repo.Zone.List(s.stranger)
+	s.Require().NoError(err)
+	s.Empty(zones)
+}
+
+func (s *TenantSuite) TestDetectionIsolation() {
+	var (
+		client     = s.createClient()
+		incidentID = primitive.NewObjectID()
+		now        = time.Now().UTC().Truncate(time.Millisecond)
+		expiresAt  = now.Add(time.Hour)
+	)
+
+	_, err := s.repo.Detection.Create(s.owner, &entities.Detection{
+		ClientID: client.ID, IncidentID: incidentID, Label: "gunshot", Timestamp: now, ExpiresAt: &expiresAt,
+	})
+	s.Require().NoError(err)
+
+	detections, err := s.repo.Detection.List(s.stranger, entities.DetectionFilter{ClientID: client.ID})
+	s.Require().NoError(err)
+	s.Empty(detections)
+
+	count, err := s.repo.Detection.Count(s.stranger, client.ID, now.Add(-time.Minute), now.Add(time.Minute))
+	s.Require().NoError(err)
+	s.Zero(count)
+
+	count, err = s.repo.Detection.CountExpiring(s.stranger, expiresAt.Add(time.Minute))
+	s.Require().NoError(err)
+	s.Zero(count)
+
+	// writes of the stranger by the incident id don't reach detections of the owner
+	s.Require().NoError(s.repo.Detection.MarkFalsePositive(s.stranger, incidentID.Hex()))
+	s.Require().NoError(s.repo.Detection.Hold(s.stranger, incidentID))
+
+	detections, err = s.repo.Detection.List(s.owner, entities.DetectionFilter{IncidentID: incidentID})
+	s.Require().NoError(err)
+	s.Require().Len(detections, 1)
+	s.False(detections[0].FalsePositive, "the stranger must not mark the detection")
+	s.NotNil(detections[0].ExpiresAt, "the stranger must not hold the detection")
+}
+
+func (s *TenantSuite) TestIncidentIsolation() {
+	var (
+		client = s.createClient()
+		now    = time.Now().UTC().Truncate(time.Millisecond)
+	)
+
+	id, err := s.repo.Incident.Create(s.owner, &entities.Incident{
+		Latitude: client.Latitude, Longitude: client.Longitude, ShotCount: 1, FirstSeen: now, LastSeen: now,
+		Clients: []primitive.ObjectID{client.ID}, Status: entities.IncidentNew,
+	})
+	s.Require().NoError(err)
+	incidentID, err := primitive.ObjectIDFromHex(id)
+	s.Require().NoError(err)
+
+	_, err = s.repo.Incident.Get(s.stranger, id)
+	s.ErrorIs(err, repository.ErrIncidentNotFound)
+
+	active, err := s.repo.Incident.FindActive(s.stranger, now.Add(-time.Minute))
+	s.Require().NoError(err)
+	s.Empty(active)
+
+	incidents, err := s.repo.Incident.List(s.stranger, entities.IncidentFilter{})
+	s.Require().NoError(err)
+	s.Empty(incidents)
+
+	// detections of the stranger can't be merged into the incident of the owner
+	_, err = s.repo.Incident.Merge(s.stranger, incidentID, entities.Client{ID: primitive.NewObjectID()}, now)
+	s.ErrorIs(err, repository.ErrIncidentNotFound)
+
+	err = s.repo.Incident.Transition(s.stranger, id, entities.IncidentTransition{
+		From: entities.IncidentNew, To: entities.IncidentResolved,
+	})
+	s.ErrorIs(err, repository.ErrIncidentStatusChanged)
+
+	got, err := s.repo.Incident.Get(s.owner, id)
+	s.Require().NoError(err)
+	s.Equal(1, got.ShotCount, "the stranger must not merge into the incident")
+	s.Equal(entities.IncidentNew, got.Status, "the stranger must not close the incident")
+
+	active, err = s.repo.Incident.FindActive(s.owner, now.Add(-time.Minute))
+	s.Require().NoError(err)
+	s.Require().Len(active, 1)
+	s.Equal(incidentID, active[0].ID)
+}
+
+// TestZoneAndRuleWrites checks that writes return the documents they've changed, so the audited diff is the
+// diff of the write
+func (s *TenantSuite) TestZoneAndRuleWrites() {
+	zoneID, err := s.repo.Zone.Create(s.owner, &entities.Zone{Name: "before", Owner: "owner"})
+	s.Require().NoError(err)
+
+	_, _, err = s.repo.Zone.Update(s.stranger, zoneID, &entities.Zone{Name: "after"})
+	s.ErrorIs(err, repository.ErrZoneNotFound)
+
+	before, after, err := s.repo.Zone.Update(s.owner, zoneID, &entities.Zone{Name: "after", Owner: "owner"})
+	s.Require().NoError(err)
+	s.Equal("before", before.Name)
+	s.Equal("after", after.Name)
+	s.Equal(before.ID, after.ID)
+
+	deleted, err := s.repo.Zone.Delete(s.owner, zoneID)
+	s.Require().NoError(err)
+	s.Equal(after.Name, deleted.Name)
+
+	ruleID, err := s.repo.AlertRule.Create(s.owner, &entities.AlertRule{
+		Name: "rule", ClientID: primitive.NewObjectID(), Expression: "count > 1", Window: 60, Enabled: true,
+	})
+	s.Require().NoError(err)
+
+	zoneRule := &entities.AlertRule{Name: "rule", ZoneID: primitive.NewObjectID(), Expression: "count > 2", Window: 60}
+	beforeRule, afterRule, err := s.repo.AlertRule.Update(s.owner, ruleID, zoneRule)
+	s.Require().NoError(err)
+	s.True(beforeRule.Enabled)
+	s.False(afterRule.Enabled)
+	s.True(afterRule.ClientID.IsZero())
+	s.Equal(zoneRule.ZoneID, afterRule.ZoneID)
+
+	stored, err := s.repo.AlertRule.Get(s.owner, ruleID)
+	s.Require().NoError(err)
+	s.Equal(afterRule, stored)
+
+	_, err = s.repo.AlertRule.Delete(s.stranger, ruleID)
+	s.ErrorIs(err, repository.ErrAlertRuleNotFound)
+}
+
+func (s *TenantSuite) TestWebhookIsolation() {
+	id, err := s.repo.Webhook.Create(s.owner, &entities.Webhook{
+		URL: "https://example.com/hook", Events: []entities.WebhookEvent{entities.WebhookAlert}, Secret: "secret",
+	})
+	s.Require().NoError(err)
+
+	_, err = s.repo.Webhook.Get(s.stranger, id)
+	s.ErrorIs(err, repository.ErrWebhookNotFound)
+
+	webhooks, err := s.repo.Webhook.List(s.stranger, entities.WebhookAlert)
+	s.Require().NoError(err)
+	s.Empty(webhooks)
+
+	_, err = s.repo.Webhook.Delete(s.stranger, id)
+	s.ErrorIs(err, repository.ErrWebhookNotFound)
+
+	webhooks, err = s.repo.Webhook.List(s.owner, entities.WebhookIncidentTransition)
+	s.Require().NoError(err)
+	s.Empty(webhooks)
+
+	webhooks, err = s.repo.Webhook.List(s.owner, entities.WebhookAlert)
+	s.Require().NoError(err)
+	s.Require().Len(webhooks, 1)
+	s.Equal("secret", webhooks[0].Secret)
+
+	deleted, err := s.repo.Webhook.Delete(s.owner, id)
+	s.Require().NoError(err)
+	s.Equal(id, deleted.ID.Hex())
+}
+
+func (s *TenantSuite) TestConcurrentSensorReservations() {
+	organization := entities.Organization{Name: "quota"}
+	_, err := s.repo.Organization.Create(context.Background(), &organization)
+	s.Require().NoError(err)
+
+	var (
+		wg       sync.WaitGroup
+		reserved int32
+	)
+
+	for n := 0; n < 10; n++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			ok, err := s.repo.Organization.ReserveSensors(context.Background(), organization.ID, 1, 3)
+			s.NoError(err)
+
+			if ok {
+				atomic.AddInt32(&reserved, 1)
+			}
+		}()
+	}
+
+	wg.Wait()
+	s.Equal(int32(3), reserved)
+
+	s.Require().NoError(s.repo.Organization.ReleaseSensors(context.Background(), organization.ID, 1))
+
+	ok, err := s.repo.Organization.ReserveSensors(context.Background(), organization.ID, 1, 3)
+	s.Require().NoError(err)
+	s.True(ok, "the released place must be taken again")
+}
+
+func (s *TenantSuite) TestSensorCounterInit() {
+	organization := entities.Organization{Name: "before the counter"}
+	_, err := s.repo.Organization.Create(context.Background(), &organization)
+	s.Require().NoError(err)
+
+	_, err = s.dbClient.Database(_dbName).Collection("Organizations").
+		UpdateByID(context.Background(), organization.ID, bson.M{"$unset": bson.M{"sensors": ""}})
+	s.Require().NoError(err)
+
+	ok, err := s.repo.Organization.ReserveSensors(context.Background(), organization.ID, 1, 0)
+	s.Require().NoError(err)
+	s.False(ok, "the missing counter must be set up first")
+
+	initialized, err := s.repo.Organization.InitSensors(context.Background(), organization.ID, 2)
+	s.Require().NoError(err)
+	s.True(initialized)
+
+	initialized, err = s.repo.Organization.InitSensors(context.Background(), organization.ID, 5)
+	s.Require().NoError(err)
+	s.False(initialized)
+
+	got, err := s.repo.Organization.Get(context.Background(), organization.ID.Hex())
+	s.Require().NoError(err)
+	s.Equal(int64(2), got.Sensors)
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/tenant"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type WebhookRepo struct {
+	collection *mongo.Collection
+	tracer     trace.Tracer
+}
+
+func (w WebhookRepo) Create(ctx context.Context, webhook *entities.Webhook) (string, error) {
+	ctx, span := w.tracer.Start(ctx, "WebhookRepo.Create")
+	defer span.End()
+
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	webhook.ID = primitive.NewObjectID()
+	webhook.TenantID = tenantID
+
+	if _, err := w.collection.InsertOne(ctx, webhook); err != nil {
+		span.RecordError(err)
+		return "", errors.Wrap(err, "error during create webhook")
+	}
+
+	return webhook.ID.Hex(), nil
+}
+
+func (w WebhookRepo) Get(ctx context.Context, id string) (entities.Webhook, error) {
+	ctx, span := w.tracer.Start(ctx, "WebhookRepo.Get")
+	defer span.End()
+
+	castedID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return entities.Webhook{}, invalidID(err, "webhook")
+	}
+
+	filter, err := scoped(ctx, bson.M{"_id": castedID})
+	if err != nil {
+		return entities.Webhook{}, err
+	}
+
+	var webhook entities.Webhook
+	if err := w.collection.FindOne(ctx, filter).Decode(&webhook); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return entities.Webhook{}, ErrWebhookNotFound
+		}
+
+		span.RecordError(err)
+		return entities.Webhook{}, errors.Wrap(err, "error during get webhook from db")
+	}
+
+	return webhook, nil
+}
+
+// List returns webhooks of the tenant, the non-empty event selects the ones subscribed to it
+func (w WebhookRepo) List(ctx context.Context, event entities.WebhookEvent) ([]entities.Webhook, error) {
+	ctx, span := w.tracer.Start(ctx, "WebhookRepo.List")
+	defer span.End()
+
+	filter, err := scoped(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	if event != "" {
+		filter["events"] = event
+	}
+
+	cursor, err := w.collection.Find(ctx, filter, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during find webhooks")
+	}
+
+	webhooks := make([]entities.Webhook, 0)
+	if err := cursor.All(ctx, &webhooks); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during decode webhooks")
+	}
+
+	return webhooks, nil
+}
+
+// Delete removes the webhook and returns it as it was removed
+func (w WebhookRepo) Delete(ctx context.Context, id string) (entities.Webhook, error) {
+	ctx, span := w.tracer.Start(ctx, "WebhookRepo.Delete")
+	defer span.End()
+
+	castedID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return entities.Webhook{}, invalidID(err, "webhook")
+	}
+
+	filter, err := scoped(ctx, bson.M{"_id": castedID})
+	if err != nil {
+		return entities.Webhook{}, err
+	}
+
+	var deleted entities.Webhook
+	if err := w.collection.FindOneAndDelete(ctx, filter).Decode(&deleted); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return entities.Webhook{}, ErrWebhookNotFound
+		}
+
+		span.RecordError(err)
+		return entities.Webhook{}, errors.Wrap(err, "error during delete webhook")
+	}
+
+	return deleted, nil
+}
+
+func NewWebhookRepo(database *mongo.Database) *WebhookRepo {
+	return &WebhookRepo{
+		collection: database.Collection(_webhooksCollection),
+		tracer:     otel.Tracer("WebhookRepo"),
+	}
+}
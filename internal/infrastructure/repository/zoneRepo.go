@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/tenant"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type ZoneRepo struct {
+	collection *mongo.Collection
+	tracer     trace.Tracer
+}
+
+func (z ZoneRepo) Create(ctx context.Context, zone *entities.Zone) (string, error) {
+	ctx, span := z.tracer.Start(ctx, "ZoneRepo.Create")
+	defer span.End()
+
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	zone.ID = primitive.NewObjectID()
+	zone.TenantID = tenantID
+
+	if _, err := z.collection.InsertOne(ctx, zone); err != nil {
+		span.RecordError(err)
+		return "", errors.Wrap(err, "error during create zone")
+	}
+
+	return zone.ID.Hex(), nil
+}
+
+func (z ZoneRepo) Get(ctx context.Context, id string) (entities.Zone, error) {
+	ctx, span := z.tracer.Start(ctx, "ZoneRepo.Get")
+	defer span.End()
+
+	castedID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return entities.Zone{}, invalidID(err, "zone")
+	}
+
+	filter, err := scoped(ctx, bson.M{"_id": castedID})
+	if err != nil {
+		return entities.Zone{}, err
+	}
+
+	var zone entities.Zone
+	if err := z.collection.FindOne(ctx, filter).Decode(&zone); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return entities.Zone{}, ErrZoneNotFound
+		}
+
+		span.RecordError(err)
+		return entities.Zone{}, errors.Wrap(err, "error during get zone from db")
+	}
+
+	return zone, nil
+}
+
+// Update replaces fields of the zone, the zone is returned as it was before and after the update. Both are
+// taken by one write, so concurrent writes can't get between them
+func (z ZoneRepo) Update(ctx context.Context, id string, zone *entities.Zone) (entities.Zone, entities.Zone, error) {
+	ctx, span := z.tracer.Start(ctx, "ZoneRepo.Update")
+	defer span.End()
+
+	castedID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return entities.Zone{}, entities.Zone{}, invalidID(err, "zone")
+	}
+
+	filter, err := scoped(ctx, bson.M{"_id": castedID})
+	if err != nil {
+		return entities.Zone{}, entities.Zone{}, err
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"name":     zone.Name,
+			"owner":    zone.Owner,
+			"metadata": zone.Metadata,
+			"area":     zone.Area,
+		},
+	}
+
+	var before entities.Zone
+	if err := z.collection.FindOneAndUpdate(ctx, filter, update).Decode(&before); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return entities.Zone{}, entities.Zone{}, ErrZoneNotFound
+		}
+
+		span.RecordError(err)
+		return entities.Zone{}, entities.Zone{}, errors.Wrap(err, "error during update zone")
+	}
+
+	after := before
+	after.Name, after.Owner, after.Metadata, after.Area = zone.Name, zone.Owner, zone.Metadata, zone.Area
+
+	return before, after, nil
+}
+
+// Delete removes the zone and returns it as it was removed
+func (z ZoneRepo) Delete(ctx context.Context, id string) (entities.Zone, error) {
+	ctx, span := z.tracer.Start(ctx, "ZoneRepo.Delete")
+	defer span.End()
+
+	castedID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return entities.Zone{}, invalidID(err, "zone")
+	}
+
+	filter, err := scoped(ctx, bson.M{"_id": castedID})
+	if err != nil {
+		return entities.Zone{}, err
+	}
+
+	var deleted entities.Zone
+	if err := z.collection.FindOneAndDelete(ctx, filter).Decode(&deleted); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return entities.Zone{}, ErrZoneNotFound
+		}
+
+		span.RecordError(err)
+		return entities.Zone{}, errors.Wrap(err, "error during delete zone")
+	}
+
+	return deleted, nil
+}
+
+func (z ZoneRepo) List(ctx context.Context) ([]entities.Zone, error) {
+	ctx, span := z.tracer.Start(ctx, "ZoneRepo.List")
+	defer span.End()
+
+	filter, err := scoped(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := z.collection.Find(ctx, filter)
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during list zones")
+	}
+
+	zones := make([]entities.Zone, 0)
+	if err := cursor.All(ctx, &zones); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "error during decode zones")
+	}
+
+	return zones, nil
+}
+
+func NewZoneRepo(database *mongo.Database) *ZoneRepo {
+	return &ZoneRepo{
+		collection: database.Collection(_zonesCollection),
+		tracer:     otel.Tracer("ZoneRepo"),
+	}
+}
@@ -0,0 +1,63 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/pkg/webhook"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTPSender POSTs deliveries to webhooks signed by their secrets
+type HTTPSender struct {
+	client *http.Client
+	tracer trace.Tracer
+}
+
+func NewHTTPSender(timeout time.Duration) *HTTPSender {
+	return &HTTPSender{
+		client: &http.Client{Timeout: timeout},
+		tracer: otel.Tracer("webhooks"),
+	}
+}
+
+// Deliver fails unless the webhook answers with 2xx
+func (s *HTTPSender) Deliver(ctx context.Context, hook entities.Webhook, delivery webhook.Delivery) error {
+	ctx, span := s.tracer.Start(ctx, "webhooks.Deliver")
+	defer span.End()
+
+	body, err := json.Marshal(delivery)
+	if err != nil {
+		return errors.Wrap(err, "error during encode delivery")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "error during create webhook request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhook.SignatureHeader, webhook.Sign(hook.Secret, time.Now(), body))
+	req.Header.Set(webhook.EventHeader, string(delivery.Event))
+	req.Header.Set(webhook.DeliveryHeader, delivery.ID)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "error during send webhook request")
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("the webhook has answered %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,192 @@
+// Package kms wraps data keys with key encryption keys. The KMS interface hides where the key encryption
+// keys live; Keyfile keeps them in a local file and is the default implementation.
+package kms
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+const keySize = 32
+
+var (
+	ErrInvalidKeyfile = errors.New("invalid keyfile")
+	ErrUnknownKey     = errors.New("the key encryption key is unknown")
+	ErrUnwrap         = errors.New("can't unwrap the data key")
+)
+
+// WrappedKey is the data key encrypted by the key encryption key with the id
+type WrappedKey struct {
+	KeyID      string
+	Ciphertext []byte
+}
+
+type KMS interface {
+	// CurrentKeyID is the key new data keys are wrapped with, older keys only unwrap
+	CurrentKeyID() string
+	Wrap(ctx context.Context, dataKey []byte) (WrappedKey, error)
+	Unwrap(ctx context.Context, key WrappedKey) ([]byte, error)
+}
+
+// Keyfile keeps AES-256 key encryption keys in memory. The file is JSON:
+//
+//	{"current": "2023-01", "keys": {"2022-12": "<base64 32 bytes>", "2023-01": "<base64 32 bytes>"}}
+//
+// The key is rotated by adding a new one and making it current, the old one stays until nothing
+// is wrapped with it
+type Keyfile struct {
+	current string
+	keys    map[string]cipher.AEAD
+}
+
+type keyfileJSON struct {
+	Current string            `json:"current"`
+	Keys    map[string]string `json:"keys"`
+}
+
+// LoadKeyfile reads keys from the file
+func LoadKeyfile(path string) (*Keyfile, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKeyfile, err)
+	}
+
+	var file keyfileJSON
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKeyfile, err)
+	}
+
+	keys := make(map[string][]byte, len(file.Keys))
+	for id, encoded := range file.Keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("%w: key %q is not base64", ErrInvalidKeyfile, id)
+		}
+		keys[id] = key
+	}
+
+	return NewKeyfile(file.Current, keys)
+}
+
+// LoadOrCreateKeyfile reads keys from the file, the missing file is created with one random key. The file
+// is the only copy of the key, the audio encrypted with it is lost with the file
+func LoadOrCreateKeyfile(path string) (keyfile *Keyfile, created bool, err error) {
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		keyfile, err = LoadKeyfile(path)
+		return keyfile, false, err
+	}
+
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, false, err
+	}
+
+	id := time.Now().UTC().Format("2006-01")
+	raw, err := json.Marshal(keyfileJSON{
+		Current: id, Keys: map[string]string{id: base64.StdEncoding.EncodeToString(key)},
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	// O_EXCL keeps the key of the instance which has created the file first
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			keyfile, err = LoadKeyfile(path)
+			return keyfile, false, err
+		}
+		return nil, false, err
+	}
+
+	if _, err := file.Write(raw); err != nil {
+		_ = file.Close()
+		_ = os.Remove(path)
+		return nil, false, err
+	}
+	if err := file.Close(); err != nil {
+		_ = os.Remove(path)
+		return nil, false, err
+	}
+
+	keyfile, err = NewKeyfile(id, map[string][]byte{id: key})
+	return keyfile, true, err
+}
+
+// NewKeyfile creates the KMS from raw 32 bytes keys, the current one wraps new data keys
+func NewKeyfile(current string, keys map[string][]byte) (*Keyfile, error) {
+	if _, ok := keys[current]; !ok {
+		return nil, fmt.Errorf("%w: the current key %q is missing", ErrInvalidKeyfile, current)
+	}
+
+	k := &Keyfile{current: current, keys: make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		if len(key) != keySize {
+			return nil, fmt.Errorf("%w: key %q must be %d bytes", ErrInvalidKeyfile, id, keySize)
+		}
+
+		aead, err := newGCM(key)
+		if err != nil {
+			return nil, err
+		}
+		k.keys[id] = aead
+	}
+
+	return k, nil
+}
+
+func (k *Keyfile) CurrentKeyID() string {
+	return k.current
+}
+
+// Wrap encrypts the data key with the current key, the nonce is prepended to the ciphertext
+// and the key id is authenticated
+func (k *Keyfile) Wrap(_ context.Context, dataKey []byte) (WrappedKey, error) {
+	aead := k.keys[k.current]
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return WrappedKey{}, err
+	}
+
+	return WrappedKey{
+		KeyID:      k.current,
+		Ciphertext: aead.Seal(nonce, nonce, dataKey, []byte(k.current)),
+	}, nil
+}
+
+func (k *Keyfile) Unwrap(_ context.Context, key WrappedKey) ([]byte, error) {
+	aead, ok := k.keys[key.KeyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, key.KeyID)
+	}
+
+	if len(key.Ciphertext) < aead.NonceSize() {
+		return nil, ErrUnwrap
+	}
+
+	nonce, ciphertext := key.Ciphertext[:aead.NonceSize()], key.Ciphertext[aead.NonceSize():]
+	dataKey, err := aead.Open(nil, nonce, ciphertext, []byte(key.KeyID))
+	if err != nil {
+		return nil, ErrUnwrap
+	}
+
+	return dataKey, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
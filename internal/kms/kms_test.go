@@ -0,0 +1,109 @@
+package kms_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"github.com/Imm0bilize/gunshot-api-service/internal/kms"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestKeyfileRotation(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		dataKey = bytes.Repeat([]byte{7}, 32)
+		old     = bytes.Repeat([]byte{1}, 32)
+		current = bytes.Repeat([]byte{2}, 32)
+	)
+
+	before, err := kms.NewKeyfile("old", map[string][]byte{"old": old})
+	require.NoError(t, err)
+
+	wrapped, err := before.Wrap(ctx, dataKey)
+	require.NoError(t, err)
+	require.Equal(t, "old", wrapped.KeyID)
+
+	// after the rotation the old key only unwraps
+	after, err := kms.NewKeyfile("current", map[string][]byte{"old": old, "current": current})
+	require.NoError(t, err)
+	require.Equal(t, "current", after.CurrentKeyID())
+
+	got, err := after.Unwrap(ctx, wrapped)
+	require.NoError(t, err)
+	require.Equal(t, dataKey, got)
+
+	rewrapped, err := after.Wrap(ctx, got)
+	require.NoError(t, err)
+	require.Equal(t, "current", rewrapped.KeyID)
+
+	// the key id is authenticated, the ciphertext can't be claimed by another key
+	_, err = after.Unwrap(ctx, kms.WrappedKey{KeyID: "current", Ciphertext: wrapped.Ciphertext})
+	require.ErrorIs(t, err, kms.ErrUnwrap)
+
+	_, err = before.Unwrap(ctx, rewrapped)
+	require.ErrorIs(t, err, kms.ErrUnknownKey)
+}
+
+func TestLoadKeyfile(t *testing.T) {
+	dir := t.TempDir()
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+
+	testTable := []struct {
+		name    string
+		content string
+		expErr  error
+	}{
+		{name: "valid", content: `{"current": "k1", "keys": {"k1": "` + key + `"}}`},
+		{name: "no current key", content: `{"current": "k2", "keys": {"k1": "` + key + `"}}`, expErr: kms.ErrInvalidKeyfile},
+		{name: "short key", content: `{"current": "k1", "keys": {"k1": "AAAA"}}`, expErr: kms.ErrInvalidKeyfile},
+		{name: "not json", content: `k1`, expErr: kms.ErrInvalidKeyfile},
+	}
+
+	for i, tCase := range testTable {
+		t.Run(tCase.name, func(t *testing.T) {
+			path := filepath.Join(dir, string(rune('a'+i))+".json")
+			require.NoError(t, os.WriteFile(path, []byte(tCase.content), 0o600))
+
+			_, err := kms.LoadKeyfile(path)
+			require.ErrorIs(t, err, tCase.expErr)
+		})
+	}
+}
+
+func TestLoadOrCreateKeyfile(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		path    = filepath.Join(t.TempDir(), "keyfile.json")
+		dataKey = bytes.Repeat([]byte{7}, 32)
+	)
+
+	created, isNew, err := kms.LoadOrCreateKeyfile(path)
+	require.NoError(t, err)
+	require.True(t, isNew)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	wrapped, err := created.Wrap(ctx, dataKey)
+	require.NoError(t, err)
+
+	// the next start loads the same key
+	loaded, isNew, err := kms.LoadOrCreateKeyfile(path)
+	require.NoError(t, err)
+	require.False(t, isNew)
+	require.Equal(t, created.CurrentKeyID(), loaded.CurrentKeyID())
+
+	got, err := loaded.Unwrap(ctx, wrapped)
+	require.NoError(t, err)
+	require.Equal(t, dataKey, got)
+
+	// the invalid file isn't replaced
+	invalid := filepath.Join(t.TempDir(), "keyfile.json")
+	require.NoError(t, os.WriteFile(invalid, []byte("k1"), 0o600))
+	_, _, err = kms.LoadOrCreateKeyfile(invalid)
+	require.ErrorIs(t, err, kms.ErrInvalidKeyfile)
+}
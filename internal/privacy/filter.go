@@ -0,0 +1,167 @@
+package privacy
+
+import (
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/pkg/errors"
+	"math"
+)
+
+var ErrUnknownMode = errors.New("unknown mode of the privacy filter")
+
+const (
+	// SpeechLowHz and SpeechHighHz bound the telephone speech band
+	SpeechLowHz  = 300
+	SpeechHighHz = 3400
+
+	// _fade smooths edges of impulse windows, so the filter doesn't add clicks
+	_fade = 0.005 // seconds
+)
+
+// Q factors of two cascaded sections of the 4th order Butterworth filter
+var _butterworthQ = [...]float64{0.5412, 1.3066}
+
+// Apply filters the audio by the mode and returns the new file with the tag of the applied filter,
+// the caller's audio is not changed. The layout of the file (header and other chunks) is kept
+func Apply(mode entities.PrivacyMode, audio []byte) ([]byte, entities.AppliedFilter, error) {
+	if mode != entities.PrivacySpeechBand && mode != entities.PrivacyImpulsesOnly {
+		return nil, entities.AppliedFilter{}, ErrUnknownMode
+	}
+
+	file, err := decodeWAV(audio)
+	if err != nil {
+		return nil, entities.AppliedFilter{}, err
+	}
+
+	windows := detectImpulses(file.mono(), file.sampleRate)
+	envelope := windowEnvelope(windows, len(file.data)/(file.channels*2), file.sampleRate)
+
+	applied := entities.AppliedFilter{
+		Mode:    mode,
+		Windows: make([]entities.ImpulseWindow, 0, len(windows)),
+	}
+	if mode == entities.PrivacySpeechBand {
+		applied.LowHz, applied.HighHz = SpeechLowHz, SpeechHighHz
+	}
+	for _, w := range windows {
+		applied.Windows = append(applied.Windows, entities.ImpulseWindow{
+			StartMs: int64(w.start) * 1000 / int64(file.sampleRate),
+			EndMs:   int64(w.end) * 1000 / int64(file.sampleRate),
+		})
+	}
+
+	for channel := 0; channel < file.channels; channel++ {
+		samples := file.samples(channel)
+
+		switch mode {
+		case entities.PrivacySpeechBand:
+			outside := removeSpeechBand(samples, file.sampleRate)
+			for i := range samples {
+				samples[i] = envelope[i]*samples[i] + (1-envelope[i])*outside[i]
+			}
+		case entities.PrivacyImpulsesOnly:
+			for i := range samples {
+				samples[i] *= envelope[i]
+			}
+		}
+
+		file.setSamples(channel, samples)
+	}
+
+	return file.raw, applied, nil
+}
+
+// removeSpeechBand keeps the audio below SpeechLowHz and above SpeechHighHz
+func removeSpeechBand(samples []float64, sampleRate int) []float64 {
+	out := make([]float64, len(samples))
+
+	low := samples
+	for _, q := range _butterworthQ {
+		low = newLowPass(SpeechLowHz, q, sampleRate).process(low)
+	}
+	copy(out, low)
+
+	// there is nothing above the band if the rate can't carry it
+	if float64(SpeechHighHz) < float64(sampleRate)/2 {
+		high := samples
+		for _, q := range _butterworthQ {
+			high = newHighPass(SpeechHighHz, q, sampleRate).process(high)
+		}
+		for i := range out {
+			out[i] += high[i]
+		}
+	}
+
+	return out
+}
+
+// windowEnvelope is 1 inside windows and 0 outside of them, with linear fades around the edges
+func windowEnvelope(windows []window, length, sampleRate int) []float64 {
+	envelope := make([]float64, length)
+	fade := int(math.Ceil(_fade * float64(sampleRate)))
+
+	for _, w := range windows {
+		for i := w.start - fade; i < w.end+fade; i++ {
+			if i < 0 || i >= length {
+				continue
+			}
+
+			gain := 1.0
+			if i < w.start {
+				gain = float64(fade-(w.start-i)) / float64(fade)
+			} else if i >= w.end {
+				gain = float64(fade-(i-w.end+1)) / float64(fade)
+			}
+			envelope[i] = math.Max(envelope[i], gain)
+		}
+	}
+
+	return envelope
+}
+
+// biquad is the filter section from the Audio EQ Cookbook (R. Bristow-Johnson)
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+}
+
+func newLowPass(cutoff, q float64, sampleRate int) biquad {
+	w0 := 2 * math.Pi * cutoff / float64(sampleRate)
+	alpha, cos := math.Sin(w0)/(2*q), math.Cos(w0)
+	a0 := 1 + alpha
+
+	return biquad{
+		b0: (1 - cos) / 2 / a0,
+		b1: (1 - cos) / a0,
+		b2: (1 - cos) / 2 / a0,
+		a1: -2 * cos / a0,
+		a2: (1 - alpha) / a0,
+	}
+}
+
+func newHighPass(cutoff, q float64, sampleRate int) biquad {
+	w0 := 2 * math.Pi * cutoff / float64(sampleRate)
+	alpha, cos := math.Sin(w0)/(2*q), math.Cos(w0)
+	a0 := 1 + alpha
+
+	return biquad{
+		b0: (1 + cos) / 2 / a0,
+		b1: -(1 + cos) / a0,
+		b2: (1 + cos) / 2 / a0,
+		a1: -2 * cos / a0,
+		a2: (1 - alpha) / a0,
+	}
+}
+
+func (f biquad) process(in []float64) []float64 {
+	var (
+		out            = make([]float64, len(in))
+		x1, x2, y1, y2 float64
+	)
+	for i, x := range in {
+		y := f.b0*x + f.b1*x1 + f.b2*x2 - f.a1*y1 - f.a2*y2
+		x2, x1 = x1, x
+		y2, y1 = y1, y
+		out[i] = y
+	}
+
+	return out
+}
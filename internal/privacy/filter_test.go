@@ -0,0 +1,120 @@
+package privacy_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/privacy"
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+)
+
+const _sampleRate = 16000
+
+// speechWithShot is one second of the 1 kHz tone (the speech band) with the impulse at 500 ms
+func speechWithShot() []int16 {
+	samples := make([]int16, _sampleRate)
+	for i := range samples {
+		samples[i] = int16(3000 * math.Sin(2*math.Pi*1000*float64(i)/_sampleRate))
+	}
+	for i := 0; i < 80; i++ {
+		samples[_sampleRate/2+i] = int16(30000 * math.Exp(-float64(i)/20))
+	}
+
+	return samples
+}
+
+func encodeWAV(samples []int16) []byte {
+	var data bytes.Buffer
+	_ = binary.Write(&data, binary.LittleEndian, samples)
+
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(36+data.Len()))
+	buf.WriteString("WAVEfmt ")
+	for _, field := range []interface{}{
+		uint32(16), uint16(1), uint16(1), uint32(_sampleRate), uint32(_sampleRate * 2), uint16(2), uint16(16),
+	} {
+		_ = binary.Write(&buf, binary.LittleEndian, field)
+	}
+	buf.WriteString("data")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(data.Len()))
+	buf.Write(data.Bytes())
+
+	return buf.Bytes()
+}
+
+func decodeSamples(t *testing.T, audio []byte) []int16 {
+	samples := make([]int16, (len(audio)-44)/2)
+	require.NoError(t, binary.Read(bytes.NewReader(audio[44:]), binary.LittleEndian, samples))
+
+	return samples
+}
+
+// rms of the range in seconds
+func rms(samples []int16, from, to float64) float64 {
+	var sum float64
+	for _, sample := range samples[int(from*_sampleRate):int(to*_sampleRate)] {
+		sum += float64(sample) * float64(sample)
+	}
+
+	return math.Sqrt(sum / ((to - from) * _sampleRate))
+}
+
+func TestApply(t *testing.T) {
+	source := speechWithShot()
+
+	testTable := []struct {
+		name string
+		mode entities.PrivacyMode
+		// maxRMS is the level of the tone left outside the window
+		maxRMS float64
+		lowHz  int
+	}{
+		{
+			name:   "speech band is attenuated",
+			mode:   entities.PrivacySpeechBand,
+			maxRMS: 0.1 * rms(source, 0.1, 0.4),
+			lowHz:  privacy.SpeechLowHz,
+		},
+		{
+			name:   "only impulses are kept",
+			mode:   entities.PrivacyImpulsesOnly,
+			maxRMS: 0,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			audio := encodeWAV(source)
+			original := append([]byte(nil), audio...)
+
+			filtered, applied, err := privacy.Apply(testCase.mode, audio)
+			require.NoError(t, err)
+			require.Equal(t, original, audio, "the source must not be changed")
+			require.Equal(t, original[:44], filtered[:44], "the header must be kept")
+
+			require.Equal(t, testCase.mode, applied.Mode)
+			require.Equal(t, testCase.lowHz, applied.LowHz)
+			require.Len(t, applied.Windows, 1)
+			require.InDelta(t, 480, applied.Windows[0].StartMs, 5)
+			require.InDelta(t, 800, applied.Windows[0].EndMs, 5)
+
+			samples := decodeSamples(t, filtered)
+			require.LessOrEqual(t, rms(samples, 0.1, 0.4), testCase.maxRMS)
+			require.LessOrEqual(t, rms(samples, 0.85, 0.95), testCase.maxRMS)
+
+			// the impulse is untouched
+			require.Equal(t, source[_sampleRate/2-100:_sampleRate/2+1000], samples[_sampleRate/2-100:_sampleRate/2+1000])
+		})
+	}
+}
+
+func TestApplyUnsupported(t *testing.T) {
+	_, _, err := privacy.Apply(entities.PrivacySpeechBand, []byte("ID3 mp3 frames"))
+	require.ErrorIs(t, err, privacy.ErrUnsupportedAudio)
+
+	_, _, err = privacy.Apply("lowpass", encodeWAV(speechWithShot()))
+	require.ErrorIs(t, err, privacy.ErrUnknownMode)
+}
@@ -0,0 +1,89 @@
+package privacy
+
+import "sort"
+
+const (
+	_frame = 0.002 // seconds
+	// an impulse is the frame louder than the background by 15 dB, which rises by 10 dB within two frames:
+	// the shot does it within milliseconds, the speech much slower
+	_impulseRatio = 30
+	_impulseRise  = 10
+	// _impulseFloor (-26 dBFS) ignores impulses of the quiet background
+	_impulseFloor = 0.0025
+	// windows start a bit before the onset and keep the echo of the shot
+	_windowBefore = 0.02 // seconds
+	_windowAfter  = 0.3  // seconds
+)
+
+// window is the range of samples [start, end)
+type window struct {
+	start, end int
+}
+
+// detectImpulses finds onsets of short loud sounds by the energy of frames and returns merged windows
+// around them
+func detectImpulses(samples []float64, sampleRate int) []window {
+	frame := int(_frame * float64(sampleRate))
+	if frame == 0 {
+		frame = 1
+	}
+
+	energies := make([]float64, 0, len(samples)/frame+1)
+	for start := 0; start < len(samples); start += frame {
+		end := start + frame
+		if end > len(samples) {
+			end = len(samples)
+		}
+
+		var energy float64
+		for _, sample := range samples[start:end] {
+			energy += sample * sample
+		}
+		energies = append(energies, energy/float64(end-start))
+	}
+	if len(energies) == 0 {
+		return nil
+	}
+
+	sorted := append([]float64(nil), energies...)
+	sort.Float64s(sorted)
+	threshold := sorted[len(sorted)/2] * _impulseRatio
+	if threshold < _impulseFloor {
+		threshold = _impulseFloor
+	}
+
+	var (
+		windows []window
+		before  = int(_windowBefore * float64(sampleRate))
+		after   = int(_windowAfter * float64(sampleRate))
+	)
+	for i, energy := range energies {
+		if energy < threshold {
+			continue
+		}
+
+		previous := 0.0
+		if i >= 2 {
+			previous = energies[i-2]
+		}
+		if energy < previous*_impulseRise {
+			continue
+		}
+
+		w := window{start: i*frame - before, end: i*frame + after}
+		if w.start < 0 {
+			w.start = 0
+		}
+		if w.end > len(samples) {
+			w.end = len(samples)
+		}
+
+		if len(windows) > 0 && w.start <= windows[len(windows)-1].end {
+			windows[len(windows)-1].end = w.end
+			continue
+		}
+		windows = append(windows, w)
+	}
+
+	return windows
+}
@@ -0,0 +1,114 @@
+package privacy
+
+import (
+	"encoding/binary"
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"math"
+)
+
+const (
+	_wavFormatPCM        = 1
+	_wavFormatExtensible = 0xFFFE
+	_bitsPerSample       = 16
+)
+
+var ErrUnsupportedAudio = apperr.New(apperr.UnsupportedMedia, "the audio can't be filtered: expected 16-bit PCM WAV")
+
+// wav is the decoded 16-bit PCM file, data refers to the sample bytes of the raw file
+type wav struct {
+	raw        []byte
+	data       []byte
+	sampleRate int
+	channels   int
+}
+
+// decodeWAV reads the layout of the RIFF file, the raw bytes are copied so the caller's audio is kept
+func decodeWAV(audio []byte) (wav, error) {
+	if len(audio) < 12 || string(audio[0:4]) != "RIFF" || string(audio[8:12]) != "WAVE" {
+		return wav{}, ErrUnsupportedAudio
+	}
+
+	file := wav{raw: append([]byte(nil), audio...)}
+
+	var hasFormat bool
+	for offset := 12; offset+8 <= len(file.raw); {
+		id := string(file.raw[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(file.raw[offset+4 : offset+8]))
+		body := offset + 8
+		if body+size > len(file.raw) {
+			// devices stream the file and may leave the size of the data chunk unset
+			if id != "data" {
+				return wav{}, ErrUnsupportedAudio
+			}
+			size = len(file.raw) - body
+		}
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return wav{}, ErrUnsupportedAudio
+			}
+			format := binary.LittleEndian.Uint16(file.raw[body:])
+			file.channels = int(binary.LittleEndian.Uint16(file.raw[body+2:]))
+			file.sampleRate = int(binary.LittleEndian.Uint32(file.raw[body+4:]))
+			bits := binary.LittleEndian.Uint16(file.raw[body+14:])
+
+			if (format != _wavFormatPCM && format != _wavFormatExtensible) || bits != _bitsPerSample ||
+				file.channels == 0 || file.sampleRate == 0 {
+				return wav{}, ErrUnsupportedAudio
+			}
+			hasFormat = true
+		case "data":
+			if !hasFormat {
+				return wav{}, ErrUnsupportedAudio
+			}
+			frame := file.channels * _bitsPerSample / 8
+			file.data = file.raw[body : body+size-size%frame]
+
+			return file, nil
+		}
+
+		// chunks are aligned to the word
+		offset = body + size + size%2
+	}
+
+	return wav{}, ErrUnsupportedAudio
+}
+
+// samples returns the channel scaled to [-1, 1)
+func (w wav) samples(channel int) []float64 {
+	frame := w.channels * 2
+	samples := make([]float64, len(w.data)/frame)
+	for i := range samples {
+		samples[i] = float64(int16(binary.LittleEndian.Uint16(w.data[i*frame+channel*2:]))) / 32768
+	}
+
+	return samples
+}
+
+// setSamples writes the channel back, the samples out of the range are clipped
+func (w wav) setSamples(channel int, samples []float64) {
+	frame := w.channels * 2
+	for i, sample := range samples {
+		value := math.Round(sample * 32768)
+		value = math.Max(math.MinInt16, math.Min(math.MaxInt16, value))
+		binary.LittleEndian.PutUint16(w.data[i*frame+channel*2:], uint16(int16(value)))
+	}
+}
+
+// mono mixes channels down, impulses are detected on the mix
+func (w wav) mono() []float64 {
+	mix := w.samples(0)
+	for channel := 1; channel < w.channels; channel++ {
+		for i, sample := range w.samples(channel) {
+			mix[i] += sample
+		}
+	}
+	if w.channels > 1 {
+		for i := range mix {
+			mix[i] /= float64(w.channels)
+		}
+	}
+
+	return mix
+}
@@ -0,0 +1,92 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokOperator
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+var _operators = []string{"&&", "||", "==", "!=", ">=", "<=", ">", "<", "!", "-"}
+
+func tokenize(src string) ([]token, error) {
+	var (
+		tokens []token
+		runes  = []rune(src)
+	)
+
+	for pos := 0; pos < len(runes); {
+		r := runes[pos]
+
+		switch {
+		case unicode.IsSpace(r):
+			pos++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokLParen, value: "(", pos: pos})
+			pos++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokRParen, value: ")", pos: pos})
+			pos++
+		case r == '"' || r == '\'':
+			end := pos + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("%w: unterminated string at %d", ErrSyntax, pos)
+			}
+			tokens = append(tokens, token{kind: tokString, value: string(runes[pos+1 : end]), pos: pos})
+			pos = end + 1
+		case unicode.IsDigit(r) || r == '.':
+			end := pos
+			for end < len(runes) && (unicode.IsDigit(runes[end]) || runes[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokNumber, value: string(runes[pos:end]), pos: pos})
+			pos = end
+		case unicode.IsLetter(r) || r == '_':
+			end := pos
+			for end < len(runes) && (unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end]) || runes[end] == '_') {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokIdent, value: string(runes[pos:end]), pos: pos})
+			pos = end
+		default:
+			op := matchOperator(string(runes[pos:]))
+			if op == "" {
+				return nil, fmt.Errorf("%w: unexpected symbol '%c' at %d", ErrSyntax, r, pos)
+			}
+			tokens = append(tokens, token{kind: tokOperator, value: op, pos: pos})
+			pos += len(op)
+		}
+	}
+
+	return append(tokens, token{kind: tokEOF, pos: len(runes)}), nil
+}
+
+func matchOperator(src string) string {
+	for _, op := range _operators {
+		if strings.HasPrefix(src, op) {
+			return op
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,213 @@
+package rules
+
+import (
+	"fmt"
+)
+
+type node interface {
+	eval(env Env) (interface{}, error)
+}
+
+type literal struct {
+	value interface{}
+	typ   Type
+}
+
+func (l literal) eval(Env) (interface{}, error) {
+	return l.value, nil
+}
+
+type variable struct {
+	name string
+}
+
+func (v variable) eval(env Env) (interface{}, error) {
+	value, ok := env[v.name]
+	if !ok {
+		return nil, fmt.Errorf("variable '%s' is not set", v.name)
+	}
+
+	return value, nil
+}
+
+type not struct {
+	operand node
+}
+
+func (n not) eval(env Env) (interface{}, error) {
+	v, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	return !v.(bool), nil
+}
+
+type negate struct {
+	operand node
+}
+
+func (n negate) eval(env Env) (interface{}, error) {
+	v, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	return -v.(float64), nil
+}
+
+type binary struct {
+	op          string
+	left, right node
+}
+
+func (b binary) eval(env Env) (interface{}, error) {
+	left, err := b.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	// short-circuit evaluation
+	switch b.op {
+	case "&&":
+		if !left.(bool) {
+			return false, nil
+		}
+	case "||":
+		if left.(bool) {
+			return true, nil
+		}
+	}
+
+	right, err := b.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch b.op {
+	case "&&", "||":
+		return right.(bool), nil
+	case "==":
+		return left == right, nil
+	case "!=":
+		return left != right, nil
+	}
+
+	switch l := left.(type) {
+	case float64:
+		return compare(b.op, l < right.(float64), l == right.(float64)), nil
+	case string:
+		return compare(b.op, l < right.(string), l == right.(string)), nil
+	default:
+		return nil, fmt.Errorf("operator '%s' is not defined for %T", b.op, left)
+	}
+}
+
+func compare(op string, less, equal bool) bool {
+	switch op {
+	case ">":
+		return !less && !equal
+	case ">=":
+		return !less
+	case "<":
+		return less
+	default:
+		return less || equal
+	}
+}
+
+// check infers the type of the node and returns the node with bare words replaced by string literals
+func check(n node, schema Schema) (node, Type, error) {
+	switch n := n.(type) {
+	case literal:
+		return n, n.typ, nil
+	case variable:
+		typ, ok := schema[n.name]
+		if !ok {
+			return nil, 0, fmt.Errorf("%w: unknown variable '%s'", ErrType, n.name)
+		}
+		return n, typ, nil
+	case not:
+		operand, typ, err := check(n.operand, schema)
+		if err != nil {
+			return nil, 0, err
+		}
+		if typ != Bool {
+			return nil, 0, fmt.Errorf("%w: operator '!' expects bool, got %s", ErrType, typ)
+		}
+		return not{operand: operand}, Bool, nil
+	case negate:
+		operand, typ, err := check(n.operand, schema)
+		if err != nil {
+			return nil, 0, err
+		}
+		if typ != Number {
+			return nil, 0, fmt.Errorf("%w: operator '-' expects number, got %s", ErrType, typ)
+		}
+		if l, ok := operand.(literal); ok {
+			return literal{value: -l.value.(float64), typ: Number}, Number, nil
+		}
+		return negate{operand: operand}, Number, nil
+	case binary:
+		return checkBinary(n, schema)
+	default:
+		return nil, 0, fmt.Errorf("%w: unknown node %T", ErrType, n)
+	}
+}
+
+func checkBinary(b binary, schema Schema) (node, Type, error) {
+	b.left, b.right = bareWord(b.left, b.right, schema), bareWord(b.right, b.left, schema)
+
+	left, leftType, err := check(b.left, schema)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	right, rightType, err := check(b.right, schema)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	b.left, b.right = left, right
+
+	switch b.op {
+	case "&&", "||":
+		if leftType != Bool || rightType != Bool {
+			return nil, 0, fmt.Errorf(
+				"%w: operator '%s' expects bool operands, got %s and %s", ErrType, b.op, leftType, rightType,
+			)
+		}
+	case "==", "!=":
+		if leftType != rightType {
+			return nil, 0, fmt.Errorf("%w: can't compare %s and %s", ErrType, leftType, rightType)
+		}
+	default:
+		if leftType != rightType || leftType == Bool {
+			return nil, 0, fmt.Errorf(
+				"%w: operator '%s' is not defined for %s and %s", ErrType, b.op, leftType, rightType,
+			)
+		}
+	}
+
+	return b, Bool, nil
+}
+
+// bareWord turns an unknown identifier into a string literal when it is compared with a string variable
+func bareWord(n, other node, schema Schema) node {
+	v, ok := n.(variable)
+	if !ok {
+		return n
+	}
+
+	if _, known := schema[v.name]; known {
+		return n
+	}
+
+	if o, ok := other.(variable); ok {
+		if typ, known := schema[o.name]; known && typ == String {
+			return literal{value: v.name, typ: String}
+		}
+	}
+
+	return n
+}
@@ -0,0 +1,239 @@
+// Package rules implements a tiny expression language used by alert rules, e.g.
+//
+//	label == "gunshot" && confidence > 0.85 && (hour >= 22 || hour < 6)
+//
+// Numbers may be negated by the unary minus: longitude > -74.5.
+//
+// Expressions are parsed and type-checked against a Schema once and then evaluated for every Env.
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+var (
+	ErrSyntax = errors.New("syntax error")
+	ErrType   = errors.New("type error")
+)
+
+type Type int
+
+const (
+	Bool Type = iota
+	Number
+	String
+)
+
+func (t Type) String() string {
+	switch t {
+	case Bool:
+		return "bool"
+	case Number:
+		return "number"
+	default:
+		return "string"
+	}
+}
+
+// Schema describes variables available in expressions
+type Schema map[string]Type
+
+// Env holds values of variables: float64 for Number, string for String and bool for Bool
+type Env map[string]interface{}
+
+type Expression struct {
+	src  string
+	root node
+}
+
+// Compile parses the source and checks that it is a boolean expression over the schema variables.
+// A bare word compared with a string variable is treated as a string literal: label == gunshot
+func Compile(src string, schema Schema) (*Expression, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, fmt.Errorf("%w: unexpected '%s' at %d", ErrSyntax, tok.value, tok.pos)
+	}
+
+	root, typ, err := check(root, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	if typ != Bool {
+		return nil, fmt.Errorf("%w: expression must be bool, got %s", ErrType, typ)
+	}
+
+	return &Expression{src: src, root: root}, nil
+}
+
+func (e *Expression) String() string {
+	return e.src
+}
+
+// Eval evaluates the expression, the env must contain every variable used in it
+func (e *Expression) Eval(env Env) (bool, error) {
+	v, err := e.root.eval(env)
+	if err != nil {
+		return false, err
+	}
+
+	return v.(bool), nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+
+	return tok
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokOperator && p.peek().value == "||" {
+		p.next()
+
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = binary{op: "||", left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokOperator && p.peek().value == "&&" {
+		p.next()
+
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = binary{op: "&&", left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.peek().kind == tokOperator && p.peek().value == "!" {
+		p.next()
+
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		return not{operand: operand}, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	tok := p.peek()
+	if tok.kind != tokOperator {
+		return left, nil
+	}
+
+	switch tok.value {
+	case "==", "!=", ">", ">=", "<", "<=":
+		p.next()
+
+		right, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+
+		return binary{op: tok.value, left: left, right: right}, nil
+	}
+
+	return left, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokNumber:
+		value, err := strconv.ParseFloat(tok.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid number '%s' at %d", ErrSyntax, tok.value, tok.pos)
+		}
+		return literal{value: value, typ: Number}, nil
+	case tokString:
+		return literal{value: tok.value, typ: String}, nil
+	case tokIdent:
+		switch tok.value {
+		case "true":
+			return literal{value: true, typ: Bool}, nil
+		case "false":
+			return literal{value: false, typ: Bool}, nil
+		}
+		return variable{name: tok.value}, nil
+	case tokLParen:
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, fmt.Errorf("%w: expected ')' at %d", ErrSyntax, closing.pos)
+		}
+
+		return expr, nil
+	case tokOperator:
+		if tok.value != "-" {
+			return nil, fmt.Errorf("%w: unexpected '%s' at %d", ErrSyntax, tok.value, tok.pos)
+		}
+
+		operand, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+
+		return negate{operand: operand}, nil
+	case tokEOF:
+		return nil, fmt.Errorf("%w: unexpected end of expression", ErrSyntax)
+	default:
+		return nil, fmt.Errorf("%w: unexpected '%s' at %d", ErrSyntax, tok.value, tok.pos)
+	}
+}
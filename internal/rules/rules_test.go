@@ -0,0 +1,160 @@
+package rules_test
+
+import (
+	"github.com/Imm0bilize/gunshot-api-service/internal/rules"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+var testSchema = rules.Schema{
+	"label":      rules.String,
+	"confidence": rules.Number,
+	"hour":       rules.Number,
+	"count":      rules.Number,
+	"night":      rules.Bool,
+	"longitude":  rules.Number,
+}
+
+func TestCompile(t *testing.T) {
+	testTable := []struct {
+		name   string
+		src    string
+		expErr error
+	}{
+		{
+			name: "label and confidence",
+			src:  `label == "gunshot" && confidence > 0.85`,
+		},
+		{
+			name: "bare word string",
+			src:  "label == gunshot",
+		},
+		{
+			name: "grouping and negation",
+			src:  "!(hour >= 6 && hour < 22) || night",
+		},
+		{
+			name: "negative number",
+			src:  "longitude > -74.5",
+		},
+		{
+			name: "negated variable",
+			src:  "-longitude <= 74.5",
+		},
+		{
+			name:   "negated string",
+			src:    `-label == "gunshot"`,
+			expErr: rules.ErrType,
+		},
+		{
+			name:   "minus without operand",
+			src:    "longitude > -",
+			expErr: rules.ErrSyntax,
+		},
+		{
+			name:   "subtraction",
+			src:    "count - 1 > 0",
+			expErr: rules.ErrSyntax,
+		},
+		{
+			name:   "unknown variable",
+			src:    "loudness > 3",
+			expErr: rules.ErrType,
+		},
+		{
+			name:   "compare number with string",
+			src:    `confidence == "high"`,
+			expErr: rules.ErrType,
+		},
+		{
+			name:   "not a bool expression",
+			src:    "confidence",
+			expErr: rules.ErrType,
+		},
+		{
+			name:   "unclosed parenthesis",
+			src:    "(count >= 3",
+			expErr: rules.ErrSyntax,
+		},
+		{
+			name:   "unterminated string",
+			src:    `label == "gunshot`,
+			expErr: rules.ErrSyntax,
+		},
+		{
+			name:   "dangling operator",
+			src:    "count >=",
+			expErr: rules.ErrSyntax,
+		},
+	}
+
+	for _, tCase := range testTable {
+		t.Run(tCase.name, func(t *testing.T) {
+			_, err := rules.Compile(tCase.src, testSchema)
+			require.ErrorIs(t, err, tCase.expErr)
+		})
+	}
+}
+
+func TestEval(t *testing.T) {
+	testTable := []struct {
+		name string
+		src  string
+		env  rules.Env
+		exp  bool
+	}{
+		{
+			name: "confident gunshot",
+			src:  "label == gunshot && confidence > 0.85",
+			env:  rules.Env{"label": "gunshot", "confidence": 0.9},
+			exp:  true,
+		},
+		{
+			name: "not confident enough",
+			src:  "label == gunshot && confidence > 0.85",
+			env:  rules.Env{"label": "gunshot", "confidence": 0.85},
+			exp:  false,
+		},
+		{
+			name: "burst of detections",
+			src:  "count >= 3",
+			env:  rules.Env{"count": float64(3)},
+			exp:  true,
+		},
+		{
+			name: "night hours wrap midnight",
+			src:  "hour >= 22 || hour < 6",
+			env:  rules.Env{"hour": float64(2)},
+			exp:  true,
+		},
+		{
+			name: "west of the meridian",
+			src:  "longitude < -74 && longitude > -75",
+			env:  rules.Env{"longitude": -74.5},
+			exp:  true,
+		},
+		{
+			name: "double negation",
+			src:  "--longitude == longitude && -(-3) == 3",
+			env:  rules.Env{"longitude": 37.6},
+			exp:  true,
+		},
+		{
+			name: "day hours",
+			src:  "hour >= 22 || hour < 6",
+			env:  rules.Env{"hour": float64(13)},
+			exp:  false,
+		},
+	}
+
+	for _, tCase := range testTable {
+		t.Run(tCase.name, func(t *testing.T) {
+			expr, err := rules.Compile(tCase.src, testSchema)
+			require.NoError(t, err)
+
+			got, err := expr.Eval(tCase.env)
+			require.NoError(t, err)
+			require.Equal(t, tCase.exp, got)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+// Package signing signs exported documents (audit logs, evidence manifests) so they can be verified
+// by a third party with the public key of the service.
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+const Algorithm = "ed25519"
+
+var (
+	ErrInvalidKey       = errors.New("invalid signing key")
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+// Signature is a detached signature of the SHA-256 digest of a document
+type Signature struct {
+	Algorithm string `json:"algorithm"`
+	PublicKey string `json:"publicKey"`
+	SHA256    string `json:"sha256"`
+	Signature string `json:"signature"`
+}
+
+type Signer struct {
+	key ed25519.PrivateKey
+}
+
+// NewSigner creates the signer from the hex encoded 32 bytes seed of the Ed25519 key
+func NewSigner(seed string) (*Signer, error) {
+	raw, err := hex.DecodeString(seed)
+	if err != nil || len(raw) != ed25519.SeedSize {
+		return nil, fmt.Errorf("%w: expected %d hex encoded bytes", ErrInvalidKey, ed25519.SeedSize)
+	}
+
+	return &Signer{key: ed25519.NewKeyFromSeed(raw)}, nil
+}
+
+// PublicKey returns the base64 encoded public key
+func (s *Signer) PublicKey() string {
+	return base64.StdEncoding.EncodeToString(s.key.Public().(ed25519.PublicKey))
+}
+
+// Sign signs the SHA-256 digest of the document
+func (s *Signer) Sign(digest []byte) Signature {
+	return Signature{
+		Algorithm: Algorithm,
+		PublicKey: s.PublicKey(),
+		SHA256:    hex.EncodeToString(digest),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(s.key, digest)),
+	}
+}
+
+// SignDocument hashes and signs the document
+func (s *Signer) SignDocument(document []byte) Signature {
+	digest := sha256.Sum256(document)
+	return s.Sign(digest[:])
+}
+
+// Verify checks the signature against the digest of the document. The public key is taken from the
+// signature, callers which trust only a known key must compare it with signature.PublicKey
+func Verify(signature Signature, digest []byte) error {
+	if signature.Algorithm != Algorithm {
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidSignature, signature.Algorithm)
+	}
+
+	if signature.SHA256 != hex.EncodeToString(digest) {
+		return fmt.Errorf("%w: digest mismatch", ErrInvalidSignature)
+	}
+
+	publicKey, err := base64.StdEncoding.DecodeString(signature.PublicKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("%w: malformed public key", ErrInvalidSignature)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signature.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: malformed signature", ErrInvalidSignature)
+	}
+
+	if !ed25519.Verify(publicKey, digest, sig) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
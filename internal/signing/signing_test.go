@@ -0,0 +1,35 @@
+package signing_test
+
+import (
+	"crypto/sha256"
+	"github.com/Imm0bilize/gunshot-api-service/internal/signing"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestSignVerify(t *testing.T) {
+	signer, err := signing.NewSigner(strings.Repeat("01", 32))
+	require.NoError(t, err)
+
+	document := []byte(`{"ID":"1"}`)
+	signature := signer.SignDocument(document)
+
+	digest := sha256.Sum256(document)
+	require.NoError(t, signing.Verify(signature, digest[:]))
+
+	tampered := sha256.Sum256([]byte(`{"ID":"2"}`))
+	require.ErrorIs(t, signing.Verify(signature, tampered[:]), signing.ErrInvalidSignature)
+
+	other, err := signing.NewSigner(strings.Repeat("02", 32))
+	require.NoError(t, err)
+
+	forged := signature
+	forged.PublicKey = other.PublicKey()
+	require.ErrorIs(t, signing.Verify(forged, digest[:]), signing.ErrInvalidSignature)
+}
+
+func TestNewSignerInvalidKey(t *testing.T) {
+	_, err := signing.NewSigner("abc")
+	require.ErrorIs(t, err, signing.ErrInvalidKey)
+}
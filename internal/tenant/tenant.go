@@ -0,0 +1,61 @@
+// Package tenant carries the organization the request is made on behalf of.
+//
+// Repositories scope their queries by the tenant of the context. A context without a tenant is rejected,
+// background jobs which work across organizations must ask for it explicitly with System.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrNoTenant = errors.New("the tenant is not set")
+)
+
+type key struct{}
+
+type value struct {
+	organization entities.Organization
+	system       bool
+	// idOnly is set when the organization is not loaded, so its settings are unknown
+	idOnly bool
+}
+
+// WithOrganization scopes the context to the organization
+func WithOrganization(ctx context.Context, organization entities.Organization) context.Context {
+	return context.WithValue(ctx, key{}, value{organization: organization})
+}
+
+// WithID scopes the context to the organization when only its id is known
+func WithID(ctx context.Context, id primitive.ObjectID) context.Context {
+	return context.WithValue(ctx, key{}, value{organization: entities.Organization{ID: id}, idOnly: true})
+}
+
+// System marks the context as not scoped to any organization
+func System(ctx context.Context) context.Context {
+	return context.WithValue(ctx, key{}, value{system: true})
+}
+
+// ID returns the tenant of the context, the nil id means the system context
+func ID(ctx context.Context) (primitive.ObjectID, error) {
+	v, ok := ctx.Value(key{}).(value)
+	if !ok || (!v.system && v.organization.ID.IsZero()) {
+		return primitive.NilObjectID, ErrNoTenant
+	}
+
+	return v.organization.ID, nil
+}
+
+// Organization returns the organization the context is scoped to. Settings (quotas, retention) are
+// known only for the authenticated organization, the context made by WithID has none
+func Organization(ctx context.Context) (entities.Organization, bool) {
+	v, ok := ctx.Value(key{}).(value)
+	if !ok || v.system || v.idOnly {
+		return entities.Organization{}, false
+	}
+
+	return v.organization, true
+}
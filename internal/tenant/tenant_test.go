@@ -0,0 +1,52 @@
+package tenant_test
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/tenant"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+)
+
+func TestID(t *testing.T) {
+	id := primitive.NewObjectID()
+
+	testTable := []struct {
+		name   string
+		ctx    context.Context
+		expID  primitive.ObjectID
+		expErr error
+	}{
+		{name: "not set", ctx: context.Background(), expErr: tenant.ErrNoTenant},
+		{name: "organization", ctx: tenant.WithID(context.Background(), id), expID: id},
+		{name: "system", ctx: tenant.System(context.Background()), expID: primitive.NilObjectID},
+		{
+			name:   "organization without id is not the system",
+			ctx:    tenant.WithOrganization(context.Background(), entities.Organization{}),
+			expErr: tenant.ErrNoTenant,
+		},
+	}
+
+	for _, tCase := range testTable {
+		t.Run(tCase.name, func(t *testing.T) {
+			got, err := tenant.ID(tCase.ctx)
+			require.ErrorIs(t, err, tCase.expErr)
+			require.Equal(t, tCase.expID, got)
+		})
+	}
+}
+
+func TestOrganization(t *testing.T) {
+	organization := entities.Organization{ID: primitive.NewObjectID(), Quota: entities.Quota{MaxSensors: 1}}
+
+	got, ok := tenant.Organization(tenant.WithOrganization(context.Background(), organization))
+	require.True(t, ok)
+	require.Equal(t, organization, got)
+
+	_, ok = tenant.Organization(tenant.System(context.Background()))
+	require.False(t, ok)
+
+	_, ok = tenant.Organization(tenant.WithID(context.Background(), organization.ID))
+	require.False(t, ok, "settings of the organization known by id are not loaded")
+}
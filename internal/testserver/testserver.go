@@ -0,0 +1,517 @@
+// Package testserver runs the real router of the API over fakes of use cases, so the SDK and the CLI are
+// tested against the same HTTP behavior. Methods the callers don't use are left to embedded interfaces.
+package testserver
+
+import (
+	"context"
+	"fmt"
+	"github.com/Imm0bilize/gunshot-api-service/internal/clientio"
+	"github.com/Imm0bilize/gunshot-api-service/internal/controller/http"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository"
+	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/webhooks"
+	"github.com/Imm0bilize/gunshot-api-service/internal/uCase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"io"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	// APIKey is the only key of organizations the server accepts
+	APIKey = "gsk_test"
+	// AdminToken is the token of the admin API
+	AdminToken = "admin_test"
+)
+
+// Server is the API with fakes the test can inspect and set up
+type Server struct {
+	*httptest.Server
+
+	TenantID   primitive.ObjectID
+	Clients    *Clients
+	Audio      *Audio
+	Detections *Detections
+	Incidents  *Incidents
+	// Webhooks delivers events to webhooks created through the API over HTTP, Notify sends one
+	Webhooks *uCase.Webhook
+	// DeadLetters is the dead-letter topic replays are made from
+	DeadLetters *DeadLetters
+}
+
+// New starts the server, it's closed with the end of the test
+func New(t *testing.T) *Server {
+	gin.SetMode(gin.TestMode)
+	gin.DefaultWriter = io.Discard
+
+	tenantID := primitive.NewObjectID()
+
+	s := &Server{
+		TenantID:    tenantID,
+		Clients:     &Clients{tenantID: tenantID, clients: make(map[string]entities.Client)},
+		Audio:       &Audio{Messages: make(chan entities.Message, 1)},
+		Detections:  &Detections{},
+		DeadLetters: &DeadLetters{},
+		Incidents: &Incidents{
+			Incident: entities.Incident{ID: primitive.NewObjectID(), TenantID: tenantID, Status: entities.IncidentNew},
+		},
+	}
+
+	idempotency := &idempotencyRepo{requests: make(map[string]entities.IdempotentRequest)}
+	s.Webhooks = uCase.NewWebhookUCase(zap.NewNop(), &webhookRepo{}, webhooks.NewHTTPSender(time.Second))
+
+	s.Server = httptest.NewServer(http.NewHTTPServer(zap.NewNop(), &uCase.UseCase{
+		Client:       s.Clients,
+		Audio:        s.Audio,
+		Detection:    s.Detections,
+		Incident:     s.Incidents,
+		Organization: organizations{tenantID: tenantID},
+		Audit:        audit{},
+		Idempotency:  uCase.NewIdempotencyUCase(zap.NewNop(), idempotency),
+		Webhook:      s.Webhooks,
+		DeadLetter:   uCase.NewDeadLetterUCase(zap.NewNop(), s.DeadLetters),
+	}, AdminToken))
+	t.Cleanup(s.Server.Close)
+
+	return s
+}
+
+type organizations struct {
+	uCase.OrganizationUseCase
+
+	tenantID primitive.ObjectID
+}
+
+func (o organizations) Authenticate(_ context.Context, key string) (entities.Organization, error) {
+	if key != APIKey {
+		return entities.Organization{}, uCase.ErrUnauthenticated
+	}
+
+	return entities.Organization{ID: o.tenantID}, nil
+}
+
+type audit struct {
+	uCase.AuditUseCase
+}
+
+func (audit) Record(context.Context, uuid.UUID, *entities.AuditEntry) error {
+	return nil
+}
+
+func (audit) Complete(context.Context, uuid.UUID, *entities.AuditEntry) error {
+	return nil
+}
+
+// idempotencyRepo keeps idempotent requests in memory, they don't expire
+type idempotencyRepo struct {
+	mu       sync.Mutex
+	requests map[string]entities.IdempotentRequest
+}
+
+func (f *idempotencyRepo) Begin(
+	_ context.Context, request *entities.IdempotentRequest,
+) (entities.IdempotentRequest, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if existing, ok := f.requests[request.Key]; ok {
+		return existing, false, nil
+	}
+
+	request.ID = primitive.NewObjectID()
+	f.requests[request.Key] = *request
+
+	return *request, true, nil
+}
+
+func (f *idempotencyRepo) Complete(_ context.Context, request *entities.IdempotentRequest) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests[request.Key] = *request
+	return nil
+}
+
+func (f *idempotencyRepo) Extend(context.Context, *entities.IdempotentRequest) error {
+	return nil
+}
+
+func (f *idempotencyRepo) Release(_ context.Context, request *entities.IdempotentRequest) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.requests, request.Key)
+	return nil
+}
+
+// webhookRepo keeps webhooks of the only tenant in memory
+type webhookRepo struct {
+	mu       sync.Mutex
+	webhooks []entities.Webhook
+}
+
+func (f *webhookRepo) Create(_ context.Context, webhook *entities.Webhook) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	webhook.ID = primitive.NewObjectID()
+	f.webhooks = append(f.webhooks, *webhook)
+
+	return webhook.ID.Hex(), nil
+}
+
+func (f *webhookRepo) Get(_ context.Context, id string) (entities.Webhook, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, webhook := range f.webhooks {
+		if webhook.ID.Hex() == id {
+			return webhook, nil
+		}
+	}
+
+	return entities.Webhook{}, repository.ErrWebhookNotFound
+}
+
+func (f *webhookRepo) List(_ context.Context, event entities.WebhookEvent) ([]entities.Webhook, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	webhooks := make([]entities.Webhook, 0)
+	for _, webhook := range f.webhooks {
+		for _, subscribed := range webhook.Events {
+			if event == "" || subscribed == event {
+				webhooks = append(webhooks, webhook)
+				break
+			}
+		}
+	}
+
+	return webhooks, nil
+}
+
+func (f *webhookRepo) Delete(_ context.Context, id string) (entities.Webhook, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, webhook := range f.webhooks {
+		if webhook.ID.Hex() == id {
+			f.webhooks = append(f.webhooks[:i], f.webhooks[i+1:]...)
+			return webhook, nil
+		}
+	}
+
+	return entities.Webhook{}, repository.ErrWebhookNotFound
+}
+
+// DeadLetters records replays, every letter of the range is replayed
+type DeadLetters struct {
+	mu      sync.Mutex
+	replays []entities.DeadLetterReplay
+}
+
+func (f *DeadLetters) Replay(
+	_ context.Context, replay entities.DeadLetterReplay,
+) (entities.DeadLetterReplayResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.replays = append(f.replays, replay)
+
+	next := replay.From
+	if replay.To != nil {
+		next = *replay.To + 1
+	}
+
+	return entities.DeadLetterReplayResult{Replayed: int(next - replay.From), Next: next}, nil
+}
+
+// Replays returns replays made so far
+func (f *DeadLetters) Replays() []entities.DeadLetterReplay {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]entities.DeadLetterReplay(nil), f.replays...)
+}
+
+// Clients keeps clients in memory, deleted ones are kept until they are restored
+type Clients struct {
+	uCase.ClientUseCase
+
+	tenantID primitive.ObjectID
+
+	mu      sync.Mutex
+	clients map[string]entities.Client
+}
+
+func (f *Clients) Create(_ context.Context, _ uuid.UUID, client *entities.Client) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	client.ID = primitive.NewObjectID()
+	client.TenantID = f.tenantID
+	client.Version = 1
+	f.clients[client.ID.Hex()] = *client
+
+	return client.ID.Hex(), nil
+}
+
+func (f *Clients) Get(_ context.Context, _ uuid.UUID, id string) (entities.Client, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	client, ok := f.clients[id]
+	if !ok || client.DeletedAt != nil {
+		return entities.Client{}, errors.Wrap(repository.ErrClientNotFound, "can't get client")
+	}
+
+	return client, nil
+}
+
+// List pages live clients in the order of their IDs
+func (f *Clients) List(_ context.Context, _ uuid.UUID, filter entities.ClientFilter) ([]entities.Client, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	clients := make([]entities.Client, 0)
+	for _, client := range f.clients {
+		if client.DeletedAt == nil && client.ID.Hex() > filter.After.Hex() {
+			clients = append(clients, client)
+		}
+	}
+
+	sort.Slice(clients, func(i, j int) bool { return clients[i].ID.Hex() < clients[j].ID.Hex() })
+	if int64(len(clients)) > filter.Limit {
+		clients = clients[:filter.Limit]
+	}
+
+	return clients, nil
+}
+
+func (f *Clients) Update(
+	_ context.Context, _ uuid.UUID, id string, version int64, client *entities.Client,
+) (entities.Client, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	current := f.clients[id]
+	if version != entities.AnyVersion && current.Version != version {
+		return entities.Client{}, repository.ErrClientVersionMismatch
+	}
+
+	current.LocationName, current.FullName = client.LocationName, client.FullName
+	current.Latitude, current.Longitude = client.Latitude, client.Longitude
+	current.Version++
+	f.clients[id] = current
+
+	return current, nil
+}
+
+func (f *Clients) Patch(
+	_ context.Context, _ uuid.UUID, id string, version int64, patch entities.ClientPatch,
+) (entities.Client, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	current := f.clients[id]
+	if version != entities.AnyVersion && current.Version != version {
+		return entities.Client{}, repository.ErrClientVersionMismatch
+	}
+
+	current = patch.Apply(current)
+	current.Version++
+	f.clients[id] = current
+
+	return current, nil
+}
+
+func (f *Clients) Delete(_ context.Context, _ uuid.UUID, id string, version int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	current := f.clients[id]
+	if version != entities.AnyVersion && current.Version != version {
+		return repository.ErrClientVersionMismatch
+	}
+
+	now := time.Now().UTC()
+	current.DeletedAt = &now
+	current.Version++
+	f.clients[id] = current
+
+	return nil
+}
+
+func (f *Clients) Restore(_ context.Context, _ uuid.UUID, id string) (entities.Client, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	current, ok := f.clients[id]
+	if !ok {
+		return entities.Client{}, repository.ErrClientNotFound
+	}
+
+	current.DeletedAt = nil
+	current.Version++
+	f.clients[id] = current
+
+	return current, nil
+}
+
+// Export writes live clients as CSV whatever the format is
+func (f *Clients) Export(_ context.Context, _ uuid.UUID, _ clientio.Format, w io.Writer) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fmt.Fprintln(w, "id,fullName,locationName,latitude,longitude")
+	for id, client := range f.clients {
+		if client.DeletedAt != nil {
+			continue
+		}
+
+		fmt.Fprintf(w, "%s,%s,%s,%v,%v\n", id, client.FullName, client.LocationName, client.Latitude, client.Longitude)
+	}
+
+	return nil
+}
+
+// Import reads CSV and rejects rows without the full name, nothing is created
+func (f *Clients) Import(
+	_ context.Context, _ uuid.UUID, _ clientio.Format, r io.Reader, dryRun bool,
+) (entities.ClientImport, error) {
+	rows, err := clientio.ReadCSV(r)
+	if err != nil {
+		return entities.ClientImport{}, err
+	}
+
+	result := entities.ClientImport{
+		DryRun: dryRun, Rows: len(rows), Created: make([]entities.ImportedClient, 0),
+		Errors: make([]entities.ImportError, 0),
+	}
+
+	for _, row := range rows {
+		if row.Client.FullName == "" {
+			result.Errors = append(result.Errors, entities.ImportError{Row: row.Number, Error: "fullName is required"})
+			continue
+		}
+
+		result.Created = append(result.Created, entities.ImportedClient{Row: row.Number, ClientID: "new"})
+	}
+
+	return result, nil
+}
+
+// Audio passes uploaded messages to the test
+type Audio struct {
+	uCase.AudioUseCase
+
+	Messages chan entities.Message
+}
+
+func (f *Audio) Upload(_ context.Context, _ uuid.UUID, _ string, msg entities.Message) error {
+	f.Messages <- msg
+	return nil
+}
+
+// Detections fails the first Failures calls: with Err or, if it's nil, by blocking until the caller has gone.
+// Then it lists one detection of the client of the filter
+type Detections struct {
+	uCase.DetectionUseCase
+
+	Failures int
+	Err      error
+
+	mu         sync.Mutex
+	requestIDs []uuid.UUID
+	filters    []entities.DetectionFilter
+}
+
+func (f *Detections) List(
+	ctx context.Context, reqID uuid.UUID, filter entities.DetectionFilter,
+) ([]entities.Detection, error) {
+	f.mu.Lock()
+	f.requestIDs = append(f.requestIDs, reqID)
+	f.filters = append(f.filters, filter)
+	fail := len(f.requestIDs) <= f.Failures
+	f.mu.Unlock()
+
+	if fail && f.Err == nil {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	if fail {
+		return nil, f.Err
+	}
+
+	return []entities.Detection{{ID: primitive.NewObjectID(), ClientID: filter.ClientID, Label: "gunshot"}}, nil
+}
+
+// Calls returns request IDs of the calls made so far
+func (f *Detections) Calls() []uuid.UUID {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]uuid.UUID(nil), f.requestIDs...)
+}
+
+// Filters returns filters of the calls made so far
+func (f *Detections) Filters() []entities.DetectionFilter {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]entities.DetectionFilter(nil), f.filters...)
+}
+
+// Incidents serves the one incident, List expects the limit of 10
+type Incidents struct {
+	uCase.IncidentUseCase
+
+	mu sync.Mutex
+	// Incident is the incident of the tenant, its ID doesn't change
+	Incident entities.Incident
+}
+
+func (f *Incidents) Get(_ context.Context, _ uuid.UUID, id string) (entities.Incident, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if id != f.Incident.ID.Hex() {
+		return entities.Incident{}, repository.ErrIncidentNotFound
+	}
+
+	return f.Incident, nil
+}
+
+func (f *Incidents) List(
+	_ context.Context, _ uuid.UUID, filter entities.IncidentFilter,
+) ([]entities.Incident, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if filter.Limit != 10 {
+		return nil, errors.New("unexpected limit")
+	}
+
+	return []entities.Incident{f.Incident}, nil
+}
+
+func (f *Incidents) Transition(
+	_ context.Context, _ uuid.UUID, _ string, transition entities.IncidentTransition,
+) (entities.Incident, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	transition.From = f.Incident.Status
+	f.Incident.Status = transition.To
+	f.Incident.Transitions = append(f.Incident.Transitions, transition)
+
+	return f.Incident, nil
+}
@@ -0,0 +1,241 @@
+package uCase
+
+import (
+	"context"
+	"fmt"
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/Imm0bilize/gunshot-api-service/internal/audit"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/rules"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"time"
+)
+
+type AlertRuleRepo interface {
+	Create(ctx context.Context, rule *entities.AlertRule) (string, error)
+	Get(ctx context.Context, id string) (entities.AlertRule, error)
+	Update(ctx context.Context, id string, rule *entities.AlertRule) (entities.AlertRule, entities.AlertRule, error)
+	Delete(ctx context.Context, id string) (entities.AlertRule, error)
+	List(ctx context.Context) ([]entities.AlertRule, error)
+	FindForClient(
+		ctx context.Context, clientID primitive.ObjectID, zoneIDs []primitive.ObjectID,
+	) ([]entities.AlertRule, error)
+}
+
+var (
+	ErrInvalidRule = apperr.New(apperr.InvalidArgument, "the alert rule is invalid")
+)
+
+// _ruleSchema lists variables available in expressions of alert rules
+var _ruleSchema = rules.Schema{
+	"label":        rules.String,
+	"confidence":   rules.Number,
+	"modelVersion": rules.String,
+	"hour":         rules.Number,
+	"minute":       rules.Number,
+	"weekday":      rules.Number,
+	"count":        rules.Number,
+}
+
+type compiledRule struct {
+	entities.AlertRule
+	expr     *rules.Expression
+	location *time.Location
+}
+
+func compileRule(rule entities.AlertRule) (compiledRule, error) {
+	expr, err := rules.Compile(rule.Expression, _ruleSchema)
+	if err != nil {
+		return compiledRule{}, fmt.Errorf("%w: %s", ErrInvalidRule, err)
+	}
+
+	location, err := time.LoadLocation(rule.Timezone)
+	if err != nil {
+		return compiledRule{}, fmt.Errorf("%w: unknown timezone '%s'", ErrInvalidRule, rule.Timezone)
+	}
+
+	if rule.Window < 0 {
+		return compiledRule{}, fmt.Errorf("%w: window must not be negative", ErrInvalidRule)
+	}
+
+	return compiledRule{AlertRule: rule, expr: expr, location: location}, nil
+}
+
+func (r compiledRule) window() time.Duration {
+	return time.Duration(r.Window) * time.Second
+}
+
+// match evaluates the rule, count is the number of detections of the client in the rule window
+func (r compiledRule) match(detection entities.Detection, count int64) (bool, error) {
+	local := detection.Timestamp.In(r.location)
+
+	return r.expr.Eval(rules.Env{
+		"label":        detection.Label,
+		"confidence":   detection.Confidence,
+		"modelVersion": detection.ModelVersion,
+		"hour":         float64(local.Hour()),
+		"minute":       float64(local.Minute()),
+		"weekday":      float64(local.Weekday()),
+		"count":        float64(count),
+	})
+}
+
+type DryRunResult struct {
+	Evaluated int
+	Alerts    []entities.Alert
+}
+
+type AlertRule struct {
+	tracer        trace.Tracer
+	ruleRepo      AlertRuleRepo
+	detectionRepo DetectionRepo
+	logger        *zap.Logger
+}
+
+func NewAlertRuleUCase(logger *zap.Logger, ruleRepo AlertRuleRepo, detectionRepo DetectionRepo) *AlertRule {
+	return &AlertRule{
+		tracer:        otel.Tracer("uCase.AlertRule"),
+		ruleRepo:      ruleRepo,
+		detectionRepo: detectionRepo,
+		logger:        logger,
+	}
+}
+
+func (a AlertRule) Create(ctx context.Context, reqID uuid.UUID, rule *entities.AlertRule) (string, error) {
+	ctx, span := a.tracer.Start(ctx, "uCase.AlertRule.Create")
+	defer span.End()
+
+	if _, err := compileRule(*rule); err != nil {
+		return "", err
+	}
+
+	id, err := a.ruleRepo.Create(ctx, rule)
+	if err != nil {
+		a.logger.Error(
+			"error during create new alert rule",
+			zap.String("reqID", reqID.String()),
+			zap.Error(err),
+		)
+
+		return "", errors.Wrap(err, "can't create new alert rule")
+	}
+
+	audit.SetTargetID(ctx, id)
+	auditChange(ctx, a.logger, reqID, nil, rule)
+
+	return id, nil
+}
+
+func (a AlertRule) Get(ctx context.Context, reqID uuid.UUID, id string) (entities.AlertRule, error) {
+	ctx, span := a.tracer.Start(ctx, "uCase.AlertRule.Get")
+	defer span.End()
+
+	rule, err := a.ruleRepo.Get(ctx, id)
+	if err != nil {
+		return entities.AlertRule{}, errors.Wrap(err, "can't get the alert rule")
+	}
+
+	return rule, nil
+}
+
+func (a AlertRule) Update(ctx context.Context, reqID uuid.UUID, id string, rule *entities.AlertRule) error {
+	ctx, span := a.tracer.Start(ctx, "uCase.AlertRule.Update")
+	defer span.End()
+
+	if _, err := compileRule(*rule); err != nil {
+		return err
+	}
+
+	before, after, err := a.ruleRepo.Update(ctx, id, rule)
+	if err != nil {
+		return errors.Wrap(err, "can't update the alert rule")
+	}
+	auditChange(ctx, a.logger, reqID, &before, &after)
+
+	return nil
+}
+
+func (a AlertRule) Delete(ctx context.Context, reqID uuid.UUID, id string) error {
+	ctx, span := a.tracer.Start(ctx, "uCase.AlertRule.Delete")
+	defer span.End()
+
+	deleted, err := a.ruleRepo.Delete(ctx, id)
+	if err != nil {
+		return errors.Wrap(err, "can't delete the alert rule")
+	}
+	auditChange(ctx, a.logger, reqID, &deleted, nil)
+
+	return nil
+}
+
+func (a AlertRule) List(ctx context.Context, reqID uuid.UUID) ([]entities.AlertRule, error) {
+	ctx, span := a.tracer.Start(ctx, "uCase.AlertRule.List")
+	defer span.End()
+
+	alertRules, err := a.ruleRepo.List(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get the list of alert rules")
+	}
+
+	return alertRules, nil
+}
+
+// DryRun evaluates the rule against detections of the last days and returns alerts it would produce
+func (a AlertRule) DryRun(ctx context.Context, reqID uuid.UUID, rule entities.AlertRule, days int) (DryRunResult, error) {
+	ctx, span := a.tracer.Start(ctx, "uCase.AlertRule.DryRun")
+	defer span.End()
+
+	compiled, err := compileRule(rule)
+	if err != nil {
+		return DryRunResult{}, err
+	}
+
+	detections, err := a.detectionRepo.List(ctx, entities.DetectionFilter{
+		ClientID: rule.ClientID,
+		ZoneID:   rule.ZoneID,
+		From:     time.Now().AddDate(0, 0, -days),
+	})
+	if err != nil {
+		return DryRunResult{}, errors.Wrap(err, "can't get detections")
+	}
+
+	result := DryRunResult{Evaluated: len(detections), Alerts: make([]entities.Alert, 0)}
+
+	// detections are sorted by time, so the window of every client is a queue of its recent detections
+	windows := make(map[primitive.ObjectID][]time.Time)
+	for _, detection := range detections {
+		recent := append(windows[detection.ClientID], detection.Timestamp)
+		for len(recent) > 0 && recent[0].Before(detection.Timestamp.Add(-compiled.window())) {
+			recent = recent[1:]
+		}
+		windows[detection.ClientID] = recent
+
+		matched, err := compiled.match(detection, int64(len(recent)))
+		if err != nil {
+			return DryRunResult{}, errors.Wrap(err, "can't evaluate the alert rule")
+		}
+
+		if matched {
+			result.Alerts = append(result.Alerts, newAlert(rule, detection, detection.Timestamp))
+		}
+	}
+
+	return result, nil
+}
+
+func newAlert(rule entities.AlertRule, detection entities.Detection, createdAt time.Time) entities.Alert {
+	return entities.Alert{
+		RuleID:      rule.ID,
+		RuleName:    rule.Name,
+		DetectionID: detection.ID,
+		ClientID:    detection.ClientID,
+		IncidentID:  detection.IncidentID,
+		ZoneIDs:     detection.ZoneIDs,
+		CreatedAt:   createdAt,
+	}
+}
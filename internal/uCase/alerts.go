@@ -0,0 +1,120 @@
+package uCase
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"time"
+)
+
+type AlertRepo interface {
+	Create(ctx context.Context, alert *entities.Alert) (string, error)
+	List(ctx context.Context, filter entities.AlertFilter) ([]entities.Alert, error)
+}
+
+type AlertPublisher interface {
+	SendAlert(ctx context.Context, reqID uuid.UUID, alert entities.Alert) error
+}
+
+type Alert struct {
+	tracer        trace.Tracer
+	alertRepo     AlertRepo
+	ruleRepo      AlertRuleRepo
+	detectionRepo DetectionRepo
+	publisher     AlertPublisher
+	logger        *zap.Logger
+}
+
+func NewAlertUCase(
+	logger *zap.Logger,
+	alertRepo AlertRepo,
+	ruleRepo AlertRuleRepo,
+	detectionRepo DetectionRepo,
+	publisher AlertPublisher,
+) *Alert {
+	return &Alert{
+		tracer:        otel.Tracer("uCase.Alert"),
+		alertRepo:     alertRepo,
+		ruleRepo:      ruleRepo,
+		detectionRepo: detectionRepo,
+		publisher:     publisher,
+		logger:        logger,
+	}
+}
+
+// Evaluate checks the saved detection against rules of its client and raises an alert for every matched rule
+func (a Alert) Evaluate(ctx context.Context, reqID uuid.UUID, detection *entities.Detection) ([]entities.Alert, error) {
+	ctx, span := a.tracer.Start(ctx, "uCase.Alert.Evaluate")
+	defer span.End()
+
+	alertRules, err := a.ruleRepo.FindForClient(ctx, detection.ClientID, detection.ZoneIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get alert rules of the client")
+	}
+
+	alerts := make([]entities.Alert, 0)
+	for _, rule := range alertRules {
+		compiled, err := compileRule(rule)
+		if err != nil {
+			// rules are checked on save, so it is possible only if the schema has been changed
+			a.logger.Error(
+				"skip invalid alert rule",
+				zap.String("reqID", reqID.String()),
+				zap.String("ruleID", rule.ID.Hex()),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		count, err := a.detectionRepo.Count(
+			ctx, detection.ClientID, detection.Timestamp.Add(-compiled.window()), detection.Timestamp,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "can't count detections of the client")
+		}
+
+		matched, err := compiled.match(*detection, count)
+		if err != nil {
+			return nil, errors.Wrap(err, "can't evaluate the alert rule")
+		}
+
+		if !matched {
+			continue
+		}
+
+		alert := newAlert(rule, *detection, time.Now().UTC())
+		if _, err := a.alertRepo.Create(ctx, &alert); err != nil {
+			return nil, errors.Wrap(err, "can't save the alert")
+		}
+
+		if err := a.publisher.SendAlert(ctx, reqID, alert); err != nil {
+			span.RecordError(err)
+			a.logger.Error(
+				"error during publish alert",
+				zap.String("reqID", reqID.String()),
+				zap.String("alertID", alert.ID.Hex()),
+				zap.Error(err),
+			)
+		}
+
+		alerts = append(alerts, alert)
+	}
+
+	return alerts, nil
+}
+
+func (a Alert) List(ctx context.Context, reqID uuid.UUID, filter entities.AlertFilter) ([]entities.Alert, error) {
+	ctx, span := a.tracer.Start(ctx, "uCase.Alert.List")
+	defer span.End()
+
+	alerts, err := a.alertRepo.List(ctx, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get the list of alerts")
+	}
+
+	return alerts, nil
+}
@@ -0,0 +1,170 @@
+package uCase_test
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	mock_repository "github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository/mocks"
+	"github.com/Imm0bilize/gunshot-api-service/internal/uCase"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+func TestAlertEvaluate(t *testing.T) {
+	var (
+		clientID = primitive.NewObjectID()
+		night    = time.Date(2022, 12, 1, 23, 30, 0, 0, time.UTC)
+		day      = time.Date(2022, 12, 1, 12, 0, 0, 0, time.UTC)
+	)
+
+	testTable := []struct {
+		name      string
+		rule      entities.AlertRule
+		detection entities.Detection
+		count     int64
+		expAlerts int
+	}{
+		{
+			name:      "confident gunshot",
+			rule:      entities.AlertRule{Expression: "label == gunshot && confidence > 0.85"},
+			detection: entities.Detection{Label: entities.LabelGunshot, Confidence: 0.9, Timestamp: day},
+			count:     1,
+			expAlerts: 1,
+		},
+		{
+			name:      "low confidence",
+			rule:      entities.AlertRule{Expression: "label == gunshot && confidence > 0.85"},
+			detection: entities.Detection{Label: entities.LabelGunshot, Confidence: 0.5, Timestamp: day},
+			count:     1,
+			expAlerts: 0,
+		},
+		{
+			name:      "burst of detections",
+			rule:      entities.AlertRule{Expression: "count >= 3", Window: 60},
+			detection: entities.Detection{Label: entities.LabelGunshot, Timestamp: day},
+			count:     3,
+			expAlerts: 1,
+		},
+		{
+			name:      "night only rule at day",
+			rule:      entities.AlertRule{Expression: "hour >= 22 || hour < 6", Timezone: "Europe/Moscow"},
+			detection: entities.Detection{Label: entities.LabelGunshot, Timestamp: day},
+			count:     1,
+			expAlerts: 0,
+		},
+		{
+			name:      "night only rule in local time",
+			rule:      entities.AlertRule{Expression: "hour >= 22 || hour < 6", Timezone: "Europe/Moscow"},
+			detection: entities.Detection{Label: entities.LabelGunshot, Timestamp: night},
+			count:     1,
+			expAlerts: 1,
+		},
+	}
+
+	for _, tCase := range testTable {
+		t.Run(tCase.name, func(t *testing.T) {
+			var (
+				ctrl       = gomock.NewController(t)
+				alertRules = mock_repository.NewMockAlertRuleRepository(ctrl)
+				alerts     = mock_repository.NewMockAlertRepository(ctrl)
+				detections = mock_repository.NewMockDetectionRepository(ctrl)
+				publisher  = &fakePublisher{}
+			)
+
+			tCase.rule.ID = primitive.NewObjectID()
+			tCase.detection.ClientID = clientID
+			window := time.Duration(tCase.rule.Window) * time.Second
+
+			alertRules.EXPECT().FindForClient(gomock.Any(), clientID, gomock.Any()).Return([]entities.AlertRule{tCase.rule}, nil).Times(1)
+			detections.EXPECT().Count(gomock.Any(), clientID, tCase.detection.Timestamp.Add(-window), tCase.detection.Timestamp).
+				Return(tCase.count, nil).Times(1)
+			alerts.EXPECT().Create(gomock.Any(), gomock.Any()).Return(primitive.NewObjectID().Hex(), nil).Times(tCase.expAlerts)
+
+			useCase := uCase.NewAlertUCase(zap.NewExample(), alerts, alertRules, detections, publisher)
+			got, err := useCase.Evaluate(context.Background(), uuid.New(), &tCase.detection)
+			require.NoError(t, err)
+			require.Len(t, got, tCase.expAlerts)
+			require.Len(t, publisher.alerts, tCase.expAlerts)
+
+			ctrl.Finish()
+		})
+	}
+}
+
+func TestAlertRuleCreateValidation(t *testing.T) {
+	testTable := []struct {
+		name   string
+		rule   entities.AlertRule
+		expErr error
+	}{
+		{
+			name: "valid rule",
+			rule: entities.AlertRule{Expression: "count >= 3", Window: 60, Timezone: "Europe/Moscow"},
+		},
+		{
+			name:   "unknown variable",
+			rule:   entities.AlertRule{Expression: "loudness > 3"},
+			expErr: uCase.ErrInvalidRule,
+		},
+		{
+			name:   "unknown timezone",
+			rule:   entities.AlertRule{Expression: "count >= 3", Timezone: "Mars/Olympus"},
+			expErr: uCase.ErrInvalidRule,
+		},
+		{
+			name:   "negative window",
+			rule:   entities.AlertRule{Expression: "count >= 3", Window: -1},
+			expErr: uCase.ErrInvalidRule,
+		},
+	}
+
+	for _, tCase := range testTable {
+		t.Run(tCase.name, func(t *testing.T) {
+			var (
+				ctrl       = gomock.NewController(t)
+				alertRules = mock_repository.NewMockAlertRuleRepository(ctrl)
+			)
+
+			if tCase.expErr == nil {
+				alertRules.EXPECT().Create(gomock.Any(), &tCase.rule).Return(primitive.NewObjectID().Hex(), nil).Times(1)
+			}
+
+			useCase := uCase.NewAlertRuleUCase(zap.NewExample(), alertRules, nil)
+			_, err := useCase.Create(context.Background(), uuid.New(), &tCase.rule)
+			require.ErrorIs(t, err, tCase.expErr)
+
+			ctrl.Finish()
+		})
+	}
+}
+
+func TestAlertRuleDryRun(t *testing.T) {
+	var (
+		ctrl       = gomock.NewController(t)
+		detections = mock_repository.NewMockDetectionRepository(ctrl)
+		clientID   = primitive.NewObjectID()
+		start      = time.Now().Add(-time.Hour)
+	)
+	defer ctrl.Finish()
+
+	history := []entities.Detection{
+		{ClientID: clientID, Timestamp: start},
+		{ClientID: clientID, Timestamp: start.Add(20 * time.Second)},
+		{ClientID: clientID, Timestamp: start.Add(40 * time.Second)},
+		{ClientID: clientID, Timestamp: start.Add(50 * time.Second)},
+		{ClientID: clientID, Timestamp: start.Add(10 * time.Minute)},
+	}
+	detections.EXPECT().List(gomock.Any(), gomock.Any()).Return(history, nil).Times(1)
+
+	useCase := uCase.NewAlertRuleUCase(zap.NewExample(), nil, detections)
+	result, err := useCase.DryRun(
+		context.Background(), uuid.New(), entities.AlertRule{Expression: "count >= 3", Window: 60}, 7,
+	)
+	require.NoError(t, err)
+	require.Equal(t, 5, result.Evaluated)
+	require.Len(t, result.Alerts, 2)
+}
@@ -3,36 +3,91 @@ package uCase
 import (
 	"context"
 	"fmt"
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
 	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository"
+	"github.com/Imm0bilize/gunshot-api-service/internal/privacy"
+	"github.com/Imm0bilize/gunshot-api-service/internal/tenant"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
+	"time"
 )
 
+const _chainRetries = 5
+
 type Sender interface {
 	Send(ctx context.Context, reqID uuid.UUID, msg entities.Message) error
 }
 
+type ChainRepo interface {
+	Create(ctx context.Context, record *entities.ChainRecord) (string, error)
+	FindUpload(
+		ctx context.Context, clientID primitive.ObjectID, rawTimestamp time.Time, payloadHash string,
+	) (entities.ChainRecord, error)
+	Bounds(ctx context.Context, clientID primitive.ObjectID, from, to time.Time) (int64, int64, error)
+	Range(ctx context.Context, clientID primitive.ObjectID, from, to int64) ([]entities.ChainRecord, error)
+}
+
+// QuotaRepo counts uploads of organizations per minute for their quota
+type QuotaRepo interface {
+	TakeUpload(ctx context.Context, tenantID primitive.ObjectID, minute time.Time, limit int) (bool, error)
+}
+
+// BlobRepo keeps uploaded audio, so it can be handed over as evidence
+type BlobRepo interface {
+	Put(ctx context.Context, blob *entities.Blob) error
+	Get(ctx context.Context, key string) (entities.Blob, error)
+}
+
 type Audio struct {
-	audioSender Sender
-	tracer      trace.Tracer
-	logger      *zap.Logger
-	audioLength int
+	audioSender       Sender
+	clientRepo        ClientRepo
+	chainRepo         ChainRepo
+	blobRepo          BlobRepo
+	retention         *RetentionPolicy
+	signatureRequired bool
+	quotaRepo         QuotaRepo
+	tracer            trace.Tracer
+	logger            *zap.Logger
+	audioLength       int
 }
 
 var (
-	ErrNotEqRequiredLength = errors.New("the audio not equal to the required length")
+	ErrNotEqRequiredLength = apperr.New(apperr.InvalidArgument, "the audio not equal to the required length")
+	ErrChainContention     = apperr.New(apperr.Conflict, "too many concurrent uploads of the client")
+	ErrSignatureRequired   = apperr.New(apperr.PermissionDenied, "the upload must be signed by the device key")
+	ErrInvalidSignature    = apperr.New(apperr.PermissionDenied, "the signature of the upload is invalid")
+	ErrReplayedUpload      = apperr.New(apperr.Conflict, "the sequence of the upload has already been used")
 )
 
-func NewAudioUCase(logger *zap.Logger, audioSender Sender, audioLength int) *Audio {
+// NewAudioUCase creates the audio use case. Uploads of clients with device keys are always checked,
+// signatureRequired rejects unsigned uploads of clients without keys as well
+func NewAudioUCase(
+	logger *zap.Logger,
+	audioSender Sender,
+	clientRepo ClientRepo,
+	chainRepo ChainRepo,
+	blobRepo BlobRepo,
+	quotaRepo QuotaRepo,
+	retention *RetentionPolicy,
+	audioLength int,
+	signatureRequired bool,
+) *Audio {
 	return &Audio{
-		audioSender: audioSender,
-		tracer:      otel.Tracer("uCase.Audio"),
-		audioLength: audioLength,
-		logger:      logger,
+		audioSender:       audioSender,
+		clientRepo:        clientRepo,
+		chainRepo:         chainRepo,
+		blobRepo:          blobRepo,
+		retention:         retention,
+		signatureRequired: signatureRequired,
+		quotaRepo:         quotaRepo,
+		tracer:            otel.Tracer("uCase.Audio"),
+		audioLength:       audioLength,
+		logger:            logger,
 	}
 }
 
@@ -40,16 +95,78 @@ func (a Audio) Upload(ctx context.Context, reqID uuid.UUID, clientID string, msg
 	ctx, span := a.tracer.Start(ctx, "uCase.Audio.Upload")
 	defer span.End()
 
-	castedID, err := primitive.ObjectIDFromHex(clientID)
+	if err := a.takeUpload(ctx); err != nil {
+		return err
+	}
+
+	client, err := a.clientRepo.Get(ctx, clientID)
 	if err != nil {
-		return errors.Wrap(err, "error during convert client id")
+		return errors.Wrap(err, "can't get the client")
 	}
-	msg.ID = castedID
+	msg.ID = client.ID
+
+	// the client clock drifts, so the timestamp is moved to the server time
+	msg.RawTimestamp = msg.Timestamp
+	msg.Timestamp = client.Clock.Correct(msg.Timestamp)
 
 	//if err := a.validate(msg.Payload); err != nil {
 	//	return errors.Wrap(err, "validation error")
 	//}
 
+	payloadHash := entities.HashPayload(msg.Payload)
+
+	msg.Verification, err = a.verifySignature(client, msg, payloadHash)
+	if err != nil {
+		a.logger.Warn(
+			"upload is rejected",
+			zap.String("reqID", reqID.String()),
+			zap.String("clientID", clientID),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	// the device signature covers the audio it sent, the chain and the archive get the filtered one
+	if msg.Filter, err = a.filter(ctx, &msg, payloadHash); err != nil {
+		return err
+	}
+	if msg.Filter != nil {
+		payloadHash = entities.HashPayload(msg.Payload)
+	}
+
+	// the audio is addressed by its hash, so it's saved before the chain refers to it
+	blob := entities.Blob{
+		Key:         entities.AudioBlobKey(client.ID, payloadHash),
+		ContentType: msg.MessageType,
+		Data:        msg.Payload,
+		Size:        int64(len(msg.Payload)),
+		CreatedAt:   time.Now().UTC(),
+		ClientID:    client.ID,
+		Timestamp:   msg.Timestamp,
+		Filter:      msg.Filter,
+	}
+	if blob.ExpiresAt, err = a.retention.ExpiresAt(ctx, entities.RetentionAudio, blob.CreatedAt); err != nil {
+		return err
+	}
+	if err := a.blobRepo.Put(ctx, &blob); err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "can't save the audio")
+	}
+
+	link, err := a.extendChain(ctx, client, msg, payloadHash)
+	if err != nil {
+		a.logger.Error(
+			"error during extend hash chain",
+			zap.String("reqID", reqID.String()),
+			zap.String("clientID", clientID),
+			zap.Error(err),
+		)
+
+		return err
+	}
+	msg.Chain = link
+
 	if err := a.audioSender.Send(ctx, reqID, msg); err != nil {
 		span.RecordError(err)
 		return errors.Wrap(err, "error during send audio")
@@ -58,6 +175,246 @@ func (a Audio) Upload(ctx context.Context, reqID uuid.UUID, clientID string, msg
 	return nil
 }
 
+// filter applies the privacy filter of the organization to the payload, nil means the payload is kept.
+// The filter is deterministic, so a retried upload is stored under the same hash
+func (a Audio) filter(
+	ctx context.Context, msg *entities.Message, sourceHash string,
+) (*entities.AppliedFilter, error) {
+	organization, ok := tenant.Organization(ctx)
+	if !ok || !organization.Privacy.Enabled() {
+		return nil, nil
+	}
+
+	payload, applied, err := privacy.Apply(organization.Privacy.Mode, msg.Payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't apply the privacy filter")
+	}
+	applied.SourceHash = sourceHash
+	msg.Payload = payload
+
+	return &applied, nil
+}
+
+// extendChain appends the payload to the hash chain of the client. The record is saved first, the unique
+// sequence of the client makes concurrent uploads take turns, and the head follows it. If the head is not
+// moved after the record is saved, the next upload moves it. The retry of the upload gets the record it has
+// already got, so the audio is forwarded with the same link and consumers may get the link twice
+func (a Audio) extendChain(
+	ctx context.Context, client entities.Client, msg entities.Message, payloadHash string,
+) (entities.ChainLink, error) {
+	existing, err := a.chainRepo.FindUpload(ctx, client.ID, msg.RawTimestamp, payloadHash)
+	if err == nil {
+		return existing.ChainLink, nil
+	}
+	if !errors.Is(err, repository.ErrChainRecordNotFound) {
+		return entities.ChainLink{}, errors.Wrap(err, "can't find the chain record of the upload")
+	}
+
+	for attempt := 0; attempt < _chainRetries; attempt++ {
+		if attempt > 0 {
+			if client, err = a.clientRepo.Get(ctx, client.ID.Hex()); err != nil {
+				return entities.ChainLink{}, errors.Wrap(err, "can't get the client")
+			}
+		}
+
+		deviceSequence := client.Chain.DeviceSequence
+		if msg.Verification.Status == entities.SignatureVerified {
+			if msg.Signature.Sequence <= deviceSequence {
+				return entities.ChainLink{}, fmt.Errorf(
+					"%w: got %d, the last one is %d", ErrReplayedUpload, msg.Signature.Sequence, deviceSequence,
+				)
+			}
+			deviceSequence = msg.Signature.Sequence
+		}
+
+		record := entities.ChainRecord{
+			ClientID:  client.ID,
+			Timestamp: msg.Timestamp,
+			CreatedAt: time.Now().UTC(),
+			ChainLink: entities.ChainLink{
+				Sequence:    client.Chain.Sequence + 1,
+				PayloadHash: payloadHash,
+				PrevHash:    client.Chain.PrevHash(),
+			},
+			Verification:   msg.Verification,
+			RawTimestamp:   msg.RawTimestamp,
+			ContentType:    msg.MessageType,
+			Filter:         msg.Filter,
+			DeviceSequence: deviceSequence,
+		}
+		record.Hash = record.ComputeHash()
+
+		_, err := a.chainRepo.Create(ctx, &record)
+		if errors.Is(err, repository.ErrChainSequenceTaken) {
+			if err := a.catchUp(ctx, client); err != nil {
+				return entities.ChainLink{}, err
+			}
+
+			continue
+		}
+		if err != nil {
+			return entities.ChainLink{}, errors.Wrap(err, "can't save the chain record")
+		}
+
+		if err := a.advance(ctx, client.ID, client.Chain, record); err != nil {
+			return entities.ChainLink{}, err
+		}
+
+		return record.ChainLink, nil
+	}
+
+	return entities.ChainLink{}, ErrChainContention
+}
+
+// catchUp moves the head to the record next to it, the record may be saved by the upload which has failed
+// before it moved the head
+func (a Audio) catchUp(ctx context.Context, client entities.Client) error {
+	next := client.Chain.Sequence + 1
+
+	records, err := a.chainRepo.Range(ctx, client.ID, next, next)
+	if err != nil {
+		return errors.Wrap(err, "can't get the next chain record")
+	}
+	if len(records) == 0 {
+		return nil
+	}
+
+	return a.advance(ctx, client.ID, client.Chain, records[0])
+}
+
+// advance moves the head from the one the record follows to the record. The head moved by someone else
+// has been moved to the record or past it, since the sequence of the record is taken
+func (a Audio) advance(
+	ctx context.Context, clientID primitive.ObjectID, from entities.ChainHead, record entities.ChainRecord,
+) error {
+	// records saved before the device sequence was kept in them have none
+	deviceSequence := record.DeviceSequence
+	if deviceSequence < from.DeviceSequence {
+		deviceSequence = from.DeviceSequence
+	}
+
+	err := a.clientRepo.AdvanceChain(
+		ctx,
+		clientID,
+		from,
+		entities.ChainHead{Sequence: record.Sequence, Hash: record.Hash, DeviceSequence: deviceSequence},
+	)
+	if err != nil && !errors.Is(err, repository.ErrChainHeadMoved) {
+		return errors.Wrap(err, "can't advance the hash chain")
+	}
+
+	return nil
+}
+
+// verifySignature checks the upload against active keys of the client. The signature covers the timestamp
+// as the client sent it, before the clock correction
+func (a Audio) verifySignature(
+	client entities.Client, msg entities.Message, payloadHash string,
+) (entities.SignatureVerification, error) {
+	if len(msg.Signature.Signature) == 0 {
+		if len(client.Keys) > 0 || a.signatureRequired {
+			return entities.SignatureVerification{}, ErrSignatureRequired
+		}
+
+		return entities.SignatureVerification{Status: entities.SignatureAbsent}, nil
+	}
+
+	message := entities.UploadSigningPayload(client.ID, msg.RawTimestamp, msg.Signature.Sequence, payloadHash)
+	for _, key := range client.ActiveKeys(time.Now()) {
+		if key.Verify(message, msg.Signature.Signature) {
+			return entities.SignatureVerification{Status: entities.SignatureVerified, KeyID: key.ID}, nil
+		}
+	}
+
+	return entities.SignatureVerification{}, ErrInvalidSignature
+}
+
+// Verify recomputes the hash chain of records the client uploaded in the time range
+func (a Audio) Verify(
+	ctx context.Context, reqID uuid.UUID, clientID string, from, to time.Time,
+) (entities.ChainVerification, error) {
+	ctx, span := a.tracer.Start(ctx, "uCase.Audio.Verify")
+	defer span.End()
+
+	client, err := a.clientRepo.Get(ctx, clientID)
+	if err != nil {
+		return entities.ChainVerification{}, errors.Wrap(err, "can't get the client")
+	}
+
+	verification := entities.ChainVerification{
+		ClientID: clientID,
+		From:     from,
+		To:       to,
+		Valid:    true,
+		Breaks:   make([]entities.ChainBreak, 0),
+	}
+
+	first, last, err := a.chainRepo.Bounds(ctx, client.ID, from, to)
+	if err != nil {
+		return entities.ChainVerification{}, errors.Wrap(err, "can't get bounds of the chain")
+	}
+	if first == 0 {
+		if from.IsZero() && to.IsZero() && client.Chain.Sequence != 0 {
+			verification.Valid = false
+			verification.Breaks = append(verification.Breaks, entities.ChainBreak{
+				Sequence: 1, Reason: entities.ChainRecordMissing,
+			})
+		}
+
+		return verification, nil
+	}
+
+	// the predecessor proves the first record in the range is linked to the rest of the chain
+	records, err := a.chainRepo.Range(ctx, client.ID, first-1, last)
+	if err != nil {
+		return entities.ChainVerification{}, errors.Wrap(err, "can't get chain records")
+	}
+
+	var predecessor *entities.ChainRecord
+	if len(records) > 0 && records[0].Sequence == first-1 {
+		predecessor = &records[0]
+		records = records[1:]
+	} else if first > 1 {
+		verification.Breaks = append(verification.Breaks, entities.ChainBreak{
+			Sequence: first - 1, Reason: entities.ChainRecordMissing,
+		})
+	}
+
+	verification.Records = len(records)
+	verification.FirstSequence = first
+	verification.LastSequence = last
+	verification.Breaks = append(verification.Breaks, entities.VerifyChain(predecessor, records)...)
+
+	// the head proves nothing has been removed from the end of the chain
+	if len(records) > 0 && last == client.Chain.Sequence && records[len(records)-1].Hash != client.Chain.Hash {
+		verification.Breaks = append(verification.Breaks, entities.ChainBreak{
+			Sequence: last,
+			Reason:   entities.ChainHeadMismatch,
+			Expected: client.Chain.Hash,
+			Actual:   records[len(records)-1].Hash,
+		})
+	}
+
+	if to.IsZero() && last < client.Chain.Sequence {
+		verification.Breaks = append(verification.Breaks, entities.ChainBreak{
+			Sequence: last + 1, Reason: entities.ChainRecordMissing,
+		})
+	}
+
+	verification.Valid = len(verification.Breaks) == 0
+
+	if !verification.Valid {
+		a.logger.Warn(
+			"hash chain of the client is broken",
+			zap.String("reqID", reqID.String()),
+			zap.String("clientID", clientID),
+			zap.Int("breaks", len(verification.Breaks)),
+		)
+	}
+
+	return verification, nil
+}
+
 func (a Audio) validate(audio []byte) error {
 	if len(audio) != a.audioLength {
 		return fmt.Errorf(
@@ -67,3 +424,26 @@ func (a Audio) validate(audio []byte) error {
 
 	return nil
 }
+
+// takeUpload counts the upload against the quota of the organization, the counter is shared by instances of
+// the service
+func (a Audio) takeUpload(ctx context.Context) error {
+	organization, ok := tenant.Organization(ctx)
+	if !ok || organization.Quota.UploadsPerMinute <= 0 {
+		return nil
+	}
+
+	limit := organization.Quota.UploadsPerMinute
+	taken, err := a.quotaRepo.TakeUpload(ctx, organization.ID, time.Now(), limit)
+	if err != nil {
+		return errors.Wrap(err, "can't count the upload")
+	}
+
+	if !taken {
+		return apperr.Wrap(
+			apperr.ResourceExhausted, fmt.Errorf("%w: %d uploads per minute", ErrQuotaExceeded, limit),
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,321 @@
+package uCase_test
+
+import (
+	"context"
+	"encoding/binary"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository"
+	mock_repository "github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository/mocks"
+	"github.com/Imm0bilize/gunshot-api-service/internal/privacy"
+	"github.com/Imm0bilize/gunshot-api-service/internal/tenant"
+	"github.com/Imm0bilize/gunshot-api-service/internal/uCase"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"math"
+	"testing"
+	"time"
+)
+
+// chainOf builds a valid chain of n records of the client
+func chainOf(clientID primitive.ObjectID, n int) []entities.ChainRecord {
+	records := make([]entities.ChainRecord, 0, n)
+	prev := entities.GenesisHash
+	for i := 1; i <= n; i++ {
+		record := entities.ChainRecord{
+			ClientID:  clientID,
+			Timestamp: time.Date(2022, 12, 1, 22, 0, i, 0, time.UTC),
+			ChainLink: entities.ChainLink{
+				Sequence:    int64(i),
+				PayloadHash: entities.HashPayload([]byte{byte(i)}),
+				PrevHash:    prev,
+			},
+		}
+		record.Hash = record.ComputeHash()
+		prev = record.Hash
+
+		records = append(records, record)
+	}
+
+	return records
+}
+
+func TestAudioUploadExtendsChain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		id      = primitive.NewObjectID()
+		payload = []byte("audio")
+		stale   = entities.Client{ID: id}
+		// another upload has saved its record but failed before it moved the head
+		orphan = entities.ChainRecord{
+			ClientID: id, ChainLink: entities.ChainLink{Sequence: 1, Hash: "aa"}, DeviceSequence: 3,
+		}
+		moved = entities.Client{ID: id, Chain: entities.ChainHead{Sequence: 1, Hash: "aa", DeviceSequence: 3}}
+	)
+
+	clients := mock_repository.NewMockClientRepository(ctrl)
+	chain := mock_repository.NewMockChainRepository(ctrl)
+
+	var saved entities.ChainRecord
+	gomock.InOrder(
+		clients.EXPECT().Get(gomock.Any(), id.Hex()).Return(stale, nil),
+		chain.EXPECT().FindUpload(gomock.Any(), id, gomock.Any(), entities.HashPayload(payload)).
+			Return(entities.ChainRecord{}, repository.ErrChainRecordNotFound),
+		chain.EXPECT().Create(gomock.Any(), gomock.Any()).Return("", repository.ErrChainSequenceTaken),
+		chain.EXPECT().Range(gomock.Any(), id, int64(1), int64(1)).Return([]entities.ChainRecord{orphan}, nil),
+		clients.EXPECT().AdvanceChain(gomock.Any(), id, stale.Chain, moved.Chain).Return(nil),
+		clients.EXPECT().Get(gomock.Any(), id.Hex()).Return(moved, nil),
+		chain.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, record *entities.ChainRecord) (string, error) {
+				saved = *record
+				return "", nil
+			},
+		),
+		// the head may be moved to the record by the next upload meanwhile
+		clients.EXPECT().AdvanceChain(gomock.Any(), id, moved.Chain, gomock.Any()).Return(repository.ErrChainHeadMoved),
+	)
+
+	sender := &fakeSender{}
+	blobs := newFakeBlobs()
+	audio := uCase.NewAudioUCase(zap.NewNop(), sender, clients, chain, blobs, nil, nil, 1000, false)
+
+	err := audio.Upload(context.Background(), uuid.New(), id.Hex(), entities.Message{Payload: payload})
+	require.NoError(t, err)
+
+	stored, ok := blobs.blobs[entities.AudioBlobKey(id, entities.HashPayload(payload))]
+	require.True(t, ok, "the audio must be kept for evidence")
+	require.Equal(t, payload, stored.Data)
+
+	require.Len(t, sender.messages, 1)
+	link := sender.messages[0].Chain
+	require.Equal(t, int64(2), link.Sequence)
+	require.Equal(t, "aa", link.PrevHash)
+	require.Equal(t, entities.HashPayload(payload), link.PayloadHash)
+	require.Equal(t, saved.ComputeHash(), link.Hash)
+	require.Equal(t, saved.ChainLink, link)
+	require.Equal(t, int64(3), saved.DeviceSequence)
+}
+
+func TestAudioUploadRetry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		id      = primitive.NewObjectID()
+		payload = []byte("audio")
+		ts      = time.Date(2022, 12, 1, 22, 0, 0, 0, time.UTC)
+		// the upload has been recorded, but the audio has not been forwarded
+		recorded = chainOf(id, 1)[0]
+	)
+
+	clients := mock_repository.NewMockClientRepository(ctrl)
+	clients.EXPECT().Get(gomock.Any(), id.Hex()).
+		Return(entities.Client{ID: id, Chain: entities.ChainHead{Sequence: 1, Hash: recorded.Hash}}, nil)
+
+	chain := mock_repository.NewMockChainRepository(ctrl)
+	chain.EXPECT().FindUpload(gomock.Any(), id, ts, entities.HashPayload(payload)).Return(recorded, nil)
+
+	sender := &fakeSender{}
+	audio := uCase.NewAudioUCase(zap.NewNop(), sender, clients, chain, newFakeBlobs(), nil, nil, 1000, false)
+
+	err := audio.Upload(context.Background(), uuid.New(), id.Hex(), entities.Message{Payload: payload, Timestamp: ts})
+	require.NoError(t, err)
+
+	require.Len(t, sender.messages, 1)
+	require.Equal(t, recorded.ChainLink, sender.messages[0].Chain)
+}
+
+// pcmWAV is the 16-bit mono WAV file at 16 kHz
+func pcmWAV(samples []int16) []byte {
+	audio := make([]byte, 44+2*len(samples))
+	copy(audio, "RIFF")
+	binary.LittleEndian.PutUint32(audio[4:], uint32(36+2*len(samples)))
+	copy(audio[8:], "WAVEfmt ")
+	binary.LittleEndian.PutUint32(audio[16:], 16)
+	binary.LittleEndian.PutUint16(audio[20:], 1)
+	binary.LittleEndian.PutUint16(audio[22:], 1)
+	binary.LittleEndian.PutUint32(audio[24:], 16000)
+	binary.LittleEndian.PutUint32(audio[28:], 32000)
+	binary.LittleEndian.PutUint16(audio[32:], 2)
+	binary.LittleEndian.PutUint16(audio[34:], 16)
+	copy(audio[36:], "data")
+	binary.LittleEndian.PutUint32(audio[40:], uint32(2*len(samples)))
+	for i, sample := range samples {
+		binary.LittleEndian.PutUint16(audio[44+2*i:], uint16(sample))
+	}
+
+	return audio
+}
+
+func TestAudioUploadAppliesPrivacyFilter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		id     = primitive.NewObjectID()
+		speech = make([]int16, 16000)
+		ctx    = tenant.WithOrganization(context.Background(), entities.Organization{
+			ID:      primitive.NewObjectID(),
+			Privacy: entities.PrivacyFilter{Mode: entities.PrivacyImpulsesOnly},
+		})
+	)
+	for i := range speech {
+		speech[i] = int16(3000 * math.Sin(2*math.Pi*1000*float64(i)/16000))
+	}
+	payload := pcmWAV(speech)
+
+	clients := mock_repository.NewMockClientRepository(ctrl)
+	clients.EXPECT().Get(gomock.Any(), id.Hex()).Return(entities.Client{ID: id}, nil)
+	clients.EXPECT().AdvanceChain(gomock.Any(), id, gomock.Any(), gomock.Any()).Return(nil)
+
+	var saved entities.ChainRecord
+	chain := mock_repository.NewMockChainRepository(ctrl)
+	chain.EXPECT().FindUpload(gomock.Any(), id, gomock.Any(), gomock.Any()).
+		Return(entities.ChainRecord{}, repository.ErrChainRecordNotFound)
+	chain.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, record *entities.ChainRecord) (string, error) {
+			saved = *record
+			return "", nil
+		},
+	)
+
+	sender := &fakeSender{}
+	blobs := newFakeBlobs()
+	audio := uCase.NewAudioUCase(zap.NewNop(), sender, clients, chain, blobs, nil, nil, 1000, false)
+
+	require.NoError(t, audio.Upload(ctx, uuid.New(), id.Hex(), entities.Message{Payload: payload}))
+
+	// there are no impulses, so the speech is gone
+	silence := pcmWAV(make([]int16, len(speech)))
+	filteredHash := entities.HashPayload(silence)
+
+	stored, ok := blobs.blobs[entities.AudioBlobKey(id, filteredHash)]
+	require.True(t, ok, "the filtered audio must be archived")
+	require.Equal(t, silence, stored.Data)
+	require.Equal(t, entities.PrivacyImpulsesOnly, stored.Filter.Mode)
+	require.Equal(t, entities.HashPayload(payload), stored.Filter.SourceHash)
+
+	require.Equal(t, filteredHash, saved.PayloadHash)
+	require.Equal(t, stored.Filter, saved.Filter)
+
+	require.Len(t, sender.messages, 1)
+	require.Equal(t, silence, sender.messages[0].Payload)
+	require.Equal(t, stored.Filter, sender.messages[0].Filter)
+}
+
+func TestAudioUploadRejectsUnfilterableAudio(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		id  = primitive.NewObjectID()
+		ctx = tenant.WithOrganization(context.Background(), entities.Organization{
+			ID:      primitive.NewObjectID(),
+			Privacy: entities.PrivacyFilter{Mode: entities.PrivacySpeechBand},
+		})
+	)
+
+	clients := mock_repository.NewMockClientRepository(ctrl)
+	clients.EXPECT().Get(gomock.Any(), id.Hex()).Return(entities.Client{ID: id}, nil)
+
+	blobs := newFakeBlobs()
+	audio := uCase.NewAudioUCase(
+		zap.NewNop(), &fakeSender{}, clients, mock_repository.NewMockChainRepository(ctrl), blobs, nil, nil, 1000, false,
+	)
+
+	err := audio.Upload(ctx, uuid.New(), id.Hex(), entities.Message{Payload: []byte("mp3 frames")})
+	require.ErrorIs(t, err, privacy.ErrUnsupportedAudio)
+	require.Empty(t, blobs.blobs, "the unfiltered audio must not be archived")
+}
+
+func TestAudioVerify(t *testing.T) {
+	id := primitive.NewObjectID()
+
+	testTable := []struct {
+		name      string
+		records   func() []entities.ChainRecord
+		head      func([]entities.ChainRecord) entities.ChainHead
+		expReason []entities.ChainBreakReason
+	}{
+		{
+			name:    "valid chain",
+			records: func() []entities.ChainRecord { return chainOf(id, 5) },
+		},
+		{
+			name: "altered payload hash",
+			records: func() []entities.ChainRecord {
+				records := chainOf(id, 5)
+				records[2].PayloadHash = entities.HashPayload([]byte("forged"))
+				return records
+			},
+			expReason: []entities.ChainBreakReason{entities.ChainRecordAltered},
+		},
+		{
+			name: "recomputed record breaks the link",
+			records: func() []entities.ChainRecord {
+				records := chainOf(id, 5)
+				records[2].PayloadHash = entities.HashPayload([]byte("forged"))
+				records[2].Hash = records[2].ComputeHash()
+				return records
+			},
+			expReason: []entities.ChainBreakReason{entities.ChainLinkBroken},
+		},
+		{
+			name: "removed record",
+			records: func() []entities.ChainRecord {
+				records := chainOf(id, 5)
+				return append(records[:2], records[3:]...)
+			},
+			expReason: []entities.ChainBreakReason{entities.ChainRecordMissing},
+		},
+		{
+			name:    "removed tail",
+			records: func() []entities.ChainRecord { return chainOf(id, 5)[:4] },
+			head: func(records []entities.ChainRecord) entities.ChainHead {
+				return entities.ChainHead{Sequence: 4, Hash: chainOf(id, 5)[4].Hash}
+			},
+			expReason: []entities.ChainBreakReason{entities.ChainHeadMismatch},
+		},
+	}
+
+	for _, tCase := range testTable {
+		t.Run(tCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			records := tCase.records()
+			head := entities.ChainHead{Sequence: records[len(records)-1].Sequence, Hash: records[len(records)-1].Hash}
+			if tCase.head != nil {
+				head = tCase.head(records)
+			}
+
+			clients := mock_repository.NewMockClientRepository(ctrl)
+			clients.EXPECT().Get(gomock.Any(), id.Hex()).Return(entities.Client{ID: id, Chain: head}, nil)
+
+			chain := mock_repository.NewMockChainRepository(ctrl)
+			chain.EXPECT().Bounds(gomock.Any(), id, time.Time{}, time.Time{}).
+				Return(records[0].Sequence, records[len(records)-1].Sequence, nil)
+			chain.EXPECT().Range(gomock.Any(), id, records[0].Sequence-1, records[len(records)-1].Sequence).
+				Return(records, nil)
+
+			audio := uCase.NewAudioUCase(zap.NewNop(), &fakeSender{}, clients, chain, newFakeBlobs(), nil, nil, 1000, false)
+
+			verification, err := audio.Verify(context.Background(), uuid.New(), id.Hex(), time.Time{}, time.Time{})
+			require.NoError(t, err)
+
+			reasons := make([]entities.ChainBreakReason, 0)
+			for _, b := range verification.Breaks {
+				reasons = append(reasons, b.Reason)
+			}
+			if tCase.expReason == nil {
+				tCase.expReason = []entities.ChainBreakReason{}
+			}
+			require.Equal(t, tCase.expReason, reasons)
+			require.Equal(t, len(tCase.expReason) == 0, verification.Valid)
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package uCase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/Imm0bilize/gunshot-api-service/internal/audit"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/signing"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"io"
+)
+
+const _auditExportPage = 500
+
+var (
+	ErrSigningDisabled = apperr.New(apperr.Unimplemented, "the signing key is not configured")
+)
+
+type AuditRepo interface {
+	Create(ctx context.Context, entry *entities.AuditEntry) (string, error)
+	Complete(ctx context.Context, entry *entities.AuditEntry) error
+	List(ctx context.Context, filter entities.AuditFilter) ([]entities.AuditEntry, error)
+}
+
+// AuditTrailer is the last line of the exported JSONL, it signs the SHA-256 of all the lines before it
+type AuditTrailer struct {
+	Entries   int               `json:"entries"`
+	Signature signing.Signature `json:"signature"`
+}
+
+type Audit struct {
+	tracer    trace.Tracer
+	logger    *zap.Logger
+	auditRepo AuditRepo
+	signer    *signing.Signer
+}
+
+// NewAuditUCase creates the audit use case, the nil signer disables the signed export
+func NewAuditUCase(logger *zap.Logger, auditRepo AuditRepo, signer *signing.Signer) *Audit {
+	return &Audit{
+		tracer:    otel.Tracer("uCase.Audit"),
+		logger:    logger,
+		auditRepo: auditRepo,
+		signer:    signer,
+	}
+}
+
+// Record appends the entry to the audit log
+func (a Audit) Record(ctx context.Context, reqID uuid.UUID, entry *entities.AuditEntry) error {
+	ctx, span := a.tracer.Start(ctx, "uCase.Audit.Record")
+	defer span.End()
+
+	if _, err := a.auditRepo.Create(ctx, entry); err != nil {
+		a.logger.Error(
+			"error during record audit entry",
+			zap.String("reqID", reqID.String()),
+			zap.String("route", entry.Route),
+			zap.String("target", entry.Target.ID),
+			zap.Error(err),
+		)
+
+		return errors.Wrap(err, "can't record the audit entry")
+	}
+
+	return nil
+}
+
+// Complete saves the outcome of the entry recorded as pending
+func (a Audit) Complete(ctx context.Context, reqID uuid.UUID, entry *entities.AuditEntry) error {
+	ctx, span := a.tracer.Start(ctx, "uCase.Audit.Complete")
+	defer span.End()
+
+	if err := a.auditRepo.Complete(ctx, entry); err != nil {
+		a.logger.Error(
+			"error during complete audit entry",
+			zap.String("reqID", reqID.String()),
+			zap.String("route", entry.Route),
+			zap.String("target", entry.Target.ID),
+			zap.Error(err),
+		)
+
+		return errors.Wrap(err, "can't complete the audit entry")
+	}
+
+	return nil
+}
+
+func (a Audit) List(ctx context.Context, reqID uuid.UUID, filter entities.AuditFilter) ([]entities.AuditEntry, error) {
+	ctx, span := a.tracer.Start(ctx, "uCase.Audit.List")
+	defer span.End()
+
+	entries, err := a.auditRepo.List(ctx, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get the list of audit entries")
+	}
+
+	return entries, nil
+}
+
+// Export writes all entries matching the filter as JSON lines followed by the signed AuditTrailer.
+// The filter limit is ignored, entries are read page by page
+func (a Audit) Export(ctx context.Context, reqID uuid.UUID, filter entities.AuditFilter, w io.Writer) error {
+	ctx, span := a.tracer.Start(ctx, "uCase.Audit.Export")
+	defer span.End()
+
+	if a.signer == nil {
+		return ErrSigningDisabled
+	}
+
+	var (
+		hash    = sha256.New()
+		encoder = json.NewEncoder(io.MultiWriter(w, hash))
+		count   int
+	)
+
+	filter.Limit = _auditExportPage
+	for {
+		entries, err := a.auditRepo.List(ctx, filter)
+		if err != nil {
+			return errors.Wrap(err, "can't get audit entries")
+		}
+
+		for _, entry := range entries {
+			if err := encoder.Encode(entry); err != nil {
+				return errors.Wrap(err, "error during write audit entry")
+			}
+		}
+		count += len(entries)
+
+		if int64(len(entries)) < filter.Limit {
+			break
+		}
+		filter.Offset += filter.Limit
+	}
+
+	trailer := AuditTrailer{Entries: count, Signature: a.signer.Sign(hash.Sum(nil))}
+	if err := json.NewEncoder(w).Encode(trailer); err != nil {
+		return errors.Wrap(err, "error during write audit signature")
+	}
+
+	return nil
+}
+
+// auditChange attaches the change of the entity to the audit entry of the request
+func auditChange(ctx context.Context, logger *zap.Logger, reqID uuid.UUID, before, after interface{}) {
+	if err := audit.Change(ctx, before, after); err != nil {
+		logger.Warn("can't compute the audited change", zap.String("reqID", reqID.String()), zap.Error(err))
+	}
+}
@@ -0,0 +1,135 @@
+package uCase_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"github.com/Imm0bilize/gunshot-api-service/internal/audit"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	mock_repository "github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository/mocks"
+	"github.com/Imm0bilize/gunshot-api-service/internal/signing"
+	"github.com/Imm0bilize/gunshot-api-service/internal/uCase"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuditExport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	entries := make([]entities.AuditEntry, 501)
+	for i := range entries {
+		entries[i] = entities.AuditEntry{ID: primitive.NewObjectID(), Actor: "admin"}
+	}
+
+	repo := mock_repository.NewMockAuditRepository(ctrl)
+	repo.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, filter entities.AuditFilter) ([]entities.AuditEntry, error) {
+			end := filter.Offset + filter.Limit
+			if end > int64(len(entries)) {
+				end = int64(len(entries))
+			}
+			return entries[filter.Offset:end], nil
+		},
+	).Times(2)
+
+	signer, err := signing.NewSigner(strings.Repeat("ab", 32))
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	useCase := uCase.NewAuditUCase(zap.NewNop(), repo, signer)
+	require.NoError(t, useCase.Export(context.Background(), uuid.New(), entities.AuditFilter{Limit: 10}, &out))
+
+	var (
+		lines   []string
+		scanner = bufio.NewScanner(&out)
+	)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	require.Len(t, lines, len(entries)+1)
+
+	var trailer uCase.AuditTrailer
+	require.NoError(t, json.Unmarshal([]byte(lines[len(lines)-1]), &trailer))
+	require.Equal(t, len(entries), trailer.Entries)
+	require.Equal(t, signer.PublicKey(), trailer.Signature.PublicKey)
+
+	body := strings.Join(lines[:len(lines)-1], "\n") + "\n"
+	digest := sha256.Sum256([]byte(body))
+	require.NoError(t, signing.Verify(trailer.Signature, digest[:]))
+
+	tampered := sha256.Sum256([]byte(strings.Replace(body, "admin", "nobody", 1)))
+	require.ErrorIs(t, signing.Verify(trailer.Signature, tampered[:]), signing.ErrInvalidSignature)
+}
+
+func TestAuditExportWithoutSigner(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	useCase := uCase.NewAuditUCase(zap.NewNop(), mock_repository.NewMockAuditRepository(ctrl), nil)
+
+	err := useCase.Export(context.Background(), uuid.New(), entities.AuditFilter{}, &bytes.Buffer{})
+	require.ErrorIs(t, err, uCase.ErrSigningDisabled)
+}
+
+func TestClientUpdateAudited(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		id     = primitive.NewObjectID()
+		before = entities.Client{ID: id, FullName: "old", LocationName: "station", ZoneIDs: []primitive.ObjectID{}}
+		after  = entities.Client{ID: id, FullName: "new", LocationName: "station", ZoneIDs: []primitive.ObjectID{}}
+		entry  = &entities.AuditEntry{}
+		ctx    = audit.WithEntry(context.Background(), entry)
+	)
+
+	clients := mock_repository.NewMockClientRepository(ctrl)
+	zones := mock_repository.NewMockZoneRepository(ctrl)
+	clients.EXPECT().Update(gomock.Any(), id.Hex(), int64(0), gomock.Any()).Return(before, after, nil).Times(1)
+	zones.EXPECT().List(gomock.Any()).Return([]entities.Zone{}, nil).Times(1)
+
+	versions := mock_repository.NewMockClientVersionRepository(ctrl)
+	versions.EXPECT().Create(gomock.Any(), gomock.Any()).Return("", nil).Times(1)
+
+	useCase := uCase.NewClientUCase(zap.NewNop(), clients, zones, versions, nil, time.Hour)
+	_, err := useCase.Update(ctx, uuid.New(), id.Hex(), 0, &entities.Client{FullName: "new"})
+	require.NoError(t, err)
+
+	require.Equal(t, []entities.AuditChange{{Field: "fullName", Before: "old", After: "new"}}, entry.Changes)
+}
+
+func TestClientDeleteAudited(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		client    = entities.Client{ID: primitive.NewObjectID(), FullName: "old"}
+		deletedAt = time.Now()
+		deleted   = entities.Client{ID: client.ID, FullName: "old", DeletedAt: &deletedAt}
+		entry     = &entities.AuditEntry{}
+		ctx       = audit.WithEntry(context.Background(), entry)
+	)
+
+	clients := mock_repository.NewMockClientRepository(ctrl)
+	clients.EXPECT().Delete(gomock.Any(), client.ID.Hex(), int64(0)).Return(client, deleted, nil).Times(1)
+	versions := mock_repository.NewMockClientVersionRepository(ctrl)
+	versions.EXPECT().Create(gomock.Any(), gomock.Any()).Return("", nil).Times(1)
+
+	useCase := uCase.NewClientUCase(
+		zap.NewNop(), clients, mock_repository.NewMockZoneRepository(ctrl), versions, nil, time.Hour,
+	)
+	require.NoError(t, useCase.Delete(ctx, uuid.New(), client.ID.Hex(), 0))
+
+	require.Len(t, entry.Changes, 1)
+	require.Equal(t, "deletedAt", entry.Changes[0].Field)
+	require.Nil(t, entry.Changes[0].Before)
+}
@@ -2,32 +2,231 @@ package uCase
 
 import (
 	"context"
+	"fmt"
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/Imm0bilize/gunshot-api-service/internal/audit"
+	"github.com/Imm0bilize/gunshot-api-service/internal/clientio"
 	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository"
+	"github.com/Imm0bilize/gunshot-api-service/internal/tenant"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
+	"io"
+	"time"
 )
 
+var ErrInvalidClient = apperr.New(apperr.InvalidArgument, "the client is invalid")
+
 type ClientRepo interface {
 	Create(ctx context.Context, client *entities.Client) (string, error)
+	CreateMany(ctx context.Context, clients []*entities.Client) ([]string, error)
 	Get(ctx context.Context, id string) (entities.Client, error)
-	Update(ctx context.Context, id string, client *entities.Client) error
-	Delete(ctx context.Context, id string) error
+	Update(
+		ctx context.Context, id string, version int64, client *entities.Client,
+	) (entities.Client, entities.Client, error)
+	Patch(
+		ctx context.Context, id string, version int64, patch entities.ClientPatch,
+	) (entities.Client, entities.Client, error)
+	Delete(ctx context.Context, id string, version int64) (entities.Client, entities.Client, error)
+	List(ctx context.Context) ([]entities.Client, error)
+	Page(ctx context.Context, filter entities.ClientFilter) ([]entities.Client, error)
+	Count(ctx context.Context) (int64, error)
+	SetZones(ctx context.Context, id primitive.ObjectID, zoneIDs []primitive.ObjectID) error
+	SetHealth(ctx context.Context, id primitive.ObjectID, health entities.ClientHealth) error
+	SetHealthStatus(
+		ctx context.Context, id primitive.ObjectID, lastSeen time.Time, status entities.HealthStatus,
+	) (bool, error)
+	SetAppliedConfig(ctx context.Context, id primitive.ObjectID, version int64) error
+	SetClock(ctx context.Context, id primitive.ObjectID, clock entities.ClockState) error
+	AddKey(ctx context.Context, id primitive.ObjectID, key entities.DeviceKey, expireBy time.Time) error
+	RevokeKey(ctx context.Context, id primitive.ObjectID, keyID string, at time.Time) (entities.DeviceKey, error)
+	AdvanceChain(ctx context.Context, id primitive.ObjectID, from, to entities.ChainHead) error
+	GetWithDeleted(ctx context.Context, id string) (entities.Client, error)
+	Restore(ctx context.Context, id primitive.ObjectID) error
+	Purge(ctx context.Context, before time.Time) ([]primitive.ObjectID, error)
+}
+
+type ClientVersionRepo interface {
+	Create(ctx context.Context, version *entities.ClientVersion) (string, error)
+	List(ctx context.Context, clientID primitive.ObjectID) ([]entities.ClientVersion, error)
+	DeleteForClients(ctx context.Context, clientIDs []primitive.ObjectID) (int64, error)
 }
 
 type Client struct {
-	tracer     trace.Tracer
-	clientRepo ClientRepo
-	logger     *zap.Logger
+	tracer           trace.Tracer
+	clientRepo       ClientRepo
+	zoneRepo         ZoneRepo
+	versionRepo      ClientVersionRepo
+	organizationRepo OrganizationRepo
+	grace            time.Duration
+	logger           *zap.Logger
 }
 
-func NewClientUCase(logger *zap.Logger, clientRepo ClientRepo) *Client {
+// NewClientUCase creates the client use case, deleted clients are purged after the grace period
+func NewClientUCase(
+	logger *zap.Logger,
+	clientRepo ClientRepo,
+	zoneRepo ZoneRepo,
+	versionRepo ClientVersionRepo,
+	organizationRepo OrganizationRepo,
+	grace time.Duration,
+) *Client {
 	return &Client{
-		logger:     logger,
-		tracer:     otel.Tracer("uCase.Client"),
-		clientRepo: clientRepo,
+		logger:           logger,
+		tracer:           otel.Tracer("uCase.Client"),
+		clientRepo:       clientRepo,
+		zoneRepo:         zoneRepo,
+		versionRepo:      versionRepo,
+		organizationRepo: organizationRepo,
+		grace:            grace,
+	}
+}
+
+// assignZones puts the client into zones by its coordinates
+func (c Client) assignZones(ctx context.Context, client *entities.Client) error {
+	zones, err := c.zoneRepo.List(ctx)
+	if err != nil {
+		return errors.Wrap(err, "can't get zones")
+	}
+
+	client.ZoneIDs = entities.ZonesOf(zones, client.Location())
+
+	return nil
+}
+
+// reserveSensors takes places of the sensors in the quota of the organization before they are created, so
+// concurrent requests can't exceed it. The counter of the organization made before it is set up from
+// its clients on the first reservation
+func (c Client) reserveSensors(ctx context.Context, sensors int) error {
+	organization, ok := tenant.Organization(ctx)
+	if !ok {
+		return nil
+	}
+
+	limit := organization.Quota.MaxSensors
+
+	reserved, err := c.organizationRepo.ReserveSensors(ctx, organization.ID, sensors, limit)
+	if err != nil {
+		return errors.Wrap(err, "can't reserve sensors")
+	}
+
+	if !reserved {
+		count, err := c.clientRepo.Count(ctx)
+		if err != nil {
+			return errors.Wrap(err, "can't count clients")
+		}
+
+		initialized, err := c.organizationRepo.InitSensors(ctx, organization.ID, count)
+		if err != nil {
+			return errors.Wrap(err, "can't init sensors")
+		}
+
+		if initialized {
+			if reserved, err = c.organizationRepo.ReserveSensors(ctx, organization.ID, sensors, limit); err != nil {
+				return errors.Wrap(err, "can't reserve sensors")
+			}
+		}
+	}
+
+	if !reserved {
+		return fmt.Errorf("%w: %d sensors", ErrQuotaExceeded, limit)
+	}
+
+	return nil
+}
+
+// releaseSensors gives back places of the sensors deleted or not created, the failure leaves the counter
+// greater than the number of clients, so it's only logged
+func (c Client) releaseSensors(ctx context.Context, reqID uuid.UUID, sensors int) {
+	organization, ok := tenant.Organization(ctx)
+	if !ok || sensors == 0 {
+		return
+	}
+
+	if err := c.organizationRepo.ReleaseSensors(ctx, organization.ID, sensors); err != nil {
+		c.logger.Error(
+			"sensors of the organization are not released",
+			zap.String("reqID", reqID.String()),
+			zap.String("organizationID", organization.ID.Hex()),
+			zap.Int("sensors", sensors),
+			zap.Error(err),
+		)
+	}
+}
+
+// checkQuotaFor tells whether the organization may register the number of sensors now, without taking
+// places in the quota
+func (c Client) checkQuotaFor(ctx context.Context, sensors int) error {
+	organization, ok := tenant.Organization(ctx)
+	if !ok || organization.Quota.MaxSensors <= 0 {
+		return nil
+	}
+
+	count, err := c.clientRepo.Count(ctx)
+	if err != nil {
+		return errors.Wrap(err, "can't count clients")
+	}
+
+	if count+int64(sensors) > int64(organization.Quota.MaxSensors) {
+		return fmt.Errorf("%w: %d sensors", ErrQuotaExceeded, organization.Quota.MaxSensors)
+	}
+
+	return nil
+}
+
+// record audits the change and saves the new version of the client, the operation has already been
+// done, so failures are only logged
+func (c Client) record(
+	ctx context.Context, reqID uuid.UUID, operation entities.ClientOperation, before *entities.Client, after entities.Client,
+) {
+	var previous interface{}
+	if before != nil {
+		previous = before
+	}
+	auditChange(ctx, c.logger, reqID, previous, after)
+
+	c.saveVersion(ctx, reqID, operation, previous, after)
+}
+
+// recordMany audits creation of clients by one request and saves their first versions, the changes of
+// the clients are told apart by their IDs in the audit entry
+func (c Client) recordMany(ctx context.Context, reqID uuid.UUID, clients []*entities.Client) {
+	for _, client := range clients {
+		if err := audit.Append(ctx, client.ID.Hex(), nil, *client); err != nil {
+			c.logger.Warn("can't compute the audited change", zap.String("reqID", reqID.String()), zap.Error(err))
+		}
+
+		c.saveVersion(ctx, reqID, entities.ClientCreated, nil, *client)
+	}
+}
+
+func (c Client) saveVersion(
+	ctx context.Context, reqID uuid.UUID, operation entities.ClientOperation, before interface{}, after entities.Client,
+) {
+	changes, err := entities.Diff(before, after)
+	if err != nil {
+		c.logger.Warn("can't compute the change of the client", zap.String("reqID", reqID.String()), zap.Error(err))
+	}
+
+	version := entities.ClientVersion{
+		ClientID:  after.ID,
+		Operation: operation,
+		Client:    after,
+		Changes:   changes,
+		RequestID: reqID.String(),
+		CreatedAt: time.Now().UTC(),
+	}
+	if _, err := c.versionRepo.Create(ctx, &version); err != nil {
+		c.logger.Error(
+			"version of the client is lost",
+			zap.String("reqID", reqID.String()),
+			zap.String("clientID", after.ID.Hex()),
+			zap.Error(err),
+		)
 	}
 }
 
@@ -35,8 +234,17 @@ func (c Client) Create(ctx context.Context, reqID uuid.UUID, client *entities.Cl
 	ctx, span := c.tracer.Start(ctx, "uCase.Client.Create")
 	defer span.End()
 
+	if err := c.assignZones(ctx, client); err != nil {
+		return "", err
+	}
+
+	if err := c.reserveSensors(ctx, 1); err != nil {
+		return "", err
+	}
+
 	id, err := c.clientRepo.Create(ctx, client)
 	if err != nil {
+		c.releaseSensors(ctx, reqID, 1)
 		c.logger.Error(
 			"error during create new client",
 			zap.String("reqID", reqID.String()),
@@ -46,9 +254,112 @@ func (c Client) Create(ctx context.Context, reqID uuid.UUID, client *entities.Cl
 		return "", errors.Wrap(err, "can't create new client")
 	}
 
+	audit.SetTargetID(ctx, id)
+	c.record(ctx, reqID, entities.ClientCreated, nil, *client)
+
 	return id, nil
 }
 
+// Import validates all rows of the file and creates clients with one bulk write if none of them has errors.
+// The dry run only validates rows
+func (c Client) Import(
+	ctx context.Context, reqID uuid.UUID, format clientio.Format, r io.Reader, dryRun bool,
+) (entities.ClientImport, error) {
+	ctx, span := c.tracer.Start(ctx, "uCase.Client.Import")
+	defer span.End()
+
+	rows, err := clientio.Read(format, r)
+	if err != nil {
+		return entities.ClientImport{}, err
+	}
+
+	result := entities.ClientImport{
+		DryRun:  dryRun,
+		Rows:    len(rows),
+		Created: make([]entities.ImportedClient, 0),
+		Errors:  make([]entities.ImportError, 0),
+	}
+
+	clients := make([]*entities.Client, 0, len(rows))
+	for i := range rows {
+		row := &rows[i]
+		if row.Err == nil {
+			row.Err = validateClient(row.Client)
+		}
+		if row.Err != nil {
+			result.Errors = append(result.Errors, entities.ImportError{Row: row.Number, Error: row.Err.Error()})
+			continue
+		}
+
+		clients = append(clients, &row.Client)
+	}
+
+	if len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	if dryRun {
+		if err := c.checkQuotaFor(ctx, len(clients)); err != nil {
+			return entities.ClientImport{}, err
+		}
+
+		return result, nil
+	}
+
+	zones, err := c.zoneRepo.List(ctx)
+	if err != nil {
+		return entities.ClientImport{}, errors.Wrap(err, "can't get zones")
+	}
+	for _, client := range clients {
+		client.ZoneIDs = entities.ZonesOf(zones, client.Location())
+	}
+
+	if err := c.reserveSensors(ctx, len(clients)); err != nil {
+		return entities.ClientImport{}, err
+	}
+
+	// clients created before the failed one are kept, so they are recorded as well
+	ids, err := c.clientRepo.CreateMany(ctx, clients)
+	c.recordMany(ctx, reqID, clients[:len(ids)])
+
+	if err != nil {
+		c.releaseSensors(ctx, reqID, len(clients)-len(ids))
+		c.logger.Error(
+			"error during import clients",
+			zap.String("reqID", reqID.String()),
+			zap.Strings("created", ids),
+			zap.Error(err),
+		)
+
+		return entities.ClientImport{}, errors.Wrapf(
+			err, "can't import clients, %d of %d are created", len(ids), len(clients),
+		)
+	}
+
+	for i, id := range ids {
+		result.Created = append(result.Created, entities.ImportedClient{Row: rows[i].Number, ClientID: id})
+	}
+
+	return result, nil
+}
+
+// Export writes live clients of the tenant in the format
+func (c Client) Export(ctx context.Context, reqID uuid.UUID, format clientio.Format, w io.Writer) error {
+	ctx, span := c.tracer.Start(ctx, "uCase.Client.Export")
+	defer span.End()
+
+	clients, err := c.clientRepo.List(ctx)
+	if err != nil {
+		return errors.Wrap(err, "can't get clients")
+	}
+
+	if err := clientio.Write(format, w, clients); err != nil {
+		return errors.Wrap(err, "can't write clients")
+	}
+
+	return nil
+}
+
 func (c Client) Get(ctx context.Context, reqID uuid.UUID, clientID string) (entities.Client, error) {
 	ctx, span := c.tracer.Start(ctx, "uCase.Client.Get")
 	defer span.End()
@@ -61,25 +372,205 @@ func (c Client) Get(ctx context.Context, reqID uuid.UUID, clientID string) (enti
 	return client, nil
 }
 
-func (c Client) Update(ctx context.Context, reqID uuid.UUID, clientID string, client *entities.Client) error {
+// List returns the page of clients, deleted ones are not listed
+func (c Client) List(ctx context.Context, reqID uuid.UUID, filter entities.ClientFilter) ([]entities.Client, error) {
+	ctx, span := c.tracer.Start(ctx, "uCase.Client.List")
+	defer span.End()
+
+	clients, err := c.clientRepo.Page(ctx, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get the list of clients")
+	}
+
+	return clients, nil
+}
+
+// Update changes the client if it still has the version the operator has seen and returns the updated one
+func (c Client) Update(
+	ctx context.Context, reqID uuid.UUID, clientID string, version int64, client *entities.Client,
+) (entities.Client, error) {
 	ctx, span := c.tracer.Start(ctx, "uCase.Client.Update")
 	defer span.End()
 
-	err := c.clientRepo.Update(ctx, clientID, client)
+	if err := c.assignZones(ctx, client); err != nil {
+		return entities.Client{}, err
+	}
+
+	before, after, err := c.clientRepo.Update(ctx, clientID, version, client)
+	if err != nil {
+		return entities.Client{}, errors.Wrap(err, "can't update the client")
+	}
+	c.record(ctx, reqID, entities.ClientUpdated, &before, after)
+
+	return after, nil
+}
+
+// Patch changes only the fields of the patch if the client still has the version the operator has seen
+func (c Client) Patch(
+	ctx context.Context, reqID uuid.UUID, clientID string, version int64, patch entities.ClientPatch,
+) (entities.Client, error) {
+	ctx, span := c.tracer.Start(ctx, "uCase.Client.Patch")
+	defer span.End()
+
+	// the location is checked on the client of the version, the patch is written only over it
+	current, err := c.clientRepo.Get(ctx, clientID)
+	if err != nil {
+		return entities.Client{}, errors.Wrap(err, "can't patch the client")
+	}
+	if version == entities.AnyVersion {
+		version = current.Version
+	}
+	if current.Version != version {
+		return entities.Client{}, repository.ErrClientVersionMismatch
+	}
+
+	// the empty merge patch changes nothing, so the client is neither written nor gets another version
+	if patch.Empty() {
+		return current, nil
+	}
+
+	patched := patch.Apply(current)
+	if err := validateLocation(patched); err != nil {
+		return entities.Client{}, err
+	}
+
+	if patch.Moves() {
+		if err := c.assignZones(ctx, &patched); err != nil {
+			return entities.Client{}, err
+		}
+		patch.ZoneIDs = patched.ZoneIDs
+	}
+
+	before, after, err := c.clientRepo.Patch(ctx, clientID, version, patch)
 	if err != nil {
-		return errors.Wrap(err, "can't update the client")
+		return entities.Client{}, errors.Wrap(err, "can't patch the client")
+	}
+	c.record(ctx, reqID, entities.ClientUpdated, &before, after)
+
+	return after, nil
+}
+
+func validateClient(client entities.Client) error {
+	if client.FullName == "" {
+		return fmt.Errorf("%w: 'fullName' is required", ErrInvalidClient)
+	}
+	if client.LocationName == "" {
+		return fmt.Errorf("%w: 'locationName' is required", ErrInvalidClient)
+	}
+
+	return validateLocation(client)
+}
+
+func validateLocation(client entities.Client) error {
+	if client.Latitude < -90 || client.Latitude > 90 {
+		return fmt.Errorf("%w: latitude must be within [-90, 90]", ErrInvalidClient)
+	}
+	if client.Longitude < -180 || client.Longitude > 180 {
+		return fmt.Errorf("%w: longitude must be within [-180, 180]", ErrInvalidClient)
 	}
 
 	return nil
 }
 
-func (c Client) Delete(ctx context.Context, reqID uuid.UUID, clientID string) error {
+// Delete marks the client of the version deleted, it's purged after the grace period unless it's restored
+func (c Client) Delete(ctx context.Context, reqID uuid.UUID, clientID string, version int64) error {
 	ctx, span := c.tracer.Start(ctx, "uCase.Client.Delete")
 	defer span.End()
 
-	if err := c.clientRepo.Delete(ctx, clientID); err != nil {
+	before, after, err := c.clientRepo.Delete(ctx, clientID, version)
+	if err != nil {
 		return errors.Wrap(err, "can't delete the client")
 	}
+	c.releaseSensors(ctx, reqID, 1)
+	c.record(ctx, reqID, entities.ClientDeleted, &before, after)
 
 	return nil
 }
+
+// Restore brings back the client deleted within the grace period
+func (c Client) Restore(ctx context.Context, reqID uuid.UUID, clientID string) (entities.Client, error) {
+	ctx, span := c.tracer.Start(ctx, "uCase.Client.Restore")
+	defer span.End()
+
+	before, err := c.clientRepo.GetWithDeleted(ctx, clientID)
+	if err != nil {
+		return entities.Client{}, errors.Wrap(err, "can't restore the client")
+	}
+	if before.DeletedAt == nil {
+		return entities.Client{}, repository.ErrClientNotDeleted
+	}
+
+	// the place of the client may have been taken while it was deleted
+	if err := c.reserveSensors(ctx, 1); err != nil {
+		return entities.Client{}, err
+	}
+
+	if err := c.clientRepo.Restore(ctx, before.ID); err != nil {
+		c.releaseSensors(ctx, reqID, 1)
+		return entities.Client{}, errors.Wrap(err, "can't restore the client")
+	}
+
+	after := before
+	after.DeletedAt = nil
+	after.Version++
+	c.record(ctx, reqID, entities.ClientRestored, &before, after)
+
+	return after, nil
+}
+
+// History returns versions of the client from the oldest one, the history of deleted clients is kept
+// until they are purged
+func (c Client) History(ctx context.Context, reqID uuid.UUID, clientID string) ([]entities.ClientVersion, error) {
+	ctx, span := c.tracer.Start(ctx, "uCase.Client.History")
+	defer span.End()
+
+	client, err := c.clientRepo.GetWithDeleted(ctx, clientID)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get the client")
+	}
+
+	versions, err := c.versionRepo.List(ctx, client.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get the history of the client")
+	}
+
+	return versions, nil
+}
+
+// Purge removes clients deleted longer than the grace period ago with their history
+func (c Client) Purge(ctx context.Context) error {
+	ctx, span := c.tracer.Start(tenant.System(ctx), "uCase.Client.Purge")
+	defer span.End()
+
+	purged, err := c.clientRepo.Purge(ctx, time.Now().Add(-c.grace))
+	if err != nil {
+		return errors.Wrap(err, "can't purge deleted clients")
+	}
+	if len(purged) == 0 {
+		return nil
+	}
+
+	if _, err := c.versionRepo.DeleteForClients(ctx, purged); err != nil {
+		return errors.Wrap(err, "can't purge the history of deleted clients")
+	}
+
+	c.logger.Info("deleted clients are purged", zap.Int("count", len(purged)))
+
+	return nil
+}
+
+func (c Client) Monitor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.Purge(ctx); err != nil {
+				c.logger.Error("error during purge deleted clients", zap.Error(err))
+			}
+		}
+	}
+}
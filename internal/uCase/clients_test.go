@@ -2,6 +2,8 @@ package uCase_test
 
 import (
 	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/audit"
+	"github.com/Imm0bilize/gunshot-api-service/internal/clientio"
 	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
 	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository"
 	mock_repository "github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository/mocks"
@@ -10,30 +12,46 @@ import (
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.opentelemetry.io/otel"
 	"go.uber.org/zap"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestClientCreate(t *testing.T) {
 	testTable := []struct {
 		name          string
-		setMockOutput func(context.Context, *entities.Client, *mock_repository.MockClientRepository)
-		expErr        error
+		setMockOutput func(
+			context.Context, *entities.Client, *mock_repository.MockClientRepository, *mock_repository.MockClientVersionRepository,
+		)
+		expErr error
 	}{
 		{
 			name:   "successfully creating",
 			expErr: nil,
-			setMockOutput: func(ctx context.Context, client *entities.Client, repo *mock_repository.MockClientRepository) {
+			setMockOutput: func(
+				ctx context.Context,
+				client *entities.Client,
+				repo *mock_repository.MockClientRepository,
+				versions *mock_repository.MockClientVersionRepository,
+			) {
 				ctx, _ = otel.GetTracerProvider().Tracer("uCase.Client").Start(ctx, "uCase.Client.Create")
 				repo.EXPECT().Create(ctx, client).Return("", nil).Times(1)
+				versions.EXPECT().Create(ctx, gomock.Any()).Return("", nil).Times(1)
 			},
 		},
 		{
 			name:   "db client disconnect",
 			expErr: errors.New("can't create new client: client is disconnected"),
-			setMockOutput: func(ctx context.Context, client *entities.Client, repo *mock_repository.MockClientRepository) {
+			setMockOutput: func(
+				ctx context.Context,
+				client *entities.Client,
+				repo *mock_repository.MockClientRepository,
+				versions *mock_repository.MockClientVersionRepository,
+			) {
 				ctx, _ = otel.GetTracerProvider().Tracer("uCase.Client").Start(ctx, "uCase.Client.Create")
 				repo.EXPECT().Create(ctx, client).Return("", mongo.ErrClientDisconnected).Times(1)
 			},
@@ -43,15 +61,18 @@ func TestClientCreate(t *testing.T) {
 	for _, tCase := range testTable {
 		t.Run(tCase.name, func(t *testing.T) {
 			var (
-				ctx  = context.Background()
-				ctrl = gomock.NewController(t)
-				repo = mock_repository.NewMockClientRepository(ctrl)
+				ctx      = context.Background()
+				ctrl     = gomock.NewController(t)
+				repo     = mock_repository.NewMockClientRepository(ctrl)
+				zones    = mock_repository.NewMockZoneRepository(ctrl)
+				versions = mock_repository.NewMockClientVersionRepository(ctrl)
 			)
 
 			client := &entities.Client{}
-			tCase.setMockOutput(ctx, client, repo)
+			tCase.setMockOutput(ctx, client, repo, versions)
+			zones.EXPECT().List(gomock.Any()).Return([]entities.Zone{}, nil).Times(1)
 
-			useCase := uCase.NewClientUCase(zap.NewExample(), repo)
+			useCase := uCase.NewClientUCase(zap.NewExample(), repo, zones, versions, nil, time.Hour)
 
 			_, err := useCase.Create(ctx, uuid.New(), client)
 
@@ -111,7 +132,7 @@ func TestClientGet(t *testing.T) {
 
 			tCase.setMockOutput(ctx, tCase.id, repo)
 
-			useCase := uCase.NewClientUCase(zap.NewExample(), repo)
+			useCase := uCase.NewClientUCase(zap.NewExample(), repo, nil, nil, nil, time.Hour)
 			_, err := useCase.Get(ctx, uuid.New(), tCase.id)
 
 			if tCase.expErr != nil {
@@ -130,48 +151,93 @@ func TestClientUpdate(t *testing.T) {
 		name          string
 		id            string
 		expErr        error
-		setMockOutput func(context.Context, string, *mock_repository.MockClientRepository)
+		setMockOutput func(
+			context.Context, string, *mock_repository.MockClientRepository, *mock_repository.MockClientVersionRepository,
+		)
 	}{
 		{
 			name:   "successfully updating",
 			id:     "123",
 			expErr: nil,
-			setMockOutput: func(ctx context.Context, id string, repo *mock_repository.MockClientRepository) {
+			setMockOutput: func(
+				ctx context.Context,
+				id string,
+				repo *mock_repository.MockClientRepository,
+				versions *mock_repository.MockClientVersionRepository,
+			) {
 				ctx, _ = otel.GetTracerProvider().Tracer("uCase.Client").Start(ctx, "uCase.Client.Update")
-				repo.EXPECT().Update(ctx, id, &entities.Client{}).Return(nil).Times(1)
+				repo.EXPECT().Update(ctx, id, int64(0), &entities.Client{ZoneIDs: []primitive.ObjectID{}}).
+					Return(entities.Client{FullName: "old"}, entities.Client{FullName: "new"}, nil).Times(1)
+				versions.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(
+					func(_ context.Context, version *entities.ClientVersion) (string, error) {
+						require.Equal(t, entities.ClientUpdated, version.Operation)
+						require.Equal(t, []entities.AuditChange{{Field: "fullName", Before: "old", After: "new"}}, version.Changes)
+						return "", nil
+					},
+				).Times(1)
 			},
 		},
 		{
 			name:   "db client disconnect",
 			id:     "123",
 			expErr: errors.New("can't update the client: client is disconnected"),
-			setMockOutput: func(ctx context.Context, id string, repo *mock_repository.MockClientRepository) {
+			setMockOutput: func(
+				ctx context.Context,
+				id string,
+				repo *mock_repository.MockClientRepository,
+				versions *mock_repository.MockClientVersionRepository,
+			) {
+				ctx, _ = otel.GetTracerProvider().Tracer("uCase.Client").Start(ctx, "uCase.Client.Update")
+				repo.EXPECT().Update(ctx, id, int64(0), &entities.Client{ZoneIDs: []primitive.ObjectID{}}).
+					Return(entities.Client{}, entities.Client{}, mongo.ErrClientDisconnected).Times(1)
+			},
+		},
+		{
+			name:   "changed by another operator",
+			id:     "123",
+			expErr: errors.New("can't update the client: the client has been changed"),
+			setMockOutput: func(
+				ctx context.Context,
+				id string,
+				repo *mock_repository.MockClientRepository,
+				versions *mock_repository.MockClientVersionRepository,
+			) {
 				ctx, _ = otel.GetTracerProvider().Tracer("uCase.Client").Start(ctx, "uCase.Client.Update")
-				repo.EXPECT().Update(ctx, id, &entities.Client{}).Return(mongo.ErrClientDisconnected).Times(1)
+				repo.EXPECT().Update(ctx, id, int64(0), &entities.Client{ZoneIDs: []primitive.ObjectID{}}).
+					Return(entities.Client{}, entities.Client{}, repository.ErrClientVersionMismatch).Times(1)
 			},
 		},
 		{
 			name:   "client not found",
 			id:     "12",
 			expErr: errors.New("can't update the client: the client is not found"),
-			setMockOutput: func(ctx context.Context, id string, repo *mock_repository.MockClientRepository) {
+			setMockOutput: func(
+				ctx context.Context,
+				id string,
+				repo *mock_repository.MockClientRepository,
+				versions *mock_repository.MockClientVersionRepository,
+			) {
 				ctx, _ = otel.GetTracerProvider().Tracer("uCase.Client").Start(ctx, "uCase.Client.Update")
-				repo.EXPECT().Update(ctx, id, &entities.Client{}).Return(repository.ErrClientNotFound).Times(1)
+				repo.EXPECT().Update(ctx, id, int64(0), &entities.Client{ZoneIDs: []primitive.ObjectID{}}).
+					Return(entities.Client{}, entities.Client{}, repository.ErrClientNotFound).Times(1)
 			},
 		},
 	}
 	for _, tCase := range testTable {
 		t.Run(tCase.name, func(t *testing.T) {
 			var (
-				ctx  = context.Background()
-				ctrl = gomock.NewController(t)
-				repo = mock_repository.NewMockClientRepository(ctrl)
+				ctx      = context.Background()
+				ctrl     = gomock.NewController(t)
+				repo     = mock_repository.NewMockClientRepository(ctrl)
+				zones    = mock_repository.NewMockZoneRepository(ctrl)
+				versions = mock_repository.NewMockClientVersionRepository(ctrl)
 			)
 
-			tCase.setMockOutput(ctx, tCase.id, repo)
+			tCase.setMockOutput(ctx, tCase.id, repo, versions)
+			zones.EXPECT().List(gomock.Any()).Return([]entities.Zone{}, nil).MaxTimes(1)
 
-			useCase := uCase.NewClientUCase(zap.NewExample(), repo)
-			err := useCase.Update(ctx, uuid.New(), tCase.id, &entities.Client{})
+			useCase := uCase.NewClientUCase(zap.NewExample(), repo, zones, versions, nil, time.Hour)
+			_, err := useCase.Update(ctx, uuid.New(), tCase.id, 0, &entities.Client{})
 
 			if tCase.expErr != nil {
 				require.Equal(t, err.Error(), tCase.expErr.Error())
@@ -189,48 +255,71 @@ func TestClientDelete(t *testing.T) {
 		name          string
 		id            string
 		expErr        error
-		setMockOutput func(context.Context, string, *mock_repository.MockClientRepository)
+		setMockOutput func(
+			context.Context, string, *mock_repository.MockClientRepository, *mock_repository.MockClientVersionRepository,
+		)
 	}{
 		{
-			name:   "successfully updating",
+			name:   "successfully deleting",
 			id:     "123",
 			expErr: nil,
-			setMockOutput: func(ctx context.Context, id string, repo *mock_repository.MockClientRepository) {
+			setMockOutput: func(
+				ctx context.Context,
+				id string,
+				repo *mock_repository.MockClientRepository,
+				versions *mock_repository.MockClientVersionRepository,
+			) {
 				ctx, _ = otel.GetTracerProvider().Tracer("uCase.Client").Start(ctx, "uCase.Client.Delete")
-				repo.EXPECT().Delete(ctx, id).Return(nil).Times(1)
+				deletedAt := time.Now()
+				repo.EXPECT().Delete(ctx, id, int64(0)).
+					Return(entities.Client{}, entities.Client{DeletedAt: &deletedAt}, nil).Times(1)
+				versions.EXPECT().Create(ctx, gomock.Any()).Return("", nil).Times(1)
 			},
 		},
 		{
 			name:   "db client disconnect",
 			id:     "123",
 			expErr: errors.New("can't delete the client: client is disconnected"),
-			setMockOutput: func(ctx context.Context, id string, repo *mock_repository.MockClientRepository) {
+			setMockOutput: func(
+				ctx context.Context,
+				id string,
+				repo *mock_repository.MockClientRepository,
+				versions *mock_repository.MockClientVersionRepository,
+			) {
 				ctx, _ = otel.GetTracerProvider().Tracer("uCase.Client").Start(ctx, "uCase.Client.Delete")
-				repo.EXPECT().Delete(ctx, id).Return(mongo.ErrClientDisconnected).Times(1)
+				repo.EXPECT().Delete(ctx, id, int64(0)).
+					Return(entities.Client{}, entities.Client{}, mongo.ErrClientDisconnected).Times(1)
 			},
 		},
 		{
 			name:   "client not found",
 			id:     "12",
 			expErr: errors.New("can't delete the client: the client is not found"),
-			setMockOutput: func(ctx context.Context, id string, repo *mock_repository.MockClientRepository) {
+			setMockOutput: func(
+				ctx context.Context,
+				id string,
+				repo *mock_repository.MockClientRepository,
+				versions *mock_repository.MockClientVersionRepository,
+			) {
 				ctx, _ = otel.GetTracerProvider().Tracer("uCase.Client").Start(ctx, "uCase.Client.Delete")
-				repo.EXPECT().Delete(ctx, id).Return(repository.ErrClientNotFound).Times(1)
+				repo.EXPECT().Delete(ctx, id, int64(0)).
+					Return(entities.Client{}, entities.Client{}, repository.ErrClientNotFound).Times(1)
 			},
 		},
 	}
 	for _, tCase := range testTable {
 		t.Run(tCase.name, func(t *testing.T) {
 			var (
-				ctx  = context.Background()
-				ctrl = gomock.NewController(t)
-				repo = mock_repository.NewMockClientRepository(ctrl)
+				ctx      = context.Background()
+				ctrl     = gomock.NewController(t)
+				repo     = mock_repository.NewMockClientRepository(ctrl)
+				versions = mock_repository.NewMockClientVersionRepository(ctrl)
 			)
 
-			tCase.setMockOutput(ctx, tCase.id, repo)
+			tCase.setMockOutput(ctx, tCase.id, repo, versions)
 
-			useCase := uCase.NewClientUCase(zap.NewExample(), repo)
-			err := useCase.Delete(ctx, uuid.New(), tCase.id)
+			useCase := uCase.NewClientUCase(zap.NewExample(), repo, nil, versions, nil, time.Hour)
+			err := useCase.Delete(ctx, uuid.New(), tCase.id, 0)
 
 			if tCase.expErr != nil {
 				require.Equal(t, err.Error(), tCase.expErr.Error())
@@ -242,3 +331,276 @@ func TestClientDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestClientRestore(t *testing.T) {
+	var (
+		ctrl      = gomock.NewController(t)
+		repo      = mock_repository.NewMockClientRepository(ctrl)
+		versions  = mock_repository.NewMockClientVersionRepository(ctrl)
+		deletedAt = time.Now()
+		deleted   = entities.Client{ID: primitive.NewObjectID(), FullName: "John Doe", DeletedAt: &deletedAt}
+		live      = entities.Client{ID: primitive.NewObjectID()}
+	)
+	defer ctrl.Finish()
+
+	repo.EXPECT().GetWithDeleted(gomock.Any(), deleted.ID.Hex()).Return(deleted, nil).Times(1)
+	repo.EXPECT().Restore(gomock.Any(), deleted.ID).Return(nil).Times(1)
+	versions.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, version *entities.ClientVersion) (string, error) {
+			require.Equal(t, entities.ClientRestored, version.Operation)
+			require.Nil(t, version.Client.DeletedAt)
+			return "", nil
+		},
+	).Times(1)
+	repo.EXPECT().GetWithDeleted(gomock.Any(), live.ID.Hex()).Return(live, nil).Times(1)
+
+	useCase := uCase.NewClientUCase(zap.NewNop(), repo, nil, versions, nil, time.Hour)
+
+	restored, err := useCase.Restore(context.Background(), uuid.New(), deleted.ID.Hex())
+	require.NoError(t, err)
+	require.Nil(t, restored.DeletedAt)
+	require.Equal(t, "John Doe", restored.FullName)
+
+	_, err = useCase.Restore(context.Background(), uuid.New(), live.ID.Hex())
+	require.ErrorIs(t, err, repository.ErrClientNotDeleted)
+}
+
+func TestClientHistory(t *testing.T) {
+	var (
+		ctrl      = gomock.NewController(t)
+		repo      = mock_repository.NewMockClientRepository(ctrl)
+		versions  = mock_repository.NewMockClientVersionRepository(ctrl)
+		deletedAt = time.Now()
+		client    = entities.Client{ID: primitive.NewObjectID(), DeletedAt: &deletedAt}
+		history   = []entities.ClientVersion{
+			{ClientID: client.ID, Operation: entities.ClientCreated},
+			{ClientID: client.ID, Operation: entities.ClientDeleted},
+		}
+	)
+	defer ctrl.Finish()
+
+	repo.EXPECT().GetWithDeleted(gomock.Any(), client.ID.Hex()).Return(client, nil).Times(1)
+	versions.EXPECT().List(gomock.Any(), client.ID).Return(history, nil).Times(1)
+
+	useCase := uCase.NewClientUCase(zap.NewNop(), repo, nil, versions, nil, time.Hour)
+
+	got, err := useCase.History(context.Background(), uuid.New(), client.ID.Hex())
+	require.NoError(t, err)
+	require.Equal(t, history, got)
+}
+
+func TestClientPurge(t *testing.T) {
+	var (
+		ctrl     = gomock.NewController(t)
+		repo     = mock_repository.NewMockClientRepository(ctrl)
+		versions = mock_repository.NewMockClientVersionRepository(ctrl)
+		purged   = []primitive.ObjectID{primitive.NewObjectID()}
+		grace    = 24 * time.Hour
+	)
+	defer ctrl.Finish()
+
+	repo.EXPECT().Purge(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, before time.Time) ([]primitive.ObjectID, error) {
+			require.WithinDuration(t, time.Now().Add(-grace), before, time.Minute)
+			return purged, nil
+		},
+	).Times(1)
+	versions.EXPECT().DeleteForClients(gomock.Any(), purged).Return(int64(2), nil).Times(1)
+
+	useCase := uCase.NewClientUCase(zap.NewNop(), repo, nil, versions, nil, grace)
+	require.NoError(t, useCase.Purge(context.Background()))
+}
+
+func TestClientPatch(t *testing.T) {
+	var (
+		ctrl     = gomock.NewController(t)
+		repo     = mock_repository.NewMockClientRepository(ctrl)
+		zones    = mock_repository.NewMockZoneRepository(ctrl)
+		versions = mock_repository.NewMockClientVersionRepository(ctrl)
+		zero     = 0.0
+		name     = "Jane Doe"
+		client   = entities.Client{
+			ID: primitive.NewObjectID(), FullName: "John Doe", LocationName: "station", Latitude: 55, Longitude: 37, Version: 3,
+		}
+		patch = entities.ClientPatch{Latitude: &zero, FullName: &name}
+	)
+	defer ctrl.Finish()
+
+	patched := client
+	patched.Latitude, patched.FullName, patched.Version = 0, name, 4
+
+	gomock.InOrder(
+		repo.EXPECT().Get(gomock.Any(), client.ID.Hex()).Return(client, nil),
+		repo.EXPECT().Patch(gomock.Any(), client.ID.Hex(), int64(3), gomock.Any()).DoAndReturn(
+			func(
+				_ context.Context, _ string, _ int64, patch entities.ClientPatch,
+			) (entities.Client, entities.Client, error) {
+				require.Equal(t, 0.0, *patch.Latitude)
+				require.Nil(t, patch.Longitude)
+				require.Nil(t, patch.LocationName)
+				require.Equal(t, name, *patch.FullName)
+				require.Equal(t, []primitive.ObjectID{}, patch.ZoneIDs)
+				return client, patched, nil
+			},
+		),
+	)
+	zones.EXPECT().List(gomock.Any()).Return([]entities.Zone{}, nil).Times(1)
+	versions.EXPECT().Create(gomock.Any(), gomock.Any()).Return("", nil).Times(1)
+
+	useCase := uCase.NewClientUCase(zap.NewNop(), repo, zones, versions, nil, time.Hour)
+
+	got, err := useCase.Patch(context.Background(), uuid.New(), client.ID.Hex(), 3, patch)
+	require.NoError(t, err)
+	require.Equal(t, patched, got)
+}
+
+func TestClientPatchInvalid(t *testing.T) {
+	var (
+		ctrl   = gomock.NewController(t)
+		repo   = mock_repository.NewMockClientRepository(ctrl)
+		far    = 91.0
+		client = entities.Client{ID: primitive.NewObjectID(), Version: 1}
+	)
+	defer ctrl.Finish()
+
+	repo.EXPECT().Get(gomock.Any(), client.ID.Hex()).Return(client, nil).Times(2)
+
+	useCase := uCase.NewClientUCase(zap.NewNop(), repo, nil, nil, nil, time.Hour)
+
+	_, err := useCase.Patch(
+		context.Background(), uuid.New(), client.ID.Hex(), 1, entities.ClientPatch{Latitude: &far},
+	)
+	require.ErrorIs(t, err, uCase.ErrInvalidClient)
+
+	_, err = useCase.Patch(context.Background(), uuid.New(), client.ID.Hex(), 0, entities.ClientPatch{})
+	require.ErrorIs(t, err, repository.ErrClientVersionMismatch)
+}
+
+func TestClientPatchEmpty(t *testing.T) {
+	var (
+		ctrl   = gomock.NewController(t)
+		repo   = mock_repository.NewMockClientRepository(ctrl)
+		client = entities.Client{ID: primitive.NewObjectID(), FullName: "John Doe", Version: 2}
+	)
+	defer ctrl.Finish()
+
+	// neither Patch of the repo nor a new version is expected
+	repo.EXPECT().Get(gomock.Any(), client.ID.Hex()).Return(client, nil).Times(1)
+
+	useCase := uCase.NewClientUCase(zap.NewNop(), repo, nil, nil, nil, time.Hour)
+
+	got, err := useCase.Patch(context.Background(), uuid.New(), client.ID.Hex(), 2, entities.ClientPatch{})
+	require.NoError(t, err)
+	require.Equal(t, client, got)
+}
+
+func TestClientImport(t *testing.T) {
+	const input = "fullName,locationName,latitude,longitude\n" +
+		"North,station,55.75,37.61\n" +
+		"Null island,sea,0,0\n"
+
+	testTable := []struct {
+		name   string
+		input  string
+		dryRun bool
+		expIDs int
+		expErr []entities.ImportError
+	}{
+		{name: "import", input: input, expIDs: 2},
+		{name: "dry run", input: input, dryRun: true},
+		{
+			name:  "invalid rows",
+			input: input + ",nowhere,1,1\nSouth,station,-91,0\n",
+			expErr: []entities.ImportError{
+				{Row: 3, Error: "the client is invalid: 'fullName' is required"},
+				{Row: 4, Error: "the client is invalid: latitude must be within [-90, 90]"},
+			},
+		},
+	}
+
+	for _, tCase := range testTable {
+		t.Run(tCase.name, func(t *testing.T) {
+			var (
+				ctrl     = gomock.NewController(t)
+				repo     = mock_repository.NewMockClientRepository(ctrl)
+				zones    = mock_repository.NewMockZoneRepository(ctrl)
+				versions = mock_repository.NewMockClientVersionRepository(ctrl)
+			)
+			defer ctrl.Finish()
+
+			if tCase.expIDs > 0 {
+				zones.EXPECT().List(gomock.Any()).Return([]entities.Zone{}, nil).Times(1)
+				repo.EXPECT().CreateMany(gomock.Any(), gomock.Len(tCase.expIDs)).DoAndReturn(
+					func(_ context.Context, clients []*entities.Client) ([]string, error) {
+						require.Equal(t, 0.0, clients[1].Latitude)
+						ids := make([]string, 0, len(clients))
+						for _, client := range clients {
+							client.ID = primitive.NewObjectID()
+							ids = append(ids, client.ID.Hex())
+						}
+						return ids, nil
+					},
+				).Times(1)
+				versions.EXPECT().Create(gomock.Any(), gomock.Any()).Return("", nil).Times(tCase.expIDs)
+			}
+
+			useCase := uCase.NewClientUCase(zap.NewNop(), repo, zones, versions, nil, time.Hour)
+
+			result, err := useCase.Import(
+				context.Background(), uuid.New(), clientio.FormatCSV, strings.NewReader(tCase.input), tCase.dryRun,
+			)
+			require.NoError(t, err)
+			require.Equal(t, tCase.dryRun, result.DryRun)
+			require.Len(t, result.Created, tCase.expIDs)
+			for i, created := range result.Created {
+				require.Equal(t, i+1, created.Row)
+				require.NotEmpty(t, created.ClientID)
+			}
+
+			if tCase.expErr == nil {
+				require.Empty(t, result.Errors)
+			} else {
+				require.Equal(t, tCase.expErr, result.Errors)
+			}
+		})
+	}
+}
+
+// TestClientImportPartialFailure checks that clients created before the failed one are audited and versioned
+func TestClientImportPartialFailure(t *testing.T) {
+	const input = "fullName,locationName,latitude,longitude\n" +
+		"North,station,55.75,37.61\n" +
+		"South,station,-55.75,37.61\n"
+
+	var (
+		ctrl     = gomock.NewController(t)
+		repo     = mock_repository.NewMockClientRepository(ctrl)
+		zones    = mock_repository.NewMockZoneRepository(ctrl)
+		versions = mock_repository.NewMockClientVersionRepository(ctrl)
+		entry    = &entities.AuditEntry{}
+		created  = primitive.NewObjectID()
+	)
+	defer ctrl.Finish()
+
+	zones.EXPECT().List(gomock.Any()).Return([]entities.Zone{}, nil).Times(1)
+	repo.EXPECT().CreateMany(gomock.Any(), gomock.Len(2)).DoAndReturn(
+		func(_ context.Context, clients []*entities.Client) ([]string, error) {
+			clients[0].ID = created
+			return []string{created.Hex()}, errors.New("duplicate key")
+		},
+	).Times(1)
+	versions.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, version *entities.ClientVersion) (string, error) {
+			require.Equal(t, created, version.ClientID)
+			return "", nil
+		},
+	).Times(1)
+
+	useCase := uCase.NewClientUCase(zap.NewNop(), repo, zones, versions, nil, time.Hour)
+
+	_, err := useCase.Import(
+		audit.WithEntry(context.Background(), entry), uuid.New(), clientio.FormatCSV, strings.NewReader(input), false,
+	)
+	require.ErrorContains(t, err, "1 of 2 are created")
+	require.Contains(t, entry.Changes, entities.AuditChange{Field: created.Hex() + ".fullName", After: "North"})
+}
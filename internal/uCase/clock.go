@@ -0,0 +1,142 @@
+package uCase
+
+import (
+	"context"
+	"fmt"
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"math"
+	"time"
+)
+
+var (
+	ErrInvalidClockSample = apperr.New(apperr.InvalidArgument, "the clock sample is invalid")
+)
+
+// _clockSmoothing is the weight of the new sample in the running estimate
+const _clockSmoothing = 0.25
+
+type Clock struct {
+	tracer     trace.Tracer
+	clientRepo ClientRepo
+	logger     *zap.Logger
+	maxSkew    time.Duration
+	maxDrift   float64
+}
+
+// NewClockUCase creates the clock synchronization, clients with the offset above maxSkew
+// or the drift above maxDrift (ppm) are flagged as skewed
+func NewClockUCase(logger *zap.Logger, clientRepo ClientRepo, maxSkew time.Duration, maxDrift float64) *Clock {
+	return &Clock{
+		tracer:     otel.Tracer("uCase.Clock"),
+		clientRepo: clientRepo,
+		logger:     logger,
+		maxSkew:    maxSkew,
+		maxDrift:   maxDrift,
+	}
+}
+
+// Sync answers the exchange started by the client at clientSend and received by the server at serverReceive.
+// The previous exchange completed by the client, if passed, is folded into the clock estimate of the client
+func (c Clock) Sync(
+	ctx context.Context,
+	reqID uuid.UUID,
+	clientID string,
+	clientSend, serverReceive time.Time,
+	previous *entities.ClockSample,
+) (entities.ClockSample, entities.ClockState, error) {
+	ctx, span := c.tracer.Start(ctx, "uCase.Clock.Sync")
+	defer span.End()
+
+	client, err := c.clientRepo.Get(ctx, clientID)
+	if err != nil {
+		return entities.ClockSample{}, entities.ClockState{}, errors.Wrap(err, "can't get the client")
+	}
+
+	state := client.Clock
+	if previous != nil {
+		if err := validateClockSample(*previous); err != nil {
+			return entities.ClockSample{}, entities.ClockState{}, err
+		}
+
+		state = estimateClock(state, *previous)
+		state.Skewed = c.skewed(state)
+
+		if err := c.clientRepo.SetClock(ctx, client.ID, state); err != nil {
+			return entities.ClockSample{}, entities.ClockState{}, errors.Wrap(err, "can't save clock of the client")
+		}
+
+		if state.Skewed && !client.Clock.Skewed {
+			c.logger.Warn(
+				"the client clock is skewed",
+				zap.String("reqID", reqID.String()),
+				zap.String("clientID", clientID),
+				zap.Duration("offset", state.Offset),
+				zap.Float64("drift", state.Drift),
+			)
+		}
+	}
+
+	answer := entities.ClockSample{
+		ClientSend:    clientSend,
+		ServerReceive: serverReceive,
+		ServerSend:    time.Now().UTC(),
+	}
+
+	return answer, state, nil
+}
+
+func (c Clock) skewed(state entities.ClockState) bool {
+	offset := state.Offset
+	if offset < 0 {
+		offset = -offset
+	}
+
+	return offset > c.maxSkew || math.Abs(state.Drift) > c.maxDrift
+}
+
+func validateClockSample(sample entities.ClockSample) error {
+	if sample.ClientSend.IsZero() || sample.ServerReceive.IsZero() ||
+		sample.ServerSend.IsZero() || sample.ClientReceive.IsZero() {
+		return fmt.Errorf("%w: all four times must be set", ErrInvalidClockSample)
+	}
+
+	if sample.ServerSend.Before(sample.ServerReceive) || sample.Delay() < 0 {
+		return fmt.Errorf("%w: times are out of order", ErrInvalidClockSample)
+	}
+
+	return nil
+}
+
+// estimateClock smooths the offset and its drift, samples older than the estimate are ignored
+func estimateClock(state entities.ClockState, sample entities.ClockSample) entities.ClockState {
+	at := sample.ServerReceive
+	if state.Samples > 0 && !at.After(state.UpdatedAt) {
+		return state
+	}
+
+	next := entities.ClockState{
+		Offset:    sample.Offset(),
+		Drift:     state.Drift,
+		Delay:     sample.Delay(),
+		Samples:   state.Samples + 1,
+		UpdatedAt: at,
+	}
+
+	if state.Samples == 0 {
+		return next
+	}
+
+	observed := float64(sample.Offset()-state.Offset) / float64(at.Sub(state.UpdatedAt)) * 1e6
+	next.Drift = state.Drift + _clockSmoothing*(observed-state.Drift)
+
+	predicted := state.OffsetAt(at)
+	next.Offset = predicted + time.Duration(_clockSmoothing*float64(sample.Offset()-predicted))
+
+	return next
+}
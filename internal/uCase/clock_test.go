@@ -0,0 +1,162 @@
+package uCase_test
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository"
+	mock_repository "github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository/mocks"
+	"github.com/Imm0bilize/gunshot-api-service/internal/uCase"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+// exchange builds the sample of the client which clock is behind the server by offset, the network delay is symmetric
+func exchange(serverReceive time.Time, offset, delay time.Duration) entities.ClockSample {
+	return entities.ClockSample{
+		ClientSend:    serverReceive.Add(-offset - delay/2),
+		ServerReceive: serverReceive,
+		ServerSend:    serverReceive.Add(time.Millisecond),
+		ClientReceive: serverReceive.Add(time.Millisecond - offset + delay/2),
+	}
+}
+
+func TestClockSample(t *testing.T) {
+	sample := exchange(time.Now(), 3*time.Second, 40*time.Millisecond)
+
+	require.Equal(t, 3*time.Second, sample.Offset())
+	require.Equal(t, 40*time.Millisecond, sample.Delay())
+}
+
+func TestClockSync(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		start  = time.Date(2022, 12, 1, 22, 0, 0, 0, time.UTC)
+		client = entities.Client{ID: primitive.NewObjectID()}
+	)
+
+	clients := mock_repository.NewMockClientRepository(ctrl)
+	clients.EXPECT().Get(gomock.Any(), client.ID.Hex()).DoAndReturn(
+		func(context.Context, string) (entities.Client, error) { return client, nil },
+	).AnyTimes()
+	clients.EXPECT().SetClock(gomock.Any(), client.ID, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ primitive.ObjectID, clock entities.ClockState) error {
+			client.Clock = clock
+			return nil
+		},
+	).AnyTimes()
+
+	clock := uCase.NewClockUCase(zap.NewNop(), clients, 2*time.Second, 200)
+
+	// the first exchange has nothing to estimate
+	_, state, err := clock.Sync(context.Background(), uuid.New(), client.ID.Hex(), start, start, nil)
+	require.NoError(t, err)
+	require.Zero(t, state.Samples)
+
+	// the offset is 500ms and grows by 1ms every 10 seconds (100 ppm)
+	for i := 0; i < 20; i++ {
+		at := start.Add(time.Duration(i) * 10 * time.Second)
+		previous := exchange(at, 500*time.Millisecond+time.Duration(i)*time.Millisecond, 20*time.Millisecond)
+
+		_, state, err = clock.Sync(context.Background(), uuid.New(), client.ID.Hex(), at, at, &previous)
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, 20, state.Samples)
+	require.InDelta(t, 519*time.Millisecond, state.Offset, float64(time.Millisecond))
+	require.InDelta(t, 100, state.Drift, 10)
+	require.False(t, state.Skewed)
+
+	// the clock jumped far away
+	at := start.Add(time.Hour)
+	previous := exchange(at, time.Minute, 20*time.Millisecond)
+	for i := 0; i < 10; i++ {
+		previous = exchange(at.Add(time.Duration(i)*time.Second), time.Minute, 20*time.Millisecond)
+
+		_, state, err = clock.Sync(context.Background(), uuid.New(), client.ID.Hex(), at, at, &previous)
+		require.NoError(t, err)
+	}
+	require.True(t, state.Skewed)
+
+	// times out of order are rejected
+	previous.ClientReceive = previous.ClientSend.Add(-time.Second)
+	_, _, err = clock.Sync(context.Background(), uuid.New(), client.ID.Hex(), at, at, &previous)
+	require.ErrorIs(t, err, uCase.ErrInvalidClockSample)
+}
+
+type fakeSender struct {
+	messages []entities.Message
+}
+
+func (f *fakeSender) Send(_ context.Context, _ uuid.UUID, msg entities.Message) error {
+	f.messages = append(f.messages, msg)
+	return nil
+}
+
+func TestAudioUploadCorrectsTimestamp(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		updated = time.Date(2022, 12, 1, 22, 0, 0, 0, time.UTC)
+		raw     = updated.Add(time.Minute)
+		client  = entities.Client{
+			ID: primitive.NewObjectID(),
+			Clock: entities.ClockState{
+				Offset:    2 * time.Second,
+				Drift:     1000,
+				Samples:   5,
+				UpdatedAt: updated,
+			},
+		}
+	)
+
+	clients := mock_repository.NewMockClientRepository(ctrl)
+	clients.EXPECT().Get(gomock.Any(), client.ID.Hex()).Return(client, nil).Times(1)
+	clients.EXPECT().AdvanceChain(gomock.Any(), client.ID, gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	chain := mock_repository.NewMockChainRepository(ctrl)
+	chain.EXPECT().FindUpload(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(entities.ChainRecord{}, repository.ErrChainRecordNotFound).Times(1)
+	chain.EXPECT().Create(gomock.Any(), gomock.Any()).Return("", nil).Times(1)
+
+	sender := &fakeSender{}
+	audio := uCase.NewAudioUCase(zap.NewNop(), sender, clients, chain, newFakeBlobs(), nil, nil, 1000, false)
+
+	err := audio.Upload(context.Background(), uuid.New(), client.ID.Hex(), entities.Message{Timestamp: raw})
+	require.NoError(t, err)
+
+	require.Len(t, sender.messages, 1)
+	require.Equal(t, raw, sender.messages[0].RawTimestamp)
+	// 2s of the offset plus 60ms of the drift during the minute
+	require.Equal(t, raw.Add(2060*time.Millisecond), sender.messages[0].Timestamp)
+	require.Equal(t, client.ID, sender.messages[0].ID)
+}
+
+type fakeBlobs struct {
+	blobs map[string]entities.Blob
+}
+
+func newFakeBlobs() *fakeBlobs {
+	return &fakeBlobs{blobs: make(map[string]entities.Blob)}
+}
+
+func (f *fakeBlobs) Put(_ context.Context, blob *entities.Blob) error {
+	f.blobs[blob.Key] = *blob
+	return nil
+}
+
+func (f *fakeBlobs) Get(_ context.Context, key string) (entities.Blob, error) {
+	blob, ok := f.blobs[key]
+	if !ok {
+		return entities.Blob{}, repository.ErrBlobNotFound
+	}
+
+	return blob, nil
+}
@@ -0,0 +1,304 @@
+package uCase
+
+import (
+	"context"
+	"fmt"
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/Imm0bilize/gunshot-api-service/internal/audit"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+)
+
+type CommandRepo interface {
+	Create(ctx context.Context, command *entities.Command) (string, error)
+	Get(ctx context.Context, clientID, id primitive.ObjectID) (entities.Command, error)
+	List(ctx context.Context, filter entities.CommandFilter) ([]entities.Command, error)
+	Claim(ctx context.Context, clientID primitive.ObjectID, now time.Time) ([]entities.Command, error)
+	Acknowledge(ctx context.Context, clientID, id primitive.ObjectID, at time.Time) (entities.Command, error)
+	Complete(
+		ctx context.Context, clientID, id primitive.ObjectID, result entities.CommandResult, at time.Time,
+	) (entities.Command, error)
+	Redeliver(ctx context.Context, deliveredBefore, now time.Time) (int64, error)
+	Expire(ctx context.Context, now time.Time) (int64, error)
+}
+
+var (
+	ErrInvalidCommand = apperr.New(apperr.InvalidArgument, "the command is invalid")
+)
+
+// _commandPollInterval is how often the waiting client request checks the queue for new commands
+const _commandPollInterval = time.Second
+
+type Command struct {
+	tracer      trace.Tracer
+	commandRepo CommandRepo
+	clientRepo  ClientRepo
+	logger      *zap.Logger
+	ttl         time.Duration
+	ackTimeout  time.Duration
+}
+
+// NewCommandUCase creates the command queue, commands not completed during ttl expire and delivered commands
+// not acknowledged during ackTimeout are delivered again
+func NewCommandUCase(
+	logger *zap.Logger, commandRepo CommandRepo, clientRepo ClientRepo, ttl, ackTimeout time.Duration,
+) *Command {
+	return &Command{
+		tracer:      otel.Tracer("uCase.Command"),
+		commandRepo: commandRepo,
+		clientRepo:  clientRepo,
+		logger:      logger,
+		ttl:         ttl,
+		ackTimeout:  ackTimeout,
+	}
+}
+
+func validateCommand(command entities.Command) error {
+	switch command.Type {
+	case entities.CommandReboot, entities.CommandRecalibrate:
+		return nil
+	case entities.CommandUploadBuffer:
+		if _, err := time.Parse(time.RFC3339, command.Args["around"]); err != nil {
+			return fmt.Errorf("%w: arg 'around' must be RFC 3339 time", ErrInvalidCommand)
+		}
+
+		if seconds, err := strconv.Atoi(command.Args["seconds"]); err != nil || seconds <= 0 {
+			return fmt.Errorf("%w: arg 'seconds' must be a positive number", ErrInvalidCommand)
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown type '%s'", ErrInvalidCommand, command.Type)
+	}
+}
+
+// Enqueue puts the command into the queue of the client, the zero ExpiresAt is replaced by the default TTL
+func (c Command) Enqueue(ctx context.Context, reqID uuid.UUID, clientID string, command *entities.Command) (string, error) {
+	ctx, span := c.tracer.Start(ctx, "uCase.Command.Enqueue")
+	defer span.End()
+
+	if err := validateCommand(*command); err != nil {
+		return "", err
+	}
+
+	client, err := c.clientRepo.Get(ctx, clientID)
+	if err != nil {
+		return "", errors.Wrap(err, "can't get the client")
+	}
+
+	now := time.Now().UTC()
+	if command.ExpiresAt.IsZero() {
+		command.ExpiresAt = now.Add(c.ttl)
+	}
+
+	if !command.ExpiresAt.After(now) {
+		return "", fmt.Errorf("%w: the command is already expired", ErrInvalidCommand)
+	}
+
+	command.ClientID = client.ID
+	command.Status = entities.CommandPending
+	command.CreatedAt = now
+
+	id, err := c.commandRepo.Create(ctx, command)
+	if err != nil {
+		c.logger.Error(
+			"error during enqueue command",
+			zap.String("reqID", reqID.String()),
+			zap.String("clientID", clientID),
+			zap.Error(err),
+		)
+
+		return "", errors.Wrap(err, "can't enqueue the command")
+	}
+
+	audit.SetTargetID(ctx, id)
+	auditChange(ctx, c.logger, reqID, nil, command)
+
+	return id, nil
+}
+
+func (c Command) Get(ctx context.Context, reqID uuid.UUID, clientID, id string) (entities.Command, error) {
+	ctx, span := c.tracer.Start(ctx, "uCase.Command.Get")
+	defer span.End()
+
+	castedClientID, castedID, err := castCommandIDs(clientID, id)
+	if err != nil {
+		return entities.Command{}, err
+	}
+
+	command, err := c.commandRepo.Get(ctx, castedClientID, castedID)
+	if err != nil {
+		return entities.Command{}, errors.Wrap(err, "can't get the command")
+	}
+
+	return command, nil
+}
+
+func (c Command) List(ctx context.Context, reqID uuid.UUID, filter entities.CommandFilter) ([]entities.Command, error) {
+	ctx, span := c.tracer.Start(ctx, "uCase.Command.List")
+	defer span.End()
+
+	commands, err := c.commandRepo.List(ctx, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get commands of the client")
+	}
+
+	return commands, nil
+}
+
+// Next delivers pending commands of the client, if there are none it waits for new ones until the timeout
+func (c Command) Next(
+	ctx context.Context, reqID uuid.UUID, clientID string, timeout time.Duration,
+) ([]entities.Command, error) {
+	ctx, span := c.tracer.Start(ctx, "uCase.Command.Next")
+	defer span.End()
+
+	castedID, err := primitive.ObjectIDFromHex(clientID)
+	if err != nil {
+		return nil, errors.Wrap(err, "error during convert client id")
+	}
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	ticker := time.NewTicker(_commandPollInterval)
+	defer ticker.Stop()
+
+	for {
+		commands, err := c.commandRepo.Claim(ctx, castedID, time.Now().UTC())
+		if err != nil || len(commands) > 0 {
+			return commands, errors.Wrap(err, "can't claim commands of the client")
+		}
+
+		select {
+		case <-ctx.Done():
+			return commands, nil
+		case <-deadline.C:
+			return commands, nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c Command) Acknowledge(ctx context.Context, reqID uuid.UUID, clientID, id string) (entities.Command, error) {
+	ctx, span := c.tracer.Start(ctx, "uCase.Command.Acknowledge")
+	defer span.End()
+
+	castedClientID, castedID, err := castCommandIDs(clientID, id)
+	if err != nil {
+		return entities.Command{}, err
+	}
+
+	// the command is got first to tell the unknown command from the completed one
+	if _, err := c.commandRepo.Get(ctx, castedClientID, castedID); err != nil {
+		return entities.Command{}, errors.Wrap(err, "can't get the command")
+	}
+
+	command, err := c.commandRepo.Acknowledge(ctx, castedClientID, castedID, time.Now().UTC())
+	if err != nil {
+		return entities.Command{}, errors.Wrap(err, "can't acknowledge the command")
+	}
+
+	return command, nil
+}
+
+func (c Command) Complete(
+	ctx context.Context, reqID uuid.UUID, clientID, id string, result entities.CommandResult,
+) (entities.Command, error) {
+	ctx, span := c.tracer.Start(ctx, "uCase.Command.Complete")
+	defer span.End()
+
+	castedClientID, castedID, err := castCommandIDs(clientID, id)
+	if err != nil {
+		return entities.Command{}, err
+	}
+
+	if _, err := c.commandRepo.Get(ctx, castedClientID, castedID); err != nil {
+		return entities.Command{}, errors.Wrap(err, "can't get the command")
+	}
+
+	command, err := c.commandRepo.Complete(ctx, castedClientID, castedID, result, time.Now().UTC())
+	if err != nil {
+		return entities.Command{}, errors.Wrap(err, "can't save result of the command")
+	}
+
+	return command, nil
+}
+
+// Expire marks commands which outlived their TTL as expired
+func (c Command) Expire(ctx context.Context) error {
+	ctx, span := c.tracer.Start(ctx, "uCase.Command.Expire")
+	defer span.End()
+
+	expired, err := c.commandRepo.Expire(ctx, time.Now().UTC())
+	if err != nil {
+		return errors.Wrap(err, "can't expire commands")
+	}
+
+	if expired > 0 {
+		c.logger.Info("commands expired", zap.Int64("count", expired))
+	}
+
+	return nil
+}
+
+// Redeliver returns commands which were delivered but not acknowledged during the ack timeout to the queue
+func (c Command) Redeliver(ctx context.Context) error {
+	ctx, span := c.tracer.Start(ctx, "uCase.Command.Redeliver")
+	defer span.End()
+
+	now := time.Now().UTC()
+
+	redelivered, err := c.commandRepo.Redeliver(ctx, now.Add(-c.ackTimeout), now)
+	if err != nil {
+		return errors.Wrap(err, "can't redeliver commands")
+	}
+
+	if redelivered > 0 {
+		c.logger.Info("not acknowledged commands are returned to the queue", zap.Int64("count", redelivered))
+	}
+
+	return nil
+}
+
+// Monitor expires and redelivers commands with the interval until the context is canceled
+func (c Command) Monitor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.Expire(ctx); err != nil {
+				c.logger.Error("error during expire commands", zap.Error(err))
+			}
+
+			if err := c.Redeliver(ctx); err != nil {
+				c.logger.Error("error during redeliver commands", zap.Error(err))
+			}
+		}
+	}
+}
+
+func castCommandIDs(clientID, id string) (primitive.ObjectID, primitive.ObjectID, error) {
+	castedClientID, err := primitive.ObjectIDFromHex(clientID)
+	if err != nil {
+		return primitive.NilObjectID, primitive.NilObjectID, errors.Wrap(err, "error during convert client id")
+	}
+
+	castedID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return primitive.NilObjectID, primitive.NilObjectID, errors.Wrap(err, "error during convert command id")
+	}
+
+	return castedClientID, castedID, nil
+}
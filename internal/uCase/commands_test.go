@@ -0,0 +1,156 @@
+package uCase_test
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository"
+	mock_repository "github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository/mocks"
+	"github.com/Imm0bilize/gunshot-api-service/internal/uCase"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+func TestCommandEnqueue(t *testing.T) {
+	client := entities.Client{ID: primitive.NewObjectID()}
+
+	testTable := []struct {
+		name    string
+		command entities.Command
+		saved   bool
+		expErr  error
+	}{
+		{
+			name:    "reboot",
+			command: entities.Command{Type: entities.CommandReboot},
+			saved:   true,
+		},
+		{
+			name: "upload buffer",
+			command: entities.Command{
+				Type: entities.CommandUploadBuffer,
+				Args: map[string]string{"around": "2022-12-01T22:00:00Z", "seconds": "30"},
+			},
+			saved: true,
+		},
+		{
+			name: "upload buffer without the time",
+			command: entities.Command{
+				Type: entities.CommandUploadBuffer,
+				Args: map[string]string{"seconds": "30"},
+			},
+			expErr: uCase.ErrInvalidCommand,
+		},
+		{
+			name:    "unknown type",
+			command: entities.Command{Type: "selfdestruct"},
+			expErr:  uCase.ErrInvalidCommand,
+		},
+		{
+			name: "expired",
+			command: entities.Command{
+				Type:      entities.CommandReboot,
+				ExpiresAt: time.Now().Add(-time.Minute),
+			},
+			expErr: uCase.ErrInvalidCommand,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			clients := mock_repository.NewMockClientRepository(ctrl)
+			clients.EXPECT().Get(gomock.Any(), client.ID.Hex()).Return(client, nil).AnyTimes()
+
+			commands := mock_repository.NewMockCommandRepository(ctrl)
+			if testCase.saved {
+				commands.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, command *entities.Command) (string, error) {
+						require.Equal(t, client.ID, command.ClientID)
+						require.Equal(t, entities.CommandPending, command.Status)
+						require.WithinDuration(t, time.Now().Add(10*time.Minute), command.ExpiresAt, time.Second)
+						return primitive.NewObjectID().Hex(), nil
+					}).Times(1)
+			}
+
+			command := uCase.NewCommandUCase(zap.NewNop(), commands, clients, 10*time.Minute, time.Minute)
+
+			_, err := command.Enqueue(context.Background(), uuid.New(), client.ID.Hex(), &testCase.command)
+			if testCase.expErr != nil {
+				require.ErrorIs(t, err, testCase.expErr)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCommandNext(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		clientID = primitive.NewObjectID()
+		pending  = entities.Command{ID: primitive.NewObjectID(), ClientID: clientID, Type: entities.CommandReboot}
+	)
+
+	commands := mock_repository.NewMockCommandRepository(ctrl)
+	gomock.InOrder(
+		commands.EXPECT().Claim(gomock.Any(), clientID, gomock.Any()).Return([]entities.Command{}, nil).Times(1),
+		commands.EXPECT().Claim(gomock.Any(), clientID, gomock.Any()).Return([]entities.Command{pending}, nil).Times(1),
+	)
+
+	command := uCase.NewCommandUCase(
+		zap.NewNop(), commands, mock_repository.NewMockClientRepository(ctrl), time.Minute, time.Minute,
+	)
+
+	delivered, err := command.Next(context.Background(), uuid.New(), clientID.Hex(), 5*time.Second)
+	require.NoError(t, err)
+	require.Equal(t, []entities.Command{pending}, delivered)
+}
+
+func TestCommandAcknowledgeUnknown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		clientID  = primitive.NewObjectID()
+		commandID = primitive.NewObjectID()
+	)
+
+	commands := mock_repository.NewMockCommandRepository(ctrl)
+	commands.EXPECT().Get(gomock.Any(), clientID, commandID).Return(entities.Command{}, repository.ErrCommandNotFound).Times(1)
+
+	command := uCase.NewCommandUCase(
+		zap.NewNop(), commands, mock_repository.NewMockClientRepository(ctrl), time.Minute, time.Minute,
+	)
+
+	_, err := command.Acknowledge(context.Background(), uuid.New(), clientID.Hex(), commandID.Hex())
+	require.ErrorIs(t, err, repository.ErrCommandNotFound)
+}
+
+func TestCommandRedeliver(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	commands := mock_repository.NewMockCommandRepository(ctrl)
+	commands.EXPECT().Redeliver(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, deliveredBefore, now time.Time) (int64, error) {
+			require.Equal(t, 2*time.Minute, now.Sub(deliveredBefore))
+			return 1, nil
+		},
+	).Times(1)
+
+	command := uCase.NewCommandUCase(
+		zap.NewNop(), commands, mock_repository.NewMockClientRepository(ctrl), time.Minute, 2*time.Minute,
+	)
+
+	require.NoError(t, command.Redeliver(context.Background()))
+}
@@ -0,0 +1,217 @@
+package uCase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+)
+
+type ConfigRepo interface {
+	Save(
+		ctx context.Context, scope entities.ConfigScope, ownerID primitive.ObjectID, config entities.SensorConfig,
+	) (entities.ConfigDocument, error)
+	Get(ctx context.Context, scope entities.ConfigScope, ownerID primitive.ObjectID) (entities.ConfigDocument, error)
+	FindForClient(
+		ctx context.Context, clientID primitive.ObjectID, zoneIDs []primitive.ObjectID,
+	) ([]entities.ConfigDocument, error)
+}
+
+// _configPollInterval is how often the waiting client request checks the config for changes
+const _configPollInterval = time.Second
+
+type Config struct {
+	tracer     trace.Tracer
+	configRepo ConfigRepo
+	clientRepo ClientRepo
+	zoneRepo   ZoneRepo
+	logger     *zap.Logger
+}
+
+func NewConfigUCase(logger *zap.Logger, configRepo ConfigRepo, clientRepo ClientRepo, zoneRepo ZoneRepo) *Config {
+	return &Config{
+		tracer:     otel.Tracer("uCase.Config"),
+		configRepo: configRepo,
+		clientRepo: clientRepo,
+		zoneRepo:   zoneRepo,
+		logger:     logger,
+	}
+}
+
+func (c Config) SetClientConfig(
+	ctx context.Context, reqID uuid.UUID, clientID string, config entities.SensorConfig,
+) (entities.ConfigDocument, error) {
+	ctx, span := c.tracer.Start(ctx, "uCase.Config.SetClientConfig")
+	defer span.End()
+
+	client, err := c.clientRepo.Get(ctx, clientID)
+	if err != nil {
+		return entities.ConfigDocument{}, errors.Wrap(err, "can't get the client")
+	}
+
+	document, err := c.configRepo.Save(ctx, entities.ConfigScopeClient, client.ID, config)
+	if err != nil {
+		return entities.ConfigDocument{}, errors.Wrap(err, "can't save config of the client")
+	}
+
+	return document, nil
+}
+
+func (c Config) SetZoneConfig(
+	ctx context.Context, reqID uuid.UUID, zoneID string, config entities.SensorConfig,
+) (entities.ConfigDocument, error) {
+	ctx, span := c.tracer.Start(ctx, "uCase.Config.SetZoneConfig")
+	defer span.End()
+
+	zone, err := c.zoneRepo.Get(ctx, zoneID)
+	if err != nil {
+		return entities.ConfigDocument{}, errors.Wrap(err, "can't get the zone")
+	}
+
+	document, err := c.configRepo.Save(ctx, entities.ConfigScopeZone, zone.ID, config)
+	if err != nil {
+		return entities.ConfigDocument{}, errors.Wrap(err, "can't save config of the zone")
+	}
+
+	return document, nil
+}
+
+func (c Config) GetZoneConfig(ctx context.Context, reqID uuid.UUID, zoneID string) (entities.ConfigDocument, error) {
+	ctx, span := c.tracer.Start(ctx, "uCase.Config.GetZoneConfig")
+	defer span.End()
+
+	// configs are not scoped by tenants, so the zone is checked first
+	zone, err := c.zoneRepo.Get(ctx, zoneID)
+	if err != nil {
+		return entities.ConfigDocument{}, errors.Wrap(err, "can't get the zone")
+	}
+
+	document, err := c.configRepo.Get(ctx, entities.ConfigScopeZone, zone.ID)
+	if err != nil {
+		return entities.ConfigDocument{}, errors.Wrap(err, "can't get config of the zone")
+	}
+
+	return document, nil
+}
+
+// Effective merges configs of zones of the client in order of its zones and puts the client override on top.
+// The version is the digest of the merged config, so it changes only when the config the client must apply does
+func (c Config) Effective(ctx context.Context, reqID uuid.UUID, clientID string) (entities.EffectiveConfig, error) {
+	ctx, span := c.tracer.Start(ctx, "uCase.Config.Effective")
+	defer span.End()
+
+	client, err := c.clientRepo.Get(ctx, clientID)
+	if err != nil {
+		return entities.EffectiveConfig{}, errors.Wrap(err, "can't get the client")
+	}
+
+	documents, err := c.configRepo.FindForClient(ctx, client.ID, client.ZoneIDs)
+	if err != nil {
+		return entities.EffectiveConfig{}, errors.Wrap(err, "can't get configs of the client")
+	}
+
+	var (
+		zones    = make(map[primitive.ObjectID]entities.ConfigDocument, len(documents))
+		override *entities.ConfigDocument
+	)
+
+	for i, document := range documents {
+		if document.Scope == entities.ConfigScopeClient {
+			override = &documents[i]
+			continue
+		}
+		zones[document.OwnerID] = document
+	}
+
+	effective := entities.EffectiveConfig{ClientID: client.ID, AppliedVersion: client.AppliedConfig}
+	for _, zoneID := range client.ZoneIDs {
+		if document, ok := zones[zoneID]; ok {
+			effective.Config = effective.Config.Merge(document.Config)
+		}
+	}
+
+	if override != nil {
+		effective.Config = effective.Config.Merge(override.Config)
+	}
+
+	effective.Version, err = configVersion(effective.Config)
+	if err != nil {
+		return entities.EffectiveConfig{}, err
+	}
+	effective.ETag = `"` + strconv.FormatInt(effective.Version, 16) + `"`
+
+	return effective, nil
+}
+
+// Wait blocks until the effective config of the client gets another ETag or the timeout is over,
+// then returns the current config
+func (c Config) Wait(
+	ctx context.Context, reqID uuid.UUID, clientID, etag string, timeout time.Duration,
+) (entities.EffectiveConfig, error) {
+	ctx, span := c.tracer.Start(ctx, "uCase.Config.Wait")
+	defer span.End()
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	ticker := time.NewTicker(_configPollInterval)
+	defer ticker.Stop()
+
+	for {
+		effective, err := c.Effective(ctx, reqID, clientID)
+		if err != nil || effective.ETag != etag {
+			return effective, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return effective, nil
+		case <-deadline.C:
+			return effective, nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Applied saves the version of the config the client is running now
+func (c Config) Applied(ctx context.Context, reqID uuid.UUID, clientID string, version int64) error {
+	ctx, span := c.tracer.Start(ctx, "uCase.Config.Applied")
+	defer span.End()
+
+	castedID, err := primitive.ObjectIDFromHex(clientID)
+	if err != nil {
+		return errors.Wrap(err, "error during convert client id")
+	}
+
+	if err := c.clientRepo.SetAppliedConfig(ctx, castedID, version); err != nil {
+		return errors.Wrap(err, "can't save applied config of the client")
+	}
+
+	return nil
+}
+
+// configVersion returns the positive version of the merged config made of its digest, the empty config is of
+// version 0, which is the applied version of the client that has never reported it
+func configVersion(config entities.SensorConfig) (int64, error) {
+	if config == (entities.SensorConfig{}) {
+		return 0, nil
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		return 0, errors.Wrap(err, "error during marshal config")
+	}
+
+	sum := sha256.Sum256(data)
+
+	return int64(binary.BigEndian.Uint64(sum[:8]) >> 1), nil
+}
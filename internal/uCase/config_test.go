@@ -0,0 +1,167 @@
+package uCase_test
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	mock_repository "github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository/mocks"
+	"github.com/Imm0bilize/gunshot-api-service/internal/uCase"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestConfigEffective(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		district = primitive.NewObjectID()
+		station  = primitive.NewObjectID()
+		client   = entities.Client{
+			ID:            primitive.NewObjectID(),
+			ZoneIDs:       []primitive.ObjectID{district, station},
+			AppliedConfig: 3,
+		}
+		gain = 12.5
+	)
+
+	documents := []entities.ConfigDocument{
+		{
+			Scope:   entities.ConfigScopeClient,
+			OwnerID: client.ID,
+			Version: 2,
+			Config:  entities.SensorConfig{ChunkLength: intPtr(5)},
+		},
+		{
+			Scope:   entities.ConfigScopeZone,
+			OwnerID: station,
+			Version: 1,
+			Config:  entities.SensorConfig{SampleRate: intPtr(48000), ChunkLength: intPtr(10)},
+		},
+		{
+			Scope:   entities.ConfigScopeZone,
+			OwnerID: district,
+			Version: 4,
+			Config:  entities.SensorConfig{Gain: &gain, SampleRate: intPtr(16000), UploadInterval: intPtr(60)},
+		},
+	}
+
+	clients := mock_repository.NewMockClientRepository(ctrl)
+	clients.EXPECT().Get(gomock.Any(), client.ID.Hex()).Return(client, nil).Times(2)
+
+	configs := mock_repository.NewMockConfigRepository(ctrl)
+	configs.EXPECT().FindForClient(gomock.Any(), client.ID, client.ZoneIDs).Return(documents, nil).Times(2)
+
+	config := uCase.NewConfigUCase(zap.NewNop(), configs, clients, mock_repository.NewMockZoneRepository(ctrl))
+
+	effective, err := config.Effective(context.Background(), uuid.New(), client.ID.Hex())
+	require.NoError(t, err)
+
+	require.NotZero(t, effective.Version)
+	require.Equal(t, gain, *effective.Config.Gain)
+	require.Equal(t, 48000, *effective.Config.SampleRate)
+	require.Equal(t, 5, *effective.Config.ChunkLength)
+	require.Equal(t, 60, *effective.Config.UploadInterval)
+	require.True(t, effective.Drift())
+	require.NotEmpty(t, effective.ETag)
+
+	// the changed ETag is returned without waiting
+	changed, err := config.Wait(context.Background(), uuid.New(), client.ID.Hex(), `"outdated"`, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, effective.ETag, changed.ETag)
+}
+
+// TestConfigVersion checks that the version follows the merged config rather than versions of its documents
+func TestConfigVersion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		zone   = primitive.NewObjectID()
+		client = entities.Client{ID: primitive.NewObjectID(), ZoneIDs: []primitive.ObjectID{zone}}
+	)
+
+	var (
+		zoneConfig = entities.ConfigDocument{
+			Scope: entities.ConfigScopeZone, OwnerID: zone, Version: 5,
+			Config: entities.SensorConfig{SampleRate: intPtr(16000)},
+		}
+		override = entities.ConfigDocument{
+			Scope: entities.ConfigScopeClient, OwnerID: client.ID, Version: 1,
+			Config: entities.SensorConfig{SampleRate: intPtr(16000)},
+		}
+		changed = entities.ConfigDocument{
+			Scope: entities.ConfigScopeClient, OwnerID: client.ID, Version: 2,
+			Config: entities.SensorConfig{SampleRate: intPtr(48000)},
+		}
+		reverted = entities.ConfigDocument{
+			Scope: entities.ConfigScopeClient, OwnerID: client.ID, Version: 3,
+			Config: entities.SensorConfig{SampleRate: intPtr(16000)},
+		}
+	)
+
+	clients := mock_repository.NewMockClientRepository(ctrl)
+	clients.EXPECT().Get(gomock.Any(), client.ID.Hex()).Return(client, nil).Times(4)
+
+	configs := mock_repository.NewMockConfigRepository(ctrl)
+	gomock.InOrder(
+		configs.EXPECT().FindForClient(gomock.Any(), client.ID, client.ZoneIDs).
+			Return([]entities.ConfigDocument{zoneConfig}, nil),
+		configs.EXPECT().FindForClient(gomock.Any(), client.ID, client.ZoneIDs).
+			Return([]entities.ConfigDocument{zoneConfig, override}, nil),
+		configs.EXPECT().FindForClient(gomock.Any(), client.ID, client.ZoneIDs).
+			Return([]entities.ConfigDocument{zoneConfig, changed}, nil),
+		configs.EXPECT().FindForClient(gomock.Any(), client.ID, client.ZoneIDs).
+			Return([]entities.ConfigDocument{reverted}, nil),
+	)
+
+	config := uCase.NewConfigUCase(zap.NewNop(), configs, clients, mock_repository.NewMockZoneRepository(ctrl))
+
+	versions := make([]int64, 0, 4)
+	for i := 0; i < 4; i++ {
+		effective, err := config.Effective(context.Background(), uuid.New(), client.ID.Hex())
+		require.NoError(t, err)
+
+		versions = append(versions, effective.Version)
+	}
+
+	// the override of the same value doesn't change the config, the changed value does, the reverted one is
+	// the config of the first version again, though versions of the documents are smaller in sum
+	require.Equal(t, versions[0], versions[1])
+	require.NotEqual(t, versions[1], versions[2])
+	require.Equal(t, versions[0], versions[3])
+}
+
+func TestConfigWaitTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := entities.Client{ID: primitive.NewObjectID()}
+
+	clients := mock_repository.NewMockClientRepository(ctrl)
+	clients.EXPECT().Get(gomock.Any(), client.ID.Hex()).Return(client, nil).AnyTimes()
+
+	configs := mock_repository.NewMockConfigRepository(ctrl)
+	configs.EXPECT().FindForClient(gomock.Any(), client.ID, gomock.Any()).
+		Return([]entities.ConfigDocument{}, nil).AnyTimes()
+
+	config := uCase.NewConfigUCase(zap.NewNop(), configs, clients, mock_repository.NewMockZoneRepository(ctrl))
+
+	current, err := config.Effective(context.Background(), uuid.New(), client.ID.Hex())
+	require.NoError(t, err)
+	require.False(t, current.Drift())
+
+	start := time.Now()
+	unchanged, err := config.Wait(context.Background(), uuid.New(), client.ID.Hex(), current.ETag, 50*time.Millisecond)
+	require.NoError(t, err)
+	require.Equal(t, current.ETag, unchanged.ETag)
+	require.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+}
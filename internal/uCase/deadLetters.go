@@ -0,0 +1,73 @@
+package uCase
+
+import (
+	"context"
+	"fmt"
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrDeadLettersDisabled = apperr.New(apperr.Unimplemented, "the dead-letter topic is not configured")
+	ErrInvalidReplay       = apperr.New(apperr.InvalidArgument, "the replay of dead letters is invalid")
+)
+
+// DeadLetterQueue is the dead-letter topic of detections
+type DeadLetterQueue interface {
+	Replay(ctx context.Context, replay entities.DeadLetterReplay) (entities.DeadLetterReplayResult, error)
+}
+
+type DeadLetter struct {
+	tracer trace.Tracer
+	logger *zap.Logger
+	queue  DeadLetterQueue
+}
+
+// NewDeadLetterUCase creates the replay of dead letters, the nil queue means there is no dead-letter topic
+func NewDeadLetterUCase(logger *zap.Logger, queue DeadLetterQueue) *DeadLetter {
+	return &DeadLetter{
+		tracer: otel.Tracer("uCase.DeadLetter"),
+		logger: logger,
+		queue:  queue,
+	}
+}
+
+// Replay produces the selected dead letters to the detections topic again, they are processed as new detections
+func (d DeadLetter) Replay(
+	ctx context.Context, reqID uuid.UUID, replay entities.DeadLetterReplay,
+) (entities.DeadLetterReplayResult, error) {
+	ctx, span := d.tracer.Start(ctx, "uCase.DeadLetter.Replay")
+	defer span.End()
+
+	if d.queue == nil {
+		return entities.DeadLetterReplayResult{}, ErrDeadLettersDisabled
+	}
+
+	if replay.Partition < 0 || replay.From < 0 || (replay.To != nil && *replay.To < replay.From) {
+		return entities.DeadLetterReplayResult{}, fmt.Errorf(
+			"%w: the partition and offsets must not be negative, to must not be less than from", ErrInvalidReplay,
+		)
+	}
+
+	result, err := d.queue.Replay(ctx, replay)
+	if err != nil {
+		return result, errors.Wrap(err, "can't replay dead letters")
+	}
+
+	d.logger.Info(
+		"dead letters are replayed",
+		zap.String("reqID", reqID.String()),
+		zap.Int32("partition", replay.Partition),
+		zap.Int64("from", replay.From),
+		zap.Int64("next", result.Next),
+		zap.Int("replayed", result.Replayed),
+		zap.Int("skipped", result.Skipped),
+	)
+
+	return result, nil
+}
@@ -0,0 +1,61 @@
+package uCase_test
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/uCase"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"testing"
+)
+
+type countingQueue struct {
+	replays int
+}
+
+func (q *countingQueue) Replay(
+	_ context.Context, replay entities.DeadLetterReplay,
+) (entities.DeadLetterReplayResult, error) {
+	q.replays++
+	return entities.DeadLetterReplayResult{Next: replay.From}, nil
+}
+
+func TestDeadLetterReplay(t *testing.T) {
+	var (
+		before = int64(1)
+		after  = int64(5)
+	)
+
+	testTable := []struct {
+		name   string
+		replay entities.DeadLetterReplay
+		expErr error
+	}{
+		{name: "range", replay: entities.DeadLetterReplay{From: 2, To: &after}},
+		{name: "up to the newest", replay: entities.DeadLetterReplay{Partition: 1, From: 2}},
+		{name: "to before from", replay: entities.DeadLetterReplay{From: 2, To: &before}, expErr: uCase.ErrInvalidReplay},
+		{name: "negative offset", replay: entities.DeadLetterReplay{From: -1}, expErr: uCase.ErrInvalidReplay},
+		{name: "negative partition", replay: entities.DeadLetterReplay{Partition: -1}, expErr: uCase.ErrInvalidReplay},
+	}
+
+	for _, tCase := range testTable {
+		t.Run(tCase.name, func(t *testing.T) {
+			queue := &countingQueue{}
+
+			_, err := uCase.NewDeadLetterUCase(zap.NewNop(), queue).Replay(context.Background(), uuid.New(), tCase.replay)
+			require.ErrorIs(t, err, tCase.expErr)
+
+			if tCase.expErr == nil {
+				require.Equal(t, 1, queue.replays)
+			} else {
+				require.Zero(t, queue.replays)
+			}
+		})
+	}
+
+	_, err := uCase.NewDeadLetterUCase(zap.NewNop(), nil).Replay(
+		context.Background(), uuid.New(), entities.DeadLetterReplay{},
+	)
+	require.ErrorIs(t, err, uCase.ErrDeadLettersDisabled)
+}
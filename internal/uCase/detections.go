@@ -0,0 +1,146 @@
+package uCase
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/tenant"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"time"
+)
+
+type DetectionRepo interface {
+	Create(ctx context.Context, detection *entities.Detection) (string, error)
+	List(ctx context.Context, filter entities.DetectionFilter) ([]entities.Detection, error)
+	Count(ctx context.Context, clientID primitive.ObjectID, from, to time.Time) (int64, error)
+	MarkFalsePositive(ctx context.Context, incidentID string) error
+}
+
+// TombstoneRepo tells which clients have been erased
+type TombstoneRepo interface {
+	Erased(ctx context.Context, clientID primitive.ObjectID) (bool, error)
+}
+
+type Correlator interface {
+	Correlate(ctx context.Context, reqID uuid.UUID, detection *entities.Detection) (entities.Incident, error)
+}
+
+type Evaluator interface {
+	Evaluate(ctx context.Context, reqID uuid.UUID, detection *entities.Detection) ([]entities.Alert, error)
+}
+
+type Detection struct {
+	tracer        trace.Tracer
+	detectionRepo DetectionRepo
+	clientRepo    ClientRepo
+	tombstoneRepo TombstoneRepo
+	correlator    Correlator
+	evaluator     Evaluator
+	retention     *RetentionPolicy
+	logger        *zap.Logger
+}
+
+func NewDetectionUCase(
+	logger *zap.Logger,
+	detectionRepo DetectionRepo,
+	clientRepo ClientRepo,
+	tombstoneRepo TombstoneRepo,
+	correlator Correlator,
+	evaluator Evaluator,
+	retention *RetentionPolicy,
+) *Detection {
+	return &Detection{
+		tracer:        otel.Tracer("uCase.Detection"),
+		detectionRepo: detectionRepo,
+		clientRepo:    clientRepo,
+		tombstoneRepo: tombstoneRepo,
+		correlator:    correlator,
+		evaluator:     evaluator,
+		retention:     retention,
+		logger:        logger,
+	}
+}
+
+// Process saves the result of the model, groups gunshots into incidents and raises alerts
+func (d Detection) Process(ctx context.Context, reqID uuid.UUID, detection *entities.Detection) error {
+	ctx, span := d.tracer.Start(ctx, "uCase.Detection.Process")
+	defer span.End()
+
+	detection.RequestID = reqID.String()
+
+	// the model may answer after the client has been erased
+	erased, err := d.tombstoneRepo.Erased(ctx, detection.ClientID)
+	if err != nil {
+		return errors.Wrap(err, "can't check the tombstone of the client")
+	}
+	if erased {
+		d.logger.Info(
+			"detection of the erased client is dropped",
+			zap.String("reqID", reqID.String()),
+			zap.String("clientID", detection.ClientID.Hex()),
+		)
+
+		return nil
+	}
+
+	// detections come from the broker without a tenant, it is taken from the client
+	client, err := d.clientRepo.Get(tenant.System(ctx), detection.ClientID.Hex())
+	if err != nil {
+		return errors.Wrap(err, "can't get the client of the detection")
+	}
+	detection.ZoneIDs = client.ZoneIDs
+
+	ctx = tenant.WithID(ctx, client.TenantID)
+
+	detection.ExpiresAt, err = d.retention.ExpiresAt(ctx, entities.RetentionDetections, detection.Timestamp)
+	if err != nil {
+		return err
+	}
+
+	if detection.Label == entities.LabelGunshot {
+		incident, err := d.correlator.Correlate(ctx, reqID, detection)
+		if err != nil {
+			span.RecordError(err)
+			return errors.Wrap(err, "can't correlate the detection")
+		}
+		detection.IncidentID = incident.ID
+
+		// the incident is under the legal hold, the detection is kept with it
+		if incident.LegalHold != nil {
+			detection.ExpiresAt = nil
+		}
+	}
+
+	if _, err := d.detectionRepo.Create(ctx, detection); err != nil {
+		d.logger.Error(
+			"error during save detection",
+			zap.String("reqID", reqID.String()),
+			zap.Error(err),
+		)
+
+		return errors.Wrap(err, "can't save the detection")
+	}
+
+	if _, err := d.evaluator.Evaluate(ctx, reqID, detection); err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "can't evaluate alert rules")
+	}
+
+	return nil
+}
+
+func (d Detection) List(ctx context.Context, reqID uuid.UUID, filter entities.DetectionFilter) ([]entities.Detection, error) {
+	ctx, span := d.tracer.Start(ctx, "uCase.Detection.List")
+	defer span.End()
+
+	detections, err := d.detectionRepo.List(ctx, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get the list of detections")
+	}
+
+	return detections, nil
+}
@@ -0,0 +1,133 @@
+package uCase
+
+import (
+	"context"
+	"fmt"
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/Imm0bilize/gunshot-api-service/internal/audit"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"time"
+)
+
+var (
+	ErrInvalidDeviceKey = apperr.New(apperr.InvalidArgument, "invalid device key")
+	ErrDeviceKeyExists  = apperr.New(apperr.Conflict, "the device key is already registered")
+	ErrDeviceKeyUnknown = apperr.New(apperr.NotFound, "the device key is not found")
+)
+
+type DeviceKey struct {
+	tracer     trace.Tracer
+	logger     *zap.Logger
+	clientRepo ClientRepo
+	overlap    time.Duration
+}
+
+// NewDeviceKeyUCase creates the use case of device keys, overlap is how long rotated keys keep working
+// by default
+func NewDeviceKeyUCase(logger *zap.Logger, clientRepo ClientRepo, overlap time.Duration) *DeviceKey {
+	return &DeviceKey{
+		tracer:     otel.Tracer("uCase.DeviceKey"),
+		logger:     logger,
+		clientRepo: clientRepo,
+		overlap:    overlap,
+	}
+}
+
+// Register adds the key to the client. Keys which are active at the moment expire after the overlap,
+// so uploads already signed with them are accepted while the device switches to the new key.
+// The nil overlap means the default one. Keys are changed in place, so concurrent rotations are not lost
+func (d DeviceKey) Register(
+	ctx context.Context, reqID uuid.UUID, clientID string, publicKey []byte, overlap *time.Duration,
+) (entities.DeviceKey, error) {
+	ctx, span := d.tracer.Start(ctx, "uCase.DeviceKey.Register")
+	defer span.End()
+
+	now := time.Now().UTC()
+
+	key, err := entities.NewDeviceKey(publicKey, now)
+	if err != nil {
+		return entities.DeviceKey{}, fmt.Errorf("%w: %s", ErrInvalidDeviceKey, err)
+	}
+
+	client, err := d.clientRepo.Get(ctx, clientID)
+	if err != nil {
+		return entities.DeviceKey{}, errors.Wrap(err, "can't get the client")
+	}
+
+	if overlap == nil {
+		overlap = &d.overlap
+	}
+
+	err = d.clientRepo.AddKey(ctx, client.ID, key, now.Add(*overlap))
+	if errors.Is(err, repository.ErrDeviceKeyExists) {
+		return entities.DeviceKey{}, ErrDeviceKeyExists
+	}
+	if err != nil {
+		d.logger.Error(
+			"error during register device key",
+			zap.String("reqID", reqID.String()),
+			zap.String("clientID", clientID),
+			zap.Error(err),
+		)
+
+		return entities.DeviceKey{}, errors.Wrap(err, "can't save device keys")
+	}
+
+	audit.SetTargetID(ctx, key.ID)
+	auditChange(ctx, d.logger, reqID, nil, key)
+
+	return key, nil
+}
+
+func (d DeviceKey) List(ctx context.Context, reqID uuid.UUID, clientID string) ([]entities.DeviceKey, error) {
+	ctx, span := d.tracer.Start(ctx, "uCase.DeviceKey.List")
+	defer span.End()
+
+	client, err := d.clientRepo.Get(ctx, clientID)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get the client")
+	}
+
+	if client.Keys == nil {
+		return make([]entities.DeviceKey, 0), nil
+	}
+
+	return client.Keys, nil
+}
+
+// Revoke expires the key immediately, e.g. when the device is compromised
+func (d DeviceKey) Revoke(ctx context.Context, reqID uuid.UUID, clientID, keyID string) error {
+	ctx, span := d.tracer.Start(ctx, "uCase.DeviceKey.Revoke")
+	defer span.End()
+
+	client, err := d.clientRepo.Get(ctx, clientID)
+	if err != nil {
+		return errors.Wrap(err, "can't get the client")
+	}
+
+	now := time.Now().UTC()
+
+	before, err := d.clientRepo.RevokeKey(ctx, client.ID, keyID, now)
+	if errors.Is(err, repository.ErrDeviceKeyNotFound) {
+		return ErrDeviceKeyUnknown
+	}
+	if err != nil {
+		return errors.Wrap(err, "can't revoke the device key")
+	}
+
+	after := before
+	if after.ActiveAt(now) {
+		after.ExpiresAt = now
+	}
+
+	audit.SetTargetID(ctx, keyID)
+	auditChange(ctx, d.logger, reqID, before, after)
+
+	return nil
+}
@@ -0,0 +1,204 @@
+package uCase_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository"
+	mock_repository "github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository/mocks"
+	"github.com/Imm0bilize/gunshot-api-service/internal/uCase"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+func newDeviceKey(t *testing.T) (entities.DeviceKey, ed25519.PrivateKey) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	key, err := entities.NewDeviceKey(public, time.Now().UTC())
+	require.NoError(t, err)
+
+	return key, private
+}
+
+func signUpload(
+	private ed25519.PrivateKey, clientID primitive.ObjectID, ts time.Time, sequence int64, payload []byte,
+) entities.UploadSignature {
+	message := entities.UploadSigningPayload(clientID, ts, sequence, entities.HashPayload(payload))
+	return entities.UploadSignature{Sequence: sequence, Signature: ed25519.Sign(private, message)}
+}
+
+func TestAudioUploadSignature(t *testing.T) {
+	var (
+		key, private    = newDeviceKey(t)
+		expired, oldKey = newDeviceKey(t)
+		_, stranger     = newDeviceKey(t)
+		id              = primitive.NewObjectID()
+		ts              = time.Date(2022, 12, 1, 22, 0, 0, 0, time.UTC)
+		payload         = []byte("audio")
+	)
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+
+	testTable := []struct {
+		name      string
+		client    entities.Client
+		signature entities.UploadSignature
+		required  bool
+		expErr    error
+		expStatus entities.SignatureStatus
+	}{
+		{
+			name:      "verified",
+			client:    entities.Client{ID: id, Keys: []entities.DeviceKey{expired, key}},
+			signature: signUpload(private, id, ts, 1, payload),
+			expStatus: entities.SignatureVerified,
+		},
+		{
+			name:      "signed by the unknown key",
+			client:    entities.Client{ID: id, Keys: []entities.DeviceKey{key}},
+			signature: signUpload(stranger, id, ts, 1, payload),
+			expErr:    uCase.ErrInvalidSignature,
+		},
+		{
+			name:      "signed by the expired key",
+			client:    entities.Client{ID: id, Keys: []entities.DeviceKey{expired, key}},
+			signature: signUpload(oldKey, id, ts, 1, payload),
+			expErr:    uCase.ErrInvalidSignature,
+		},
+		{
+			name:      "signed other audio",
+			client:    entities.Client{ID: id, Keys: []entities.DeviceKey{key}},
+			signature: signUpload(private, id, ts, 1, []byte("other")),
+			expErr:    uCase.ErrInvalidSignature,
+		},
+		{
+			name:   "unsigned upload of the client with keys",
+			client: entities.Client{ID: id, Keys: []entities.DeviceKey{key}},
+			expErr: uCase.ErrSignatureRequired,
+		},
+		{
+			name:      "unsigned upload of the client without keys",
+			client:    entities.Client{ID: id},
+			expStatus: entities.SignatureAbsent,
+		},
+		{
+			name:     "unsigned uploads are disabled",
+			client:   entities.Client{ID: id},
+			required: true,
+			expErr:   uCase.ErrSignatureRequired,
+		},
+		{
+			name: "replayed sequence",
+			client: entities.Client{
+				ID: id, Keys: []entities.DeviceKey{key}, Chain: entities.ChainHead{Sequence: 3, DeviceSequence: 7},
+			},
+			signature: signUpload(private, id, ts, 7, payload),
+			expErr:    uCase.ErrReplayedUpload,
+		},
+	}
+
+	for _, tCase := range testTable {
+		t.Run(tCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			clients := mock_repository.NewMockClientRepository(ctrl)
+			clients.EXPECT().Get(gomock.Any(), id.Hex()).Return(tCase.client, nil).Times(1)
+
+			chain := mock_repository.NewMockChainRepository(ctrl)
+			chain.EXPECT().FindUpload(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(entities.ChainRecord{}, repository.ErrChainRecordNotFound).MaxTimes(1)
+			if tCase.expErr == nil {
+				clients.EXPECT().AdvanceChain(gomock.Any(), id, gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				chain.EXPECT().Create(gomock.Any(), gomock.Any()).Return("", nil).Times(1)
+			}
+
+			sender := &fakeSender{}
+			audio := uCase.NewAudioUCase(zap.NewNop(), sender, clients, chain, newFakeBlobs(), nil, nil, 1000, tCase.required)
+
+			err := audio.Upload(
+				context.Background(),
+				uuid.New(),
+				id.Hex(),
+				entities.Message{Payload: payload, Timestamp: ts, Signature: tCase.signature},
+			)
+			require.ErrorIs(t, err, tCase.expErr)
+
+			if tCase.expErr != nil {
+				require.Empty(t, sender.messages)
+				return
+			}
+			require.Len(t, sender.messages, 1)
+			require.Equal(t, tCase.expStatus, sender.messages[0].Verification.Status)
+		})
+	}
+}
+
+func TestDeviceKeyRotation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		current, _ = newDeviceKey(t)
+		next, _    = newDeviceKey(t)
+		client     = entities.Client{ID: primitive.NewObjectID(), Keys: []entities.DeviceKey{current}}
+		overlap    = time.Hour
+		expireBy   time.Time
+	)
+
+	clients := mock_repository.NewMockClientRepository(ctrl)
+	clients.EXPECT().Get(gomock.Any(), client.ID.Hex()).Return(client, nil).Times(2)
+	clients.EXPECT().AddKey(gomock.Any(), client.ID, gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ primitive.ObjectID, key entities.DeviceKey, by time.Time) error {
+			require.True(t, key.ExpiresAt.IsZero(), "the new key must not expire")
+			expireBy = by
+			return nil
+		},
+	).Times(1)
+	clients.EXPECT().AddKey(gomock.Any(), client.ID, gomock.Any(), gomock.Any()).
+		Return(repository.ErrDeviceKeyExists).Times(1)
+
+	useCase := uCase.NewDeviceKeyUCase(zap.NewNop(), clients, 24*time.Hour)
+
+	key, err := useCase.Register(context.Background(), uuid.New(), client.ID.Hex(), next.PublicKey, &overlap)
+	require.NoError(t, err)
+	require.Equal(t, next.ID, key.ID)
+
+	// previous keys sign uploads during the overlap only
+	require.WithinDuration(t, time.Now().Add(overlap), expireBy, time.Minute)
+
+	_, err = useCase.Register(context.Background(), uuid.New(), client.ID.Hex(), next.PublicKey, nil)
+	require.ErrorIs(t, err, uCase.ErrDeviceKeyExists)
+
+	_, err = useCase.Register(context.Background(), uuid.New(), client.ID.Hex(), []byte("short"), nil)
+	require.ErrorIs(t, err, uCase.ErrInvalidDeviceKey)
+}
+
+func TestDeviceKeyRevoke(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		key, _ = newDeviceKey(t)
+		client = entities.Client{ID: primitive.NewObjectID(), Keys: []entities.DeviceKey{key}}
+	)
+
+	clients := mock_repository.NewMockClientRepository(ctrl)
+	clients.EXPECT().Get(gomock.Any(), client.ID.Hex()).Return(client, nil).Times(2)
+	clients.EXPECT().RevokeKey(gomock.Any(), client.ID, key.ID, gomock.Any()).Return(key, nil).Times(1)
+	clients.EXPECT().RevokeKey(gomock.Any(), client.ID, "missing", gomock.Any()).
+		Return(entities.DeviceKey{}, repository.ErrDeviceKeyNotFound).Times(1)
+
+	useCase := uCase.NewDeviceKeyUCase(zap.NewNop(), clients, 24*time.Hour)
+
+	require.NoError(t, useCase.Revoke(context.Background(), uuid.New(), client.ID.Hex(), key.ID))
+
+	err := useCase.Revoke(context.Background(), uuid.New(), client.ID.Hex(), "missing")
+	require.ErrorIs(t, err, uCase.ErrDeviceKeyUnknown)
+}
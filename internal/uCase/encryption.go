@@ -0,0 +1,132 @@
+package uCase
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository"
+	"github.com/Imm0bilize/gunshot-api-service/internal/tenant"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"time"
+)
+
+const _rewrapPage = 500
+
+var (
+	ErrEncryptionDisabled = apperr.New(apperr.Unimplemented, "the encryption of the audio is not configured")
+)
+
+// RewrapRepo is the encrypted store of the audio
+type RewrapRepo interface {
+	CurrentKeyID() string
+	Stale(ctx context.Context, keyID string, after string, limit int64) ([]entities.Blob, error)
+	Rewrap(ctx context.Context, blob entities.Blob) error
+}
+
+// RewrapResult is the outcome of the key rotation, Failed objects are wrapped with keys the KMS doesn't have
+type RewrapResult struct {
+	KeyID     string `json:"keyID"`
+	Rewrapped int    `json:"rewrapped"`
+	Failed    int    `json:"failed"`
+}
+
+type KeyRotation struct {
+	tracer trace.Tracer
+	logger *zap.Logger
+	repo   RewrapRepo
+}
+
+// NewKeyRotationUCase creates the rotation of key encryption keys, the nil repo means the audio is not encrypted
+func NewKeyRotationUCase(logger *zap.Logger, repo RewrapRepo) *KeyRotation {
+	return &KeyRotation{
+		tracer: otel.Tracer("uCase.KeyRotation"),
+		logger: logger,
+		repo:   repo,
+	}
+}
+
+// Rewrap wraps data keys of all objects with the current key encryption key. The audio is not rewritten,
+// so the old key can be removed from the KMS once nothing is left wrapped with it
+func (k KeyRotation) Rewrap(ctx context.Context, reqID uuid.UUID) (RewrapResult, error) {
+	ctx, span := k.tracer.Start(ctx, "uCase.KeyRotation.Rewrap")
+	defer span.End()
+
+	if k.repo == nil {
+		return RewrapResult{}, ErrEncryptionDisabled
+	}
+
+	var (
+		ctxSystem = tenant.System(ctx)
+		result    = RewrapResult{KeyID: k.repo.CurrentKeyID()}
+		after     string
+	)
+
+	for {
+		blobs, err := k.repo.Stale(ctxSystem, result.KeyID, after, _rewrapPage)
+		if err != nil {
+			return result, errors.Wrap(err, "can't get objects to rewrap")
+		}
+
+		for _, blob := range blobs {
+			err := k.repo.Rewrap(ctxSystem, blob)
+			switch {
+			case err == nil:
+				result.Rewrapped++
+			case errors.Is(err, repository.ErrBlobNotFound):
+				// removed or rewrapped by someone else meanwhile
+			case errors.Is(err, repository.ErrBlobDecryption):
+				result.Failed++
+				k.logger.Error(
+					"data key can't be rewrapped",
+					zap.String("reqID", reqID.String()),
+					zap.String("key", blob.Key),
+					zap.Error(err),
+				)
+			default:
+				return result, errors.Wrap(err, "can't rewrap the data key")
+			}
+		}
+
+		if len(blobs) < _rewrapPage {
+			break
+		}
+		after = blobs[len(blobs)-1].Key
+	}
+
+	if result.Rewrapped > 0 || result.Failed > 0 {
+		k.logger.Info(
+			"data keys are rewrapped",
+			zap.String("reqID", reqID.String()),
+			zap.String("keyID", result.KeyID),
+			zap.Int("rewrapped", result.Rewrapped),
+			zap.Int("failed", result.Failed),
+		)
+	}
+
+	return result, nil
+}
+
+// Monitor rewraps data keys every interval until the context is done, nothing is done without the encryption
+func (k KeyRotation) Monitor(ctx context.Context, interval time.Duration) {
+	if k.repo == nil {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := k.Rewrap(ctx, uuid.New()); err != nil {
+				k.logger.Error("error during rewrap data keys", zap.Error(err))
+			}
+		}
+	}
+}
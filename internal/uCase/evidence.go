@@ -0,0 +1,232 @@
+package uCase
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository"
+	"github.com/Imm0bilize/gunshot-api-service/internal/signing"
+	"github.com/Imm0bilize/gunshot-api-service/pkg/evidence"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"io"
+	"mime"
+	"time"
+)
+
+// _evidencePadding widens the time range of the incident, so the clips have the sound before the first
+// shot and the echo after the last one
+const _evidencePadding = 10 * time.Second
+
+// _audioExtensions names clips in the bundle by the content type of the upload
+var _audioExtensions = map[string]string{
+	"audio/wav":   ".wav",
+	"audio/wave":  ".wav",
+	"audio/x-wav": ".wav",
+	"audio/mpeg":  ".mp3",
+	"audio/ogg":   ".ogg",
+	"audio/flac":  ".flac",
+}
+
+type EvidenceChainRepo interface {
+	Between(ctx context.Context, clientID primitive.ObjectID, from, to time.Time) ([]entities.ChainRecord, error)
+}
+
+type Evidence struct {
+	tracer        trace.Tracer
+	logger        *zap.Logger
+	incidentRepo  IncidentRepo
+	clientRepo    ClientRepo
+	detectionRepo DetectionRepo
+	chainRepo     EvidenceChainRepo
+	blobRepo      BlobRepo
+	auditRepo     AuditRepo
+	signer        *signing.Signer
+}
+
+// NewEvidenceUCase creates the evidence use case, the nil signer disables the export
+func NewEvidenceUCase(
+	logger *zap.Logger,
+	incidentRepo IncidentRepo,
+	clientRepo ClientRepo,
+	detectionRepo DetectionRepo,
+	chainRepo EvidenceChainRepo,
+	blobRepo BlobRepo,
+	auditRepo AuditRepo,
+	signer *signing.Signer,
+) *Evidence {
+	return &Evidence{
+		tracer:        otel.Tracer("uCase.Evidence"),
+		logger:        logger,
+		incidentRepo:  incidentRepo,
+		clientRepo:    clientRepo,
+		detectionRepo: detectionRepo,
+		chainRepo:     chainRepo,
+		blobRepo:      blobRepo,
+		auditRepo:     auditRepo,
+		signer:        signer,
+	}
+}
+
+// Export writes the signed evidence bundle of the incident. Everything is read before the first byte
+// is written, so a failure can still be reported to the caller
+func (e Evidence) Export(ctx context.Context, reqID uuid.UUID, incidentID string, w io.Writer) error {
+	ctx, span := e.tracer.Start(ctx, "uCase.Evidence.Export")
+	defer span.End()
+
+	if e.signer == nil {
+		return ErrSigningDisabled
+	}
+
+	incident, err := e.incidentRepo.Get(ctx, incidentID)
+	if err != nil {
+		return errors.Wrap(err, "can't get the incident")
+	}
+
+	manifest := evidence.Manifest{
+		RequestID:   reqID,
+		GeneratedAt: time.Now().UTC(),
+		Incident:    incident,
+		From:        incident.FirstSeen.Add(-_evidencePadding),
+		To:          incident.LastSeen.Add(_evidencePadding),
+		Sensors:     make([]evidence.Sensor, 0, len(incident.Clients)),
+		Clips:       make([]evidence.Clip, 0),
+	}
+
+	audio := make(map[string][]byte)
+	for _, clientID := range incident.Clients {
+		sensor, err := e.sensor(ctx, clientID.Hex())
+		if err != nil {
+			return err
+		}
+		manifest.Sensors = append(manifest.Sensors, sensor)
+
+		records, err := e.chainRepo.Between(ctx, clientID, manifest.From, manifest.To)
+		if err != nil {
+			return errors.Wrap(err, "can't get chain records")
+		}
+
+		for _, record := range records {
+			clip, data, err := e.clip(ctx, record)
+			if err != nil {
+				return err
+			}
+			manifest.Clips = append(manifest.Clips, clip)
+			if !clip.Missing {
+				audio[clip.File] = data
+			}
+		}
+	}
+
+	detections, err := e.detectionRepo.List(ctx, entities.DetectionFilter{IncidentID: incident.ID})
+	if err != nil {
+		return errors.Wrap(err, "can't get detections of the incident")
+	}
+
+	trail, err := e.auditTrail(ctx, incident)
+	if err != nil {
+		return err
+	}
+
+	bundle := evidence.NewWriter(w)
+	if err := bundle.AddJSON(evidence.ManifestFile, manifest); err != nil {
+		return err
+	}
+	for _, clip := range manifest.Clips {
+		if clip.Missing {
+			continue
+		}
+		if err := bundle.Add(clip.File, audio[clip.File]); err != nil {
+			return err
+		}
+	}
+	if err := bundle.AddJSON(evidence.DetectionsFile, detections); err != nil {
+		return err
+	}
+	if err := bundle.AddJSON(evidence.AuditFile, trail); err != nil {
+		return err
+	}
+
+	if err := bundle.Close(e.signer); err != nil {
+		return err
+	}
+
+	e.logger.Info(
+		"evidence bundle is exported",
+		zap.String("reqID", reqID.String()),
+		zap.String("incidentID", incidentID),
+		zap.Int("clips", len(audio)),
+	)
+
+	return nil
+}
+
+// sensor describes the contributing client, the client may have been deleted since the incident
+func (e Evidence) sensor(ctx context.Context, clientID string) (evidence.Sensor, error) {
+	client, err := e.clientRepo.Get(ctx, clientID)
+	if errors.Is(err, repository.ErrClientNotFound) {
+		id, _ := primitive.ObjectIDFromHex(clientID)
+		return evidence.Sensor{ClientID: id, Deleted: true}, nil
+	}
+	if err != nil {
+		return evidence.Sensor{}, errors.Wrap(err, "can't get the client")
+	}
+
+	return evidence.Sensor{
+		ClientID:     client.ID,
+		LocationName: client.LocationName,
+		FullName:     client.FullName,
+		Latitude:     client.Latitude,
+		Longitude:    client.Longitude,
+		Clock:        client.Clock,
+	}, nil
+}
+
+// clip reads the audio of the chain record, the audio may have been removed by the retention
+func (e Evidence) clip(ctx context.Context, record entities.ChainRecord) (evidence.Clip, []byte, error) {
+	blob, err := e.blobRepo.Get(ctx, entities.AudioBlobKey(record.ClientID, record.PayloadHash))
+	if errors.Is(err, repository.ErrBlobNotFound) {
+		return evidence.Clip{Record: record, Missing: true}, nil, nil
+	}
+	if err != nil {
+		return evidence.Clip{}, nil, errors.Wrap(err, "can't get the audio")
+	}
+
+	return evidence.Clip{Record: record, File: evidence.ClipFile(record, audioExtension(blob.ContentType))},
+		blob.Data, nil
+}
+
+// auditTrail collects audit entries of the incident and its sensors
+func (e Evidence) auditTrail(ctx context.Context, incident entities.Incident) ([]entities.AuditEntry, error) {
+	filters := []entities.AuditFilter{{TargetType: "incident", TargetID: incident.ID.Hex()}}
+	for _, clientID := range incident.Clients {
+		filters = append(filters, entities.AuditFilter{TargetType: "client", TargetID: clientID.Hex()})
+	}
+
+	trail := make([]entities.AuditEntry, 0)
+	for _, filter := range filters {
+		entries, err := e.auditRepo.List(ctx, filter)
+		if err != nil {
+			return nil, errors.Wrap(err, "can't get the audit trail")
+		}
+		trail = append(trail, entries...)
+	}
+
+	return trail, nil
+}
+
+func audioExtension(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ".bin"
+	}
+
+	if extension, ok := _audioExtensions[mediaType]; ok {
+		return extension
+	}
+
+	return ".bin"
+}
@@ -0,0 +1,142 @@
+package uCase_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository"
+	mock_repository "github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository/mocks"
+	"github.com/Imm0bilize/gunshot-api-service/internal/signing"
+	"github.com/Imm0bilize/gunshot-api-service/internal/uCase"
+	"github.com/Imm0bilize/gunshot-api-service/pkg/evidence"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEvidenceExport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		shot    = time.Date(2022, 12, 1, 22, 0, 0, 0, time.UTC)
+		client  = entities.Client{ID: primitive.NewObjectID(), LocationName: "square", Latitude: 55.75, Longitude: 37.61}
+		deleted = primitive.NewObjectID()
+		records []entities.ChainRecord
+	)
+
+	incident := entities.Incident{
+		ID:        primitive.NewObjectID(),
+		FirstSeen: shot,
+		LastSeen:  shot.Add(time.Second),
+		Clients:   []primitive.ObjectID{client.ID, deleted},
+	}
+
+	clients := mock_repository.NewMockClientRepository(ctrl)
+	clients.EXPECT().Get(gomock.Any(), client.ID.Hex()).DoAndReturn(
+		func(context.Context, string) (entities.Client, error) { return client, nil },
+	).AnyTimes()
+	clients.EXPECT().Get(gomock.Any(), deleted.Hex()).Return(entities.Client{}, repository.ErrClientNotFound)
+	clients.EXPECT().AdvanceChain(gomock.Any(), client.ID, gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ primitive.ObjectID, _, to entities.ChainHead) error {
+			client.Chain = to
+			return nil
+		},
+	).AnyTimes()
+
+	chain := mock_repository.NewMockChainRepository(ctrl)
+	chain.EXPECT().FindUpload(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(entities.ChainRecord{}, repository.ErrChainRecordNotFound).AnyTimes()
+	chain.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, record *entities.ChainRecord) (string, error) {
+			records = append(records, *record)
+			return "", nil
+		},
+	).AnyTimes()
+
+	// the clips are uploaded the usual way, so the bundle carries real chain records
+	blobs := newFakeBlobs()
+	audio := uCase.NewAudioUCase(zap.NewNop(), &fakeSender{}, clients, chain, blobs, nil, nil, 1000, false)
+	for i, payload := range []string{"before", "shot", "removed"} {
+		msg := entities.Message{
+			Payload:     []byte(payload),
+			Timestamp:   shot.Add(time.Duration(i) * time.Second),
+			MessageType: "audio/wav",
+		}
+		require.NoError(t, audio.Upload(context.Background(), uuid.New(), client.ID.Hex(), msg))
+	}
+	delete(blobs.blobs, entities.AudioBlobKey(client.ID, entities.HashPayload([]byte("removed"))))
+
+	incidents := mock_repository.NewMockIncidentRepository(ctrl)
+	incidents.EXPECT().Get(gomock.Any(), incident.ID.Hex()).Return(incident, nil)
+
+	chain.EXPECT().Between(gomock.Any(), client.ID, shot.Add(-10*time.Second), shot.Add(11*time.Second)).
+		DoAndReturn(func(context.Context, primitive.ObjectID, time.Time, time.Time) ([]entities.ChainRecord, error) {
+			return records, nil
+		})
+	chain.EXPECT().Between(gomock.Any(), deleted, gomock.Any(), gomock.Any()).Return([]entities.ChainRecord{}, nil)
+
+	detections := mock_repository.NewMockDetectionRepository(ctrl)
+	detections.EXPECT().List(gomock.Any(), entities.DetectionFilter{IncidentID: incident.ID}).Return(
+		[]entities.Detection{{ClientID: client.ID, IncidentID: incident.ID, ModelVersion: "v2", Timestamp: shot}}, nil,
+	)
+
+	audits := mock_repository.NewMockAuditRepository(ctrl)
+	audits.EXPECT().List(gomock.Any(), gomock.Any()).Return([]entities.AuditEntry{{Actor: "admin"}}, nil).Times(3)
+
+	signer, err := signing.NewSigner(strings.Repeat("01", 32))
+	require.NoError(t, err)
+
+	useCase := uCase.NewEvidenceUCase(zap.NewNop(), incidents, clients, detections, chain, blobs, audits, signer)
+
+	var buf bytes.Buffer
+	require.NoError(t, useCase.Export(context.Background(), uuid.New(), incident.ID.Hex(), &buf))
+
+	report, err := evidence.Verify(bytes.NewReader(buf.Bytes()), int64(buf.Len()), signer.PublicKey())
+	require.NoError(t, err)
+	require.True(t, report.Valid, report.Problems)
+	require.Equal(t, 2, report.Clips)
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	var manifest evidence.Manifest
+	readJSON(t, archive, evidence.ManifestFile, &manifest)
+	require.Len(t, manifest.Sensors, 2)
+	require.Equal(t, client.Latitude, manifest.Sensors[0].Latitude)
+	require.True(t, manifest.Sensors[1].Deleted)
+	require.Len(t, manifest.Clips, 3)
+	require.Equal(t, "audio/"+client.ID.Hex()+"/000001.wav", manifest.Clips[0].File)
+	require.True(t, manifest.Clips[2].Missing)
+
+	var got []entities.Detection
+	readJSON(t, archive, evidence.DetectionsFile, &got)
+	require.Equal(t, "v2", got[0].ModelVersion)
+}
+
+func TestEvidenceExportSigningDisabled(t *testing.T) {
+	useCase := uCase.NewEvidenceUCase(zap.NewNop(), nil, nil, nil, nil, nil, nil, nil)
+
+	var buf bytes.Buffer
+	err := useCase.Export(context.Background(), uuid.New(), primitive.NewObjectID().Hex(), &buf)
+	require.ErrorIs(t, err, uCase.ErrSigningDisabled)
+	require.Zero(t, buf.Len())
+}
+
+func readJSON(t *testing.T, archive *zip.Reader, name string, v interface{}) {
+	f, err := archive.Open(name)
+	require.NoError(t, err)
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, v))
+}
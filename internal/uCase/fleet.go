@@ -0,0 +1,226 @@
+package uCase
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/tenant"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"time"
+)
+
+type HeartbeatRepo interface {
+	Create(ctx context.Context, heartbeat *entities.Heartbeat) (string, error)
+	List(ctx context.Context, filter entities.HeartbeatFilter) ([]entities.Heartbeat, error)
+}
+
+type MaintenancePublisher interface {
+	SendMaintenanceEvent(ctx context.Context, reqID uuid.UUID, event entities.MaintenanceEvent) error
+}
+
+type Fleet struct {
+	tracer        trace.Tracer
+	heartbeatRepo HeartbeatRepo
+	clientRepo    ClientRepo
+	publisher     MaintenancePublisher
+	retention     *RetentionPolicy
+	logger        *zap.Logger
+	degradedAfter time.Duration
+	offlineAfter  time.Duration
+}
+
+// NewFleetUCase creates the fleet monitor: the client is degraded when there were no heartbeats
+// for degradedAfter and offline after offlineAfter
+func NewFleetUCase(
+	logger *zap.Logger,
+	heartbeatRepo HeartbeatRepo,
+	clientRepo ClientRepo,
+	publisher MaintenancePublisher,
+	retention *RetentionPolicy,
+	degradedAfter, offlineAfter time.Duration,
+) *Fleet {
+	return &Fleet{
+		tracer:        otel.Tracer("uCase.Fleet"),
+		heartbeatRepo: heartbeatRepo,
+		clientRepo:    clientRepo,
+		publisher:     publisher,
+		retention:     retention,
+		logger:        logger,
+		degradedAfter: degradedAfter,
+		offlineAfter:  offlineAfter,
+	}
+}
+
+// Heartbeat saves the status report of the client and marks it online
+func (f Fleet) Heartbeat(ctx context.Context, reqID uuid.UUID, clientID string, heartbeat *entities.Heartbeat) error {
+	ctx, span := f.tracer.Start(ctx, "uCase.Fleet.Heartbeat")
+	defer span.End()
+
+	castedID, err := primitive.ObjectIDFromHex(clientID)
+	if err != nil {
+		return errors.Wrap(err, "error during convert client id")
+	}
+
+	heartbeat.ClientID = castedID
+	heartbeat.ReceivedAt = time.Now().UTC()
+
+	health := entities.ClientHealth{
+		Status:    entities.HealthOnline,
+		LastSeen:  heartbeat.ReceivedAt,
+		Heartbeat: *heartbeat,
+	}
+
+	// the health is updated first, so heartbeats of unknown clients are not saved
+	if err := f.clientRepo.SetHealth(ctx, castedID, health); err != nil {
+		return errors.Wrap(err, "can't update health of the client")
+	}
+
+	heartbeat.ExpiresAt, err = f.retention.ExpiresAt(ctx, entities.RetentionHeartbeats, heartbeat.ReceivedAt)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.heartbeatRepo.Create(ctx, heartbeat); err != nil {
+		return errors.Wrap(err, "can't save the heartbeat")
+	}
+
+	return nil
+}
+
+func (f Fleet) Heartbeats(
+	ctx context.Context, reqID uuid.UUID, filter entities.HeartbeatFilter,
+) ([]entities.Heartbeat, error) {
+	ctx, span := f.tracer.Start(ctx, "uCase.Fleet.Heartbeats")
+	defer span.End()
+
+	heartbeats, err := f.heartbeatRepo.List(ctx, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get heartbeats of the client")
+	}
+
+	return heartbeats, nil
+}
+
+// Health returns the current status of every client
+func (f Fleet) Health(ctx context.Context, reqID uuid.UUID) (entities.FleetHealth, error) {
+	ctx, span := f.tracer.Start(ctx, "uCase.Fleet.Health")
+	defer span.End()
+
+	clients, err := f.clientRepo.List(ctx)
+	if err != nil {
+		return entities.FleetHealth{}, errors.Wrap(err, "can't get clients")
+	}
+
+	now := time.Now()
+	fleet := entities.FleetHealth{Clients: make([]entities.ClientStatus, 0, len(clients))}
+
+	for _, client := range clients {
+		health := client.Health
+		health.Status = f.status(now, health.LastSeen)
+
+		switch health.Status {
+		case entities.HealthOnline:
+			fleet.Online++
+		case entities.HealthDegraded:
+			fleet.Degraded++
+		default:
+			fleet.Offline++
+		}
+
+		fleet.Clients = append(fleet.Clients, entities.ClientStatus{ClientID: client.ID, Health: health})
+	}
+
+	return fleet, nil
+}
+
+// Check saves statuses of clients that have been changed since the last check
+// and raises maintenance events for clients which went offline
+func (f Fleet) Check(ctx context.Context) error {
+	ctx, span := f.tracer.Start(ctx, "uCase.Fleet.Check")
+	defer span.End()
+
+	// the whole fleet is checked regardless of organizations
+	ctx = tenant.System(ctx)
+
+	clients, err := f.clientRepo.List(ctx)
+	if err != nil {
+		return errors.Wrap(err, "can't get clients")
+	}
+
+	now := time.Now()
+	for _, client := range clients {
+		health := client.Health
+
+		status := f.status(now, health.LastSeen)
+		if status == health.Status {
+			continue
+		}
+
+		previous := health.Status
+
+		// a heartbeat received since the clients were listed wins over the computed status
+		changed, err := f.clientRepo.SetHealthStatus(ctx, client.ID, health.LastSeen, status)
+		if err != nil {
+			return errors.Wrap(err, "can't update health of the client")
+		}
+		if !changed {
+			continue
+		}
+
+		// never seen clients are offline from the beginning
+		if status != entities.HealthOffline || previous == "" {
+			continue
+		}
+
+		event := entities.MaintenanceEvent{
+			ClientID:  client.ID,
+			From:      previous,
+			To:        status,
+			LastSeen:  health.LastSeen,
+			Timestamp: now.UTC(),
+		}
+
+		if err := f.publisher.SendMaintenanceEvent(ctx, uuid.New(), event); err != nil {
+			span.RecordError(err)
+			f.logger.Error(
+				"error during publish maintenance event",
+				zap.String("clientID", client.ID.Hex()),
+				zap.Error(err),
+			)
+		}
+	}
+
+	return nil
+}
+
+// Monitor checks the fleet with the interval until the context is canceled
+func (f Fleet) Monitor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := f.Check(ctx); err != nil {
+				f.logger.Error("error during check the fleet", zap.Error(err))
+			}
+		}
+	}
+}
+
+func (f Fleet) status(now, lastSeen time.Time) entities.HealthStatus {
+	switch since := now.Sub(lastSeen); {
+	case lastSeen.IsZero() || since >= f.offlineAfter:
+		return entities.HealthOffline
+	case since >= f.degradedAfter:
+		return entities.HealthDegraded
+	default:
+		return entities.HealthOnline
+	}
+}
@@ -0,0 +1,113 @@
+package uCase_test
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository"
+	mock_repository "github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository/mocks"
+	"github.com/Imm0bilize/gunshot-api-service/internal/uCase"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+func clientSeen(status entities.HealthStatus, ago time.Duration) entities.Client {
+	return entities.Client{
+		ID: primitive.NewObjectID(),
+		Health: entities.ClientHealth{
+			Status:   status,
+			LastSeen: time.Now().Add(-ago),
+		},
+	}
+}
+
+func TestFleetHealth(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		online   = clientSeen(entities.HealthOnline, time.Second)
+		degraded = clientSeen(entities.HealthOnline, 5*time.Minute)
+		offline  = clientSeen(entities.HealthDegraded, time.Hour)
+		never    = entities.Client{ID: primitive.NewObjectID()}
+	)
+
+	clients := mock_repository.NewMockClientRepository(ctrl)
+	clients.EXPECT().List(gomock.Any()).Return([]entities.Client{online, degraded, offline, never}, nil).Times(1)
+
+	fleet := uCase.NewFleetUCase(
+		zap.NewNop(), mock_repository.NewMockHeartbeatRepository(ctrl), clients, &fakePublisher{}, nil,
+		2*time.Minute, 10*time.Minute,
+	)
+
+	health, err := fleet.Health(context.Background(), uuid.New())
+	require.NoError(t, err)
+
+	require.Equal(t, 1, health.Online)
+	require.Equal(t, 1, health.Degraded)
+	require.Equal(t, 2, health.Offline)
+	require.Len(t, health.Clients, 4)
+	require.Equal(t, entities.HealthDegraded, health.Clients[1].Health.Status)
+	require.Equal(t, entities.HealthOffline, health.Clients[3].Health.Status)
+}
+
+func TestFleetCheck(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		online   = clientSeen(entities.HealthOnline, time.Second)
+		degraded = clientSeen(entities.HealthOnline, 5*time.Minute)
+		offline  = clientSeen(entities.HealthDegraded, time.Hour)
+		never    = entities.Client{ID: primitive.NewObjectID()}
+		// the heartbeat of the client is received after it's listed
+		revived = clientSeen(entities.HealthDegraded, time.Hour)
+	)
+
+	clients := mock_repository.NewMockClientRepository(ctrl)
+	clients.EXPECT().List(gomock.Any()).
+		Return([]entities.Client{online, degraded, offline, never, revived}, nil).Times(1)
+	clients.EXPECT().SetHealthStatus(gomock.Any(), degraded.ID, degraded.Health.LastSeen, entities.HealthDegraded).
+		Return(true, nil).Times(1)
+	clients.EXPECT().SetHealthStatus(gomock.Any(), offline.ID, offline.Health.LastSeen, entities.HealthOffline).
+		Return(true, nil).Times(1)
+	clients.EXPECT().SetHealthStatus(gomock.Any(), never.ID, time.Time{}, entities.HealthOffline).
+		Return(true, nil).Times(1)
+	clients.EXPECT().SetHealthStatus(gomock.Any(), revived.ID, revived.Health.LastSeen, entities.HealthOffline).
+		Return(false, nil).Times(1)
+
+	publisher := &fakePublisher{}
+	fleet := uCase.NewFleetUCase(
+		zap.NewNop(), mock_repository.NewMockHeartbeatRepository(ctrl), clients, publisher, nil,
+		2*time.Minute, 10*time.Minute,
+	)
+
+	require.NoError(t, fleet.Check(context.Background()))
+
+	require.Len(t, publisher.maintenance, 1)
+	require.Equal(t, offline.ID, publisher.maintenance[0].ClientID)
+	require.Equal(t, entities.HealthDegraded, publisher.maintenance[0].From)
+	require.Equal(t, entities.HealthOffline, publisher.maintenance[0].To)
+}
+
+func TestFleetHeartbeatUnknownClient(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := primitive.NewObjectID()
+
+	clients := mock_repository.NewMockClientRepository(ctrl)
+	clients.EXPECT().SetHealth(gomock.Any(), id, gomock.Any()).Return(repository.ErrClientNotFound).Times(1)
+
+	fleet := uCase.NewFleetUCase(
+		zap.NewNop(), mock_repository.NewMockHeartbeatRepository(ctrl), clients, &fakePublisher{}, nil,
+		2*time.Minute, 10*time.Minute,
+	)
+
+	err := fleet.Heartbeat(context.Background(), uuid.New(), id.Hex(), &entities.Heartbeat{FirmwareVersion: "1.0.0"})
+	require.ErrorIs(t, err, repository.ErrClientNotFound)
+}
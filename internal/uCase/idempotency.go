@@ -0,0 +1,151 @@
+package uCase
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"time"
+)
+
+const (
+	// _idempotencyLease is how long the pending request holds its key unless it's extended by Hold, so the
+	// key of the request lost with the instance which has served it is freed
+	_idempotencyLease = time.Minute
+	// _idempotencyTTL is how long responses are returned to retries
+	_idempotencyTTL = 24 * time.Hour
+)
+
+var (
+	ErrIdempotencyKeyReused = apperr.New(
+		apperr.Conflict, "the Idempotency-Key has been sent with another method, path or body",
+	)
+	ErrIdempotentRequestPending = apperr.New(
+		apperr.Unavailable, "the request with the Idempotency-Key is being served",
+	)
+)
+
+type IdempotencyRepo interface {
+	Begin(ctx context.Context, request *entities.IdempotentRequest) (entities.IdempotentRequest, bool, error)
+	Complete(ctx context.Context, request *entities.IdempotentRequest) error
+	Extend(ctx context.Context, request *entities.IdempotentRequest) error
+	Release(ctx context.Context, request *entities.IdempotentRequest) error
+}
+
+type Idempotency struct {
+	tracer          trace.Tracer
+	logger          *zap.Logger
+	idempotencyRepo IdempotencyRepo
+}
+
+func NewIdempotencyUCase(logger *zap.Logger, idempotencyRepo IdempotencyRepo) *Idempotency {
+	return &Idempotency{
+		tracer:          otel.Tracer("uCase.Idempotency"),
+		logger:          logger,
+		idempotencyRepo: idempotencyRepo,
+	}
+}
+
+// Begin takes the key of the request. If the key is taken by the earlier attempt of the request, its
+// completed request is returned with false: the response is to be sent again
+func (i Idempotency) Begin(
+	ctx context.Context, reqID uuid.UUID, request *entities.IdempotentRequest,
+) (entities.IdempotentRequest, bool, error) {
+	ctx, span := i.tracer.Start(ctx, "uCase.Idempotency.Begin")
+	defer span.End()
+
+	request.Status = 0
+	request.ExpiresAt = time.Now().UTC().Add(_idempotencyLease)
+
+	existing, started, err := i.idempotencyRepo.Begin(ctx, request)
+	if err != nil {
+		i.logger.Error(
+			"error during begin idempotent request",
+			zap.String("reqID", reqID.String()),
+			zap.String("key", request.Key),
+			zap.Error(err),
+		)
+
+		return entities.IdempotentRequest{}, false, errors.Wrap(err, "can't begin the idempotent request")
+	}
+
+	switch {
+	case started:
+		return existing, true, nil
+	case !existing.Matches(*request):
+		return entities.IdempotentRequest{}, false, ErrIdempotencyKeyReused
+	case existing.Pending():
+		return entities.IdempotentRequest{}, false, ErrIdempotentRequestPending
+	default:
+		return existing, false, nil
+	}
+}
+
+// Complete saves the response of the request begun by Begin
+func (i Idempotency) Complete(ctx context.Context, reqID uuid.UUID, request *entities.IdempotentRequest) error {
+	ctx, span := i.tracer.Start(ctx, "uCase.Idempotency.Complete")
+	defer span.End()
+
+	request.ExpiresAt = time.Now().UTC().Add(_idempotencyTTL)
+
+	if err := i.idempotencyRepo.Complete(ctx, request); err != nil {
+		i.logger.Error(
+			"error during complete idempotent request",
+			zap.String("reqID", reqID.String()),
+			zap.String("key", request.Key),
+			zap.Error(err),
+		)
+
+		return errors.Wrap(err, "can't complete the idempotent request")
+	}
+
+	return nil
+}
+
+// Hold extends the lease of the pending request until the context is done, so the key of the request served
+// longer than the lease, e.g. of the large import, isn't freed for retries
+func (i Idempotency) Hold(ctx context.Context, reqID uuid.UUID, request *entities.IdempotentRequest) {
+	ticker := time.NewTicker(_idempotencyLease / 3)
+	defer ticker.Stop()
+
+	extended := *request
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			extended.ExpiresAt = time.Now().UTC().Add(_idempotencyLease)
+			if err := i.idempotencyRepo.Extend(ctx, &extended); err != nil && ctx.Err() == nil {
+				i.logger.Warn(
+					"error during extend idempotent request",
+					zap.String("reqID", reqID.String()),
+					zap.String("key", request.Key),
+					zap.Error(err),
+				)
+			}
+		}
+	}
+}
+
+// Release frees the key of the request which has failed, so its retry is served again
+func (i Idempotency) Release(ctx context.Context, reqID uuid.UUID, request *entities.IdempotentRequest) error {
+	ctx, span := i.tracer.Start(ctx, "uCase.Idempotency.Release")
+	defer span.End()
+
+	if err := i.idempotencyRepo.Release(ctx, request); err != nil {
+		i.logger.Error(
+			"error during release idempotent request",
+			zap.String("reqID", reqID.String()),
+			zap.String("key", request.Key),
+			zap.Error(err),
+		)
+
+		return errors.Wrap(err, "can't release the idempotent request")
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package uCase_test
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	mock_repository "github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository/mocks"
+	"github.com/Imm0bilize/gunshot-api-service/internal/uCase"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyBegin(t *testing.T) {
+	request := entities.IdempotentRequest{
+		Key: "key", Method: http.MethodPost, Path: "/api/v1/client", BodyHash: "e3b0c44298fc1c14",
+	}
+
+	completed := request
+	completed.Status = http.StatusCreated
+	completed.Body = []byte(`{"ID":"1"}`)
+
+	testCases := []struct {
+		name     string
+		existing entities.IdempotentRequest
+		started  bool
+		expected entities.IdempotentRequest
+		err      error
+	}{
+		{name: "the first attempt", existing: request, started: true, expected: request},
+		{name: "the retry of the completed request", existing: completed, expected: completed},
+		{name: "the retry of the pending request", existing: request, err: uCase.ErrIdempotentRequestPending},
+		{
+			name: "the key of another request",
+			existing: entities.IdempotentRequest{
+				Key: "key", Method: http.MethodPost, Path: "/api/v1/zones", BodyHash: request.BodyHash,
+			},
+			err: uCase.ErrIdempotencyKeyReused,
+		},
+		{
+			name: "the key of another body",
+			existing: entities.IdempotentRequest{
+				Key: "key", Method: http.MethodPost, Path: "/api/v1/client", BodyHash: "5feceb66ffc86f38",
+				Status: http.StatusCreated,
+			},
+			err: uCase.ErrIdempotencyKeyReused,
+		},
+	}
+
+	for _, tCase := range testCases {
+		t.Run(tCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repository.NewMockIdempotencyRepository(ctrl)
+			repo.EXPECT().Begin(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, r *entities.IdempotentRequest) (entities.IdempotentRequest, bool, error) {
+					require.True(t, r.Pending())
+					require.WithinDuration(t, time.Now().Add(time.Minute), r.ExpiresAt, time.Second)
+					return tCase.existing, tCase.started, nil
+				},
+			)
+
+			begun := request
+			actual, started, err := uCase.NewIdempotencyUCase(zap.NewNop(), repo).Begin(
+				context.Background(), uuid.New(), &begun,
+			)
+			require.ErrorIs(t, err, tCase.err)
+			require.Equal(t, tCase.started, started)
+			if tCase.err == nil {
+				require.Equal(t, tCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestIdempotencyComplete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_repository.NewMockIdempotencyRepository(ctrl)
+	repo.EXPECT().Complete(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, r *entities.IdempotentRequest) error {
+			require.WithinDuration(t, time.Now().Add(24*time.Hour), r.ExpiresAt, time.Second)
+			return nil
+		},
+	)
+
+	useCase := uCase.NewIdempotencyUCase(zap.NewNop(), repo)
+	request := &entities.IdempotentRequest{Key: "key", Status: http.StatusCreated}
+	require.NoError(t, useCase.Complete(context.Background(), uuid.New(), request))
+}
@@ -0,0 +1,254 @@
+package uCase
+
+import (
+	"context"
+	"fmt"
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/geo"
+	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"time"
+)
+
+type IncidentRepo interface {
+	Create(ctx context.Context, incident *entities.Incident) (string, error)
+	Get(ctx context.Context, id string) (entities.Incident, error)
+	Merge(ctx context.Context, id primitive.ObjectID, client entities.Client, ts time.Time) (entities.Incident, error)
+	SetZones(ctx context.Context, id primitive.ObjectID, shotCount int, zoneIDs []primitive.ObjectID) error
+	Transition(ctx context.Context, id string, transition entities.IncidentTransition) error
+	FindActive(ctx context.Context, since time.Time) ([]entities.Incident, error)
+	List(ctx context.Context, filter entities.IncidentFilter) ([]entities.Incident, error)
+}
+
+type IncidentPublisher interface {
+	SendIncidentTransition(
+		ctx context.Context, reqID uuid.UUID, incident entities.Incident, transition entities.IncidentTransition,
+	) error
+}
+
+var (
+	ErrInvalidTransition = apperr.New(apperr.Conflict, "the transition is not allowed")
+)
+
+// _transitions is the state machine of the incident: status -> statuses reachable from it
+var _transitions = map[entities.IncidentStatus][]entities.IncidentStatus{
+	entities.IncidentNew: {
+		entities.IncidentAcknowledged,
+		entities.IncidentFalsePositive,
+	},
+	entities.IncidentAcknowledged: {
+		entities.IncidentDispatched,
+		entities.IncidentResolved,
+		entities.IncidentFalsePositive,
+	},
+	entities.IncidentDispatched: {
+		entities.IncidentResolved,
+		entities.IncidentFalsePositive,
+	},
+}
+
+type Incident struct {
+	tracer        trace.Tracer
+	incidentRepo  IncidentRepo
+	clientRepo    ClientRepo
+	detectionRepo DetectionRepo
+	zoneRepo      ZoneRepo
+	publisher     IncidentPublisher
+	logger        *zap.Logger
+	radius        float64
+	window        time.Duration
+}
+
+// NewIncidentUCase creates the aggregator which merges detections reported by clients
+// located no further than radius (meters) from each other and within the time window
+func NewIncidentUCase(
+	logger *zap.Logger,
+	incidentRepo IncidentRepo,
+	clientRepo ClientRepo,
+	detectionRepo DetectionRepo,
+	zoneRepo ZoneRepo,
+	publisher IncidentPublisher,
+	radius float64,
+	window time.Duration,
+) *Incident {
+	return &Incident{
+		tracer:        otel.Tracer("uCase.Incident"),
+		incidentRepo:  incidentRepo,
+		clientRepo:    clientRepo,
+		detectionRepo: detectionRepo,
+		zoneRepo:      zoneRepo,
+		publisher:     publisher,
+		logger:        logger,
+		radius:        radius,
+		window:        window,
+	}
+}
+
+// Correlate attaches the detection to the nearest active incident or opens a new one
+func (i Incident) Correlate(ctx context.Context, reqID uuid.UUID, detection *entities.Detection) (entities.Incident, error) {
+	ctx, span := i.tracer.Start(ctx, "uCase.Incident.Correlate")
+	defer span.End()
+
+	client, err := i.clientRepo.Get(ctx, detection.ClientID.Hex())
+	if err != nil {
+		return entities.Incident{}, errors.Wrap(err, "can't get the client of the detection")
+	}
+
+	candidates, err := i.incidentRepo.FindActive(ctx, detection.Timestamp.Add(-i.window))
+	if err != nil {
+		return entities.Incident{}, errors.Wrap(err, "can't find active incidents")
+	}
+
+	zones, err := i.zoneRepo.List(ctx)
+	if err != nil {
+		return entities.Incident{}, errors.Wrap(err, "can't get zones")
+	}
+
+	if incident, found := i.nearest(candidates, client.Location(), detection.Timestamp); found {
+		merged, err := i.incidentRepo.Merge(ctx, incident.ID, client, detection.Timestamp)
+		switch {
+		case err == nil:
+			merged.ZoneIDs = entities.ZonesOf(zones, geo.Point{Latitude: merged.Latitude, Longitude: merged.Longitude})
+			if err := i.incidentRepo.SetZones(ctx, merged.ID, merged.ShotCount, merged.ZoneIDs); err != nil {
+				return entities.Incident{}, errors.Wrap(err, "can't set zones of the incident")
+			}
+
+			return merged, nil
+		case errors.Is(err, repository.ErrIncidentNotFound):
+			// the incident has been closed since it was found, the detection opens a new one
+		default:
+			return entities.Incident{}, errors.Wrap(err, "can't update the incident")
+		}
+	}
+
+	incident := entities.Incident{
+		Latitude:  client.Latitude,
+		Longitude: client.Longitude,
+		ShotCount: 1,
+		FirstSeen: detection.Timestamp,
+		LastSeen:  detection.Timestamp,
+		Clients:   []primitive.ObjectID{client.ID},
+		Status:    entities.IncidentNew,
+		ZoneIDs:   entities.ZonesOf(zones, client.Location()),
+	}
+
+	if _, err := i.incidentRepo.Create(ctx, &incident); err != nil {
+		return entities.Incident{}, errors.Wrap(err, "can't create new incident")
+	}
+
+	i.logger.Info(
+		"new incident is opened",
+		zap.String("reqID", reqID.String()),
+		zap.String("incidentID", incident.ID.Hex()),
+	)
+
+	return incident, nil
+}
+
+func (i Incident) nearest(candidates []entities.Incident, point geo.Point, ts time.Time) (entities.Incident, bool) {
+	var (
+		best     entities.Incident
+		bestDist = i.radius
+		found    bool
+	)
+
+	for _, candidate := range candidates {
+		if ts.Before(candidate.FirstSeen.Add(-i.window)) || ts.After(candidate.LastSeen.Add(i.window)) {
+			continue
+		}
+
+		dist := geo.Distance(point, geo.Point{Latitude: candidate.Latitude, Longitude: candidate.Longitude})
+		if dist <= bestDist {
+			best, bestDist, found = candidate, dist, true
+		}
+	}
+
+	return best, found
+}
+
+// Transition moves the incident through the lifecycle and notifies downstream systems about it
+func (i Incident) Transition(
+	ctx context.Context, reqID uuid.UUID, id string, transition entities.IncidentTransition,
+) (entities.Incident, error) {
+	ctx, span := i.tracer.Start(ctx, "uCase.Incident.Transition")
+	defer span.End()
+
+	incident, err := i.incidentRepo.Get(ctx, id)
+	if err != nil {
+		return entities.Incident{}, errors.Wrap(err, "can't get the incident")
+	}
+
+	if !allowed(incident.Status, transition.To) {
+		return entities.Incident{}, fmt.Errorf(
+			"%w: from '%s' to '%s'", ErrInvalidTransition, incident.Status, transition.To,
+		)
+	}
+
+	transition.From = incident.Status
+	transition.Timestamp = time.Now().UTC()
+
+	if err := i.incidentRepo.Transition(ctx, id, transition); err != nil {
+		return entities.Incident{}, errors.Wrap(err, "can't change the incident status")
+	}
+
+	incident.Status = transition.To
+	incident.Transitions = append(incident.Transitions, transition)
+
+	if transition.To == entities.IncidentFalsePositive {
+		if err := i.detectionRepo.MarkFalsePositive(ctx, id); err != nil {
+			return entities.Incident{}, errors.Wrap(err, "can't mark detections of the incident")
+		}
+	}
+
+	if err := i.publisher.SendIncidentTransition(ctx, reqID, incident, transition); err != nil {
+		span.RecordError(err)
+		i.logger.Error(
+			"error during publish incident transition",
+			zap.String("reqID", reqID.String()),
+			zap.String("incidentID", id),
+			zap.Error(err),
+		)
+	}
+
+	return incident, nil
+}
+
+func allowed(from, to entities.IncidentStatus) bool {
+	for _, status := range _transitions[from] {
+		if status == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (i Incident) Get(ctx context.Context, reqID uuid.UUID, id string) (entities.Incident, error) {
+	ctx, span := i.tracer.Start(ctx, "uCase.Incident.Get")
+	defer span.End()
+
+	incident, err := i.incidentRepo.Get(ctx, id)
+	if err != nil {
+		return entities.Incident{}, errors.Wrap(err, "can't get the incident")
+	}
+
+	return incident, nil
+}
+
+func (i Incident) List(ctx context.Context, reqID uuid.UUID, filter entities.IncidentFilter) ([]entities.Incident, error) {
+	ctx, span := i.tracer.Start(ctx, "uCase.Incident.List")
+	defer span.End()
+
+	incidents, err := i.incidentRepo.List(ctx, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get the list of incidents")
+	}
+
+	return incidents, nil
+}
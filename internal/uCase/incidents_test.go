@@ -0,0 +1,254 @@
+package uCase_test
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository"
+	mock_repository "github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository/mocks"
+	"github.com/Imm0bilize/gunshot-api-service/internal/uCase"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+type fakePublisher struct {
+	transitions []entities.IncidentTransition
+	alerts      []entities.Alert
+	maintenance []entities.MaintenanceEvent
+}
+
+func (f *fakePublisher) SendMaintenanceEvent(_ context.Context, _ uuid.UUID, event entities.MaintenanceEvent) error {
+	f.maintenance = append(f.maintenance, event)
+	return nil
+}
+
+func (f *fakePublisher) SendAlert(_ context.Context, _ uuid.UUID, alert entities.Alert) error {
+	f.alerts = append(f.alerts, alert)
+	return nil
+}
+
+func (f *fakePublisher) SendIncidentTransition(
+	_ context.Context, _ uuid.UUID, _ entities.Incident, transition entities.IncidentTransition,
+) error {
+	f.transitions = append(f.transitions, transition)
+	return nil
+}
+
+func TestIncidentCorrelate(t *testing.T) {
+	var (
+		now     = time.Date(2022, 12, 1, 22, 0, 0, 0, time.UTC)
+		nearby  = entities.Client{ID: primitive.NewObjectID(), Latitude: 55.7558, Longitude: 37.6173}
+		faraway = entities.Client{ID: primitive.NewObjectID(), Latitude: 59.9343, Longitude: 30.3351}
+		active  = entities.Incident{
+			ID:        primitive.NewObjectID(),
+			Latitude:  55.7560,
+			Longitude: 37.6175,
+			ShotCount: 1,
+			FirstSeen: now.Add(-10 * time.Second),
+			LastSeen:  now.Add(-10 * time.Second),
+			Clients:   []primitive.ObjectID{primitive.NewObjectID()},
+		}
+		merged = entities.Incident{
+			ID:        active.ID,
+			Latitude:  (active.Latitude + nearby.Latitude) / 2,
+			Longitude: (active.Longitude + nearby.Longitude) / 2,
+			ShotCount: 2,
+			FirstSeen: active.FirstSeen,
+			LastSeen:  now,
+			Clients:   append(active.Clients, nearby.ID),
+		}
+	)
+
+	testTable := []struct {
+		name          string
+		client        entities.Client
+		setMockOutput func(*mock_repository.MockIncidentRepository, *mock_repository.MockClientRepository)
+		checkIncident func(*testing.T, entities.Incident)
+		expErr        error
+	}{
+		{
+			name:   "detection joins the nearby incident",
+			client: nearby,
+			setMockOutput: func(incidents *mock_repository.MockIncidentRepository, clients *mock_repository.MockClientRepository) {
+				clients.EXPECT().Get(gomock.Any(), nearby.ID.Hex()).Return(nearby, nil).Times(1)
+				incidents.EXPECT().FindActive(gomock.Any(), now.Add(-time.Minute)).Return([]entities.Incident{active}, nil).Times(1)
+				incidents.EXPECT().Merge(gomock.Any(), active.ID, nearby, now).Return(merged, nil).Times(1)
+				incidents.EXPECT().SetZones(gomock.Any(), active.ID, 2, []primitive.ObjectID{}).Return(nil).Times(1)
+			},
+			checkIncident: func(t *testing.T, incident entities.Incident) {
+				require.Equal(t, active.ID, incident.ID)
+				require.Equal(t, 2, incident.ShotCount)
+				require.Equal(t, merged.Clients, incident.Clients)
+			},
+		},
+		{
+			name:   "incident closed since it was found",
+			client: nearby,
+			setMockOutput: func(incidents *mock_repository.MockIncidentRepository, clients *mock_repository.MockClientRepository) {
+				clients.EXPECT().Get(gomock.Any(), nearby.ID.Hex()).Return(nearby, nil).Times(1)
+				incidents.EXPECT().FindActive(gomock.Any(), now.Add(-time.Minute)).Return([]entities.Incident{active}, nil).Times(1)
+				incidents.EXPECT().Merge(gomock.Any(), active.ID, nearby, now).
+					Return(entities.Incident{}, repository.ErrIncidentNotFound).Times(1)
+				incidents.EXPECT().Create(gomock.Any(), gomock.Any()).Return(primitive.NewObjectID().Hex(), nil).Times(1)
+			},
+			checkIncident: func(t *testing.T, incident entities.Incident) {
+				require.Equal(t, 1, incident.ShotCount)
+				require.Equal(t, []primitive.ObjectID{nearby.ID}, incident.Clients)
+			},
+		},
+		{
+			name:   "distant detection opens a new incident",
+			client: faraway,
+			setMockOutput: func(incidents *mock_repository.MockIncidentRepository, clients *mock_repository.MockClientRepository) {
+				clients.EXPECT().Get(gomock.Any(), faraway.ID.Hex()).Return(faraway, nil).Times(1)
+				incidents.EXPECT().FindActive(gomock.Any(), now.Add(-time.Minute)).Return([]entities.Incident{active}, nil).Times(1)
+				incidents.EXPECT().Create(gomock.Any(), gomock.Any()).Return(primitive.NewObjectID().Hex(), nil).Times(1)
+			},
+			checkIncident: func(t *testing.T, incident entities.Incident) {
+				require.Equal(t, 1, incident.ShotCount)
+				require.Equal(t, now, incident.FirstSeen)
+				require.Equal(t, []primitive.ObjectID{faraway.ID}, incident.Clients)
+				require.Equal(t, faraway.Latitude, incident.Latitude)
+			},
+		},
+		{
+			name:   "client not found",
+			client: nearby,
+			expErr: errors.New("can't get the client of the detection: the client is not found"),
+			setMockOutput: func(incidents *mock_repository.MockIncidentRepository, clients *mock_repository.MockClientRepository) {
+				clients.EXPECT().Get(gomock.Any(), nearby.ID.Hex()).Return(entities.Client{}, repository.ErrClientNotFound).Times(1)
+			},
+		},
+	}
+
+	for _, tCase := range testTable {
+		t.Run(tCase.name, func(t *testing.T) {
+			var (
+				ctrl      = gomock.NewController(t)
+				incidents = mock_repository.NewMockIncidentRepository(ctrl)
+				clients   = mock_repository.NewMockClientRepository(ctrl)
+				zones     = mock_repository.NewMockZoneRepository(ctrl)
+			)
+
+			tCase.setMockOutput(incidents, clients)
+			zones.EXPECT().List(gomock.Any()).Return([]entities.Zone{}, nil).AnyTimes()
+
+			useCase := uCase.NewIncidentUCase(
+				zap.NewExample(), incidents, clients, nil, zones, &fakePublisher{}, 500, time.Minute,
+			)
+			incident, err := useCase.Correlate(
+				context.Background(),
+				uuid.New(),
+				&entities.Detection{ClientID: tCase.client.ID, Label: entities.LabelGunshot, Timestamp: now},
+			)
+
+			if tCase.expErr != nil {
+				require.Equal(t, tCase.expErr.Error(), err.Error())
+			} else {
+				require.NoError(t, err)
+				tCase.checkIncident(t, incident)
+			}
+
+			ctrl.Finish()
+		})
+	}
+}
+
+func TestIncidentTransition(t *testing.T) {
+	id := primitive.NewObjectID()
+
+	testTable := []struct {
+		name          string
+		from          entities.IncidentStatus
+		to            entities.IncidentStatus
+		setMockOutput func(*mock_repository.MockIncidentRepository, *mock_repository.MockDetectionRepository)
+		expErr        error
+	}{
+		{
+			name: "acknowledge new incident",
+			from: entities.IncidentNew,
+			to:   entities.IncidentAcknowledged,
+			setMockOutput: func(incidents *mock_repository.MockIncidentRepository, _ *mock_repository.MockDetectionRepository) {
+				incidents.EXPECT().Transition(gomock.Any(), id.Hex(), gomock.Any()).Return(nil).Times(1)
+			},
+		},
+		{
+			name: "mark as false positive",
+			from: entities.IncidentDispatched,
+			to:   entities.IncidentFalsePositive,
+			setMockOutput: func(incidents *mock_repository.MockIncidentRepository, detections *mock_repository.MockDetectionRepository) {
+				incidents.EXPECT().Transition(gomock.Any(), id.Hex(), gomock.Any()).Return(nil).Times(1)
+				detections.EXPECT().MarkFalsePositive(gomock.Any(), id.Hex()).Return(nil).Times(1)
+			},
+		},
+		{
+			name:          "dispatch new incident",
+			from:          entities.IncidentNew,
+			to:            entities.IncidentDispatched,
+			setMockOutput: func(*mock_repository.MockIncidentRepository, *mock_repository.MockDetectionRepository) {},
+			expErr:        errors.New("the transition is not allowed: from 'new' to 'dispatched'"),
+		},
+		{
+			name:          "reopen resolved incident",
+			from:          entities.IncidentResolved,
+			to:            entities.IncidentAcknowledged,
+			setMockOutput: func(*mock_repository.MockIncidentRepository, *mock_repository.MockDetectionRepository) {},
+			expErr:        errors.New("the transition is not allowed: from 'resolved' to 'acknowledged'"),
+		},
+		{
+			name: "concurrent transition",
+			from: entities.IncidentNew,
+			to:   entities.IncidentAcknowledged,
+			setMockOutput: func(incidents *mock_repository.MockIncidentRepository, _ *mock_repository.MockDetectionRepository) {
+				incidents.EXPECT().Transition(gomock.Any(), id.Hex(), gomock.Any()).
+					Return(repository.ErrIncidentStatusChanged).Times(1)
+			},
+			expErr: errors.New(
+				"can't change the incident status: the incident status has been changed by someone else",
+			),
+		},
+	}
+
+	for _, tCase := range testTable {
+		t.Run(tCase.name, func(t *testing.T) {
+			var (
+				ctrl       = gomock.NewController(t)
+				incidents  = mock_repository.NewMockIncidentRepository(ctrl)
+				detections = mock_repository.NewMockDetectionRepository(ctrl)
+				publisher  = &fakePublisher{}
+			)
+
+			incidents.EXPECT().Get(gomock.Any(), id.Hex()).
+				Return(entities.Incident{ID: id, Status: tCase.from}, nil).Times(1)
+			tCase.setMockOutput(incidents, detections)
+
+			useCase := uCase.NewIncidentUCase(
+				zap.NewExample(), incidents, nil, detections, nil, publisher, 500, time.Minute,
+			)
+			incident, err := useCase.Transition(
+				context.Background(),
+				uuid.New(),
+				id.Hex(),
+				entities.IncidentTransition{To: tCase.to, Actor: "dispatcher"},
+			)
+
+			if tCase.expErr != nil {
+				require.Equal(t, tCase.expErr.Error(), err.Error())
+				require.Empty(t, publisher.transitions)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tCase.to, incident.Status)
+				require.Len(t, publisher.transitions, 1)
+				require.Equal(t, tCase.from, publisher.transitions[0].From)
+				require.Equal(t, "dispatcher", publisher.transitions[0].Actor)
+			}
+
+			ctrl.Finish()
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package uCase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/Imm0bilize/gunshot-api-service/internal/audit"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"time"
+)
+
+type OrganizationRepo interface {
+	Create(ctx context.Context, organization *entities.Organization) (string, error)
+	Get(ctx context.Context, id string) (entities.Organization, error)
+	GetByKeyHash(ctx context.Context, hash string) (entities.Organization, error)
+	List(ctx context.Context) ([]entities.Organization, error)
+	Update(ctx context.Context, id string, organization *entities.Organization) error
+	SetKeyHash(ctx context.Context, id string, hash string) error
+	ReserveSensors(ctx context.Context, id primitive.ObjectID, sensors, limit int) (bool, error)
+	ReleaseSensors(ctx context.Context, id primitive.ObjectID, sensors int) error
+	InitSensors(ctx context.Context, id primitive.ObjectID, count int64) (bool, error)
+}
+
+var (
+	ErrUnauthenticated = apperr.New(apperr.Unauthenticated, "the API key is invalid")
+	ErrQuotaExceeded   = apperr.New(apperr.PermissionDenied, "the quota of the organization is exceeded")
+)
+
+type Organization struct {
+	tracer           trace.Tracer
+	organizationRepo OrganizationRepo
+	logger           *zap.Logger
+}
+
+func NewOrganizationUCase(logger *zap.Logger, organizationRepo OrganizationRepo) *Organization {
+	return &Organization{
+		tracer:           otel.Tracer("uCase.Organization"),
+		organizationRepo: organizationRepo,
+		logger:           logger,
+	}
+}
+
+// Create saves the organization and returns its API key, the key is not stored and can't be got later
+func (o Organization) Create(
+	ctx context.Context, reqID uuid.UUID, organization *entities.Organization,
+) (string, string, error) {
+	ctx, span := o.tracer.Start(ctx, "uCase.Organization.Create")
+	defer span.End()
+
+	key, hash, err := newAPIKey()
+	if err != nil {
+		return "", "", err
+	}
+
+	organization.APIKeyHash = hash
+	organization.CreatedAt = time.Now().UTC()
+
+	id, err := o.organizationRepo.Create(ctx, organization)
+	if err != nil {
+		o.logger.Error(
+			"error during create new organization",
+			zap.String("reqID", reqID.String()),
+			zap.Error(err),
+		)
+
+		return "", "", errors.Wrap(err, "can't create new organization")
+	}
+
+	audit.SetTargetID(ctx, id)
+	auditChange(ctx, o.logger, reqID, nil, organization)
+
+	return id, key, nil
+}
+
+func (o Organization) Get(ctx context.Context, reqID uuid.UUID, id string) (entities.Organization, error) {
+	ctx, span := o.tracer.Start(ctx, "uCase.Organization.Get")
+	defer span.End()
+
+	organization, err := o.organizationRepo.Get(ctx, id)
+	if err != nil {
+		return entities.Organization{}, errors.Wrap(err, "can't get the organization")
+	}
+
+	return organization, nil
+}
+
+func (o Organization) List(ctx context.Context, reqID uuid.UUID) ([]entities.Organization, error) {
+	ctx, span := o.tracer.Start(ctx, "uCase.Organization.List")
+	defer span.End()
+
+	organizations, err := o.organizationRepo.List(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get the list of organizations")
+	}
+
+	return organizations, nil
+}
+
+func (o Organization) Update(
+	ctx context.Context, reqID uuid.UUID, id string, organization *entities.Organization,
+) error {
+	ctx, span := o.tracer.Start(ctx, "uCase.Organization.Update")
+	defer span.End()
+
+	if err := o.organizationRepo.Update(ctx, id, organization); err != nil {
+		return errors.Wrap(err, "can't update the organization")
+	}
+
+	return nil
+}
+
+// RotateKey replaces the API key of the organization, the old key stops working at once
+func (o Organization) RotateKey(ctx context.Context, reqID uuid.UUID, id string) (string, error) {
+	ctx, span := o.tracer.Start(ctx, "uCase.Organization.RotateKey")
+	defer span.End()
+
+	key, hash, err := newAPIKey()
+	if err != nil {
+		return "", err
+	}
+
+	if err := o.organizationRepo.SetKeyHash(ctx, id, hash); err != nil {
+		return "", errors.Wrap(err, "can't replace the API key")
+	}
+
+	return key, nil
+}
+
+// Authenticate returns the organization which owns the API key
+func (o Organization) Authenticate(ctx context.Context, key string) (entities.Organization, error) {
+	ctx, span := o.tracer.Start(ctx, "uCase.Organization.Authenticate")
+	defer span.End()
+
+	if key == "" {
+		return entities.Organization{}, ErrUnauthenticated
+	}
+
+	organization, err := o.organizationRepo.GetByKeyHash(ctx, hashAPIKey(key))
+	if err != nil {
+		if errors.Is(err, repository.ErrOrganizationNotFound) {
+			return entities.Organization{}, ErrUnauthenticated
+		}
+
+		return entities.Organization{}, errors.Wrap(err, "can't get the organization")
+	}
+
+	return organization, nil
+}
+
+func newAPIKey() (string, string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", errors.Wrap(err, "error during generate API key")
+	}
+
+	key := hex.EncodeToString(raw)
+
+	return key, hashAPIKey(key), nil
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
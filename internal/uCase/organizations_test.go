@@ -0,0 +1,196 @@
+package uCase_test
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository"
+	mock_repository "github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository/mocks"
+	"github.com/Imm0bilize/gunshot-api-service/internal/tenant"
+	"github.com/Imm0bilize/gunshot-api-service/internal/uCase"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+func TestOrganizationAuthenticate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		repo         = mock_repository.NewMockOrganizationRepository(ctrl)
+		organization = entities.Organization{ID: primitive.NewObjectID(), Name: "city"}
+		stored       entities.Organization
+	)
+
+	repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, o *entities.Organization) (string, error) {
+			stored = *o
+			return organization.ID.Hex(), nil
+		},
+	).Times(1)
+	repo.EXPECT().GetByKeyHash(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, hash string) (entities.Organization, error) {
+			if hash != stored.APIKeyHash {
+				return entities.Organization{}, repository.ErrOrganizationNotFound
+			}
+			return organization, nil
+		},
+	).Times(2)
+
+	useCase := uCase.NewOrganizationUCase(zap.NewNop(), repo)
+
+	_, key, err := useCase.Create(context.Background(), uuid.New(), &entities.Organization{Name: "city"})
+	require.NoError(t, err)
+	require.NotEqual(t, key, stored.APIKeyHash, "the key must not be stored as is")
+
+	got, err := useCase.Authenticate(context.Background(), key)
+	require.NoError(t, err)
+	require.Equal(t, organization, got)
+
+	_, err = useCase.Authenticate(context.Background(), "wrong")
+	require.ErrorIs(t, err, uCase.ErrUnauthenticated)
+
+	_, err = useCase.Authenticate(context.Background(), "")
+	require.ErrorIs(t, err, uCase.ErrUnauthenticated)
+}
+
+func TestClientCreateQuota(t *testing.T) {
+	testTable := []struct {
+		name        string
+		maxSensors  int
+		reserved    []bool
+		count       int64
+		initialized bool
+		createErr   error
+		expErr      error
+		expRelease  bool
+	}{
+		{name: "no limit", maxSensors: 0, reserved: []bool{true}},
+		{name: "under the limit", maxSensors: 3, reserved: []bool{true}},
+		{name: "limit reached", maxSensors: 3, reserved: []bool{false}, count: 3, expErr: uCase.ErrQuotaExceeded},
+		{
+			name:        "counter is set up from clients",
+			maxSensors:  3,
+			reserved:    []bool{false, true},
+			count:       2,
+			initialized: true,
+		},
+		{
+			name:       "failed create releases the place",
+			maxSensors: 3,
+			reserved:   []bool{true},
+			createErr:  errors.New("the database is down"),
+			expErr:     errors.New("the database is down"),
+			expRelease: true,
+		},
+	}
+
+	for _, tCase := range testTable {
+		t.Run(tCase.name, func(t *testing.T) {
+			var (
+				ctrl          = gomock.NewController(t)
+				clients       = mock_repository.NewMockClientRepository(ctrl)
+				zones         = mock_repository.NewMockZoneRepository(ctrl)
+				versions      = mock_repository.NewMockClientVersionRepository(ctrl)
+				organizations = mock_repository.NewMockOrganizationRepository(ctrl)
+				organization  = entities.Organization{
+					ID:    primitive.NewObjectID(),
+					Quota: entities.Quota{MaxSensors: tCase.maxSensors},
+				}
+				ctx = tenant.WithOrganization(context.Background(), organization)
+			)
+			defer ctrl.Finish()
+
+			zones.EXPECT().List(gomock.Any()).Return([]entities.Zone{}, nil).Times(1)
+
+			calls := make([]*gomock.Call, 0, len(tCase.reserved))
+			for _, reserved := range tCase.reserved {
+				calls = append(calls, organizations.EXPECT().
+					ReserveSensors(gomock.Any(), organization.ID, 1, tCase.maxSensors).Return(reserved, nil))
+			}
+			gomock.InOrder(calls...)
+
+			if !tCase.reserved[0] {
+				clients.EXPECT().Count(gomock.Any()).Return(tCase.count, nil).Times(1)
+				organizations.EXPECT().InitSensors(gomock.Any(), organization.ID, tCase.count).
+					Return(tCase.initialized, nil).Times(1)
+			}
+
+			if tCase.reserved[len(tCase.reserved)-1] {
+				clients.EXPECT().Create(gomock.Any(), gomock.Any()).
+					Return(primitive.NewObjectID().Hex(), tCase.createErr).Times(1)
+			}
+			if tCase.expErr == nil {
+				versions.EXPECT().Create(gomock.Any(), gomock.Any()).Return("", nil).Times(1)
+			}
+			if tCase.expRelease {
+				organizations.EXPECT().ReleaseSensors(gomock.Any(), organization.ID, 1).Return(nil).Times(1)
+			}
+
+			useCase := uCase.NewClientUCase(zap.NewNop(), clients, zones, versions, organizations, time.Hour)
+
+			_, err := useCase.Create(ctx, uuid.New(), &entities.Client{})
+			if tCase.expErr != nil {
+				require.ErrorContains(t, err, tCase.expErr.Error())
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAudioUploadRateLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		client    = entities.Client{ID: primitive.NewObjectID()}
+		limited   = entities.Organization{ID: primitive.NewObjectID(), Quota: entities.Quota{UploadsPerMinute: 2}}
+		other     = entities.Organization{ID: primitive.NewObjectID(), Quota: entities.Quota{UploadsPerMinute: 2}}
+		unlimited = entities.Organization{ID: primitive.NewObjectID()}
+	)
+
+	clients := mock_repository.NewMockClientRepository(ctrl)
+	clients.EXPECT().Get(gomock.Any(), client.ID.Hex()).Return(client, nil).AnyTimes()
+	clients.EXPECT().AdvanceChain(gomock.Any(), client.ID, gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	chain := mock_repository.NewMockChainRepository(ctrl)
+	chain.EXPECT().FindUpload(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(entities.ChainRecord{}, repository.ErrChainRecordNotFound).AnyTimes()
+	chain.EXPECT().Create(gomock.Any(), gomock.Any()).Return("", nil).AnyTimes()
+
+	// the counter is kept by the repository, so it's shared by instances of the service
+	uploads := make(map[primitive.ObjectID]int)
+	quota := mock_repository.NewMockQuotaRepository(ctrl)
+	quota.EXPECT().TakeUpload(gomock.Any(), gomock.Any(), gomock.Any(), 2).DoAndReturn(
+		func(_ context.Context, tenantID primitive.ObjectID, minute time.Time, limit int) (bool, error) {
+			require.WithinDuration(t, time.Now(), minute, time.Second)
+			if uploads[tenantID] >= limit {
+				return false, nil
+			}
+			uploads[tenantID]++
+			return true, nil
+		},
+	).Times(4)
+
+	audio := uCase.NewAudioUCase(zap.NewNop(), &fakeSender{}, clients, chain, newFakeBlobs(), quota, nil, 1000, false)
+
+	ctx := tenant.WithOrganization(context.Background(), limited)
+	for i := 0; i < 2; i++ {
+		require.NoError(t, audio.Upload(ctx, uuid.New(), client.ID.Hex(), entities.Message{}))
+	}
+	require.ErrorIs(t, audio.Upload(ctx, uuid.New(), client.ID.Hex(), entities.Message{}), uCase.ErrQuotaExceeded)
+
+	// counters are kept per tenant
+	ctx = tenant.WithOrganization(context.Background(), other)
+	require.NoError(t, audio.Upload(ctx, uuid.New(), client.ID.Hex(), entities.Message{}))
+
+	// uploads without the limit aren't counted
+	ctx = tenant.WithOrganization(context.Background(), unlimited)
+	require.NoError(t, audio.Upload(ctx, uuid.New(), client.ID.Hex(), entities.Message{}))
+}
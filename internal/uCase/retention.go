@@ -0,0 +1,371 @@
+package uCase
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository"
+	"github.com/Imm0bilize/gunshot-api-service/internal/tenant"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+const (
+	_sweepPage = 500
+	// _retentionCacheTTL is how long a change of the retention of the organization may be unnoticed
+	_retentionCacheTTL = time.Minute
+)
+
+type RetentionDetectionRepo interface {
+	Hold(ctx context.Context, incidentID primitive.ObjectID) error
+	Release(ctx context.Context, incidentID primitive.ObjectID, period time.Duration) error
+	CountExpiring(ctx context.Context, before time.Time) (int64, error)
+}
+
+type RetentionHeartbeatRepo interface {
+	CountExpiring(ctx context.Context, before time.Time) (int64, error)
+}
+
+type RetentionBlobRepo interface {
+	Expired(ctx context.Context, before time.Time, after string, limit int64) ([]entities.Blob, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type LegalHoldRepo interface {
+	Get(ctx context.Context, id string) (entities.Incident, error)
+	List(ctx context.Context, filter entities.IncidentFilter) ([]entities.Incident, error)
+	SetLegalHold(ctx context.Context, id primitive.ObjectID, hold *entities.LegalHold) error
+}
+
+// RetentionPolicy resolves the retention of the tenant of the context. The nil policy keeps everything
+type RetentionPolicy struct {
+	defaults         entities.Retention
+	organizationRepo OrganizationRepo
+
+	mu    sync.Mutex
+	cache map[primitive.ObjectID]cachedRetention
+	now   func() time.Time
+}
+
+type cachedRetention struct {
+	retention entities.Retention
+	loadedAt  time.Time
+}
+
+func NewRetentionPolicy(defaults entities.Retention, organizationRepo OrganizationRepo) *RetentionPolicy {
+	return &RetentionPolicy{
+		defaults:         defaults,
+		organizationRepo: organizationRepo,
+		cache:            make(map[primitive.ObjectID]cachedRetention),
+		now:              time.Now,
+	}
+}
+
+// Resolve returns the retention of the organization filled with defaults. Data coming from the broker
+// has only the tenant ID in the context, so the organization is loaded and cached for a while
+func (p *RetentionPolicy) Resolve(ctx context.Context) (entities.Retention, error) {
+	if p == nil {
+		return entities.Retention{}, nil
+	}
+
+	if organization, ok := tenant.Organization(ctx); ok {
+		return organization.Retention.Or(p.defaults), nil
+	}
+
+	tenantID, err := tenant.ID(ctx)
+	if err != nil {
+		return entities.Retention{}, err
+	}
+	if tenantID.IsZero() {
+		return p.defaults, nil
+	}
+
+	p.mu.Lock()
+	cached, ok := p.cache[tenantID]
+	p.mu.Unlock()
+	if ok && p.now().Sub(cached.loadedAt) < _retentionCacheTTL {
+		return cached.retention, nil
+	}
+
+	organization, err := p.organizationRepo.Get(tenant.System(ctx), tenantID.Hex())
+	if err != nil {
+		return entities.Retention{}, errors.Wrap(err, "can't get the organization")
+	}
+
+	retention := organization.Retention.Or(p.defaults)
+
+	p.mu.Lock()
+	p.cache[tenantID] = cachedRetention{retention: retention, loadedAt: p.now()}
+	p.mu.Unlock()
+
+	return retention, nil
+}
+
+// ExpiresAt returns when the data of the class created at the moment is removed, nil means never
+func (p *RetentionPolicy) ExpiresAt(
+	ctx context.Context, class entities.RetentionClass, createdAt time.Time,
+) (*time.Time, error) {
+	retention, err := p.Resolve(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't resolve the retention")
+	}
+
+	return retention.ExpiresAt(class, createdAt), nil
+}
+
+type Retention struct {
+	tracer        trace.Tracer
+	logger        *zap.Logger
+	policy        *RetentionPolicy
+	incidentRepo  LegalHoldRepo
+	detectionRepo RetentionDetectionRepo
+	heartbeatRepo RetentionHeartbeatRepo
+	blobRepo      RetentionBlobRepo
+	interval      time.Duration
+}
+
+// NewRetentionUCase creates the retention use case. Detections and heartbeats are removed by TTL indexes,
+// the audio by the sweep running every interval
+func NewRetentionUCase(
+	logger *zap.Logger,
+	policy *RetentionPolicy,
+	incidentRepo LegalHoldRepo,
+	detectionRepo RetentionDetectionRepo,
+	heartbeatRepo RetentionHeartbeatRepo,
+	blobRepo RetentionBlobRepo,
+	interval time.Duration,
+) *Retention {
+	return &Retention{
+		tracer:        otel.Tracer("uCase.Retention"),
+		logger:        logger,
+		policy:        policy,
+		incidentRepo:  incidentRepo,
+		detectionRepo: detectionRepo,
+		heartbeatRepo: heartbeatRepo,
+		blobRepo:      blobRepo,
+		interval:      interval,
+	}
+}
+
+// Hold places the incident under the legal hold. The incident is marked first, so detections correlated
+// after that are saved without the expiration; holding again updates the reason
+func (r Retention) Hold(
+	ctx context.Context, reqID uuid.UUID, incidentID string, hold entities.LegalHold,
+) (entities.Incident, error) {
+	ctx, span := r.tracer.Start(ctx, "uCase.Retention.Hold")
+	defer span.End()
+
+	before, err := r.incidentRepo.Get(ctx, incidentID)
+	if err != nil {
+		return entities.Incident{}, errors.Wrap(err, "can't get the incident")
+	}
+
+	hold.Since = time.Now().UTC()
+	if err := r.incidentRepo.SetLegalHold(ctx, before.ID, &hold); err != nil {
+		return entities.Incident{}, errors.Wrap(err, "can't hold the incident")
+	}
+
+	if err := r.detectionRepo.Hold(ctx, before.ID); err != nil {
+		return entities.Incident{}, errors.Wrap(err, "can't hold detections of the incident")
+	}
+
+	after := before
+	after.LegalHold = &hold
+	auditChange(ctx, r.logger, reqID, before, after)
+
+	r.logger.Info(
+		"the incident is placed under the legal hold",
+		zap.String("reqID", reqID.String()),
+		zap.String("incidentID", incidentID),
+	)
+
+	return after, nil
+}
+
+// Release lifts the legal hold, detections expire again by the retention counted from their timestamp
+func (r Retention) Release(ctx context.Context, reqID uuid.UUID, incidentID string) (entities.Incident, error) {
+	ctx, span := r.tracer.Start(ctx, "uCase.Retention.Release")
+	defer span.End()
+
+	before, err := r.incidentRepo.Get(ctx, incidentID)
+	if err != nil {
+		return entities.Incident{}, errors.Wrap(err, "can't get the incident")
+	}
+
+	retention, err := r.policy.Resolve(ctx)
+	if err != nil {
+		return entities.Incident{}, errors.Wrap(err, "can't resolve the retention")
+	}
+
+	if err := r.incidentRepo.SetLegalHold(ctx, before.ID, nil); err != nil {
+		return entities.Incident{}, errors.Wrap(err, "can't release the incident")
+	}
+
+	if err := r.detectionRepo.Release(ctx, before.ID, retention.Period(entities.RetentionDetections)); err != nil {
+		return entities.Incident{}, errors.Wrap(err, "can't release detections of the incident")
+	}
+
+	after := before
+	after.LegalHold = nil
+	auditChange(ctx, r.logger, reqID, before, after)
+
+	return after, nil
+}
+
+// Report is the dry run of the retention of the tenant: what expires before the next sweep
+func (r Retention) Report(ctx context.Context, reqID uuid.UUID) (entities.RetentionReport, error) {
+	ctx, span := r.tracer.Start(ctx, "uCase.Retention.Report")
+	defer span.End()
+
+	retention, err := r.policy.Resolve(ctx)
+	if err != nil {
+		return entities.RetentionReport{}, errors.Wrap(err, "can't resolve the retention")
+	}
+
+	now := time.Now().UTC()
+	report := entities.RetentionReport{GeneratedAt: now, Until: now.Add(r.interval), Retention: retention}
+
+	audio, err := r.sweep(ctx, report.Until, true)
+	if err != nil {
+		return entities.RetentionReport{}, err
+	}
+
+	detections, err := r.detectionRepo.CountExpiring(ctx, report.Until)
+	if err != nil {
+		return entities.RetentionReport{}, errors.Wrap(err, "can't count expiring detections")
+	}
+
+	heartbeats, err := r.heartbeatRepo.CountExpiring(ctx, report.Until)
+	if err != nil {
+		return entities.RetentionReport{}, errors.Wrap(err, "can't count expiring heartbeats")
+	}
+
+	report.Classes = []entities.RetentionClassReport{
+		audio,
+		{Class: entities.RetentionDetections, Count: detections},
+		{Class: entities.RetentionHeartbeats, Count: heartbeats},
+	}
+
+	return report, nil
+}
+
+// Sweep removes the expired audio of all tenants except the audio of incidents under the legal hold
+func (r Retention) Sweep(ctx context.Context) error {
+	ctx, span := r.tracer.Start(ctx, "uCase.Retention.Sweep")
+	defer span.End()
+
+	removed, err := r.sweep(tenant.System(ctx), time.Now().UTC(), false)
+	if err != nil {
+		return err
+	}
+
+	if removed.Count > 0 || removed.Held > 0 {
+		r.logger.Info(
+			"expired audio is removed",
+			zap.Int64("count", removed.Count),
+			zap.Int64("bytes", removed.Bytes),
+			zap.Int64("held", removed.Held),
+		)
+	}
+
+	return nil
+}
+
+// Monitor sweeps the audio every interval until the context is done
+func (r Retention) Monitor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Sweep(ctx); err != nil {
+				r.logger.Error("error during sweep the audio", zap.Error(err))
+			}
+		}
+	}
+}
+
+// sweep walks the audio expiring before the moment and removes it unless it's the dry run
+func (r Retention) sweep(ctx context.Context, before time.Time, dryRun bool) (entities.RetentionClassReport, error) {
+	report := entities.RetentionClassReport{Class: entities.RetentionAudio}
+
+	held, err := r.heldWindows(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	var after string
+	for {
+		blobs, err := r.blobRepo.Expired(ctx, before, after, _sweepPage)
+		if err != nil {
+			return report, errors.Wrap(err, "can't get the expired audio")
+		}
+
+		for _, blob := range blobs {
+			if held.covers(blob.ClientID, blob.Timestamp) {
+				report.Held++
+				continue
+			}
+
+			if !dryRun {
+				err := r.blobRepo.Delete(ctx, blob.Key)
+				if errors.Is(err, repository.ErrBlobNotFound) {
+					continue
+				}
+				if err != nil {
+					return report, errors.Wrap(err, "can't remove the expired audio")
+				}
+			}
+
+			report.Count++
+			report.Bytes += blob.Size
+		}
+
+		if len(blobs) < _sweepPage {
+			return report, nil
+		}
+		after = blobs[len(blobs)-1].Key
+	}
+}
+
+// holdWindows are time ranges of the audio of every client which belong to incidents under the legal hold
+type holdWindows map[primitive.ObjectID][][2]time.Time
+
+func (h holdWindows) covers(clientID primitive.ObjectID, ts time.Time) bool {
+	for _, window := range h[clientID] {
+		if !ts.Before(window[0]) && !ts.After(window[1]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// heldWindows covers the same audio the evidence bundle of the incident is made of
+func (r Retention) heldWindows(ctx context.Context) (holdWindows, error) {
+	incidents, err := r.incidentRepo.List(ctx, entities.IncidentFilter{Held: true})
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get incidents under the legal hold")
+	}
+
+	windows := make(holdWindows)
+	for _, incident := range incidents {
+		window := [2]time.Time{
+			incident.FirstSeen.Add(-_evidencePadding),
+			incident.LastSeen.Add(_evidencePadding),
+		}
+		for _, clientID := range incident.Clients {
+			windows[clientID] = append(windows[clientID], window)
+		}
+	}
+
+	return windows, nil
+}
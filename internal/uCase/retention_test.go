@@ -0,0 +1,141 @@
+package uCase_test
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	mock_repository "github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository/mocks"
+	"github.com/Imm0bilize/gunshot-api-service/internal/tenant"
+	"github.com/Imm0bilize/gunshot-api-service/internal/uCase"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+func TestRetentionPolicyResolve(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		defaults     = entities.Retention{AudioDays: 30, DetectionDays: 365, HeartbeatDays: 7}
+		organization = entities.Organization{ID: primitive.NewObjectID(), Retention: entities.Retention{AudioDays: 1}}
+		expected     = entities.Retention{AudioDays: 1, DetectionDays: 365, HeartbeatDays: 7}
+	)
+
+	// detections come from the broker with the tenant ID only, the organization is loaded once
+	organizations := mock_repository.NewMockOrganizationRepository(ctrl)
+	organizations.EXPECT().Get(gomock.Any(), organization.ID.Hex()).Return(organization, nil).Times(1)
+
+	policy := uCase.NewRetentionPolicy(defaults, organizations)
+
+	got, err := policy.Resolve(tenant.WithOrganization(context.Background(), organization))
+	require.NoError(t, err)
+	require.Equal(t, expected, got)
+
+	for i := 0; i < 2; i++ {
+		got, err = policy.Resolve(tenant.WithID(context.Background(), organization.ID))
+		require.NoError(t, err)
+		require.Equal(t, expected, got)
+	}
+
+	got, err = policy.Resolve(tenant.System(context.Background()))
+	require.NoError(t, err)
+	require.Equal(t, defaults, got)
+
+	_, err = policy.Resolve(context.Background())
+	require.ErrorIs(t, err, tenant.ErrNoTenant)
+
+	createdAt := time.Date(2022, 12, 1, 22, 0, 0, 0, time.UTC)
+	expiresAt, err := policy.ExpiresAt(
+		tenant.WithOrganization(context.Background(), organization), entities.RetentionAudio, createdAt,
+	)
+	require.NoError(t, err)
+	require.Equal(t, createdAt.Add(24*time.Hour), *expiresAt)
+
+	// the nil policy keeps everything
+	var keep *uCase.RetentionPolicy
+	expiresAt, err = keep.ExpiresAt(context.Background(), entities.RetentionAudio, createdAt)
+	require.NoError(t, err)
+	require.Nil(t, expiresAt)
+}
+
+func TestRetentionSweepSkipsHeldAudio(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		shot     = time.Date(2022, 12, 1, 22, 0, 0, 0, time.UTC)
+		clientID = primitive.NewObjectID()
+		held     = entities.Blob{Key: "audio/held", ClientID: clientID, Timestamp: shot.Add(5 * time.Second), Size: 10}
+		expired  = entities.Blob{Key: "audio/expired", ClientID: clientID, Timestamp: shot.Add(time.Hour), Size: 20}
+	)
+
+	incidents := mock_repository.NewMockIncidentRepository(ctrl)
+	incidents.EXPECT().List(gomock.Any(), entities.IncidentFilter{Held: true}).Return([]entities.Incident{{
+		FirstSeen: shot,
+		LastSeen:  shot,
+		Clients:   []primitive.ObjectID{clientID},
+		LegalHold: &entities.LegalHold{Reason: "court order"},
+	}}, nil).Times(2)
+
+	blobs := mock_repository.NewMockBlobRepository(ctrl)
+	blobs.EXPECT().Expired(gomock.Any(), gomock.Any(), "", gomock.Any()).Return(
+		[]entities.Blob{expired, held}, nil,
+	).Times(2)
+	blobs.EXPECT().Delete(gomock.Any(), expired.Key).Return(nil).Times(1)
+
+	detections := mock_repository.NewMockDetectionRepository(ctrl)
+	detections.EXPECT().CountExpiring(gomock.Any(), gomock.Any()).Return(int64(3), nil)
+
+	heartbeats := mock_repository.NewMockHeartbeatRepository(ctrl)
+	heartbeats.EXPECT().CountExpiring(gomock.Any(), gomock.Any()).Return(int64(4), nil)
+
+	retention := uCase.NewRetentionUCase(
+		zap.NewNop(), nil, incidents, detections, heartbeats, blobs, time.Hour,
+	)
+
+	// the dry run removes nothing
+	report, err := retention.Report(tenant.System(context.Background()), uuid.New())
+	require.NoError(t, err)
+	require.Equal(t, []entities.RetentionClassReport{
+		{Class: entities.RetentionAudio, Count: 1, Bytes: 20, Held: 1},
+		{Class: entities.RetentionDetections, Count: 3},
+		{Class: entities.RetentionHeartbeats, Count: 4},
+	}, report.Classes)
+
+	require.NoError(t, retention.Sweep(context.Background()))
+}
+
+func TestRetentionHold(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	incident := entities.Incident{ID: primitive.NewObjectID()}
+
+	incidents := mock_repository.NewMockIncidentRepository(ctrl)
+	incidents.EXPECT().Get(gomock.Any(), incident.ID.Hex()).Return(incident, nil).Times(2)
+
+	detections := mock_repository.NewMockDetectionRepository(ctrl)
+	gomock.InOrder(
+		incidents.EXPECT().SetLegalHold(gomock.Any(), incident.ID, gomock.Not(gomock.Nil())).Return(nil),
+		detections.EXPECT().Hold(gomock.Any(), incident.ID).Return(nil),
+		incidents.EXPECT().SetLegalHold(gomock.Any(), incident.ID, gomock.Nil()).Return(nil),
+		detections.EXPECT().Release(gomock.Any(), incident.ID, 365*24*time.Hour).Return(nil),
+	)
+
+	policy := uCase.NewRetentionPolicy(entities.Retention{DetectionDays: 365}, nil)
+	retention := uCase.NewRetentionUCase(zap.NewNop(), policy, incidents, detections, nil, nil, time.Hour)
+
+	ctx := tenant.System(context.Background())
+
+	got, err := retention.Hold(ctx, uuid.New(), incident.ID.Hex(), entities.LegalHold{Reason: "court order"})
+	require.NoError(t, err)
+	require.Equal(t, "court order", got.LegalHold.Reason)
+
+	got, err = retention.Release(ctx, uuid.New(), incident.ID.Hex())
+	require.NoError(t, err)
+	require.Nil(t, got.LegalHold)
+}
@@ -2,43 +2,386 @@ package uCase
 
 import (
 	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/clientio"
 	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
 	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository"
+	"github.com/Imm0bilize/gunshot-api-service/internal/signing"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
+	"io"
+	"time"
 )
 
 var (
-	_ ClientUseCase = Client{}
-	_ AudioUseCase  = Audio{}
+	_ ClientUseCase       = Client{}
+	_ AudioUseCase        = Audio{}
+	_ IncidentUseCase     = Incident{}
+	_ DetectionUseCase    = Detection{}
+	_ AlertRuleUseCase    = AlertRule{}
+	_ AlertUseCase        = Alert{}
+	_ ZoneUseCase         = Zone{}
+	_ FleetUseCase        = Fleet{}
+	_ ConfigUseCase       = Config{}
+	_ CommandUseCase      = Command{}
+	_ ClockUseCase        = Clock{}
+	_ OrganizationUseCase = Organization{}
+	_ AuditUseCase        = Audit{}
+	_ DeviceKeyUseCase    = DeviceKey{}
+	_ EvidenceUseCase     = Evidence{}
+	_ RetentionUseCase    = Retention{}
+	_ KeyRotationUseCase  = KeyRotation{}
+	_ SubjectUseCase      = Subject{}
+	_ IdempotencyUseCase  = Idempotency{}
+	_ WebhookUseCase      = Webhook{}
+	_ DeadLetterUseCase   = DeadLetter{}
 )
 
 type ClientUseCase interface {
 	Create(ctx context.Context, reqID uuid.UUID, client *entities.Client) (string, error)
 	Get(ctx context.Context, reqID uuid.UUID, id string) (entities.Client, error)
-	Update(ctx context.Context, reqID uuid.UUID, id string, client *entities.Client) error
-	Delete(ctx context.Context, reqID uuid.UUID, id string) error
+	List(ctx context.Context, reqID uuid.UUID, filter entities.ClientFilter) ([]entities.Client, error)
+	Update(
+		ctx context.Context, reqID uuid.UUID, id string, version int64, client *entities.Client,
+	) (entities.Client, error)
+	Patch(
+		ctx context.Context, reqID uuid.UUID, id string, version int64, patch entities.ClientPatch,
+	) (entities.Client, error)
+	Delete(ctx context.Context, reqID uuid.UUID, id string, version int64) error
+	Import(
+		ctx context.Context, reqID uuid.UUID, format clientio.Format, r io.Reader, dryRun bool,
+	) (entities.ClientImport, error)
+	Export(ctx context.Context, reqID uuid.UUID, format clientio.Format, w io.Writer) error
+	Restore(ctx context.Context, reqID uuid.UUID, id string) (entities.Client, error)
+	History(ctx context.Context, reqID uuid.UUID, id string) ([]entities.ClientVersion, error)
+	Purge(ctx context.Context) error
+	Monitor(ctx context.Context, interval time.Duration)
 }
 
 type AudioUseCase interface {
 	Upload(ctx context.Context, reqID uuid.UUID, id string, msg entities.Message) error
+	Verify(
+		ctx context.Context, reqID uuid.UUID, clientID string, from, to time.Time,
+	) (entities.ChainVerification, error)
+}
+
+type IncidentUseCase interface {
+	Correlate(ctx context.Context, reqID uuid.UUID, detection *entities.Detection) (entities.Incident, error)
+	Transition(
+		ctx context.Context, reqID uuid.UUID, id string, transition entities.IncidentTransition,
+	) (entities.Incident, error)
+	Get(ctx context.Context, reqID uuid.UUID, id string) (entities.Incident, error)
+	List(ctx context.Context, reqID uuid.UUID, filter entities.IncidentFilter) ([]entities.Incident, error)
+}
+
+type DetectionUseCase interface {
+	Process(ctx context.Context, reqID uuid.UUID, detection *entities.Detection) error
+	List(ctx context.Context, reqID uuid.UUID, filter entities.DetectionFilter) ([]entities.Detection, error)
+}
+
+type AlertRuleUseCase interface {
+	Create(ctx context.Context, reqID uuid.UUID, rule *entities.AlertRule) (string, error)
+	Get(ctx context.Context, reqID uuid.UUID, id string) (entities.AlertRule, error)
+	Update(ctx context.Context, reqID uuid.UUID, id string, rule *entities.AlertRule) error
+	Delete(ctx context.Context, reqID uuid.UUID, id string) error
+	List(ctx context.Context, reqID uuid.UUID) ([]entities.AlertRule, error)
+	DryRun(ctx context.Context, reqID uuid.UUID, rule entities.AlertRule, days int) (DryRunResult, error)
+}
+
+type AlertUseCase interface {
+	Evaluate(ctx context.Context, reqID uuid.UUID, detection *entities.Detection) ([]entities.Alert, error)
+	List(ctx context.Context, reqID uuid.UUID, filter entities.AlertFilter) ([]entities.Alert, error)
+}
+
+type ZoneUseCase interface {
+	Create(ctx context.Context, reqID uuid.UUID, zone *entities.Zone) (string, error)
+	Get(ctx context.Context, reqID uuid.UUID, id string) (entities.Zone, error)
+	Update(ctx context.Context, reqID uuid.UUID, id string, zone *entities.Zone) error
+	Delete(ctx context.Context, reqID uuid.UUID, id string) error
+	List(ctx context.Context, reqID uuid.UUID) ([]entities.Zone, error)
+}
+
+type FleetUseCase interface {
+	Heartbeat(ctx context.Context, reqID uuid.UUID, clientID string, heartbeat *entities.Heartbeat) error
+	Heartbeats(ctx context.Context, reqID uuid.UUID, filter entities.HeartbeatFilter) ([]entities.Heartbeat, error)
+	Health(ctx context.Context, reqID uuid.UUID) (entities.FleetHealth, error)
+	Check(ctx context.Context) error
+	Monitor(ctx context.Context, interval time.Duration)
+}
+
+type ConfigUseCase interface {
+	SetClientConfig(
+		ctx context.Context, reqID uuid.UUID, clientID string, config entities.SensorConfig,
+	) (entities.ConfigDocument, error)
+	SetZoneConfig(
+		ctx context.Context, reqID uuid.UUID, zoneID string, config entities.SensorConfig,
+	) (entities.ConfigDocument, error)
+	GetZoneConfig(ctx context.Context, reqID uuid.UUID, zoneID string) (entities.ConfigDocument, error)
+	Effective(ctx context.Context, reqID uuid.UUID, clientID string) (entities.EffectiveConfig, error)
+	Wait(
+		ctx context.Context, reqID uuid.UUID, clientID, etag string, timeout time.Duration,
+	) (entities.EffectiveConfig, error)
+	Applied(ctx context.Context, reqID uuid.UUID, clientID string, version int64) error
+}
+
+type CommandUseCase interface {
+	Enqueue(ctx context.Context, reqID uuid.UUID, clientID string, command *entities.Command) (string, error)
+	Get(ctx context.Context, reqID uuid.UUID, clientID, id string) (entities.Command, error)
+	List(ctx context.Context, reqID uuid.UUID, filter entities.CommandFilter) ([]entities.Command, error)
+	Next(ctx context.Context, reqID uuid.UUID, clientID string, timeout time.Duration) ([]entities.Command, error)
+	Acknowledge(ctx context.Context, reqID uuid.UUID, clientID, id string) (entities.Command, error)
+	Complete(
+		ctx context.Context, reqID uuid.UUID, clientID, id string, result entities.CommandResult,
+	) (entities.Command, error)
+	Expire(ctx context.Context) error
+	Monitor(ctx context.Context, interval time.Duration)
+}
+
+type ClockUseCase interface {
+	Sync(
+		ctx context.Context,
+		reqID uuid.UUID,
+		clientID string,
+		clientSend, serverReceive time.Time,
+		previous *entities.ClockSample,
+	) (entities.ClockSample, entities.ClockState, error)
+}
+
+type OrganizationUseCase interface {
+	Create(ctx context.Context, reqID uuid.UUID, organization *entities.Organization) (string, string, error)
+	Get(ctx context.Context, reqID uuid.UUID, id string) (entities.Organization, error)
+	List(ctx context.Context, reqID uuid.UUID) ([]entities.Organization, error)
+	Update(ctx context.Context, reqID uuid.UUID, id string, organization *entities.Organization) error
+	RotateKey(ctx context.Context, reqID uuid.UUID, id string) (string, error)
+	Authenticate(ctx context.Context, key string) (entities.Organization, error)
+}
+
+type AuditUseCase interface {
+	Record(ctx context.Context, reqID uuid.UUID, entry *entities.AuditEntry) error
+	Complete(ctx context.Context, reqID uuid.UUID, entry *entities.AuditEntry) error
+	List(ctx context.Context, reqID uuid.UUID, filter entities.AuditFilter) ([]entities.AuditEntry, error)
+	Export(ctx context.Context, reqID uuid.UUID, filter entities.AuditFilter, w io.Writer) error
+}
+
+type DeviceKeyUseCase interface {
+	Register(
+		ctx context.Context, reqID uuid.UUID, clientID string, publicKey []byte, overlap *time.Duration,
+	) (entities.DeviceKey, error)
+	List(ctx context.Context, reqID uuid.UUID, clientID string) ([]entities.DeviceKey, error)
+	Revoke(ctx context.Context, reqID uuid.UUID, clientID, keyID string) error
+}
+
+type EvidenceUseCase interface {
+	Export(ctx context.Context, reqID uuid.UUID, incidentID string, w io.Writer) error
+}
+
+type RetentionUseCase interface {
+	Hold(ctx context.Context, reqID uuid.UUID, incidentID string, hold entities.LegalHold) (entities.Incident, error)
+	Release(ctx context.Context, reqID uuid.UUID, incidentID string) (entities.Incident, error)
+	Report(ctx context.Context, reqID uuid.UUID) (entities.RetentionReport, error)
+	Sweep(ctx context.Context) error
+	Monitor(ctx context.Context, interval time.Duration)
+}
+
+type KeyRotationUseCase interface {
+	Rewrap(ctx context.Context, reqID uuid.UUID) (RewrapResult, error)
+	Monitor(ctx context.Context, interval time.Duration)
+}
+
+type SubjectUseCase interface {
+	Export(ctx context.Context, reqID uuid.UUID, clientID string, w io.Writer) error
+	Erase(ctx context.Context, reqID uuid.UUID, clientID string) (entities.Tombstone, error)
+}
+
+type IdempotencyUseCase interface {
+	Begin(
+		ctx context.Context, reqID uuid.UUID, request *entities.IdempotentRequest,
+	) (entities.IdempotentRequest, bool, error)
+	Complete(ctx context.Context, reqID uuid.UUID, request *entities.IdempotentRequest) error
+	Hold(ctx context.Context, reqID uuid.UUID, request *entities.IdempotentRequest)
+	Release(ctx context.Context, reqID uuid.UUID, request *entities.IdempotentRequest) error
+}
+
+type WebhookUseCase interface {
+	Create(ctx context.Context, reqID uuid.UUID, webhook *entities.Webhook) (string, error)
+	Get(ctx context.Context, reqID uuid.UUID, id string) (entities.Webhook, error)
+	List(ctx context.Context, reqID uuid.UUID) ([]entities.Webhook, error)
+	Delete(ctx context.Context, reqID uuid.UUID, id string) error
+}
+
+type DeadLetterUseCase interface {
+	Replay(
+		ctx context.Context, reqID uuid.UUID, replay entities.DeadLetterReplay,
+	) (entities.DeadLetterReplayResult, error)
 }
 
 type UseCase struct {
-	Client ClientUseCase
-	Audio  AudioUseCase
+	Client       ClientUseCase
+	Audio        AudioUseCase
+	Incident     IncidentUseCase
+	Detection    DetectionUseCase
+	AlertRule    AlertRuleUseCase
+	Alert        AlertUseCase
+	Zone         ZoneUseCase
+	Fleet        FleetUseCase
+	Config       ConfigUseCase
+	Command      CommandUseCase
+	Clock        ClockUseCase
+	Organization OrganizationUseCase
+	Audit        AuditUseCase
+	DeviceKey    DeviceKeyUseCase
+	Evidence     EvidenceUseCase
+	Retention    RetentionUseCase
+	KeyRotation  KeyRotationUseCase
+	Subject      SubjectUseCase
+	Idempotency  IdempotencyUseCase
+	Webhook      WebhookUseCase
+	DeadLetter   DeadLetterUseCase
+}
+
+type Publisher interface {
+	IncidentPublisher
+	AlertPublisher
+	MaintenancePublisher
 }
 
 type Params struct {
-	Logger      *zap.Logger
-	Repo        *repository.Repo
-	AudioSender Sender
-	AudioLength int
+	Logger         *zap.Logger
+	Repo           *repository.Repo
+	AudioSender    Sender
+	Publisher      Publisher
+	AudioLength    int
+	IncidentRadius float64
+	IncidentWindow time.Duration
+	DegradedAfter  time.Duration
+	OfflineAfter   time.Duration
+	CommandTTL     time.Duration
+	CommandAck     time.Duration
+	ClockMaxSkew   time.Duration
+	ClockMaxDrift  float64
+	AuditSigner    *signing.Signer
+	// SignatureRequired rejects unsigned uploads of clients without device keys too
+	SignatureRequired bool
+	KeyOverlap        time.Duration
+	// Retention is the default of organizations which have not set their own, SweepInterval is the period
+	// of the sweep of the expired audio
+	Retention     entities.Retention
+	SweepInterval time.Duration
+	// DeleteGrace is the time deleted clients may be restored before they are purged
+	DeleteGrace time.Duration
+	// EncryptedBlobs is the store of the audio when the encryption is enabled
+	EncryptedBlobs RewrapRepo
+	// WebhookSender delivers events to webhooks of organizations
+	WebhookSender WebhookSender
+	// DeadLetters is the dead-letter topic of detections, nil when it's not configured
+	DeadLetters DeadLetterQueue
 }
 
 func NewUseCase(params Params) (*UseCase, error) {
+	retention := NewRetentionPolicy(params.Retention, params.Repo.Organization)
+
+	webhook := NewWebhookUCase(params.Logger, params.Repo.Webhook, params.WebhookSender)
+	publisher := webhookPublisher{Publisher: params.Publisher, webhooks: webhook}
+
+	incident := NewIncidentUCase(
+		params.Logger,
+		params.Repo.Incident,
+		params.Repo.Client,
+		params.Repo.Detection,
+		params.Repo.Zone,
+		publisher,
+		params.IncidentRadius,
+		params.IncidentWindow,
+	)
+
+	alert := NewAlertUCase(
+		params.Logger, params.Repo.Alert, params.Repo.AlertRule, params.Repo.Detection, publisher,
+	)
+
 	return &UseCase{
-		Client: NewClientUCase(params.Logger, params.Repo.Client),
-		Audio:  NewAudioUCase(params.Logger, params.AudioSender, params.AudioLength),
+		Client: NewClientUCase(
+			params.Logger,
+			params.Repo.Client,
+			params.Repo.Zone,
+			params.Repo.ClientVersion,
+			params.Repo.Organization,
+			params.DeleteGrace,
+		),
+		Audio: NewAudioUCase(
+			params.Logger,
+			params.AudioSender,
+			params.Repo.Client,
+			params.Repo.Chain,
+			params.Repo.Blob,
+			params.Repo.Quota,
+			retention,
+			params.AudioLength,
+			params.SignatureRequired,
+		),
+		Incident: incident,
+		Detection: NewDetectionUCase(
+			params.Logger, params.Repo.Detection, params.Repo.Client, params.Repo.Erasure, incident, alert, retention,
+		),
+		AlertRule: NewAlertRuleUCase(params.Logger, params.Repo.AlertRule, params.Repo.Detection),
+		Alert:     alert,
+		Zone:      NewZoneUCase(params.Logger, params.Repo.Zone, params.Repo.Client),
+		Fleet: NewFleetUCase(
+			params.Logger,
+			params.Repo.Heartbeat,
+			params.Repo.Client,
+			params.Publisher,
+			retention,
+			params.DegradedAfter,
+			params.OfflineAfter,
+		),
+		Config: NewConfigUCase(params.Logger, params.Repo.Config, params.Repo.Client, params.Repo.Zone),
+		Command: NewCommandUCase(
+			params.Logger, params.Repo.Command, params.Repo.Client, params.CommandTTL, params.CommandAck,
+		),
+		Clock:        NewClockUCase(params.Logger, params.Repo.Client, params.ClockMaxSkew, params.ClockMaxDrift),
+		Organization: NewOrganizationUCase(params.Logger, params.Repo.Organization),
+		Audit:        NewAuditUCase(params.Logger, params.Repo.Audit, params.AuditSigner),
+		DeviceKey:    NewDeviceKeyUCase(params.Logger, params.Repo.Client, params.KeyOverlap),
+		Evidence: NewEvidenceUCase(
+			params.Logger,
+			params.Repo.Incident,
+			params.Repo.Client,
+			params.Repo.Detection,
+			params.Repo.Chain,
+			params.Repo.Blob,
+			params.Repo.Audit,
+			params.AuditSigner,
+		),
+		Retention: NewRetentionUCase(
+			params.Logger,
+			retention,
+			params.Repo.Incident,
+			params.Repo.Detection,
+			params.Repo.Heartbeat,
+			params.Repo.Blob,
+			params.SweepInterval,
+		),
+		KeyRotation: NewKeyRotationUCase(params.Logger, params.EncryptedBlobs),
+		Subject: NewSubjectUCase(
+			params.Logger,
+			SubjectRepos{
+				Client:        params.Repo.Client,
+				ClientVersion: params.Repo.ClientVersion,
+				Incident:      params.Repo.Incident,
+				Detection:     params.Repo.Detection,
+				Heartbeat:     params.Repo.Heartbeat,
+				Command:       params.Repo.Command,
+				Config:        params.Repo.Config,
+				Alert:         params.Repo.Alert,
+				AlertRule:     params.Repo.AlertRule,
+				Audit:         params.Repo.Audit,
+				Chain:         params.Repo.Chain,
+				Blob:          params.Repo.Blob,
+				Erasure:       params.Repo.Erasure,
+			},
+			params.AuditSigner,
+		),
+		Idempotency: NewIdempotencyUCase(params.Logger, params.Repo.Idempotency),
+		Webhook:     webhook,
+		DeadLetter:  NewDeadLetterUCase(params.Logger, params.DeadLetters),
 	}, nil
 }
@@ -0,0 +1,297 @@
+package uCase
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository"
+	"github.com/Imm0bilize/gunshot-api-service/internal/signing"
+	"github.com/Imm0bilize/gunshot-api-service/pkg/evidence"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"io"
+	"time"
+)
+
+// files of the subject export besides the ones of the evidence bundle
+const (
+	SubjectHeartbeatsFile = "heartbeats.json"
+	SubjectCommandsFile   = "commands.json"
+	SubjectConfigFile     = "config.json"
+	SubjectAlertsFile     = "alerts.json"
+	SubjectRulesFile      = "rules.json"
+	SubjectIncidentsFile  = "incidents.json"
+	SubjectHistoryFile    = "history.json"
+)
+
+type ErasureRepo interface {
+	Tombstone(ctx context.Context, tombstone *entities.Tombstone) error
+	Erased(ctx context.Context, clientID primitive.ObjectID) (bool, error)
+	Erase(ctx context.Context, clientID primitive.ObjectID, hold entities.ErasureHold) (entities.ErasureReport, error)
+}
+
+// SubjectRepos are stores of everything the service keeps about the client
+type SubjectRepos struct {
+	Client        ClientRepo
+	ClientVersion ClientVersionRepo
+	Incident      IncidentRepo
+	Detection     DetectionRepo
+	Heartbeat     HeartbeatRepo
+	Command       CommandRepo
+	Config        ConfigRepo
+	Alert         AlertRepo
+	AlertRule     AlertRuleRepo
+	Audit         AuditRepo
+	Chain         ChainRepo
+	Blob          BlobRepo
+	Erasure       ErasureRepo
+}
+
+// SubjectManifest describes the client and the audio in the export
+type SubjectManifest struct {
+	RequestID   uuid.UUID       `json:"requestID"`
+	GeneratedAt time.Time       `json:"generatedAt"`
+	Client      entities.Client `json:"client"`
+	Clips       []evidence.Clip `json:"clips"`
+}
+
+type Subject struct {
+	tracer trace.Tracer
+	logger *zap.Logger
+	repos  SubjectRepos
+	signer *signing.Signer
+}
+
+// NewSubjectUCase creates the use case of data subject requests, exports are signed when the signer is set
+func NewSubjectUCase(logger *zap.Logger, repos SubjectRepos, signer *signing.Signer) *Subject {
+	return &Subject{
+		tracer: otel.Tracer("uCase.Subject"),
+		logger: logger,
+		repos:  repos,
+		signer: signer,
+	}
+}
+
+// Export writes the ZIP archive of everything the service keeps about the client. Documents are read
+// before the first byte is written, the audio is streamed clip by clip
+func (s Subject) Export(ctx context.Context, reqID uuid.UUID, clientID string, w io.Writer) error {
+	ctx, span := s.tracer.Start(ctx, "uCase.Subject.Export")
+	defer span.End()
+
+	client, err := s.repos.Client.GetWithDeleted(ctx, clientID)
+	if err != nil {
+		return errors.Wrap(err, "can't get the client")
+	}
+
+	documents, err := s.documents(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	manifest := SubjectManifest{
+		RequestID:   reqID,
+		GeneratedAt: time.Now().UTC(),
+		Client:      client,
+		Clips:       make([]evidence.Clip, 0),
+	}
+
+	records := make([]entities.ChainRecord, 0)
+	if client.Chain.Sequence > 0 {
+		if records, err = s.repos.Chain.Range(ctx, client.ID, 1, client.Chain.Sequence); err != nil {
+			return errors.Wrap(err, "can't get chain records")
+		}
+	}
+
+	blobs := make(map[string]string, len(records))
+	for _, record := range records {
+		key := entities.AudioBlobKey(record.ClientID, record.PayloadHash)
+		blob, err := s.repos.Blob.Get(ctx, key)
+		if errors.Is(err, repository.ErrBlobNotFound) {
+			manifest.Clips = append(manifest.Clips, evidence.Clip{Record: record, Missing: true})
+			continue
+		}
+		if err != nil {
+			return errors.Wrap(err, "can't get the audio")
+		}
+
+		clip := evidence.Clip{Record: record, File: evidence.ClipFile(record, audioExtension(blob.ContentType))}
+		manifest.Clips = append(manifest.Clips, clip)
+		blobs[clip.File] = key
+	}
+
+	archive := evidence.NewWriter(w)
+	if err := archive.AddJSON(evidence.ManifestFile, manifest); err != nil {
+		return err
+	}
+	for _, document := range documents {
+		if err := archive.AddJSON(document.name, document.value); err != nil {
+			return err
+		}
+	}
+
+	for _, clip := range manifest.Clips {
+		if clip.Missing {
+			continue
+		}
+
+		// the audio is read again, so only one clip is in memory
+		blob, err := s.repos.Blob.Get(ctx, blobs[clip.File])
+		if err != nil {
+			return errors.Wrap(err, "can't get the audio")
+		}
+		if err := archive.Add(clip.File, blob.Data); err != nil {
+			return err
+		}
+	}
+
+	if err := archive.Close(s.signer); err != nil {
+		return err
+	}
+
+	s.logger.Info(
+		"client data is exported",
+		zap.String("reqID", reqID.String()),
+		zap.String("clientID", clientID),
+		zap.Int("clips", len(blobs)),
+	)
+
+	return nil
+}
+
+type subjectDocument struct {
+	name  string
+	value interface{}
+}
+
+// documents collects records of every collection which refers to the client
+func (s Subject) documents(ctx context.Context, client entities.Client) ([]subjectDocument, error) {
+	detections, err := s.repos.Detection.List(ctx, entities.DetectionFilter{ClientID: client.ID})
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get detections of the client")
+	}
+
+	heartbeats, err := s.repos.Heartbeat.List(ctx, entities.HeartbeatFilter{ClientID: client.ID})
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get heartbeats of the client")
+	}
+
+	commands, err := s.repos.Command.List(ctx, entities.CommandFilter{ClientID: client.ID})
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get commands of the client")
+	}
+
+	var config *entities.ConfigDocument
+	document, err := s.repos.Config.Get(ctx, entities.ConfigScopeClient, client.ID)
+	if err == nil {
+		config = &document
+	} else if !errors.Is(err, repository.ErrConfigNotFound) {
+		return nil, errors.Wrap(err, "can't get the config of the client")
+	}
+
+	alerts, err := s.repos.Alert.List(ctx, entities.AlertFilter{ClientID: client.ID})
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get alerts of the client")
+	}
+
+	allRules, err := s.repos.AlertRule.List(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get alert rules")
+	}
+	rules := make([]entities.AlertRule, 0)
+	for _, rule := range allRules {
+		if rule.ClientID == client.ID {
+			rules = append(rules, rule)
+		}
+	}
+
+	incidents, err := s.repos.Incident.List(ctx, entities.IncidentFilter{ClientID: client.ID})
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get incidents of the client")
+	}
+
+	history, err := s.repos.ClientVersion.List(ctx, client.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get the history of the client")
+	}
+
+	trail, err := s.repos.Audit.List(ctx, entities.AuditFilter{TargetID: client.ID.Hex()})
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get the audit trail")
+	}
+
+	return []subjectDocument{
+		{name: evidence.DetectionsFile, value: detections},
+		{name: SubjectHeartbeatsFile, value: heartbeats},
+		{name: SubjectCommandsFile, value: commands},
+		{name: SubjectConfigFile, value: config},
+		{name: SubjectAlertsFile, value: alerts},
+		{name: SubjectRulesFile, value: rules},
+		{name: SubjectIncidentsFile, value: incidents},
+		{name: SubjectHistoryFile, value: history},
+		{name: evidence.AuditFile, value: trail},
+	}, nil
+}
+
+// Erase removes everything the service keeps about the client. The audio and detections of incidents
+// under the legal hold are kept, they refer to the client by its ID only. The tombstone is written
+// first, so messages of the broker which arrive during the erasure are dropped as well
+func (s Subject) Erase(ctx context.Context, reqID uuid.UUID, clientID string) (entities.Tombstone, error) {
+	ctx, span := s.tracer.Start(ctx, "uCase.Subject.Erase")
+	defer span.End()
+
+	client, err := s.repos.Client.GetWithDeleted(ctx, clientID)
+	if err != nil {
+		return entities.Tombstone{}, errors.Wrap(err, "can't get the client")
+	}
+
+	incidents, err := s.repos.Incident.List(ctx, entities.IncidentFilter{ClientID: client.ID, Held: true})
+	if err != nil {
+		return entities.Tombstone{}, errors.Wrap(err, "can't get incidents under the legal hold")
+	}
+
+	var hold entities.ErasureHold
+	for _, incident := range incidents {
+		hold.Incidents = append(hold.Incidents, incident.ID)
+		hold.Windows = append(hold.Windows, [2]time.Time{
+			incident.FirstSeen.Add(-_evidencePadding),
+			incident.LastSeen.Add(_evidencePadding),
+		})
+	}
+
+	tombstone := entities.Tombstone{
+		ClientID:  client.ID,
+		RequestID: reqID.String(),
+		ErasedAt:  time.Now().UTC(),
+		Report:    entities.NewErasureReport(),
+	}
+	if err := s.repos.Erasure.Tombstone(ctx, &tombstone); err != nil {
+		return entities.Tombstone{}, errors.Wrap(err, "can't save the tombstone")
+	}
+
+	if tombstone.Report, err = s.repos.Erasure.Erase(ctx, client.ID, hold); err != nil {
+		s.logger.Error(
+			"error during erase client data",
+			zap.String("reqID", reqID.String()),
+			zap.String("clientID", clientID),
+			zap.Error(err),
+		)
+
+		return entities.Tombstone{}, errors.Wrap(err, "can't erase the client data")
+	}
+
+	if err := s.repos.Erasure.Tombstone(ctx, &tombstone); err != nil {
+		return entities.Tombstone{}, errors.Wrap(err, "can't save the report of the erasure")
+	}
+
+	s.logger.Info(
+		"client data is erased",
+		zap.String("reqID", reqID.String()),
+		zap.String("clientID", clientID),
+		zap.Int("heldIncidents", len(hold.Incidents)),
+	)
+
+	return tombstone, nil
+}
@@ -0,0 +1,179 @@
+package uCase_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository"
+	mock_repository "github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository/mocks"
+	"github.com/Imm0bilize/gunshot-api-service/internal/uCase"
+	"github.com/Imm0bilize/gunshot-api-service/pkg/evidence"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestSubjectExport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		client = entities.Client{ID: primitive.NewObjectID(), FullName: "John Doe"}
+		other  = primitive.NewObjectID()
+		blobs  = newFakeBlobs()
+		audio  = []byte("clip")
+	)
+
+	records := chainOf(client.ID, 2)
+	client.Chain = entities.ChainHead{Sequence: 2, Hash: records[1].Hash}
+	records[0].PayloadHash = entities.HashPayload(audio)
+	require.NoError(t, blobs.Put(context.Background(), &entities.Blob{
+		Key: entities.AudioBlobKey(client.ID, records[0].PayloadHash), ContentType: "audio/wav", Data: audio,
+	}))
+
+	repos := mockSubjectRepos(ctrl, blobs)
+	repos.Client.(*mock_repository.MockClientRepository).EXPECT().
+		GetWithDeleted(gomock.Any(), client.ID.Hex()).Return(client, nil)
+	repos.Chain.(*mock_repository.MockChainRepository).EXPECT().
+		Range(gomock.Any(), client.ID, int64(1), int64(2)).Return(records, nil)
+	repos.Detection.(*mock_repository.MockDetectionRepository).EXPECT().
+		List(gomock.Any(), entities.DetectionFilter{ClientID: client.ID}).
+		Return([]entities.Detection{{ClientID: client.ID, Label: entities.LabelGunshot}}, nil)
+	repos.Heartbeat.(*mock_repository.MockHeartbeatRepository).EXPECT().
+		List(gomock.Any(), entities.HeartbeatFilter{ClientID: client.ID}).Return([]entities.Heartbeat{}, nil)
+	repos.Command.(*mock_repository.MockCommandRepository).EXPECT().
+		List(gomock.Any(), entities.CommandFilter{ClientID: client.ID}).Return([]entities.Command{}, nil)
+	repos.Config.(*mock_repository.MockConfigRepository).EXPECT().
+		Get(gomock.Any(), entities.ConfigScopeClient, client.ID).
+		Return(entities.ConfigDocument{}, repository.ErrConfigNotFound)
+	repos.Alert.(*mock_repository.MockAlertRepository).EXPECT().
+		List(gomock.Any(), entities.AlertFilter{ClientID: client.ID}).Return([]entities.Alert{}, nil)
+	repos.AlertRule.(*mock_repository.MockAlertRuleRepository).EXPECT().
+		List(gomock.Any()).Return([]entities.AlertRule{{Name: "mine", ClientID: client.ID}, {ClientID: other}}, nil)
+	repos.Incident.(*mock_repository.MockIncidentRepository).EXPECT().
+		List(gomock.Any(), entities.IncidentFilter{ClientID: client.ID}).Return([]entities.Incident{}, nil)
+	repos.ClientVersion.(*mock_repository.MockClientVersionRepository).EXPECT().
+		List(gomock.Any(), client.ID).Return([]entities.ClientVersion{{ClientID: client.ID}}, nil)
+	repos.Audit.(*mock_repository.MockAuditRepository).EXPECT().
+		List(gomock.Any(), entities.AuditFilter{TargetID: client.ID.Hex()}).Return([]entities.AuditEntry{}, nil)
+
+	var out bytes.Buffer
+	useCase := uCase.NewSubjectUCase(zap.NewNop(), repos, nil)
+	require.NoError(t, useCase.Export(context.Background(), uuid.New(), client.ID.Hex(), &out))
+
+	archive, err := zip.NewReader(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	require.NoError(t, err)
+
+	var manifest uCase.SubjectManifest
+	readJSON(t, archive, evidence.ManifestFile, &manifest)
+	require.Equal(t, "John Doe", manifest.Client.FullName)
+	require.Len(t, manifest.Clips, 2)
+	require.True(t, manifest.Clips[1].Missing)
+
+	f, err := archive.Open(manifest.Clips[0].File)
+	require.NoError(t, err)
+	data, err := io.ReadAll(f)
+	require.NoError(t, err)
+	require.Equal(t, audio, data)
+
+	var rules []entities.AlertRule
+	readJSON(t, archive, uCase.SubjectRulesFile, &rules)
+	require.Len(t, rules, 1)
+	require.Equal(t, "mine", rules[0].Name)
+
+	// the unsigned archive still lists digests of its files
+	_, err = archive.Open(evidence.SumsFile)
+	require.NoError(t, err)
+	_, err = archive.Open(evidence.SignatureFile)
+	require.Error(t, err)
+}
+
+func TestSubjectErase(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		client = entities.Client{ID: primitive.NewObjectID(), FullName: "John Doe"}
+		shot   = time.Date(2022, 12, 1, 22, 0, 0, 0, time.UTC)
+		held   = entities.Incident{ID: primitive.NewObjectID(), FirstSeen: shot, LastSeen: shot.Add(time.Second)}
+		report = entities.ErasureReport{Deleted: map[string]int64{"Clients": 1}}
+	)
+
+	repos := mockSubjectRepos(ctrl, newFakeBlobs())
+	repos.Client.(*mock_repository.MockClientRepository).EXPECT().
+		GetWithDeleted(gomock.Any(), client.ID.Hex()).Return(client, nil)
+	repos.Incident.(*mock_repository.MockIncidentRepository).EXPECT().
+		List(gomock.Any(), entities.IncidentFilter{ClientID: client.ID, Held: true}).
+		Return([]entities.Incident{held}, nil)
+
+	erasure := repos.Erasure.(*mock_repository.MockErasureRepository)
+	var saved []entities.Tombstone
+	erasure.EXPECT().Tombstone(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, tombstone *entities.Tombstone) error {
+			saved = append(saved, *tombstone)
+			return nil
+		},
+	).Times(2)
+	erasure.EXPECT().Erase(gomock.Any(), client.ID, entities.ErasureHold{
+		Incidents: []primitive.ObjectID{held.ID},
+		Windows:   [][2]time.Time{{shot.Add(-10 * time.Second), shot.Add(11 * time.Second)}},
+	}).Return(report, nil)
+
+	useCase := uCase.NewSubjectUCase(zap.NewNop(), repos, nil)
+	tombstone, err := useCase.Erase(context.Background(), uuid.New(), client.ID.Hex())
+	require.NoError(t, err)
+	require.Equal(t, report, tombstone.Report)
+
+	// the tombstone is there before anything is erased
+	require.Len(t, saved, 2)
+	require.Empty(t, saved[0].Report.Deleted)
+	require.Equal(t, report, saved[1].Report)
+}
+
+func TestDetectionOfErasedClientDropped(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	clientID := primitive.NewObjectID()
+
+	erasure := mock_repository.NewMockErasureRepository(ctrl)
+	erasure.EXPECT().Erased(gomock.Any(), clientID).Return(true, nil)
+
+	// neither the client nor the detection is touched
+	useCase := uCase.NewDetectionUCase(
+		zap.NewNop(),
+		mock_repository.NewMockDetectionRepository(ctrl),
+		mock_repository.NewMockClientRepository(ctrl),
+		erasure,
+		nil,
+		nil,
+		nil,
+	)
+
+	detection := &entities.Detection{ClientID: clientID, Label: entities.LabelGunshot}
+	require.NoError(t, useCase.Process(context.Background(), uuid.New(), detection))
+}
+
+func mockSubjectRepos(ctrl *gomock.Controller, blobs *fakeBlobs) uCase.SubjectRepos {
+	return uCase.SubjectRepos{
+		Client:        mock_repository.NewMockClientRepository(ctrl),
+		ClientVersion: mock_repository.NewMockClientVersionRepository(ctrl),
+		Incident:      mock_repository.NewMockIncidentRepository(ctrl),
+		Detection:     mock_repository.NewMockDetectionRepository(ctrl),
+		Heartbeat:     mock_repository.NewMockHeartbeatRepository(ctrl),
+		Command:       mock_repository.NewMockCommandRepository(ctrl),
+		Config:        mock_repository.NewMockConfigRepository(ctrl),
+		Alert:         mock_repository.NewMockAlertRepository(ctrl),
+		AlertRule:     mock_repository.NewMockAlertRuleRepository(ctrl),
+		Audit:         mock_repository.NewMockAuditRepository(ctrl),
+		Chain:         mock_repository.NewMockChainRepository(ctrl),
+		Blob:          blobs,
+		Erasure:       mock_repository.NewMockErasureRepository(ctrl),
+	}
+}
@@ -0,0 +1,238 @@
+package uCase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/Imm0bilize/gunshot-api-service/internal/audit"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/pkg/webhook"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"net/url"
+	"time"
+)
+
+const (
+	// _webhookAttempts is how many times the delivery is tried, attempts are _webhookBackoff apart and the
+	// backoff doubles
+	_webhookAttempts = 3
+	_webhookBackoff  = time.Second
+	_webhookSecret   = 32
+)
+
+var ErrInvalidWebhook = apperr.New(apperr.InvalidArgument, "the webhook is invalid")
+
+type WebhookRepo interface {
+	Create(ctx context.Context, webhook *entities.Webhook) (string, error)
+	Get(ctx context.Context, id string) (entities.Webhook, error)
+	List(ctx context.Context, event entities.WebhookEvent) ([]entities.Webhook, error)
+	Delete(ctx context.Context, id string) (entities.Webhook, error)
+}
+
+// WebhookSender POSTs the delivery signed by the secret to the URL of the webhook
+type WebhookSender interface {
+	Deliver(ctx context.Context, webhook entities.Webhook, delivery webhook.Delivery) error
+}
+
+type Webhook struct {
+	tracer      trace.Tracer
+	logger      *zap.Logger
+	webhookRepo WebhookRepo
+	sender      WebhookSender
+}
+
+func NewWebhookUCase(logger *zap.Logger, webhookRepo WebhookRepo, sender WebhookSender) *Webhook {
+	return &Webhook{
+		tracer:      otel.Tracer("uCase.Webhook"),
+		logger:      logger,
+		webhookRepo: webhookRepo,
+		sender:      sender,
+	}
+}
+
+// Create subscribes the URL to the events, the secret deliveries are signed with is generated and set to the
+// webhook: it's returned by the call only
+func (w Webhook) Create(ctx context.Context, reqID uuid.UUID, hook *entities.Webhook) (string, error) {
+	ctx, span := w.tracer.Start(ctx, "uCase.Webhook.Create")
+	defer span.End()
+
+	if err := validateWebhook(hook); err != nil {
+		return "", err
+	}
+
+	secret := make([]byte, _webhookSecret)
+	if _, err := rand.Read(secret); err != nil {
+		return "", errors.Wrap(err, "can't generate the secret of the webhook")
+	}
+	hook.Secret = base64.RawURLEncoding.EncodeToString(secret)
+	hook.CreatedAt = time.Now().UTC()
+
+	id, err := w.webhookRepo.Create(ctx, hook)
+	if err != nil {
+		return "", errors.Wrap(err, "can't create new webhook")
+	}
+
+	audit.SetTargetID(ctx, id)
+	auditChange(ctx, w.logger, reqID, nil, hook)
+
+	return id, nil
+}
+
+func (w Webhook) Get(ctx context.Context, reqID uuid.UUID, id string) (entities.Webhook, error) {
+	ctx, span := w.tracer.Start(ctx, "uCase.Webhook.Get")
+	defer span.End()
+
+	hook, err := w.webhookRepo.Get(ctx, id)
+	if err != nil {
+		return entities.Webhook{}, errors.Wrap(err, "can't get the webhook")
+	}
+
+	return hook, nil
+}
+
+func (w Webhook) List(ctx context.Context, reqID uuid.UUID) ([]entities.Webhook, error) {
+	ctx, span := w.tracer.Start(ctx, "uCase.Webhook.List")
+	defer span.End()
+
+	webhooks, err := w.webhookRepo.List(ctx, "")
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get the list of webhooks")
+	}
+
+	return webhooks, nil
+}
+
+func (w Webhook) Delete(ctx context.Context, reqID uuid.UUID, id string) error {
+	ctx, span := w.tracer.Start(ctx, "uCase.Webhook.Delete")
+	defer span.End()
+
+	deleted, err := w.webhookRepo.Delete(ctx, id)
+	if err != nil {
+		return errors.Wrap(err, "can't delete the webhook")
+	}
+	auditChange(ctx, w.logger, reqID, &deleted, nil)
+
+	return nil
+}
+
+// Notify delivers the event to webhooks of the organization subscribed to it. Deliveries run in the
+// background and are retried, failures are logged: the broker is the reliable channel of events
+func (w Webhook) Notify(ctx context.Context, reqID uuid.UUID, event entities.WebhookEvent, data interface{}) {
+	ctx, span := w.tracer.Start(ctx, "uCase.Webhook.Notify")
+	defer span.End()
+
+	webhooks, err := w.webhookRepo.List(ctx, event)
+	if err != nil {
+		w.logger.Error(
+			"can't get webhooks of the event", zap.String("reqID", reqID.String()), zap.Error(err),
+		)
+		return
+	}
+	if len(webhooks) == 0 {
+		return
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		w.logger.Error("can't encode the event", zap.String("reqID", reqID.String()), zap.Error(err))
+		return
+	}
+
+	delivery := webhook.Delivery{
+		ID:        uuid.NewString(),
+		Event:     event,
+		RequestID: reqID.String(),
+		Timestamp: time.Now().UTC(),
+		Data:      raw,
+	}
+
+	// the caller doesn't wait for deliveries
+	ctx = audit.Detach(ctx)
+	for _, hook := range webhooks {
+		go w.deliver(ctx, reqID, hook, delivery)
+	}
+}
+
+func (w Webhook) deliver(ctx context.Context, reqID uuid.UUID, hook entities.Webhook, delivery webhook.Delivery) {
+	backoff := _webhookBackoff
+
+	for attempt := 1; ; attempt++ {
+		err := w.sender.Deliver(ctx, hook, delivery)
+		if err == nil {
+			return
+		}
+
+		if attempt == _webhookAttempts {
+			w.logger.Error(
+				"webhook delivery is lost",
+				zap.String("reqID", reqID.String()),
+				zap.String("webhookID", hook.ID.Hex()),
+				zap.String("deliveryID", delivery.ID),
+				zap.Error(err),
+			)
+			return
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func validateWebhook(hook *entities.Webhook) error {
+	target, err := url.Parse(hook.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("%w: the URL must be the absolute http or https one", ErrInvalidWebhook)
+	}
+
+	if len(hook.Events) == 0 {
+		return fmt.Errorf("%w: no events", ErrInvalidWebhook)
+	}
+
+	for _, event := range hook.Events {
+		if !knownWebhookEvent(event) {
+			return fmt.Errorf("%w: unknown event %q", ErrInvalidWebhook, event)
+		}
+	}
+
+	return nil
+}
+
+func knownWebhookEvent(event entities.WebhookEvent) bool {
+	for _, known := range entities.WebhookEvents {
+		if event == known {
+			return true
+		}
+	}
+
+	return false
+}
+
+// webhookPublisher passes events to the broker and to webhooks of the organization
+type webhookPublisher struct {
+	Publisher
+
+	webhooks *Webhook
+}
+
+func (p webhookPublisher) SendIncidentTransition(
+	ctx context.Context, reqID uuid.UUID, incident entities.Incident, transition entities.IncidentTransition,
+) error {
+	p.webhooks.Notify(ctx, reqID, entities.WebhookIncidentTransition, entities.WebhookPayloadIncident{
+		Incident: incident, Transition: transition,
+	})
+
+	return p.Publisher.SendIncidentTransition(ctx, reqID, incident, transition)
+}
+
+func (p webhookPublisher) SendAlert(ctx context.Context, reqID uuid.UUID, alert entities.Alert) error {
+	p.webhooks.Notify(ctx, reqID, entities.WebhookAlert, alert)
+
+	return p.Publisher.SendAlert(ctx, reqID, alert)
+}
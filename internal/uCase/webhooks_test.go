@@ -0,0 +1,99 @@
+package uCase_test
+
+import (
+	"context"
+	"errors"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	mock_repository "github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository/mocks"
+	"github.com/Imm0bilize/gunshot-api-service/internal/uCase"
+	"github.com/Imm0bilize/gunshot-api-service/pkg/webhook"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+// flakySender fails the first Failures deliveries and passes the rest to the channel
+type flakySender struct {
+	Failures   int
+	Deliveries chan webhook.Delivery
+}
+
+func (s *flakySender) Deliver(_ context.Context, _ entities.Webhook, delivery webhook.Delivery) error {
+	if s.Failures > 0 {
+		s.Failures--
+		return errors.New("connection refused")
+	}
+
+	s.Deliveries <- delivery
+	return nil
+}
+
+func TestWebhookCreate(t *testing.T) {
+	cases := []struct {
+		name    string
+		webhook entities.Webhook
+		valid   bool
+	}{
+		{"valid", entities.Webhook{URL: "https://example.com/hook", Events: entities.WebhookEvents}, true},
+		{"relative URL", entities.Webhook{URL: "/hook", Events: entities.WebhookEvents}, false},
+		{"another scheme", entities.Webhook{URL: "ftp://example.com", Events: entities.WebhookEvents}, false},
+		{"no events", entities.Webhook{URL: "https://example.com/hook"}, false},
+		{
+			"unknown event",
+			entities.Webhook{URL: "https://example.com/hook", Events: []entities.WebhookEvent{"detection"}},
+			false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repository.NewMockWebhookRepository(ctrl)
+			if tc.valid {
+				repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return("id", nil).Times(1)
+			}
+
+			hook := tc.webhook
+			useCase := uCase.NewWebhookUCase(zap.NewExample(), repo, nil)
+			_, err := useCase.Create(context.Background(), uuid.New(), &hook)
+			if !tc.valid {
+				require.ErrorIs(t, err, uCase.ErrInvalidWebhook)
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotEmpty(t, hook.Secret)
+		})
+	}
+}
+
+func TestWebhookNotifyRetries(t *testing.T) {
+	var (
+		ctrl   = gomock.NewController(t)
+		repo   = mock_repository.NewMockWebhookRepository(ctrl)
+		sender = &flakySender{Failures: 1, Deliveries: make(chan webhook.Delivery, 1)}
+		reqID  = uuid.New()
+	)
+	defer ctrl.Finish()
+
+	hook := entities.Webhook{ID: primitive.NewObjectID(), URL: "https://example.com/hook", Secret: "secret"}
+	repo.EXPECT().List(gomock.Any(), entities.WebhookAlert).Return([]entities.Webhook{hook}, nil).Times(1)
+
+	useCase := uCase.NewWebhookUCase(zap.NewExample(), repo, sender)
+	useCase.Notify(context.Background(), reqID, entities.WebhookAlert, entities.Alert{RuleName: "night"})
+
+	select {
+	case delivery := <-sender.Deliveries:
+		require.Equal(t, entities.WebhookAlert, delivery.Event)
+		require.Equal(t, reqID.String(), delivery.RequestID)
+		require.Contains(t, string(delivery.Data), `"ruleName":"night"`)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the delivery is not retried")
+	}
+}
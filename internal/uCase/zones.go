@@ -0,0 +1,166 @@
+package uCase
+
+import (
+	"context"
+	"fmt"
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/Imm0bilize/gunshot-api-service/internal/audit"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type ZoneRepo interface {
+	Create(ctx context.Context, zone *entities.Zone) (string, error)
+	Get(ctx context.Context, id string) (entities.Zone, error)
+	Update(ctx context.Context, id string, zone *entities.Zone) (entities.Zone, entities.Zone, error)
+	Delete(ctx context.Context, id string) (entities.Zone, error)
+	List(ctx context.Context) ([]entities.Zone, error)
+}
+
+var (
+	ErrInvalidZone = apperr.New(apperr.InvalidArgument, "the zone is invalid")
+)
+
+type Zone struct {
+	tracer     trace.Tracer
+	zoneRepo   ZoneRepo
+	clientRepo ClientRepo
+	logger     *zap.Logger
+}
+
+func NewZoneUCase(logger *zap.Logger, zoneRepo ZoneRepo, clientRepo ClientRepo) *Zone {
+	return &Zone{
+		tracer:     otel.Tracer("uCase.Zone"),
+		zoneRepo:   zoneRepo,
+		clientRepo: clientRepo,
+		logger:     logger,
+	}
+}
+
+func (z Zone) Create(ctx context.Context, reqID uuid.UUID, zone *entities.Zone) (string, error) {
+	ctx, span := z.tracer.Start(ctx, "uCase.Zone.Create")
+	defer span.End()
+
+	if err := zone.Area.Validate(); err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidZone, err)
+	}
+
+	id, err := z.zoneRepo.Create(ctx, zone)
+	if err != nil {
+		return "", errors.Wrap(err, "can't create new zone")
+	}
+
+	audit.SetTargetID(ctx, id)
+	auditChange(ctx, z.logger, reqID, nil, zone)
+	z.reassignClients(ctx, reqID)
+
+	return id, nil
+}
+
+func (z Zone) Get(ctx context.Context, reqID uuid.UUID, id string) (entities.Zone, error) {
+	ctx, span := z.tracer.Start(ctx, "uCase.Zone.Get")
+	defer span.End()
+
+	zone, err := z.zoneRepo.Get(ctx, id)
+	if err != nil {
+		return entities.Zone{}, errors.Wrap(err, "can't get the zone")
+	}
+
+	return zone, nil
+}
+
+func (z Zone) Update(ctx context.Context, reqID uuid.UUID, id string, zone *entities.Zone) error {
+	ctx, span := z.tracer.Start(ctx, "uCase.Zone.Update")
+	defer span.End()
+
+	if err := zone.Area.Validate(); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidZone, err)
+	}
+
+	before, after, err := z.zoneRepo.Update(ctx, id, zone)
+	if err != nil {
+		return errors.Wrap(err, "can't update the zone")
+	}
+	auditChange(ctx, z.logger, reqID, &before, &after)
+	z.reassignClients(ctx, reqID)
+
+	return nil
+}
+
+func (z Zone) Delete(ctx context.Context, reqID uuid.UUID, id string) error {
+	ctx, span := z.tracer.Start(ctx, "uCase.Zone.Delete")
+	defer span.End()
+
+	deleted, err := z.zoneRepo.Delete(ctx, id)
+	if err != nil {
+		return errors.Wrap(err, "can't delete the zone")
+	}
+	auditChange(ctx, z.logger, reqID, &deleted, nil)
+	z.reassignClients(ctx, reqID)
+
+	return nil
+}
+
+func (z Zone) List(ctx context.Context, reqID uuid.UUID) ([]entities.Zone, error) {
+	ctx, span := z.tracer.Start(ctx, "uCase.Zone.List")
+	defer span.End()
+
+	zones, err := z.zoneRepo.List(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get the list of zones")
+	}
+
+	return zones, nil
+}
+
+// reassignClients recomputes zones of every client after the set of zones has been changed. The zone is
+// already written, so failures are logged instead of failing the request: zones of the clients left behind
+// are recomputed by the next write of zones or of the client
+func (z Zone) reassignClients(ctx context.Context, reqID uuid.UUID) {
+	zones, err := z.zoneRepo.List(ctx)
+	if err != nil {
+		z.logger.Error("can't get zones to reassign clients", zap.String("reqID", reqID.String()), zap.Error(err))
+		return
+	}
+
+	clients, err := z.clientRepo.List(ctx)
+	if err != nil {
+		z.logger.Error("can't get clients to reassign zones", zap.String("reqID", reqID.String()), zap.Error(err))
+		return
+	}
+
+	for _, client := range clients {
+		zoneIDs := entities.ZonesOf(zones, client.Location())
+		if sameIDs(zoneIDs, client.ZoneIDs) {
+			continue
+		}
+
+		if err := z.clientRepo.SetZones(ctx, client.ID, zoneIDs); err != nil {
+			z.logger.Error(
+				"can't reassign zones of the client",
+				zap.String("reqID", reqID.String()),
+				zap.String("clientID", client.ID.Hex()),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+func sameIDs(a, b []primitive.ObjectID) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
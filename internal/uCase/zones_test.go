@@ -0,0 +1,105 @@
+package uCase_test
+
+import (
+	"context"
+	"errors"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/geo"
+	mock_repository "github.com/Imm0bilize/gunshot-api-service/internal/infrastructure/repository/mocks"
+	"github.com/Imm0bilize/gunshot-api-service/internal/uCase"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+var testZone = entities.Zone{
+	ID:   primitive.NewObjectID(),
+	Name: "center",
+	Area: geo.Polygon{
+		Type:        "Polygon",
+		Coordinates: [][][]float64{{{37.0, 55.0}, {38.0, 55.0}, {38.0, 56.0}, {37.0, 56.0}, {37.0, 55.0}}},
+	},
+}
+
+func TestClientUpdateAssignsZones(t *testing.T) {
+	var (
+		ctrl    = gomock.NewController(t)
+		clients = mock_repository.NewMockClientRepository(ctrl)
+		zones   = mock_repository.NewMockZoneRepository(ctrl)
+		id      = primitive.NewObjectID().Hex()
+	)
+	defer ctrl.Finish()
+
+	zones.EXPECT().List(gomock.Any()).Return([]entities.Zone{testZone}, nil).Times(1)
+	clients.EXPECT().Update(gomock.Any(), id, int64(0), gomock.Any()).
+		Return(entities.Client{}, entities.Client{}, nil).Times(1)
+	versions := mock_repository.NewMockClientVersionRepository(ctrl)
+	versions.EXPECT().Create(gomock.Any(), gomock.Any()).Return("", nil).Times(1)
+
+	client := &entities.Client{Latitude: 55.5, Longitude: 37.5}
+
+	useCase := uCase.NewClientUCase(zap.NewExample(), clients, zones, versions, nil, time.Hour)
+	_, err := useCase.Update(context.Background(), uuid.New(), id, 0, client)
+	require.NoError(t, err)
+	require.Equal(t, []primitive.ObjectID{testZone.ID}, client.ZoneIDs)
+}
+
+func TestZoneUpdateReassignsClients(t *testing.T) {
+	var (
+		ctrl    = gomock.NewController(t)
+		clients = mock_repository.NewMockClientRepository(ctrl)
+		zones   = mock_repository.NewMockZoneRepository(ctrl)
+		inside  = entities.Client{ID: primitive.NewObjectID(), Latitude: 55.5, Longitude: 37.5}
+		outside = entities.Client{ID: primitive.NewObjectID(), Latitude: 59.9, Longitude: 30.3}
+		stale   = entities.Client{
+			ID: primitive.NewObjectID(), Latitude: 59.9, Longitude: 30.3, ZoneIDs: []primitive.ObjectID{testZone.ID},
+		}
+	)
+	defer ctrl.Finish()
+
+	zones.EXPECT().Update(gomock.Any(), testZone.ID.Hex(), gomock.Any()).Return(testZone, testZone, nil).Times(1)
+	zones.EXPECT().List(gomock.Any()).Return([]entities.Zone{testZone}, nil).Times(1)
+	clients.EXPECT().List(gomock.Any()).Return([]entities.Client{inside, outside, stale}, nil).Times(1)
+	clients.EXPECT().SetZones(gomock.Any(), inside.ID, []primitive.ObjectID{testZone.ID}).Return(nil).Times(1)
+	clients.EXPECT().SetZones(gomock.Any(), stale.ID, []primitive.ObjectID{}).Return(nil).Times(1)
+
+	useCase := uCase.NewZoneUCase(zap.NewExample(), zones, clients)
+	zone := testZone
+	require.NoError(t, useCase.Update(context.Background(), uuid.New(), testZone.ID.Hex(), &zone))
+}
+
+// TestZoneCreateReassignFailure checks that the zone written before clients fail to be reassigned is reported
+// as created
+func TestZoneCreateReassignFailure(t *testing.T) {
+	var (
+		ctrl    = gomock.NewController(t)
+		clients = mock_repository.NewMockClientRepository(ctrl)
+		zones   = mock_repository.NewMockZoneRepository(ctrl)
+		inside  = entities.Client{ID: primitive.NewObjectID(), Latitude: 55.5, Longitude: 37.5}
+	)
+	defer ctrl.Finish()
+
+	zones.EXPECT().Create(gomock.Any(), gomock.Any()).Return(testZone.ID.Hex(), nil).Times(1)
+	zones.EXPECT().List(gomock.Any()).Return([]entities.Zone{testZone}, nil).Times(1)
+	clients.EXPECT().List(gomock.Any()).Return([]entities.Client{inside}, nil).Times(1)
+	clients.EXPECT().SetZones(gomock.Any(), inside.ID, gomock.Any()).Return(errors.New("timeout")).Times(1)
+
+	useCase := uCase.NewZoneUCase(zap.NewExample(), zones, clients)
+	zone := testZone
+	id, err := useCase.Create(context.Background(), uuid.New(), &zone)
+	require.NoError(t, err)
+	require.Equal(t, testZone.ID.Hex(), id)
+}
+
+func TestZoneCreateInvalidArea(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	useCase := uCase.NewZoneUCase(zap.NewExample(), mock_repository.NewMockZoneRepository(ctrl), nil)
+	_, err := useCase.Create(context.Background(), uuid.New(), &entities.Zone{Area: geo.Polygon{Type: "Point"}})
+	require.ErrorIs(t, err, uCase.ErrInvalidZone)
+}
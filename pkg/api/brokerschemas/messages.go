@@ -3,9 +3,42 @@ package brokerschemas
 import (
 	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
 	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
 )
 
+// AudioMessage carries the payload with its link of the hash chain of the client: consumers recompute
+// SHA-256 of the payload and compare it with Payload.Chain.PayloadHash, see entities.ChainRecord.ComputeHash.
+// Payload.Filter tells which privacy filter has changed the audio, the hash chain covers the filtered one
 type AudioMessage struct {
 	Payload   entities.Message `json:"payload"`
 	RequestID uuid.UUID        `json:"requestID"`
 }
+
+// DetectionMessage is the result of the audio classification produced by the ml service
+type DetectionMessage struct {
+	RequestID    uuid.UUID `json:"requestID"`
+	ClientID     string    `json:"clientID"`
+	Label        string    `json:"label"`
+	Confidence   float64   `json:"confidence"`
+	ModelVersion string    `json:"modelVersion"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+type IncidentTransitionMessage struct {
+	RequestID  uuid.UUID                   `json:"requestID"`
+	IncidentID string                      `json:"incidentID"`
+	Transition entities.IncidentTransition `json:"transition"`
+	ShotCount  int                         `json:"shotCount"`
+	Clients    []primitive.ObjectID        `json:"clients"`
+}
+
+type AlertMessage struct {
+	RequestID uuid.UUID      `json:"requestID"`
+	Alert     entities.Alert `json:"alert"`
+}
+
+type MaintenanceMessage struct {
+	RequestID uuid.UUID                 `json:"requestID"`
+	Event     entities.MaintenanceEvent `json:"event"`
+}
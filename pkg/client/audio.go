@@ -0,0 +1,47 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	_signatureHeader = "X-Signature"
+	_sequenceHeader  = "X-Sequence"
+)
+
+// UploadAudio streams the audio recorded by the sensor at ts. The upload is not retried: the reader may be
+// consumed by the failed attempt
+func (c *Client) UploadAudio(ctx context.Context, id string, ts time.Time, audio io.Reader, opts UploadOptions) error {
+	contentType := opts.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	header := make(http.Header)
+	if opts.Signature != nil {
+		header.Set(_signatureHeader, base64.StdEncoding.EncodeToString(opts.Signature))
+		header.Set(_sequenceHeader, strconv.FormatInt(opts.Sequence, 10))
+	}
+
+	_, err := c.do(
+		ctx,
+		call{
+			method:      http.MethodPost,
+			path:        sensorPath(id) + "/" + strconv.FormatInt(ts.UnixMilli(), 10) + "/upload",
+			header:      header,
+			contentType: contentType,
+			body: func() (io.Reader, error) {
+				return audio, nil
+			},
+			once: true,
+		},
+		nil,
+	)
+
+	return err
+}
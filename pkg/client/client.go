@@ -0,0 +1,272 @@
+// Package client is the Go SDK of the API. Every call carries the 'X-REQUEST-ID' header, generated unless
+// the context has one; calls are retried on failures which may pass, POSTs and PATCHes carry the
+// 'Idempotency-Key' header so the service serves their retries once. Problems of the service are returned
+// as *Error. Responses are types of the service, aliased by the package.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultTimeout    = 30 * time.Second
+	DefaultMaxRetries = 3
+	DefaultBackoff    = 200 * time.Millisecond
+	DefaultMaxBackoff = 5 * time.Second
+
+	_requestIDHeader      = "X-REQUEST-ID"
+	_idempotencyKeyHeader = "Idempotency-Key"
+	_apiPrefix            = "/api/v1"
+	_jsonType             = "application/json"
+)
+
+type Config struct {
+	// BaseURL is the address of the service, e.g. http://localhost:8080
+	BaseURL string
+	// APIKey is the key of the organization
+	APIKey     string
+	HTTPClient *http.Client
+	// Timeout limits every attempt of the call, the context limits the call with all its retries
+	Timeout time.Duration
+	// MaxRetries is the number of retries of calls, negative disables them. Calls reading the caller's reader
+	// are not retried
+	MaxRetries int
+	// Backoff is the pause before the first retry, it doubles up to MaxBackoff. Retry-After of the service
+	// takes precedence
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+type Client struct {
+	cfg     Config
+	baseURL *url.URL
+}
+
+// New returns the client of the service, zero fields of the config take defaults
+func New(cfg Config) (*Client, error) {
+	baseURL, err := url.Parse(strings.TrimSuffix(cfg.BaseURL, "/"))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid base URL")
+	}
+
+	if baseURL.Scheme != "http" && baseURL.Scheme != "https" {
+		return nil, errors.New("invalid base URL: expected http or https scheme")
+	}
+
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = DefaultMaxRetries
+	}
+	if cfg.Backoff == 0 {
+		cfg.Backoff = DefaultBackoff
+	}
+	if cfg.MaxBackoff == 0 {
+		cfg.MaxBackoff = DefaultMaxBackoff
+	}
+
+	return &Client{cfg: cfg, baseURL: baseURL}, nil
+}
+
+type requestIDKey struct{}
+
+// WithRequestID makes calls with the context carry the request id, e.g. the one of the incoming request
+func WithRequestID(ctx context.Context, requestID uuid.UUID) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFrom returns the request id set by WithRequestID
+func RequestIDFrom(ctx context.Context) (uuid.UUID, bool) {
+	requestID, ok := ctx.Value(requestIDKey{}).(uuid.UUID)
+	return requestID, ok
+}
+
+// call is the request to the API, the body is made for every attempt unless it's read once
+type call struct {
+	method      string
+	path        string
+	query       url.Values
+	header      http.Header
+	contentType string
+	body        func() (io.Reader, error)
+	// once tells the body is the reader of the caller, the failed attempt may have consumed it
+	once bool
+}
+
+type response struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// jsonCall returns the call with the JSON body
+func jsonCall(method, path string, v interface{}) (call, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return call{}, errors.Wrap(err, "can't encode the body")
+	}
+
+	return call{
+		method:      method,
+		path:        path,
+		contentType: _jsonType,
+		body: func() (io.Reader, error) {
+			return bytes.NewReader(raw), nil
+		},
+	}, nil
+}
+
+// do makes the call and decodes the JSON response into out. Attempts share the request id, so the service
+// logs and audits them as one request. Attempts of methods which are not idempotent share the idempotency
+// key as well, the service returns the response of the served attempt to the others
+func (c *Client) do(ctx context.Context, call call, out interface{}) (response, error) {
+	requestID, ok := RequestIDFrom(ctx)
+	if !ok {
+		requestID = uuid.New()
+	}
+
+	retries := c.cfg.MaxRetries
+	if call.once || retries < 0 {
+		retries = 0
+	}
+
+	if !idempotent(call.method) {
+		header := call.header.Clone()
+		if header == nil {
+			header = make(http.Header)
+		}
+
+		header.Set(_idempotencyKeyHeader, uuid.NewString())
+		call.header = header
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.attempt(ctx, requestID, call)
+		if err == nil && resp.status < http.StatusBadRequest {
+			if out != nil && len(resp.body) > 0 {
+				if err := json.Unmarshal(resp.body, out); err != nil {
+					return resp, errors.Wrapf(err, "can't decode the response of %s %s", call.method, call.path)
+				}
+			}
+
+			return resp, nil
+		}
+
+		if err == nil {
+			err = newError(resp, requestID)
+		}
+
+		if attempt >= retries || ctx.Err() != nil || !temporary(err) {
+			return resp, err
+		}
+
+		timer := time.NewTimer(c.backoff(attempt, resp.header))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return resp, errors.Wrapf(ctx.Err(), "%s %s is interrupted: %s", call.method, call.path, err)
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt sends the request once, the response is read in full before the timeout of the attempt is over
+func (c *Client) attempt(ctx context.Context, requestID uuid.UUID, call call) (response, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	var body io.Reader
+	if call.body != nil {
+		var err error
+		if body, err = call.body(); err != nil {
+			return response{}, err
+		}
+	}
+
+	target := *c.baseURL
+	target.Path += _apiPrefix + call.path
+	target.RawQuery = call.query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, call.method, target.String(), body)
+	if err != nil {
+		return response{}, errors.Wrapf(err, "can't make the request %s %s", call.method, call.path)
+	}
+
+	for name, values := range call.header {
+		req.Header[name] = values
+	}
+
+	req.Header.Set(_requestIDHeader, requestID.String())
+	req.Header.Set("Accept", _jsonType+", "+_problemType)
+	if c.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.APIKey)
+	}
+	if call.contentType != "" {
+		req.Header.Set("Content-Type", call.contentType)
+	}
+
+	resp, err := c.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return response{}, errors.Wrapf(err, "can't %s %s", call.method, call.path)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return response{}, errors.Wrapf(err, "can't read the response of %s %s", call.method, call.path)
+	}
+
+	return response{status: resp.StatusCode, header: resp.Header, body: raw}, nil
+}
+
+// backoff returns the pause before the retry: the exponential one with jitter unless the service has asked
+// for the other by Retry-After
+func (c *Client) backoff(attempt int, header http.Header) time.Duration {
+	if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	pause := c.cfg.Backoff << attempt
+	if pause > c.cfg.MaxBackoff || pause <= 0 {
+		pause = c.cfg.MaxBackoff
+	}
+
+	return pause/2 + time.Duration(rand.Int63n(int64(pause/2)+1))
+}
+
+// idempotent tells if the method may be repeated (RFC 7231), writes of clients are conditional on their
+// version as well
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// temporary tells if the failed call may pass on retry: failures of transport and attempts out of time
+// may, problems of the service tell themselves
+func temporary(err error) bool {
+	var problem *Error
+	if errors.As(err, &problem) {
+		return problem.Temporary()
+	}
+
+	return true
+}
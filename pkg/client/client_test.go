@@ -0,0 +1,397 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/testserver"
+	"github.com/Imm0bilize/gunshot-api-service/pkg/client"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const _apiKey = testserver.APIKey
+
+type fixture struct {
+	*testserver.Server
+}
+
+func newFixture(t *testing.T) *fixture {
+	return &fixture{Server: testserver.New(t)}
+}
+
+func (f *fixture) client(t *testing.T, cfg client.Config) *client.Client {
+	cfg.BaseURL = f.URL
+	if cfg.APIKey == "" {
+		cfg.APIKey = _apiKey
+	}
+	if cfg.Backoff == 0 {
+		cfg.Backoff = time.Millisecond
+	}
+
+	c, err := client.New(cfg)
+	require.NoError(t, err)
+
+	return c
+}
+
+func TestSensors(t *testing.T) {
+	var (
+		f   = newFixture(t)
+		c   = f.client(t, client.Config{})
+		ctx = context.Background()
+	)
+
+	id, err := c.RegisterSensor(ctx, client.SensorInfo{LocationName: "Main st.", FullName: "Sensor 1", Latitude: 0})
+	require.NoError(t, err)
+
+	sensor, err := c.GetSensor(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, "Sensor 1", sensor.FullName)
+	require.EqualValues(t, 1, sensor.Version)
+
+	updated, err := c.UpdateSensor(ctx, id, sensor.Version, client.SensorInfo{
+		LocationName: "Main st.", FullName: "Sensor 2", Latitude: 55.7, Longitude: 37.6,
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 2, updated.Version)
+	require.Equal(t, "Sensor 2", updated.FullName)
+
+	_, err = c.UpdateSensor(ctx, id, sensor.Version, client.SensorInfo{LocationName: "Main st.", FullName: "Stale"})
+	require.ErrorIs(t, err, client.ErrFailedPrecondition)
+
+	fullName := "Sensor 3"
+	sensor, err = c.PatchSensor(ctx, id, updated.Version, client.SensorPatch{FullName: &fullName})
+	require.NoError(t, err)
+	require.Equal(t, "Sensor 3", sensor.FullName)
+	require.Equal(t, "Main st.", sensor.LocationName)
+	require.EqualValues(t, 55.7, sensor.Latitude)
+
+	require.NoError(t, c.DeleteSensor(ctx, id, sensor.Version))
+
+	_, err = c.GetSensor(ctx, id)
+	require.ErrorIs(t, err, client.ErrNotFound)
+
+	sensor, err = c.RestoreSensor(ctx, id)
+	require.NoError(t, err)
+	require.Nil(t, sensor.DeletedAt)
+}
+
+func TestErrors(t *testing.T) {
+	var (
+		f         = newFixture(t)
+		ctx       = context.Background()
+		requestID = uuid.New()
+	)
+
+	_, err := f.client(t, client.Config{}).GetSensor(client.WithRequestID(ctx, requestID), primitive.NewObjectID().Hex())
+
+	var problem *client.Error
+	require.ErrorAs(t, err, &problem)
+	require.Equal(t, 404, problem.Status)
+	require.Equal(t, client.CodeNotFound, problem.Code)
+	require.Equal(t, "can't get client: the client is not found", problem.Detail)
+	require.Equal(t, requestID.String(), problem.RequestID)
+	require.False(t, problem.Temporary())
+
+	_, err = f.client(t, client.Config{APIKey: "gsk_other"}).GetIncident(ctx, f.Incidents.Incident.ID.Hex())
+	require.ErrorIs(t, err, client.ErrUnauthenticated)
+
+	_, err = f.client(t, client.Config{}).ListDetections(ctx, client.DetectionFilter{Limit: 1000})
+	require.ErrorIs(t, err, client.ErrInvalidArgument)
+	require.ErrorAs(t, err, &problem)
+	require.Equal(t, []client.Violation{{Location: "query.limit", Error: "must be at most 500"}}, problem.Violations())
+}
+
+func TestRetries(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("temporary failures", func(t *testing.T) {
+		f := newFixture(t)
+		f.Detections.Failures = 2
+		f.Detections.Err = errors.Wrap(mongo.ErrClientDisconnected, "can't find detections")
+
+		clientID, after := primitive.NewObjectID(), primitive.NewObjectID()
+
+		detections, err := f.client(t, client.Config{}).ListDetections(ctx, client.DetectionFilter{
+			ClientID: clientID.Hex(), After: after.Hex(),
+		})
+		require.NoError(t, err)
+		require.Len(t, detections, 1)
+		require.Equal(t, clientID, f.Detections.Filters()[2].ClientID)
+		require.Equal(t, after, f.Detections.Filters()[2].After)
+
+		// attempts are the same request
+		calls := f.Detections.Calls()
+		require.Len(t, calls, 3)
+		require.NotEqual(t, uuid.Nil, calls[0])
+		require.Equal(t, calls[0], calls[1])
+		require.Equal(t, calls[0], calls[2])
+
+		_, err = f.client(t, client.Config{}).ListDetections(ctx, client.DetectionFilter{})
+		require.NoError(t, err)
+		require.NotEqual(t, calls[0], f.Detections.Calls()[3], "the new call has the new request id")
+	})
+
+	t.Run("retries are over", func(t *testing.T) {
+		f := newFixture(t)
+		f.Detections.Failures = 3
+		f.Detections.Err = errors.Wrap(mongo.ErrClientDisconnected, "can't find detections")
+
+		_, err := f.client(t, client.Config{MaxRetries: 1}).ListDetections(ctx, client.DetectionFilter{})
+		require.ErrorIs(t, err, client.ErrUnavailable)
+		require.Len(t, f.Detections.Calls(), 2)
+	})
+
+	t.Run("permanent failures", func(t *testing.T) {
+		f := newFixture(t)
+		f.Detections.Failures = 1
+		f.Detections.Err = errors.New("unexpected")
+
+		_, err := f.client(t, client.Config{}).ListDetections(ctx, client.DetectionFilter{})
+		require.ErrorIs(t, err, client.ErrInternal)
+		require.Len(t, f.Detections.Calls(), 1)
+	})
+
+	t.Run("lost responses of writes", func(t *testing.T) {
+		var (
+			f         = newFixture(t)
+			transport = &lossyTransport{lost: 1}
+			c         = f.client(t, client.Config{HTTPClient: &http.Client{Transport: transport}})
+		)
+
+		id, err := c.RegisterSensor(ctx, client.SensorInfo{LocationName: "Main st.", FullName: "Sensor 1"})
+		require.NoError(t, err)
+
+		var export bytes.Buffer
+		require.NoError(t, c.ExportSensors(ctx, "csv", &export))
+		require.Equal(t, 2, strings.Count(export.String(), "\n"), "the sensor is created once")
+
+		transport.lost = 1
+		fullName := "Sensor 2"
+		sensor, err := c.PatchSensor(ctx, id, 1, client.SensorPatch{FullName: &fullName})
+		require.NoError(t, err, "the retry isn't taken for the stale write")
+		require.EqualValues(t, 2, sensor.Version)
+
+		// attempts of the write share the key, every write has its own
+		keys := transport.keys
+		require.Len(t, keys, 5)
+		require.NotEmpty(t, keys[0])
+		require.Equal(t, keys[0], keys[1])
+		require.Empty(t, keys[2], "reads have no key")
+		require.Equal(t, keys[3], keys[4])
+		require.NotEqual(t, keys[0], keys[3])
+	})
+}
+
+// lossyTransport loses responses of the first lost requests after the service has served them
+type lossyTransport struct {
+	mu   sync.Mutex
+	lost int
+	keys []string
+}
+
+func (l *lossyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	l.mu.Lock()
+	l.keys = append(l.keys, req.Header.Get("Idempotency-Key"))
+	lose := l.lost > 0
+	l.lost--
+	l.mu.Unlock()
+
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil || !lose {
+		return resp, err
+	}
+
+	_ = resp.Body.Close()
+	return nil, errors.New("connection reset by peer")
+}
+
+func TestTimeouts(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("the attempt is out of time", func(t *testing.T) {
+		f := newFixture(t)
+		f.Detections.Failures = 1
+
+		_, err := f.client(t, client.Config{Timeout: 100 * time.Millisecond}).ListDetections(
+			ctx, client.DetectionFilter{},
+		)
+		require.NoError(t, err)
+		require.Len(t, f.Detections.Calls(), 2)
+	})
+
+	t.Run("the call is out of time", func(t *testing.T) {
+		f := newFixture(t)
+		f.Detections.Failures = 10
+
+		ctx, cancel := context.WithTimeout(ctx, 150*time.Millisecond)
+		defer cancel()
+
+		_, err := f.client(t, client.Config{Timeout: 100 * time.Millisecond}).ListDetections(
+			ctx, client.DetectionFilter{},
+		)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.Less(t, len(f.Detections.Calls()), 10)
+	})
+}
+
+func TestUploadAudio(t *testing.T) {
+	var (
+		f  = newFixture(t)
+		ts = time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC)
+	)
+
+	id, err := f.client(t, client.Config{}).RegisterSensor(context.Background(), client.SensorInfo{
+		LocationName: "Main st.", FullName: "Sensor 1",
+	})
+	require.NoError(t, err)
+
+	// the audio is streamed as it's recorded
+	reader, writer := io.Pipe()
+	go func() {
+		for _, chunk := range []string{"RIFF", "....", "WAVE"} {
+			_, _ = writer.Write([]byte(chunk))
+		}
+
+		_ = writer.Close()
+	}()
+
+	err = f.client(t, client.Config{}).UploadAudio(context.Background(), id, ts, reader, client.UploadOptions{
+		ContentType: "audio/wav", Signature: []byte("signature"), Sequence: 7,
+	})
+	require.NoError(t, err)
+
+	msg := <-f.Audio.Messages
+	require.Equal(t, []byte("RIFF....WAVE"), msg.Payload)
+	require.Equal(t, "audio/wav", msg.MessageType)
+	require.Equal(t, ts, msg.Timestamp)
+	require.Equal(t, entities.UploadSignature{Sequence: 7, Signature: []byte("signature")}, msg.Signature)
+}
+
+func TestIncidents(t *testing.T) {
+	var (
+		f   = newFixture(t)
+		c   = f.client(t, client.Config{})
+		ctx = context.Background()
+		id  = f.Incidents.Incident.ID.Hex()
+	)
+
+	incidents, err := c.ListIncidents(ctx, client.IncidentFilter{Limit: 10, From: time.Now().Add(-time.Hour)})
+	require.NoError(t, err)
+	require.Len(t, incidents, 1)
+
+	incident, err := c.TransitionIncident(ctx, id, client.Transition{
+		Status: client.IncidentAcknowledged, DisplayName: "operator",
+	})
+	require.NoError(t, err)
+	require.Equal(t, client.IncidentAcknowledged, incident.Status)
+
+	incident, err = c.GetIncident(ctx, id)
+	require.NoError(t, err)
+	// the actor is the organization of the API key whatever name the caller has given
+	require.Equal(t, []client.IncidentTransition{{
+		From: client.IncidentNew, To: client.IncidentAcknowledged,
+		Actor: "organization:" + f.TenantID.Hex(), DisplayName: "operator",
+	}}, incident.Transitions)
+
+	_, err = c.TransitionIncident(ctx, id, client.Transition{Status: client.IncidentNew})
+	require.ErrorIs(t, err, client.ErrInvalidArgument)
+}
+
+func TestWebhooks(t *testing.T) {
+	var (
+		f   = newFixture(t)
+		c   = f.client(t, client.Config{})
+		ctx = context.Background()
+	)
+
+	var secret string
+	deliveries := make(chan client.WebhookDelivery, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivery, err := client.ParseWebhook(r, secret)
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		deliveries <- delivery
+	}))
+	t.Cleanup(receiver.Close)
+
+	_, _, err := c.CreateWebhook(ctx, "ftp://example.com", client.WebhookAlert)
+	require.ErrorIs(t, err, client.ErrInvalidArgument)
+
+	id, secret, err := c.CreateWebhook(ctx, receiver.URL, client.WebhookAlert)
+	require.NoError(t, err)
+	require.NotEmpty(t, secret)
+
+	webhooks, err := c.ListWebhooks(ctx)
+	require.NoError(t, err)
+	require.Len(t, webhooks, 1)
+	require.Equal(t, []client.WebhookEvent{client.WebhookAlert}, webhooks[0].Events)
+
+	alert := client.Alert{ID: primitive.NewObjectID(), RuleName: "night"}
+	reqID := uuid.New()
+	f.Webhooks.Notify(ctx, reqID, entities.WebhookIncidentTransition, entities.WebhookPayloadIncident{})
+	f.Webhooks.Notify(ctx, reqID, entities.WebhookAlert, alert)
+
+	select {
+	case delivery := <-deliveries:
+		require.Equal(t, client.WebhookAlert, delivery.Event)
+		require.Equal(t, reqID.String(), delivery.RequestID)
+		require.JSONEq(t, mustJSON(t, alert), string(delivery.Data))
+	case <-time.After(5 * time.Second):
+		t.Fatal("the alert is not delivered")
+	}
+
+	require.NoError(t, c.DeleteWebhook(ctx, id))
+	_, err = c.GetWebhook(ctx, id)
+	require.ErrorIs(t, err, client.ErrNotFound)
+}
+
+func mustJSON(t *testing.T, v interface{}) string {
+	raw, err := json.Marshal(v)
+	require.NoError(t, err)
+
+	return string(raw)
+}
+
+func TestListSensors(t *testing.T) {
+	var (
+		f   = newFixture(t)
+		c   = f.client(t, client.Config{})
+		ctx = context.Background()
+		ids = make([]string, 0, 3)
+	)
+
+	for _, name := range []string{"Sensor 1", "Sensor 2", "Sensor 3"} {
+		id, err := c.RegisterSensor(ctx, client.SensorInfo{LocationName: "Main st.", FullName: name})
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+
+	page, after, err := c.ListSensors(ctx, "", 2)
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	require.Equal(t, ids[1], after)
+
+	page, after, err = c.ListSensors(ctx, after, 2)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	require.Equal(t, ids[2], page[0].ID.Hex())
+	require.Empty(t, after)
+
+	_, _, err = c.ListSensors(ctx, "not an id", 0)
+	require.ErrorIs(t, err, client.ErrInvalidArgument)
+}
@@ -0,0 +1,27 @@
+package client
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/controller/http/dto"
+	"net/http"
+)
+
+// ReplayDeadLetters produces dead letters of detections to the detections topic again. It's the admin call:
+// the client must be created with AUTH_ADMIN_TOKEN of the service as the API key
+func (c *Client) ReplayDeadLetters(ctx context.Context, replay DeadLetterReplay) (DeadLetterReplayResult, error) {
+	var result DeadLetterReplayResult
+
+	call, err := jsonCall(http.MethodPost, "/dead-letters/replay", dto.DeadLetterReplayRequest{
+		Partition:     replay.Partition,
+		From:          replay.From,
+		To:            replay.To,
+		SourceOffsets: replay.SourceOffsets,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	_, err = c.do(ctx, call, &result)
+
+	return result, err
+}
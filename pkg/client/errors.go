@@ -0,0 +1,174 @@
+package client
+
+import (
+	"encoding/json"
+	"github.com/Imm0bilize/gunshot-api-service/internal/apperr"
+	"github.com/google/uuid"
+	"mime"
+	"net/http"
+)
+
+const _problemType = "application/problem+json"
+
+// Code is the kind of the problem, codes are the ones of the service
+type Code string
+
+const (
+	CodeInternal             = Code(apperr.Internal)
+	CodeNotFound             = Code(apperr.NotFound)
+	CodeInvalidArgument      = Code(apperr.InvalidArgument)
+	CodeConflict             = Code(apperr.Conflict)
+	CodeUnavailable          = Code(apperr.Unavailable)
+	CodeFailedPrecondition   = Code(apperr.FailedPrecondition)
+	CodePreconditionRequired = Code(apperr.PreconditionRequired)
+	CodeUnauthenticated      = Code(apperr.Unauthenticated)
+	CodePermissionDenied     = Code(apperr.PermissionDenied)
+	CodeResourceExhausted    = Code(apperr.ResourceExhausted)
+	CodeUnsupportedMedia     = Code(apperr.UnsupportedMedia)
+	CodeUnimplemented        = Code(apperr.Unimplemented)
+)
+
+// Sentinels match problems of their code with errors.Is
+var (
+	ErrInternal             = &Error{Code: CodeInternal}
+	ErrNotFound             = &Error{Code: CodeNotFound}
+	ErrInvalidArgument      = &Error{Code: CodeInvalidArgument}
+	ErrConflict             = &Error{Code: CodeConflict}
+	ErrUnavailable          = &Error{Code: CodeUnavailable}
+	ErrFailedPrecondition   = &Error{Code: CodeFailedPrecondition}
+	ErrPreconditionRequired = &Error{Code: CodePreconditionRequired}
+	ErrUnauthenticated      = &Error{Code: CodeUnauthenticated}
+	ErrPermissionDenied     = &Error{Code: CodePermissionDenied}
+	ErrResourceExhausted    = &Error{Code: CodeResourceExhausted}
+	ErrUnsupportedMedia     = &Error{Code: CodeUnsupportedMedia}
+	ErrUnimplemented        = &Error{Code: CodeUnimplemented}
+)
+
+// _codes are codes of statuses of responses which are not problem details, e.g. the ones of proxies
+var _codes = map[int]Code{
+	http.StatusNotFound:              CodeNotFound,
+	http.StatusBadRequest:            CodeInvalidArgument,
+	http.StatusConflict:              CodeConflict,
+	http.StatusBadGateway:            CodeUnavailable,
+	http.StatusServiceUnavailable:    CodeUnavailable,
+	http.StatusGatewayTimeout:        CodeUnavailable,
+	http.StatusPreconditionFailed:    CodeFailedPrecondition,
+	http.StatusPreconditionRequired:  CodePreconditionRequired,
+	http.StatusUnauthorized:          CodeUnauthenticated,
+	http.StatusForbidden:             CodePermissionDenied,
+	http.StatusTooManyRequests:       CodeResourceExhausted,
+	http.StatusUnsupportedMediaType:  CodeUnsupportedMedia,
+	http.StatusNotImplemented:        CodeUnimplemented,
+	http.StatusRequestEntityTooLarge: CodeInvalidArgument,
+}
+
+// Error is the problem (RFC 7807) returned by the service. Errors holds details of the problem as they
+// are, e.g. rows of the import; Violations decodes the ones of invalid requests
+type Error struct {
+	Status    int             `json:"status"`
+	Code      Code            `json:"code"`
+	Title     string          `json:"title"`
+	Detail    string          `json:"detail,omitempty"`
+	Instance  string          `json:"instance,omitempty"`
+	RequestID string          `json:"requestID,omitempty"`
+	Errors    json.RawMessage `json:"errors,omitempty"`
+}
+
+// Violation is the part of the request which doesn't match the API, e.g. "query.limit"
+type Violation struct {
+	Location string `json:"location"`
+	Error    string `json:"error"`
+}
+
+func (e *Error) Error() string {
+	switch {
+	case e.Detail != "":
+		return string(e.Code) + ": " + e.Detail
+	case e.Title != "":
+		return string(e.Code) + ": " + e.Title
+	default:
+		return string(e.Code)
+	}
+}
+
+// Is matches errors of the same code
+func (e *Error) Is(target error) bool {
+	problem, ok := target.(*Error)
+	return ok && problem.Code == e.Code
+}
+
+// Temporary tells if the call may pass on retry
+func (e *Error) Temporary() bool {
+	switch e.Status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// Violations returns parts of the invalid request, nil if the problem has none
+func (e *Error) Violations() []Violation {
+	var violations []Violation
+	if e.Code != CodeInvalidArgument || json.Unmarshal(e.Errors, &violations) != nil {
+		return nil
+	}
+
+	for _, violation := range violations {
+		if violation.Location == "" {
+			// details of the other kind, e.g. rows of the import
+			return nil
+		}
+	}
+
+	return violations
+}
+
+// ImportErrors returns errors of rows of the rejected import, nil if the problem has none
+func (e *Error) ImportErrors() []ImportError {
+	var rows []ImportError
+	if e.Code != CodeInvalidArgument || json.Unmarshal(e.Errors, &rows) != nil {
+		return nil
+	}
+
+	for _, row := range rows {
+		if row.Row == 0 {
+			return nil
+		}
+	}
+
+	return rows
+}
+
+// newError reads the problem of the response, other error responses get the code by their status
+func newError(resp response, requestID uuid.UUID) *Error {
+	problem := &Error{}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.header.Get("Content-Type"))
+	if mediaType == _problemType || mediaType == _jsonType {
+		if err := json.Unmarshal(resp.body, problem); err != nil {
+			problem = &Error{}
+		}
+	}
+
+	problem.Status = resp.status
+	if problem.Code == "" {
+		code, ok := _codes[resp.status]
+		if !ok {
+			code = CodeInternal
+		}
+
+		problem.Code = code
+	}
+
+	if problem.Title == "" {
+		problem.Title = http.StatusText(resp.status)
+	}
+
+	if problem.RequestID == "" {
+		problem.RequestID = requestID.String()
+	}
+
+	return problem
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/controller/http/dto"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+func (c *Client) ListDetections(ctx context.Context, filter DetectionFilter) ([]Detection, error) {
+	query := pageQuery(filter.From, filter.To, filter.Limit, filter.Offset)
+	if filter.ClientID != "" {
+		query.Set("clientID", filter.ClientID)
+	}
+	if filter.ZoneID != "" {
+		query.Set("zoneID", filter.ZoneID)
+	}
+	if filter.After != "" {
+		query.Set("after", filter.After)
+	}
+
+	var resp dto.DetectionsResponse
+
+	_, err := c.do(ctx, call{method: http.MethodGet, path: "/detections", query: query}, &resp)
+
+	return resp.Detections, err
+}
+
+func (c *Client) ListIncidents(ctx context.Context, filter IncidentFilter) ([]Incident, error) {
+	query := pageQuery(filter.From, filter.To, filter.Limit, filter.Offset)
+	if filter.ZoneID != "" {
+		query.Set("zoneID", filter.ZoneID)
+	}
+
+	var resp dto.IncidentsResponse
+
+	_, err := c.do(ctx, call{method: http.MethodGet, path: "/incidents", query: query}, &resp)
+
+	return resp.Incidents, err
+}
+
+func (c *Client) GetIncident(ctx context.Context, id string) (Incident, error) {
+	var incident Incident
+
+	_, err := c.do(ctx, call{method: http.MethodGet, path: "/incidents/" + url.PathEscape(id)}, &incident)
+
+	return incident, err
+}
+
+// TransitionIncident moves the incident along its workflow and returns the incident with the transition
+func (c *Client) TransitionIncident(ctx context.Context, id string, transition Transition) (Incident, error) {
+	var incident Incident
+
+	call, err := jsonCall(http.MethodPost, "/incidents/"+url.PathEscape(id)+"/transitions", dto.TransitionRequest{
+		Status:      string(transition.Status),
+		DisplayName: transition.DisplayName,
+		Notes:       transition.Notes,
+	})
+	if err != nil {
+		return incident, err
+	}
+
+	_, err = c.do(ctx, call, &incident)
+
+	return incident, err
+}
+
+// pageQuery encodes the time range and the page, zero values are left to defaults of the service
+func pageQuery(from, to time.Time, limit, offset int64) url.Values {
+	query := make(url.Values)
+	if !from.IsZero() {
+		query.Set("from", from.Format(time.RFC3339))
+	}
+	if !to.IsZero() {
+		query.Set("to", to.Format(time.RFC3339))
+	}
+	if limit != 0 {
+		query.Set("limit", strconv.FormatInt(limit, 10))
+	}
+	if offset != 0 {
+		query.Set("offset", strconv.FormatInt(offset, 10))
+	}
+
+	return query
+}
+
+// ExportEvidence writes the evidence bundle of the incident, pkg/evidence verifies it
+func (c *Client) ExportEvidence(ctx context.Context, id string, w io.Writer) error {
+	resp, err := c.do(ctx, call{method: http.MethodGet, path: "/incidents/" + url.PathEscape(id) + "/evidence"}, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(resp.body)
+
+	return err
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/controller/http/dto"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// RegisterDeviceKey adds the Ed25519 public key of the sensor. Previous keys keep working for the overlap,
+// nil takes the default of the service
+func (c *Client) RegisterDeviceKey(
+	ctx context.Context, id string, publicKey []byte, overlap *time.Duration,
+) (DeviceKey, error) {
+	var key DeviceKey
+
+	req := dto.DeviceKeyRequest{PublicKey: publicKey}
+	if overlap != nil {
+		seconds := int(overlap.Seconds())
+		req.Overlap = &seconds
+	}
+
+	call, err := jsonCall(http.MethodPost, sensorPath(id)+"/keys", req)
+	if err != nil {
+		return key, err
+	}
+
+	_, err = c.do(ctx, call, &key)
+
+	return key, err
+}
+
+func (c *Client) ListDeviceKeys(ctx context.Context, id string) ([]DeviceKey, error) {
+	var resp dto.DeviceKeysResponse
+
+	_, err := c.do(ctx, call{method: http.MethodGet, path: sensorPath(id) + "/keys"}, &resp)
+
+	return resp.Keys, err
+}
+
+func (c *Client) RevokeDeviceKey(ctx context.Context, id, keyID string) error {
+	_, err := c.do(
+		ctx, call{method: http.MethodDelete, path: sensorPath(id) + "/keys/" + url.PathEscape(keyID)}, nil,
+	)
+
+	return err
+}
+
+// VerifyAudioChain checks the hash chain of uploads of the sensor in the time range, zero times are open
+func (c *Client) VerifyAudioChain(ctx context.Context, id string, from, to time.Time) (ChainVerification, error) {
+	var verification ChainVerification
+
+	_, err := c.do(
+		ctx,
+		call{method: http.MethodGet, path: sensorPath(id) + "/audio/verify", query: pageQuery(from, to, 0, 0)},
+		&verification,
+	)
+
+	return verification, err
+}
@@ -0,0 +1,154 @@
+package client
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/controller/http/dto"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const _mergePatchType = "application/merge-patch+json"
+
+func sensorPath(id string) string {
+	return "/client/" + url.PathEscape(id)
+}
+
+// RegisterSensor registers the sensor and returns its id
+func (c *Client) RegisterSensor(ctx context.Context, info SensorInfo) (string, error) {
+	call, err := jsonCall(http.MethodPost, "/client", info.request())
+	if err != nil {
+		return "", err
+	}
+
+	var resp dto.RegisterResponse
+
+	if _, err := c.do(ctx, call, &resp); err != nil {
+		return "", err
+	}
+
+	return resp.ClientID, nil
+}
+
+func (c *Client) GetSensor(ctx context.Context, id string) (Sensor, error) {
+	var sensor Sensor
+
+	_, err := c.do(ctx, call{method: http.MethodGet, path: sensorPath(id)}, &sensor)
+
+	return sensor, err
+}
+
+// ListSensors returns the page of sensors in the order of their IDs and the after of the next page, which is
+// empty after the last one. The zero limit is the default of the service
+func (c *Client) ListSensors(ctx context.Context, after string, limit int64) ([]Sensor, string, error) {
+	query := make(url.Values)
+	if after != "" {
+		query.Set("after", after)
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.FormatInt(limit, 10))
+	}
+
+	var resp dto.ClientsResponse
+
+	if _, err := c.do(ctx, call{method: http.MethodGet, path: "/clients", query: query}, &resp); err != nil {
+		return nil, "", err
+	}
+
+	return resp.Clients, resp.Next, nil
+}
+
+// UpdateSensor replaces fields of the sensor if it's still of the version and returns the updated sensor. The
+// retry of the update which has been applied fails with ErrFailedPrecondition
+func (c *Client) UpdateSensor(ctx context.Context, id string, version int64, info SensorInfo) (Sensor, error) {
+	var sensor Sensor
+
+	call, err := jsonCall(http.MethodPut, sensorPath(id), info.request())
+	if err != nil {
+		return sensor, err
+	}
+
+	call.header = ifMatch(version)
+
+	_, err = c.do(ctx, call, &sensor)
+
+	return sensor, err
+}
+
+// PatchSensor applies the patch to the sensor if it's still of the version
+func (c *Client) PatchSensor(ctx context.Context, id string, version int64, patch SensorPatch) (Sensor, error) {
+	var sensor Sensor
+
+	call, err := jsonCall(http.MethodPatch, sensorPath(id), patch)
+	if err != nil {
+		return sensor, err
+	}
+
+	call.contentType = _mergePatchType
+	call.header = ifMatch(version)
+
+	_, err = c.do(ctx, call, &sensor)
+
+	return sensor, err
+}
+
+// DeleteSensor deletes the sensor if it's still of the version, it may be restored until it's purged
+func (c *Client) DeleteSensor(ctx context.Context, id string, version int64) error {
+	_, err := c.do(ctx, call{method: http.MethodDelete, path: sensorPath(id), header: ifMatch(version)}, nil)
+	return err
+}
+
+func (c *Client) RestoreSensor(ctx context.Context, id string) (Sensor, error) {
+	var sensor Sensor
+
+	_, err := c.do(ctx, call{method: http.MethodPost, path: sensorPath(id) + "/restore"}, &sensor)
+
+	return sensor, err
+}
+
+// ifMatch makes writes conditional on the version of the sensor
+func ifMatch(version int64) http.Header {
+	return http.Header{"If-Match": {entities.ClientETag(version)}}
+}
+
+// ImportSensors creates sensors of the CSV file (text/csv) or the GeoJSON FeatureCollection
+// (application/geo+json). Nothing is created if any row is invalid: the problem lists errors of rows,
+// ImportErrors decodes them. The import is not retried: the file may be consumed by the failed attempt
+func (c *Client) ImportSensors(
+	ctx context.Context, file io.Reader, contentType string, dryRun bool,
+) (SensorImport, error) {
+	var result SensorImport
+
+	_, err := c.do(
+		ctx,
+		call{
+			method:      http.MethodPost,
+			path:        "/clients/import",
+			query:       url.Values{"dryRun": {strconv.FormatBool(dryRun)}},
+			contentType: contentType,
+			body: func() (io.Reader, error) {
+				return file, nil
+			},
+			once: true,
+		},
+		&result,
+	)
+
+	return result, err
+}
+
+// ExportSensors writes sensors of the organization in the format: csv, geojson or kml
+func (c *Client) ExportSensors(ctx context.Context, format string, w io.Writer) error {
+	resp, err := c.do(
+		ctx, call{method: http.MethodGet, path: "/clients/export", query: url.Values{"format": {format}}}, nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(resp.body)
+
+	return err
+}
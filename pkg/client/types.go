@@ -0,0 +1,134 @@
+package client
+
+import (
+	"encoding/json"
+	"github.com/Imm0bilize/gunshot-api-service/internal/controller/http/dto"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/pkg/webhook"
+	"time"
+)
+
+// Responses are types of the service, aliases let other modules name them
+type (
+	// Sensor is the client of the API: the device which uploads audio
+	Sensor             = entities.Client
+	Detection          = entities.Detection
+	Incident           = entities.Incident
+	IncidentStatus     = entities.IncidentStatus
+	IncidentTransition = entities.IncidentTransition
+	SensorImport       = entities.ClientImport
+	ImportError        = entities.ImportError
+	DeviceKey          = entities.DeviceKey
+	ChainVerification  = entities.ChainVerification
+	ChainBreak         = entities.ChainBreak
+	Webhook            = entities.Webhook
+	WebhookEvent       = entities.WebhookEvent
+	// WebhookDelivery is the body of the request the service POSTs to the webhook
+	WebhookDelivery = webhook.Delivery
+	// WebhookIncident is Data of the incident.transition delivery, Data of the alert one is Alert
+	WebhookIncident = entities.WebhookPayloadIncident
+	Alert           = entities.Alert
+	// DeadLetterReplay selects dead letters by their offsets in the dead-letter topic or in the detections one
+	DeadLetterReplay       = entities.DeadLetterReplay
+	DeadLetterReplayResult = entities.DeadLetterReplayResult
+)
+
+const (
+	IncidentNew           = entities.IncidentNew
+	IncidentAcknowledged  = entities.IncidentAcknowledged
+	IncidentDispatched    = entities.IncidentDispatched
+	IncidentResolved      = entities.IncidentResolved
+	IncidentFalsePositive = entities.IncidentFalsePositive
+
+	WebhookIncidentTransition = entities.WebhookIncidentTransition
+	WebhookAlert              = entities.WebhookAlert
+)
+
+// SensorInfo registers the sensor and replaces its fields on update
+type SensorInfo struct {
+	LocationName        string
+	FullName            string
+	Latitude            float64
+	Longitude           float64
+	NotificationMethods []string
+}
+
+func (i SensorInfo) request() dto.ClientInfo {
+	methods := i.NotificationMethods
+	if methods == nil {
+		methods = make([]string, 0)
+	}
+
+	return dto.ClientInfo{
+		LocationName:        i.LocationName,
+		FullName:            i.FullName,
+		Latitude:            &i.Latitude,
+		Longitude:           &i.Longitude,
+		NotificationMethods: methods,
+	}
+}
+
+// SensorPatch changes fields which are set, names can be replaced but not removed
+type SensorPatch struct {
+	LocationName *string
+	FullName     *string
+	Latitude     *float64
+	Longitude    *float64
+}
+
+// MarshalJSON encodes the patch as the JSON merge patch (RFC 7396)
+func (p SensorPatch) MarshalJSON() ([]byte, error) {
+	patch := make(map[string]interface{})
+	if p.LocationName != nil {
+		patch["locationName"] = *p.LocationName
+	}
+	if p.FullName != nil {
+		patch["fullName"] = *p.FullName
+	}
+	if p.Latitude != nil {
+		patch["latitude"] = *p.Latitude
+	}
+	if p.Longitude != nil {
+		patch["longitude"] = *p.Longitude
+	}
+
+	return json.Marshal(patch)
+}
+
+// UploadOptions describe the audio, the signature and its sequence are checked against device keys of
+// the sensor
+type UploadOptions struct {
+	// ContentType is the media type of the audio, application/octet-stream by default
+	ContentType string
+	Signature   []byte
+	Sequence    int64
+}
+
+// DetectionFilter selects detections, zero fields match all
+type DetectionFilter struct {
+	ClientID string
+	ZoneID   string
+	From     time.Time
+	To       time.Time
+	// After is the ID of the detection, the ones stored after it are listed in the order they are stored
+	After  string
+	Limit  int64
+	Offset int64
+}
+
+// IncidentFilter selects incidents, zero fields match all
+type IncidentFilter struct {
+	ZoneID string
+	From   time.Time
+	To     time.Time
+	Limit  int64
+	Offset int64
+}
+
+// Transition moves the incident to the status. The actor of the transition is the organization of the API key,
+// DisplayName is shown with it
+type Transition struct {
+	Status      IncidentStatus
+	DisplayName string
+	Notes       string
+}
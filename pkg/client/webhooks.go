@@ -0,0 +1,58 @@
+package client
+
+import (
+	"context"
+	"github.com/Imm0bilize/gunshot-api-service/internal/controller/http/dto"
+	"github.com/Imm0bilize/gunshot-api-service/pkg/webhook"
+	"net/http"
+	"net/url"
+)
+
+func webhookPath(id string) string {
+	return "/webhooks/" + url.PathEscape(id)
+}
+
+// CreateWebhook subscribes the URL to events of the organization and returns the id of the webhook with the
+// secret its deliveries are signed with. The secret is returned only once, keep it for ParseWebhook
+func (c *Client) CreateWebhook(ctx context.Context, target string, events ...WebhookEvent) (string, string, error) {
+	call, err := jsonCall(http.MethodPost, "/webhooks", dto.WebhookInfo{URL: target, Events: events})
+	if err != nil {
+		return "", "", err
+	}
+
+	var resp dto.WebhookCreatedResponse
+
+	if _, err := c.do(ctx, call, &resp); err != nil {
+		return "", "", err
+	}
+
+	return resp.ID, resp.Secret, nil
+}
+
+func (c *Client) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	var resp dto.WebhooksResponse
+
+	_, err := c.do(ctx, call{method: http.MethodGet, path: "/webhooks"}, &resp)
+
+	return resp.Webhooks, err
+}
+
+func (c *Client) GetWebhook(ctx context.Context, id string) (Webhook, error) {
+	var hook Webhook
+
+	_, err := c.do(ctx, call{method: http.MethodGet, path: webhookPath(id)}, &hook)
+
+	return hook, err
+}
+
+func (c *Client) DeleteWebhook(ctx context.Context, id string) error {
+	_, err := c.do(ctx, call{method: http.MethodDelete, path: webhookPath(id)}, nil)
+	return err
+}
+
+// ParseWebhook verifies the signature of the delivery the service has POSTed to the webhook and decodes it.
+// Deliveries signed earlier than webhook.DefaultTolerance are refused, so replays don't pass. A delivery may
+// come more than once, its ID is the same for every attempt
+func ParseWebhook(r *http.Request, secret string) (WebhookDelivery, error) {
+	return webhook.Parse(r, secret)
+}
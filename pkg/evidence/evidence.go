@@ -0,0 +1,309 @@
+// Package evidence writes and verifies evidence bundles of incidents. The bundle is a ZIP archive with
+// the manifest, audio clips and supporting documents; SHA256SUMS lists the digest of every file and
+// SHA256SUMS.sig signs it with the key of the service, so the bundle can be checked offline.
+package evidence
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/signing"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	ManifestFile   = "manifest.json"
+	DetectionsFile = "detections.json"
+	AuditFile      = "audit.json"
+	SumsFile       = "SHA256SUMS"
+	SignatureFile  = "SHA256SUMS.sig"
+	AudioDir       = "audio/"
+)
+
+var (
+	ErrDuplicateFile = errors.New("the file is already in the bundle")
+	ErrMalformed     = errors.New("the bundle is malformed")
+)
+
+// Manifest describes the incident and where the audio of every contributing sensor is in the bundle
+type Manifest struct {
+	RequestID   uuid.UUID         `json:"requestID"`
+	GeneratedAt time.Time         `json:"generatedAt"`
+	Incident    entities.Incident `json:"incident"`
+	// From and To is the time range the clips were taken from
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+	Sensors []Sensor  `json:"sensors"`
+	Clips   []Clip    `json:"clips"`
+}
+
+// Sensor is the contributing client as it is at the moment of the export
+type Sensor struct {
+	ClientID     primitive.ObjectID  `json:"clientID"`
+	LocationName string              `json:"locationName"`
+	FullName     string              `json:"fullName"`
+	Latitude     float64             `json:"latitude"`
+	Longitude    float64             `json:"longitude"`
+	Clock        entities.ClockState `json:"clock"`
+	// Deleted is set when the client has been removed since the incident
+	Deleted bool `json:"deleted,omitempty"`
+}
+
+// Clip is the uploaded audio with its record of the hash chain. Timestamp of the record is corrected
+// by the clock estimate of the client, RawTimestamp is the one the client sent
+type Clip struct {
+	File   string               `json:"file,omitempty"`
+	Record entities.ChainRecord `json:"record"`
+	// Missing is set when the audio is no longer stored
+	Missing bool `json:"missing,omitempty"`
+}
+
+// ClipFile is the name of the clip in the bundle
+func ClipFile(record entities.ChainRecord, extension string) string {
+	return fmt.Sprintf("%s%s/%06d%s", AudioDir, record.ClientID.Hex(), record.Sequence, extension)
+}
+
+// Writer streams the bundle, the digest of every added file is collected for SHA256SUMS
+type Writer struct {
+	zw    *zip.Writer
+	sums  bytes.Buffer
+	names map[string]struct{}
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{zw: zip.NewWriter(w), names: make(map[string]struct{})}
+}
+
+// Add writes the file into the bundle
+func (w *Writer) Add(name string, data []byte) error {
+	if _, ok := w.names[name]; ok || name == SumsFile || name == SignatureFile {
+		return fmt.Errorf("%w: %s", ErrDuplicateFile, name)
+	}
+	w.names[name] = struct{}{}
+
+	if err := w.write(name, data); err != nil {
+		return err
+	}
+
+	digest := sha256.Sum256(data)
+	fmt.Fprintf(&w.sums, "%s  %s\n", hex.EncodeToString(digest[:]), name)
+
+	return nil
+}
+
+// AddJSON writes the value as the indented JSON file
+func (w *Writer) AddJSON(name string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return errors.Wrapf(err, "can't encode %s", name)
+	}
+
+	return w.Add(name, data)
+}
+
+// Close writes SHA256SUMS with its signature and finishes the archive, the nil signer leaves the archive
+// unsigned
+func (w *Writer) Close(signer *signing.Signer) error {
+	sums := w.sums.Bytes()
+	if err := w.write(SumsFile, sums); err != nil {
+		return err
+	}
+
+	if signer == nil {
+		return errors.Wrap(w.zw.Close(), "can't finish the bundle")
+	}
+
+	signature, err := json.MarshalIndent(signer.SignDocument(sums), "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "can't encode the signature")
+	}
+	if err := w.write(SignatureFile, signature); err != nil {
+		return err
+	}
+
+	return errors.Wrap(w.zw.Close(), "can't finish the bundle")
+}
+
+func (w *Writer) write(name string, data []byte) error {
+	f, err := w.zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now().UTC()})
+	if err != nil {
+		return errors.Wrapf(err, "can't add %s", name)
+	}
+
+	if _, err := f.Write(data); err != nil {
+		return errors.Wrapf(err, "can't write %s", name)
+	}
+
+	return nil
+}
+
+// Report is the result of the offline verification of the bundle. Valid means the bundle is intact and
+// consistent with its signature, Trusted means it's signed with the trusted key; only both prove the
+// service has made it
+type Report struct {
+	Valid     bool   `json:"valid"`
+	Trusted   bool   `json:"trusted"`
+	PublicKey string `json:"publicKey"`
+	Files     int    `json:"files"`
+	Clips     int    `json:"clips"`
+	// Problems lists everything which doesn't match, the bundle is valid when it's empty
+	Problems []string `json:"problems"`
+}
+
+func (r *Report) problem(format string, args ...interface{}) {
+	r.Problems = append(r.Problems, fmt.Sprintf(format, args...))
+}
+
+// Verify checks the signature of SHA256SUMS, digests of all files and hash chains of the clips.
+// The trusted key is the base64 encoded public key of the service. Anyone can re-sign a forged bundle
+// with their own key, so without the trusted key the bundle is never Trusted, whatever key it carries
+func Verify(r io.ReaderAt, size int64, trustedKey string) (Report, error) {
+	report := Report{Problems: make([]string, 0)}
+
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return Report{}, fmt.Errorf("%w: %s", ErrMalformed, err)
+	}
+
+	files := make(map[string][]byte, len(archive.File))
+	for _, f := range archive.File {
+		data, err := readFile(f)
+		if err != nil {
+			return Report{}, fmt.Errorf("%w: can't read %s: %s", ErrMalformed, f.Name, err)
+		}
+		files[f.Name] = data
+	}
+
+	sums, ok := files[SumsFile]
+	if !ok {
+		return Report{}, fmt.Errorf("%w: %s is missing", ErrMalformed, SumsFile)
+	}
+
+	rawSignature, ok := files[SignatureFile]
+	if !ok {
+		return Report{}, fmt.Errorf("%w: %s is missing", ErrMalformed, SignatureFile)
+	}
+
+	var signature signing.Signature
+	if err := json.Unmarshal(rawSignature, &signature); err != nil {
+		return Report{}, fmt.Errorf("%w: %s: %s", ErrMalformed, SignatureFile, err)
+	}
+	report.PublicKey = signature.PublicKey
+
+	digest := sha256.Sum256(sums)
+	signed := signing.Verify(signature, digest[:])
+	if signed != nil {
+		report.problem("%s: %s", SignatureFile, signed)
+	}
+	if trustedKey != "" && signature.PublicKey != trustedKey {
+		report.problem("%s: signed with the untrusted key %s", SignatureFile, signature.PublicKey)
+	}
+	report.Trusted = signed == nil && trustedKey != "" && signature.PublicKey == trustedKey
+
+	listed, err := parseSums(sums)
+	if err != nil {
+		return Report{}, err
+	}
+	report.Files = len(listed)
+
+	for name, expected := range listed {
+		data, ok := files[name]
+		if !ok {
+			report.problem("%s: listed but missing", name)
+			continue
+		}
+
+		if actual := sha256.Sum256(data); hex.EncodeToString(actual[:]) != expected {
+			report.problem("%s: digest mismatch", name)
+		}
+	}
+
+	for name := range files {
+		if _, ok := listed[name]; !ok && name != SumsFile && name != SignatureFile {
+			report.problem("%s: not listed in %s", name, SumsFile)
+		}
+	}
+
+	if data, ok := files[ManifestFile]; ok {
+		verifyClips(&report, data, files)
+	} else {
+		report.problem("%s: missing", ManifestFile)
+	}
+
+	sort.Strings(report.Problems)
+	report.Valid = len(report.Problems) == 0
+
+	return report, nil
+}
+
+// verifyClips recomputes hash chains of clips of every sensor and compares the audio with payload hashes
+func verifyClips(report *Report, data []byte, files map[string][]byte) {
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		report.problem("%s: %s", ManifestFile, err)
+		return
+	}
+
+	chains := make(map[primitive.ObjectID][]entities.ChainRecord)
+	for _, clip := range manifest.Clips {
+		chains[clip.Record.ClientID] = append(chains[clip.Record.ClientID], clip.Record)
+		if clip.Missing {
+			continue
+		}
+		report.Clips++
+
+		audio, ok := files[clip.File]
+		if !ok {
+			report.problem("%s: the clip is missing", clip.File)
+			continue
+		}
+
+		if entities.HashPayload(audio) != clip.Record.PayloadHash {
+			report.problem("%s: the audio doesn't match the hash chain", clip.File)
+		}
+	}
+
+	for clientID, records := range chains {
+		sort.Slice(records, func(i, j int) bool { return records[i].Sequence < records[j].Sequence })
+
+		for _, b := range entities.VerifyChain(nil, records) {
+			report.problem("chain of %s: record %d: %s", clientID.Hex(), b.Sequence, b.Reason)
+		}
+	}
+}
+
+func parseSums(sums []byte) (map[string]string, error) {
+	listed := make(map[string]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(sums))
+	for scanner.Scan() {
+		digest, name, ok := strings.Cut(scanner.Text(), "  ")
+		if !ok || len(digest) != sha256.Size*2 {
+			return nil, fmt.Errorf("%w: malformed line of %s: %q", ErrMalformed, SumsFile, scanner.Text())
+		}
+		listed[name] = digest
+	}
+
+	return listed, scanner.Err()
+}
+
+func readFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return io.ReadAll(rc)
+}
@@ -0,0 +1,164 @@
+package evidence_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"github.com/Imm0bilize/gunshot-api-service/internal/signing"
+	"github.com/Imm0bilize/gunshot-api-service/pkg/evidence"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newSigner(t *testing.T, seed string) *signing.Signer {
+	signer, err := signing.NewSigner(strings.Repeat(seed, 32))
+	require.NoError(t, err)
+
+	return signer
+}
+
+// chain links clips of the client the way the audio upload does
+func chain(clientID primitive.ObjectID, payloads ...[]byte) []evidence.Clip {
+	var (
+		clips = make([]evidence.Clip, 0, len(payloads))
+		prev  = entities.GenesisHash
+		ts    = time.Date(2022, 12, 1, 22, 0, 0, 0, time.UTC)
+	)
+
+	for i, payload := range payloads {
+		record := entities.ChainRecord{
+			ClientID:  clientID,
+			Timestamp: ts.Add(time.Duration(i) * time.Second),
+			ChainLink: entities.ChainLink{
+				Sequence:    int64(i + 1),
+				PayloadHash: entities.HashPayload(payload),
+				PrevHash:    prev,
+			},
+		}
+		record.Hash = record.ComputeHash()
+		prev = record.Hash
+
+		clips = append(clips, evidence.Clip{File: evidence.ClipFile(record, ".wav"), Record: record})
+	}
+
+	return clips
+}
+
+func bundle(t *testing.T, signer *signing.Signer, manifest evidence.Manifest, files map[string][]byte) []byte {
+	var buf bytes.Buffer
+
+	w := evidence.NewWriter(&buf)
+	require.NoError(t, w.AddJSON(evidence.ManifestFile, manifest))
+	for _, clip := range manifest.Clips {
+		require.NoError(t, w.Add(clip.File, files[clip.File]))
+	}
+	require.NoError(t, w.Close(signer))
+
+	return buf.Bytes()
+}
+
+// rewrite copies the bundle replacing contents of files, e.g. to tamper with them
+func rewrite(t *testing.T, data []byte, replace map[string][]byte) []byte {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, f := range archive.File {
+		content, ok := replace[f.Name]
+		if !ok {
+			rc, err := f.Open()
+			require.NoError(t, err)
+			content, err = io.ReadAll(rc)
+			require.NoError(t, err)
+			require.NoError(t, rc.Close())
+		}
+		delete(replace, f.Name)
+
+		fw, err := w.Create(f.Name)
+		require.NoError(t, err)
+		_, err = fw.Write(content)
+		require.NoError(t, err)
+	}
+	for name, content := range replace {
+		fw, err := w.Create(name)
+		require.NoError(t, err)
+		_, err = fw.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
+
+func verify(t *testing.T, data []byte, key string) evidence.Report {
+	report, err := evidence.Verify(bytes.NewReader(data), int64(len(data)), key)
+	require.NoError(t, err)
+
+	return report
+}
+
+func TestVerify(t *testing.T) {
+	var (
+		signer   = newSigner(t, "01")
+		clientID = primitive.NewObjectID()
+		payloads = [][]byte{[]byte("first"), []byte("second")}
+		clips    = chain(clientID, payloads...)
+		files    = map[string][]byte{clips[0].File: payloads[0], clips[1].File: payloads[1]}
+		manifest = evidence.Manifest{Clips: clips}
+		data     = bundle(t, signer, manifest, files)
+	)
+
+	t.Run("valid", func(t *testing.T) {
+		report := verify(t, data, signer.PublicKey())
+		require.True(t, report.Valid, report.Problems)
+		require.True(t, report.Trusted)
+		require.Equal(t, 3, report.Files)
+		require.Equal(t, 2, report.Clips)
+	})
+
+	t.Run("untrusted key", func(t *testing.T) {
+		report := verify(t, data, newSigner(t, "02").PublicKey())
+		require.False(t, report.Valid)
+		require.False(t, report.Trusted)
+	})
+
+	t.Run("no trusted key", func(t *testing.T) {
+		report := verify(t, data, "")
+		require.True(t, report.Valid, report.Problems)
+		require.False(t, report.Trusted, "the key of the bundle itself must not be trusted")
+		require.Equal(t, signer.PublicKey(), report.PublicKey)
+	})
+
+	t.Run("audio replaced", func(t *testing.T) {
+		report := verify(t, rewrite(t, data, map[string][]byte{clips[1].File: []byte("forged")}), "")
+		require.False(t, report.Valid)
+		require.Contains(t, report.Problems, clips[1].File+": digest mismatch")
+	})
+
+	t.Run("file added", func(t *testing.T) {
+		report := verify(t, rewrite(t, data, map[string][]byte{"extra.txt": []byte("x")}), "")
+		require.Equal(t, []string{"extra.txt: not listed in " + evidence.SumsFile}, report.Problems)
+	})
+
+	t.Run("sums re-signed by another key", func(t *testing.T) {
+		// the forger can list new digests only under its own key
+		forged := clips[1]
+		forged.Record.PayloadHash = entities.HashPayload([]byte("forged"))
+		other := bundle(t, newSigner(t, "02"), evidence.Manifest{Clips: []evidence.Clip{clips[0], forged}},
+			map[string][]byte{clips[0].File: payloads[0], clips[1].File: []byte("forged")})
+
+		report := verify(t, other, signer.PublicKey())
+		require.False(t, report.Valid)
+		require.Contains(t, report.Problems, "chain of "+clientID.Hex()+": record 2: record_altered")
+	})
+}
+
+func TestVerifyMalformed(t *testing.T) {
+	_, err := evidence.Verify(bytes.NewReader([]byte("not a zip")), 9, "")
+	require.ErrorIs(t, err, evidence.ErrMalformed)
+}
@@ -0,0 +1,117 @@
+// Package webhook signs and verifies deliveries of webhooks. The delivery is the JSON POST with the event,
+// the signature header holds the HMAC-SHA256 of the timestamp and the body by the secret of the webhook:
+//
+//	X-Gunshot-Signature: t=<unix seconds>,v1=<hex HMAC-SHA256 of "<unix seconds>.<body>">
+//
+// The timestamp is signed, so the receiver can refuse deliveries replayed later than its tolerance.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Imm0bilize/gunshot-api-service/internal/entities"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Gunshot-Signature"
+	EventHeader     = "X-Gunshot-Event"
+	DeliveryHeader  = "X-Gunshot-Delivery"
+
+	// DefaultTolerance is how old the delivery Parse takes
+	DefaultTolerance = 5 * time.Minute
+
+	_maxBodySize = 1 << 20
+)
+
+var (
+	ErrMissingSignature = errors.New("the delivery is not signed")
+	ErrInvalidSignature = errors.New("the signature of the delivery is invalid")
+	ErrExpired          = errors.New("the delivery is older than the tolerance")
+)
+
+// Delivery is the body of the webhook request, Data is the incident with its transition for the
+// incident.transition event and the alert for the alert event
+type Delivery struct {
+	ID        string                `json:"ID"`
+	Event     entities.WebhookEvent `json:"event"`
+	RequestID string                `json:"requestID"`
+	Timestamp time.Time             `json:"timestamp"`
+	Data      json.RawMessage       `json:"data"`
+}
+
+// Sign returns the value of the signature header of the body sent at the moment
+func Sign(secret string, at time.Time, body []byte) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	return "t=" + timestamp + ",v1=" + mac(secret, timestamp, body)
+}
+
+// Verify checks the signature header of the body, deliveries signed earlier than the tolerance before now
+// are refused
+func Verify(secret, signature string, body []byte, now time.Time, tolerance time.Duration) error {
+	if signature == "" {
+		return ErrMissingSignature
+	}
+
+	var timestamp, expected string
+	for _, part := range strings.Split(signature, ",") {
+		name, value, _ := strings.Cut(part, "=")
+		switch name {
+		case "t":
+			timestamp = value
+		case "v1":
+			expected = value
+		}
+	}
+
+	at, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || expected == "" {
+		return fmt.Errorf("%w: malformed header", ErrInvalidSignature)
+	}
+
+	if !hmac.Equal([]byte(expected), []byte(mac(secret, timestamp, body))) {
+		return ErrInvalidSignature
+	}
+
+	if now.Sub(time.Unix(at, 0)) > tolerance {
+		return ErrExpired
+	}
+
+	return nil
+}
+
+// Parse verifies the webhook request by the secret and decodes its delivery
+func Parse(r *http.Request, secret string) (Delivery, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, _maxBodySize))
+	if err != nil {
+		return Delivery{}, err
+	}
+
+	if err := Verify(secret, r.Header.Get(SignatureHeader), body, time.Now(), DefaultTolerance); err != nil {
+		return Delivery{}, err
+	}
+
+	var delivery Delivery
+	if err := json.Unmarshal(body, &delivery); err != nil {
+		return Delivery{}, fmt.Errorf("can't decode the delivery: %w", err)
+	}
+
+	return delivery, nil
+}
+
+func mac(secret, timestamp string, body []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(timestamp))
+	h.Write([]byte("."))
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
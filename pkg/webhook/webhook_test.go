@@ -0,0 +1,56 @@
+package webhook_test
+
+import (
+	"bytes"
+	"github.com/Imm0bilize/gunshot-api-service/pkg/webhook"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	var (
+		body      = []byte(`{"ID":"1","event":"alert"}`)
+		at        = time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+		signature = webhook.Sign("secret", at, body)
+	)
+
+	require.NoError(t, webhook.Verify("secret", signature, body, at.Add(time.Minute), webhook.DefaultTolerance))
+
+	cases := []struct {
+		name      string
+		secret    string
+		signature string
+		body      []byte
+		now       time.Time
+		err       error
+	}{
+		{"another secret", "another", signature, body, at, webhook.ErrInvalidSignature},
+		{"tampered body", "secret", signature, []byte(`{"ID":"2","event":"alert"}`), at, webhook.ErrInvalidSignature},
+		{"no signature", "secret", "", body, at, webhook.ErrMissingSignature},
+		{"malformed signature", "secret", "v1=abc", body, at, webhook.ErrInvalidSignature},
+		{"replayed later", "secret", signature, body, at.Add(time.Hour), webhook.ErrExpired},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := webhook.Verify(tc.secret, tc.signature, tc.body, tc.now, webhook.DefaultTolerance)
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	body := []byte(`{"ID":"1","event":"alert","requestID":"r","data":{"ruleName":"night"}}`)
+
+	req := httptest.NewRequest(http.MethodPost, "/hook", bytes.NewReader(body))
+	req.Header.Set(webhook.SignatureHeader, webhook.Sign("secret", time.Now(), body))
+
+	delivery, err := webhook.Parse(req, "secret")
+	require.NoError(t, err)
+	require.Equal(t, "1", delivery.ID)
+	require.EqualValues(t, "alert", delivery.Event)
+	require.JSONEq(t, `{"ruleName":"night"}`, string(delivery.Data))
+}